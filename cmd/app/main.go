@@ -1,12 +1,27 @@
 package main
 
 import (
+	"log"
+	"os"
+
 	"main/server"
 	"main/server/common/globals"
+	"main/server/common/migrations"
+	"main/server/common/module"
+	"main/server/common/storage"
+	"main/server/model"
 )
 
 
 func main() {
 	globals.SetupEnvironmentVariables()
+
+	/* "app migrate up" migrates with the migrations the binary was built with, a deploy needs no go toolchain */
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		storage.Connect(storage.Default())
+		Models := append(model.Models, module.Models(server.Modules...)...)
+		if err := migrations.Command(storage.DB, os.Args[2:], Models); err != nil { log.Fatal(err) }
+		return
+	}
 	server.Run()
-}
\ No newline at end of file
+}
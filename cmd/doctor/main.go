@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"main/server"
+	"main/server/common/globals"
+	"main/server/common/migrations"
+	"main/server/common/module"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+/*
+	Checks the environment the app runs in and tells how to fix what's wrong:
+
+	go run ./cmd/doctor
+*/
+
+/* Warn when less is left on the uploads' disk */
+const MinFreeSpace = 1 << 30
+
+type check struct {
+	failed		int
+	warned		int
+}
+
+func (c *check) ok(message string) { fmt.Println("  ✔", message) }
+
+func (c *check) warn(message string, fix string) {
+	c.warned++
+	fmt.Println("  !", message)
+	fmt.Println("      →", fix)
+}
+
+func (c *check) fail(message string, fix string) {
+	c.failed++
+	fmt.Println("  ✘", message)
+	fmt.Println("      →", fix)
+}
+
+func main() {
+	c := &check{}
+
+	fmt.Println("Configuration")
+	if _, err := os.Stat(".env"); err != nil {
+		c.fail(".env is missing", "cp .env.example .env and fill it in")
+		summary(c)
+	}
+	globals.SetupEnvironmentVariables()
+	config(c)
+
+	fmt.Println("Database")
+	connected := database(c)
+	if connected { schema(c) }
+
+	fmt.Println("Uploads")
+	uploads(c)
+
+	fmt.Println("Mail")
+	mail(c)
+
+	fmt.Println("Binaries")
+	binaries(c)
+
+	summary(c)
+}
+
+func config(c *check) {
+	required := map[string]string{
+		"Port": globals.Env.Port,
+		"Uploads": globals.Env.Uploads,
+		"DB_HOST": globals.Env.DB_HOST,
+		"DB_PORT": globals.Env.DB_PORT,
+		"DB_USER": globals.Env.DB_USER,
+		"DB_NAME": globals.Env.DB_NAME,
+		"SENDGRID_API_KEY": globals.Env.SENDGRID_API_KEY,
+	}
+	for _, key := range []string{"Port", "Uploads", "DB_HOST", "DB_PORT", "DB_USER", "DB_NAME", "SENDGRID_API_KEY"} {
+		if required[key] == "" { c.fail(key + " is not set", "set " + key + " in .env, see .env.example") } else { c.ok(key + " is set") }
+	}
+
+	if globals.Env.PageMaxSize <= 0 { c.warn("PageMaxSize is not a positive number, lists come back empty", "set PageMaxSize=20 in .env") }
+	if os.Getenv("COOKIE_SECRET") == "" && globals.Env.GOENV != "development" {
+		c.fail("COOKIE_SECRET is not set, the server doesn't start", "set COOKIE_SECRET to the output of: openssl rand -hex 32")
+	} else if os.Getenv("COOKIE_SECRET") == "" {
+		c.warn("COOKIE_SECRET is not set, signed cookies break on restart", "set COOKIE_SECRET to the output of: openssl rand -hex 32")
+	}
+	if globals.Env.PACKAGE_SECRET == "" { c.warn("PACKAGE_SECRET is not set, content packages are disabled", "set PACKAGE_SECRET, the same on every environment") }
+
+	if _, err := os.Stat(filepath.Join(globals.Env.Locales, globals.Env.DefaultLocale + ".json")); err != nil {
+		c.fail("No catalog for the default locale " + globals.Env.DefaultLocale, "add " + filepath.Join(globals.Env.Locales, globals.Env.DefaultLocale + ".json") + " or change DefaultLocale")
+	}
+}
+
+func database(c *check) bool {
+	storage.Connect(storage.Default())
+	if storage.DB == nil {
+		c.fail("Can't connect to " + globals.Env.DB_HOST + ":" + globals.Env.DB_PORT, "check DB_* in .env and that postgres is running")
+		return false
+	}
+
+	sql, err := storage.DB.DB()
+	if err == nil { err = sql.Ping() }
+	if err != nil {
+		c.fail("Database doesn't answer: " + err.Error(), "check DB_* in .env and that postgres is running")
+		return false
+	}
+
+	c.ok("Connected to " + globals.Env.DB_NAME)
+	return true
+}
+
+func schema(c *check) {
+	Migrations, err := migrations.Embedded()
+	var Versions []migrations.Status
+	if err == nil { Versions, err = migrations.States(storage.DB, Migrations) }
+	if err != nil {
+		c.fail("Can't read the migrations: " + err.Error(), "check the files of " + migrations.Directory)
+		return
+	}
+
+	Pending := 0
+	for _, State := range Versions {
+		switch State.State {
+			case migrations.Pending: Pending++
+			case migrations.Modified, migrations.Missing:
+				c.fail(fmt.Sprintf("Migration %04d_%s is %s", State.Version, State.Name, State.State), "restore its file as it was applied, a change is a new migration")
+		}
+	}
+	if Pending > 0 {
+		c.fail(fmt.Sprintf("%d migrations are pending", Pending), "make migrate")
+		return
+	}
+
+	/* Migrated, but the models want more: one changed without a migration */
+	Drifts, err := storage.Validate(storage.DB, append(model.Models, module.Models(server.Modules...)...)...)
+	if err != nil {
+		c.fail("Can't compare the models with the database: " + err.Error(), "check the models parse, go vet ./server/model")
+		return
+	}
+
+	if len(Drifts) > 0 {
+		c.fail(fmt.Sprintf("%d tables, columns or indexes are missing", len(Drifts)), "add a migration for the models changed: make migration name=...")
+		for _, Drift := range Drifts { fmt.Println("       ", Drift) }
+		return
+	}
+	c.ok("All tables are migrated")
+}
+
+func uploads(c *check) {
+	dir := "./public" + globals.Env.Uploads
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		c.fail(dir + " doesn't exist", "mkdir -p " + dir)
+		return
+	}
+
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		c.fail(dir + " isn't writable", "chmod -R 777 ./public, or chown it to the user running the app")
+		return
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	c.ok(dir + " is writable")
+
+	free, err := freeSpace(dir)
+	switch {
+		case err != nil: c.warn("Free space is unknown: " + err.Error(), "check the disk of " + dir + " by hand")
+		case free < MinFreeSpace: c.warn(fmt.Sprintf("Only %d MB free on the uploads' disk", free >> 20), "free up space or move Uploads to a bigger disk")
+		default: c.ok(fmt.Sprintf("%d MB free", free >> 20))
+	}
+}
+
+func mail(c *check) {
+	connection, err := net.DialTimeout("tcp", "api.sendgrid.com:443", 5 * time.Second)
+	if err != nil {
+		c.fail("SendGrid is unreachable: " + err.Error(), "check outgoing connections to api.sendgrid.com:443")
+		return
+	}
+	connection.Close()
+	c.ok("SendGrid is reachable")
+}
+
+func binaries(c *check) {
+	for binary, fix := range map[string]string{
+		"ffmpeg": "install ffmpeg (apt install ffmpeg / brew install ffmpeg), video uploads and IMAGE_FORMATS need it",
+		"ffprobe": "comes with ffmpeg, video thumbnails need it",
+		"templ": "go install github.com/a-h/templ/cmd/templ@latest",
+		"npx": "install node.js, tailwind is built with npx",
+	} {
+		if path, err := exec.LookPath(binary); err != nil { c.fail(binary + " is not installed", fix) } else { c.ok(binary + " → " + path) }
+	}
+}
+
+func summary(c *check) {
+	fmt.Printf("\n%d problems, %d warnings\n", c.failed, c.warned)
+	if c.failed > 0 { os.Exit(1) }
+	os.Exit(0)
+}
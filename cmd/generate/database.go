@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm/schema"
+
+	"main/server/common/storage"
+)
+
+/*
+	From the database: every table of the current schema is compared with its model,
+
+	  ✘ files: column legacy_path has no field
+	  ✘ files: field Thumbnail has no column, run make migrate
+
+	and tables no model declares get a stub to be reviewed and added to model.Models.
+*/
+
+type column struct {
+	Table		string
+	Name		string
+	Type		string
+}
+
+/* Initialisms the models spell in capitals */
+var initialisms = map[string]string{ "id": "ID", "ip": "IP", "url": "URL", "html": "HTML", "api": "API", "json": "JSON", "uuid": "UUID" }
+
+func compare(Schemas []*schema.Schema, check bool) {
+	storage.Connect(storage.Default())
+
+	var Columns []column
+	err := storage.DB.Raw(`
+		SELECT table_name AS "table", column_name AS "name", data_type AS "type"
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+		ORDER BY table_name, ordinal_position`).Scan(&Columns).Error
+	if err != nil { fail(err) }
+
+	Tables := map[string][]column{}
+	Order := []string{}
+	for _, Column := range Columns {
+		if _, seen := Tables[Column.Table]; !seen { Order = append(Order, Column.Table) }
+		Tables[Column.Table] = append(Tables[Column.Table], Column)
+	}
+
+	Known := map[string]*schema.Schema{}
+	Joins := map[string]bool{}
+	for _, Schema := range Schemas {
+		Known[Schema.Table] = Schema
+		for _, Relation := range Schema.Relationships.Many2Many { Joins[Relation.JoinTable.Table] = true }
+	}
+
+	Drift := 0
+	for _, Table := range Order {
+		Schema, ok := Known[Table]
+		switch {
+			case ok:
+				Drift += drift(Schema, Tables[Table])
+			case Joins[Table]:
+				continue
+			default:
+				Drift++
+				stub(Table, Tables[Table], check)
+		}
+	}
+	for _, Schema := range Schemas {
+		if _, ok := Tables[Schema.Table]; !ok {
+			Drift++
+			fmt.Printf("  ✘ %s: table is missing, run make migrate\n", Schema.Table)
+		}
+	}
+
+	if Drift == 0 { fmt.Println("  ✔ the database matches the models") }
+	if check && Drift > 0 { os.Exit(1) }
+}
+
+func drift(Schema *schema.Schema, Columns []column) int {
+	Drift := 0
+	Fields := map[string]bool{}
+	for _, Field := range Schema.Fields { if Field.DBName != "" { Fields[Field.DBName] = true } }
+
+	Existing := map[string]bool{}
+	for _, Column := range Columns {
+		Existing[Column.Name] = true
+		if Fields[Column.Name] { continue }
+		Drift++
+		fmt.Printf("  ✘ %s: column %s has no field\n", Schema.Table, Column.Name)
+	}
+	for _, Field := range Schema.Fields {
+		if Field.DBName == "" || Existing[Field.DBName] { continue }
+		Drift++
+		fmt.Printf("  ✘ %s: field %s has no column, run make migrate\n", Schema.Table, Field.Name)
+	}
+	return Drift
+}
+
+/* Stubs are never overwritten, once written they're the model to edit */
+func stub(Table string, Columns []column, check bool) {
+	path := filepath.Join(ModelsDir, Table + ".model.go")
+	if check {
+		fmt.Printf("  ✘ %s: table has no model\n", Table)
+		return
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("  ✘ %s: table has no model, %s exists already\n", Table, path)
+		return
+	}
+
+	var source strings.Builder
+	Model := map[string]bool{ "id": false, "created_at": false, "updated_at": false, "deleted_at": false }
+	for _, Column := range Columns { if _, ok := Model[Column.Name]; ok { Model[Column.Name] = true } }
+	Embedded := Model["id"] && Model["created_at"] && Model["updated_at"] && Model["deleted_at"]
+
+	Imports := []string{}
+	Fields := []string{}
+	if Embedded { Fields = append(Fields, "\tgorm.Model"); Imports = append(Imports, `"gorm.io/gorm"`) }
+	for _, Column := range Columns {
+		if _, ok := Model[Column.Name]; ok && Embedded { continue }
+
+		Type := goType(Column)
+		if strings.Contains(Type, "time.") && !contains(Imports, `"time"`) { Imports = append([]string{ `"time"` }, Imports...) }
+		Fields = append(Fields, fmt.Sprintf("\t%s\t%s", field(Column.Name), Type))
+	}
+
+	source.WriteString("package model\n\n")
+	if len(Imports) > 0 { source.WriteString("import (\n\t" + strings.Join(Imports, "\n\n\t") + "\n)\n\n") }
+	fmt.Fprintf(&source, "// %s was generated from the table by cmd/generate, review it and add it to model.Models.\n", name(Table))
+	fmt.Fprintf(&source, "type %s struct {\n%s\n}\n", name(Table), strings.Join(Fields, "\n"))
+
+	if err := os.WriteFile(path, []byte(source.String()), 0644); err != nil { fail(err) }
+	fmt.Printf("  ✘ %s: table has no model, wrote %s\n", Table, path)
+}
+
+/* Tables are named after the models: file_thumbnails is File_thumbnails */
+func name(Table string) string {
+	return strings.ToUpper(Table[:1]) + Table[1:]
+}
+
+/* Columns the way gorm names fields: file_id is FileID */
+func field(Column string) string {
+	var Field strings.Builder
+	for _, part := range strings.Split(Column, "_") {
+		if part == "" { continue }
+		if initialism, ok := initialisms[part]; ok { Field.WriteString(initialism); continue }
+		Field.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return Field.String()
+}
+
+/* gorm makes most columns nullable, the stubs use plain types as the models do */
+func goType(Column column) string {
+	switch Column.Type {
+		case "smallint", "integer":
+			return "int"
+		case "bigint":
+			return "int64"
+		case "real", "double precision", "numeric":
+			return "float64"
+		case "boolean":
+			return "bool"
+		case "date", "timestamp with time zone", "timestamp without time zone":
+			return "time.Time"
+		case "bytea":
+			return "[]byte"
+	}
+	return "string"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values { if v == value { return true } }
+	return false
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm/schema"
+
+	"main/server"
+	"main/server/common/globals"
+	"main/server/common/module"
+	"main/server/model"
+)
+
+/*
+	Generates code from the models' schema so it can't drift from them:
+
+	go run ./cmd/generate               writes the column constants (server/model/columns.gen.go)
+	                                    and the repositories (server/common/repository/repositories.gen.go)
+	go run ./cmd/generate -check        fails when those are out of date instead of writing them
+	go run ./cmd/generate -from db      compares the live database with the models and writes
+	                                    a model stub (server/model/<table>.model.go) for every table without one
+
+	The models are the ones cmd/migrate migrates, model.Models and the modules' own.
+*/
+
+const (
+	ColumnsFile = "server/model/columns.gen.go"
+	RepositoriesFile = "server/common/repository/repositories.gen.go"
+	ModelsDir = "server/model"
+)
+
+const Header = "// Code generated by cmd/generate from the models. DO NOT EDIT.\n\n"
+
+func main() {
+	from := flag.String("from", "models", "models: generate code from the models, db: compare the database with the models")
+	check := flag.Bool("check", false, "only report what's out of date, exit 1 when something is")
+	flag.Parse()
+
+	globals.SetupEnvironmentVariables()
+	Schemas, err := schemas(append(model.Models, module.Models(server.Modules...)...))
+	if err != nil { fail(err) }
+
+	switch *from {
+		case "models":
+			generate(Schemas, *check)
+		case "db":
+			compare(Schemas, *check)
+		default:
+			fail(fmt.Errorf("unknown -from %q, models or db", *from))
+	}
+}
+
+/* Parsed the way gorm does when migrating, with the default naming */
+func schemas(models []any) ([]*schema.Schema, error) {
+	cache := &sync.Map{}
+	Schemas := []*schema.Schema{}
+	for _, model := range models {
+		Schema, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		if err != nil { return nil, fmt.Errorf("parsing %T: %w", model, err) }
+		Schemas = append(Schemas, Schema)
+	}
+	sort.Slice(Schemas, func(i, j int) bool { return Schemas[i].Name < Schemas[j].Name })
+	return Schemas, nil
+}
+
+func generate(Schemas []*schema.Schema, check bool) {
+	Outdated := 0
+	for path, source := range map[string][]byte{
+		ColumnsFile: columns(Schemas),
+		RepositoriesFile: repositories(Schemas),
+	} {
+		formatted, err := format.Source(source)
+		if err != nil { fail(fmt.Errorf("formatting %s: %w", path, err)) }
+
+		current, _ := os.ReadFile(path)
+		if bytes.Equal(current, formatted) { continue }
+
+		if check {
+			Outdated++
+			fmt.Println("  ✘", path, "is out of date, run make generate")
+			continue
+		}
+		if err := os.WriteFile(path, formatted, 0644); err != nil { fail(err) }
+		fmt.Println("  ✔", path)
+	}
+	if Outdated > 0 { os.Exit(1) }
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
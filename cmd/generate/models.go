@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+/*
+	From the models:
+
+	const (
+		FilesTable = "files"
+		FilesID = "id"
+		FilesPath = "path"
+		...
+	)
+
+	type RolesRepository struct { Repository[model.Roles] }
+	var Roles = RolesRepository{}
+	func (repository RolesRepository) FindByName(ctx context.Context, Name string) (model.Roles, error)
+*/
+
+func columns(Schemas []*schema.Schema) []byte {
+	var source strings.Builder
+	source.WriteString(Header + "package model\n")
+
+	for _, Schema := range Schemas {
+		fmt.Fprintf(&source, "\n// %s columns (table %s).\nconst (\n", Schema.Name, Schema.Table)
+		fmt.Fprintf(&source, "\t%sTable = %q\n", Schema.Name, Schema.Table)
+		for _, Field := range Schema.Fields {
+			if Field.DBName == "" { continue }
+			fmt.Fprintf(&source, "\t%s%s = %q\n", Schema.Name, Field.Name, Field.DBName)
+		}
+		source.WriteString(")\n")
+	}
+	return []byte(source.String())
+}
+
+func repositories(Schemas []*schema.Schema) []byte {
+	var source strings.Builder
+	source.WriteString(Header + "package repository\n\nimport (\n\t\"context\"\n\n\t\"gorm.io/gorm\"\n\n\t\"main/server/model\"\n)\n")
+
+	for _, Schema := range Schemas {
+		name := Schema.Name + "Repository"
+		fmt.Fprintf(&source, "\n// %s is the data access of model.%s.\ntype %s struct { Repository[model.%s] }\n\n", name, Schema.Name, name, Schema.Name)
+		fmt.Fprintf(&source, "var %s = %s{}\n\n", Schema.Name, name)
+		fmt.Fprintf(&source, "// With returns the repository working on the given connection, usually a transaction.\n")
+		fmt.Fprintf(&source, "func (repository %s) With(db *gorm.DB) %s { return %s{ repository.Repository.With(db) } }\n", name, name, name)
+
+		for _, Field := range unique(Schema) {
+			fmt.Fprintf(&source, "\n// FindBy%s returns the %s of the %s.\n", Field.Name, Schema.Name, Field.DBName)
+			fmt.Fprintf(&source, "func (repository %s) FindBy%s(ctx context.Context, %s %s) (model.%s, error) {\n", name, Field.Name, Field.Name, Field.FieldType, Schema.Name)
+			fmt.Fprintf(&source, "\treturn repository.FindBy(ctx, model.%s%s, %s)\n}\n", Schema.Name, Field.Name, Field.Name)
+		}
+	}
+	return []byte(source.String())
+}
+
+/* Columns unique on their own, other than the primary key (Find covers it), of types which need no import */
+func unique(Schema *schema.Schema) []*schema.Field {
+	Unique := map[string]bool{}
+	for _, Field := range Schema.Fields { if Field.Unique { Unique[Field.Name] = true } }
+	for _, Index := range Schema.ParseIndexes() {
+		if Index.Class == "UNIQUE" && len(Index.Fields) == 1 { Unique[Index.Fields[0].Name] = true }
+	}
+
+	Fields := []*schema.Field{}
+	for _, Field := range Schema.Fields {
+		if !Unique[Field.Name] || Field.PrimaryKey || Field.DBName == "" { continue }
+		if Field.FieldType.PkgPath() != "" || Field.FieldType.Kind() > reflect.Float64 && Field.FieldType.Kind() != reflect.String { continue }
+		Fields = append(Fields, Field)
+	}
+	return Fields
+}
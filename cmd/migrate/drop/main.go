@@ -1,14 +1,20 @@
 package main
 
 import (
-	"main/cmd/migrate/migration"
+	"main/server"
 	"main/server/common/globals"
+	"main/server/common/migrations"
+	"main/server/common/module"
 	"main/server/common/storage"
+	"main/server/model"
 )
 
 func main() {
 	globals.SetupEnvironmentVariables()
 	storage.Connect(storage.Default())
 	
-	storage.DB.Migrator().DropTable(migration.Models...)
+	storage.DB.Migrator().DropTable(append(model.Models, module.Models(server.Modules...)...)...)
+
+	/* Nothing is migrated anymore, the next migrate starts from the baseline */
+	storage.DB.Migrator().DropTable(&migrations.Record{})
 }
\ No newline at end of file
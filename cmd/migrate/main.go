@@ -1,14 +1,31 @@
 package main
 
 import (
-	"main/cmd/migrate/migration"
+	"log"
+	"os"
+
+	"main/server"
 	"main/server/common/globals"
+	"main/server/common/migrations"
+	"main/server/common/module"
 	"main/server/common/storage"
+	"main/server/model"
 )
 
+/*
+	Migrates the database with the versioned migrations of package migrations:
+
+	go run ./cmd/migrate                 # up
+	go run ./cmd/migrate down -steps 2
+	go run ./cmd/migrate status
+	go run ./cmd/migrate new product_sku
+
+	The built app runs them too, as "app migrate up".
+*/
 func main() {
 	globals.SetupEnvironmentVariables()
 	storage.Connect(storage.Default())
-	
-	storage.DB.Migrator().AutoMigrate(migration.Models...)
+
+	Models := append(model.Models, module.Models(server.Modules...)...)
+	if err := migrations.Command(storage.DB, os.Args[1:], Models); err != nil { log.Fatal(err) }
 }
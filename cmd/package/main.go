@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/service/packager"
+)
+
+/*
+	Moves content between environments:
+
+	go run ./cmd/package export -kinds news,faq -out content.zip
+	go run ./cmd/package import -in content.zip -conflict skip|overwrite|duplicate
+*/
+func main() {
+	if len(os.Args) < 2 { log.Fatal("usage: package export|import [flags]") }
+
+	globals.SetupEnvironmentVariables()
+	if globals.Env.PACKAGE_SECRET == "" { log.Fatal("PACKAGE_SECRET is not set") }
+	storage.Connect(storage.Default())
+
+	switch os.Args[1] {
+		case "export": export(os.Args[2:])
+		case "import": load(os.Args[2:])
+		default: log.Fatal("usage: package export|import [flags]")
+	}
+}
+
+func export(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	kinds := flags.String("kinds", strings.Join(packager.Kinds, ","), "comma separated kinds: " + strings.Join(packager.Kinds, ","))
+	out := flags.String("out", "content.zip", "archive to write")
+	flags.Parse(args)
+
+	file, err := os.Create(*out)
+	if err != nil { log.Fatal(err) }
+	defer file.Close()
+
+	if err := packager.Export(file, strings.Split(*kinds, ",")); err != nil { log.Fatal(err) }
+	fmt.Println("Exported", *kinds, "into", *out)
+}
+
+func load(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	in := flags.String("in", "content.zip", "archive to import")
+	conflict := flags.String("conflict", string(packager.Skip), "what to do with records already present: skip, overwrite or duplicate")
+	flags.Parse(args)
+
+	file, err := os.Open(*in)
+	if err != nil { log.Fatal(err) }
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil { log.Fatal(err) }
+
+	Results, err := packager.Import(file, info.Size(), packager.Conflict(*conflict))
+	if err != nil { log.Fatal(err) }
+
+	for _, Result := range Results {
+		fmt.Printf("%-14s %-10s %6d -> %-6d %s\n", Result.Kind, Result.Action, Result.OldID, Result.NewID, Result.Key)
+	}
+}
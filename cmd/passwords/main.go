@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/auth"
+)
+
+/*
+	Reports how the users' passwords are hashed, the ones not current (bcrypt, other argon2 parameters, the previous
+	pepper) are upgraded as their users sign in:
+
+	go run ./cmd/passwords
+	go run ./cmd/passwords -list
+
+	Before PASSWORD_PEPPER_PREVIOUS is dropped, "pepper" should be down to 0: the passwords hashed with it can't be
+	checked anymore once it's gone, their users have to reset them.
+*/
+
+func main() {
+	list := flag.Bool("list", false, "list the users whose password hash isn't current")
+	flag.Parse()
+
+	globals.SetupEnvironmentVariables()
+	storage.Connect(storage.Default())
+
+	Report, err := auth.PasswordReport()
+	if err != nil { log.Fatal(err) }
+
+	Total := 0
+	for _, Scheme := range []string{ auth.SchemeCurrent, auth.SchemeParameters, auth.SchemePepper, auth.SchemeBcrypt, auth.SchemeUnknown } {
+		fmt.Printf("%-12s %8d\n", Scheme, Report[Scheme])
+		Total += Report[Scheme]
+	}
+	fmt.Printf("%-12s %8d\n", "total", Total)
+	if !*list { return }
+
+	var Users []model.Users
+	if err := storage.DB.Where("password <> ''").Find(&Users).Error; err != nil { log.Fatal(err) }
+	fmt.Println()
+	for _, User := range Users {
+		if Scheme := auth.Scheme(User.Password); Scheme != auth.SchemeCurrent { fmt.Printf("%-12s %6d  %s\n", Scheme, User.ID, User.Email) }
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/service/rbac"
+)
+
+/*
+	Exports the access-control setup (permissions, roles and the users who have them) to a file, and imports one, see
+	package rbac. The format is the file's: .json is JSON, anything else YAML.
+
+	go run ./cmd/rbac -export rbac.yaml
+	go run ./cmd/rbac -import rbac.yaml                  # only shows what would change
+	go run ./cmd/rbac -import rbac.yaml -apply
+	go run ./cmd/rbac -import rbac.yaml -apply -prune    # the file is the whole setup, what it leaves out is removed
+
+	An import which can't be applied (a role granting a permission which isn't defined, nobody left with the admin
+	role, ...) tells why and exits with 1, so a pull request changing the file can be checked against an environment.
+*/
+func main() {
+	export := flag.String("export", "", "file to export the setup to, - for the standard output")
+	imported := flag.String("import", "", "file to import the setup from")
+	apply := flag.Bool("apply", false, "apply the import, without it the changes are only shown")
+	prune := flag.Bool("prune", false, "remove the permissions and roles the file leaves out, and the roles of the users it leaves out")
+	flag.Parse()
+
+	if (*export == "") == (*imported == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	globals.SetupEnvironmentVariables()
+	storage.Connect(storage.Default())
+
+	if *export != "" {
+		Setup, err := rbac.Export()
+		if err != nil { log.Fatal(err) }
+		Content, err := rbac.Marshal(Setup, rbac.FormatOf(*export))
+		if err != nil { log.Fatal(err) }
+
+		if *export == "-" {
+			os.Stdout.Write(Content)
+			return
+		}
+		if err := os.WriteFile(*export, Content, 0644); err != nil { log.Fatal(err) }
+		fmt.Printf("Exported %d permissions, %d roles and %d assignments to %s\n", len(Setup.Permissions), len(Setup.Roles), len(Setup.Assignments), *export)
+		return
+	}
+
+	Content, err := os.ReadFile(*imported)
+	if err != nil { log.Fatal(err) }
+	Setup, err := rbac.Unmarshal(Content, rbac.FormatOf(*imported))
+	if err != nil { log.Fatal(err) }
+
+	Plan := rbac.Plan
+	if *apply { Plan = rbac.Apply }
+	Diff, err := Plan(Setup, *prune)
+
+	for _, Change := range Diff.Changes { fmt.Println(Change) }
+	for _, User := range Diff.Skipped { fmt.Println("  skipped user", User, "- not in this database") }
+	if len(Diff.Problems) > 0 {
+		fmt.Println()
+		for _, Problem := range Diff.Problems { fmt.Println("✘", Problem) }
+	}
+	if err != nil { log.Fatal(err) }
+
+	switch {
+		case len(Diff.Changes) == 0: fmt.Println("Nothing to change")
+		case *apply: fmt.Printf("Applied %d changes\n", len(Diff.Changes))
+		default: fmt.Printf("%d changes, run again with -apply to make them\n", len(Diff.Changes))
+	}
+}
@@ -10,81 +10,113 @@ var Type = []model.File_types{
 		Name:     "Archives/Rar",
 		Ext:      "rar",
 		Max_size: 10 * 1024 * 1024, // 10MB
+		Mimes:    "application/vnd.rar,application/x-rar-compressed",
+		Enabled:  true,
 	},
 	{
 		Name:     "Image/Jpeg",
 		Ext:      "jpeg",
 		Max_size: 10 * 1024 * 1024, // 10MB
+		Mimes:    "image/jpeg",
+		Enabled:  true,
 	},
 	{
 		Name:     "Image/Jpg",
 		Ext:      "jpg",
 		Max_size: 10 * 1024 * 1024, // 10MB
+		Mimes:    "image/jpeg",
+		Enabled:  true,
 	},
 	{
 		Name:     "Image/Png",
 		Ext:      "png",
 		Max_size: 10 * 1024 * 1024, // 10MB
+		Mimes:    "image/png",
+		Enabled:  true,
 	},
 	{
 		Name:     "Image/Gif",
 		Ext:      "gif",
 		Max_size: 10 * 1024 * 1024, // 10MB
+		Mimes:    "image/gif",
+		Enabled:  true,
 	},
 	{
 		Name:     "Video/Mov",
 		Ext:      "mov",
 		Max_size: 200 * 1024 * 1024, // 200MB
+		Mimes:    "video/quicktime",
+		Enabled:  true,
 	},
 	{
 		Name:     "Video",
 		Ext:      "mp4",
 		Max_size: 100 * 1024 * 1024, // 100MB
+		Mimes:    "video/mp4",
+		Enabled:  true,
 	},
 	{
 		Name:     "PDF",
 		Ext:      "pdf",
 		Max_size: 20 * 1024 * 1024, // 20MB
+		Mimes:    "application/pdf",
+		Enabled:  true,
 	},
 	{
 		Name:     "Text Document",
 		Ext:      "txt",
 		Max_size: 10 * 1024 * 1024, // 10MB
+		Mimes:    "text/plain",
+		Enabled:  true,
 	},
 	{
 		Name:     "Document",
 		Ext:      "docx",
 		Max_size: 10 * 1024 * 1024, // 10MB
+		Mimes:    "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		Enabled:  true,
 	},
 	{
 		Name:     "Spreadsheet",
 		Ext:      "xlsx",
 		Max_size: 10 * 1024 * 1024, // 10MB
+		Mimes:    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		Enabled:  true,
 	},
 	{
 		Name:     "Presentation",
 		Ext:      "pptx",
 		Max_size: 20 * 1024 * 1024, // 20MB
+		Mimes:    "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		Enabled:  true,
 	},
 	{
 		Name:     "Audio",
 		Ext:      "mp3",
 		Max_size: 50 * 1024 * 1024, // 50MB
+		Mimes:    "audio/mpeg",
+		Enabled:  true,
 	},
 	{
 		Name:     "Executable",
 		Ext:      "exe",
 		Max_size: 100 * 1024 * 1024, // 100MB
+		Mimes:    "application/vnd.microsoft.portable-executable,application/x-msdownload",
+		Enabled:  true,
 	},
 	{
 		Name:     "Archive",
 		Ext:      "zip",
 		Max_size: 100 * 1024 * 1024, // 100MB
+		Mimes:    "application/zip",
+		Enabled:  true,
 	},
 	{
 		Name:     "Font",
 		Ext:      "ttf",
 		Max_size: 1 * 1024 * 1024, // 1MB
+		Mimes:    "font/ttf",
+		Enabled:  true,
 	},
 } 
 
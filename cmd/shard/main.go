@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"main/server/common/globals"
+	uploader "main/server/common/helpers"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+/*
+	Moves the uploads stored flat in the uploads folder, as they were before uploads were sharded, to their sharded
+	paths (ab/cd/abcd….ext) and updates the rows storing them, see uploader.Relocate:
+
+	go run ./cmd/shard -dry
+	go run ./cmd/shard
+
+	It can be stopped and run again, moved uploads are skipped.
+*/
+func main() {
+	dry := flag.Bool("dry", false, "only count the uploads to move")
+	flag.Parse()
+
+	globals.SetupEnvironmentVariables()
+	storage.Connect(storage.Default())
+
+	/* Versions may store a content no file stores anymore */
+	var Paths []string
+	Flat := globals.Env.Uploads + "%"
+	Nested := globals.Env.Uploads + "%/%"
+	if err := storage.DB.Unscoped().Model(&model.Files{}).Where("path LIKE ? AND path NOT LIKE ?", Flat, Nested).Distinct().Pluck("path", &Paths).Error; err != nil { log.Fatal(err) }
+	var Versions []string
+	if err := storage.DB.Unscoped().Model(&model.File_versions{}).Where("path LIKE ? AND path NOT LIKE ?", Flat, Nested).Distinct().Pluck("path", &Versions).Error; err != nil { log.Fatal(err) }
+	Unique := map[string]bool{}
+	for _, Path := range append(Paths, Versions...) { Unique[Path] = true }
+
+	if *dry {
+		fmt.Println(len(Unique), "uploads are stored flat in", globals.Env.Uploads)
+		return
+	}
+
+	Moved, Failed := 0, 0
+	for Path := range Unique {
+		Target, err := uploader.Relocate(context.Background(), Path)
+		switch {
+			case err != nil:
+				Failed++
+				log.Print("Moving ", Path, ": ", err)
+			case Target != Path:
+				Moved++
+				fmt.Println(Path, "→", Target)
+		}
+	}
+
+	fmt.Println("Moved", Moved, "uploads,", Failed, "failed")
+	if Failed > 0 { log.Fatal("Run it again to move the uploads which failed") }
+}
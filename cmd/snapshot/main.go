@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/service/snapshot"
+)
+
+/*
+	Makes an anonymized snapshot of the database for developers, see package snapshot:
+
+	go run ./cmd/snapshot -out snapshot.zip
+	go run ./cmd/snapshot -out snapshot.zip -seed "same as last time"
+
+	Loaded into a development database with:
+
+	go run ./cmd/migrate
+	unzip snapshot.zip && psql -d yacco -f snapshot.sql
+*/
+func main() {
+	out := flag.String("out", "snapshot.zip", "archive to write")
+	seed := flag.String("seed", "", "fakes values the same way as the snapshots made with it, random when empty")
+	flag.Parse()
+
+	globals.SetupEnvironmentVariables()
+	storage.Connect(storage.Default())
+
+	/* A random seed can't be guessed, so the fakes can't be matched back to real addresses */
+	Seed := []byte(*seed)
+	if len(Seed) == 0 {
+		Seed = make([]byte, 32)
+		if _, err := rand.Read(Seed); err != nil { log.Fatal(err) }
+	}
+
+	file, err := os.Create(*out)
+	if err != nil { log.Fatal(err) }
+	defer file.Close()
+
+	if err := snapshot.Write(context.Background(), file, Seed); err != nil {
+		os.Remove(*out)
+		log.Fatal(err)
+	}
+	fmt.Println("Wrote", *out, "- users sign in with their anonymized email and the password", snapshot.Password)
+}
@@ -0,0 +1,82 @@
+// Package bandwidth carries the low-bandwidth mode on the request context, so the media templ helpers (InlineImage,
+// ResponsiveImage, Video) can send smaller images, lazy load every one of them and leave videos alone until they're
+// played.
+//
+// The mode is the visitor's choice when they made one (the "lite" query parameter, remembered in the Cookie), the
+// browser's "Save-Data: on" header otherwise, and the site's setting (Interface.LowBandwidth) last.
+package bandwidth
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Cookie remembers a visitor's choice, On or Off.
+const Cookie = "bandwidth"
+
+const (
+	On	= "on"
+	Off	= "off"
+)
+
+// MinWidth is the narrowest image variant the low-bandwidth mode asks for.
+const MinWidth = 160
+
+/* The site's setting, loaded with the Interface on full page loads and set by the settings tab */
+var site, known atomic.Bool
+
+type lowKey struct{}
+
+// SetSite sets the site's default mode.
+func SetSite(Low bool) {
+	site.Store(Low)
+	known.Store(true)
+}
+
+// Known reports whether the site's mode was set since the app started.
+func Known() bool {
+	return known.Load()
+}
+
+// Site returns the site's default mode.
+func Site() bool {
+	return site.Load()
+}
+
+// Resolve decides the mode of a request from the visitor's Choice (On, Off, "" without one) and its Save-Data header.
+func Resolve(Choice string, SaveData string) bool {
+	switch Choice {
+		case On: return true
+		case Off: return false
+	}
+	return SaveData == "on" || Site()
+}
+
+func WithLow(parent context.Context, Low bool) context.Context {
+	return context.WithValue(parent, lowKey{}, Low)
+}
+
+// Low reports whether the request is in low-bandwidth mode.
+func Low(ctx context.Context) bool {
+	Low, _ := ctx.Value(lowKey{}).(bool)
+	return Low
+}
+
+// Width returns the width of the image variant to send for an image shown Width wide, half of it in low-bandwidth mode.
+func Width(ctx context.Context, Width int) int {
+	if !Low(ctx) || Width <= MinWidth { return Width }
+	return max(MinWidth, Width / 2)
+}
+
+// Loading returns an img's loading attribute. Eager images (the first screen's) load right away, unless in
+// low-bandwidth mode, which loads nothing before it's scrolled to.
+func Loading(ctx context.Context, Eager bool) string {
+	if Eager && !Low(ctx) { return "eager" }
+	return "lazy"
+}
+
+// Priority returns an img's fetchpriority attribute, low-bandwidth mode lets the page's text and scripts go first.
+func Priority(ctx context.Context) string {
+	if Low(ctx) { return "low" }
+	return "auto"
+}
@@ -0,0 +1,96 @@
+// Package blob abstracts where uploaded files are stored.
+//
+// Keys are the file's path without the leading slash ("uploads/3f2a....png"), the same for every backend,
+// so switching STORAGE_BACKEND only needs the files copied over.
+//
+//   local   files under ./public, served by the static handler as well (default)
+//   s3      any S3 compatible service (AWS, MinIO, ...), configured by the S3_* variables
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"main/server/common/domain"
+	"main/server/common/globals"
+)
+
+var (
+	ErrNotFound = domain.NotFound("blob not found")
+	ErrUnsupported = errors.New("not supported by the storage backend")
+)
+
+// Object describes a stored blob.
+type Object struct {
+	Size			int64
+	ModTime			time.Time
+	ContentType		string
+}
+
+type Backend interface {
+	// Put stores the reader's content, size is -1 when unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns a reader of the blob, an io.ReadSeeker when the backend can seek.
+	Open(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a url the client can fetch the blob from directly, ErrUnsupported when there is none.
+	SignedURL(key string, expires time.Duration) (string, error)
+	// URL returns the blob's permanent public url, ErrUnsupported when it isn't public.
+	URL(key string) (string, error)
+}
+
+// Adopter is a Backend which can take a local file over as a blob without copying it, as the local backend does
+// with a rename. The file is gone from its path once adopted, handles opened on it still read it.
+type Adopter interface {
+	Adopt(ctx context.Context, key string, path string) error
+}
+
+// Lister is a Backend which can list the keys of its blobs starting with a prefix, e.g. the cached copies of a file.
+type Lister interface {
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// PublicRoot is the directory of the local backend, served as is by the web server.
+const PublicRoot = "./public"
+
+var (
+	once sync.Once
+	backend Backend
+)
+
+// Default returns the backend chosen by globals.Env.STORAGE_BACKEND.
+func Default() Backend {
+	once.Do(func() {
+		switch globals.Env.STORAGE_BACKEND {
+			case "s3":
+				backend = &S3{
+					Endpoint: globals.Env.S3_ENDPOINT,
+					Region: globals.Env.S3_REGION,
+					Bucket: globals.Env.S3_BUCKET,
+					AccessKey: globals.Env.S3_ACCESS_KEY,
+					SecretKey: globals.Env.S3_SECRET_KEY,
+					Prefix: globals.Env.S3_PREFIX,
+					PublicURL: globals.Env.S3_PUBLIC_URL,
+				}
+			default:
+				backend = &Local{ Root: PublicRoot }
+		}
+	})
+	return backend
+}
+
+// Use makes the backend the one Default returns, in place of STORAGE_BACKEND's, e.g. a Local one in a temporary
+// directory for benchmarks.
+func Use(Backend Backend) {
+	once.Do(func() {})
+	backend = Backend
+}
+
+// Key turns a file path ("/uploads/x.png") into a blob key.
+func Key(path string) string {
+	return strings.TrimLeft(path, "/")
+}
@@ -0,0 +1,102 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Local stores blobs as files under Root.
+type Local struct {
+	Root		string
+}
+
+func (local *Local) path(key string) string {
+	return filepath.Join(local.Root, filepath.Clean("/" + key))
+}
+
+/* The content is written next to the blob and renamed over it, so the static handler never serves a partial file
+   and two uploads of the same content don't write into one another */
+func (local *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path := local.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { return err }
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".put-*")
+	if err != nil { return err }
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil { return err }
+	if err := os.Chmod(file.Name(), 0644); err != nil { return err }
+	return os.Rename(file.Name(), path)
+}
+
+// Adopt moves a local file in as the blob, it's copied when it lives on another file system.
+func (local *Local) Adopt(ctx context.Context, key string, path string) error {
+	target := local.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil { return err }
+	if err := os.Chmod(path, 0644); err != nil { return err }
+	if err := os.Rename(path, target); err == nil { return nil }
+
+	file, err := os.Open(path)
+	if err != nil { return err }
+	defer file.Close()
+	if err := local.Put(ctx, key, file, -1, ""); err != nil { return err }
+	return os.Remove(path)
+}
+
+func (local *Local) Open(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	file, err := os.Open(local.path(key))
+	if errors.Is(err, os.ErrNotExist) { return nil, Object{}, ErrNotFound }
+	if err != nil { return nil, Object{}, err }
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, Object{}, ErrNotFound
+	}
+
+	return file, Object{ Size: info.Size(), ModTime: info.ModTime(), ContentType: mime.TypeByExtension(filepath.Ext(key)) }, nil
+}
+
+func (local *Local) Delete(ctx context.Context, key string) error {
+	err := os.Remove(local.path(key))
+	if errors.Is(err, os.ErrNotExist) { return nil }
+	return err
+}
+
+func (local *Local) List(ctx context.Context, prefix string) ([]string, error) {
+	prefix = Key(prefix)
+	dir := filepath.Dir(local.path(prefix + "_"))
+
+	var keys []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() { return err }
+		relative, err := filepath.Rel(local.Root, path)
+		if err != nil { return err }
+		if key := filepath.ToSlash(relative); strings.HasPrefix(key, prefix) { keys = append(keys, key) }
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) { return nil, nil }
+	return keys, err
+}
+
+/* Local files are public static files, there is nothing to sign */
+func (local *Local) SignedURL(key string, expires time.Duration) (string, error) {
+	return "", ErrUnsupported
+}
+
+/* The static handler serves ./public at the site's root, but without the file's name nor its guards: downloads
+   go through the app */
+func (local *Local) URL(key string) (string, error) {
+	return "", ErrUnsupported
+}
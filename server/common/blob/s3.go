@@ -0,0 +1,248 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PartSize is the size of the parts large blobs are uploaded in, blobs up to it (of a known size) take a single request.
+const PartSize = 16 << 20
+
+// S3 stores blobs in a bucket of an S3 compatible service (AWS, MinIO, ...), addressed path-style (endpoint/bucket/key).
+// Every request is made through a presigned url (AWS Signature Version 4), so no SDK is needed.
+type S3 struct {
+	Endpoint		string
+	Region			string
+	Bucket			string
+	AccessKey		string
+	SecretKey		string
+	// Prefix is prepended to every key, so several sites can share a bucket ("yacco/uploads/x.png").
+	Prefix			string
+	// PublicURL is where the bucket is readable without signing (a public bucket, a CDN), empty when it isn't.
+	PublicURL		string
+}
+
+// Put stores the blob in a single request, or as a multipart upload when it's larger than PartSize or of unknown size.
+func (s3 *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size < 0 || size > PartSize { return s3.multipart(ctx, key, r, contentType) }
+
+	response, err := s3.do(ctx, http.MethodPut, key, nil, r, size, contentType)
+	if err != nil { return err }
+	response.Body.Close()
+	return nil
+}
+
+func (s3 *S3) Open(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	response, err := s3.do(ctx, http.MethodGet, key, nil, nil, 0, "")
+	if err != nil { return nil, Object{}, err }
+
+	modified, _ := http.ParseTime(response.Header.Get("Last-Modified"))
+	return response.Body, Object{
+		Size: response.ContentLength,
+		ModTime: modified,
+		ContentType: response.Header.Get("Content-Type"),
+	}, nil
+}
+
+func (s3 *S3) Delete(ctx context.Context, key string) error {
+	response, err := s3.do(ctx, http.MethodDelete, key, nil, nil, 0, "")
+	if err == ErrNotFound { return nil }
+	if err != nil { return err }
+	response.Body.Close()
+	return nil
+}
+
+func (s3 *S3) SignedURL(key string, expires time.Duration) (string, error) {
+	return s3.presign(http.MethodGet, key, nil, expires, time.Now())
+}
+
+// URL returns the blob's url under PublicURL, ErrUnsupported when the bucket isn't public.
+func (s3 *S3) URL(key string) (string, error) {
+	if s3.PublicURL == "" { return "", ErrUnsupported }
+	return strings.TrimRight(s3.PublicURL, "/") + "/" + encodePath(s3.object(key)), nil
+}
+
+// List pages through the bucket's objects (ListObjectsV2), the keys are returned without the Prefix.
+func (s3 *S3) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	params := map[string]string{ "list-type": "2", "prefix": s3.object(prefix) }
+	for {
+		response, err := s3.do(ctx, http.MethodGet, "", params, nil, 0, "")
+		if err != nil { return nil, err }
+
+		var listed struct {
+			Contents				[]struct{ Key string }
+			IsTruncated				bool
+			NextContinuationToken	string
+		}
+		err = xml.NewDecoder(response.Body).Decode(&listed)
+		response.Body.Close()
+		if err != nil { return nil, fmt.Errorf("s3 list %s: %w", prefix, err) }
+
+		for _, object := range listed.Contents { keys = append(keys, strings.TrimPrefix(object.Key, s3.object(""))) }
+		if !listed.IsTruncated { return keys, nil }
+		params["continuation-token"] = listed.NextContinuationToken
+	}
+}
+
+/* The object's key in the bucket, with the Prefix */
+func (s3 *S3) object(key string) string {
+	if prefix := strings.Trim(s3.Prefix, "/"); prefix != "" { return prefix + "/" + Key(key) }
+	return Key(key)
+}
+
+/* https://docs.aws.amazon.com/AmazonS3/latest/userguide/mpuoverview.html, a failed upload is aborted so its parts don't linger */
+func (s3 *S3) multipart(ctx context.Context, key string, r io.Reader, contentType string) error {
+	response, err := s3.do(ctx, http.MethodPost, key, map[string]string{ "uploads": "" }, nil, 0, contentType)
+	if err != nil { return err }
+
+	var created struct{ UploadId string }
+	err = xml.NewDecoder(response.Body).Decode(&created)
+	response.Body.Close()
+	if err != nil { return fmt.Errorf("s3 multipart %s: %w", key, err) }
+
+	upload := map[string]string{ "uploadId": created.UploadId }
+	abort := func(err error) error {
+		if response, abortErr := s3.do(context.WithoutCancel(ctx), http.MethodDelete, key, upload, nil, 0, ""); abortErr == nil { response.Body.Close() }
+		return err
+	}
+
+	type part struct {
+		PartNumber		int
+		ETag			string
+	}
+	var completed struct {
+		XMLName			xml.Name	`xml:"CompleteMultipartUpload"`
+		Parts			[]part		`xml:"Part"`
+	}
+
+	buffer := make([]byte, PartSize)
+	for number := 1; ; number++ {
+		read, err := io.ReadFull(r, buffer)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) { return abort(err) }
+		if read == 0 && number > 1 { break }
+
+		query := map[string]string{ "partNumber": strconv.Itoa(number), "uploadId": created.UploadId }
+		response, putErr := s3.do(ctx, http.MethodPut, key, query, bytes.NewReader(buffer[:read]), int64(read), "")
+		if putErr != nil { return abort(putErr) }
+		response.Body.Close()
+
+		completed.Parts = append(completed.Parts, part{ PartNumber: number, ETag: response.Header.Get("ETag") })
+		if read < PartSize { break }
+	}
+
+	body, err := xml.Marshal(completed)
+	if err != nil { return abort(err) }
+
+	response, err = s3.do(ctx, http.MethodPost, key, upload, bytes.NewReader(body), int64(len(body)), "application/xml")
+	if err != nil { return abort(err) }
+	defer response.Body.Close()
+
+	/* S3 may answer 200 with an error document when completing fails midway */
+	answer, _ := io.ReadAll(io.LimitReader(response.Body, 1 << 16))
+	if bytes.Contains(answer, []byte("<Error>")) { return abort(fmt.Errorf("s3 multipart %s: %s", key, answer)) }
+	return nil
+}
+
+func (s3 *S3) do(ctx context.Context, method string, key string, params map[string]string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	signed, err := s3.presign(method, key, params, 15 * time.Minute, time.Now())
+	if err != nil { return nil, err }
+
+	request, err := http.NewRequestWithContext(ctx, method, signed, body)
+	if err != nil { return nil, err }
+	if body != nil { request.ContentLength = size }
+	if contentType != "" { request.Header.Set("Content-Type", contentType) }
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil { return nil, err }
+
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, ErrNotFound
+	}
+	if response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		response.Body.Close()
+		return nil, fmt.Errorf("s3 %s %s: %s %s", method, key, response.Status, message)
+	}
+	return response, nil
+}
+
+/* https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html, params are signed along (multipart's uploadId, ...) */
+func (s3 *S3) presign(method string, key string, params map[string]string, expires time.Duration, now time.Time) (string, error) {
+	endpoint, err := url.Parse(strings.TrimRight(s3.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" { return "", fmt.Errorf("S3_ENDPOINT is invalid: %q", s3.Endpoint) }
+
+	now = now.UTC()
+	date := now.Format("20060102")
+	scope := date + "/" + s3.Region + "/s3/aws4_request"
+	/* No key is the bucket itself, which is listed */
+	object := s3.object(key)
+	if key == "" { object = "" }
+	path := endpoint.Path + "/" + encodePath(s3.Bucket + "/" + object)
+
+	query := map[string]string{
+		"X-Amz-Algorithm": "AWS4-HMAC-SHA256",
+		"X-Amz-Credential": s3.AccessKey + "/" + scope,
+		"X-Amz-Date": now.Format("20060102T150405Z"),
+		"X-Amz-Expires": strconv.Itoa(int(expires.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+	for name, value := range params { query[name] = value }
+	names := make([]string, 0, len(query))
+	for name := range query { names = append(names, name) }
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names { pairs[i] = encode(name) + "=" + encode(query[name]) }
+	canonicalQuery := strings.Join(pairs, "&")
+
+	canonical := strings.Join([]string{ method, path, canonicalQuery, "host:" + endpoint.Host + "\n", "host", "UNSIGNED-PAYLOAD" }, "\n")
+	digest := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + query["X-Amz-Date"] + "\n" + scope + "\n" + hex.EncodeToString(digest[:])
+
+	signing := sign([]byte("AWS4" + s3.SecretKey), date)
+	signing = sign(signing, s3.Region)
+	signing = sign(signing, "s3")
+	signing = sign(signing, "aws4_request")
+
+	return endpoint.Scheme + "://" + endpoint.Host + path + "?" + canonicalQuery + "&X-Amz-Signature=" + hex.EncodeToString(sign(signing, toSign)), nil
+}
+
+func sign(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+/* AWS wants every byte but the unreserved ones percent-encoded, spaces included */
+func encode(value string) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || b == '-' || b == '.' || b == '_' || b == '~' {
+			builder.WriteByte(b)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
+
+func encodePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments { segments[i] = encode(segment) }
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,51 @@
+package controller
+
+// SuperRole is the role which is granted every permission.
+const SuperRole = "admin"
+
+// Grants are the roles and permissions of the current user, keyed by name.
+type Grants struct {
+	Roles			map[string]bool
+	Permissions		map[string]bool
+}
+
+// GrantsLoader loads the grants of the current user, the Auth middleware sets one with SetGrants.
+type GrantsLoader func() (Grants, error)
+
+// SetGrants sets how the grants of the current user are loaded.
+// Loading is deferred until the first Can or HasRole call and happens once per request.
+func (ctx *Context) SetGrants(loader GrantsLoader) {
+	ctx.Set("GRANTS", nil)
+	ctx.Set("GRANTS_LOADER", loader)
+}
+
+// Grants returns the roles and permissions of the current user, empty ones when nobody is logged in.
+func (ctx *Context) Grants() Grants {
+	if grants, ok := ctx.Get("GRANTS").(Grants); ok { return grants }
+
+	grants := Grants{ Roles: map[string]bool{}, Permissions: map[string]bool{} }
+	if loader, ok := ctx.Get("GRANTS_LOADER").(GrantsLoader); ok {
+		loaded, err := loader()
+		if err != nil { ctx.Log("Loading grants: ", err) } else { grants = loaded }
+	}
+
+	ctx.Set("GRANTS", grants)
+	return grants
+}
+
+// Can reports whether the current user has the permission, through any of the user's roles.
+// Users with the SuperRole can do everything.
+//
+// Example usage:
+//   if !ctx.Can("files.delete") {
+//      return echo.NewHTTPError(http.StatusForbidden)
+//   }
+func (ctx *Context) Can(permission string) bool {
+	grants := ctx.Grants()
+	return grants.Roles[SuperRole] || grants.Permissions[permission]
+}
+
+// HasRole reports whether the current user has the role.
+func (ctx *Context) HasRole(role string) bool {
+	return ctx.Grants().Roles[role]
+}
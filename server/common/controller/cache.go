@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/preview"
+)
+
+// PublicMaxAge is how long the public pages are reused by the browser before being revalidated.
+const PublicMaxAge = time.Minute
+
+// HtmlWithCache renders the component like Html, letting the browser reuse it for maxAge
+// and revalidate it with its ETag afterwards: an unchanged page is answered with 304 (Not Modified) and no body.
+//
+// Example usage:
+//   app.GET("/about", controller.Register(func(ctx *controller.Context) error {
+//      return ctx.HtmlWithCache(view.About(About), 5 * time.Minute)
+//   }))
+//
+// Notes:
+//   - The page is still rendered on every request, the ETag is the hash of the rendered html.
+//   - Responses are "private", the page depends on the locale and the session so shared caches must not keep it.
+//   - Preview renders and htmx fragments aren't cached (see NoHistoryCache), they're still answered with 304 when unchanged.
+func (ctx *Context) HtmlWithCache(component templ.Component, maxAge time.Duration) error {
+	Body, ok := ctx.rendered(ctx.page(component))
+	defer release(Body)
+
+	ctx.NoHistoryCache(ctx.IsHtmx())
+	if !ok { return ctx.HTMLBlob(http.StatusInternalServerError, Body.Bytes()) }
+
+	if !ctx.IsHtmx() && preview.Channel(ctx.Request().Context()) == "" { ctx.CacheControl(maxAge) }
+
+	return ctx.Cached(echo.MIMETextHTMLCharsetUTF8, Body.Bytes())
+}
+
+// Cached writes the body with an ETag computed from it, or 304 (Not Modified) when the request's
+// If-None-Match already names that ETag.
+//
+// Example usage:
+//   ctx.CacheControl(time.Hour)
+//   return ctx.Cached("application/json", Data)
+func (ctx *Context) Cached(contentType string, body []byte) error {
+	if ctx.ETag(ContentETag(body)) { return nil }
+	return ctx.Blob(http.StatusOK, contentType, body)
+}
+
+// ETag sets the ETag header and answers 304 (Not Modified) when the request's If-None-Match matches it.
+//
+// Example usage:
+//   if ctx.ETag(strconv.Itoa(int(News.UpdatedAt.Unix()))) { return nil }
+//
+// Returns:
+//   - true when 304 was written, the handler must not write anything else.
+func (ctx *Context) ETag(tag string) bool {
+	if !strings.HasPrefix(tag, `"`) && !strings.HasPrefix(tag, `W/"`) { tag = `"` + tag + `"` }
+	ctx.Response().Header().Set("ETag", tag)
+
+	if !etagMatches(ctx.Request().Header.Get("If-None-Match"), tag) { return false }
+	ctx.Response().WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// CacheControl lets the browser reuse the response for maxAge, a zero maxAge makes it revalidate every time.
+func (ctx *Context) CacheControl(maxAge time.Duration) {
+	ctx.Response().Header().Set("Cache-Control", "private, max-age=" + strconv.Itoa(int(maxAge.Seconds())) + ", must-revalidate")
+}
+
+// Immutable lets browsers and shared caches keep the response for a year without revalidating it,
+// for responses whose url always answers the same (content addressed files and what's made of them).
+func (ctx *Context) Immutable() {
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+}
+
+// ContentETag returns a strong ETag for the body, quotes included.
+func ContentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+/* If-None-Match uses the weak comparison, W/"x" matches "x" */
+func etagMatches(header string, tag string) bool {
+	if header == "" { return false }
+	if strings.TrimSpace(header) == "*" { return true }
+
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == tag { return true }
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/domain"
+)
+
+// RetryAfter is what a saturated route asks its clients to wait before trying again.
+const RetryAfter = 10 * time.Second
+
+// ErrBusy is returned by Concurrency when the route's slots are all taken, answered with 429 (Too Many Requests).
+var ErrBusy = domain.Busy("too many requests of this kind are running, try again later")
+
+/* Slots are per instance and per name, so routes sharing a name share their slots */
+var (
+	limitersMu	sync.Mutex
+	limiters	= map[string]chan struct{}{}
+)
+
+func limiter(name string, max int) chan struct{} {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	if slots, ok := limiters[name]; ok { return slots }
+	limiters[name] = make(chan struct{}, max)
+	return limiters[name]
+}
+
+// Concurrency is route middleware letting at most max requests of the name run at once on this instance.
+// The others aren't queued: they're answered right away with 429 and a Retry-After header (see RetryAfter),
+// so expensive work (exports, image processing) can't pile up and exhaust the server.
+//
+// Example usage:
+//   container.Admin.POST("/package/export", controller.Register(export), controller.Concurrency("package", 2))
+//   Media.GET("/:id/resize", resize, controller.Concurrency("resize", 4))
+//
+// Notes:
+//   - Routes given the same name share the slots, max is taken from the first one registered.
+//   - The limit is per instance, each instance behind a load balancer has its own slots.
+func Concurrency(name string, max int) echo.MiddlewareFunc {
+	slots := limiter(name, max)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			select {
+				case slots <- struct{}{}:
+					defer func() { <-slots }()
+					return next(c)
+				default:
+					c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(RetryAfter / time.Second)))
+					return ErrBusy
+			}
+		}
+	}
+}
+
+// WithConcurrency limits the routes of the group to max requests running at once, see Concurrency.
+func WithConcurrency(name string, max int) GroupOption {
+	return func(group *RouteGroup) { group.Echo.Use(Concurrency(name, max)) }
+}
@@ -9,21 +9,22 @@
 //     allowing extended features to be added to route handlers.
 //   - Route Handler Registration: The Register function registers route handlers with additional features
 //     and replaces Echo's context with the custom controller.Context type.
+//   - Route Groups: The Group function applies shared middleware, policies and a layout to a whole echo group,
+//     whose routes then take controller handlers directly.
 //   - HTML Rendering: The Html method renders templ components and returns them as HTML,
 //     supporting both full page rendering and fragment rendering for htmx requests.
 //   - htmx Request Detection: The IsHtmx method checks if a request is made via htmx by examining the "Hx-Request" header.
+//     The Htmx method exposes the rest of the htmx request headers (HX-Boosted, HX-Target, HX-Current-URL, ...).
 package controller
 
 import (
 	"net/http"
-	"strconv"
-	"time"
 
 	"github.com/a-h/templ"
 	"github.com/labstack/echo/v4"
 
 	"main/build/view"
-	"main/server/common/globals"
+	"main/server/common/route"
 	"main/server/model"
 )
 
@@ -38,6 +39,8 @@ import (
 // - Html() error
 //
 // - IsHtmx() bool
+//
+// - Htmx() Htmx
 type Context struct { echo.Context }
 
 // Initialize creates a middleware function for initializing a controller Context instance.
@@ -92,10 +95,39 @@ func (ctx *Context) IsAdmin() bool {
 	return ctx.Get("ISADMIN").(bool)
 }
 
+// User returns the user set by the Auth middleware.
+//
+// Deprecated: it panics when the route isn't behind the Auth middleware, use CurrentUser or MustUser.
 func (ctx *Context) User() model.Users {
 	return ctx.Get("USER").(model.Users)
 }
 
+// CurrentUser returns the user set by the Auth middleware, without panicking when there is none.
+//
+// Example usage:
+//   if User, ok := ctx.CurrentUser(); ok {
+//      ctx.Log("Viewed by ", User.Email)
+//   }
+//
+// Returns:
+//   - The logged in user, or an empty model.Users.
+//   - false when the request isn't authenticated.
+func (ctx *Context) CurrentUser() (model.Users, bool) {
+	User, ok := ctx.Get("USER").(model.Users)
+	return User, ok
+}
+
+// MustUser returns the user set by the Auth middleware, or a 401 error meant to be returned by the handler.
+//
+// Example usage:
+//   User, err := ctx.MustUser()
+//   if err != nil { return err }
+func (ctx *Context) MustUser() (model.Users, error) {
+	User, ok := ctx.CurrentUser()
+	if !ok { return User, echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated") }
+	return User, nil
+}
+
 // Html renders the given templ component and returns it as HTML.
 // If the request is made via htmx, it returns the component as a fragment.
 // Otherwise, it embeds the component within the layout of the base HTML.
@@ -116,30 +148,37 @@ func (ctx *Context) User() model.Users {
 //   - The method requires access to the request context and response writer via the Context instance.
 //   - If the request is made via htmx, the component is rendered as a fragment without any layout.
 //   - If the request is not made via htmx, the component is rendered within the layout of the base HTML.
+//   - History restore requests ("HX-History-Restore-Request") always get the full page, fragments are sent with
+//     "Cache-Control: no-store" so the browser never restores a fragment as a whole page.
 //   - Make sure to handle any errors returned by this method appropriately.
 func (ctx *Context) Html(component templ.Component) error {
 	return ctx.HtmlWithStatus(http.StatusOK, component)
 }
 
+// HtmlWithStatus renders like Html with the status code.
+// The page is rendered into a buffer first, a failing render is answered with the error page and 500
+// instead of a half written page with the requested status.
+//
+// Notes:
+//   - htmx fragments are sent with 200, htmx doesn't swap error statuses. Use Renders (or HtmlFormErrors) for those.
 func (ctx *Context) HtmlWithStatus(code int, component templ.Component) error {
-	if ctx.IsHtmx() { return component.Render(ctx.Request().Context(), ctx.Response()) }
+	ctx.NoHistoryCache(ctx.IsHtmx())
+	if ctx.IsHtmx() { code = http.StatusOK }
 
-	var Base templ.Component
-	if(ctx.IsAdmin()) {
-		Base = view.Admin(component)
-	} else {
-		Base = view.Pages(ctx.Get("Interface").(model.Interface), component)
-	}
+	return ctx.renderBuffered(code, ctx.page(component))
+}
 
-	ctx.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTML)
-	ctx.Response().Writer.WriteHeader(code)
-	return Base.Render(ctx.Request().Context(), ctx.Response().Writer)
+/* The component as it's sent: alone for htmx fragments, inside the group's (WithLayout), the admin or the site layout otherwise */
+func (ctx *Context) page(component templ.Component) templ.Component {
+	if ctx.IsHtmx() { return component }
+	if layout, ok := ctx.Get("LAYOUT").(Layout); ok { return layout(component) }
+	if ctx.IsAdmin() { return view.Admin(component) }
+	return view.Pages(ctx.Get("Interface").(model.Interface), component)
 }
 
+// Renders sends the component alone (no layout) with the status code, buffered like HtmlWithStatus.
 func (ctx *Context) Renders(code int, component templ.Component) error {
-	ctx.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTML)
-	ctx.Response().Writer.WriteHeader(code)
-	return component.Render(ctx.Request().Context(), ctx.Response().Writer)
+	return ctx.renderBuffered(code, component)
 }
 
 func (ctx *Context) RenderPlain(component templ.Component) string {
@@ -148,7 +187,8 @@ func (ctx *Context) RenderPlain(component templ.Component) string {
 }
 
 // IsHtmx checks if the request is made via htmx (Hypertext Markup eXtension).
-// It examines the request headers and returns true if the "Hx-Request" header is set to "true".
+// It examines the request headers and returns true if the "Hx-Request" header is set to "true"
+// and the request is not a boosted navigation, which needs the full layout.
 //
 // Example usage:
 //   isHtmxRequest := ctx.IsHtmx()
@@ -159,59 +199,39 @@ func (ctx *Context) RenderPlain(component templ.Component) string {
 // Notes:
 //   - The method requires access to the request context via the Context instance.
 //   - It examines the "Hx-Request" header to determine if the request is an htmx request.
+//   - Boosted requests ("HX-Boosted") and requests with "hx-fullPage" are treated as full page requests, see Htmx.IsFragment.
 //   - This method can be used to conditionally render content or handle logic based on the type of request.
 func (ctx *Context) IsHtmx() bool {
-	return ctx.Request().Header.Get("Hx-Request") == "true" && ctx.Request().Header.Get("hx-fullPage") != "true"
-}
-
-type Cookie struct {
-	Key string
-	Value string
-	Expires time.Time
+	return ctx.Htmx().IsFragment()
 }
 
-func (ctx *Context) WriteCookie(data Cookie) {
-	cookie := new(http.Cookie)
-	cookie.Name = data.Key
-	cookie.Value = data.Value
-	cookie.Expires = data.Expires
-	ctx.SetCookie(cookie)
-}
-
-func (ctx *Context) ReadCookie(Key string) Cookie {
-	cookie, err := ctx.Cookie(Key)
-	if err != nil { cookie = &http.Cookie{ Name: "", Value: "", Expires: time.Now() } }
-	return Cookie{ Key: cookie.Name, Value: cookie.Value, Expires: cookie.Expires }
-}
-
-type QueryPageParameter struct {
-	Page		string		`query:"page"`
-	PageSize	string		`query:"pageSize"`
+// Viewed tells middleware.Analytics the record the page shows, its view is counted under the record's ID.
+// A page of a route with parameters isn't counted unless its handler found the record.
+//
+// Example usage:
+//   if News.ID != 0 { ctx.Viewed(News.ID) }
+func (ctx *Context) Viewed(ID uint) {
+	ctx.Set("VIEWED", ID)
 }
 
-func (ctx *Context) Page() int {
-	var Query QueryPageParameter
-
-	if ctx.QueryParam("page") == "" { 
-		Query.Page = "1" 
-	} else {
-		ctx.Bind(&Query)
-	}
-
-	page, _ := strconv.Atoi(Query.Page)
-	if page <= 0 { page = 1 }
-	return page
+// RegisterNamed registers a route handler like Register and names the route, so its url can be generated
+// by ctx.URLFor (or route.URL in templ) instead of being hard-coded.
+//
+// Example usage:
+//   app.GET("/news/:ID", controller.RegisterNamed("news.detail", detail))
+//
+// Notes:
+//   - The name is given to the route registered with the returned handler, pass it straight to app.GET (or POST, ...).
+//   - route.Setup must be called on the app before routes are registered.
+func RegisterNamed(name string, handlerFunc func(*Context) error) echo.HandlerFunc {
+	route.Name(name)
+	return Register(handlerFunc)
 }
 
-func (ctx *Context) PageSize() int {
-	var Query QueryPageParameter
-	if ctx.QueryParam("pageSize") == "" { Query.PageSize = "-1" }
-	
-	ctx.Bind(&Query)
-	pageSize, _ := strconv.Atoi(Query.PageSize)
-
-	if pageSize <= 0 || pageSize > globals.Env.PageMaxSize {
-		pageSize = globals.Env.PageMaxSize
-	}
-	return pageSize
+// URLFor returns the url of a named route, see route.URL.
+//
+// Example usage:
+//   ctx.Response().Header().Set("HX-Push-Url", ctx.URLFor("news.detail", News.ID))
+func (ctx *Context) URLFor(name string, params ...any) string {
+	return route.URL(name, params...)
 }
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"main/server/common/globals"
+)
+
+// Cookie describes a cookie with its full attribute set.
+//
+// Zero values of Path and SameSite fall back to the defaults configured in globals.Env
+// (COOKIE_PATH, COOKIE_SAMESITE, lax unless configured), Domain falls back to COOKIE_DOMAIN.
+// Cookies are HttpOnly unless Scripted opts out, for the ones JavaScript has to read.
+// Use NewCookie to get a Cookie with the configured Secure default as well.
+//
+// MaxAge follows net/http semantics: 0 means no Max-Age attribute,
+// a negative value deletes the cookie right away.
+type Cookie struct {
+	Key			string
+	Value		string
+	Expires		time.Time
+	MaxAge		int
+	Path		string
+	Domain		string
+	Scripted	bool			/* readable by JavaScript, not HttpOnly */
+	Secure		bool
+	SameSite	http.SameSite
+}
+
+// NewCookie creates a Cookie carrying the secure defaults configured in globals.Env.
+//
+// Example usage:
+//   ctx.WriteCookie(controller.NewCookie("token", Token, time.Now().Add(24 * time.Hour)))
+func NewCookie(Key string, Value string, Expires time.Time) Cookie {
+	return Cookie{
+		Key: Key,
+		Value: Value,
+		Expires: Expires,
+		Path: globals.Env.COOKIE_PATH,
+		Domain: globals.Env.COOKIE_DOMAIN,
+		Scripted: !globals.Env.COOKIE_HTTPONLY,
+		Secure: globals.Env.COOKIE_SECURE,
+		SameSite: SameSite(globals.Env.COOKIE_SAMESITE),
+	}
+}
+
+// SameSite converts the configured textual SameSite mode (lax, strict, none) into http.SameSite.
+// Unknown values are treated as lax.
+func SameSite(mode string) http.SameSite {
+	switch strings.ToLower(mode) {
+		case "strict": return http.SameSiteStrictMode
+		case "none": return http.SameSiteNoneMode
+		default: return http.SameSiteLaxMode
+	}
+}
+
+func (ctx *Context) WriteCookie(data Cookie) {
+	if data.Path == "" { data.Path = globals.Env.COOKIE_PATH }
+	if data.Domain == "" { data.Domain = globals.Env.COOKIE_DOMAIN }
+	if data.SameSite == 0 { data.SameSite = SameSite(globals.Env.COOKIE_SAMESITE) }
+
+	/* Browsers reject SameSite=None cookies which are not Secure */
+	if data.SameSite == http.SameSiteNoneMode { data.Secure = true }
+
+	cookie := new(http.Cookie)
+	cookie.Name = data.Key
+	cookie.Value = data.Value
+	cookie.Expires = data.Expires
+	cookie.MaxAge = data.MaxAge
+	cookie.Path = data.Path
+	cookie.Domain = data.Domain
+	cookie.HttpOnly = !data.Scripted
+	cookie.Secure = data.Secure
+	cookie.SameSite = data.SameSite
+	ctx.SetCookie(cookie)
+}
+
+// ReadCookie returns the cookie or an empty Cookie when it's missing.
+// Use HasCookie to tell a missing cookie apart from a cookie with an empty value.
+func (ctx *Context) ReadCookie(Key string) Cookie {
+	cookie, err := ctx.Cookie(Key)
+	if err != nil { cookie = &http.Cookie{ Name: "", Value: "", Expires: time.Now() } }
+	return Cookie{ Key: cookie.Name, Value: cookie.Value, Expires: cookie.Expires }
+}
+
+// HasCookie reports whether the request carries a cookie with the given name, even with an empty value.
+func (ctx *Context) HasCookie(Key string) bool {
+	_, err := ctx.Cookie(Key)
+	return err == nil
+}
+
+// DeleteCookie expires the cookie on the client.
+// The browser only drops a cookie when path and domain match the ones it was written with,
+// so it expects the cookie to live on the configured defaults, use ExpireCookie for other ones.
+//
+// Example usage:
+//   ctx.DeleteCookie("token")
+func (ctx *Context) DeleteCookie(Key string) {
+	ctx.ExpireCookie(Cookie{ Key: Key })
+}
+
+// ExpireCookie expires the cookie matching the name, path and domain of the given one.
+//
+// Example usage:
+//   ctx.ExpireCookie(controller.Cookie{ Key: "token", Path: "/admin" })
+func (ctx *Context) ExpireCookie(data Cookie) {
+	data.Value = ""
+	data.Expires = time.Unix(0, 0)
+	data.MaxAge = -1
+	ctx.WriteCookie(data)
+}
+
+var (
+	ErrCookieMissing = errors.New("cookie is missing")
+	ErrCookieTampered = errors.New("cookie value was tampered with")
+)
+
+// WriteSignedCookie writes the cookie with an HMAC-SHA256 signature appended to its value.
+// The value stays readable on the client, but any change to it is detected by ReadSignedCookie.
+// The signature covers the cookie name too, so a value can't be moved into another cookie.
+//
+// Example usage:
+//   ctx.WriteSignedCookie(controller.NewCookie("locale", "ka", time.Now().Add(24 * time.Hour)))
+func (ctx *Context) WriteSignedCookie(data Cookie) {
+	data.Value = base64.RawURLEncoding.EncodeToString([]byte(data.Value)) + "." + cookieSignature(data.Key, data.Value)
+	ctx.WriteCookie(data)
+}
+
+// ReadSignedCookie reads a cookie written by WriteSignedCookie and verifies its signature.
+// Returns ErrCookieMissing when there is no such cookie and ErrCookieTampered when the signature doesn't match.
+func (ctx *Context) ReadSignedCookie(Key string) (Cookie, error) {
+	cookie, err := ctx.Cookie(Key)
+	if err != nil { return Cookie{}, ErrCookieMissing }
+
+	encoded, signature, found := strings.Cut(cookie.Value, ".")
+	if !found { return Cookie{}, ErrCookieTampered }
+
+	value, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil { return Cookie{}, ErrCookieTampered }
+
+	if !hmac.Equal([]byte(signature), []byte(cookieSignature(Key, string(value)))) {
+		return Cookie{}, ErrCookieTampered
+	}
+
+	return Cookie{ Key: cookie.Name, Value: string(value), Expires: cookie.Expires }, nil
+}
+
+// WriteEncryptedCookie writes the cookie with its value encrypted by AES-256-GCM,
+// so the client can neither read nor modify it.
+func (ctx *Context) WriteEncryptedCookie(data Cookie) error {
+	gcm, err := cookieCipher()
+	if err != nil { return err }
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil { return err }
+
+	sealed := gcm.Seal(nonce, nonce, []byte(data.Value), []byte(data.Key))
+	data.Value = base64.RawURLEncoding.EncodeToString(sealed)
+	ctx.WriteCookie(data)
+	return nil
+}
+
+// ReadEncryptedCookie reads and decrypts a cookie written by WriteEncryptedCookie.
+// Returns ErrCookieMissing when there is no such cookie and ErrCookieTampered when it can't be decrypted.
+func (ctx *Context) ReadEncryptedCookie(Key string) (Cookie, error) {
+	cookie, err := ctx.Cookie(Key)
+	if err != nil { return Cookie{}, ErrCookieMissing }
+
+	gcm, err := cookieCipher()
+	if err != nil { return Cookie{}, err }
+
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || len(sealed) < gcm.NonceSize() { return Cookie{}, ErrCookieTampered }
+
+	value, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(Key))
+	if err != nil { return Cookie{}, ErrCookieTampered }
+
+	return Cookie{ Key: cookie.Name, Value: string(value), Expires: cookie.Expires }, nil
+}
+
+func cookieSignature(Key string, Value string) string {
+	mac := hmac.New(sha256.New, []byte(globals.Env.COOKIE_SECRET))
+	mac.Write([]byte(Key + "=" + Value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func cookieCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte("cookie-encryption:" + globals.Env.COOKIE_SECRET))
+	block, err := aes.NewCipher(key[:])
+	if err != nil { return nil, err }
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/blob"
+	"main/server/common/globals"
+	"main/server/model"
+)
+
+// DownloadGuard decides whether the current request may download the file, an error denies it and is returned as is.
+// Refusals should be domain.Forbidden("files", ...), so the access policy (see Hides) can hide the file.
+type DownloadGuard func(ctx *Context, file model.Files) error
+
+// DownloadGuards run before every Download, e.g. to keep files of unpublished content private.
+var DownloadGuards = []DownloadGuard{ Visible }
+
+// SignedURLExpiry is how long the signed urls Download redirects to stay valid.
+const SignedURLExpiry = 15 * time.Minute
+
+type downloadOptions struct {
+	disposition		string
+	filename		string
+	guards			[]DownloadGuard
+	key				string
+}
+
+type DownloadOption func(*downloadOptions)
+
+// Inline asks the browser to display the file.
+func Inline() DownloadOption {
+	return func(options *downloadOptions) { options.disposition = "inline" }
+}
+
+// Attachment asks the browser to save the file.
+func Attachment() DownloadOption {
+	return func(options *downloadOptions) { options.disposition = "attachment" }
+}
+
+// Filename overrides the name the file is saved under, the original upload name by default.
+func Filename(name string) DownloadOption {
+	return func(options *downloadOptions) { options.filename = name }
+}
+
+// Variant sends another blob made of the file (a thumbnail, a resized copy) in place of the file itself,
+// the guards still decide on the file.
+func Variant(key string) DownloadOption {
+	return func(options *downloadOptions) { options.key = key }
+}
+
+// Guard adds a DownloadGuard for this download only.
+func Guard(guard DownloadGuard) DownloadOption {
+	return func(options *downloadOptions) { options.guards = append(options.guards, guard) }
+}
+
+// Authorize runs the DownloadGuards and the given ones, for handlers which do costly work on the file (resizing it,
+// ...) before they Download it. The first error is returned.
+func (ctx *Context) Authorize(file model.Files, guards ...DownloadGuard) error {
+	for _, guard := range append(DownloadGuards, guards...) {
+		if err := guard(ctx, file); err != nil { return err }
+	}
+	return nil
+}
+
+// Download sends a stored file with its Content-Type and Content-Disposition, from whichever blob backend holds it.
+// Media types (images, video, audio, pdf) are shown inline unless Attachment is given, anything else is an attachment.
+//
+// Example usage:
+//   var File model.Files
+//   if storage.DB.First(&File, ctx.Param("id")).Error != nil { return echo.ErrNotFound }
+//   return ctx.Download(File, controller.Attachment(), controller.Filename("report.pdf"))
+//
+// Notes:
+//   - DownloadGuards and Guard options run first, the first error is returned.
+//   - Seekable blobs (local files) go through http.ServeContent, which handles range requests (seeking in videos,
+//     resumed downloads) and conditional requests. Others are streamed by StreamFile.
+//   - With S3_REDIRECT the client is redirected to the backend instead: to the blob's public url (S3_PUBLIC_URL)
+//     for public files, to a signed url valid for SignedURLExpiry for the others, when it has one.
+func (ctx *Context) Download(file model.Files, opts ...DownloadOption) error {
+	options := downloadOptions{ filename: file.Original }
+	for _, opt := range opts { opt(&options) }
+	if options.filename == "" { options.filename = file.Name }
+
+	if err := ctx.Authorize(file, options.guards...); err != nil { return err }
+
+	key := blob.Key(file.Path)
+	if options.key != "" { key = options.key }
+
+	backend := blob.Default()
+	if globals.Env.S3_REDIRECT {
+		/* A public url never expires, only public files get one, the others a signed url which does */
+		if file.IsPublic() && !file.UnderReview() {
+			if public, err := backend.URL(key); err == nil { return ctx.Redirect(http.StatusFound, public) }
+		}
+		if signed, err := backend.SignedURL(key, SignedURLExpiry); err == nil {
+			return ctx.Redirect(http.StatusFound, signed)
+		}
+	}
+
+	content, object, err := backend.Open(ctx.Request().Context(), key)
+	if err == blob.ErrNotFound { return echo.ErrNotFound }
+	if err != nil { return err }
+	defer content.Close()
+
+	contentType := file.MimeType()
+	if options.key != "" { contentType = mime.TypeByExtension(filepath.Ext(key)) }
+	if contentType == "" { contentType = object.ContentType }
+
+	if options.disposition == "" {
+		options.disposition = "attachment"
+		if isMedia(contentType) { options.disposition = "inline" }
+	}
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType(options.disposition, map[string]string{ "filename": options.filename }))
+
+	if seeker, ok := content.(io.ReadSeeker); ok {
+		if contentType != "" { ctx.Response().Header().Set(echo.HeaderContentType, contentType) }
+		http.ServeContent(ctx.Response(), ctx.Request(), options.filename, object.ModTime, seeker)
+		return nil
+	}
+
+	if object.Size >= 0 { ctx.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(object.Size, 10)) }
+	return ctx.StreamFile(content, contentType, options.filename)
+}
+
+// StreamFile copies the reader into the response as it's read, nothing is held in memory.
+// The file is sent as an attachment named filename, unless a Content-Disposition header was already set.
+// It isn't named Stream because echo.Context has a Stream method with another signature.
+//
+// Example usage:
+//   reader, _, err := blob.Default().Open(ctx.Request().Context(), "exports/report.csv")
+//   if err != nil { return err }
+//   defer reader.Close()
+//   return ctx.StreamFile(reader, "text/csv", "report.csv")
+func (ctx *Context) StreamFile(reader io.Reader, contentType string, filename string) error {
+	if contentType == "" { contentType = echo.MIMEOctetStream }
+	if ctx.Response().Header().Get(echo.HeaderContentDisposition) == "" {
+		ctx.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{ "filename": filename }))
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentType, contentType)
+	ctx.Response().WriteHeader(http.StatusOK)
+	_, err := io.Copy(ctx.Response(), reader)
+	return err
+}
+
+func isMedia(contentType string) bool {
+	for _, prefix := range []string{"image/", "video/", "audio/", "application/pdf"} {
+		if strings.HasPrefix(contentType, prefix) { return true }
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/domain"
+)
+
+// ErrorHandler answers the errors handlers return: domain errors (see package domain) with their kind's status
+// and translated message, as json when the client asks for it, as text otherwise. Other errors, echo's
+// HTTPErrors included, go to fallback.
+//
+// Example usage:
+//   app.HTTPErrorHandler = controller.ErrorHandler(app.DefaultHTTPErrorHandler)
+//
+// Notes:
+//   - Errors are logged with the request ID, client errors (SeverityInfo) and echo's HTTPErrors aren't.
+//   - The json body is { "code": "not_found", "message": "...", "detail": "<the service's message>" }.
+//   - Refusals of hidden resources are answered as missing ones, see Conceal.
+func ErrorHandler(fallback echo.HTTPErrorHandler) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		ctx, ok := c.(*Context)
+		if !ok { ctx = &Context{Context: c} }
+		err = Conceal(err)
+
+		var httpError *echo.HTTPError
+		if errors.As(err, &httpError) {
+			fallback(err, c)
+			return
+		}
+		if !domain.Known(err) {
+			ctx.Log(domain.SeverityError, ": ", err)
+			fallback(err, c)
+			return
+		}
+
+		if Level := domain.Level(err); Level != domain.SeverityInfo { ctx.Log(Level, ": ", err) }
+		if ctx.Response().Committed { return }
+
+		Code := domain.Code(err)
+		Message := ctx.T("error." + Code)
+		if strings.Contains(ctx.Request().Header.Get(echo.HeaderAccept), echo.MIMEApplicationJSON) {
+			ctx.JSON(domain.Status(err), map[string]string{ "code": Code, "message": Message, "detail": err.Error() })
+			return
+		}
+		ctx.String(domain.Status(err), Message)
+	}
+}
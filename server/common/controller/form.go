@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"net/http"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// FormErrorKey is the FormErrors key for errors which don't belong to a single field.
+const FormErrorKey = "_form"
+
+// FormErrors maps form field names (the `form` tag, or `json` tag, of the bound struct) to error messages.
+// It's a plain map underneath, so templ components take it as map[string]string.
+type FormErrors map[string]string
+
+// FormValidator can be implemented by form structs for rules the `validate` tag can't express.
+type FormValidator interface {
+	Validate(errs FormErrors)
+}
+
+func (errs FormErrors) Add(field string, message string) {
+	if _, exists := errs[field]; !exists { errs[field] = message }
+}
+
+func (errs FormErrors) Has(field string) bool {
+	_, exists := errs[field]
+	return exists
+}
+
+func (errs FormErrors) Valid() bool {
+	return len(errs) == 0
+}
+
+// BindForm binds the request into a new T and validates it.
+// Rules are read from the `validate` tag of T's fields: required, email, min=N and max=N (length of strings).
+// If T implements FormValidator its Validate method runs afterwards.
+//
+// Example usage:
+//   type LoginInput struct {
+//      Email    string `form:"email" json:"email" validate:"required,email"`
+//      Password string `form:"password" json:"password" validate:"required"`
+//   }
+//
+//   form, errs := controller.BindForm[LoginInput](ctx)
+//   if !errs.Valid() {
+//      return ctx.HtmlFormErrors("#LoginForm", view.LoginForm(form.Email, errs))
+//   }
+//
+// Returns:
+//   - The bound T, filled with whatever could be bound so the form can be repopulated.
+//   - FormErrors, empty when the form is valid.
+func BindForm[T any](ctx *Context) (T, FormErrors) {
+	var form T
+	errs := FormErrors{}
+
+	if err := ctx.Bind(&form); err != nil {
+		errs.Add(FormErrorKey, ctx.T("form.unreadable"))
+		return form, errs
+	}
+
+	validateForm(ctx, reflect.ValueOf(&form).Elem(), errs)
+	if validator, ok := any(&form).(FormValidator); ok { validator.Validate(errs) }
+
+	return form, errs
+}
+
+// HtmlFormErrors re-renders a form component with its errors and status 422 (Unprocessable Entity).
+// htmx doesn't swap 4xx responses on its own, the FormErrors script in the layout lets 422 through,
+// HX-Retarget/HX-Reswap make sure the form replaces itself wherever the original request was aimed.
+func (ctx *Context) HtmlFormErrors(target string, component templ.Component) error {
+	if target != "" {
+		ctx.Response().Header().Set("HX-Retarget", target)
+		ctx.Response().Header().Set("HX-Reswap", "outerHTML")
+	}
+
+	if ctx.IsHtmx() { return ctx.Renders(http.StatusUnprocessableEntity, component) }
+	return ctx.HtmlWithStatus(http.StatusUnprocessableEntity, component)
+}
+
+func validateForm(ctx *Context, form reflect.Value, errs FormErrors) {
+	if form.Kind() != reflect.Struct { return }
+
+	for i := 0; i < form.NumField(); i++ {
+		field := form.Type().Field(i)
+		rules := field.Tag.Get("validate")
+		if rules == "" || !field.IsExported() { continue }
+
+		name := formFieldName(field)
+		value := form.Field(i)
+
+		for _, rule := range strings.Split(rules, ",") {
+			rule, argument, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			limit, _ := strconv.Atoi(argument)
+
+			switch rule {
+				case "required":
+					if value.IsZero() { errs.Add(name, ctx.T("form.required")) }
+				case "email":
+					if value.Kind() != reflect.String || value.String() == "" { continue }
+					if _, err := mail.ParseAddress(value.String()); err != nil { errs.Add(name, ctx.T("form.email")) }
+				case "min":
+					if value.Kind() == reflect.String && len([]rune(value.String())) < limit {
+						errs.Add(name, ctx.T("form.min", limit))
+					}
+				case "max":
+					if value.Kind() == reflect.String && len([]rune(value.String())) > limit {
+						errs.Add(name, ctx.T("form.max", limit))
+					}
+			}
+		}
+	}
+}
+
+func formFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"form", "json", "query", "param"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" { return name }
+	}
+	return field.Name
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/domain"
+	"main/server/common/route"
+)
+
+// Handler is a route handler taking the controller Context, what Register wraps for echo.
+type Handler func(*Context) error
+
+// Layout wraps a full page component, e.g. view.Admin. Fragments (htmx) are never wrapped.
+type Layout func(templ.Component) templ.Component
+
+// GroupOption configures a RouteGroup, see WithMiddleware, RequireUser, RequirePermission, RequireRole, WithLayout and WithConcurrency.
+type GroupOption func(*RouteGroup)
+
+// RouteGroup is an echo group whose routes take controller handlers directly and share its middleware and policies.
+type RouteGroup struct {
+	Echo		*echo.Group
+}
+
+// Group applies the options to the echo group, in order, after making sure every request of it carries
+// a controller Context (so it doesn't depend on app.Use(controller.Initialize()) having run first).
+//
+// Example usage:
+//   Types := controller.Group(container.Admin.Group("/filetypes"), controller.RequirePermission("filetypes.manage"))
+//   Types.GET("", index)
+//   Types.DELETE("/:id", remove)
+//
+// Returns:
+//   - The RouteGroup, its GET, POST, PUT, PATCH and DELETE take func(*controller.Context) error handlers.
+//
+// Notes:
+//   - Options are middleware of the whole group, they run before any middleware given to a single route.
+//   - Authentication itself stays in middleware.Auth, pass it with WithMiddleware when the parent group doesn't run it.
+func Group(group *echo.Group, options ...GroupOption) *RouteGroup {
+	Routes := &RouteGroup{ Echo: group }
+	group.Use(initialized)
+	for _, option := range options { option(Routes) }
+	return Routes
+}
+
+/* Same as Initialize, without wrapping a context which already is a controller Context */
+func initialized(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, ok := c.(*Context); ok { return next(c) }
+		return next(&Context{Context: c})
+	}
+}
+
+// WithMiddleware adds echo middleware to the group.
+func WithMiddleware(middleware ...echo.MiddlewareFunc) GroupOption {
+	return func(group *RouteGroup) { group.Echo.Use(middleware...) }
+}
+
+// RequireUser answers requests without a logged in user with 401, the user is set by middleware.Auth.
+func RequireUser() GroupOption {
+	return guard(func(ctx *Context) error {
+		_, err := ctx.MustUser()
+		return err
+	})
+}
+
+// RequirePermission answers requests of users without the permission with 403 (or 404, see Hides), like middleware.Can.
+func RequirePermission(permission string) GroupOption {
+	return guard(func(ctx *Context) error {
+		if !ctx.Can(permission) { return domain.Forbidden(PermissionResource(permission), "Missing permission: " + permission) }
+		return nil
+	})
+}
+
+// RequireRole answers requests of users without the role with 403 (or 404, see Hides), like middleware.Role.
+func RequireRole(role string) GroupOption {
+	return guard(func(ctx *Context) error {
+		if !ctx.HasRole(role) && !ctx.HasRole(SuperRole) { return domain.Forbidden(role, "Missing role: " + role) }
+		return nil
+	})
+}
+
+// WithLayout makes Html render the group's full pages inside the layout instead of the admin or the site layout.
+func WithLayout(layout Layout) GroupOption {
+	return func(group *RouteGroup) {
+		group.Echo.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return Register(func(ctx *Context) error {
+				ctx.Set("LAYOUT", layout)
+				return next(ctx)
+			})
+		})
+	}
+}
+
+/* A policy is a middleware which lets the request through when check returns nil */
+func guard(check Handler) GroupOption {
+	return func(group *RouteGroup) {
+		group.Echo.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return Register(func(ctx *Context) error {
+				if err := check(ctx); err != nil { return err }
+				return next(ctx)
+			})
+		})
+	}
+}
+
+// Use adds echo middleware to the group, like WithMiddleware.
+func (group *RouteGroup) Use(middleware ...echo.MiddlewareFunc) {
+	group.Echo.Use(middleware...)
+}
+
+// Group creates a sub-group sharing this group's options, with options of its own.
+func (group *RouteGroup) Group(prefix string, options ...GroupOption) *RouteGroup {
+	return Group(group.Echo.Group(prefix), options...)
+}
+
+func (group *RouteGroup) GET(path string, handler Handler, middleware ...echo.MiddlewareFunc) *echo.Route {
+	return group.Echo.GET(path, Register(handler), middleware...)
+}
+
+func (group *RouteGroup) POST(path string, handler Handler, middleware ...echo.MiddlewareFunc) *echo.Route {
+	return group.Echo.POST(path, Register(handler), middleware...)
+}
+
+func (group *RouteGroup) PUT(path string, handler Handler, middleware ...echo.MiddlewareFunc) *echo.Route {
+	return group.Echo.PUT(path, Register(handler), middleware...)
+}
+
+func (group *RouteGroup) PATCH(path string, handler Handler, middleware ...echo.MiddlewareFunc) *echo.Route {
+	return group.Echo.PATCH(path, Register(handler), middleware...)
+}
+
+func (group *RouteGroup) DELETE(path string, handler Handler, middleware ...echo.MiddlewareFunc) *echo.Route {
+	return group.Echo.DELETE(path, Register(handler), middleware...)
+}
+
+func (group *RouteGroup) HEAD(path string, handler Handler, middleware ...echo.MiddlewareFunc) *echo.Route {
+	return group.Echo.HEAD(path, Register(handler), middleware...)
+}
+
+func (group *RouteGroup) OPTIONS(path string, handler Handler, middleware ...echo.MiddlewareFunc) *echo.Route {
+	return group.Echo.OPTIONS(path, Register(handler), middleware...)
+}
+
+// Named registers the next route under the name, see RegisterNamed.
+//
+// Example usage:
+//   News.Named("news.detail").GET("/:ID", detail)
+func (group *RouteGroup) Named(name string) *RouteGroup {
+	route.Name(name)
+	return group
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// StatusStopPolling makes htmx stop polling the element which issued the request (hx-trigger="every 2s").
+const StatusStopPolling = 286
+
+// Htmx holds the request headers htmx sends along with every request it issues.
+//
+// Semantics:
+//
+// - Request: "HX-Request" is "true", the request was issued by htmx at all.
+//
+// - Boosted: "HX-Boosted" is "true", the request comes from an hx-boost'ed link or form.
+// Boosted requests replace the whole body, so they need the full layout, not a fragment.
+//
+// - Target: "HX-Target", id of the target element if it exists.
+//
+// - TriggerName: "HX-Trigger-Name", name of the triggered element if it exists.
+//
+// - Trigger: "HX-Trigger", id of the triggered element if it exists.
+//
+// - CurrentURL: "HX-Current-URL", the current url of the browser.
+//
+// - HistoryRestoreRequest: "HX-History-Restore-Request" is "true", htmx missed its local history cache
+// and asks the server for the page, which must be a full page render.
+//
+// - FullPage: "hx-fullPage" is "true", the project's own header forcing a full page render.
+type Htmx struct {
+	Request					bool
+	Boosted					bool
+	Target					string
+	TriggerName				string
+	Trigger					string
+	CurrentURL				string
+	HistoryRestoreRequest	bool
+	FullPage				bool
+}
+
+// Htmx parses the htmx request headers into an Htmx struct.
+//
+// Example usage:
+//   if ctx.Htmx().Target == "AdminContent" {
+//      return ctx.Html(view.Fragment())
+//   }
+func (ctx *Context) Htmx() Htmx {
+	header := ctx.Request().Header
+	return Htmx{
+		Request: header.Get("HX-Request") == "true",
+		Boosted: header.Get("HX-Boosted") == "true",
+		Target: header.Get("HX-Target"),
+		TriggerName: header.Get("HX-Trigger-Name"),
+		Trigger: header.Get("HX-Trigger"),
+		CurrentURL: header.Get("HX-Current-URL"),
+		HistoryRestoreRequest: header.Get("HX-History-Restore-Request") == "true",
+		FullPage: header.Get("hx-fullPage") == "true",
+	}
+}
+
+// IsFragment reports whether the response should be rendered as a fragment without the layout.
+// Boosted navigations, history restore requests and requests forcing the full page always get the layout.
+func (htmx Htmx) IsFragment() bool {
+	return htmx.Request && !htmx.Boosted && !htmx.HistoryRestoreRequest && !htmx.FullPage
+}
+
+// NoHistoryCache marks the response so that a fragment is never cached (by the browser or a proxy)
+// in place of the full page living on the same url.
+// Vary tells caches that the body depends on htmx headers, no-store keeps fragments out of the back/forward cache.
+func (ctx *Context) NoHistoryCache(fragment bool) {
+	header := ctx.Response().Header()
+	header.Add("Vary", "HX-Request")
+	header.Add("Vary", "HX-Boosted")
+	header.Add("Vary", "HX-History-Restore-Request")
+	if fragment { header.Set("Cache-Control", "no-store, max-age=0") }
+}
+
+// HxNoContent responds 204, htmx leaves the target as it is.
+// Events, when given, are sent in HX-Trigger so the page can still react to the request.
+//
+// Example usage:
+//   return ctx.HxNoContent("saved")
+//   return ctx.HxNoContent(map[string]any{ "saved": map[string]int{ "id": 5 } })
+//
+// Notes:
+//   - Event names (strings) alone are joined with commas. With maps of event details they're all sent as one json
+//     object, the names without details.
+func (ctx *Context) HxNoContent(events ...any) error {
+	if err := ctx.hxTrigger(events); err != nil { return err }
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// HxTrigger sends the events in HX-Trigger with whatever response follows, like HxNoContent does.
+//
+// Example usage:
+//   if err := ctx.HxTrigger(map[string]any{ "video-transcoded": Status }); err != nil { return err }
+//   return ctx.HxStopPolling(view.VideoTranscoding(URL, State))
+func (ctx *Context) HxTrigger(events ...any) error {
+	return ctx.hxTrigger(events)
+}
+
+// HxStopPolling responds 286, which stops an htmx polling element, swapping the component when given one.
+//
+// Example usage:
+//   if Job.Done { return ctx.HxStopPolling(view.JobDone(Job)) }
+//   return ctx.Html(view.JobProgress(Job))
+func (ctx *Context) HxStopPolling(component ...templ.Component) error {
+	if len(component) == 0 { return ctx.NoContent(StatusStopPolling) }
+	return ctx.Renders(StatusStopPolling, component[0])
+}
+
+func (ctx *Context) hxTrigger(events []any) error {
+	if len(events) == 0 { return nil }
+
+	names := []string{}
+	merged := map[string]any{}
+	for _, event := range events {
+		switch event := event.(type) {
+			case string:
+				names = append(names, event)
+				merged[event] = nil
+			default:
+				/* Maps of any type, read back as one to merge them */
+				data, err := json.Marshal(event)
+				if err != nil { return err }
+				var details map[string]json.RawMessage
+				if err := json.Unmarshal(data, &details); err != nil { return err }
+				for name, detail := range details { merged[name] = detail }
+		}
+	}
+
+	if len(names) == len(events) {
+		ctx.Response().Header().Set("HX-Trigger", strings.Join(names, ", "))
+		return nil
+	}
+	data, err := json.Marshal(merged)
+	if err != nil { return err }
+	ctx.Response().Header().Set("HX-Trigger", string(data))
+	return nil
+}
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"main/server/common/i18n"
+)
+
+// Locale returns the locale negotiated for the request by middleware.Locale.
+func (ctx *Context) Locale() string {
+	return i18n.Locale(ctx.Request().Context())
+}
+
+// SetLocale switches the locale of the request, unsupported locales are ignored.
+func (ctx *Context) SetLocale(locale string) {
+	if !i18n.IsSupported(locale) { return }
+	ctx.SetRequest(ctx.Request().WithContext(i18n.WithLocale(ctx.Request().Context(), locale)))
+}
+
+// T translates the key into the request's locale, formatting it with args.
+//
+// Example usage:
+//   errs.Add(controller.FormErrorKey, ctx.T("login.invalid"))
+func (ctx *Context) T(key string, args ...any) string {
+	return i18n.Translate(ctx.Locale(), key, args...)
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"mime/multipart"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/globals"
+)
+
+/*
+	Echo parses multipart forms keeping up to 32MB of their files in memory, for every request which reads a form
+	value. The methods below parse them first with UPLOAD_MEMORY instead, so an upload costs at most that much memory
+	whatever its size, the rest is spilled to temporary files by mime/multipart.
+*/
+
+// FormFile returns the file of a multipart form field, see echo.Context.FormFile.
+func (ctx *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	if err := ctx.multipart(); err != nil { return nil, err }
+	return ctx.Context.FormFile(name)
+}
+
+// MultipartForm returns the parsed multipart form, see echo.Context.MultipartForm.
+func (ctx *Context) MultipartForm() (*multipart.Form, error) {
+	if err := ctx.multipart(); err != nil { return nil, err }
+	return ctx.Context.MultipartForm()
+}
+
+// FormValue returns a form field's value, "" when the form can't be parsed, see echo.Context.FormValue.
+func (ctx *Context) FormValue(name string) string {
+	if ctx.multipart() != nil { return "" }
+	return ctx.Context.FormValue(name)
+}
+
+// Bind binds the request into i, see echo.Context.Bind.
+func (ctx *Context) Bind(i any) error {
+	if err := ctx.multipart(); err != nil { return err }
+	return ctx.Context.Bind(i)
+}
+
+/* Once parsed, Echo and net/http reuse the form. Other bodies, and a multipart one read as a stream
+   (uploader.Receive), are left untouched */
+func (ctx *Context) multipart() error {
+	Request := ctx.Request()
+	if Request.MultipartForm != nil || !strings.HasPrefix(Request.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) { return nil }
+	return Request.ParseMultipartForm(globals.Env.UPLOAD_MEMORY)
+}
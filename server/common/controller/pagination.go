@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"net/url"
+	"strconv"
+
+	"main/server/common/globals"
+)
+
+// MaxPage is the last page Pagination goes to, later ones are clamped to it: the offset of a page like
+// 999999999999999999 overflows into a negative one.
+const MaxPage = 1 << 20
+
+// Pagination is the page requested through the "page" and "pageSize" query parameters.
+type Pagination struct {
+	Page		int
+	PageSize	int
+	Offset		int
+
+	path		string
+	query		url.Values
+}
+
+// Pagination reads the page from the query, it's parsed once per request.
+// Page starts at 1, PageSize defaults to, and is capped at, globals.Env.PageMaxSize.
+//
+// Example usage:
+//   Pagination := ctx.Pagination()
+//   storage.DB.Offset(Pagination.Offset).Limit(Pagination.PageSize).Find(&News)
+//   return ctx.Html(view.News(News, Pagination.Prev(), Pagination.Next()))
+//
+// Notes:
+//   - storage.Paginate(ctx) is the gorm scope doing the same.
+func (ctx *Context) Pagination() Pagination {
+	if pagination, ok := ctx.Get("PAGINATION").(Pagination); ok { return pagination }
+
+	query := ctx.Request().URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page <= 0 { page = 1 }
+	if page > MaxPage { page = MaxPage }
+
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if pageSize <= 0 || pageSize > globals.Env.PageMaxSize { pageSize = globals.Env.PageMaxSize }
+
+	pagination := Pagination{
+		Page: page,
+		PageSize: pageSize,
+		Offset: (page - 1) * pageSize,
+		path: ctx.Request().URL.Path,
+		query: query,
+	}
+
+	ctx.Set("PAGINATION", pagination)
+	return pagination
+}
+
+// URL returns the url of the given page, keeping the other query parameters of the request.
+func (pagination Pagination) URL(page int) string {
+	query := url.Values{}
+	for key, values := range pagination.query { query[key] = values }
+	query.Set("page", strconv.Itoa(page))
+
+	return pagination.path + "?" + query.Encode()
+}
+
+// Next returns the url of the next page.
+func (pagination Pagination) Next() string {
+	return pagination.URL(pagination.Page + 1)
+}
+
+// Prev returns the url of the previous page, "" on the first one.
+func (pagination Pagination) Prev() string {
+	if pagination.Page <= 1 { return "" }
+	return pagination.URL(pagination.Page - 1)
+}
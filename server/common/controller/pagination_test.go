@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/globals"
+)
+
+func FuzzPagination(f *testing.F) {
+	globals.Env.PageMaxSize = 20
+
+	f.Add("page=2&pageSize=10")
+	f.Add("page=999999999999999999&pageSize=-5")
+	f.Add("page=-1&pageSize=1000000")
+	f.Add("page=abc&pageSize=%zz")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, Query string) {
+		Request := &http.Request{ Method: http.MethodGet, URL: &url.URL{ Path: "/news", RawQuery: Query }, Header: http.Header{} }
+		ctx := &Context{ echo.New().NewContext(Request, httptest.NewRecorder()) }
+
+		Pagination := ctx.Pagination()
+		if Pagination.Page < 1 || Pagination.Page > MaxPage { t.Fatalf("page %d out of [1, %d]", Pagination.Page, MaxPage) }
+		if Pagination.PageSize < 1 || Pagination.PageSize > globals.Env.PageMaxSize { t.Fatalf("page size %d out of [1, %d]", Pagination.PageSize, globals.Env.PageMaxSize) }
+		if Pagination.Offset != (Pagination.Page - 1) * Pagination.PageSize { t.Fatalf("offset %d of page %d", Pagination.Offset, Pagination.Page) }
+
+		Next, err := url.Parse(Pagination.Next())
+		if err != nil { t.Fatalf("next page url: %v", err) }
+		if Next.Query().Get("page") == "" { t.Fatalf("next page url %q has no page", Next) }
+	})
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/domain"
+	"main/server/common/globals"
+)
+
+// Hides reports whether refusing access to the resource answers 404 Not Found, as if it didn't exist,
+// instead of 403 Forbidden. ACCESS_HIDE lists the hidden resources, "*" hides every one.
+// Resources are named by the refusals (domain.Forbidden): the permission's resource for middleware.Can
+// (see PermissionResource), the role for middleware.Role.
+func Hides(Resource string) bool {
+	for _, Hidden := range globals.Env.ACCESS_HIDE {
+		if Hidden == "*" || Hidden == Resource { return true }
+	}
+	return false
+}
+
+// PermissionResource names the resource a permission grants access to, permissions are named "<resource>.<action>":
+// "files.delete" refuses the "files" resource.
+func PermissionResource(permission string) string {
+	Resource, _, _ := strings.Cut(permission, ".")
+	return Resource
+}
+
+// Conceal turns a refusal of a hidden resource (see Hides) into the not found error a missing one gets,
+// other errors are returned as they are. ErrorHandler conceals every error it answers.
+//
+// Notes:
+//   - The refusal's message isn't kept, the response mustn't tell a hidden resource from a missing one.
+//   - echo's 403 HTTPErrors name no resource, they're only concealed when everything is hidden ("*").
+func Conceal(err error) error {
+	if errors.Is(err, domain.ErrForbidden) {
+		if Hides(domain.Resource(err)) { return domain.NotFound("not found") }
+		return err
+	}
+
+	var httpError *echo.HTTPError
+	if errors.As(err, &httpError) && httpError.Code == http.StatusForbidden && Hides("*") { return echo.ErrNotFound }
+	return err
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"regexp"
+	"strings"
+)
+
+/* Second line of defense, whitelisted names still have to look like a column */
+var columnPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+type SortField struct {
+	Column		string
+	Desc		bool
+}
+
+// Sort is the parsed "sort" query parameter, in order of precedence.
+type Sort []SortField
+
+// Sort parses "?sort=-created_at,name" ("-" for descending) keeping only the allowed fields.
+// An allowed entry is either a column or "param:column", when the parameter name differs from the column.
+//
+// Example usage:
+//   storage.DB.Scopes(storage.Sorted(ctx.Sort("created_at", "name", "category:category_id"))).Find(&Products)
+func (ctx *Context) Sort(allowed ...string) Sort {
+	var sort Sort
+	columns := whitelist(allowed)
+
+	for _, field := range strings.Split(ctx.QueryParam("sort"), ",") {
+		field = strings.TrimSpace(field)
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimLeft(field, "+-")
+
+		if column, ok := columns[field]; ok { sort = append(sort, SortField{ Column: column, Desc: desc }) }
+	}
+	return sort
+}
+
+// Or is the sort, or the given default order when the request asked for none.
+//
+// Example usage:
+//   Sort := ctx.Sort("created_at", "name").Or(controller.SortField{ Column: "created_at", Desc: true })
+func (sort Sort) Or(Default ...SortField) Sort {
+	if len(sort) == 0 { return Default }
+	return sort
+}
+
+// Filter operators, used as "filter[name][operator]=value", eq when omitted.
+const (
+	FilterEq	= "eq"
+	FilterNe	= "ne"
+	FilterGt	= "gt"
+	FilterGte	= "gte"
+	FilterLt	= "lt"
+	FilterLte	= "lte"
+	FilterLike	= "like"
+	FilterIn	= "in"
+)
+
+// FilterSchema whitelists the filters a route accepts, mapping their names to columns.
+type FilterSchema map[string]string
+
+type Filter struct {
+	Column		string
+	Operator	string
+	Values		[]string
+}
+
+type Filters []Filter
+
+// Filters parses "?filter[type]=pdf&filter[size][lte]=1024&filter[status][in]=draft,published",
+// keeping only the filters of the schema. Unknown names or operators are ignored.
+//
+// Example usage:
+//   Filters := ctx.Filters(controller.FilterSchema{ "type": "type", "size": "size" })
+//   storage.DB.Scopes(storage.Filtered(Filters)).Find(&Files)
+func (ctx *Context) Filters(schema FilterSchema) Filters {
+	var filters Filters
+
+	for key, values := range ctx.QueryParams() {
+		name, operator, ok := parseFilterKey(key)
+		if !ok || len(values) == 0 { continue }
+
+		column, known := schema[name]
+		if !known || !columnPattern.MatchString(column) { continue }
+
+		switch operator {
+			case FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte, FilterLike:
+				filters = append(filters, Filter{ Column: column, Operator: operator, Values: values[:1] })
+			case FilterIn:
+				filters = append(filters, Filter{ Column: column, Operator: operator, Values: strings.Split(values[0], ",") })
+		}
+	}
+	return filters
+}
+
+/* "filter[name]" or "filter[name][operator]" */
+func parseFilterKey(key string) (string, string, bool) {
+	rest, found := strings.CutPrefix(key, "filter[")
+	if !found { return "", "", false }
+
+	name, rest, found := strings.Cut(rest, "]")
+	if !found || name == "" { return "", "", false }
+	if rest == "" { return name, FilterEq, true }
+
+	operator, found := strings.CutPrefix(rest, "[")
+	if !found || !strings.HasSuffix(operator, "]") { return "", "", false }
+	return name, strings.TrimSuffix(operator, "]"), true
+}
+
+func whitelist(allowed []string) map[string]string {
+	columns := map[string]string{}
+	for _, entry := range allowed {
+		param, column, mapped := strings.Cut(entry, ":")
+		if !mapped { column = param }
+		if columnPattern.MatchString(column) { columns[param] = column }
+	}
+	return columns
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+
+	"github.com/a-h/templ"
+
+	"main/build/view"
+)
+
+/* Buffers grown past this aren't pooled, one huge page shouldn't keep its memory forever */
+const maxPooledBuffer = 1 << 20
+
+var buffers = sync.Pool{ New: func() any { return new(bytes.Buffer) } }
+
+// rendered renders the component into a pooled buffer before anything is written to the response.
+// When rendering fails the buffer holds the error page instead, within the layout if the layout itself renders.
+// The buffer must be given back with release.
+//
+// Returns:
+//   - The buffer with the rendered html.
+//   - false when the component failed and the buffer holds the error page.
+func (ctx *Context) rendered(component templ.Component) (*bytes.Buffer, bool) {
+	Body := buffers.Get().(*bytes.Buffer)
+	Body.Reset()
+
+	err := component.Render(ctx.Request().Context(), Body)
+	if err == nil { return Body, true }
+
+	ctx.Log("Rendering failed: ", err)
+	Body.Reset()
+	if err := ctx.page(view.ErrorPage(ctx.RequestID())).Render(ctx.Request().Context(), Body); err != nil {
+		Body.Reset()
+		view.ErrorPage(ctx.RequestID()).Render(ctx.Request().Context(), Body)
+	}
+	return Body, false
+}
+
+func release(Body *bytes.Buffer) {
+	if Body.Cap() <= maxPooledBuffer { buffers.Put(Body) }
+}
+
+// renderBuffered writes the component with the status code once it's fully rendered,
+// a failed render is answered with the error page and 500 (Internal Server Error) instead.
+func (ctx *Context) renderBuffered(code int, component templ.Component) error {
+	Body, ok := ctx.rendered(component)
+	defer release(Body)
+
+	if !ok { code = http.StatusInternalServerError }
+	return ctx.HTMLBlob(code, Body.Bytes())
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+	"log"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID stores the request ID in a context.Context so it follows the request into services and goroutines.
+func WithRequestID(parent context.Context, RequestID string) context.Context {
+	return context.WithValue(parent, requestIDKey{}, RequestID)
+}
+
+// RequestIDFrom returns the request ID stored by WithRequestID, or "" when there is none.
+func RequestIDFrom(ctx context.Context) string {
+	RequestID, _ := ctx.Value(requestIDKey{}).(string)
+	return RequestID
+}
+
+type actorKey struct{}
+
+// WithActor stores who makes the request in a context.Context, so what's saved with it (see package audit) is
+// attributed to them. It's asked for when it's needed, the user is signed in by middleware running after it's stored.
+func WithActor(parent context.Context, Actor func() Actor) context.Context {
+	return context.WithValue(parent, actorKey{}, Actor)
+}
+
+// ActorFrom returns who makes the request stored by WithActor, false when there's none (a job, a command).
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	Who, ok := ctx.Value(actorKey{}).(func() Actor)
+	if !ok { return Actor{}, false }
+	return Who(), true
+}
+
+// RequestID returns the ID assigned to the request by middleware.RequestID.
+//
+// Example usage:
+//   return ctx.Html(view.ErrorPage(ctx.RequestID()))
+func (ctx *Context) RequestID() string {
+	return RequestIDFrom(ctx.Request().Context())
+}
+
+// Log prints to the standard logger prefixed with the request ID, so every line of one request can be correlated.
+func (ctx *Context) Log(v ...any) {
+	log.Print(append([]any{"[" + ctx.RequestID() + "] "}, v...)...)
+}
+
+// Actor is who makes a request, as audit trails record it: the signed in user (nil for anonymous requests), their
+// address and browser, and the request ID to find its log lines.
+type Actor struct {
+	UsersID		*uint
+	IP			string
+	UserAgent	string
+	RequestID	string
+}
+
+// Actor returns who makes the request.
+//
+// Example usage:
+//   Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "product"))
+func (ctx *Context) Actor() Actor {
+	Who := Actor{ IP: ctx.RealIP(), UserAgent: ctx.Request().UserAgent(), RequestID: ctx.RequestID() }
+	if User, ok := ctx.CurrentUser(); ok { Who.UsersID = &User.ID }
+	return Who
+}
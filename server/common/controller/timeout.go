@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+)
+
+// RegisterWithTimeout registers a route handler like Register, giving it a deadline.
+// The request context is cancelled once the timeout passes, which aborts queries made through ctx.DB()
+// and anything else using ctx.Request().Context(). When the deadline was hit and nothing was written yet,
+// the timeout error component is rendered with 504 (Gateway Timeout) instead of the handler's error.
+//
+// Example usage:
+//   app.GET("/products/list", controller.RegisterWithTimeout(10 * time.Second, list))
+//
+// Notes:
+//   - The handler isn't preempted, code which ignores the context (storage.DB without WithContext) still runs to its end.
+func RegisterWithTimeout(timeout time.Duration, handlerFunc func(*Context) error) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.(*Context)
+		parent := ctx.Request()
+
+		deadline, cancel := context.WithTimeout(parent.Context(), timeout)
+		defer cancel()
+
+		ctx.SetRequest(parent.WithContext(deadline))
+		err := handlerFunc(ctx)
+
+		/* Rendering checks the context too, the timeout page needs the original one */
+		ctx.SetRequest(parent)
+		if !errors.Is(deadline.Err(), context.DeadlineExceeded) || ctx.Response().Committed { return err }
+
+		ctx.Log("Handler timed out after ", timeout, ": ", err)
+		if ctx.IsHtmx() { return ctx.Renders(http.StatusGatewayTimeout, view.TimeoutError(ctx.RequestID())) }
+		return ctx.HtmlWithStatus(http.StatusGatewayTimeout, view.TimeoutError(ctx.RequestID()))
+	}
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Database is the application's database handle, set by storage.Connect.
+// The controller can't import storage (storage imports the controller), handlers use ctx.DB() instead.
+var Database *gorm.DB
+
+// ErrRollback makes ctx.Tx roll back without the handler failing.
+var ErrRollback = errors.New("transaction rolled back")
+
+// DB returns the database handle of the request: the transaction of ctx.Tx or of a middleware (Preview) when
+// there is one, Database otherwise.
+// Queries are bound to the request context, they're cancelled with it.
+//
+// Example usage:
+//   var Products []model.Products
+//   ctx.DB().Where(&model.Products{Public: true}).Find(&Products)
+func (ctx *Context) DB() *gorm.DB {
+	if tx, ok := ctx.Get("DB").(*gorm.DB); ok && tx != nil { return tx.WithContext(ctx.Request().Context()) }
+	return Database.WithContext(ctx.Request().Context())
+}
+
+// Tx runs fn inside a transaction, committed when fn returns nil and rolled back otherwise, a panic of fn included
+// (it's rolled back, then the panic goes on).
+// While fn runs ctx.DB() returns the transaction, so helpers called from fn take part in it.
+// Inside another transaction it becomes a savepoint, rolling back only fn's work.
+//
+// Example usage:
+//   err := ctx.Tx(func(tx *gorm.DB) error {
+//      if err := tx.Create(&Order).Error; err != nil { return err }
+//      return tx.Model(&Product).Update("Stock", gorm.Expr("stock - 1")).Error
+//   })
+//
+// Returns:
+//   - fn's error, nil when it returned ErrRollback.
+//   - The error of beginning or committing the transaction.
+func (ctx *Context) Tx(fn func(tx *gorm.DB) error) error {
+	parent := ctx.Get("DB")
+	defer ctx.Set("DB", parent)
+
+	err := ctx.DB().Transaction(func(tx *gorm.DB) error {
+		ctx.Set("DB", tx)
+		return fn(tx)
+	})
+
+	if errors.Is(err, ErrRollback) { return nil }
+	return err
+}
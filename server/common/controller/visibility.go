@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"main/server/common/domain"
+	"main/server/model"
+)
+
+// PrivatePermission lets users download the files only admins may (model.VisibilityAdmin).
+const PrivatePermission = "files.private"
+
+// Visible is the DownloadGuard of the files' visibility: public files are for everyone, authenticated ones
+// for signed in users and admin ones for users with PrivatePermission. Routes serving files must identify
+// the user (middleware.Identify) for the last two to be downloadable at all. Files shared with the request are
+// downloadable whatever their visibility, except the ones the malware scanner flagged: until they're reviewed
+// only users with PrivatePermission may download them.
+func Visible(ctx *Context, file model.Files) error {
+	Shared, _ := ctx.Get("SHARED").(uint)
+
+	switch {
+		case file.UnderReview():
+			if ctx.Can(PrivatePermission) { return nil }
+		case file.IsPublic(), Shared == file.ID:
+			return nil
+		case file.Visibility == model.VisibilityAuthenticated:
+			if _, ok := ctx.CurrentUser(); ok { return nil }
+		case file.Visibility == model.VisibilityAdmin:
+			if ctx.Can(PrivatePermission) { return nil }
+	}
+	return domain.Forbidden("files", "file is not visible to the current user")
+}
+
+// Share lets the request download the file whatever its visibility, once it verified a signed url (uploader.VerifyURL).
+func (ctx *Context) Share(file model.Files) {
+	ctx.Set("SHARED", file.ID)
+}
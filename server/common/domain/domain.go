@@ -0,0 +1,113 @@
+// Package domain is the taxonomy of errors services and repositories return, so callers branch on the kind
+// of an error with errors.Is instead of matching its message.
+//
+// A service declares its errors as one of the kinds, with its own message:
+//
+//   var ErrNotFound = domain.NotFound("preview channel not found")
+//
+//   errors.Is(err, previewer.ErrNotFound) // the precise error
+//   errors.Is(err, domain.ErrNotFound)    // its kind
+//
+// Handlers may return them as they are, controller.ErrorHandler answers with the kind's HTTP status and
+// translated message ("error.<code>"), and logs it with the kind's Severity.
+package domain
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kinds of errors.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	ErrUnsupportedType = errors.New("unsupported type")
+	ErrConflict = errors.New("conflict")
+	ErrInvalid = errors.New("invalid")
+	ErrForbidden = errors.New("forbidden")
+	ErrBusy = errors.New("busy")
+)
+
+type Severity string
+
+const (
+	SeverityInfo		Severity = "info"
+	SeverityWarning		Severity = "warning"
+	SeverityError		Severity = "error"
+)
+
+type kind struct {
+	err			error
+	code		string
+	status		int
+	severity	Severity
+}
+
+/* Unknown errors are the server's fault, known kinds are the client's */
+var kinds = []kind{
+	{ ErrNotFound, "not_found", http.StatusNotFound, SeverityInfo },
+	{ ErrQuotaExceeded, "quota_exceeded", http.StatusRequestEntityTooLarge, SeverityWarning },
+	{ ErrUnsupportedType, "unsupported_type", http.StatusUnsupportedMediaType, SeverityInfo },
+	{ ErrConflict, "conflict", http.StatusConflict, SeverityInfo },
+	{ ErrInvalid, "invalid", http.StatusUnprocessableEntity, SeverityInfo },
+	{ ErrForbidden, "forbidden", http.StatusForbidden, SeverityInfo },
+	{ ErrBusy, "busy", http.StatusTooManyRequests, SeverityWarning },
+}
+
+var internal = kind{ nil, "internal", http.StatusInternalServerError, SeverityError }
+
+// Error is an error of a kind, with the service's own message.
+// Refusals (ErrForbidden) name the Resource refused, controller.ErrorHandler may hide it behind a 404.
+type Error struct {
+	Kind		error
+	Message		string
+	Resource	string
+}
+
+func (err *Error) Error() string { return err.Message }
+
+// Is makes errors.Is match the error's kind as well as the error itself.
+func (err *Error) Is(target error) bool { return target == err.Kind }
+
+func NotFound(Message string) error { return &Error{ Kind: ErrNotFound, Message: Message } }
+func QuotaExceeded(Message string) error { return &Error{ Kind: ErrQuotaExceeded, Message: Message } }
+func UnsupportedType(Message string) error { return &Error{ Kind: ErrUnsupportedType, Message: Message } }
+func Conflict(Message string) error { return &Error{ Kind: ErrConflict, Message: Message } }
+func Invalid(Message string) error { return &Error{ Kind: ErrInvalid, Message: Message } }
+func Forbidden(Resource string, Message string) error { return &Error{ Kind: ErrForbidden, Message: Message, Resource: Resource } }
+func Busy(Message string) error { return &Error{ Kind: ErrBusy, Message: Message } }
+
+func lookup(err error) kind {
+	for _, known := range kinds {
+		if errors.Is(err, known.err) { return known }
+	}
+	return internal
+}
+
+// Known reports whether the error is of one of the kinds.
+func Known(err error) bool {
+	return lookup(err).err != nil
+}
+
+// Code returns the machine readable code of the error's kind ("not_found", ...), "internal" for other errors.
+// Its translation key is "error." + Code.
+func Code(err error) string {
+	return lookup(err).code
+}
+
+// Status returns the HTTP status of the error's kind, 500 for other errors.
+func Status(err error) int {
+	return lookup(err).status
+}
+
+// Resource returns the resource a refusal names, "" for other errors.
+func Resource(err error) string {
+	var Known *Error
+	if errors.As(err, &Known) { return Known.Resource }
+	return ""
+}
+
+// Level returns how the error is logged, SeverityError for errors of no kind.
+func Level(err error) Severity {
+	return lookup(err).severity
+}
@@ -1,10 +1,16 @@
 package globals
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"log"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -12,8 +18,11 @@ import (
 type EnvVarsType struct {
 	Port 			string
 	GOENV			string
+	APP_URL			string
 	Uploads         string
 	PageMaxSize     int
+	Locales			string
+	DefaultLocale	string
 	DB_HOST         string
 	DB_PORT			string
 	DB_USER			string
@@ -22,6 +31,64 @@ type EnvVarsType struct {
 	DB_SSLMODE		string
 
 	SENDGRID_API_KEY string
+	SENDGRID_WEBHOOK_KEY string
+
+	COOKIE_PATH		string
+	COOKIE_DOMAIN	string
+	COOKIE_SAMESITE	string
+	COOKIE_SECURE	bool
+	COOKIE_HTTPONLY	bool
+	COOKIE_SECRET	string
+
+	PACKAGE_SECRET	string
+
+	HOOKS			string
+	HOOKS_SECRET	string
+
+	STORAGE_BACKEND	string
+	S3_ENDPOINT		string
+	S3_REGION		string
+	S3_BUCKET		string
+	S3_ACCESS_KEY	string
+	S3_SECRET_KEY	string
+	S3_PREFIX		string
+	S3_PUBLIC_URL	string
+	S3_REDIRECT		bool
+
+	STREAM_SECRET	string
+	STREAM_RATE		int
+	SHARE_SECRET	string
+	THUMBNAIL_SIZES	[]int
+	RESIZE_SIZES	[]int
+	SCHEMA_CHECK	string
+	ID_STRATEGY		string
+	PUBLIC_IDS_ONLY	bool
+	UPLOAD_MAX_SIZE	int64
+	UPLOAD_TRASH	string
+	UPLOAD_QUOTA	int64
+	UPLOAD_MEMORY	int64
+	ACCESS_HIDE		[]string
+	INTERNAL_SECRET	string
+	CHAOS			string
+	BUDGETS			string
+	SCANNER			string
+	CLAMD_ADDRESS	string
+	SCAN_ACTION		string
+	SCAN_QUARANTINE	string
+	EXIF_STRIP		bool
+	INLINE_MAX		int
+	INDEXNOW_KEY	string
+	TRANSCODE_HEIGHTS	[]int
+	EXPORT_ASYNC_ROWS	int
+	IMAGE_FORMATS	[]string
+	PASSWORD_PEPPER	string
+	PASSWORD_PEPPER_PREVIOUS	string
+	ARGON2_MEMORY	int
+	ARGON2_TIME		int
+	ARGON2_THREADS	int
+	TELEMETRY		bool
+	TELEMETRY_DAYS	int
+	TRASH_DAYS		int
 }
 
 var Env EnvVarsType
@@ -31,13 +98,166 @@ func SetupEnvironmentVariables() {
 	if err != nil { log.Fatal(err) }
 
 	/* Conversions */
-	PageMaxSize, _ := strconv.Atoi(os.Getenv("PageMaxSize"))
+	PageMaxSize, err := strconv.Atoi(os.Getenv("PageMaxSize"))
+	if err != nil || PageMaxSize <= 0 { PageMaxSize = 20 }
+
+	Locales := os.Getenv("Locales")
+	if Locales == "" { Locales = "./locales" }
+
+	DefaultLocale := os.Getenv("DefaultLocale")
+	if DefaultLocale == "" { DefaultLocale = "ka" }
+
+	Hooks := os.Getenv("HOOKS")
+	if Hooks == "" { Hooks = "./hooks.json" }
+
+	S3Redirect, _ := strconv.ParseBool(os.Getenv("S3_REDIRECT"))
+
+	/* Cookies are secure unless told otherwise, except Secure on development (no https locally) */
+	CookieSecure, err := strconv.ParseBool(os.Getenv("COOKIE_SECURE"))
+	if err != nil { CookieSecure = os.Getenv("GOENV") != "development" }
+
+	CookieHttpOnly, err := strconv.ParseBool(os.Getenv("COOKIE_HTTPONLY"))
+	if err != nil { CookieHttpOnly = true }
+
+	CookiePath := os.Getenv("COOKIE_PATH")
+	if CookiePath == "" { CookiePath = "/" }
+
+	CookieSameSite := os.Getenv("COOKIE_SAMESITE")
+	if CookieSameSite == "" { CookieSameSite = "lax" }
+
+	/* Signed and encrypted cookies can't live without a secret, a random one only survives until restart. The server
+	   doesn't start with one outside of development (see Validate), the command line tools needn't have it */
+	CookieSecret := os.Getenv("COOKIE_SECRET")
+	if CookieSecret == "" {
+		random := make([]byte, 32)
+		rand.Read(random)
+		CookieSecret = hex.EncodeToString(random)
+	}
+
+	/* Stream urls are signed with a key derived from the cookie secret unless they have their own */
+	StreamSecret := os.Getenv("STREAM_SECRET")
+	if StreamSecret == "" { StreamSecret = derive(CookieSecret, "stream") }
+
+	StreamRate, _ := strconv.Atoi(os.Getenv("STREAM_RATE"))
+
+	/* Shared download links as well, see uploader.SignURL */
+	ShareSecret := os.Getenv("SHARE_SECRET")
+	if ShareSecret == "" { ShareSecret = derive(CookieSecret, "share") }
+
+	/* Widths of the thumbnails made of image uploads, "none" makes none */
+	ThumbnailSizes := []int{160, 480, 1200}
+	if Sizes := os.Getenv("THUMBNAIL_SIZES"); Sizes != "" {
+		ThumbnailSizes = nil
+		for _, Size := range strings.Split(Sizes, ",") {
+			if Width, err := strconv.Atoi(strings.TrimSpace(Size)); err == nil && Width > 0 { ThumbnailSizes = append(ThumbnailSizes, Width) }
+		}
+	}
+
+	/* /media/:id/resize refuses other sizes, each size requested is cached so they must be few */
+	ResizeSizes := []int{160, 320, 480, 640, 960, 1280, 1920}
+	if Sizes := os.Getenv("RESIZE_SIZES"); Sizes != "" {
+		ResizeSizes = nil
+		for _, Size := range strings.Split(Sizes, ",") {
+			if Pixels, err := strconv.Atoi(strings.TrimSpace(Size)); err == nil && Pixels > 0 { ResizeSizes = append(ResizeSizes, Pixels) }
+		}
+	}
+
+	/* "warn" logs what the database lacks at startup, "strict" refuses to start, "off" skips the check */
+	SchemaCheck := os.Getenv("SCHEMA_CHECK")
+	if SchemaCheck == "" { SchemaCheck = "warn" }
+
+	/* Public identifiers of files and content, see package ids */
+	IDStrategy := os.Getenv("ID_STRATEGY")
+	if IDStrategy != "uuid" { IDStrategy = "ulid" }
+	PublicIDsOnly, _ := strconv.ParseBool(os.Getenv("PUBLIC_IDS_ONLY"))
+
+	/* Megabytes in the environment, bytes in Env. It bounds every request body, whatever the file types allow */
+	UploadMaxSize, err := strconv.ParseFloat(os.Getenv("UPLOAD_MAX_SIZE"), 64)
+	if err != nil || UploadMaxSize <= 0 { UploadMaxSize = 100 }
+
+	/* Megabytes each user may store in uploads unless their role or they have their own quota (see package quota),
+	   0 is unlimited */
+	UploadQuota, err := strconv.ParseFloat(os.Getenv("UPLOAD_QUOTA"), 64)
+	if err != nil || UploadQuota < 0 { UploadQuota = 1024 }
+
+	/* Megabytes of a multipart form's files kept in memory while it's parsed (ctx.FormFile), the rest is spilled to
+	   temporary files. Forms read with uploader.Receive stream to disk and keep none */
+	UploadMemory, err := strconv.ParseFloat(os.Getenv("UPLOAD_MEMORY"), 64)
+	if err != nil || UploadMemory < 0 { UploadMemory = 4 }
+
+	/* Blob key prefix removed files are moved under instead of deleted, empty deletes them */
+	UploadTrash := strings.Trim(os.Getenv("UPLOAD_TRASH"), "/")
+	if UploadTrash != "" { UploadTrash += "/" }
+
+	/* Resources whose refusals answer 404 instead of 403, see controller.Hides */
+	var AccessHide []string
+	for _, Resource := range strings.Split(os.Getenv("ACCESS_HIDE"), ",") {
+		if Resource = strings.TrimSpace(Resource); Resource != "" { AccessHide = append(AccessHide, Resource) }
+	}
+
+	/* Internal calls (/internal/...) are signed with the cookie secret unless they have their own, see package signature */
+	InternalSecret := os.Getenv("INTERNAL_SECRET")
+	if InternalSecret == "" { InternalSecret = CookieSecret }
+
+	/* Infected uploads are kept in this directory of the server's disk with SCAN_ACTION=quarantine */
+	ScanQuarantine := strings.TrimRight(os.Getenv("SCAN_QUARANTINE"), "/")
+	if ScanQuarantine == "" { ScanQuarantine = "quarantine" }
+
+	/* Uploaded jpegs and pngs are re-encoded without their metadata and upright, unless EXIF_STRIP=false */
+	ExifStrip, err := strconv.ParseBool(os.Getenv("EXIF_STRIP"))
+	if err != nil { ExifStrip = true }
+
+	/* Images up to this many bytes are kept in Files.Base64 as a data uri too, 0 keeps none */
+	InlineMax, err := strconv.Atoi(os.Getenv("INLINE_MAX"))
+	if err != nil || InlineMax < 0 { InlineMax = 8 * 1024 }
+
+	/* Heights (px) of the mp4 copies made of uploaded videos, none larger than the video */
+	TranscodeHeights := []int{720, 480}
+	if Heights := os.Getenv("TRANSCODE_HEIGHTS"); Heights != "" {
+		TranscodeHeights = nil
+		for _, Height := range strings.Split(Heights, ",") {
+			if Pixels, err := strconv.Atoi(strings.TrimSpace(Height)); err == nil && Pixels > 0 { TranscodeHeights = append(TranscodeHeights, Pixels) }
+		}
+	}
+
+	/* Exports of more rows are made in the background and mailed as a link, the smaller ones are streamed */
+	ExportAsyncRows, err := strconv.Atoi(os.Getenv("EXPORT_ASYNC_ROWS"))
+	if err != nil || ExportAsyncRows < 0 { ExportAsyncRows = 5000 }
+
+	/* Modern formats uploaded images are also converted to (see package converter), none unless asked for */
+	var ImageFormats []string
+	for _, Format := range strings.Split(os.Getenv("IMAGE_FORMATS"), ",") {
+		if Format = strings.ToLower(strings.TrimSpace(Format)); Format == "webp" || Format == "avif" { ImageFormats = append(ImageFormats, Format) }
+	}
+
+	/* Passwords are hashed with argon2id, see auth.HashPassword: memory in KiB, passes and lanes */
+	Argon2Memory, err := strconv.Atoi(os.Getenv("ARGON2_MEMORY"))
+	if err != nil || Argon2Memory < 8 * 1024 { Argon2Memory = 64 * 1024 }
+	Argon2Time, err := strconv.Atoi(os.Getenv("ARGON2_TIME"))
+	if err != nil || Argon2Time < 1 { Argon2Time = 3 }
+	Argon2Threads, err := strconv.Atoi(os.Getenv("ARGON2_THREADS"))
+	if err != nil || Argon2Threads < 1 || Argon2Threads > 255 { Argon2Threads = 2 }
+
+	/* Feature usage is only counted when the deployment's owner asks for it, see package telemetry */
+	Telemetry, _ := strconv.ParseBool(os.Getenv("TELEMETRY"))
+	TelemetryDays, err := strconv.Atoi(os.Getenv("TELEMETRY_DAYS"))
+	if err != nil || TelemetryDays < 1 { TelemetryDays = 90 }
+
+	/* Trashed rows are purged after these days, 0 keeps them until they're purged by hand */
+	TrashDays, err := strconv.Atoi(os.Getenv("TRASH_DAYS"))
+	if err != nil || TrashDays < 0 { TrashDays = 30 }
+
+	Pepper := secret("PASSWORD_PEPPER")
+	if Pepper == "" && os.Getenv("GOENV") != "development" { log.Print("PASSWORD_PEPPER is not set, passwords are hashed without a pepper") }
 
 	Env = EnvVarsType{
 		Port: os.Getenv("Port"),
 		GOENV: os.Getenv("GOENV"),
+		APP_URL: strings.TrimRight(os.Getenv("APP_URL"), "/"),
 		Uploads: os.Getenv("Uploads"),
 		PageMaxSize: PageMaxSize,
+		Locales: Locales,
+		DefaultLocale: DefaultLocale,
 		DB_HOST: os.Getenv("DB_HOST"),
 		DB_PORT: os.Getenv("DB_PORT"),
 		DB_USER: os.Getenv("DB_USER"),
@@ -45,5 +265,87 @@ func SetupEnvironmentVariables() {
 		DB_NAME: os.Getenv("DB_NAME"),
 		DB_SSLMODE: os.Getenv("DB_SSLMODE"),
 		SENDGRID_API_KEY: os.Getenv("SENDGRID_API_KEY"),
+		SENDGRID_WEBHOOK_KEY: os.Getenv("SENDGRID_WEBHOOK_KEY"),
+		COOKIE_PATH: CookiePath,
+		COOKIE_DOMAIN: os.Getenv("COOKIE_DOMAIN"),
+		COOKIE_SAMESITE: CookieSameSite,
+		COOKIE_SECURE: CookieSecure,
+		COOKIE_HTTPONLY: CookieHttpOnly,
+		COOKIE_SECRET: CookieSecret,
+		PACKAGE_SECRET: os.Getenv("PACKAGE_SECRET"),
+		HOOKS: Hooks,
+		HOOKS_SECRET: os.Getenv("HOOKS_SECRET"),
+		STORAGE_BACKEND: os.Getenv("STORAGE_BACKEND"),
+		S3_ENDPOINT: os.Getenv("S3_ENDPOINT"),
+		S3_REGION: os.Getenv("S3_REGION"),
+		S3_BUCKET: os.Getenv("S3_BUCKET"),
+		S3_ACCESS_KEY: os.Getenv("S3_ACCESS_KEY"),
+		S3_SECRET_KEY: os.Getenv("S3_SECRET_KEY"),
+		S3_PREFIX: os.Getenv("S3_PREFIX"),
+		S3_PUBLIC_URL: os.Getenv("S3_PUBLIC_URL"),
+		S3_REDIRECT: S3Redirect,
+		STREAM_SECRET: StreamSecret,
+		STREAM_RATE: StreamRate,
+		SHARE_SECRET: ShareSecret,
+		THUMBNAIL_SIZES: ThumbnailSizes,
+		RESIZE_SIZES: ResizeSizes,
+		SCHEMA_CHECK: SchemaCheck,
+		ID_STRATEGY: IDStrategy,
+		PUBLIC_IDS_ONLY: PublicIDsOnly,
+		UPLOAD_MAX_SIZE: int64(UploadMaxSize * 1024 * 1024),
+		UPLOAD_TRASH: UploadTrash,
+		UPLOAD_QUOTA: int64(UploadQuota * 1024 * 1024),
+		UPLOAD_MEMORY: int64(UploadMemory * 1024 * 1024),
+		ACCESS_HIDE: AccessHide,
+		INTERNAL_SECRET: InternalSecret,
+		CHAOS: os.Getenv("CHAOS"),
+		BUDGETS: os.Getenv("BUDGETS"),
+		SCANNER: os.Getenv("SCANNER"),
+		CLAMD_ADDRESS: os.Getenv("CLAMD_ADDRESS"),
+		SCAN_ACTION: os.Getenv("SCAN_ACTION"),
+		SCAN_QUARANTINE: ScanQuarantine,
+		EXIF_STRIP: ExifStrip,
+		INLINE_MAX: InlineMax,
+		INDEXNOW_KEY: os.Getenv("INDEXNOW_KEY"),
+		TRANSCODE_HEIGHTS: TranscodeHeights,
+		EXPORT_ASYNC_ROWS: ExportAsyncRows,
+		IMAGE_FORMATS: ImageFormats,
+		PASSWORD_PEPPER: Pepper,
+		PASSWORD_PEPPER_PREVIOUS: secret("PASSWORD_PEPPER_PREVIOUS"),
+		ARGON2_MEMORY: Argon2Memory,
+		ARGON2_TIME: Argon2Time,
+		ARGON2_THREADS: Argon2Threads,
+		TELEMETRY: Telemetry,
+		TELEMETRY_DAYS: TelemetryDays,
+		TRASH_DAYS: TrashDays,
 	}
-}
\ No newline at end of file
+}
+
+// Validate checks the settings the server can't run without, which the command line tools needn't have:
+// COOKIE_SECRET, outside of development.
+func Validate() error {
+	if os.Getenv("COOKIE_SECRET") != "" { return nil }
+	if os.Getenv("GOENV") != "development" { return errors.New("COOKIE_SECRET is not set") }
+
+	log.Print("COOKIE_SECRET is not set, using a random one")
+	return nil
+}
+
+/* A secret is read from the variable itself or, for the secrets mounted as files (Docker, Kubernetes), from the file
+   named by the variable with _FILE appended */
+func secret(Name string) string {
+	if Value := os.Getenv(Name); Value != "" { return Value }
+
+	File := os.Getenv(Name + "_FILE")
+	if File == "" { return "" }
+	Content, err := os.ReadFile(File)
+	if err != nil { log.Fatal("Reading ", Name, "_FILE: ", err) }
+	return strings.TrimRight(string(Content), "\r\n")
+}
+
+/* A key of its own for each purpose, so what's signed for one can't be replayed for another */
+func derive(Secret string, Purpose string) string {
+	mac := hmac.New(sha256.New, []byte(Secret))
+	mac.Write([]byte(Purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
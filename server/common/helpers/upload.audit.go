@@ -0,0 +1,59 @@
+package uploader
+
+import (
+	"errors"
+	"log"
+
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// Audit records what the Actor did to a file (model.FileUploaded, ...) in its history, File_events. Detail names
+// the file, its current name when it's "". The history doesn't hold the change back: a failure is only logged.
+//
+// Example usage:
+//   if err := uploader.Remove(ctx.Request().Context(), File.ID); err != nil { return err }
+//   uploader.Audit(ctx.Actor(), File.ID, model.FileDeleted, File.Original)
+func Audit(Actor controller.Actor, FileID uint, Action string, Detail string) {
+	if Detail == "" {
+		var File model.Files
+		if storage.DB.Unscoped().Select("id", "name", "original").First(&File, FileID).Error == nil { Detail = File.Original }
+		if Detail == "" { Detail = File.Name }
+	}
+
+	Event := model.File_events{
+		FileID: FileID, Action: Action, UsersID: Actor.UsersID, IP: Actor.IP, UserAgent: Actor.UserAgent, RequestID: Actor.RequestID, Detail: Detail,
+	}
+	if err := storage.DB.Create(&Event).Error; err != nil { log.Print("[", Actor.RequestID, "] Recording ", Action, " of file ", FileID, ": ", err) }
+}
+
+// Audited records the Action of a stored upload (see Audit), a failed one isn't recorded. It returns the upload,
+// so it wraps the pipeline.
+//
+// Example usage:
+//   Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "product"))
+func Audited(Actor controller.Actor, Action string, Upload *UploadResponse) *UploadResponse {
+	if Upload.Success { Audit(Actor, uint(Upload.ID), Action, Upload.Name) }
+	return Upload
+}
+
+// Aborted records an upload the client aborted (ErrAborted) as a model.FileAborted event without a file, other
+// errors aren't recorded. Receive leaves nothing of the upload behind, the event is all there is of it.
+//
+// Example usage:
+//   Form, err := uploader.Receive(ctx.Request(), 1)
+//   if err != nil {
+//       uploader.Aborted(ctx.Actor(), err)
+//       return respond(ctx, &uploader.Received{}, uploader.Refused(err))
+//   }
+func Aborted(Actor controller.Actor, err error) {
+	if errors.Is(err, ErrAborted) { Audit(Actor, 0, model.FileAborted, err.Error()) }
+}
+
+// Events returns the history of a file, oldest first, with the users of the events.
+func Events(FileID uint) ([]model.File_events, error) {
+	var History []model.File_events
+	err := storage.DB.Preload("Users").Where("file_id = ?", FileID).Order("created_at, id").Find(&History).Error
+	return History, err
+}
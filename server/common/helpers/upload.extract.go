@@ -0,0 +1,117 @@
+package uploader
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"main/server/common/domain"
+	"main/server/service/filetypes"
+)
+
+// MaxEntries is how many files an archive may hold, folders and skipped entries aside.
+const MaxEntries = 200
+
+var (
+	ErrArchive = domain.Invalid("not a readable zip archive")
+	ErrEntryPath = domain.Invalid("archive entry has an unsafe path")
+)
+
+// Extract hands Each the files of a zip archive one after the other, with their Path in the archive, each one as a
+// ReceivedFile named after the entry's base name, whose temporary file is removed once Each returns: an archive never
+// takes more room on disk than its largest file. Entries Extract refuses are handed over with their error and an
+// empty file:
+//   - ErrEntryPath for paths leaving the archive ("../", absolute ones, zip-slip), links and other special files.
+//   - filetypes.ErrUnknown, wrapped, for files of a type which can't be uploaded.
+//   - ErrTooLarge, wrapped, for files over the limit of their type (filetypes.Limit). The limit is checked on what's
+//     actually decompressed, not on the size the archive claims.
+// Folders, and the files operating systems add (__MACOSX/, ".DS_Store" and other hidden files), are skipped.
+//
+// Example usage:
+//   err := uploader.Extract(Archive, func(Path string, Entry uploader.ReceivedFile, err error) {
+//       if err != nil { Manifest = append(Manifest, uploader.Unextracted(Path, err)); return }
+//       Manifest = append(Manifest, uploader.Store(Entry.Content, Entry.Name, Entry.Size, Entry.ContentType, ""))
+//   })
+//
+// Returns:
+//   - ErrArchive when the file isn't a zip archive, before Each is called.
+//   - ErrTooMany when the archive holds more than MaxEntries files, before Each is called.
+func Extract(Archive ReceivedFile, Each func(Path string, Entry ReceivedFile, err error)) error {
+	Reader, err := zip.NewReader(Archive.Content, Archive.Size)
+	if err != nil { return fmt.Errorf("%w: %v", ErrArchive, err) }
+
+	Entries := []*zip.File{}
+	for _, Entry := range Reader.File {
+		if !skipped(Entry) { Entries = append(Entries, Entry) }
+	}
+	if len(Entries) > MaxEntries { return fmt.Errorf("%w: an archive holds at most %d files", ErrTooMany, MaxEntries) }
+
+	for _, Entry := range Entries {
+		Path := clean(Entry.Name)
+		File, err := extract(Entry, Archive.Field)
+		if err != nil {
+			Each(Path, ReceivedFile{ Field: Archive.Field, Name: clean(path.Base(Entry.Name)) }, err)
+			continue
+		}
+		Each(Path, File, nil)
+		File.Content.Close()
+		os.Remove(File.Content.Name())
+	}
+	return nil
+}
+
+// Unextracted builds the response of an entry Extract refused.
+func Unextracted(Name string, err error) *UploadResponse {
+	var Upload *UploadResponse
+	switch {
+		case errors.Is(err, ErrTooLarge): Upload = Failed(CodeTooLarge, err.Error())
+		case errors.Is(err, filetypes.ErrUnknown): Upload = Rejected(err)
+		default: Upload = Failed(CodeArchive, err.Error())
+	}
+	Upload.Name = Name
+	return Upload
+}
+
+/* Folders and the files operating systems leave in archives, nobody meant to upload them */
+func skipped(Entry *zip.File) bool {
+	if Entry.FileInfo().IsDir() || strings.HasSuffix(Entry.Name, "/") { return true }
+	for _, Segment := range strings.Split(strings.ReplaceAll(Entry.Name, "\\", "/"), "/") {
+		if Segment == "__MACOSX" { return true }
+	}
+	return strings.HasPrefix(path.Base(Entry.Name), ".")
+}
+
+/* Entries are never written where their path says, only their base name is kept, but a path leaving the archive
+   is a crafted one and its file isn't trusted either */
+func extract(Entry *zip.File, Field string) (ReceivedFile, error) {
+	Name := path.Clean(strings.ReplaceAll(Entry.Name, "\\", "/"))
+	if path.IsAbs(Name) || Name == ".." || strings.HasPrefix(Name, "../") || strings.Contains(Name, ":") {
+		return ReceivedFile{}, fmt.Errorf("%w: %s", ErrEntryPath, Entry.Name)
+	}
+	if !Entry.Mode().IsRegular() { return ReceivedFile{}, fmt.Errorf("%w: %s is not a file", ErrEntryPath, Entry.Name) }
+
+	Base := clean(path.Base(Name))
+	Type, found := filetypes.Resolve(Extension(Base))
+	if !found { return ReceivedFile{}, fmt.Errorf("%w: %s", filetypes.ErrUnknown, Extension(Base)) }
+
+	/* A declared size over the limit is refused before anything is decompressed */
+	if Limit := filetypes.Limit(Extension(Base)); Entry.UncompressedSize64 > uint64(Limit) {
+		return ReceivedFile{}, fmt.Errorf("%w: %s is limited to %d bytes", ErrTooLarge, Base, Limit)
+	}
+
+	Content, err := Entry.Open()
+	if err != nil { return ReceivedFile{}, fmt.Errorf("%w: %v", ErrArchive, err) }
+	defer Content.Close()
+
+	/* The content is sniffed by the pipeline, the type claimed for it is the one its extension stands for */
+	ContentType := mime.TypeByExtension(Extension(Base))
+	if Mimes := filetypes.List(Type.Mimes); len(Mimes) > 0 { ContentType = Mimes[0] }
+
+	File, err := receive(Field, Base, ContentType, Content)
+	if err != nil && !errors.Is(err, ErrTooLarge) { return File, fmt.Errorf("%w: %v", ErrArchive, err) }
+	return File, err
+}
@@ -0,0 +1,134 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"main/server/common/domain"
+	"main/server/service/filetypes"
+)
+
+// FetchTimeout bounds the whole download of a remote file, headers and body.
+const FetchTimeout = 30 * time.Second
+
+// MaxRedirects is how many redirects a remote file may go through.
+const MaxRedirects = 3
+
+var (
+	ErrURL = domain.Invalid("only public http and https urls can be fetched")
+	ErrFetch = domain.Invalid("remote file couldn't be fetched")
+)
+
+/* Every connection, redirects included, is checked once the name is resolved: a public name resolving to a private
+   address (DNS rebinding) is refused as well */
+var fetcher = &http.Client{
+	Timeout: FetchTimeout,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{ Timeout: 10 * time.Second, Control: public }).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		ResponseHeaderTimeout: 15 * time.Second,
+	},
+	CheckRedirect: func(Request *http.Request, via []*http.Request) error {
+		if len(via) >= MaxRedirects { return fmt.Errorf("%w: more than %d redirects", ErrFetch, MaxRedirects) }
+		return checkURL(Request.URL)
+	},
+}
+
+// Fetch downloads a remote file into a form like Receive's, holding it as its "file" field, for Store to take in.
+// The file is cut off as soon as it exceeds the limit of its extension (filetypes.Limit), as a received one is.
+// Its name is the url's last segment, with the extension of the served Content-Type when it has none.
+//
+// Example usage:
+//   Form, err := uploader.Fetch(ctx.Request().Context(), "https://example.com/photo.jpg")
+//   if err != nil { return uploader.Unfetched(err) }
+//   defer Form.Close()
+//   File := Form.Files[0]
+//   Upload := uploader.Store(File.Content, File.Name, File.Size, File.ContentType, "")
+//
+// Returns:
+//   - ErrURL for anything but an http(s) url of a public address, private networks and the host itself are refused.
+//   - ErrFetch, wrapped with the reason, when the server doesn't answer 200 in FetchTimeout.
+//   - ErrTooLarge, wrapped with the file and its limit, for a file over its limit.
+func Fetch(ctx context.Context, Raw string) (*Received, error) {
+	Address, err := url.Parse(strings.TrimSpace(Raw))
+	if err != nil { return nil, ErrURL }
+	if err := checkURL(Address); err != nil { return nil, err }
+
+	Request, err := http.NewRequestWithContext(ctx, http.MethodGet, Address.String(), nil)
+	if err != nil { return nil, ErrURL }
+	Request.Header.Set("Accept", "*/*")
+
+	Response, err := fetcher.Do(Request)
+	if err != nil {
+		if errors.Is(err, ErrURL) { return nil, ErrURL }
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	defer Response.Body.Close()
+	if Response.StatusCode != http.StatusOK { return nil, fmt.Errorf("%w: the server answered %s", ErrFetch, Response.Status) }
+
+	ContentType, _, _ := mime.ParseMediaType(Response.Header.Get("Content-Type"))
+	Name := clean(fetchedName(Response.Request.URL, ContentType))
+
+	/* A declared length over the limit is refused before anything is read */
+	if Limit := filetypes.Limit(Extension(Name)); Response.ContentLength > Limit {
+		return nil, fmt.Errorf("%w: %s is limited to %d bytes", ErrTooLarge, Name, Limit)
+	}
+
+	File, err := receive("file", Name, ContentType, Response.Body)
+	if err != nil {
+		if errors.Is(err, ErrTooLarge) { return nil, err }
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	return &Received{ Values: map[string]string{}, Files: []ReceivedFile{File} }, nil
+}
+
+// Unfetched is the UploadResponse of a remote file Fetch refused.
+func Unfetched(err error) *UploadResponse {
+	switch {
+		case errors.Is(err, ErrURL): return Failed(CodeURL, err.Error())
+		case errors.Is(err, ErrFetch): return Failed(CodeFetch, err.Error())
+		default: return Refused(err)
+	}
+}
+
+func checkURL(Address *url.URL) error {
+	if Address.Scheme != "http" && Address.Scheme != "https" { return ErrURL }
+	if Address.Hostname() == "" || Address.User != nil { return ErrURL }
+	return nil
+}
+
+/* net.Dialer's Control sees the address actually dialed, after the name was resolved */
+func public(network string, address string, _ syscall.RawConn) error {
+	Host, _, err := net.SplitHostPort(address)
+	if err != nil { return ErrURL }
+	IP := net.ParseIP(Host)
+	if IP == nil || IP.IsLoopback() || IP.IsPrivate() || IP.IsUnspecified() || IP.IsLinkLocalUnicast() ||
+		IP.IsLinkLocalMulticast() || IP.IsInterfaceLocalMulticast() || IP.IsMulticast() {
+		return fmt.Errorf("%w: %s", ErrURL, Host)
+	}
+	return nil
+}
+
+/* The last segment of the url the file came from (after redirects). Urls of scripts ("photo.php?id=3") and without
+   an extension get the one of the served Content-Type which is an upload type, the content is checked against it */
+func fetchedName(Address *url.URL, ContentType string) string {
+	Name := path.Base(Address.Path)
+	if Name == "/" || Name == "." { Name = "file" }
+	if _, found := filetypes.Resolve(Extension(Name)); found { return Name }
+
+	Extensions, _ := mime.ExtensionsByType(ContentType)
+	for _, Candidate := range Extensions {
+		if _, found := filetypes.Resolve(Candidate); found { return Name + Candidate }
+	}
+	return Name
+}
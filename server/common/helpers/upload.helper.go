@@ -1,93 +1,225 @@
 package uploader
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
 	"io"
 	"log"
+	"main/server/common/blob"
 	"main/server/common/globals"
 	"main/server/common/storage"
 	"main/server/model"
+	"main/server/service/filetypes"
+	"main/server/service/hooks"
+	"main/server/service/inspector"
+	"main/server/service/telemetry"
+	"main/server/service/thumbnailer"
+	"main/server/service/transcoder"
 	"mime/multipart"
-	"os"
-	"strconv"
+	"path"
 )
 
-type UploadResponse struct { ID int; Message string; Success bool }
-
+// File stores an uploaded file and records it in Files, see FileFor.
 func File(file *multipart.FileHeader) *UploadResponse {
+	return FileFor(file, "")
+}
+
+// FileFor stores a file uploaded for a context ("category", "product", ...) and records it in Files.
+// The file must match an enabled File_types row (extension, content type, size and context), see package filetypes.
+// When an "upload.scan" verdict hook is configured (see package hooks) the file is scanned by it first,
+// an infected file is rejected before it's stored. Once stored, it's scanned by the configured scanner
+// (see package scanner), SCAN_ACTION tells what becomes of an infected one.
+func FileFor(file *multipart.FileHeader, Context string) *UploadResponse {
 	// Open the uploaded file
 	src, err := file.Open()
+	if err != nil { return Failed(CodeUnreadable, "Error opening received file") }
+	defer src.Close()
+
+	return Store(src, file.Filename, file.Size, file.Header.Get("Content-Type"), Context)
+}
+
+// Store runs the upload pipeline of FileFor on a file which didn't come as a multipart form field,
+// e.g. one assembled from resumable upload chunks: type check, image normalization (EXIF_STRIP, see thumbnailer.Normalize),
+// hashing, scan, storage, metadata (see package inspector) and the Files record. The source is read once and needn't
+// seek, Size is the one announced for it and the received size is checked against the limits as well.
+// A file whose record can't be saved isn't left in storage. The file is public, see StoreVisible.
+func Store(src io.Reader, Name string, Size int64, ContentType string, Context string) *UploadResponse {
+	return StoreVisible(src, Name, Size, ContentType, Context, model.VisibilityPublic)
+}
+
+// StoreVisible is Store for a file of the given visibility (see Visibility), which its row has from the start.
+//
+// Example usage:
+//   Visibility, ok := uploader.Visibility(ctx.FormValue("visibility"))
+//   Upload := uploader.StoreVisible(File.Content, File.Name, File.Size, File.ContentType, "", Visibility)
+func StoreVisible(src io.Reader, Name string, Size int64, ContentType string, Context string, Visibility string) *UploadResponse {
+	File, Scan, Rejection := prepare(src, Name, Size, ContentType, Context)
+	if Rejection != nil { return Rejection }
+	File.Visibility = Visibility
+
+	Result := storage.DB.Create(&File)
+	if Result.Error != nil || Result.RowsAffected < 1 {
+		log.Print(Result)
+		unstore(File.Path)
+		return Failed(CodeDatabase, "File uploaded but was not saved in database")
+	}
+	return processed(File, Scan)
+}
+
+/* A blob whose row couldn't be saved is deleted, nothing would ever point to it. Uploads are content addressed,
+   one another row stores is kept */
+func unstore(Path string) {
+	Shared, err := shared(context.Background(), Path, 0)
+	if err == nil && !Shared { err = deleteBlob(context.Background(), blob.Key(Path)) }
+	if err != nil { log.Print("Removing unsaved upload ", Path, ": ", err) }
+}
+
+/* Every step of the pipeline but the Files record, which is a new row for Store and the replaced one for Replace.
+   The source is read once, into a temporary file hashed on the way (see spool), which the local backend then renames
+   to the hash name: nothing is read twice from the client and nothing is asked to seek */
+func prepare(src io.Reader, Name string, Size int64, ContentType string, Context string) (model.Files, ScanResult, *UploadResponse) {
+	extension := Extension(Name)
+	if len(extension) < 2 { return model.Files{}, "", Failed(CodeExtension, "File type " + extension + " has a problem") }
+
+	/* The client's content type isn't trusted, the one sniffed from the content is checked below */
+	Type, err := filetypes.Check(extension, "", Size, Context)
 	if err != nil {
-		return &UploadResponse{ ID: -1, Message: "Error opening received file", Success: false }
+		log.Print("Rejecting upload ", Name, ": ", err)
+		return model.Files{}, "", Rejected(err)
 	}
-	defer src.Close()
-	
-	// Calculate SHA-256 hash of the file contents
-	hash := sha256.New()
-	if _, err := io.Copy(hash, src); err != nil {
-		return &UploadResponse{ ID: -1, Message: "Error calculating hash", Success: false }
+
+	Spool, err := spool(src)
+	if err != nil { return model.Files{}, "", Failed(CodeUnreadable, "Error reading file") }
+	defer Spool.Close()
+
+	/* The size announced with the file is the client's word as well, the limit applies to what was received */
+	if Spool.Size != Size {
+		if Type, err = filetypes.Check(extension, "", Spool.Size, Context); err != nil {
+			log.Print("Rejecting upload ", Name, ": ", err)
+			return model.Files{}, "", Rejected(err)
+		}
+		Size = Spool.Size
 	}
 
-	// Reset src to the beginning to read again
-	src.Seek(0, 0)
-	extension := GetFileExtension(file)
-	hashName := hex.EncodeToString(hash.Sum(nil))
+	/* The extension is the client's word, the content has to agree with it */
+	head, err := Spool.Head(filetypes.SniffLength)
+	if err != nil { return model.Files{}, "", Failed(CodeUnreadable, "Error reading file") }
 
-	// Create a new file on the server to store the uploaded file
-	dst, err := os.Create("./public" + globals.Env.Uploads + hashName + extension)
+	Mime, err := filetypes.Detect(extension, head)
+	if err == nil { _, err = filetypes.Check(extension, Mime, Size, Context) }
 	if err != nil {
-		return &UploadResponse{ ID: -1, Message: "Error creating file on server", Success: false }
+		log.Print("Rejecting upload ", Name, ": ", err)
+		return model.Files{}, "", Rejected(err)
+	}
+
+	/* Photos from phones carry their GPS position and are turned by a tag, what's stored is the upright image alone */
+	if globals.Env.EXIF_STRIP && thumbnailer.IsImage(model.Files{ Name: Name }) {
+		data, err := io.ReadAll(Spool.Reader())
+		if err != nil { return model.Files{}, "", Failed(CodeUnreadable, "Error reading file") }
+
+		Normalized, changed, err := thumbnailer.Normalize(data, extension)
+		if errors.Is(err, thumbnailer.ErrTooLarge) { return model.Files{}, "", Failed(CodeTooLarge, err.Error()) }
+		if err != nil { return model.Files{}, "", Failed(CodeUnreadable, "Image could not be decoded: " + err.Error()) }
+
+		if changed {
+			Spool.Close()
+			if Spool, err = spool(bytes.NewReader(Normalized)); err != nil { return model.Files{}, "", Failed(CodeUnreadable, "Error reading file") }
+			defer Spool.Close()
+			Size = Spool.Size
+		}
+	}
+	hashName := Spool.Hash
+
+	/* A type may leave scanning out (File_types.Scan), its uploads are stored as ScanSkipped */
+	Scan := ScanSkipped
+	if Type.Scan && hooks.Has("upload.scan") {
+		Verdict := hooks.AskFile(context.Background(), "upload.scan", map[string]any{
+			"name": Name,
+			"size": Size,
+			"sha256": hashName,
+		}, Spool.File.Name())
+		if !Verdict.Allow { return model.Files{}, "", Failed(CodeInfected, "File was rejected by the scanner: " + Verdict.Message) }
+		Scan = ScanClean
 	}
-	defer dst.Close()
 
-	// Copy the file from the form data to the destination file
-	if _, err = io.Copy(dst, src); err != nil {
-		return &UploadResponse{ ID: -1, Message: "Error copying file to destination", Success: false }
+	/* Scanned while spooled, nothing unscanned is ever stored where it could be served */
+	Review := ""
+	if Type.Scan {
+		Scanned, Flagged, Rejection := screen(Spool, Name, extension)
+		if Rejection != nil { return model.Files{}, "", Rejection }
+		if Scanned != ScanSkipped { Scan = Scanned }
+		Review = Flagged
 	}
 
-	if len(extension) < 2 {
-		return &UploadResponse{ ID: -1, Message: "File type " + extension + " has a problem", Success: false }
+	/* Tiny images are sent inside the page rather than fetched, see view.InlineImage */
+	Base64 := ""
+	if Review == "" && Size <= int64(globals.Env.INLINE_MAX) && thumbnailer.IsImage(model.Files{ Name: Name }) {
+		data, err := io.ReadAll(Spool.Reader())
+		if err != nil { return model.Files{}, "", Failed(CodeUnreadable, "Error reading file") }
+		Base64 = "data:" + Mime + ";base64," + base64.StdEncoding.EncodeToString(data)
 	}
 
-	var Type model.File_types
-	result := storage.DB.Where(&model.File_types{Ext: extension[1:]}).Last(&Type)
+	// Store the file in the configured storage backend
+	Path := Sharded(hashName, extension)
+	key := blob.Key(Path)
+	if err := Spool.Put(context.Background(), key, Mime); err != nil {
+		log.Print("Storing upload: ", err)
+		return model.Files{}, "", Failed(CodeStorage, "Error storing file")
+	}
 
-	if result.Error != nil {
-		log.Print(result)
-		return &UploadResponse{ ID: -1, Message: "Server can't accept " + extension + " type files", Success: false }
+	/* Like the rest of the processing, a flagged file isn't inspected. Metadata is a nicety, failing it doesn't fail the upload */
+	var Metadata model.File_metadata
+	if Review == "" {
+		if Metadata, err = inspector.Inspect(context.Background(), Spool.Reader(), Name, Mime); err != nil { log.Print("Inspecting ", Name, ": ", err) }
 	}
 
 	var File model.Files = model.Files{
 		Name: hashName + extension,
-		Original: file.Filename,
-		Size: int(file.Size),
-		Location: globals.Env.Uploads,
-		Path: globals.Env.Uploads + hashName + extension,
+		Original: Name,
+		Size: int(Size),
+		Location: path.Dir(Path) + "/",
+		Path: Path,
+		Mime: Mime,
 		Compressed: false,
-		Base64: "",
+		Base64: Base64,
 		TypeID: int(Type.ID),
+		Review: Review,
+		Metadata: Metadata,
 	}
 
-	Result := storage.DB.Create(&File)
-	if Result.Error != nil || Result.RowsAffected < 1 {
-		log.Print(Result)
-		return &UploadResponse{ ID: -1, Message: "File uploaded but was not saved in database", Success: false }
+	return File, Scan, nil
+}
+
+/* A stored file's thumbnails and video variants, unless it's flagged */
+func processed(File model.Files, Scan ScanResult) *UploadResponse {
+	telemetry.Upload(int64(File.Size))
+
+	/* A flagged file isn't processed until it's approved, decoders are where malicious content strikes */
+	if File.UnderReview() {
+		Upload := Uploaded(File, Scan)
+		Upload.Status = StatusPending
+		return Upload
 	}
 
-	return &UploadResponse{ ID: int(File.ID), Message: "Successfully uploaded", Success: true }
+	thumbnail(File)
+	transcoder.Enqueue(&File)
+	return Uploaded(File, Scan)
 }
 
-func GetDbTypeIdByExtension(extension string) int {
-	types := map[string]string{
-		"jpeg": "2",
-		"jpg": "3",
-		"png": "4",
-		"gif": "5",
+// Visibility checks the visibility an upload asks for (model.VisibilityPublic, ...), "" is public.
+func Visibility(value string) (string, bool) {
+	switch value {
+		case "", model.VisibilityPublic: return model.VisibilityPublic, true
+		case model.VisibilityAuthenticated, model.VisibilityAdmin: return value, true
 	}
+	return "", false
+}
 
-	indeger, _ := strconv.Atoi(types[extension])
-	return indeger
+func GetDbTypeIdByExtension(extension string) int {
+	Type, _ := filetypes.Resolve(extension)
+	return int(Type.ID)
 }
 
 func GetFileExtension(file *multipart.FileHeader) string {
@@ -97,12 +229,32 @@ func GetFileExtension(file *multipart.FileHeader) string {
 	}
 	defer header.Close()
 
+	return Extension(file.Filename)
+}
+
+// Extension returns the extension of a file name with its dot (".png"), or "" when it has none.
+func Extension(Name string) string {
 	extension := ""
-	for i := len(file.Filename) - 1; i >= 0; i-- {
-		if file.Filename[i] == '.' {
-			extension = file.Filename[i:]
+	for i := len(Name) - 1; i >= 0; i-- {
+		if Name[i] == '.' {
+			extension = Name[i:]
 			break
 		}
 	}
 	return extension
-}
\ No newline at end of file
+}
+
+// Rejected builds the response of a file refused by filetypes.Check or filetypes.Detect.
+func Rejected(err error) *UploadResponse {
+	return Failed(typeCode(err), "Server can't accept this file: " + err.Error())
+}
+
+func typeCode(err error) string {
+	switch {
+		case errors.Is(err, filetypes.ErrSize): return CodeTooLarge
+		case errors.Is(err, filetypes.ErrMime), errors.Is(err, filetypes.ErrContent): return CodeMimeRejected
+		case errors.Is(err, filetypes.ErrDisabled): return CodeTypeDisabled
+		case errors.Is(err, filetypes.ErrContext): return CodeContext
+		default: return CodeTypeRejected
+	}
+}
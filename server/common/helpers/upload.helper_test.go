@@ -0,0 +1,119 @@
+package uploader
+
+import (
+	"context"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"main/server/common/blob"
+	"main/server/common/globals"
+	"main/server/model"
+	"main/server/service/filetypes"
+)
+
+/* What the pipeline mustn't regress past. Uploads stream, the heap doesn't grow with the size of the files. Both are
+   only enforced with -budget, throughput depends on how loaded the machine is */
+var budget = flag.Bool("budget", false, "fail the benchmarks over their heap and throughput budget")
+
+const (
+	MaxHeapGrowth = 32 << 20
+	MinRate = 20.0				/* megabytes per second a large file is at least stored at */
+)
+
+/* Every file starts as a pdf, the type is sniffed */
+const Header = "%PDF-1.7\n"
+
+/* How often the heap is sampled */
+const Sampling = 5 * time.Millisecond
+
+func BenchmarkPrepareSmall(b *testing.B) { benchmarkPrepare(b, 64 << 10, false) }
+
+func BenchmarkPrepareLarge(b *testing.B) { benchmarkPrepare(b, 32 << 20, false) }
+
+func BenchmarkPrepareConcurrent(b *testing.B) { benchmarkPrepare(b, 64 << 10, true) }
+
+func BenchmarkPrepareConcurrentLarge(b *testing.B) { benchmarkPrepare(b, 8 << 20, true) }
+
+/* The pipeline up to the Files record (spooling, hashing, checks and storage) in a temporary local backend, the
+   database isn't needed */
+func benchmarkPrepare(b *testing.B, Size int64, Concurrent bool) {
+	globals.Env.UPLOAD_MAX_SIZE = 1 << 30
+	globals.Env.EXIF_STRIP = false
+	blob.Use(&blob.Local{ Root: b.TempDir() })
+	filetypes.Cache(model.File_types{ Name: "PDF", Ext: "pdf", Mimes: "application/pdf", Enabled: true })
+	b.Cleanup(filetypes.Reset)
+
+	b.SetBytes(Size)
+	b.ReportAllocs()
+	Growth := sample()
+	b.ResetTimer()
+
+	var Job atomic.Int64
+	if Concurrent {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if err := upload(Size, Job.Add(1)); err != nil { b.Error(err) }
+			}
+		})
+	} else {
+		for i := 0; i < b.N; i++ {
+			if err := upload(Size, Job.Add(1)); err != nil { b.Fatal(err) }
+		}
+	}
+
+	b.StopTimer()
+	Heap := Growth()
+	b.ReportMetric(float64(Heap) / (1 << 20), "MB-heap")
+	if *budget && Heap > MaxHeapGrowth { b.Fatalf("the heap grew by %d bytes, over %d", Heap, MaxHeapGrowth) }
+
+	Rate := float64(Size * int64(b.N)) / (1 << 20) / b.Elapsed().Seconds()
+	if *budget && Size >= 1 << 20 && Rate < MinRate { b.Fatalf("%.1f MB/s, under %.1f", Rate, MinRate) }
+}
+
+func upload(Size int64, Job int64) error {
+	File, _, Rejection := prepare(content(Size), fmt.Sprintf("benchmark-%d.pdf", Job), Size, "application/pdf", "")
+	if Rejection != nil { return fmt.Errorf("%s %s", Rejection.Code, Rejection.Message) }
+	return blob.Default().Delete(context.Background(), blob.Key(File.Path))
+}
+
+/* Random content streamed as it's read, the benchmark never holds a file in memory itself */
+func content(Size int64) io.Reader {
+	return io.MultiReader(strings.NewReader(Header), io.LimitReader(rand.Reader, Size - int64(len(Header))))
+}
+
+/* Samples the heap until the returned func is called, which tells how much it grew at most */
+func sample() func() int64 {
+	runtime.GC()
+	var Before runtime.MemStats
+	runtime.ReadMemStats(&Before)
+
+	var Peak atomic.Int64
+	Peak.Store(int64(Before.HeapInuse))
+	Done := make(chan struct{})
+	Sampled := make(chan struct{})
+	go func() {
+		defer close(Sampled)
+		var Stats runtime.MemStats
+		for {
+			select {
+				case <-Done: return
+				case <-time.After(Sampling):
+					runtime.ReadMemStats(&Stats)
+					if int64(Stats.HeapInuse) > Peak.Load() { Peak.Store(int64(Stats.HeapInuse)) }
+			}
+		}
+	}()
+
+	return func() int64 {
+		close(Done)
+		<-Sampled
+		return Peak.Load() - int64(Before.HeapInuse)
+	}
+}
@@ -0,0 +1,206 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"unicode"
+
+	"main/server/common/domain"
+	"main/server/service/filetypes"
+)
+
+// MaxValueSize bounds each plain (not file) field of a received form.
+const MaxValueSize = 1 << 20
+
+// MaxNameLength bounds the names of received files, in bytes.
+const MaxNameLength = 255
+
+var (
+	ErrTooLarge = domain.QuotaExceeded("file is too large")
+	ErrTooMany = domain.QuotaExceeded("too many files")
+	ErrForm = domain.Invalid("malformed multipart form")
+	ErrAborted = domain.Invalid("the client aborted the upload")
+)
+
+// Received is a multipart form read by Receive, its files are temporary files removed by Close.
+type Received struct {
+	Values		map[string]string
+	Files		[]ReceivedFile
+}
+
+// ReceivedFile is a file field of a Received form.
+type ReceivedFile struct {
+	Field			string
+	Name			string
+	ContentType		string
+	Size			int64
+	Content			*os.File
+}
+
+// Receive reads a multipart upload as it streams in, unlike ParseMultipartForm, which copies whole files to disk
+// before anything can look at them. Every file is cut off as soon as it exceeds the limit of its extension
+// (filetypes.Limit, UPLOAD_MAX_SIZE at most), so a client can't fill the disk with an arbitrarily large one.
+//
+// Example usage:
+//   Received, err := uploader.Receive(ctx.Request(), MaxFiles)
+//   if err != nil { return err }
+//   defer Received.Close()
+//   for _, File := range Received.Named("files[]") { uploader.Store(File.Content, File.Name, File.Size, File.ContentType, Received.Values["context"]) }
+//
+// Returns:
+//   - ErrTooLarge, wrapped with the file and its limit, for a file over its limit or a body over UPLOAD_MAX_SIZE.
+//   - ErrTooMany when more than MaxFiles files are sent.
+//   - ErrForm when the body isn't a multipart form.
+//   - ErrAborted, wrapped with the file being received and how much of the body arrived, when the client went away.
+//
+// Notes:
+//   - Nothing of a form which failed is kept, the files already received are removed with the one cut off.
+func Receive(Request *http.Request, MaxFiles int) (*Received, error) {
+	Request.Body = Cancellable(Request.Context(), Request.Body)
+	reader, err := Request.MultipartReader()
+	if err != nil { return nil, fmt.Errorf("%w: %v", ErrForm, err) }
+
+	Form := &Received{ Values: map[string]string{} }
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF { return Form, nil }
+		if err != nil {
+			Form.Close()
+			return nil, failure(err)
+		}
+
+		if part.FileName() == "" {
+			Value, err := io.ReadAll(io.LimitReader(part, MaxValueSize))
+			if err != nil {
+				Form.Close()
+				return nil, failure(err)
+			}
+			Form.Values[part.FormName()] = string(Value)
+			continue
+		}
+
+		if len(Form.Files) >= MaxFiles {
+			Form.Close()
+			return nil, fmt.Errorf("%w: at most %d", ErrTooMany, MaxFiles)
+		}
+
+		File, err := receive(part.FormName(), clean(part.FileName()), part.Header.Get("Content-Type"), part)
+		if err != nil {
+			Form.Close()
+			return nil, err
+		}
+		Form.Files = append(Form.Files, File)
+	}
+}
+
+// Named returns the files of a field, in the order they were sent.
+func (Form *Received) Named(Field string) []ReceivedFile {
+	Files := []ReceivedFile{}
+	for _, File := range Form.Files {
+		if File.Field == Field { Files = append(Files, File) }
+	}
+	return Files
+}
+
+// Close removes the temporary files.
+func (Form *Received) Close() {
+	for _, File := range Form.Files {
+		File.Content.Close()
+		os.Remove(File.Content.Name())
+	}
+}
+
+/* One byte past the limit is read to tell a file of exactly the limit from a larger one */
+func receive(Field string, Name string, ContentType string, part io.Reader) (ReceivedFile, error) {
+	Limit := filetypes.Limit(Extension(Name))
+
+	Content, err := os.CreateTemp("", "upload-*")
+	if err != nil { return ReceivedFile{}, err }
+
+	Size, err := io.Copy(Content, io.LimitReader(part, Limit + 1))
+	var MaxBytes *http.MaxBytesError
+	switch {
+		case errors.As(err, &MaxBytes):
+			err = failure(err)
+		case err == nil && Size > Limit:
+			err = fmt.Errorf("%w: %s is limited to %d bytes", ErrTooLarge, Name, Limit)
+		case err == nil:
+			_, err = Content.Seek(0, io.SeekStart)
+	}
+	if errors.Is(err, ErrAborted) { err = fmt.Errorf("%s: %w", Name, err) }
+	if err != nil {
+		Content.Close()
+		os.Remove(Content.Name())
+		return ReceivedFile{}, err
+	}
+
+	return ReceivedFile{ Field: Field, Name: Name, ContentType: ContentType, Size: Size, Content: Content }, nil
+}
+
+/* Names are the client's, they're stored (Files.Original) and sent back in headers: postgres refuses invalid
+   UTF-8 and NUL bytes, control characters break headers. What's left of an unusable name is "file" */
+func clean(Name string) string {
+	Name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) { return -1 }
+		return r
+	}, strings.ToValidUTF8(Name, ""))
+
+	/* A long name is shortened before its extension, which decides its type */
+	if len(Name) > MaxNameLength {
+		Ext := Extension(Name)
+		if len(Ext) > MaxNameLength / 2 { Ext = "" }
+		Name = strings.ToValidUTF8(Name[:MaxNameLength - len(Ext)], "") + Ext
+	}
+	if strings.Trim(Name, ". ") == "" { return "file" }
+	return Name
+}
+
+/* The body cut off by http.MaxBytesReader is a too large upload, not a broken one, nor is one the client gave up */
+func failure(err error) error {
+	if errors.Is(err, ErrAborted) { return err }
+	var MaxBytes *http.MaxBytesError
+	if errors.As(err, &MaxBytes) { return fmt.Errorf("%w: the request is limited to %d bytes", ErrTooLarge, MaxBytes.Limit) }
+	return fmt.Errorf("%w: %v", ErrForm, err)
+}
+
+// Cancellable makes a request body fail with ErrAborted once its client is gone: the request's context was cancelled,
+// or the connection broke off before the end of the body. A body cut off by http.MaxBytesReader keeps its
+// *http.MaxBytesError. The read which fails ends the copy it's part of, so nothing more waits on the client.
+//
+// Example usage:
+//   Upload, Response, err := resumable.Append(Token, Offset, uploader.Cancellable(ctx.Request().Context(), ctx.Request().Body))
+func Cancellable(ctx context.Context, Body io.ReadCloser) io.ReadCloser {
+	return &cancellable{ ReadCloser: Body, ctx: ctx }
+}
+
+type cancellable struct {
+	io.ReadCloser
+	ctx			context.Context
+	read		int64
+}
+
+func (Body *cancellable) Read(p []byte) (int, error) {
+	if err := Body.ctx.Err(); err != nil { return 0, fmt.Errorf("%w after %d bytes: %v", ErrAborted, Body.read, err) }
+
+	n, err := Body.ReadCloser.Read(p)
+	Body.read += int64(n)
+
+	var MaxBytes *http.MaxBytesError
+	if err != nil && err != io.EOF && !errors.As(err, &MaxBytes) { err = fmt.Errorf("%w after %d bytes: %v", ErrAborted, Body.read, err) }
+	return n, err
+}
+
+// Refused builds the response of a form Receive refused.
+func Refused(err error) *UploadResponse {
+	switch {
+		case errors.Is(err, ErrAborted): return Failed(CodeAborted, err.Error())
+		case errors.Is(err, ErrTooLarge): return Failed(CodeTooLarge, err.Error())
+		case errors.Is(err, ErrTooMany): return Failed(CodeTooMany, err.Error())
+		default: return Failed(CodeMissingFile, err.Error())
+	}
+}
@@ -0,0 +1,31 @@
+package uploader
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func FuzzClean(f *testing.F) {
+	f.Add("report.pdf")
+	f.Add("..")
+	f.Add("name\x00with\nnul.png")
+	f.Add("\xff\xfe.jpg")
+	f.Add(strings.Repeat("ა", 200) + ".docx")
+	f.Add(strings.Repeat("a", 300) + "." + strings.Repeat("b", 200))
+
+	f.Fuzz(func(t *testing.T, Name string) {
+		Cleaned := clean(Name)
+
+		if !utf8.ValidString(Cleaned) { t.Fatalf("clean(%q) = %q isn't UTF-8", Name, Cleaned) }
+		if len(Cleaned) > MaxNameLength { t.Fatalf("clean(%q) is %d bytes long", Name, len(Cleaned)) }
+		if strings.Trim(Cleaned, ". ") == "" { t.Fatalf("clean(%q) = %q is unusable", Name, Cleaned) }
+		if strings.IndexFunc(Cleaned, unicode.IsControl) >= 0 { t.Fatalf("clean(%q) = %q has control characters", Name, Cleaned) }
+
+		/* A name which needn't be cleaned stays as it is */
+		if utf8.ValidString(Name) && len(Name) <= MaxNameLength && strings.IndexFunc(Name, unicode.IsControl) < 0 && strings.Trim(Name, ". ") != "" && Cleaned != Name {
+			t.Fatalf("clean(%q) = %q", Name, Cleaned)
+		}
+	})
+}
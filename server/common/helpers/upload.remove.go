@@ -0,0 +1,209 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"main/server/common/blob"
+	"main/server/common/globals"
+	"main/server/common/domain"
+	"main/server/common/saga"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/converter"
+	"main/server/service/thumbnailer"
+	"main/server/service/transcoder"
+)
+
+var (
+	// ErrNotFound is returned by Remove for files which don't exist.
+	ErrNotFound = domain.NotFound("file not found")
+	// ErrReferenced is returned by Remove for files other records still use, wrapped with them.
+	ErrReferenced = domain.Conflict("file is in use")
+)
+
+// Remove deletes a file for good, a trashed one (see Trash) as well: its Files row, its blob and the variants made from it (video sprites, tracks and renditions,
+// image thumbnails and their webp/avif copies), and so the blobs of its earlier versions (see Replace).
+// It runs as a saga (see package saga): the row is hidden first and brought back when it can't be purged, the blobs
+// are only deleted after, so a file is never left pointing to a missing blob.
+//
+// Returns:
+//   - ErrNotFound for a file which doesn't exist.
+//   - ErrReferenced, wrapped with the records using it ("News.thumbnail_id (2)"), for a file which is still used.
+//
+// Notes:
+//   - Uploads are content addressed, the blob is kept while another row still stores the same content.
+//   - With UPLOAD_TRASH set, the blob is moved under that prefix instead of deleted, the variants are deleted anyway.
+//     The local backend keeps it under ./public, where it's still served.
+func Remove(ctx context.Context, ID uint) error {
+	var File model.Files
+	if err := storage.DB.Scopes(storage.WithTrashed).First(&File, ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) { return ErrNotFound }
+		return err
+	}
+	if err := unreferenced(storage.DB.WithContext(ctx), File); err != nil { return err }
+
+	/* The variants' rows go with the file's, they're read before it's purged */
+	var Derivatives []model.File_derivatives
+	var Renditions []model.File_renditions
+	var Thumbnails []model.File_thumbnails
+	var Versions []model.File_versions
+
+	return saga.New("file.remove").
+		Step("hide", func(ctx context.Context) error {
+			/* Checked again under the lock, a reference may have been added since */
+			return storage.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				var Locked model.Files
+				if err := tx.Scopes(storage.WithTrashed).Clauses(lock).Select("id").First(&Locked, File.ID).Error; err != nil { return err }
+				if err := unreferenced(tx, File); err != nil { return saga.Permanent(err) }
+				return tx.Delete(&File).Error
+			})
+		}, func(ctx context.Context) error {
+			/* A trashed file goes back to the trash */
+			return storage.DB.WithContext(ctx).Unscoped().Model(&File).Update("deleted_at", File.DeletedAt).Error
+		}).
+		Step("collect", func(ctx context.Context) error {
+			db := storage.DB.WithContext(ctx)
+			if err := db.Where("file_id = ?", File.ID).Find(&Derivatives).Error; err != nil { return err }
+			if err := db.Where("file_id = ?", File.ID).Find(&Renditions).Error; err != nil { return err }
+			if err := db.Where("file_id = ?", File.ID).Find(&Thumbnails).Error; err != nil { return err }
+			return db.Where("file_id = ? AND path <> ?", File.ID, File.Path).Find(&Versions).Error
+		}, nil).
+		/* The last step which may fail and be compensated, blobs are only deleted once the row is gone for good */
+		Step("purge", func(ctx context.Context) error {
+			return storage.DB.WithContext(ctx).Unscoped().Delete(&File).Error
+		}, nil).
+		Step("blob", func(ctx context.Context) error {
+			/* The variants are named after the content too, they go with its blob */
+			if Shared, err := shared(ctx, File.Path, File.ID); err != nil || Shared { return err }
+
+			Keys := []string{}
+			for _, Derivative := range Derivatives { Keys = append(Keys, Derivative.Key) }
+			for _, Thumbnail := range Thumbnails { Keys = append(Keys, blob.Key(Thumbnail.Path)) }
+			if transcoder.IsVideo(File) {
+				Keys = append(Keys, transcoder.SpriteKey(File), transcoder.TrackKey(File))
+				for _, Rendition := range Renditions { Keys = append(Keys, Rendition.Key) }
+			}
+			for _, key := range Keys {
+				if err := deleteBlob(ctx, key); err != nil { return err }
+			}
+
+			if err := thumbnailer.Purge(ctx, File.Path); err != nil { return err }
+			if globals.Env.UPLOAD_TRASH != "" { return trashBlob(ctx, blob.Key(File.Path)) }
+			return deleteBlob(ctx, blob.Key(File.Path))
+		}, nil).
+		Step("versions", func(ctx context.Context) error {
+			Removed := map[string]bool{}
+			for _, Version := range Versions {
+				if Removed[Version.Path] { continue }
+				Removed[Version.Path] = true
+				if err := removeVersion(ctx, File.ID, model.Files{ Name: Version.Name, Path: Version.Path }); err != nil { return err }
+			}
+			return nil
+		}, nil).
+		Run(ctx)
+}
+
+// Trash soft deletes a file: it isn't found or downloaded anymore, but its blobs stay and it can be restored
+// (see storage.Restore) until it's removed for good by Remove, see package trash.
+//
+// Returns:
+//   - ErrNotFound for a file which doesn't exist or is trashed already.
+//   - ErrReferenced, wrapped with the records using it, for a file which is still used.
+func Trash(ctx context.Context, ID uint) error {
+	return storage.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var File model.Files
+		if err := tx.Clauses(lock).First(&File, ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) { return ErrNotFound }
+			return err
+		}
+		if err := unreferenced(tx, File); err != nil { return err }
+		return tx.Delete(&File).Error
+	})
+}
+
+/* Rows pointing to the file lock it while they're written (FOR KEY SHARE), none can be added between the check
+   for references and the delete while it's locked for update */
+var lock = clause.Locking{ Strength: "UPDATE" }
+
+func unreferenced(db *gorm.DB, File model.Files) error {
+	References, err := storage.References(db, &model.Files{}, File.ID, referencing()...)
+	if err != nil { return err }
+	if len(References) == 0 { return nil }
+
+	Used := []string{}
+	for _, Reference := range References { Used = append(Used, Reference.String()) }
+	return fmt.Errorf("%w: %s", ErrReferenced, strings.Join(Used, ", "))
+}
+
+/* An earlier content's variants have no rows anymore, they're found by the configured sizes */
+func removeVersion(ctx context.Context, FileID uint, Content model.Files) error {
+	if Shared, err := shared(ctx, Content.Path, FileID); err != nil || Shared { return err }
+
+	for _, key := range variants(Content) {
+		if err := deleteBlob(ctx, key); err != nil { return err }
+	}
+	if err := thumbnailer.Purge(ctx, Content.Path); err != nil { return err }
+	if globals.Env.UPLOAD_TRASH != "" { return trashBlob(ctx, blob.Key(Content.Path)) }
+	return deleteBlob(ctx, blob.Key(Content.Path))
+}
+
+/* The keys the variants of a content may have with the configured sizes and formats, whether they were made or not */
+func variants(Content model.Files) []string {
+	Keys := []string{}
+	if transcoder.IsVideo(Content) {
+		Keys = append(Keys, transcoder.SpriteKey(Content), transcoder.TrackKey(Content))
+		for _, Height := range globals.Env.TRANSCODE_HEIGHTS { Keys = append(Keys, transcoder.RenditionKey(Content, Height)) }
+	}
+	if thumbnailer.IsImage(Content) {
+		for _, Width := range globals.Env.THUMBNAIL_SIZES { Keys = append(Keys, thumbnailer.Key(Content, Width)) }
+		for _, Format := range globals.Env.IMAGE_FORMATS {
+			for _, Width := range append([]int{0}, globals.Env.THUMBNAIL_SIZES...) { Keys = append(Keys, converter.Key(Content, Width, Format)) }
+		}
+	}
+	return Keys
+}
+
+/* Uploads are content addressed: another file, or a version of another file, may store the same blob. A trashed
+   file still needs it, it may be restored */
+func shared(ctx context.Context, Path string, FileID uint) (bool, error) {
+	var Files, Versions int64
+	if err := storage.DB.WithContext(ctx).Scopes(storage.WithTrashed).Model(&model.Files{}).Where("path = ? AND id <> ?", Path, FileID).Count(&Files).Error; err != nil { return false, err }
+	if err := storage.DB.WithContext(ctx).Model(&model.File_versions{}).Where("path = ? AND file_id <> ?", Path, FileID).Count(&Versions).Error; err != nil { return false, err }
+	return Files + Versions > 0, nil
+}
+
+/* A blob which is already gone is as good as deleted, a retried step finds it so */
+func deleteBlob(ctx context.Context, key string) error {
+	if err := blob.Default().Delete(ctx, key); err != nil && !errors.Is(err, blob.ErrNotFound) { return err }
+	return nil
+}
+
+func trashBlob(ctx context.Context, key string) error {
+	return moveBlob(ctx, key, globals.Env.UPLOAD_TRASH + key)
+}
+
+/* Moved as a copy then a delete, the backends can't rename. A retried step finds the blob gone and the copy made */
+func moveBlob(ctx context.Context, key string, to string) error {
+	Copied, err := copyBlob(ctx, key, to)
+	if err != nil || !Copied { return err }
+	return deleteBlob(ctx, key)
+}
+
+/* Resumable uploads only record which file they became, they don't use it. Versions belong to it. The modules' models
+   are registered as they boot */
+func referencing() []any {
+	Models := []any{}
+	for _, Model := range model.All() {
+		switch Model.(type) {
+			case *model.Resumable_uploads, *model.File_versions: continue
+		}
+		Models = append(Models, Model)
+	}
+	return Models
+}
@@ -0,0 +1,165 @@
+package uploader
+
+import (
+	"net/http"
+	"strconv"
+
+	"main/server/common/i18n"
+	"main/server/model"
+	"main/server/service/quota"
+	"main/server/service/transcoder"
+)
+
+// UploadVersion is the version of the UploadResponse json shape, bumped on breaking changes.
+// Version 1 only had ID, Message and Success, their keys are kept as they were.
+const UploadVersion = 2
+
+type UploadStatus string
+
+const (
+	StatusPending	UploadStatus = "pending"
+	StatusReady		UploadStatus = "ready"
+	StatusFailed	UploadStatus = "failed"
+)
+
+type ScanResult string
+
+const (
+	ScanClean		ScanResult = "clean"
+	ScanInfected	ScanResult = "infected"
+	ScanSkipped		ScanResult = "skipped"
+	ScanFailed		ScanResult = "failed"		/* the scanner couldn't tell, the file is handled as an infected one */
+)
+
+// Machine readable error codes of UploadResponse.Code, each one has an "upload.<code>" translation.
+const (
+	CodeMissingFile		= "missing_file"
+	CodeTooMany			= "too_many_files"
+	CodeUnreadable		= "unreadable"
+	CodeStorage			= "storage_failed"
+	CodeExtension		= "invalid_extension"
+	CodeTypeRejected	= "type_rejected"
+	CodeTypeDisabled	= "type_disabled"
+	CodeMimeRejected	= "mime_rejected"
+	CodeTooLarge		= "too_large"
+	CodeContext			= "context_rejected"
+	CodeInfected		= "infected"
+	CodeScanFailed		= "scan_failed"
+	CodeDatabase		= "database_failed"
+	CodeVisibility		= "invalid_visibility"
+	CodeQuota			= "quota_exceeded"
+	CodeURL				= "invalid_url"
+	CodeFetch			= "fetch_failed"
+	CodeArchive			= "invalid_archive"
+	CodeAborted			= "aborted"
+)
+
+type UploadResponse struct {
+	Version		int					`json:"version"`
+	ID			int					`json:"ID"`
+	PublicID	string				`json:"publicId,omitempty"`
+	Message		string				`json:"Message"`
+	Success		bool				`json:"Success"`
+	Code		string				`json:"code,omitempty"`
+	Status		UploadStatus		`json:"status"`
+	Scan		ScanResult			`json:"scan,omitempty"`
+	URL			string				`json:"url,omitempty"`
+	Variants	map[string]string	`json:"variants,omitempty"`
+	MimeType	string				`json:"mimeType,omitempty"`
+	Size		int					`json:"size,omitempty"`
+	Metadata	*Metadata			`json:"metadata,omitempty"`
+	Transcoding	string				`json:"transcoding,omitempty"`		/* a video's processing, see GET /files/:id/transcoding */
+	Quota		*quota.Quota		`json:"quota,omitempty"`			/* the uploader's, when it refused the file */
+	Detail		string				`json:"detail,omitempty"`
+	Name		string				`json:"name,omitempty"`
+}
+
+// Metadata is what's known of an uploaded file's content (model.File_metadata), the fields it doesn't have are left out.
+type Metadata struct {
+	Width		int			`json:"width,omitempty"`
+	Height		int			`json:"height,omitempty"`
+	Pages		int			`json:"pages,omitempty"`
+	Duration	float64		`json:"duration,omitempty"`
+	Codec		string		`json:"codec,omitempty"`
+}
+
+// Failed builds the response of a rejected upload.
+// Message is technical and in english, Localize moves it into Detail and replaces it with the code's translation.
+func Failed(Code string, Message string) *UploadResponse {
+	return &UploadResponse{ Version: UploadVersion, ID: -1, Message: Message, Success: false, Code: Code, Status: StatusFailed }
+}
+
+// OverQuota builds the response of an upload the uploader's quota has no room for, it carries the quota.
+func OverQuota(Quota quota.Quota, err error) *UploadResponse {
+	Upload := Failed(CodeQuota, err.Error())
+	Upload.Quota = &Quota
+	return Upload
+}
+
+// Localize translates the message of a failed upload into the locale.
+func (Upload *UploadResponse) Localize(locale string) *UploadResponse {
+	if Upload.Success || Upload.Code == "" { return Upload }
+
+	key := "upload." + Upload.Code
+	if Message := i18n.Translate(locale, key); Message != key {
+		Upload.Detail = Upload.Message
+		Upload.Message = Message
+	}
+	return Upload
+}
+
+// HTTPStatus is the status code the upload is answered with.
+func (Upload *UploadResponse) HTTPStatus() int {
+	switch Upload.Code {
+		case "":
+			return http.StatusOK
+		case CodeTooLarge, CodeTooMany:
+			return http.StatusRequestEntityTooLarge
+		case CodeTypeRejected, CodeTypeDisabled, CodeMimeRejected, CodeExtension:
+			return http.StatusUnsupportedMediaType
+		case CodeContext, CodeInfected, CodeVisibility:
+			return http.StatusUnprocessableEntity
+		case CodeQuota:
+			return http.StatusForbidden
+		case CodeStorage, CodeDatabase, CodeScanFailed:
+			return http.StatusInternalServerError
+		case CodeFetch:
+			return http.StatusBadGateway
+		default:
+			return http.StatusBadRequest
+	}
+}
+
+// Uploaded builds the response of a stored file, served through the /files/:id download route.
+// Videos list their seek preview sprite and its WebVTT track as variants once the transcoder stored them.
+// Image thumbnails are made in the background (see Setup), a fresh upload lists none yet.
+func Uploaded(File model.Files, Scan ScanResult) *UploadResponse {
+	Variants := map[string]string{}
+	if File.Sprite {
+		Variants["sprite"] = "/" + transcoder.SpriteKey(File)
+		Variants["thumbnails"] = "/" + transcoder.TrackKey(File)
+	}
+	for _, Thumbnail := range File.Thumbnails { Variants[strconv.Itoa(Thumbnail.Width) + "w"] = Thumbnail.Path }
+	for _, Rendition := range File.Renditions { Variants[strconv.Itoa(Rendition.Height) + "p"] = "/" + Rendition.Key }
+
+	var Inspected *Metadata
+	if Found := File.Metadata; Found.ID != 0 {
+		Inspected = &Metadata{ Width: Found.Width, Height: Found.Height, Pages: Found.Pages, Duration: Found.Duration, Codec: Found.Codec }
+	}
+
+	return &UploadResponse{
+		Version: UploadVersion,
+		ID: int(File.ID),
+		PublicID: File.Ref(File.ID),
+		Message: "Successfully uploaded",
+		Success: true,
+		Status: StatusReady,
+		Scan: Scan,
+		URL: "/files/" + File.Ref(File.ID),
+		Variants: Variants,
+		MimeType: File.MimeType(),
+		Size: File.Size,
+		Metadata: Inspected,
+		Transcoding: File.Transcoding,
+	}
+}
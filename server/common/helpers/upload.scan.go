@@ -0,0 +1,90 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"main/server/common/blob"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/scanner"
+	"main/server/service/transcoder"
+)
+
+// screen scans an upload with the configured scanner (see package scanner) while it's still spooled, before
+// anything of it is stored where it could be served. It tells the scan result and the review the Files row gets,
+// or the response failing the upload. An infected file and one the scanner failed on are handled alike, after
+// SCAN_ACTION:
+//
+//   reject       the upload fails, nothing is stored
+//   quarantine   the content is copied to the SCAN_QUARANTINE directory and the upload fails
+//   flag         the file is stored pending review (model.ReviewPending), see Approve
+func screen(Spool *spooled, Name string, extension string) (ScanResult, string, *UploadResponse) {
+	if scanner.Default() == nil { return ScanSkipped, "", nil }
+
+	Verdict, err := scanner.Scan(context.Background(), Spool.Reader())
+	if err == nil && !Verdict.Infected { return ScanClean, "", nil }
+
+	Scan, Reason := ScanInfected, Verdict.Signature
+	if err != nil { Scan, Reason = ScanFailed, err.Error() }
+	log.Print("Scanning upload ", Name, " with ", scanner.Default().Name(), ": ", Reason)
+
+	Action := scanner.Configured()
+	if Action == scanner.ActionFlag { return Scan, model.ReviewPending, nil }
+	if Action == scanner.ActionQuarantine {
+		if err := quarantine(Spool, Spool.Hash + extension); err != nil { log.Print("Quarantining upload ", Name, ": ", err) }
+	}
+
+	if Scan == ScanFailed { return Scan, "", Failed(CodeScanFailed, "File could not be scanned") }
+	return Scan, "", Failed(CodeInfected, "File was rejected by the scanner: " + Reason)
+}
+
+/* Quarantined content is kept on the server's disk, never under the directory the local backend serves */
+func quarantine(Spool *spooled, Name string) error {
+	Directory, err := filepath.Abs(globals.Env.SCAN_QUARANTINE)
+	if err != nil { return err }
+	Served, err := filepath.Abs(blob.PublicRoot)
+	if err != nil { return err }
+	if Relative, err := filepath.Rel(Served, Directory); err == nil && !strings.HasPrefix(Relative, "..") {
+		return fmt.Errorf("SCAN_QUARANTINE %s is served publicly, the upload isn't kept", Directory)
+	}
+
+	if err := os.MkdirAll(Directory, 0700); err != nil { return err }
+	Quarantined, err := os.OpenFile(filepath.Join(Directory, Name), os.O_CREATE | os.O_WRONLY | os.O_TRUNC, 0600)
+	if err != nil { return err }
+	if _, err := io.Copy(Quarantined, Spool.Reader()); err != nil {
+		Quarantined.Close()
+		return err
+	}
+	return Quarantined.Close()
+}
+
+// Approve clears the review of a file the scanner flagged (SCAN_ACTION=flag), it's then downloadable after
+// its visibility and its thumbnails and video variants are made as for any upload.
+//
+// Returns:
+//   - ErrNotFound for a file which doesn't exist.
+func Approve(ID uint) error {
+	var File model.Files
+	if err := storage.DB.First(&File, ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) { return ErrNotFound }
+		return err
+	}
+	if !File.UnderReview() { return nil }
+
+	if err := storage.DB.Model(&File).Update(model.FilesReview, "").Error; err != nil { return err }
+	File.Review = ""
+
+	thumbnail(File)
+	transcoder.Enqueue(&File)
+	return nil
+}
@@ -0,0 +1,131 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"main/server/common/blob"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// ShardDepth is how many levels of folders uploads are sharded into, two hex characters of their hash each: a level
+// splits a folder 256 ways.
+const ShardDepth = 2
+
+// Sharded returns where the content of a hash is stored, under folders named after the start of the hash so no
+// folder grows past what file systems handle well:
+//   Sharded("abcdef…", ".jpg") = globals.Env.Uploads + "ab/cd/abcdef….jpg"
+func Sharded(Hash string, Ext string) string {
+	Folders := ""
+	for Level := 0; Level < ShardDepth && len(Hash) >= (Level + 1) * 2; Level++ { Folders += Hash[Level * 2:Level * 2 + 2] + "/" }
+	return globals.Env.Uploads + Folders + Hash + Ext
+}
+
+// Relocate moves a content stored before uploads were sharded, flat in the uploads folder, to its Sharded path:
+// its blob and the blobs of its variants (thumbnails, modern formats, video copies, sprites and tracks), then the
+// Files, File_versions and variant rows storing it. Content addressed uploads share their content, every row
+// with the Path is moved at once. It returns the new path, the same one for a path which isn't a flat upload.
+//
+// Example usage:
+//   Sharded, err := uploader.Relocate(ctx, "/uploads/9f86d081….png")
+//
+// Notes:
+//   - Blobs are copied, the rows updated and only then the earlier blobs deleted: the content is always reachable
+//     under the path its rows give, and a relocation which failed halfway is finished by running it again.
+//   - Resized copies (cache/resize/) are a cache, they're made again under the new path.
+func Relocate(ctx context.Context, Path string) (string, error) {
+	Name := path.Base(Path)
+	Hash := strings.TrimSuffix(Name, Extension(Name))
+	if Path != globals.Env.Uploads + Name || !hashed(Hash) { return Path, nil }
+
+	Target := Sharded(Hash, Extension(Name))
+	From, To := blob.Key(Path), blob.Key(Target)
+
+	var Files []model.Files
+	if err := storage.DB.WithContext(ctx).Unscoped().Where("path = ?", Path).Find(&Files).Error; err != nil { return Path, err }
+	IDs := []uint{}
+	for _, File := range Files { IDs = append(IDs, File.ID) }
+
+	var Thumbnails []model.File_thumbnails
+	var Derivatives []model.File_derivatives
+	var Renditions []model.File_renditions
+	if len(IDs) > 0 {
+		if err := storage.DB.WithContext(ctx).Where("file_id IN ?", IDs).Find(&Thumbnails).Error; err != nil { return Path, err }
+		if err := storage.DB.WithContext(ctx).Where("file_id IN ?", IDs).Find(&Derivatives).Error; err != nil { return Path, err }
+		if err := storage.DB.WithContext(ctx).Where("file_id IN ?", IDs).Find(&Renditions).Error; err != nil { return Path, err }
+	}
+
+	/* Versions have no variant rows, theirs are found by the configured sizes as removeVersion does */
+	Keys := append([]string{ From }, variants(model.Files{ Name: Name, Path: Path })...)
+	for _, Thumbnail := range Thumbnails { Keys = append(Keys, blob.Key(Thumbnail.Path)) }
+	for _, Derivative := range Derivatives { Keys = append(Keys, Derivative.Key) }
+	for _, Rendition := range Renditions { Keys = append(Keys, Rendition.Key) }
+
+	Moved := []string{}
+	for _, key := range unique(Keys) {
+		if !strings.HasPrefix(key, From) { continue }
+		Copied, err := copyBlob(ctx, key, To + strings.TrimPrefix(key, From))
+		if err != nil { return Path, err }
+		if Copied { Moved = append(Moved, key) }
+	}
+
+	err := storage.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		Location := path.Dir(Target) + "/"
+		if err := tx.Unscoped().Model(&model.Files{}).Where("path = ?", Path).Updates(map[string]any{ "path": Target, "location": Location }).Error; err != nil { return err }
+		if err := tx.Unscoped().Model(&model.File_versions{}).Where("path = ?", Path).Updates(map[string]any{ "path": Target, "location": Location }).Error; err != nil { return err }
+		for _, Thumbnail := range Thumbnails {
+			if err := tx.Model(&Thumbnail).Update("path", Target + strings.TrimPrefix(Thumbnail.Path, Path)).Error; err != nil { return err }
+		}
+		for _, Derivative := range Derivatives {
+			if err := tx.Model(&Derivative).Update("key", To + strings.TrimPrefix(Derivative.Key, From)).Error; err != nil { return err }
+		}
+		for _, Rendition := range Renditions {
+			if err := tx.Model(&Rendition).Update("key", To + strings.TrimPrefix(Rendition.Key, From)).Error; err != nil { return err }
+		}
+		return nil
+	})
+	if err != nil { return Path, err }
+
+	for _, key := range Moved {
+		if err := deleteBlob(ctx, key); err != nil { return Target, err }
+	}
+	return Target, nil
+}
+
+/* Uploads are named after the hex sha-256 of their content, other files under the uploads folder (exports, ...) aren't
+   content addressed and stay where they are */
+func hashed(Name string) bool {
+	if len(Name) != 64 { return false }
+	for _, r := range Name {
+		if !strings.ContainsRune("0123456789abcdef", r) { return false }
+	}
+	return true
+}
+
+/* Copies a blob unless it's gone, as it is when an earlier run moved it already or the variant was never made */
+func copyBlob(ctx context.Context, key string, to string) (bool, error) {
+	reader, Object, err := blob.Default().Open(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) { return false, nil }
+	if err != nil { return false, err }
+	defer reader.Close()
+
+	if err := blob.Default().Put(ctx, to, reader, Object.Size, Object.ContentType); err != nil { return false, err }
+	return true, nil
+}
+
+func unique(Keys []string) []string {
+	Seen := map[string]bool{}
+	Unique := []string{}
+	for _, key := range Keys {
+		if Seen[key] { continue }
+		Seen[key] = true
+		Unique = append(Unique, key)
+	}
+	return Unique
+}
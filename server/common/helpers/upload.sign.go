@@ -0,0 +1,67 @@
+package uploader
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/domain"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// MaxURLExpiry bounds how long a signed url may stay valid.
+const MaxURLExpiry = 30 * 24 * time.Hour
+
+var (
+	ErrLinkInvalid = domain.Forbidden("files", "download link is invalid")
+	ErrLinkExpired = domain.Forbidden("files", "download link has expired")
+)
+
+// SignURL returns a url downloading the file until the expiry has passed, whatever its visibility, for sharing it
+// with people who can't sign in or embedding it in mails. It's absolute when APP_URL is set:
+//
+//   https://yacco.ge/files/01HZX3K6Q4V8M2N7P9R5T1W3YB/shared?expires=1735686000&signature=4f1c...
+//
+// Example usage:
+//   URL, err := uploader.SignURL(File.ID, 7 * 24 * time.Hour)
+//   if err != nil { return err }
+//
+// Returns:
+//   - ErrNotFound for a file which doesn't exist.
+//
+// Notes:
+//   - The expiry is capped at MaxURLExpiry. A link can't be revoked alone, changing SHARE_SECRET revokes them all.
+func SignURL(FileID uint, Expiry time.Duration) (string, error) {
+	var File model.Files
+	if err := storage.DB.First(&File, FileID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) { return "", ErrNotFound }
+		return "", err
+	}
+
+	Expiry = min(Expiry, MaxURLExpiry)
+	Ref := File.Ref(File.ID)
+	Expires := time.Now().Add(Expiry).Unix()
+	return globals.Env.APP_URL + "/files/" + Ref + "/shared?expires=" + strconv.FormatInt(Expires, 10) + "&signature=" + signURL(Ref, Expires), nil
+}
+
+// VerifyURL checks the signature of a shared url against the file identifier and the expiry as they came in it.
+func VerifyURL(Ref string, Expires string, Signature string) error {
+	ExpiresAt, err := strconv.ParseInt(Expires, 10, 64)
+	if err != nil || Signature == "" { return ErrLinkInvalid }
+	if !hmac.Equal([]byte(Signature), []byte(signURL(Ref, ExpiresAt))) { return ErrLinkInvalid }
+	if time.Now().Unix() > ExpiresAt { return ErrLinkExpired }
+	return nil
+}
+
+func signURL(Ref string, Expires int64) string {
+	mac := hmac.New(sha256.New, []byte(globals.Env.SHARE_SECRET))
+	mac.Write([]byte("share:" + Ref + ":" + strconv.FormatInt(Expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
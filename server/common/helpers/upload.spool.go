@@ -0,0 +1,74 @@
+package uploader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"sync"
+
+	"main/server/common/blob"
+)
+
+// BufferSize is the buffer an upload is spooled through, what it holds of the file in memory at once. Buffers are
+// pooled, concurrent uploads don't allocate one each.
+const BufferSize = 64 << 10
+
+var buffers = sync.Pool{ New: func() any { Buffer := make([]byte, BufferSize); return &Buffer } }
+
+/* An upload's content in a temporary file, written and hashed in a single pass: the source is read once, whether or
+   not it can seek, and every later step reads the file instead */
+type spooled struct {
+	File		*os.File
+	Size		int64
+	Hash		string
+	adopted		bool
+}
+
+func spool(src io.Reader) (*spooled, error) {
+	File, err := os.CreateTemp("", "upload-*")
+	if err != nil { return nil, err }
+
+	Buffer := buffers.Get().(*[]byte)
+	defer buffers.Put(Buffer)
+
+	hash := sha256.New()
+	Size, err := io.CopyBuffer(io.MultiWriter(File, hash), src, *Buffer)
+	if err != nil {
+		File.Close()
+		os.Remove(File.Name())
+		return nil, err
+	}
+	return &spooled{ File: File, Size: Size, Hash: hex.EncodeToString(hash.Sum(nil)) }, nil
+}
+
+// Reader reads the content from its start, readers don't share a position.
+func (Spool *spooled) Reader() *io.SectionReader {
+	return io.NewSectionReader(Spool.File, 0, Spool.Size)
+}
+
+// Head returns the first n bytes of the content, fewer for a shorter one.
+func (Spool *spooled) Head(n int) ([]byte, error) {
+	head := make([]byte, n)
+	read, err := Spool.File.ReadAt(head, 0)
+	if err == io.EOF { err = nil }
+	return head[:read], err
+}
+
+// Put stores the content as the blob key. A backend which can adopt the file (blob.Adopter) renames it into place
+// rather than copying it, the open file still reads it afterwards.
+func (Spool *spooled) Put(ctx context.Context, key string, ContentType string) error {
+	if Adopter, ok := blob.Default().(blob.Adopter); ok {
+		if err := Adopter.Adopt(ctx, key, Spool.File.Name()); err != nil { return err }
+		Spool.adopted = true
+		return nil
+	}
+	return blob.Default().Put(ctx, key, Spool.Reader(), Spool.Size, ContentType)
+}
+
+// Close closes the file and removes it, unless it was adopted as a blob.
+func (Spool *spooled) Close() {
+	Spool.File.Close()
+	if !Spool.adopted { os.Remove(Spool.File.Name()) }
+}
@@ -0,0 +1,62 @@
+package uploader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/converter"
+	"main/server/service/filetypes"
+	"main/server/service/outbox"
+	"main/server/service/thumbnailer"
+)
+
+// KindThumbnail is the outbox kind of the jobs making the thumbnails of an image, see Setup.
+const KindThumbnail = "thumbnail"
+
+type thumbnailJob struct {
+	FileID		uint
+}
+
+// Setup registers the thumbnail job on the outbox. An upload only queues it, so decoding and downscaling a large
+// image doesn't hold the request, and the outbox retries it after a failure or a restart. The image's webp/avif
+// copies (see package converter) are made once its thumbnails are, they're made of them too.
+func Setup() {
+	outbox.Handle(KindThumbnail, func(ctx context.Context, Message model.Outbox_messages) error {
+		var Job thumbnailJob
+		if err := json.Unmarshal([]byte(Message.Payload), &Job); err != nil { return err }
+
+		/* Removed or flagged since, there's nothing to make */
+		var File model.Files
+		err := storage.DB.WithContext(ctx).Preload("Thumbnails").First(&File, Job.FileID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) { return nil }
+		if err != nil { return err }
+		if File.UnderReview() { return nil }
+
+		/* Retrying can't make a decompression bomb smaller, the image is shown in full size */
+		err = thumbnailer.Generate(ctx, &File)
+		if errors.Is(err, thumbnailer.ErrTooLarge) { log.Print("Thumbnailing ", File.Name, ": ", err) }
+		if err != nil && !errors.Is(err, thumbnailer.ErrTooLarge) { return err }
+
+		converter.Enqueue(File)
+		return nil
+	})
+}
+
+/* A file without thumbnails still shows, in full size, until they're made. Types may leave them out
+   (File_types.Thumbnails), their images are converted right away */
+func thumbnail(File model.Files) {
+	if !thumbnailer.IsImage(File) || !filetypes.Thumbnailed(Extension(File.Name)) {
+		converter.Enqueue(File)
+		return
+	}
+
+	err := outbox.Add(storage.DB, KindThumbnail, strconv.Itoa(int(File.ID)), thumbnailJob{ FileID: File.ID })
+	if err != nil { log.Print("Queueing thumbnails of ", File.Name, ": ", err) }
+}
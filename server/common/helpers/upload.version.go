@@ -0,0 +1,168 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+
+	"gorm.io/gorm"
+
+	"main/server/common/blob"
+	"main/server/common/domain"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/inspector"
+	"main/server/service/thumbnailer"
+)
+
+// ErrVersionNotFound is returned by Restore for a version the file doesn't have.
+var ErrVersionNotFound = domain.NotFound("file version not found")
+
+// Replace stores a new content for an existing file, through the whole pipeline of Store, and records its current
+// content as a version (model.File_versions). The file keeps its ID, so /files/:id and every record using it
+// show the new content from now on.
+//
+// Example usage:
+//   Upload, err := uploader.Replace(File.ID, Content, "brochure.pdf", Size, "application/pdf")
+//
+// Returns:
+//   - ErrNotFound for a file which doesn't exist, the rejections of Store in the response otherwise.
+//
+// Notes:
+//   - The replacement may be of any enabled type, whatever the upload context of the file was.
+//   - Thumbnails, metadata and video renditions are made again from the new content, the earlier ones are kept with its blob.
+//   - The file's owner is charged for the current content only, versions aren't counted in the quota.
+func Replace(ID uint, src io.Reader, Name string, Size int64, ContentType string) (*UploadResponse, error) {
+	var File model.Files
+	if err := storage.DB.First(&File, ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) { return nil, ErrNotFound }
+		return nil, err
+	}
+
+	Next, Scan, Rejection := prepare(src, Name, Size, ContentType, "")
+	if Rejection != nil { return Rejection, nil }
+
+	if err := swap(&File, Next); err != nil {
+		log.Print("Replacing file ", File.ID, ": ", err)
+		unstore(Next.Path)
+		return Failed(CodeDatabase, "File uploaded but was not saved in database"), nil
+	}
+	return processed(File, Scan), nil
+}
+
+// Restore makes an earlier version the file's content again. Like a replacement it records the current content
+// as a version, so the history is never rewritten: restoring version 2 of a file at version 3 makes version 4.
+//
+// Returns:
+//   - ErrNotFound for a file which doesn't exist, ErrVersionNotFound for a version it doesn't have.
+func Restore(ID uint, Version int) (*UploadResponse, error) {
+	var File model.Files
+	if err := storage.DB.First(&File, ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) { return nil, ErrNotFound }
+		return nil, err
+	}
+
+	var Earlier model.File_versions
+	if err := storage.DB.Where("file_id = ? AND version = ?", File.ID, Version).First(&Earlier).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) { return nil, ErrVersionNotFound }
+		return nil, err
+	}
+
+	Next := model.Files{
+		Name: Earlier.Name,
+		Original: Earlier.Original,
+		Location: Earlier.Location,
+		Path: Earlier.Path,
+		Size: Earlier.Size,
+		Mime: Earlier.Mime,
+		Base64: Earlier.Base64,
+		TypeID: Earlier.TypeID,
+		Review: Earlier.Review,
+	}
+	/* Metadata isn't versioned, it's read again from the blob. Like on upload, failing it doesn't fail the restore */
+	if !Next.UnderReview() {
+		Metadata, err := inspect(Next)
+		if err != nil { log.Print("Inspecting ", Next.Name, ": ", err) }
+		Next.Metadata = Metadata
+	}
+
+	if err := swap(&File, Next); err != nil { return nil, err }
+	return processed(File, ScanSkipped), nil
+}
+
+// Versions returns the earlier versions of a file, the newest first.
+func Versions(ID uint) ([]model.File_versions, error) {
+	var Versions []model.File_versions
+	err := storage.DB.Where("file_id = ?", ID).Order("version DESC").Find(&Versions).Error
+	return Versions, err
+}
+
+/* Records the current content as a version and moves the file to the next one, the variants of the current content go with it.
+   Its resized copies don't, they're a cache made again if it's restored */
+func swap(File *model.Files, Next model.Files) error {
+	Previous := File.Path
+	defer func() {
+		if File.Path == Previous { return }
+		if err := thumbnailer.Purge(context.Background(), Previous); err != nil { log.Print("Purging resized copies of ", Previous, ": ", err) }
+	}()
+
+	return storage.DB.Transaction(func(tx *gorm.DB) error {
+		Earlier := model.File_versions{
+			FileID: File.ID,
+			Version: File.Version,
+			Name: File.Name,
+			Original: File.Original,
+			Location: File.Location,
+			Path: File.Path,
+			Size: File.Size,
+			Mime: File.Mime,
+			Base64: File.Base64,
+			TypeID: File.TypeID,
+			Review: File.Review,
+		}
+		if err := tx.Create(&Earlier).Error; err != nil { return err }
+
+		/* The rows only, the blobs are named after the content and stay with the version's */
+		for _, Variant := range []any{ &model.File_thumbnails{}, &model.File_renditions{}, &model.File_derivatives{}, &model.File_metadata{} } {
+			if err := tx.Unscoped().Where("file_id = ?", File.ID).Delete(Variant).Error; err != nil { return err }
+		}
+
+		Version := File.Version + 1
+		if err := tx.Model(&model.Files{}).Where("id = ?", File.ID).Updates(map[string]any{
+			model.FilesName: Next.Name,
+			model.FilesOriginal: Next.Original,
+			model.FilesLocation: Next.Location,
+			model.FilesPath: Next.Path,
+			model.FilesSize: Next.Size,
+			model.FilesMime: Next.Mime,
+			model.FilesBase64: Next.Base64,
+			model.FilesTypeID: Next.TypeID,
+			model.FilesReview: Next.Review,
+			model.FilesTranscoding: "",
+			model.FilesSprite: false,
+			model.FilesCompressed: false,
+			model.FilesVersion: Version,
+		}).Error; err != nil { return err }
+
+		Metadata := Next.Metadata
+		Metadata.ID, Metadata.FileID = 0, File.ID
+		if Metadata != (model.File_metadata{ FileID: File.ID }) {
+			if err := tx.Create(&Metadata).Error; err != nil { return err }
+		}
+
+		File.Name, File.Original, File.Location, File.Path = Next.Name, Next.Original, Next.Location, Next.Path
+		File.Size, File.Mime, File.Base64, File.TypeID = Next.Size, Next.Mime, Next.Base64, Next.TypeID
+		File.Review, File.Transcoding, File.Sprite, File.Compressed, File.Version = Next.Review, "", false, false, Version
+		File.Thumbnails, File.Renditions, File.Metadata = nil, nil, Metadata
+		return nil
+	})
+}
+
+func inspect(File model.Files) (model.File_metadata, error) {
+	reader, _, err := blob.Default().Open(context.Background(), blob.Key(File.Path))
+	if err != nil { return model.File_metadata{}, err }
+	defer reader.Close()
+
+	return inspector.Inspect(context.Background(), reader, File.Name, File.Mime)
+}
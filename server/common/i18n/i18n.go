@@ -0,0 +1,145 @@
+// Package i18n loads translation catalogs and resolves translations for a locale.
+//
+// Catalogs are flat json files named after their locale ("ka.json", "en.json") inside globals.Env.Locales:
+//
+//   { "login.title": "კაბინეტი", "products.count": "%d პროდუქტი" }
+//
+// The locale of a request is negotiated by middleware.Locale and stored on the request context,
+// so both controllers (ctx.T) and templ components (i18n.T(ctx, ...)) translate without passing it around.
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"main/server/common/globals"
+)
+
+type localeKey struct{}
+
+var (
+	mu sync.RWMutex
+	catalogs = map[string]map[string]string{}
+)
+
+// Load reads every catalog found in the directory, replacing the loaded ones.
+func Load(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil { return err }
+
+	loaded := map[string]map[string]string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil { return err }
+
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil { return fmt.Errorf("%s: %w", file, err) }
+
+		loaded[strings.TrimSuffix(filepath.Base(file), ".json")] = catalog
+	}
+
+	mu.Lock()
+	catalogs = loaded
+	mu.Unlock()
+	return nil
+}
+
+// Setup loads the catalogs configured in globals.Env, a broken catalog only leaves the site untranslated.
+func Setup() {
+	if err := Load(globals.Env.Locales); err != nil { log.Print("Loading translations: ", err) }
+}
+
+// Supported returns the locales having a catalog, sorted.
+func Supported() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs { locales = append(locales, locale) }
+	sort.Strings(locales)
+	return locales
+}
+
+// IsSupported reports whether there is a catalog for the locale.
+func IsSupported(locale string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	_, exists := catalogs[locale]
+	return exists
+}
+
+// Negotiate returns the first supported locale among the candidates, matching "en-US" to "en" as well,
+// falling back to globals.Env.DefaultLocale.
+func Negotiate(candidates ...string) string {
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if candidate == "" { continue }
+		if IsSupported(candidate) { return candidate }
+
+		base, _, _ := strings.Cut(candidate, "-")
+		if IsSupported(base) { return base }
+	}
+	return globals.Env.DefaultLocale
+}
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header ordered by their quality.
+func ParseAcceptLanguage(header string) []string {
+	type language struct { tag string; quality float64 }
+	var languages []language
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" { continue }
+
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil { quality = parsed }
+		}
+		languages = append(languages, language{ tag: tag, quality: quality })
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].quality > languages[j].quality })
+
+	tags := make([]string, len(languages))
+	for i, language := range languages { tags[i] = language.tag }
+	return tags
+}
+
+// WithLocale stores the locale on a context.Context.
+func WithLocale(parent context.Context, locale string) context.Context {
+	return context.WithValue(parent, localeKey{}, locale)
+}
+
+// Locale returns the locale stored on the context, or the default one.
+func Locale(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" { return locale }
+	return globals.Env.DefaultLocale
+}
+
+// T translates the key into the locale of the context, it's meant for templ components:
+//
+//   <h1>{ i18n.T(ctx, "login.title") }</h1>
+func T(ctx context.Context, key string, args ...any) string {
+	return Translate(Locale(ctx), key, args...)
+}
+
+// Translate returns the translation of the key in the locale, formatted with args by fmt.Sprintf.
+// Missing translations fall back to the default locale and then to the key itself.
+func Translate(locale string, key string, args ...any) string {
+	mu.RLock()
+	message, found := catalogs[locale][key]
+	if !found { message, found = catalogs[globals.Env.DefaultLocale][key] }
+	mu.RUnlock()
+
+	if !found { message = key }
+	if len(args) > 0 { return fmt.Sprintf(message, args...) }
+	return message
+}
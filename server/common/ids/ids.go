@@ -0,0 +1,119 @@
+// Package ids makes the public identifiers of the models embedding model.External, so urls and apis don't expose
+// their sequential IDs: /files/01HZX3K6Q4V8M2N7P9R5T1W3YB instead of /files/12, which can't be guessed or counted.
+//
+// ID_STRATEGY picks the kind of identifier new rows get: "ulid" (the default, sortable by creation time)
+// or "uuid" (random, version 4). Both can be looked up whatever the strategy, rows keep theirs when it changes.
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"main/server/common/globals"
+)
+
+const (
+	StrategyULID = "ulid"
+	StrategyUUID = "uuid"
+)
+
+/* Crockford's base32, without I, L, O and U */
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	ulid = regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+	uuid = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// New returns a new public identifier of the configured strategy.
+func New() string {
+	if globals.Env.ID_STRATEGY == StrategyUUID { return UUID() }
+	return ULID()
+}
+
+// ULID returns a ULID: 48 bits of milliseconds and 80 random bits, as 26 base32 characters.
+func ULID() string {
+	var data [16]byte
+	var now [8]byte
+	binary.BigEndian.PutUint64(now[:], uint64(time.Now().UnixMilli()))
+	copy(data[:6], now[2:])
+	rand.Read(data[6:])
+
+	/* 130 bits of characters for 128 bits of data, the first character carries the 2 leading zero bits */
+	var encoded [26]byte
+	for i := 25; i >= 0; i-- {
+		encoded[i] = alphabet[data[15] & 31]
+		shift(&data)
+	}
+	return string(encoded[:])
+}
+
+// UUID returns a random (version 4) UUID.
+func UUID() string {
+	var data [16]byte
+	rand.Read(data[:])
+	data[6] = data[6] & 0x0f | 0x40
+	data[8] = data[8] & 0x3f | 0x80
+
+	encoded := hex.EncodeToString(data[:])
+	return encoded[:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:]
+}
+
+// Valid reports whether the value is a public identifier, of either strategy.
+func Valid(value string) bool {
+	return ulid.MatchString(value) || uuid.MatchString(value)
+}
+
+// Match is a gorm scope finding a row by the identifier in a url: its public identifier, or its ID
+// unless PUBLIC_IDS_ONLY refuses sequential IDs (links made before the public ones keep working otherwise).
+//
+// Example usage:
+//   var File model.Files
+//   if ctx.DB().Scopes(ids.Match(ctx.Param("id"))).First(&File).Error != nil { return echo.ErrNotFound }
+func Match(value string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if Valid(value) { return db.Where("public_id = ?", value) }
+
+		ID, err := strconv.ParseUint(value, 10, 0)
+		if err != nil || globals.Env.PUBLIC_IDS_ONLY { return db.Where("1 = 0") }
+		return db.Where("id = ?", ID)
+	}
+}
+
+// Backfill gives a public identifier to the rows created before their model embedded model.External,
+// cmd/migrate runs it after migrating. Models without a public_id column are skipped.
+func Backfill(db *gorm.DB, models ...any) error {
+	for _, model := range models {
+		Statement := &gorm.Statement{ DB: db }
+		if err := Statement.Parse(model); err != nil { return err }
+		if !hasPublicID(Statement.Schema) { continue }
+
+		for {
+			var Rows []uint
+			if err := db.Unscoped().Model(model).Where("public_id IS NULL").Limit(500).Pluck("id", &Rows).Error; err != nil { return err }
+			if len(Rows) == 0 { break }
+
+			for _, ID := range Rows {
+				if err := db.Unscoped().Model(model).Where("id = ?", ID).UpdateColumn("public_id", New()).Error; err != nil { return err }
+			}
+		}
+	}
+	return nil
+}
+
+func hasPublicID(Schema *schema.Schema) bool {
+	return Schema.LookUpField("public_id") != nil
+}
+
+/* Shifts the 128 bits right by 5 */
+func shift(data *[16]byte) {
+	for i := 15; i > 0; i-- { data[i] = data[i] >> 5 | data[i - 1] << 3 }
+	data[0] >>= 5
+}
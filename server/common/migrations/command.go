@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gorm.io/gorm"
+
+	"main/server/common/ids"
+)
+
+// Command runs the migrate command line, for cmd/migrate and the app's "migrate" subcommand:
+//
+//   migrate [up] [-to 12]      applies the pending migrations, up to a version
+//   migrate down [-steps 2]    rolls back the last applied ones
+//   migrate status
+//   migrate new <name>         writes an empty migration numbered after the last one
+//
+// Models are backfilled with their public identifiers (see ids.Backfill) once migrated up.
+func Command(db *gorm.DB, Args []string, Models []any) error {
+	Subcommand := "up"
+	if len(Args) > 0 && Args[0] != "" && Args[0][0] != '-' { Subcommand, Args = Args[0], Args[1:] }
+
+	Flags := flag.NewFlagSet("migrate " + Subcommand, flag.ExitOnError)
+	to := Flags.Int("to", 0, "version to migrate up to, the last one when 0")
+	steps := Flags.Int("steps", 1, "how many migrations to roll back")
+	Flags.Parse(Args)
+
+	Migrations, err := Embedded()
+	if err != nil { return err }
+
+	switch Subcommand {
+		case "up":
+			Done, err := Up(db, Migrations, *to)
+			for _, Item := range Done { fmt.Printf("↑ %04d_%s\n", Item.Version, Item.Name) }
+			if err != nil { return err }
+			if len(Done) == 0 { fmt.Println("Nothing to migrate") }
+
+			/* Rows created before their model had a public identifier */
+			return ids.Backfill(db, Models...)
+
+		case "down":
+			Done, err := Down(db, Migrations, *steps)
+			for _, Item := range Done { fmt.Printf("↓ %04d_%s\n", Item.Version, Item.Name) }
+			if err != nil { return err }
+			if len(Done) == 0 { fmt.Println("Nothing to roll back") }
+			return nil
+
+		case "status":
+			Current, err := States(db, Migrations)
+			if err != nil { return err }
+			for _, State := range Current {
+				At := ""
+				if State.AppliedAt != nil { At = State.AppliedAt.Format("2006-01-02 15:04") }
+				fmt.Printf("%-9s %-16s %04d_%s\n", State.State, At, State.Version, State.Name)
+			}
+			return nil
+
+		case "new":
+			if Flags.NArg() == 0 { return fmt.Errorf("migrate new <name>") }
+			Path, err := New(Migrations, Flags.Arg(0), func(Path string, Content []byte) error {
+				return os.WriteFile(Path, Content, 0644)
+			})
+			if err != nil { return err }
+			fmt.Println("Wrote", Path, "and its down file")
+			return nil
+	}
+	return fmt.Errorf("migrate: unknown subcommand %s, up, down, status or new", Subcommand)
+}
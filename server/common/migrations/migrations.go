@@ -0,0 +1,255 @@
+// Package migrations versions the database schema: it's changed by numbered SQL files, applied in order and
+// recorded with their checksum in schema_migrations, instead of AutoMigrate guessing the changes from the models.
+//
+// A migration is a pair of files in sql/ (embedded, so the binary carries them):
+//
+//   0002_product_sku.up.sql     ALTER TABLE "products" ADD COLUMN "sku" text;
+//   0002_product_sku.down.sql   ALTER TABLE "products" DROP COLUMN "sku";
+//
+// An applied migration mustn't be edited, its checksum wouldn't match anymore and Up refuses to run until it's
+// restored: a change is a new migration. The down file may be left out, the migration can't be rolled back then.
+// Statements run in one transaction per migration. A file starting with "-- migrate: no-transaction" runs
+// without one, for the statement which can't run in a transaction (CREATE INDEX CONCURRENTLY) alone in its file.
+//
+// 0001_baseline is the schema AutoMigrate made before migrations were versioned. It only creates what's missing, so
+// a database an earlier version AutoMigrated, with some of its tables and columns, is brought up to it. It has no
+// down file: rolling it back would drop the data the database had before it was versioned.
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/domain"
+)
+
+//go:embed sql/*.sql
+var Files embed.FS
+
+// Directory is where the migration files are in the repository, cmd/migrate new writes there.
+const Directory = "server/common/migrations/sql"
+
+/* Taken for the whole run, two instances deploying at once mustn't both migrate */
+const lockKey = 7_102_023
+
+const noTransaction = "-- migrate: no-transaction"
+
+var (
+	ErrModified = domain.Conflict("migrations: an applied migration was modified or removed")
+	ErrOutOfOrder = domain.Conflict("migrations: a pending migration is older than an applied one")
+	ErrIrreversible = domain.Invalid("migrations: the migration has no down file")
+)
+
+type Migration struct {
+	Version		int
+	Name		string
+	Up			string
+	Down		string
+	Checksum	string		/* sha-256 of the up file, the one whose changes are applied */
+}
+
+// Record is an applied migration, as schema_migrations keeps it.
+type Record struct {
+	Version		int			`gorm:"primaryKey;autoIncrement:false"`
+	Name		string
+	Checksum	string
+	AppliedAt	time.Time
+}
+
+func (Record) TableName() string { return "schema_migrations" }
+
+// State of a migration, as Status tells it.
+const (
+	Applied = "applied"
+	Pending = "pending"
+	Modified = "modified"		/* applied, but its up file changed since */
+	Missing = "missing"			/* applied, but its files are gone */
+)
+
+type Status struct {
+	Migration
+	State		string
+	AppliedAt	*time.Time
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load reads the migrations of a directory, sorted by version.
+func Load(Folder fs.FS) ([]Migration, error) {
+	Entries, err := fs.ReadDir(Folder, ".")
+	if err != nil { return nil, err }
+
+	Versions := map[int]*Migration{}
+	for _, Entry := range Entries {
+		Match := fileName.FindStringSubmatch(Entry.Name())
+		if Match == nil { return nil, fmt.Errorf("migrations: %s isn't named like 0001_name.up.sql", Entry.Name()) }
+		Version, _ := strconv.Atoi(Match[1])
+
+		Content, err := fs.ReadFile(Folder, Entry.Name())
+		if err != nil { return nil, err }
+
+		Found, ok := Versions[Version]
+		if !ok {
+			Found = &Migration{ Version: Version, Name: Match[2] }
+			Versions[Version] = Found
+		}
+		if Found.Name != Match[2] { return nil, fmt.Errorf("migrations: version %d is both %s and %s", Version, Found.Name, Match[2]) }
+		if Match[3] == "up" { Found.Up = string(Content) } else { Found.Down = string(Content) }
+	}
+
+	Migrations := []Migration{}
+	for _, Found := range Versions {
+		if Found.Up == "" { return nil, fmt.Errorf("migrations: %04d_%s has no up file", Found.Version, Found.Name) }
+		Sum := sha256.Sum256([]byte(Found.Up))
+		Found.Checksum = hex.EncodeToString(Sum[:])
+		Migrations = append(Migrations, *Found)
+	}
+	slices.SortFunc(Migrations, func(a, b Migration) int { return a.Version - b.Version })
+	return Migrations, nil
+}
+
+// Embedded are the migrations the binary was built with.
+func Embedded() ([]Migration, error) {
+	Folder, err := fs.Sub(Files, "sql")
+	if err != nil { return nil, err }
+	return Load(Folder)
+}
+
+// States tells which migrations are applied and which are pending, the ones applied whose files are gone too.
+func States(db *gorm.DB, Migrations []Migration) ([]Status, error) {
+	if err := prepare(db); err != nil { return nil, err }
+	var Records []Record
+	if err := db.Order("version").Find(&Records).Error; err != nil { return nil, err }
+
+	Recorded := map[int]Record{}
+	for _, Row := range Records { Recorded[Row.Version] = Row }
+
+	Result := []Status{}
+	for _, Item := range Migrations {
+		Row, ok := Recorded[Item.Version]
+		switch {
+			case !ok: Result = append(Result, Status{ Migration: Item, State: Pending })
+			case Row.Checksum != Item.Checksum: Result = append(Result, Status{ Migration: Item, State: Modified, AppliedAt: &Row.AppliedAt })
+			default: Result = append(Result, Status{ Migration: Item, State: Applied, AppliedAt: &Row.AppliedAt })
+		}
+		delete(Recorded, Item.Version)
+	}
+	for _, Row := range Recorded {
+		Result = append(Result, Status{ Migration: Migration{ Version: Row.Version, Name: Row.Name, Checksum: Row.Checksum }, State: Missing, AppliedAt: &Row.AppliedAt })
+	}
+	slices.SortFunc(Result, func(a, b Status) int { return a.Version - b.Version })
+	return Result, nil
+}
+
+// Up applies the pending migrations up to a version (every one when To is 0), in order, and returns the ones applied.
+//
+// Returns:
+//   - ErrModified when an applied migration's file changed or is gone, nothing is applied then.
+//   - ErrOutOfOrder when a pending migration is older than the last applied one (merged after it was applied,
+//     it's to be renumbered).
+func Up(db *gorm.DB, Migrations []Migration, To int) ([]Migration, error) {
+	Done := []Migration{}
+	err := locked(db, func(conn *gorm.DB) error {
+		Current, err := States(conn, Migrations)
+		if err != nil { return err }
+
+		Last := 0
+		for _, State := range Current {
+			switch State.State {
+				case Modified, Missing: return fmt.Errorf("%w: %04d_%s is %s", ErrModified, State.Version, State.Name, State.State)
+				case Applied: Last = State.Version
+			}
+		}
+
+		for _, State := range Current {
+			if State.State != Pending || (To > 0 && State.Version > To) { continue }
+			if State.Version < Last { return fmt.Errorf("%w: %04d_%s", ErrOutOfOrder, State.Version, State.Name) }
+
+			if err := run(conn, State.Migration.Up, func(tx *gorm.DB) error {
+				return tx.Create(&Record{ Version: State.Version, Name: State.Name, Checksum: State.Checksum, AppliedAt: time.Now() }).Error
+			}); err != nil { return fmt.Errorf("migrations: %04d_%s: %w", State.Version, State.Name, err) }
+			Done = append(Done, State.Migration)
+		}
+		return nil
+	})
+	return Done, err
+}
+
+// Down rolls back the last applied migrations, Steps of them, and returns the ones rolled back.
+//
+// Returns:
+//   - ErrIrreversible when one of them has no down file, the ones after it are rolled back already.
+//   - ErrModified when one of them was modified or removed: its down file may not undo what was applied.
+func Down(db *gorm.DB, Migrations []Migration, Steps int) ([]Migration, error) {
+	Done := []Migration{}
+	err := locked(db, func(conn *gorm.DB) error {
+		Current, err := States(conn, Migrations)
+		if err != nil { return err }
+
+		for i := len(Current) - 1; i >= 0 && len(Done) < Steps; i-- {
+			State := Current[i]
+			switch {
+				case State.State == Pending: continue
+				case State.State != Applied: return fmt.Errorf("%w: %04d_%s is %s", ErrModified, State.Version, State.Name, State.State)
+				case strings.TrimSpace(State.Down) == "": return fmt.Errorf("%w: %04d_%s", ErrIrreversible, State.Version, State.Name)
+			}
+
+			if err := run(conn, State.Down, func(tx *gorm.DB) error {
+				return tx.Delete(&Record{}, State.Version).Error
+			}); err != nil { return fmt.Errorf("migrations: %04d_%s: %w", State.Version, State.Name, err) }
+			Done = append(Done, State.Migration)
+		}
+		return nil
+	})
+	return Done, err
+}
+
+// New writes an empty migration numbered after the last one, and returns the up file's path.
+func New(Migrations []Migration, Name string, write func(Path string, Content []byte) error) (string, error) {
+	Name = strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(Name), "_"), "_")
+	if Name == "" { return "", domain.Invalid("migrations: a migration needs a name") }
+
+	Version := 1
+	if len(Migrations) > 0 { Version = Migrations[len(Migrations) - 1].Version + 1 }
+	Base := path.Join(Directory, fmt.Sprintf("%04d_%s", Version, Name))
+
+	if err := write(Base + ".up.sql", []byte("-- " + Name + "\n")); err != nil { return "", err }
+	if err := write(Base + ".down.sql", []byte("-- Undoes " + Name + ", leave the file out when it can't be undone\n")); err != nil { return "", err }
+	return Base + ".up.sql", nil
+}
+
+func prepare(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS "schema_migrations" ("version" bigint PRIMARY KEY, "name" text, "checksum" text, "applied_at" timestamptz)`).Error
+}
+
+/* The statements and the record of them in one transaction, or one after the other without */
+func run(db *gorm.DB, Statements string, record func(*gorm.DB) error) error {
+	if !strings.HasPrefix(strings.TrimSpace(Statements), noTransaction) {
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(Statements).Error; err != nil { return err }
+			return record(tx)
+		})
+	}
+	if err := db.Exec(Statements).Error; err != nil { return err }
+	return record(db)
+}
+
+/* An advisory lock is the session's, the whole run stays on the connection which took it */
+func locked(db *gorm.DB, do func(*gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil { return err }
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+		return do(conn)
+	})
+}
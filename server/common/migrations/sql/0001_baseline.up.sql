@@ -0,0 +1,772 @@
+-- The schema as AutoMigrate made it from the models before migrations were versioned. It only adds what's missing,
+-- a database an earlier version AutoMigrated is brought up to it, see package migrations.
+
+CREATE TABLE IF NOT EXISTS "cities" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"display_name" text,"display_name_in" text,"lat" text,"lng" text,"streets_count" bigint,PRIMARY KEY ("id"));
+ALTER TABLE "cities" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "cities" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "cities" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "cities" ADD COLUMN IF NOT EXISTS "display_name" text;
+ALTER TABLE "cities" ADD COLUMN IF NOT EXISTS "display_name_in" text;
+ALTER TABLE "cities" ADD COLUMN IF NOT EXISTS "lat" text;
+ALTER TABLE "cities" ADD COLUMN IF NOT EXISTS "lng" text;
+ALTER TABLE "cities" ADD COLUMN IF NOT EXISTS "streets_count" bigint;
+CREATE INDEX IF NOT EXISTS "idx_cities_deleted_at" ON "cities" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "districts" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"city_id" bigint,"display_name" text,"display_name_in" text,"lat" text,"lng" text,"streets_count" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_districts_city" FOREIGN KEY ("city_id") REFERENCES "cities"("id"));
+ALTER TABLE "districts" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "districts" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "districts" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "districts" ADD COLUMN IF NOT EXISTS "city_id" bigint;
+ALTER TABLE "districts" ADD COLUMN IF NOT EXISTS "display_name" text;
+ALTER TABLE "districts" ADD COLUMN IF NOT EXISTS "display_name_in" text;
+ALTER TABLE "districts" ADD COLUMN IF NOT EXISTS "lat" text;
+ALTER TABLE "districts" ADD COLUMN IF NOT EXISTS "lng" text;
+ALTER TABLE "districts" ADD COLUMN IF NOT EXISTS "streets_count" bigint;
+DO $$ BEGIN ALTER TABLE "districts" ADD CONSTRAINT "fk_districts_city" FOREIGN KEY ("city_id") REFERENCES "cities"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_districts_deleted_at" ON "districts" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "branches" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"slug" text,"phone_number" text,"map" text,"district_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_branches_district" FOREIGN KEY ("district_id") REFERENCES "districts"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "branches" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "branches" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "branches" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "branches" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "branches" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "branches" ADD COLUMN IF NOT EXISTS "phone_number" text;
+ALTER TABLE "branches" ADD COLUMN IF NOT EXISTS "map" text;
+ALTER TABLE "branches" ADD COLUMN IF NOT EXISTS "district_id" bigint;
+DO $$ BEGIN ALTER TABLE "branches" ADD CONSTRAINT "fk_branches_district" FOREIGN KEY ("district_id") REFERENCES "districts"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_branches_deleted_at" ON "branches" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "branch_shifts" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"branches_id" bigint,"name" text,"slug" text,"opens_at" timestamptz,"closes_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_branches_shifts" FOREIGN KEY ("branches_id") REFERENCES "branches"("id"));
+ALTER TABLE "branch_shifts" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "branch_shifts" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "branch_shifts" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "branch_shifts" ADD COLUMN IF NOT EXISTS "branches_id" bigint;
+ALTER TABLE "branch_shifts" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "branch_shifts" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "branch_shifts" ADD COLUMN IF NOT EXISTS "opens_at" timestamptz;
+ALTER TABLE "branch_shifts" ADD COLUMN IF NOT EXISTS "closes_at" timestamptz;
+DO $$ BEGIN ALTER TABLE "branch_shifts" ADD CONSTRAINT "fk_branches_shifts" FOREIGN KEY ("branches_id") REFERENCES "branches"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_branch_shifts_deleted_at" ON "branch_shifts" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "category_filters_options" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"key" text,"value" text,PRIMARY KEY ("id"));
+ALTER TABLE "category_filters_options" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "category_filters_options" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "category_filters_options" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "category_filters_options" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "category_filters_options" ADD COLUMN IF NOT EXISTS "key" text;
+ALTER TABLE "category_filters_options" ADD COLUMN IF NOT EXISTS "value" text;
+CREATE INDEX IF NOT EXISTS "idx_category_filters_options_deleted_at" ON "category_filters_options" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "category_filters" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"slug" text,"default_value_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_category_filters_default_value" FOREIGN KEY ("default_value_id") REFERENCES "category_filters_options"("id"));
+ALTER TABLE "category_filters" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "category_filters" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "category_filters" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "category_filters" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "category_filters" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "category_filters" ADD COLUMN IF NOT EXISTS "default_value_id" bigint;
+DO $$ BEGIN ALTER TABLE "category_filters" ADD CONSTRAINT "fk_category_filters_default_value" FOREIGN KEY ("default_value_id") REFERENCES "category_filters_options"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_category_filters_deleted_at" ON "category_filters" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "category_filters_options_joins" ("category_filters_id" bigint,"category_filters_option_id" bigint,PRIMARY KEY ("category_filters_id","category_filters_option_id"),CONSTRAINT "fk_category_filters_options_joins_category_filters" FOREIGN KEY ("category_filters_id") REFERENCES "category_filters"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_category_filters_options_joins_category_filters_option" FOREIGN KEY ("category_filters_option_id") REFERENCES "category_filters_options"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "category_filters_options_joins" ADD COLUMN IF NOT EXISTS "category_filters_id" bigint;
+ALTER TABLE "category_filters_options_joins" ADD COLUMN IF NOT EXISTS "category_filters_option_id" bigint;
+DO $$ BEGIN ALTER TABLE "category_filters_options_joins" ADD CONSTRAINT "fk_category_filters_options_joins_category_filters" FOREIGN KEY ("category_filters_id") REFERENCES "category_filters"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "category_filters_options_joins" ADD CONSTRAINT "fk_category_filters_options_joins_category_filters_option" FOREIGN KEY ("category_filters_option_id") REFERENCES "category_filters_options"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE TABLE IF NOT EXISTS "file_types" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"ext" text,"max_size" bigint,"mimes" text,"contexts" text,"enabled" boolean DEFAULT true,"thumbnails" boolean DEFAULT true,"scan" boolean DEFAULT true,PRIMARY KEY ("id"));
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "ext" text;
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "max_size" bigint;
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "mimes" text;
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "contexts" text;
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "enabled" boolean DEFAULT true;
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "thumbnails" boolean DEFAULT true;
+ALTER TABLE "file_types" ADD COLUMN IF NOT EXISTS "scan" boolean DEFAULT true;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_file_types_ext" ON "file_types" ("ext");
+CREATE INDEX IF NOT EXISTS "idx_file_types_deleted_at" ON "file_types" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "files" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"public_id" varchar(36),"name" text,"original" text,"location" text,"path" text,"size" bigint,"mime" text,"base64" text,"compressed" boolean,"type_id" bigint,"transcoding" varchar(16),"visibility" varchar(16) DEFAULT 'public',"review" varchar(16),"owner_id" bigint,"version" bigint DEFAULT 1,PRIMARY KEY ("id"),CONSTRAINT "fk_files_type" FOREIGN KEY ("type_id") REFERENCES "file_types"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "public_id" varchar(36);
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "original" text;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "location" text;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "path" text;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "size" bigint;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "mime" text;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "base64" text;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "compressed" boolean;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "type_id" bigint;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "transcoding" varchar(16);
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "visibility" varchar(16) DEFAULT 'public';
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "review" varchar(16);
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "owner_id" bigint;
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "version" bigint DEFAULT 1;
+DO $$ BEGIN ALTER TABLE "files" ADD CONSTRAINT "fk_files_type" FOREIGN KEY ("type_id") REFERENCES "file_types"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_files_owner_id" ON "files" ("owner_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_files_public_id" ON "files" ("public_id");
+CREATE INDEX IF NOT EXISTS "idx_files_deleted_at" ON "files" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "categories" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"slug" text,"public" boolean,"icon_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_categories_icon" FOREIGN KEY ("icon_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "public" boolean;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "icon_id" bigint;
+DO $$ BEGIN ALTER TABLE "categories" ADD CONSTRAINT "fk_categories_icon" FOREIGN KEY ("icon_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_categories_deleted_at" ON "categories" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "category_filters_joins" ("categories_id" bigint,"category_filters_id" bigint,PRIMARY KEY ("categories_id","category_filters_id"),CONSTRAINT "fk_category_filters_joins_categories" FOREIGN KEY ("categories_id") REFERENCES "categories"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_category_filters_joins_category_filters" FOREIGN KEY ("category_filters_id") REFERENCES "category_filters"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "category_filters_joins" ADD COLUMN IF NOT EXISTS "categories_id" bigint;
+ALTER TABLE "category_filters_joins" ADD COLUMN IF NOT EXISTS "category_filters_id" bigint;
+DO $$ BEGIN ALTER TABLE "category_filters_joins" ADD CONSTRAINT "fk_category_filters_joins_categories" FOREIGN KEY ("categories_id") REFERENCES "categories"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "category_filters_joins" ADD CONSTRAINT "fk_category_filters_joins_category_filters" FOREIGN KEY ("category_filters_id") REFERENCES "category_filters"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE TABLE IF NOT EXISTS "chat_statuses" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"slug" text,PRIMARY KEY ("id"));
+ALTER TABLE "chat_statuses" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "chat_statuses" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "chat_statuses" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "chat_statuses" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "chat_statuses" ADD COLUMN IF NOT EXISTS "slug" text;
+CREATE INDEX IF NOT EXISTS "idx_chat_statuses_deleted_at" ON "chat_statuses" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "chat_types" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"slug" text,PRIMARY KEY ("id"));
+ALTER TABLE "chat_types" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "chat_types" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "chat_types" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "chat_types" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "chat_types" ADD COLUMN IF NOT EXISTS "slug" text;
+CREATE INDEX IF NOT EXISTS "idx_chat_types_deleted_at" ON "chat_types" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "chats" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"fullname" text,"email" text,"type_id" bigint,"chat_status_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_chats_type" FOREIGN KEY ("type_id") REFERENCES "chat_types"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_chats_chat_status" FOREIGN KEY ("chat_status_id") REFERENCES "chat_statuses"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "chats" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "chats" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "chats" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "chats" ADD COLUMN IF NOT EXISTS "fullname" text;
+ALTER TABLE "chats" ADD COLUMN IF NOT EXISTS "email" text;
+ALTER TABLE "chats" ADD COLUMN IF NOT EXISTS "type_id" bigint;
+ALTER TABLE "chats" ADD COLUMN IF NOT EXISTS "chat_status_id" bigint;
+DO $$ BEGIN ALTER TABLE "chats" ADD CONSTRAINT "fk_chats_type" FOREIGN KEY ("type_id") REFERENCES "chat_types"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "chats" ADD CONSTRAINT "fk_chats_chat_status" FOREIGN KEY ("chat_status_id") REFERENCES "chat_statuses"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_chats_deleted_at" ON "chats" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "chat_letters" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"chat_id" bigint,"body" text,"from" text,"to" text,"letter_status_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_chat_letters_chat" FOREIGN KEY ("chat_id") REFERENCES "chats"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_chat_letters_letter_status" FOREIGN KEY ("letter_status_id") REFERENCES "chat_statuses"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "chat_letters" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "chat_letters" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "chat_letters" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "chat_letters" ADD COLUMN IF NOT EXISTS "chat_id" bigint;
+ALTER TABLE "chat_letters" ADD COLUMN IF NOT EXISTS "body" text;
+ALTER TABLE "chat_letters" ADD COLUMN IF NOT EXISTS "from" text;
+ALTER TABLE "chat_letters" ADD COLUMN IF NOT EXISTS "to" text;
+ALTER TABLE "chat_letters" ADD COLUMN IF NOT EXISTS "letter_status_id" bigint;
+DO $$ BEGIN ALTER TABLE "chat_letters" ADD CONSTRAINT "fk_chat_letters_chat" FOREIGN KEY ("chat_id") REFERENCES "chats"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "chat_letters" ADD CONSTRAINT "fk_chat_letters_letter_status" FOREIGN KEY ("letter_status_id") REFERENCES "chat_statuses"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_chat_letters_deleted_at" ON "chat_letters" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "faqs" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"slug" text,"answer" text,"question" text,PRIMARY KEY ("id"));
+ALTER TABLE "faqs" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "faqs" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "faqs" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "faqs" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "faqs" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "faqs" ADD COLUMN IF NOT EXISTS "answer" text;
+ALTER TABLE "faqs" ADD COLUMN IF NOT EXISTS "question" text;
+CREATE INDEX IF NOT EXISTS "idx_faqs_deleted_at" ON "faqs" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "file_thumbnails" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"file_id" bigint,"width" bigint,"height" bigint,"path" text,PRIMARY KEY ("id"),CONSTRAINT "fk_files_thumbnails" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE CASCADE ON UPDATE CASCADE);
+ALTER TABLE "file_thumbnails" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "file_thumbnails" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "file_thumbnails" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "file_thumbnails" ADD COLUMN IF NOT EXISTS "file_id" bigint;
+ALTER TABLE "file_thumbnails" ADD COLUMN IF NOT EXISTS "width" bigint;
+ALTER TABLE "file_thumbnails" ADD COLUMN IF NOT EXISTS "height" bigint;
+ALTER TABLE "file_thumbnails" ADD COLUMN IF NOT EXISTS "path" text;
+DO $$ BEGIN ALTER TABLE "file_thumbnails" ADD CONSTRAINT "fk_files_thumbnails" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE CASCADE ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_file_thumbnails_file_id" ON "file_thumbnails" ("file_id");
+CREATE INDEX IF NOT EXISTS "idx_file_thumbnails_deleted_at" ON "file_thumbnails" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "file_metadata" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"file_id" bigint,"width" bigint,"height" bigint,"pages" bigint,"duration" decimal,"codec" varchar(32),PRIMARY KEY ("id"),CONSTRAINT "fk_files_metadata" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE CASCADE ON UPDATE CASCADE);
+ALTER TABLE "file_metadata" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "file_metadata" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "file_metadata" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "file_metadata" ADD COLUMN IF NOT EXISTS "file_id" bigint;
+ALTER TABLE "file_metadata" ADD COLUMN IF NOT EXISTS "width" bigint;
+ALTER TABLE "file_metadata" ADD COLUMN IF NOT EXISTS "height" bigint;
+ALTER TABLE "file_metadata" ADD COLUMN IF NOT EXISTS "pages" bigint;
+ALTER TABLE "file_metadata" ADD COLUMN IF NOT EXISTS "duration" decimal;
+ALTER TABLE "file_metadata" ADD COLUMN IF NOT EXISTS "codec" varchar(32);
+DO $$ BEGIN ALTER TABLE "file_metadata" ADD CONSTRAINT "fk_files_metadata" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE CASCADE ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_file_metadata_file_id" ON "file_metadata" ("file_id");
+CREATE INDEX IF NOT EXISTS "idx_file_metadata_deleted_at" ON "file_metadata" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "file_renditions" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"file_id" bigint,"width" bigint,"height" bigint,"key" text,PRIMARY KEY ("id"),CONSTRAINT "fk_files_renditions" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE CASCADE ON UPDATE CASCADE);
+ALTER TABLE "file_renditions" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "file_renditions" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "file_renditions" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "file_renditions" ADD COLUMN IF NOT EXISTS "file_id" bigint;
+ALTER TABLE "file_renditions" ADD COLUMN IF NOT EXISTS "width" bigint;
+ALTER TABLE "file_renditions" ADD COLUMN IF NOT EXISTS "height" bigint;
+ALTER TABLE "file_renditions" ADD COLUMN IF NOT EXISTS "key" text;
+DO $$ BEGIN ALTER TABLE "file_renditions" ADD CONSTRAINT "fk_files_renditions" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE CASCADE ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_file_renditions_file_id" ON "file_renditions" ("file_id");
+CREATE INDEX IF NOT EXISTS "idx_file_renditions_deleted_at" ON "file_renditions" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "file_derivatives" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"file_id" bigint,"format" varchar(8),"width" bigint,"key" text,"size" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_files_derivatives" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE CASCADE ON UPDATE CASCADE);
+ALTER TABLE "file_derivatives" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "file_derivatives" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "file_derivatives" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "file_derivatives" ADD COLUMN IF NOT EXISTS "file_id" bigint;
+ALTER TABLE "file_derivatives" ADD COLUMN IF NOT EXISTS "format" varchar(8);
+ALTER TABLE "file_derivatives" ADD COLUMN IF NOT EXISTS "width" bigint;
+ALTER TABLE "file_derivatives" ADD COLUMN IF NOT EXISTS "key" text;
+ALTER TABLE "file_derivatives" ADD COLUMN IF NOT EXISTS "size" bigint;
+DO $$ BEGIN ALTER TABLE "file_derivatives" ADD CONSTRAINT "fk_files_derivatives" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE CASCADE ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_file_derivatives_file_id" ON "file_derivatives" ("file_id");
+CREATE INDEX IF NOT EXISTS "idx_file_derivatives_deleted_at" ON "file_derivatives" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "file_versions" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"file_id" bigint,"version" bigint,"name" text,"original" text,"location" text,"path" text,"size" bigint,"mime" text,"base64" text,"type_id" bigint,"review" varchar(16),PRIMARY KEY ("id"),CONSTRAINT "fk_files_versions" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE CASCADE ON UPDATE CASCADE);
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "file_id" bigint;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "version" bigint;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "original" text;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "location" text;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "path" text;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "size" bigint;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "mime" text;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "base64" text;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "type_id" bigint;
+ALTER TABLE "file_versions" ADD COLUMN IF NOT EXISTS "review" varchar(16);
+DO $$ BEGIN ALTER TABLE "file_versions" ADD CONSTRAINT "fk_files_versions" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE CASCADE ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_file_versions_file_id" ON "file_versions" ("file_id");
+CREATE INDEX IF NOT EXISTS "idx_file_versions_deleted_at" ON "file_versions" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "users" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"fullname" text,"email" text,"password" text,"token" text,"locale" text,"failed_logins" bigint,"locked_until" timestamptz,"alert_new_device" boolean DEFAULT true,"alert_password_change" boolean DEFAULT true,"alert_lockout" boolean DEFAULT true,"digest_weekly" boolean DEFAULT true,PRIMARY KEY ("id"));
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "fullname" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "email" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "password" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "token" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "locale" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "failed_logins" bigint;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "locked_until" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "alert_new_device" boolean DEFAULT true;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "alert_password_change" boolean DEFAULT true;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "alert_lockout" boolean DEFAULT true;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "digest_weekly" boolean DEFAULT true;
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "file_events" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"file_id" bigint,"action" varchar(16),"users_id" bigint,"ip" varchar(64),"user_agent" varchar(512),"request_id" varchar(64),"detail" text,PRIMARY KEY ("id"),CONSTRAINT "fk_file_events_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "file_events" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "file_events" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "file_events" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "file_events" ADD COLUMN IF NOT EXISTS "file_id" bigint;
+ALTER TABLE "file_events" ADD COLUMN IF NOT EXISTS "action" varchar(16);
+ALTER TABLE "file_events" ADD COLUMN IF NOT EXISTS "users_id" bigint;
+ALTER TABLE "file_events" ADD COLUMN IF NOT EXISTS "ip" varchar(64);
+ALTER TABLE "file_events" ADD COLUMN IF NOT EXISTS "user_agent" varchar(512);
+ALTER TABLE "file_events" ADD COLUMN IF NOT EXISTS "request_id" varchar(64);
+ALTER TABLE "file_events" ADD COLUMN IF NOT EXISTS "detail" text;
+DO $$ BEGIN ALTER TABLE "file_events" ADD CONSTRAINT "fk_file_events_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_file_events_deleted_at" ON "file_events" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_file_events_users_id" ON "file_events" ("users_id");
+CREATE INDEX IF NOT EXISTS "idx_file_events_file_id" ON "file_events" ("file_id");
+CREATE TABLE IF NOT EXISTS "resumable_uploads" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"token" text,"name" text,"mime" text,"context" text,"length" bigint,"offset" bigint,"file_id" bigint,"owner_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_resumable_uploads_file" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "token" text;
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "mime" text;
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "context" text;
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "length" bigint;
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "offset" bigint;
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "file_id" bigint;
+ALTER TABLE "resumable_uploads" ADD COLUMN IF NOT EXISTS "owner_id" bigint;
+DO $$ BEGIN ALTER TABLE "resumable_uploads" ADD CONSTRAINT "fk_resumable_uploads_file" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_resumable_uploads_owner_id" ON "resumable_uploads" ("owner_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_resumable_uploads_token" ON "resumable_uploads" ("token");
+CREATE INDEX IF NOT EXISTS "idx_resumable_uploads_deleted_at" ON "resumable_uploads" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "upload_quotas" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"users_id" bigint,"roles_id" bigint,"bytes" bigint,PRIMARY KEY ("id"));
+ALTER TABLE "upload_quotas" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "upload_quotas" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "upload_quotas" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "upload_quotas" ADD COLUMN IF NOT EXISTS "users_id" bigint;
+ALTER TABLE "upload_quotas" ADD COLUMN IF NOT EXISTS "roles_id" bigint;
+ALTER TABLE "upload_quotas" ADD COLUMN IF NOT EXISTS "bytes" bigint;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_upload_quotas_users_id" ON "upload_quotas" ("users_id");
+CREATE INDEX IF NOT EXISTS "idx_upload_quotas_deleted_at" ON "upload_quotas" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_upload_quotas_roles_id" ON "upload_quotas" ("roles_id");
+CREATE TABLE IF NOT EXISTS "interfaces" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"ver" bigint,"name" text,"slug" text,"low_bandwidth" boolean,PRIMARY KEY ("id"));
+ALTER TABLE "interfaces" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "interfaces" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "interfaces" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "interfaces" ADD COLUMN IF NOT EXISTS "ver" bigint;
+ALTER TABLE "interfaces" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "interfaces" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "interfaces" ADD COLUMN IF NOT EXISTS "low_bandwidth" boolean;
+CREATE INDEX IF NOT EXISTS "idx_interfaces_deleted_at" ON "interfaces" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "news_types" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"slug" text,PRIMARY KEY ("id"));
+ALTER TABLE "news_types" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "news_types" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "news_types" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "news_types" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "news_types" ADD COLUMN IF NOT EXISTS "slug" text;
+CREATE INDEX IF NOT EXISTS "idx_news_types_deleted_at" ON "news_types" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "news" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"public_id" varchar(36),"views" bigint,"title" text,"body" text,"public" boolean,"published_at" timestamptz,"url" text,"thumbnail_id" bigint,"type_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_news_thumbnail" FOREIGN KEY ("thumbnail_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_news_type" FOREIGN KEY ("type_id") REFERENCES "news_types"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "public_id" varchar(36);
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "views" bigint;
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "title" text;
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "body" text;
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "public" boolean;
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "published_at" timestamptz;
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "url" text;
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "thumbnail_id" bigint;
+ALTER TABLE "news" ADD COLUMN IF NOT EXISTS "type_id" bigint;
+DO $$ BEGIN ALTER TABLE "news" ADD CONSTRAINT "fk_news_thumbnail" FOREIGN KEY ("thumbnail_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "news" ADD CONSTRAINT "fk_news_type" FOREIGN KEY ("type_id") REFERENCES "news_types"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_news_public_id" ON "news" ("public_id");
+CREATE INDEX IF NOT EXISTS "idx_news_deleted_at" ON "news" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "interface_news_joins" ("interface_id" bigint,"news_id" bigint,PRIMARY KEY ("interface_id","news_id"),CONSTRAINT "fk_interface_news_joins_interface" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_interface_news_joins_news" FOREIGN KEY ("news_id") REFERENCES "news"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "interface_news_joins" ADD COLUMN IF NOT EXISTS "interface_id" bigint;
+ALTER TABLE "interface_news_joins" ADD COLUMN IF NOT EXISTS "news_id" bigint;
+DO $$ BEGIN ALTER TABLE "interface_news_joins" ADD CONSTRAINT "fk_interface_news_joins_interface" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "interface_news_joins" ADD CONSTRAINT "fk_interface_news_joins_news" FOREIGN KEY ("news_id") REFERENCES "news"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE TABLE IF NOT EXISTS "interface_slide_shows" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"interface_id" bigint,"name" text,"slug" text,"slogan" text,"desc" text,"url" text,"index" bigint,"type_id" bigint,"pic_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_interface_slide_shows_type" FOREIGN KEY ("type_id") REFERENCES "file_types"("id"),CONSTRAINT "fk_interface_slide_shows_pic" FOREIGN KEY ("pic_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_interfaces_slide_show" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"));
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "interface_id" bigint;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "slogan" text;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "desc" text;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "url" text;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "index" bigint;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "type_id" bigint;
+ALTER TABLE "interface_slide_shows" ADD COLUMN IF NOT EXISTS "pic_id" bigint;
+DO $$ BEGIN ALTER TABLE "interface_slide_shows" ADD CONSTRAINT "fk_interface_slide_shows_type" FOREIGN KEY ("type_id") REFERENCES "file_types"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "interface_slide_shows" ADD CONSTRAINT "fk_interface_slide_shows_pic" FOREIGN KEY ("pic_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "interface_slide_shows" ADD CONSTRAINT "fk_interfaces_slide_show" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_interface_slide_shows_deleted_at" ON "interface_slide_shows" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "interface_reasons" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"interface_id" bigint,"name" text,"slug" text,"title" text,"desc" text,"url" text,"icon_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_interface_reasons_icon" FOREIGN KEY ("icon_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_interfaces_reasons" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"));
+ALTER TABLE "interface_reasons" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "interface_reasons" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "interface_reasons" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "interface_reasons" ADD COLUMN IF NOT EXISTS "interface_id" bigint;
+ALTER TABLE "interface_reasons" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "interface_reasons" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "interface_reasons" ADD COLUMN IF NOT EXISTS "title" text;
+ALTER TABLE "interface_reasons" ADD COLUMN IF NOT EXISTS "desc" text;
+ALTER TABLE "interface_reasons" ADD COLUMN IF NOT EXISTS "url" text;
+ALTER TABLE "interface_reasons" ADD COLUMN IF NOT EXISTS "icon_id" bigint;
+DO $$ BEGIN ALTER TABLE "interface_reasons" ADD CONSTRAINT "fk_interface_reasons_icon" FOREIGN KEY ("icon_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "interface_reasons" ADD CONSTRAINT "fk_interfaces_reasons" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_interface_reasons_deleted_at" ON "interface_reasons" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "interface_contacts" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"interface_id" bigint,"ver" bigint,"name" text,"slug" text,"phone" text,"email" text,"location" text,"short_desc" text,"location_link" text,"location_iframe" text,PRIMARY KEY ("id"),CONSTRAINT "fk_interfaces_contact" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"));
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "interface_id" bigint;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "ver" bigint;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "phone" text;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "email" text;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "location" text;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "short_desc" text;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "location_link" text;
+ALTER TABLE "interface_contacts" ADD COLUMN IF NOT EXISTS "location_iframe" text;
+DO $$ BEGIN ALTER TABLE "interface_contacts" ADD CONSTRAINT "fk_interfaces_contact" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_interface_contacts_deleted_at" ON "interface_contacts" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "interface_abouts" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"interface_id" bigint,"ver" bigint,"body" text,"terms" text,PRIMARY KEY ("id"),CONSTRAINT "fk_interfaces_about" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"));
+ALTER TABLE "interface_abouts" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "interface_abouts" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "interface_abouts" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "interface_abouts" ADD COLUMN IF NOT EXISTS "interface_id" bigint;
+ALTER TABLE "interface_abouts" ADD COLUMN IF NOT EXISTS "ver" bigint;
+ALTER TABLE "interface_abouts" ADD COLUMN IF NOT EXISTS "body" text;
+ALTER TABLE "interface_abouts" ADD COLUMN IF NOT EXISTS "terms" text;
+DO $$ BEGIN ALTER TABLE "interface_abouts" ADD CONSTRAINT "fk_interfaces_about" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_interface_abouts_deleted_at" ON "interface_abouts" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "interface_mails" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"interface_id" bigint,"logo_id" bigint,"primary" text,"background" text,"text" text,"footer" text,PRIMARY KEY ("id"),CONSTRAINT "fk_interface_mails_logo" FOREIGN KEY ("logo_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_interfaces_mail" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"));
+ALTER TABLE "interface_mails" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "interface_mails" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "interface_mails" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "interface_mails" ADD COLUMN IF NOT EXISTS "interface_id" bigint;
+ALTER TABLE "interface_mails" ADD COLUMN IF NOT EXISTS "logo_id" bigint;
+ALTER TABLE "interface_mails" ADD COLUMN IF NOT EXISTS "primary" text;
+ALTER TABLE "interface_mails" ADD COLUMN IF NOT EXISTS "background" text;
+ALTER TABLE "interface_mails" ADD COLUMN IF NOT EXISTS "text" text;
+ALTER TABLE "interface_mails" ADD COLUMN IF NOT EXISTS "footer" text;
+DO $$ BEGIN ALTER TABLE "interface_mails" ADD CONSTRAINT "fk_interface_mails_logo" FOREIGN KEY ("logo_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "interface_mails" ADD CONSTRAINT "fk_interfaces_mail" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_interface_mails_deleted_at" ON "interface_mails" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "social_media" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"interface_id" bigint,"name" text,"slug" text,"url" text,"icon_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_social_media_icon" FOREIGN KEY ("icon_id") REFERENCES "files"("id"),CONSTRAINT "fk_interfaces_social_media" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"));
+ALTER TABLE "social_media" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "social_media" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "social_media" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "social_media" ADD COLUMN IF NOT EXISTS "interface_id" bigint;
+ALTER TABLE "social_media" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "social_media" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "social_media" ADD COLUMN IF NOT EXISTS "url" text;
+ALTER TABLE "social_media" ADD COLUMN IF NOT EXISTS "icon_id" bigint;
+DO $$ BEGIN ALTER TABLE "social_media" ADD CONSTRAINT "fk_social_media_icon" FOREIGN KEY ("icon_id") REFERENCES "files"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "social_media" ADD CONSTRAINT "fk_interfaces_social_media" FOREIGN KEY ("interface_id") REFERENCES "interfaces"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_social_media_deleted_at" ON "social_media" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "interface_versions" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"interface_id" bigint,"version" bigint,"snapshot" text,"users_id" bigint,"note" text,PRIMARY KEY ("id"),CONSTRAINT "fk_interface_versions_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "interface_versions" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "interface_versions" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "interface_versions" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "interface_versions" ADD COLUMN IF NOT EXISTS "interface_id" bigint;
+ALTER TABLE "interface_versions" ADD COLUMN IF NOT EXISTS "version" bigint;
+ALTER TABLE "interface_versions" ADD COLUMN IF NOT EXISTS "snapshot" text;
+ALTER TABLE "interface_versions" ADD COLUMN IF NOT EXISTS "users_id" bigint;
+ALTER TABLE "interface_versions" ADD COLUMN IF NOT EXISTS "note" text;
+DO $$ BEGIN ALTER TABLE "interface_versions" ADD CONSTRAINT "fk_interface_versions_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_interface_versions_interface_id" ON "interface_versions" ("interface_id");
+CREATE INDEX IF NOT EXISTS "idx_interface_versions_deleted_at" ON "interface_versions" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "products" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"public_id" varchar(36),"name" text,"slug" text,"description" text,"description_html" text,"public" boolean DEFAULT true,"category_id" bigint,"technical_sheet_url" text,"thumbnail_id" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_products_category" FOREIGN KEY ("category_id") REFERENCES "categories"("id"),CONSTRAINT "fk_products_thumbnail" FOREIGN KEY ("thumbnail_id") REFERENCES "files"("id"));
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "public_id" varchar(36);
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "description" text;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "description_html" text;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "public" boolean DEFAULT true;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "category_id" bigint;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "technical_sheet_url" text;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "thumbnail_id" bigint;
+DO $$ BEGIN ALTER TABLE "products" ADD CONSTRAINT "fk_products_category" FOREIGN KEY ("category_id") REFERENCES "categories"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "products" ADD CONSTRAINT "fk_products_thumbnail" FOREIGN KEY ("thumbnail_id") REFERENCES "files"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_products_public_id" ON "products" ("public_id");
+CREATE INDEX IF NOT EXISTS "idx_products_deleted_at" ON "products" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "product_specifications" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"products_id" bigint,"name" text,"slug" text,PRIMARY KEY ("id"),CONSTRAINT "fk_products_specifications" FOREIGN KEY ("products_id") REFERENCES "products"("id"));
+ALTER TABLE "product_specifications" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "product_specifications" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "product_specifications" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "product_specifications" ADD COLUMN IF NOT EXISTS "products_id" bigint;
+ALTER TABLE "product_specifications" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "product_specifications" ADD COLUMN IF NOT EXISTS "slug" text;
+DO $$ BEGIN ALTER TABLE "product_specifications" ADD CONSTRAINT "fk_products_specifications" FOREIGN KEY ("products_id") REFERENCES "products"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_product_specifications_deleted_at" ON "product_specifications" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "product_properties" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"products_id" bigint,"name" text,"slug" text,PRIMARY KEY ("id"),CONSTRAINT "fk_products_properties" FOREIGN KEY ("products_id") REFERENCES "products"("id"));
+ALTER TABLE "product_properties" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "product_properties" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "product_properties" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "product_properties" ADD COLUMN IF NOT EXISTS "products_id" bigint;
+ALTER TABLE "product_properties" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "product_properties" ADD COLUMN IF NOT EXISTS "slug" text;
+DO $$ BEGIN ALTER TABLE "product_properties" ADD CONSTRAINT "fk_products_properties" FOREIGN KEY ("products_id") REFERENCES "products"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_product_properties_deleted_at" ON "product_properties" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "product_approvals" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"products_id" bigint,"name" text,"slug" text,PRIMARY KEY ("id"),CONSTRAINT "fk_products_approvals" FOREIGN KEY ("products_id") REFERENCES "products"("id"));
+ALTER TABLE "product_approvals" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "product_approvals" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "product_approvals" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "product_approvals" ADD COLUMN IF NOT EXISTS "products_id" bigint;
+ALTER TABLE "product_approvals" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "product_approvals" ADD COLUMN IF NOT EXISTS "slug" text;
+DO $$ BEGIN ALTER TABLE "product_approvals" ADD CONSTRAINT "fk_products_approvals" FOREIGN KEY ("products_id") REFERENCES "products"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_product_approvals_deleted_at" ON "product_approvals" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "product_packagings" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"products_id" bigint,"name" text,"slug" text,"reference" text,"conditioning" text,"cardboard" text,PRIMARY KEY ("id"),CONSTRAINT "fk_products_packing" FOREIGN KEY ("products_id") REFERENCES "products"("id"));
+ALTER TABLE "product_packagings" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "product_packagings" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "product_packagings" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "product_packagings" ADD COLUMN IF NOT EXISTS "products_id" bigint;
+ALTER TABLE "product_packagings" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "product_packagings" ADD COLUMN IF NOT EXISTS "slug" text;
+ALTER TABLE "product_packagings" ADD COLUMN IF NOT EXISTS "reference" text;
+ALTER TABLE "product_packagings" ADD COLUMN IF NOT EXISTS "conditioning" text;
+ALTER TABLE "product_packagings" ADD COLUMN IF NOT EXISTS "cardboard" text;
+DO $$ BEGIN ALTER TABLE "product_packagings" ADD CONSTRAINT "fk_products_packing" FOREIGN KEY ("products_id") REFERENCES "products"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_product_packagings_deleted_at" ON "product_packagings" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "preview_channels" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"token" text,"status" text DEFAULT 'open',"published_at" timestamptz,PRIMARY KEY ("id"));
+ALTER TABLE "preview_channels" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "preview_channels" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "preview_channels" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "preview_channels" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "preview_channels" ADD COLUMN IF NOT EXISTS "token" text;
+ALTER TABLE "preview_channels" ADD COLUMN IF NOT EXISTS "status" text DEFAULT 'open';
+ALTER TABLE "preview_channels" ADD COLUMN IF NOT EXISTS "published_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_preview_channels_token" ON "preview_channels" ("token");
+CREATE INDEX IF NOT EXISTS "idx_preview_channels_deleted_at" ON "preview_channels" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "preview_changes" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"channel_id" bigint,"kind" text,"record_id" bigint,"delete" boolean,"payload" text,PRIMARY KEY ("id"),CONSTRAINT "fk_preview_channels_changes" FOREIGN KEY ("channel_id") REFERENCES "preview_channels"("id"));
+ALTER TABLE "preview_changes" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "preview_changes" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "preview_changes" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "preview_changes" ADD COLUMN IF NOT EXISTS "channel_id" bigint;
+ALTER TABLE "preview_changes" ADD COLUMN IF NOT EXISTS "kind" text;
+ALTER TABLE "preview_changes" ADD COLUMN IF NOT EXISTS "record_id" bigint;
+ALTER TABLE "preview_changes" ADD COLUMN IF NOT EXISTS "delete" boolean;
+ALTER TABLE "preview_changes" ADD COLUMN IF NOT EXISTS "payload" text;
+DO $$ BEGIN ALTER TABLE "preview_changes" ADD CONSTRAINT "fk_preview_channels_changes" FOREIGN KEY ("channel_id") REFERENCES "preview_channels"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_preview_changes_deleted_at" ON "preview_changes" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "preview_links" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"channel_id" bigint,"token" text,"path" text,"label" text,"expires_at" timestamptz,"revoked_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_preview_channels_links" FOREIGN KEY ("channel_id") REFERENCES "preview_channels"("id"));
+ALTER TABLE "preview_links" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "preview_links" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "preview_links" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "preview_links" ADD COLUMN IF NOT EXISTS "channel_id" bigint;
+ALTER TABLE "preview_links" ADD COLUMN IF NOT EXISTS "token" text;
+ALTER TABLE "preview_links" ADD COLUMN IF NOT EXISTS "path" text;
+ALTER TABLE "preview_links" ADD COLUMN IF NOT EXISTS "label" text;
+ALTER TABLE "preview_links" ADD COLUMN IF NOT EXISTS "expires_at" timestamptz;
+ALTER TABLE "preview_links" ADD COLUMN IF NOT EXISTS "revoked_at" timestamptz;
+DO $$ BEGIN ALTER TABLE "preview_links" ADD CONSTRAINT "fk_preview_channels_links" FOREIGN KEY ("channel_id") REFERENCES "preview_channels"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_preview_links_token" ON "preview_links" ("token");
+CREATE INDEX IF NOT EXISTS "idx_preview_links_channel_id" ON "preview_links" ("channel_id");
+CREATE INDEX IF NOT EXISTS "idx_preview_links_deleted_at" ON "preview_links" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "preview_visits" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"link_id" bigint,"path" text,"ip" text,"user_agent" text,PRIMARY KEY ("id"),CONSTRAINT "fk_preview_links_visits" FOREIGN KEY ("link_id") REFERENCES "preview_links"("id"));
+ALTER TABLE "preview_visits" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "preview_visits" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "preview_visits" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "preview_visits" ADD COLUMN IF NOT EXISTS "link_id" bigint;
+ALTER TABLE "preview_visits" ADD COLUMN IF NOT EXISTS "path" text;
+ALTER TABLE "preview_visits" ADD COLUMN IF NOT EXISTS "ip" text;
+ALTER TABLE "preview_visits" ADD COLUMN IF NOT EXISTS "user_agent" text;
+DO $$ BEGIN ALTER TABLE "preview_visits" ADD CONSTRAINT "fk_preview_links_visits" FOREIGN KEY ("link_id") REFERENCES "preview_links"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_preview_visits_link_id" ON "preview_visits" ("link_id");
+CREATE INDEX IF NOT EXISTS "idx_preview_visits_deleted_at" ON "preview_visits" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "search_analyzers" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"locale" varchar(8),"stemming" boolean DEFAULT true,"stopwords" text,"synonyms" text,PRIMARY KEY ("id"));
+ALTER TABLE "search_analyzers" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "search_analyzers" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "search_analyzers" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "search_analyzers" ADD COLUMN IF NOT EXISTS "locale" varchar(8);
+ALTER TABLE "search_analyzers" ADD COLUMN IF NOT EXISTS "stemming" boolean DEFAULT true;
+ALTER TABLE "search_analyzers" ADD COLUMN IF NOT EXISTS "stopwords" text;
+ALTER TABLE "search_analyzers" ADD COLUMN IF NOT EXISTS "synonyms" text;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_search_analyzers_locale" ON "search_analyzers" ("locale");
+CREATE INDEX IF NOT EXISTS "idx_search_analyzers_deleted_at" ON "search_analyzers" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "ping_engines" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" varchar(32),"enabled" boolean,PRIMARY KEY ("id"));
+ALTER TABLE "ping_engines" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "ping_engines" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "ping_engines" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "ping_engines" ADD COLUMN IF NOT EXISTS "name" varchar(32);
+ALTER TABLE "ping_engines" ADD COLUMN IF NOT EXISTS "enabled" boolean;
+CREATE INDEX IF NOT EXISTS "idx_ping_engines_deleted_at" ON "ping_engines" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_ping_engines_name" ON "ping_engines" ("name");
+CREATE TABLE IF NOT EXISTS "page_views" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"route" varchar(64),"param" varchar(64),"fragment" boolean,"day" date,"views" bigint,"last_viewed_at" timestamptz,PRIMARY KEY ("id"));
+ALTER TABLE "page_views" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "page_views" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "page_views" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "page_views" ADD COLUMN IF NOT EXISTS "route" varchar(64);
+ALTER TABLE "page_views" ADD COLUMN IF NOT EXISTS "param" varchar(64);
+ALTER TABLE "page_views" ADD COLUMN IF NOT EXISTS "fragment" boolean;
+ALTER TABLE "page_views" ADD COLUMN IF NOT EXISTS "day" date;
+ALTER TABLE "page_views" ADD COLUMN IF NOT EXISTS "views" bigint;
+ALTER TABLE "page_views" ADD COLUMN IF NOT EXISTS "last_viewed_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_page_views_day" ON "page_views" ("route","param","fragment","day");
+CREATE INDEX IF NOT EXISTS "idx_page_views_deleted_at" ON "page_views" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "seo_rules" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"path" varchar(255),"section" boolean,"no_index" boolean,"no_follow" boolean,"canonical" varchar(512),"note" varchar(255),PRIMARY KEY ("id"));
+ALTER TABLE "seo_rules" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "seo_rules" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "seo_rules" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "seo_rules" ADD COLUMN IF NOT EXISTS "path" varchar(255);
+ALTER TABLE "seo_rules" ADD COLUMN IF NOT EXISTS "section" boolean;
+ALTER TABLE "seo_rules" ADD COLUMN IF NOT EXISTS "no_index" boolean;
+ALTER TABLE "seo_rules" ADD COLUMN IF NOT EXISTS "no_follow" boolean;
+ALTER TABLE "seo_rules" ADD COLUMN IF NOT EXISTS "canonical" varchar(512);
+ALTER TABLE "seo_rules" ADD COLUMN IF NOT EXISTS "note" varchar(255);
+CREATE INDEX IF NOT EXISTS "idx_seo_rules_deleted_at" ON "seo_rules" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_seo_rules_path" ON "seo_rules" ("path");
+CREATE TABLE IF NOT EXISTS "budget_reports" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"path" varchar(255),"status" varchar(16),"payload" bigint,"blocking" bigint,"images" bigint,"largest_image" bigint,"exceeded" text,"error" text,"checked_at" timestamptz,PRIMARY KEY ("id"));
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "path" varchar(255);
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "status" varchar(16);
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "payload" bigint;
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "blocking" bigint;
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "images" bigint;
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "largest_image" bigint;
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "exceeded" text;
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "error" text;
+ALTER TABLE "budget_reports" ADD COLUMN IF NOT EXISTS "checked_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_budget_reports_checked_at" ON "budget_reports" ("checked_at");
+CREATE INDEX IF NOT EXISTS "idx_budget_reports_deleted_at" ON "budget_reports" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "kiosk_displays" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"token" text,"page" varchar(32),"refresh" bigint,"ancestors" text,"seen_at" timestamptz,PRIMARY KEY ("id"));
+ALTER TABLE "kiosk_displays" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "kiosk_displays" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "kiosk_displays" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "kiosk_displays" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "kiosk_displays" ADD COLUMN IF NOT EXISTS "token" text;
+ALTER TABLE "kiosk_displays" ADD COLUMN IF NOT EXISTS "page" varchar(32);
+ALTER TABLE "kiosk_displays" ADD COLUMN IF NOT EXISTS "refresh" bigint;
+ALTER TABLE "kiosk_displays" ADD COLUMN IF NOT EXISTS "ancestors" text;
+ALTER TABLE "kiosk_displays" ADD COLUMN IF NOT EXISTS "seen_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_kiosk_displays_token" ON "kiosk_displays" ("token");
+CREATE INDEX IF NOT EXISTS "idx_kiosk_displays_deleted_at" ON "kiosk_displays" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "installations" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"site_name" text,"locale" text,"uploads" text,"completed_at" timestamptz,PRIMARY KEY ("id"));
+ALTER TABLE "installations" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "installations" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "installations" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "installations" ADD COLUMN IF NOT EXISTS "site_name" text;
+ALTER TABLE "installations" ADD COLUMN IF NOT EXISTS "locale" text;
+ALTER TABLE "installations" ADD COLUMN IF NOT EXISTS "uploads" text;
+ALTER TABLE "installations" ADD COLUMN IF NOT EXISTS "completed_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_installations_deleted_at" ON "installations" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "digests" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"period_start" timestamptz,"period_end" timestamptz,"recipients" bigint,PRIMARY KEY ("id"));
+ALTER TABLE "digests" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "digests" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "digests" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "digests" ADD COLUMN IF NOT EXISTS "period_start" timestamptz;
+ALTER TABLE "digests" ADD COLUMN IF NOT EXISTS "period_end" timestamptz;
+ALTER TABLE "digests" ADD COLUMN IF NOT EXISTS "recipients" bigint;
+CREATE INDEX IF NOT EXISTS "idx_digests_deleted_at" ON "digests" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "job_failures" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"job" text,"error" text,PRIMARY KEY ("id"));
+ALTER TABLE "job_failures" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "job_failures" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "job_failures" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "job_failures" ADD COLUMN IF NOT EXISTS "job" text;
+ALTER TABLE "job_failures" ADD COLUMN IF NOT EXISTS "error" text;
+CREATE INDEX IF NOT EXISTS "idx_job_failures_job" ON "job_failures" ("job");
+CREATE INDEX IF NOT EXISTS "idx_job_failures_deleted_at" ON "job_failures" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "outbox_messages" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"key" text,"kind" text,"topic" text,"payload" text,"status" text DEFAULT 'pending',"attempts" bigint,"available_at" timestamptz,"sent_at" timestamptz,"error" text,PRIMARY KEY ("id"));
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "key" text;
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "kind" text;
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "topic" text;
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "payload" text;
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "status" text DEFAULT 'pending';
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "attempts" bigint;
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "available_at" timestamptz;
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "sent_at" timestamptz;
+ALTER TABLE "outbox_messages" ADD COLUMN IF NOT EXISTS "error" text;
+CREATE INDEX IF NOT EXISTS "idx_outbox_messages_available_at" ON "outbox_messages" ("available_at");
+CREATE INDEX IF NOT EXISTS "idx_outbox_messages_status" ON "outbox_messages" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_outbox_messages_key" ON "outbox_messages" ("key");
+CREATE INDEX IF NOT EXISTS "idx_outbox_messages_deleted_at" ON "outbox_messages" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "permissions" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"description" text,PRIMARY KEY ("id"));
+ALTER TABLE "permissions" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "permissions" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "permissions" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "permissions" ADD COLUMN IF NOT EXISTS "name" text;
+ALTER TABLE "permissions" ADD COLUMN IF NOT EXISTS "description" text;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_permissions_name" ON "permissions" ("name");
+CREATE INDEX IF NOT EXISTS "idx_permissions_deleted_at" ON "permissions" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "roles" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,PRIMARY KEY ("id"));
+ALTER TABLE "roles" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "roles" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "roles" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "roles" ADD COLUMN IF NOT EXISTS "name" text;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_roles_name" ON "roles" ("name");
+CREATE INDEX IF NOT EXISTS "idx_roles_deleted_at" ON "roles" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "role_permissions" ("roles_id" bigint,"permissions_id" bigint,PRIMARY KEY ("roles_id","permissions_id"),CONSTRAINT "fk_role_permissions_roles" FOREIGN KEY ("roles_id") REFERENCES "roles"("id"),CONSTRAINT "fk_role_permissions_permissions" FOREIGN KEY ("permissions_id") REFERENCES "permissions"("id"));
+ALTER TABLE "role_permissions" ADD COLUMN IF NOT EXISTS "roles_id" bigint;
+ALTER TABLE "role_permissions" ADD COLUMN IF NOT EXISTS "permissions_id" bigint;
+DO $$ BEGIN ALTER TABLE "role_permissions" ADD CONSTRAINT "fk_role_permissions_roles" FOREIGN KEY ("roles_id") REFERENCES "roles"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "role_permissions" ADD CONSTRAINT "fk_role_permissions_permissions" FOREIGN KEY ("permissions_id") REFERENCES "permissions"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE TABLE IF NOT EXISTS "user_roles" ("users_id" bigint,"roles_id" bigint,PRIMARY KEY ("users_id","roles_id"),CONSTRAINT "fk_user_roles_users" FOREIGN KEY ("users_id") REFERENCES "users"("id"),CONSTRAINT "fk_user_roles_roles" FOREIGN KEY ("roles_id") REFERENCES "roles"("id"));
+ALTER TABLE "user_roles" ADD COLUMN IF NOT EXISTS "users_id" bigint;
+ALTER TABLE "user_roles" ADD COLUMN IF NOT EXISTS "roles_id" bigint;
+DO $$ BEGIN ALTER TABLE "user_roles" ADD CONSTRAINT "fk_user_roles_users" FOREIGN KEY ("users_id") REFERENCES "users"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "user_roles" ADD CONSTRAINT "fk_user_roles_roles" FOREIGN KEY ("roles_id") REFERENCES "roles"("id"); EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE TABLE IF NOT EXISTS "remember_tokens" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"users_id" bigint,"series" text,"token_hash" text,"user_agent" text,"expires_at" timestamptz,"last_used_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_remember_tokens_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE);
+ALTER TABLE "remember_tokens" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "remember_tokens" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "remember_tokens" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "remember_tokens" ADD COLUMN IF NOT EXISTS "users_id" bigint;
+ALTER TABLE "remember_tokens" ADD COLUMN IF NOT EXISTS "series" text;
+ALTER TABLE "remember_tokens" ADD COLUMN IF NOT EXISTS "token_hash" text;
+ALTER TABLE "remember_tokens" ADD COLUMN IF NOT EXISTS "user_agent" text;
+ALTER TABLE "remember_tokens" ADD COLUMN IF NOT EXISTS "expires_at" timestamptz;
+ALTER TABLE "remember_tokens" ADD COLUMN IF NOT EXISTS "last_used_at" timestamptz;
+DO $$ BEGIN ALTER TABLE "remember_tokens" ADD CONSTRAINT "fk_remember_tokens_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_remember_tokens_series" ON "remember_tokens" ("series");
+CREATE INDEX IF NOT EXISTS "idx_remember_tokens_deleted_at" ON "remember_tokens" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "sessions" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"users_id" bigint,"token_hash" text,"roles" text,"user_agent" text,"ip" text,"expires_at" timestamptz,"last_used_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_sessions_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE);
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "users_id" bigint;
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "token_hash" text;
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "roles" text;
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "user_agent" text;
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "ip" text;
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "expires_at" timestamptz;
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "last_used_at" timestamptz;
+DO $$ BEGIN ALTER TABLE "sessions" ADD CONSTRAINT "fk_sessions_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_sessions_users_id" ON "sessions" ("users_id");
+CREATE INDEX IF NOT EXISTS "idx_sessions_deleted_at" ON "sessions" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sessions_token_hash" ON "sessions" ("token_hash");
+CREATE TABLE IF NOT EXISTS "user_devices" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"users_id" bigint,"fingerprint" text,"user_agent" text,"ip" text,"last_seen_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_user_devices_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE);
+ALTER TABLE "user_devices" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "user_devices" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "user_devices" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "user_devices" ADD COLUMN IF NOT EXISTS "users_id" bigint;
+ALTER TABLE "user_devices" ADD COLUMN IF NOT EXISTS "fingerprint" text;
+ALTER TABLE "user_devices" ADD COLUMN IF NOT EXISTS "user_agent" text;
+ALTER TABLE "user_devices" ADD COLUMN IF NOT EXISTS "ip" text;
+ALTER TABLE "user_devices" ADD COLUMN IF NOT EXISTS "last_seen_at" timestamptz;
+DO $$ BEGIN ALTER TABLE "user_devices" ADD CONSTRAINT "fk_user_devices_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_user_devices_fingerprint" ON "user_devices" ("fingerprint");
+CREATE INDEX IF NOT EXISTS "idx_user_devices_deleted_at" ON "user_devices" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "notifications" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"users_id" bigint,"category" varchar(32),"title" text,"body" text,"link" text,"read_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_notifications_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE);
+ALTER TABLE "notifications" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "notifications" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "notifications" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "notifications" ADD COLUMN IF NOT EXISTS "users_id" bigint;
+ALTER TABLE "notifications" ADD COLUMN IF NOT EXISTS "category" varchar(32);
+ALTER TABLE "notifications" ADD COLUMN IF NOT EXISTS "title" text;
+ALTER TABLE "notifications" ADD COLUMN IF NOT EXISTS "body" text;
+ALTER TABLE "notifications" ADD COLUMN IF NOT EXISTS "link" text;
+ALTER TABLE "notifications" ADD COLUMN IF NOT EXISTS "read_at" timestamptz;
+DO $$ BEGIN ALTER TABLE "notifications" ADD CONSTRAINT "fk_notifications_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_notifications_users_id" ON "notifications" ("users_id");
+CREATE INDEX IF NOT EXISTS "idx_notifications_deleted_at" ON "notifications" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_notifications_category" ON "notifications" ("category");
+CREATE TABLE IF NOT EXISTS "notification_mutes" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"users_id" bigint,"category" varchar(32),PRIMARY KEY ("id"));
+ALTER TABLE "notification_mutes" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "notification_mutes" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "notification_mutes" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "notification_mutes" ADD COLUMN IF NOT EXISTS "users_id" bigint;
+ALTER TABLE "notification_mutes" ADD COLUMN IF NOT EXISTS "category" varchar(32);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_notification_mute" ON "notification_mutes" ("users_id","category");
+CREATE INDEX IF NOT EXISTS "idx_notification_mutes_deleted_at" ON "notification_mutes" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "tasks" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"users_id" bigint,"kind" varchar(32),"title" text,"state" varchar(16),"done" bigint,"total" bigint,"file_id" bigint,"error" text,"finished_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_tasks_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE,CONSTRAINT "fk_tasks_file" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "users_id" bigint;
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "kind" varchar(32);
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "title" text;
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "state" varchar(16);
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "done" bigint;
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "total" bigint;
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "file_id" bigint;
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "error" text;
+ALTER TABLE "tasks" ADD COLUMN IF NOT EXISTS "finished_at" timestamptz;
+DO $$ BEGIN ALTER TABLE "tasks" ADD CONSTRAINT "fk_tasks_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+DO $$ BEGIN ALTER TABLE "tasks" ADD CONSTRAINT "fk_tasks_file" FOREIGN KEY ("file_id") REFERENCES "files"("id") ON DELETE SET NULL ON UPDATE CASCADE; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+CREATE INDEX IF NOT EXISTS "idx_tasks_users_id" ON "tasks" ("users_id");
+CREATE INDEX IF NOT EXISTS "idx_tasks_deleted_at" ON "tasks" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_tasks_state" ON "tasks" ("state");
+CREATE TABLE IF NOT EXISTS "mails" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"subject" text,"body" text,"from" text,"to" text,"cc" text,"bcc" text,"status" text,"message_id" text,"error" text,"status_at" timestamptz,PRIMARY KEY ("id"));
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "subject" text;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "body" text;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "from" text;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "to" text;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "cc" text;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "bcc" text;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "status" text;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "message_id" text;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "error" text;
+ALTER TABLE "mails" ADD COLUMN IF NOT EXISTS "status_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_mails_message_id" ON "mails" ("message_id");
+CREATE INDEX IF NOT EXISTS "idx_mails_status" ON "mails" ("status");
+CREATE INDEX IF NOT EXISTS "idx_mails_to" ON "mails" ("to");
+CREATE INDEX IF NOT EXISTS "idx_mails_deleted_at" ON "mails" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "mail_suppressions" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"email" text,"reason" text,"detail" text,PRIMARY KEY ("id"));
+ALTER TABLE "mail_suppressions" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "mail_suppressions" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "mail_suppressions" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "mail_suppressions" ADD COLUMN IF NOT EXISTS "email" text;
+ALTER TABLE "mail_suppressions" ADD COLUMN IF NOT EXISTS "reason" text;
+ALTER TABLE "mail_suppressions" ADD COLUMN IF NOT EXISTS "detail" text;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_mail_suppressions_email" ON "mail_suppressions" ("email");
+CREATE INDEX IF NOT EXISTS "idx_mail_suppressions_deleted_at" ON "mail_suppressions" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "subscribes" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"email" text,PRIMARY KEY ("id"));
+ALTER TABLE "subscribes" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "subscribes" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "subscribes" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "subscribes" ADD COLUMN IF NOT EXISTS "email" text;
+CREATE INDEX IF NOT EXISTS "idx_subscribes_deleted_at" ON "subscribes" ("deleted_at");
@@ -0,0 +1,5 @@
+-- Daily feature usage counts, see package telemetry.
+
+CREATE TABLE "feature_uses" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"kind" varchar(16),"name" varchar(160),"day" date,"count" bigint,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_feature_uses_day" ON "feature_uses" ("kind","name","day");
+CREATE INDEX IF NOT EXISTS "idx_feature_uses_deleted_at" ON "feature_uses" ("deleted_at");
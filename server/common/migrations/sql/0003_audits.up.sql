@@ -0,0 +1,6 @@
+-- Who created, changed and deleted the audited rows, see package audit.
+
+CREATE TABLE "audits" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"entity" varchar(64),"entity_id" bigint,"action" varchar(16),"users_id" bigint,"ip" varchar(64),"user_agent" varchar(512),"request_id" varchar(64),"changes" text,PRIMARY KEY ("id"),CONSTRAINT "fk_audits_users" FOREIGN KEY ("users_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_audits_deleted_at" ON "audits" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_audits_entity" ON "audits" ("entity","entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audits_users_id" ON "audits" ("users_id");
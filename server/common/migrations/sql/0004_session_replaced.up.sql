@@ -0,0 +1,3 @@
+-- Replaced sessions stay valid for a grace period, see auth.StartSession.
+
+ALTER TABLE "sessions" ADD COLUMN IF NOT EXISTS "replaced_at" timestamptz;
@@ -0,0 +1,5 @@
+-- The admins a digest was mailed to, a failed run resumes with the others, see digest.Send.
+
+CREATE TABLE "digest_deliveries" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"digests_id" bigint,"users_id" bigint,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_digest_delivery" ON "digest_deliveries" ("digests_id","users_id");
+CREATE INDEX IF NOT EXISTS "idx_digest_deliveries_deleted_at" ON "digest_deliveries" ("deleted_at");
@@ -0,0 +1,4 @@
+-- Whether a video's timeline sprite and track are stored, uploader.Uploaded lists them only then, see transcoder.Sprites.
+
+ALTER TABLE "files" ADD COLUMN IF NOT EXISTS "sprite" boolean;
+UPDATE "files" SET "sprite" = true WHERE "transcoding" = 'ready';
@@ -0,0 +1,201 @@
+// Package module lets features plug into the application without touching server.Run.
+//
+// A feature implements Module and is listed in server.Modules, on boot its Register receives the echo app and
+// a Container through which it contributes everything else: admin routes, database models to migrate,
+// admin sidebar entries, dashboard widgets, periodic jobs (failures are recorded in Job_failures) and event listeners.
+//
+//   type Module struct{}
+//
+//   func (Module) Name() string { return "packager" }
+//
+//   func (Module) Register(app *echo.Echo, container *module.Container) {
+//      container.Admin.GET("/package", controller.Register(index))
+//      container.Migrate(&model.Packages{})
+//      container.Widget(view.DashboardWidget{ Name: "packages", Render: widget })
+//      container.AdminRoute(view.AdminRoute{ Path: "/package", Name: "კონტენტი", Slug: "package", Icon: view.SettingsIcon() })
+//      container.Cron("cleanup", time.Hour, cleanup)
+//      container.On("news.published", notify)
+//   }
+//
+// Register must only declare things, jobs are started by Container.Start, so modules can also be registered
+// by tools which only need their models (cmd/migrate).
+package module
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"main/build/view"
+	"main/server/common/domain"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+type Module interface {
+	Name() string
+	Register(app *echo.Echo, container *Container)
+}
+
+var ErrUnknownJob = domain.NotFound("job not found")
+
+// Listener handles an event emitted by Container.Emit, the payload type is up to the event.
+type Listener func(ctx context.Context, payload any) error
+
+type job struct {
+	name		string
+	every		time.Duration
+	run			func(ctx context.Context) error
+}
+
+type Container struct {
+	DB			*gorm.DB
+	Admin		*echo.Group
+	Internal	*echo.Group
+
+	mu			sync.RWMutex
+	models		[]any
+	jobs		[]job
+	listeners	map[string][]Listener
+	owners		map[string]string		/* "GET /admin/kiosks" to the module which registered it, written by Boot only */
+	names		[]string
+	widgets		[]view.DashboardWidget
+	routes		[]view.AdminRoute
+}
+
+// Boot registers the modules, admin is the authenticated admin route group and internal the group of the
+// endpoints other services call, which only takes signed requests (see package signature). Their dashboard widgets
+// and admin sidebar entries are added to the views', their models registered (see model.Register).
+func Boot(app *echo.Echo, admin *echo.Group, internal *echo.Group, modules ...Module) *Container {
+	container := declare(app, admin, internal, modules...)
+	view.DashboardWidgets = append(view.DashboardWidgets, container.widgets...)
+	view.AdminRoutes = append(view.AdminRoutes, container.routes...)
+	model.Register(container.models...)
+	for _, Name := range container.names { log.Print("Module registered: ", Name) }
+	return container
+}
+
+/* The modules' declarations, kept by the container alone */
+func declare(app *echo.Echo, admin *echo.Group, internal *echo.Group, modules ...Module) *Container {
+	container := &Container{ DB: storage.DB, Admin: admin, Internal: internal, listeners: map[string][]Listener{}, owners: map[string]string{} }
+
+	/* Echo keeps its routes in a map, the module's are the ones which weren't there before it registered */
+	Registered := map[string]bool{}
+	for _, Route := range app.Routes() { Registered[Route.Method + " " + Route.Path] = true }
+
+	for _, module := range modules {
+		module.Register(app, container)
+		for _, Route := range app.Routes() {
+			Key := Route.Method + " " + Route.Path
+			if !Registered[Key] { container.owners[Key] = module.Name() }
+			Registered[Key] = true
+		}
+		container.names = append(container.names, module.Name())
+	}
+	return container
+}
+
+// Names are the names of the modules registered, in order.
+func (container *Container) Names() []string {
+	return container.names
+}
+
+// Owner is the name of the module which registered a route, by its method and path pattern (ctx.Path()).
+// Routes no module registered are the app's own, "core".
+func (container *Container) Owner(Method string, Path string) string {
+	if Name, ok := container.owners[Method + " " + Path]; ok { return Name }
+	return "core"
+}
+
+// Models returns the models the modules want migrated, without starting anything nor touching the views.
+func Models(modules ...Module) []any {
+	app := echo.New()
+	return declare(app, app.Group("admin"), app.Group("internal"), modules...).models
+}
+
+// Migrate declares the module's models along with model.Models: their tables are made by migrations (see
+// package migrations), cmd/generate and cmd/doctor check them against the database. Boot registers them too (see
+// model.Register), so the rows pointing to a file or a user are found in the modules' tables as well.
+func (container *Container) Migrate(models ...any) {
+	container.models = append(container.models, models...)
+}
+
+// Models returns the models the modules declared with Migrate.
+func (container *Container) Models() []any {
+	return container.models
+}
+
+// Widget adds a widget to the admin dashboard, once the modules are booted.
+func (container *Container) Widget(widget view.DashboardWidget) {
+	container.widgets = append(container.widgets, widget)
+}
+
+// AdminRoute adds an entry to the admin sidebar, once the modules are booted.
+func (container *Container) AdminRoute(route view.AdminRoute) {
+	container.routes = append(container.routes, route)
+}
+
+// Cron declares a job which runs every interval once Start is called.
+func (container *Container) Cron(name string, every time.Duration, run func(ctx context.Context) error) {
+	container.jobs = append(container.jobs, job{ name: name, every: every, run: run })
+}
+
+// On subscribes a listener to an event.
+func (container *Container) On(event string, listener Listener) {
+	container.mu.Lock()
+	defer container.mu.Unlock()
+	container.listeners[event] = append(container.listeners[event], listener)
+}
+
+// Emit calls the event's listeners in the order they subscribed.
+// A failing listener is logged and doesn't stop the others, the emitter isn't affected by its listeners.
+func (container *Container) Emit(ctx context.Context, event string, payload any) {
+	container.mu.RLock()
+	listeners := container.listeners[event]
+	container.mu.RUnlock()
+
+	for _, listener := range listeners {
+		if err := listener(ctx, payload); err != nil { log.Print("Event ", event, " listener: ", err) }
+	}
+}
+
+// Start runs the declared jobs in the background until ctx is done.
+func (container *Container) Start(ctx context.Context) {
+	for _, Job := range container.jobs {
+		go func(scheduled job) {
+			ticker := time.NewTicker(scheduled.every)
+			defer ticker.Stop()
+
+			for {
+				select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						scheduled.execute(ctx)
+				}
+			}
+		}(Job)
+	}
+}
+
+// Run runs a declared job now, besides its schedule, for the services which trigger jobs (POST /internal/jobs/:name).
+// Its failure is recorded as a scheduled one is, and returned.
+func (container *Container) Run(ctx context.Context, name string) error {
+	for _, job := range container.jobs {
+		if job.name == name { return job.execute(ctx) }
+	}
+	return ErrUnknownJob
+}
+
+func (job job) execute(ctx context.Context) error {
+	err := job.run(ctx)
+	if err != nil {
+		log.Print("Job ", job.name, ": ", err)
+		storage.DB.Create(&model.Job_failures{ Job: job.name, Error: err.Error() })
+	}
+	return err
+}
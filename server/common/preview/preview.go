@@ -0,0 +1,22 @@
+// Package preview carries the active preview channel on the request context,
+// so templ components can tell a preview render from a live one.
+package preview
+
+import "context"
+
+const Cookie = "preview"
+
+// LinkCookie holds the token of a preview link (previewer.Share), the way people without an account preview a page.
+const LinkCookie = "preview-link"
+
+type channelKey struct{}
+
+func WithChannel(parent context.Context, Name string) context.Context {
+	return context.WithValue(parent, channelKey{}, Name)
+}
+
+// Channel returns the name of the previewed channel, "" when the request is not a preview.
+func Channel(ctx context.Context) string {
+	Name, _ := ctx.Value(channelKey{}).(string)
+	return Name
+}
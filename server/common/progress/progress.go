@@ -0,0 +1,152 @@
+// Package progress counts the bytes of uploads as they're received, so the page sending one can show how far it got
+// (GET /upload/progress/:token, as json or as server-sent events).
+//
+// A form upload is tracked under the token the client sends in its "progress" query parameter, a resumable one
+// under its own token, the total being its whole length and not the chunk's:
+//
+//   Tracker := progress.Start(Token, ctx.Request().ContentLength, 0)
+//   ctx.Request().Body = Tracker.Body(ctx.Request().Body)
+//   ...
+//   Tracker.Finish(Upload.Code)
+//
+// Notes:
+//   - Progress is kept in the memory of the instance receiving the upload, the progress route must reach the same one.
+package progress
+
+import (
+	"io"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Expiry is how long an upload's progress is kept after it last changed.
+const Expiry = 10 * time.Minute
+
+// Stages of an upload: its bytes are received, then it's processed (hashed, checked, scanned, stored).
+const (
+	StageReceiving	= "receiving"
+	StageProcessing	= "processing"
+	StageDone		= "done"
+	StageFailed		= "failed"
+)
+
+// Progress is the state of an upload, Total is 0 when the client didn't tell its length.
+type Progress struct {
+	Token		string		`json:"token"`
+	Stage		string		`json:"stage"`
+	Received	int64		`json:"received"`
+	Total		int64		`json:"total"`
+	Percent		float64		`json:"percent"`
+	Code		string		`json:"code,omitempty"`		/* why it failed, an UploadResponse code */
+}
+
+// Tracker tracks an upload, a nil one (see Start) does nothing.
+type Tracker struct {
+	token		string
+	total		int64
+	received	atomic.Int64
+	mu			sync.Mutex
+	stage		string
+	code		string
+	updated		time.Time
+}
+
+var trackers sync.Map
+
+var valid = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+// Valid reports whether a token may name an upload, 8 to 64 letters, digits, "-" and "_".
+func Valid(Token string) bool {
+	return valid.MatchString(Token)
+}
+
+// Start tracks the upload of the token, Received bytes of its Total are already there (a resumed upload).
+// An invalid token tracks nothing, it returns nil.
+func Start(Token string, Total int64, Received int64) *Tracker {
+	if !Valid(Token) { return nil }
+	expire()
+
+	Tracker := &Tracker{ token: Token, total: Total, stage: StageReceiving, updated: time.Now() }
+	Tracker.received.Store(Received)
+	trackers.Store(Token, Tracker)
+	return Tracker
+}
+
+// Get returns the progress of the upload of the token.
+func Get(Token string) (Progress, bool) {
+	Value, ok := trackers.Load(Token)
+	if !ok { return Progress{}, false }
+	return Value.(*Tracker).Progress(), true
+}
+
+// Progress returns the upload's state.
+func (Tracker *Tracker) Progress() Progress {
+	Tracker.mu.Lock()
+	defer Tracker.mu.Unlock()
+
+	Progress := Progress{ Token: Tracker.token, Stage: Tracker.stage, Received: Tracker.received.Load(), Total: Tracker.total, Code: Tracker.code }
+	if Progress.Total > 0 { Progress.Percent = min(100, float64(Progress.Received) * 100 / float64(Progress.Total)) }
+	return Progress
+}
+
+// Body counts the bytes read through the body.
+func (Tracker *Tracker) Body(Body io.ReadCloser) io.ReadCloser {
+	if Tracker == nil { return Body }
+	return &counted{ ReadCloser: Body, tracker: Tracker }
+}
+
+// Processing tells the upload is received whole and being processed.
+func (Tracker *Tracker) Processing() {
+	Tracker.set(StageProcessing, "")
+}
+
+// Finish ends the upload, done when Code is "", failed with that code otherwise.
+func (Tracker *Tracker) Finish(Code string) {
+	if Code != "" {
+		Tracker.set(StageFailed, Code)
+		return
+	}
+	Tracker.set(StageDone, "")
+}
+
+func (Tracker *Tracker) set(Stage string, Code string) {
+	if Tracker == nil { return }
+	Tracker.mu.Lock()
+	Tracker.stage, Tracker.code, Tracker.updated = Stage, Code, time.Now()
+	Tracker.mu.Unlock()
+}
+
+// Finished reports whether the progress won't change any more.
+func (Progress Progress) Finished() bool {
+	return Progress.Stage == StageDone || Progress.Stage == StageFailed
+}
+
+type counted struct {
+	io.ReadCloser
+	tracker		*Tracker
+}
+
+func (body *counted) Read(data []byte) (int, error) {
+	n, err := body.ReadCloser.Read(data)
+	if n > 0 {
+		body.tracker.received.Add(int64(n))
+		body.tracker.mu.Lock()
+		body.tracker.updated = time.Now()
+		body.tracker.mu.Unlock()
+	}
+	return n, err
+}
+
+/* Uploads are few next to the bytes they send, sweeping them all on every start is cheap */
+func expire() {
+	trackers.Range(func(Token, Value any) bool {
+		Tracker := Value.(*Tracker)
+		Tracker.mu.Lock()
+		Stale := time.Since(Tracker.updated) > Expiry
+		Tracker.mu.Unlock()
+		if Stale { trackers.Delete(Token) }
+		return true
+	})
+}
@@ -0,0 +1,44 @@
+// Package repository holds typed data access for the models, so handlers and services don't
+// spell table and column names by hand.
+//
+// Repository is the generic part, the per-model repositories (repositories.gen.go) embed it and add
+// a FindBy<Field> lookup for every unique column. They're generated by cmd/generate, don't edit them:
+//
+//   make generate
+//
+// Example usage:
+//   Role, err := repository.Roles.FindByName(ctx.Request().Context(), "admin")
+//   if err != nil { return err }
+//
+//   /* Within a handler's transaction */
+//   err := ctx.Tx(func(tx *gorm.DB) error {
+//       return repository.Files.With(tx).Create(ctx.Request().Context(), &File)
+//   })
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"main/server/common/storage"
+)
+
+// ErrNotFound is returned by the lookups of a single row which doesn't exist.
+var ErrNotFound = storage.ErrNotFound
+
+// Repository is the data access of one model, on storage.DB unless bound to a transaction with With. It's a
+// storage.Repo, errors are translated into storage.ErrNotFound and storage.ErrConflict the same way.
+type Repository[T any] struct {
+	storage.Repo[T]
+}
+
+// With returns the repository working on the given connection, usually a transaction.
+func (repository Repository[T]) With(db *gorm.DB) Repository[T] {
+	return Repository[T]{ repository.Repo.With(db) }
+}
+
+// FindByPublicID returns the row of a public identifier, for models embedding model.External.
+func (repository Repository[T]) FindByPublicID(ctx context.Context, ID string) (T, error) {
+	return repository.FindBy(ctx, "public_id", ID)
+}
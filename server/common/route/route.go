@@ -0,0 +1,93 @@
+// Package route keeps the paths of named routes, so urls are generated from a route's name instead of hard-coded.
+//
+// Routes are named when they're registered with controller.RegisterNamed:
+//
+//   app.GET("/news/:ID", controller.RegisterNamed("news.detail", detail))
+//
+// and their urls are generated by ctx.URLFor in controllers, or route.URL in templ components:
+//
+//   <div hx-get={ route.URL("news.detail", News.ID) } hx-push-url={ route.URL("news.detail", News.ID) }>
+//
+// It's a package of its own because templ components can't import the controller.
+package route
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	mu sync.RWMutex
+	paths = map[string]string{}
+	pending string
+)
+
+// Setup makes the app record the path of every route registered right after Name, call it before registering routes.
+/* echo calls OnAddRouteHandler synchronously from app.GET(...), right after RegisterNamed ran as its argument */
+func Setup(app *echo.Echo) {
+	app.OnAddRouteHandler = func(host string, added echo.Route, handler echo.HandlerFunc, middleware []echo.MiddlewareFunc) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if pending == "" { return }
+		if existing, found := paths[pending]; found && existing != added.Path {
+			log.Print("Route name ", pending, " is used by ", existing, " and ", added.Path)
+		}
+		paths[pending] = added.Path
+		pending = ""
+	}
+}
+
+// Name names the next registered route.
+func Name(name string) {
+	mu.Lock()
+	pending = name
+	mu.Unlock()
+}
+
+// Path returns the path of the named route as registered, e.g. "/news/:ID".
+func Path(name string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	path, found := paths[name]
+	return path, found
+}
+
+// NameOf returns the name of the route registered with the path ("/news/:ID", what echo's Context.Path gives),
+// false for routes without one.
+func NameOf(path string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for name, registered := range paths {
+		if registered == path { return name, true }
+	}
+	return "", false
+}
+
+// URL returns the url of the named route, its ":param" and "*" segments are replaced by params in order (escaped).
+// Unknown names are logged and give "/", a broken link is better than a broken page.
+//
+// Example usage:
+//   route.URL("news.detail", 12) // "/news/12"
+func URL(name string, params ...any) string {
+	path, found := Path(name)
+	if !found {
+		log.Print("Unknown route name: ", name)
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") && segment != "*" { continue }
+		if len(params) == 0 { break }
+
+		segments[i] = url.PathEscape(fmt.Sprint(params[0]))
+		params = params[1:]
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,117 @@
+// Package saga runs operations spanning several systems (database, blob store, external APIs) which can't share
+// a transaction: each step comes with the compensation undoing it, when a step fails for good the steps done
+// before it are compensated in reverse order.
+//
+//   err := saga.New("file.delete").
+//      Step("row", hide, restore).
+//      Step("blob", removeBlob, nil).
+//      Step("purge", purge, nil).
+//      Run(ctx)
+//
+// Steps and compensations are retried with a growing delay before they're given up on. A step without
+// a compensation (nil) can't be undone, put the irreversible ones last.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// Attempts is how many times a step or a compensation is tried by default.
+	Attempts = 3
+	// Backoff is the delay before the second attempt, it doubles for every following one.
+	Backoff = 200 * time.Millisecond
+)
+
+// ErrCompensation wraps the errors of compensations which failed, the operation is left half done.
+var ErrCompensation = errors.New("saga compensation failed")
+
+// Permanent marks an error retrying can't fix (e.g. a missing record), the step fails without more attempts.
+func Permanent(err error) error {
+	if err == nil { return nil }
+	return permanent{ err }
+}
+
+type permanent struct{ error }
+
+func (err permanent) Unwrap() error { return err.error }
+
+type step struct {
+	name			string
+	run				func(ctx context.Context) error
+	compensate		func(ctx context.Context) error
+}
+
+type Saga struct {
+	Name			string
+	Attempts		int
+	Backoff			time.Duration
+	steps			[]step
+}
+
+// New starts a saga, Name is used in errors and logs.
+func New(Name string) *Saga {
+	return &Saga{ Name: Name, Attempts: Attempts, Backoff: Backoff }
+}
+
+// Step adds a step, compensate undoes it (nil when it can't be undone).
+func (saga *Saga) Step(name string, run func(ctx context.Context) error, compensate func(ctx context.Context) error) *Saga {
+	saga.steps = append(saga.steps, step{ name: name, run: run, compensate: compensate })
+	return saga
+}
+
+// Run runs the steps in order. When one fails the ones done before it are compensated, last done first.
+//
+// Returns:
+//   - nil when every step succeeded.
+//   - The failed step's error, wrapped with the saga's and the step's name.
+//   - That error joined with ErrCompensation and the compensations' errors when some couldn't be undone.
+func (saga *Saga) Run(ctx context.Context) error {
+	for i, step := range saga.steps {
+		err := saga.retry(ctx, step.run)
+		if err == nil { continue }
+
+		err = fmt.Errorf("%s: %s: %w", saga.Name, step.name, err)
+		return errors.Join(err, saga.compensate(ctx, saga.steps[:i]))
+	}
+	return nil
+}
+
+/* Compensations run even when ctx is done, a cancelled request mustn't leave the operation half done */
+func (saga *Saga) compensate(ctx context.Context, done []step) error {
+	ctx = context.WithoutCancel(ctx)
+
+	var failed []error
+	for i := len(done) - 1; i >= 0; i-- {
+		if done[i].compensate == nil { continue }
+
+		if err := saga.retry(ctx, done[i].compensate); err != nil {
+			log.Print("Saga ", saga.Name, " compensating ", done[i].name, ": ", err)
+			failed = append(failed, fmt.Errorf("%s: %w", done[i].name, err))
+		}
+	}
+
+	if len(failed) == 0 { return nil }
+	return fmt.Errorf("%w: %w", ErrCompensation, errors.Join(failed...))
+}
+
+func (saga *Saga) retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	delay := saga.Backoff
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil || attempt >= saga.Attempts { return err }
+		if errors.As(err, &permanent{}) { return err }
+
+		select {
+			case <-ctx.Done():
+				return errors.Join(err, ctx.Err())
+			case <-time.After(delay):
+				delay *= 2
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package search
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MinStem is the fewest characters stemming leaves of a word, shorter words are kept whole.
+const MinStem = 3
+
+// Analyzer turns the words of a language into search terms: it drops its stop-words, cuts the words to their
+// stem and adds their synonyms. Terms are matched as substrings, a stem finds every inflected form it begins.
+type Analyzer struct {
+	Locale		string
+	Stemming	bool
+	stopwords	map[string]bool
+	synonyms	map[string][]string		/* a stem to the stems of its group, itself included */
+}
+
+// Term is a word of a query, with the alternatives it's found by: its stem first, then its synonyms'.
+type Term []string
+
+var (
+	mutex sync.RWMutex
+	analyzers = map[string]*Analyzer{}
+)
+
+// NewAnalyzer builds the analyzer of a locale, Synonyms are groups of words meaning the same ("paint, emulsion").
+func NewAnalyzer(Locale string, Stemming bool, Stopwords []string, Synonyms [][]string) *Analyzer {
+	Analyzer := &Analyzer{ Locale: Locale, Stemming: Stemming, stopwords: map[string]bool{}, synonyms: map[string][]string{} }
+	for _, Word := range Stopwords {
+		if Word = strings.ToLower(strings.TrimSpace(Word)); Word != "" { Analyzer.stopwords[Word] = true }
+	}
+
+	for _, Group := range Synonyms {
+		Stems := []string{}
+		for _, Word := range Group {
+			if Word = strings.ToLower(strings.TrimSpace(Word)); Word != "" { Stems = append(Stems, Analyzer.Stem(Word)) }
+		}
+		for _, Stem := range Stems { Analyzer.synonyms[Stem] = appendNew(Analyzer.synonyms[Stem], Stems...) }
+	}
+	return Analyzer
+}
+
+// Configure makes the analyzer the one of its locale, replacing the built-in one (Default).
+func Configure(Analyzer *Analyzer) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	analyzers[Analyzer.Locale] = Analyzer
+}
+
+// For returns the analyzer of the locale, the configured one or else the built-in one.
+func For(Locale string) *Analyzer {
+	mutex.RLock()
+	Analyzer, ok := analyzers[Locale]
+	mutex.RUnlock()
+	if ok { return Analyzer }
+	return Default(Locale)
+}
+
+// Default is the built-in analyzer of the locale: stemming and its usual stop-words, no synonyms.
+func Default(Locale string) *Analyzer {
+	return NewAnalyzer(Locale, true, Stopwords[Locale], nil)
+}
+
+// Language tells the locale of a word by its script, Georgian letters make it "ka", anything else "en".
+func Language(Word string) string {
+	for _, Rune := range Word {
+		if unicode.Is(unicode.Georgian, Rune) { return "ka" }
+	}
+	return "en"
+}
+
+// Parse splits a query into its terms, each word analyzed by the analyzer of its language. Stop-words are
+// dropped, unless the query is nothing else. At most MaxTerms are kept. A word the vocabulary (UseIndex)
+// doesn't know is searched for as the nearest word it does too, so a typo still finds something.
+func Parse(Query string) []Term {
+	Words := Words(Query)
+	Index := vocabulary.Load()
+	Terms := []Term{}
+	Seen := map[string]bool{}
+	for _, Word := range Words {
+		Analyzer := For(Language(Word))
+		if Analyzer.stopwords[Word] && !allStopwords(Words) { continue }
+
+		Stem := Analyzer.Stem(Word)
+		if Seen[Stem] || len(Terms) == MaxTerms { continue }
+		Seen[Stem] = true
+		Term := append(Term{ Stem }, without(Analyzer.synonyms[Stem], Stem)...)
+		if Index != nil {
+			if Nearest, ok := correction(Index, Word); ok { Term = appendNew(Term, Analyzer.Stem(Nearest)) }
+		}
+		Terms = append(Terms, Term)
+	}
+	return Terms
+}
+
+// Words splits a text into its lowercased words, punctuation separates them.
+func Words(Text string) []string {
+	return strings.FieldsFunc(strings.ToLower(Text), func(Rune rune) bool {
+		return !unicode.IsLetter(Rune) && !unicode.IsDigit(Rune) && !unicode.IsMark(Rune)
+	})
+}
+
+// Stem cuts the word's suffix when the analyzer stems.
+func (Analyzer *Analyzer) Stem(Word string) string {
+	if !Analyzer.Stemming { return Word }
+	return stemOf(Word)
+}
+
+/* Georgian declension and plural suffixes, English plural and verb endings, the longest first */
+var suffixes = map[string][]string{
+	"ka": { "ებისთვის", "ებიდან", "ისთვის", "ებთან", "ისგან", "ების", "ებით", "ებში", "ებზე", "ებად", "იდან", "ები", "ებს", "თან", "ის", "ით", "ად", "ში", "ზე", "მა", "ს", "ი" },
+	"en": { "ies", "ing", "es", "ed", "s", "y" },
+}
+
+func stemOf(Word string) string {
+	for _, Suffix := range suffixes[Language(Word)] {
+		if !strings.HasSuffix(Word, Suffix) { continue }
+		if Stem := strings.TrimSuffix(Word, Suffix); utf8.RuneCountInString(Stem) >= MinStem { return Stem }
+	}
+	return Word
+}
+
+func allStopwords(Words []string) bool {
+	for _, Word := range Words {
+		if !For(Language(Word)).stopwords[Word] { return false }
+	}
+	return true
+}
+
+func appendNew(List []string, Items ...string) []string {
+	for _, Item := range Items {
+		if !contains(List, Item) { List = append(List, Item) }
+	}
+	return List
+}
+
+func without(List []string, Item string) []string {
+	Rest := []string{}
+	for _, Other := range List {
+		if Other != Item { Rest = append(Rest, Other) }
+	}
+	return Rest
+}
+
+func contains(List []string, Item string) bool {
+	for _, Other := range List {
+		if Other == Item { return true }
+	}
+	return false
+}
+
+// Stopwords are the built-in stop-words of each locale.
+var Stopwords = map[string][]string{
+	"ka": {
+		"და", "ან", "თუ", "რომ", "არის", "იყო", "ეს", "ის", "ამ", "იმ", "ეგ", "მისი", "მათი", "ჩვენი",
+		"როგორც", "ასევე", "მაგრამ", "ხოლო", "კი", "არ", "ვერ", "ნუ", "რა", "ვინ", "სადაც", "როცა",
+		"ერთი", "ყველა", "უფრო", "ძალიან", "მერე", "შემდეგ", "თან", "მიერ", "გარდა", "შესახებ",
+	},
+	"en": {
+		"a", "an", "the", "and", "or", "but", "if", "of", "to", "in", "on", "at", "by", "for", "with",
+		"from", "as", "is", "are", "was", "were", "be", "it", "its", "this", "that", "these", "those",
+	},
+}
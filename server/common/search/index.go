@@ -0,0 +1,137 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// MinCorrected is the length a word needs to be corrected, shorter ones have too many neighbours.
+const MinCorrected = 4
+
+// Index is the vocabulary of the searched content, the words a misspelled one is corrected to. Candidates
+// share a trigram with the word, the nearest by edit distance wins, the most frequent among equals.
+type Index struct {
+	words		map[string]int			/* word to its count */
+	sorted		[]string
+	grams		map[string][]string
+}
+
+var vocabulary atomic.Pointer[Index]
+
+// NewIndex indexes the words of the texts.
+func NewIndex(Texts ...string) *Index {
+	Index := &Index{ words: map[string]int{}, grams: map[string][]string{} }
+	for _, Text := range Texts {
+		for _, Word := range Words(Text) { Index.words[Word]++ }
+	}
+
+	for Word := range Index.words {
+		Index.sorted = append(Index.sorted, Word)
+		for _, Gram := range trigrams(Word) { Index.grams[Gram] = append(Index.grams[Gram], Word) }
+	}
+	sort.Strings(Index.sorted)
+	return Index
+}
+
+// UseIndex makes the index the vocabulary Parse and Suggest correct words with.
+func UseIndex(Index *Index) {
+	vocabulary.Store(Index)
+}
+
+// Known reports whether a word of the vocabulary begins with the stem.
+func (Index *Index) Known(Stem string) bool {
+	i := sort.SearchStrings(Index.sorted, Stem)
+	return i < len(Index.sorted) && strings.HasPrefix(Index.sorted[i], Stem)
+}
+
+// Nearest returns the word of the vocabulary the word is most likely a misspelling of, one edit away
+// at most, two for words of 8 characters or more. Swapping two neighbouring characters is one edit.
+func (Index *Index) Nearest(Word string) (string, bool) {
+	Length := utf8.RuneCountInString(Word)
+	if Length < MinCorrected { return "", false }
+	Limit := 1
+	if Length >= 8 { Limit = 2 }
+
+	Best, BestDistance, BestCount := "", Limit + 1, 0
+	Seen := map[string]bool{}
+	for _, Gram := range trigrams(Word) {
+		for _, Candidate := range Index.grams[Gram] {
+			if Seen[Candidate] { continue }
+			Seen[Candidate] = true
+
+			Distance := distance(Word, Candidate, Limit)
+			if Distance > Limit { continue }
+			Count := Index.words[Candidate]
+			if Distance < BestDistance || (Distance == BestDistance && Count > BestCount) {
+				Best, BestDistance, BestCount = Candidate, Distance, Count
+			}
+		}
+	}
+	return Best, Best != "" && Best != Word
+}
+
+// Suggest returns the query with its misspelled words corrected by the vocabulary, "" when none was.
+//
+// Example usage:
+//   search.Suggest("საღებვი კედლის")     "საღებავი კედლის"
+func Suggest(Query string) string {
+	Index := vocabulary.Load()
+	if Index == nil { return "" }
+
+	Words := Words(Query)
+	Corrected := false
+	for i, Word := range Words {
+		if correct, ok := correction(Index, Word); ok { Words[i], Corrected = correct, true }
+	}
+	if !Corrected { return "" }
+	return strings.Join(Words, " ")
+}
+
+/* The word's correction, for a word the vocabulary doesn't know */
+func correction(Index *Index, Word string) (string, bool) {
+	Analyzer := For(Language(Word))
+	if Analyzer.stopwords[Word] || Index.Known(Analyzer.Stem(Word)) { return "", false }
+	return Index.Nearest(Word)
+}
+
+/* Padded so the ends of a word count: "  x", " xy" ... "yz " */
+func trigrams(Word string) []string {
+	Runes := []rune("  " + Word + " ")
+	Grams := make([]string, 0, len(Runes))
+	for i := 0; i + 3 <= len(Runes); i++ { Grams = append(Grams, string(Runes[i:i + 3])) }
+	return Grams
+}
+
+/* Edit distance over characters, transpositions included (optimal string alignment), Limit + 1 as soon
+   as it's known to exceed Limit */
+func distance(A string, B string, Limit int) int {
+	a, b := []rune(A), []rune(B)
+	if abs(len(a) - len(b)) > Limit { return Limit + 1 }
+
+	before := make([]int, len(b) + 1)
+	previous := make([]int, len(b) + 1)
+	current := make([]int, len(b) + 1)
+	for j := range previous { previous[j] = j }
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		Lowest := current[0]
+		for j := 1; j <= len(b); j++ {
+			Cost := 1
+			if a[i - 1] == b[j - 1] { Cost = 0 }
+			current[j] = min(previous[j] + 1, current[j - 1] + 1, previous[j - 1] + Cost)
+			if i > 1 && j > 1 && a[i - 1] == b[j - 2] && a[i - 2] == b[j - 1] { current[j] = min(current[j], before[j - 2] + 1) }
+			Lowest = min(Lowest, current[j])
+		}
+		if Lowest > Limit { return Limit + 1 }
+		before, previous, current = previous, current, before
+	}
+	return min(previous[len(b)], Limit + 1)
+}
+
+func abs(n int) int {
+	if n < 0 { return -n }
+	return n
+}
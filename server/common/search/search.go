@@ -0,0 +1,166 @@
+// Package search explains why a record matched a search: a score for every field the query's terms were found in,
+// and a snippet of the field's text around the first match with the matches marked, which view.SearchResult renders.
+// Finding the records is storage.Search's work, this only scores what it found.
+//
+//   Fields := []search.Field{ { Name: "Name", Weight: 3 }, { Name: "Description", Weight: 1 } }
+//   Hit := search.Score(search.Parse("კედლის საღებავები"), Fields, map[string]string{ "Name": Product.Name, ... })
+//   Hit.Score                  3.75
+//   Hit.Snippet("Name")        [{"შიდა ", false} {"კედლ", true} {"ის ", false} {"საღებავ", true} {"ი", false}]
+//
+// Queries are analyzed by the analyzer of each word's language (see Analyzer): stop-words are dropped, words
+// are cut to their stem and their synonyms are searched for too.
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Radius is how many characters of a field's text a snippet keeps on each side of its first match.
+const Radius = 60
+
+// MaxTerms bounds the terms of a query, the rest are ignored.
+const MaxTerms = 8
+
+// Field is a searched field of a record, a match in a heavier one counts for more.
+type Field struct {
+	Name		string
+	Label		string		/* shown next to the field's snippet, the Name when empty */
+	Weight		float64
+}
+
+// Segment is a part of a snippet, Match tells whether it's one of the query's terms.
+type Segment struct {
+	Text		string
+	Match		bool
+}
+
+type Snippet []Segment
+
+// FieldScore is what a field contributed to a hit.
+type FieldScore struct {
+	Field		string
+	Label		string
+	Score		float64
+	Snippet		Snippet
+}
+
+// Hit is a record's match of a query, its fields with a score above 0 by decreasing score.
+type Hit struct {
+	Score		float64
+	Fields		[]FieldScore
+}
+
+// Snippet returns the snippet of the field, nil when the field didn't match.
+func (Hit Hit) Snippet(Field string) Snippet {
+	for _, Score := range Hit.Fields {
+		if Score.Field == Field { return Score.Snippet }
+	}
+	return nil
+}
+
+// Score scores the values of a record's fields against the query's terms (Parse). A term counts once per field
+// it's found in, twice when it begins a word there, the field's score is the share of the terms it holds
+// times its weight.
+func Score(Terms []Term, Fields []Field, Values map[string]string) Hit {
+	Hit := Hit{}
+	if len(Terms) == 0 { return Hit }
+
+	for _, Field := range Fields {
+		Text := Values[Field.Name]
+		Lower := strings.ToLower(Text)
+
+		var Points float64
+		for _, Term := range Terms {
+			Index, _ := first(Lower, Term)
+			if Index < 0 { continue }
+			Points += 1
+			if begins(Lower, Index) { Points += 1 }
+		}
+		if Points == 0 { continue }
+
+		Label := Field.Label
+		if Label == "" { Label = Field.Name }
+		Score := Field.Weight * Points / float64(2 * len(Terms))
+		Hit.Score += Score
+		Hit.Fields = append(Hit.Fields, FieldScore{ Field: Field.Name, Label: Label, Score: Score, Snippet: Highlight(Text, Terms, Radius) })
+	}
+
+	sort.SliceStable(Hit.Fields, func(i, j int) bool { return Hit.Fields[i].Score > Hit.Fields[j].Score })
+	return Hit
+}
+
+// Highlight cuts the text around the first match of the terms, Radius characters on each side with "…" where
+// it's cut, and marks every match in it. Matches are found case insensitively, the text keeps its case.
+func Highlight(Text string, Terms []Term, Radius int) Snippet {
+	Lower := strings.ToLower(Text)
+	/* Lowercasing changes the length of a few characters, offsets then can't be shared with the text */
+	if len(Lower) != len(Text) { Lower = Text }
+
+	First, _ := next(Lower, Terms)
+	if First < 0 { return nil }
+
+	Start, End := around(Text, First, Radius)
+	Snippet := Snippet{}
+	if Start > 0 { Snippet = append(Snippet, Segment{ Text: "…" }) }
+
+	for Offset := Start; Offset < End; {
+		Index, Length := next(Lower[Offset:End], Terms)
+		if Index < 0 {
+			Snippet = append(Snippet, Segment{ Text: Text[Offset:End] })
+			break
+		}
+		if Index > 0 { Snippet = append(Snippet, Segment{ Text: Text[Offset:Offset + Index] }) }
+		Snippet = append(Snippet, Segment{ Text: Text[Offset + Index:Offset + Index + Length], Match: true })
+		Offset += Index + Length
+	}
+
+	if End < len(Text) { Snippet = append(Snippet, Segment{ Text: "…" }) }
+	return Snippet
+}
+
+/* The earliest, then longest, match of any of the terms' alternatives in the text */
+func next(Text string, Terms []Term) (int, int) {
+	Index, Length := -1, 0
+	for _, Term := range Terms {
+		At, Size := first(Text, Term)
+		if At < 0 { continue }
+		if Index < 0 || At < Index || (At == Index && Size > Length) { Index, Length = At, Size }
+	}
+	return Index, Length
+}
+
+/* The earliest match of the term's alternatives */
+func first(Text string, Term Term) (int, int) {
+	Index, Length := -1, 0
+	for _, Alternative := range Term {
+		At := strings.Index(Text, Alternative)
+		if At < 0 { continue }
+		if Index < 0 || At < Index || (At == Index && len(Alternative) > Length) { Index, Length = At, len(Alternative) }
+	}
+	return Index, Length
+}
+
+/* Byte offsets Radius characters before and after the offset, on character boundaries */
+func around(Text string, Offset int, Radius int) (int, int) {
+	Start := Offset
+	for i := 0; i < Radius && Start > 0; i++ {
+		_, Size := utf8.DecodeLastRuneInString(Text[:Start])
+		Start -= Size
+	}
+	End := Offset
+	for i := 0; i < 2 * Radius && End < len(Text); i++ {
+		_, Size := utf8.DecodeRuneInString(Text[End:])
+		End += Size
+	}
+	return Start, End
+}
+
+/* Whether the match at the offset begins a word, no letter or digit precedes it */
+func begins(Text string, Offset int) bool {
+	if Offset == 0 { return true }
+	Before, _ := utf8.DecodeLastRuneInString(Text[:Offset])
+	return !unicode.IsLetter(Before) && !unicode.IsDigit(Before)
+}
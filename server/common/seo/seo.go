@@ -0,0 +1,32 @@
+// Package seo carries the indexing directives of the page being rendered on the request context, so the SEO templ
+// component emits the robots meta tag and the canonical link the admin set for it (see package indexer).
+package seo
+
+import "context"
+
+// Directives tell search engines how to index a page, Canonical is its canonical url ("" for none).
+type Directives struct {
+	NoIndex		bool
+	NoFollow	bool
+	Canonical	string
+}
+
+type directivesKey struct{}
+
+// Robots is the content of the page's robots meta tag (and X-Robots-Tag header).
+func (Page Directives) Robots() string {
+	Index, Follow := "index", "follow"
+	if Page.NoIndex { Index = "noindex" }
+	if Page.NoFollow { Follow = "nofollow" }
+	return Index + ", " + Follow
+}
+
+func WithDirectives(parent context.Context, Page Directives) context.Context {
+	return context.WithValue(parent, directivesKey{}, Page)
+}
+
+// From returns the directives of the request, a page without them is indexed and followed.
+func From(ctx context.Context) Directives {
+	Page, _ := ctx.Value(directivesKey{}).(Directives)
+	return Page
+}
@@ -0,0 +1,122 @@
+// Package signature signs the internal calls services make to each other (job callbacks, transcoder completion hooks),
+// so the endpoints taking them can't be called by the public even when they're reachable from outside.
+//
+// A signed request carries its time and an HMAC-SHA256 of globals.Env.INTERNAL_SECRET over its method, path and query,
+// time and body:
+//
+//   X-Yacco-Timestamp: 1735686000
+//   X-Yacco-Signature: 4f1c...   hex HMAC of "POST\n/internal/transcoder/01HZX3...\n1735686000\n<hex sha256 of the body>"
+//
+// middleware.Signed verifies them. A request is only accepted within Window of its time, and only once.
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"main/server/common/domain"
+	"main/server/common/globals"
+)
+
+const (
+	HeaderTimestamp = "X-Yacco-Timestamp"
+	HeaderSignature = "X-Yacco-Signature"
+)
+
+// Window is how far a request's time may be from the clock of the server verifying it, either way.
+const Window = 5 * time.Minute
+
+// MaxBody bounds the bodies Verify reads, internal calls are small.
+const MaxBody = 1 << 20
+
+var (
+	ErrMissing = domain.Forbidden("internal", "request is not signed")
+	ErrInvalid = domain.Forbidden("internal", "request signature is invalid")
+	ErrExpired = domain.Forbidden("internal", "request signature has expired")
+	ErrReplayed = domain.Forbidden("internal", "request was already received")
+)
+
+/* Signatures accepted within the last two windows, a request older than that is refused by its time anyway */
+var (
+	mu sync.Mutex
+	seen = map[string]time.Time{}
+)
+
+// Sign signs the request with its body, which the request must carry as it is.
+//
+// Example usage:
+//   Request, _ := http.NewRequestWithContext(ctx, http.MethodPost, URL, bytes.NewReader(Body))
+//   signature.Sign(Request, Body)
+func Sign(Request *http.Request, Body []byte) {
+	Timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	Request.Header.Set(HeaderTimestamp, Timestamp)
+	Request.Header.Set(HeaderSignature, compute(Request.Method, Request.URL.RequestURI(), Timestamp, Body))
+}
+
+// Call makes a signed request, the caller closes the response's body.
+//
+// Example usage:
+//   Response, err := signature.Call(ctx, http.MethodPost, globals.Env.APP_URL + "/internal/transcoder/" + File.Ref(File.ID), nil)
+//   if err != nil { return err }
+//   defer Response.Body.Close()
+func Call(ctx context.Context, Method string, URL string, Body []byte) (*http.Response, error) {
+	Request, err := http.NewRequestWithContext(ctx, Method, URL, bytes.NewReader(Body))
+	if err != nil { return nil, err }
+	if len(Body) > 0 { Request.Header.Set("Content-Type", "application/json") }
+	Sign(Request, Body)
+	return http.DefaultClient.Do(Request)
+}
+
+// Verify checks the request's signature, its body is read and put back for the handler.
+//
+// Returns:
+//   - ErrMissing, ErrInvalid, ErrExpired or ErrReplayed, refusals of the "internal" resource.
+//
+// Notes:
+//   - The path is signed as the server sees it, a proxy in front must not rewrite the paths of internal calls.
+func Verify(Request *http.Request) error {
+	Timestamp := Request.Header.Get(HeaderTimestamp)
+	Signature := Request.Header.Get(HeaderSignature)
+	if Timestamp == "" || Signature == "" { return ErrMissing }
+
+	Seconds, err := strconv.ParseInt(Timestamp, 10, 64)
+	if err != nil { return ErrInvalid }
+
+	Body, err := io.ReadAll(io.LimitReader(Request.Body, MaxBody))
+	if err != nil { return err }
+	Request.Body = io.NopCloser(bytes.NewReader(Body))
+
+	if !hmac.Equal([]byte(Signature), []byte(compute(Request.Method, Request.URL.RequestURI(), Timestamp, Body))) { return ErrInvalid }
+
+	Age := time.Since(time.Unix(Seconds, 0))
+	if Age > Window || Age < -Window { return ErrExpired }
+	return remember(Signature)
+}
+
+func compute(Method string, URI string, Timestamp string, Body []byte) string {
+	Digest := sha256.Sum256(Body)
+	mac := hmac.New(sha256.New, []byte(globals.Env.INTERNAL_SECRET))
+	mac.Write([]byte(Method + "\n" + URI + "\n" + Timestamp + "\n" + hex.EncodeToString(Digest[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func remember(Signature string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	Now := time.Now()
+	for Known, At := range seen {
+		if Now.Sub(At) > 2 * Window { delete(seen, Known) }
+	}
+	if _, replayed := seen[Signature]; replayed { return ErrReplayed }
+	seen[Signature] = Now
+	return nil
+}
@@ -5,13 +5,16 @@ import (
 	"log"
 	"main/server/common/controller"
 	"main/server/common/globals"
+	"main/server/common/search"
 	"os"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -74,12 +77,98 @@ func Connect(config *Config) {
 	}
 
 	DB = db
+	controller.Database = db
 }
 
 func Paginate(ctx *controller.Context) func(db *gorm.DB) *gorm.DB {
 	return func (db *gorm.DB) *gorm.DB {
-		pageSize := ctx.PageSize()
-		offset := (ctx.Page() - 1) * pageSize
-		return db.Offset(offset).Limit(pageSize)
+		Pagination := ctx.Pagination()
+		return db.Offset(Pagination.Offset).Limit(Pagination.PageSize)
 	}
 }
+
+// Preload loads the associations along with the rows, as a scope for Repo's lookups.
+//
+// Example usage:
+//   File, err := repository.Files.First(ctx.Request().Context(), storage.Preload("Derivatives"), ids.Match(ID))
+func Preload(Associations ...string) func(db *gorm.DB) *gorm.DB {
+	return func (db *gorm.DB) *gorm.DB {
+		for _, Association := range Associations { db = db.Preload(Association) }
+		return db
+	}
+}
+
+// Sorted orders by the fields parsed by ctx.Sort, columns are quoted by gorm.
+func Sorted(sort controller.Sort) func(db *gorm.DB) *gorm.DB {
+	return func (db *gorm.DB) *gorm.DB {
+		for _, field := range sort {
+			db = db.Order(clause.OrderByColumn{ Column: clause.Column{ Name: field.Column }, Desc: field.Desc })
+		}
+		return db
+	}
+}
+
+// Filtered applies the filters parsed by ctx.Filters, values are always bound as parameters.
+func Filtered(filters controller.Filters) func(db *gorm.DB) *gorm.DB {
+	return func (db *gorm.DB) *gorm.DB {
+		for _, filter := range filters {
+			column := clause.Column{ Name: filter.Column }
+			value := filter.Values[0]
+
+			switch filter.Operator {
+				case controller.FilterEq: db = db.Where(clause.Eq{ Column: column, Value: value })
+				case controller.FilterNe: db = db.Where(clause.Neq{ Column: column, Value: value })
+				case controller.FilterGt: db = db.Where(clause.Gt{ Column: column, Value: value })
+				case controller.FilterGte: db = db.Where(clause.Gte{ Column: column, Value: value })
+				case controller.FilterLt: db = db.Where(clause.Lt{ Column: column, Value: value })
+				case controller.FilterLte: db = db.Where(clause.Lte{ Column: column, Value: value })
+				case controller.FilterLike: db = db.Where(clause.Like{ Column: column, Value: "%" + value + "%" })
+				case controller.FilterIn:
+					values := make([]interface{}, len(filter.Values))
+					for i, value := range filter.Values { values[i] = value }
+					db = db.Where(clause.IN{ Column: column, Values: values })
+			}
+		}
+		return db
+	}
+}
+
+// Search keeps the records holding every term of the query (search.Parse) in one of the columns, case insensitively,
+// a term is held by holding its stem or one of its synonyms. The ones holding them all in the first column come first.
+// search.Score tells then why each of them matched.
+//
+// Example usage:
+//   ctx.DB().Scopes(storage.Search(Filters.Searcher, model.ProductsName, model.ProductsDescription)).Find(&Products)
+func Search(Query string, Columns ...string) func(db *gorm.DB) *gorm.DB {
+	return func (db *gorm.DB) *gorm.DB {
+		Terms := search.Parse(Query)
+		if len(Terms) == 0 || len(Columns) == 0 { return db }
+
+		First := []clause.Expression{}
+		for _, Term := range Terms {
+			Any := []clause.Expression{}
+			InFirst := []clause.Expression{}
+			for _, Alternative := range Term {
+				Pattern := "%" + likeEscaper.Replace(Alternative) + "%"
+				for i, Column := range Columns {
+					Match := clause.Expr{ SQL: "? ILIKE ?", Vars: []any{ clause.Column{ Name: Column }, Pattern } }
+					Any = append(Any, Match)
+					if i == 0 { InFirst = append(InFirst, Match) }
+				}
+			}
+			db = db.Where(clause.Or(Any...))
+			First = append(First, clause.Or(InFirst...))
+		}
+		return db.Clauses(clause.OrderBy{ Expression: clause.Expr{ SQL: "(?) DESC", Vars: []any{ clause.And(First...) } } })
+	}
+}
+
+/* The wildcards of a term are searched for as they are */
+var likeEscaper = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
+// For returns the database handle of the request.
+//
+// Deprecated: use ctx.DB().
+func For(ctx *controller.Context) *gorm.DB {
+	return ctx.DB()
+}
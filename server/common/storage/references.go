@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Reference is a model whose rows point to a row of another one, through a belongs-to or many2many relation.
+type Reference struct {
+	Model		string
+	Column		string		/* the foreign key, or the join table of a many2many */
+	Count		int64
+}
+
+func (reference Reference) String() string {
+	return fmt.Sprintf("%s.%s (%d)", reference.Model, reference.Column, reference.Count)
+}
+
+// References finds the rows of the models pointing to the target's row ID, through the relations the models
+// declare (an Icon Files field with its IconID). Soft deleted rows don't count.
+//
+// Example usage:
+//   References, err := storage.References(storage.DB, &model.Files{}, File.ID, model.All()...)
+//   if err != nil { return err }
+//   if len(References) > 0 { return ErrReferenced }
+//
+// Notes:
+//   - A bare foreign key column, without the relation's field, isn't known to point anywhere and isn't found.
+func References(db *gorm.DB, target any, ID uint, models ...any) ([]Reference, error) {
+	Target := &gorm.Statement{ DB: db }
+	if err := Target.Parse(target); err != nil { return nil, err }
+
+	References := []Reference{}
+	for _, model := range models {
+		Statement := &gorm.Statement{ DB: db }
+		if err := Statement.Parse(model); err != nil { return nil, err }
+		Name := reflect.Indirect(reflect.ValueOf(model)).Type().Name()
+
+		for _, Relation := range Statement.Schema.Relationships.Relations {
+			if Relation.FieldSchema.Table != Target.Schema.Table { continue }
+
+			var Count int64
+			var Column string
+			switch Relation.Type {
+				case schema.BelongsTo:
+					Column = Relation.References[0].ForeignKey.DBName
+					if err := db.Model(model).Where(Column + " = ?", ID).Count(&Count).Error; err != nil { return nil, err }
+				case schema.Many2Many:
+					Column = Relation.JoinTable.Table
+					if err := db.Table(Column).Where(joinColumn(Relation) + " = ?", ID).Count(&Count).Error; err != nil { return nil, err }
+				default:
+					continue
+			}
+			if Count > 0 { References = append(References, Reference{ Model: Name, Column: Column, Count: Count }) }
+		}
+	}
+	return References, nil
+}
+
+/* The join table's column of the relation's target side */
+func joinColumn(Relation *schema.Relationship) string {
+	for _, Reference := range Relation.References {
+		if Reference.PrimaryKey.Schema == Relation.FieldSchema { return Reference.ForeignKey.DBName }
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"main/server/common/controller"
+	"main/server/common/domain"
+)
+
+var (
+	// ErrNotFound is returned by Repo for a row which doesn't exist: lookups of one, updates and deletes of none.
+	ErrNotFound = domain.NotFound("record not found")
+	// ErrConflict is returned by Repo for writes the database refuses because of other rows: a unique value taken,
+	// a row pointing to one which isn't there, or a row deleted while others still point to it.
+	ErrConflict = domain.Conflict("storage: the row conflicts with another one")
+)
+
+// Repo is the typed data access of a model T, on DB unless bound to a connection with With. Its errors are
+// translated the same way everywhere, ErrNotFound and ErrConflict (see package domain, handlers may return them as
+// they are), others are the database's.
+//
+// Example usage:
+//   var Products storage.Repo[model.Products]
+//
+//   Product, err := Products.With(ctx.DB()).Find(ctx.Request().Context(), ID)
+//   if err != nil { return err }
+//
+//   Page, err := Products.Paginate(ctx.Request().Context(), ctx.Pagination(), storage.Sorted(Sort))
+//
+// Notes:
+//   - The per-model repositories of package repository embed it.
+type Repo[T any] struct {
+	db		*gorm.DB
+}
+
+// With returns the repo working on the given connection, usually a transaction (ctx.DB()).
+func (repo Repo[T]) With(db *gorm.DB) Repo[T] {
+	return Repo[T]{ db: db }
+}
+
+// DB is the query builder of the model, for whatever the other methods don't cover.
+func (repo Repo[T]) DB(ctx context.Context) *gorm.DB {
+	db := repo.db
+	if db == nil { db = DB }
+
+	var Model T
+	return db.WithContext(ctx).Model(&Model)
+}
+
+// Find returns the row of the primary key.
+func (repo Repo[T]) Find(ctx context.Context, ID uint) (T, error) {
+	var Row T
+	return Row, repo.translate(repo.DB(ctx).First(&Row, ID).Error)
+}
+
+// First returns the first row the scopes keep, by primary key.
+//
+// Example usage:
+//   File, err := repository.Files.First(ctx.Request().Context(), ids.Match(ctx.Param("id")))
+func (repo Repo[T]) First(ctx context.Context, Scopes ...func(*gorm.DB) *gorm.DB) (T, error) {
+	var Row T
+	return Row, repo.translate(repo.DB(ctx).Scopes(Scopes...).First(&Row).Error)
+}
+
+// FindBy returns the first row whose column equals the value.
+func (repo Repo[T]) FindBy(ctx context.Context, Column string, Value any) (T, error) {
+	var Row T
+	return Row, repo.translate(repo.DB(ctx).Where(map[string]any{ Column: Value }).First(&Row).Error)
+}
+
+// List returns the rows matching the conditions (as for gorm's Where), all of them without any.
+func (repo Repo[T]) List(ctx context.Context, conditions ...any) ([]T, error) {
+	Rows := []T{}
+	Query := repo.DB(ctx)
+	if len(conditions) > 0 { Query = Query.Where(conditions[0], conditions[1:]...) }
+	return Rows, repo.translate(Query.Find(&Rows).Error)
+}
+
+// Paginate returns the page of the rows the scopes keep, in their order (Sorted, Filtered, Search...).
+//
+// Example usage:
+//   News, err := repository.News.Paginate(ctx.Request().Context(), ctx.Pagination(), storage.Filtered(Filters))
+//   return ctx.Html(view.News(News, ctx.Pagination().Prev(), ctx.Pagination().Next()))
+func (repo Repo[T]) Paginate(ctx context.Context, Pagination controller.Pagination, Scopes ...func(*gorm.DB) *gorm.DB) ([]T, error) {
+	Rows := []T{}
+	err := repo.DB(ctx).Scopes(Scopes...).Offset(Pagination.Offset).Limit(Pagination.PageSize).Find(&Rows).Error
+	return Rows, repo.translate(err)
+}
+
+// Create inserts the row and fills in its primary key.
+//
+// Returns:
+//   - ErrConflict when one of its unique values is taken, or it points to a row which isn't there.
+func (repo Repo[T]) Create(ctx context.Context, Row *T) error {
+	return repo.translate(repo.DB(ctx).Create(Row).Error)
+}
+
+// Update writes the values (a struct's non-zero fields, or a map of columns) to the row, which has its primary key.
+//
+// Returns:
+//   - ErrNotFound when there's no such row.
+//   - ErrConflict when one of the values is taken, or points to a row which isn't there.
+func (repo Repo[T]) Update(ctx context.Context, Row *T, Values any) error {
+	Result := repo.DB(ctx).Model(Row).Updates(Values)
+	if err := repo.translate(Result.Error); err != nil { return err }
+	if Result.RowsAffected == 0 { return ErrNotFound }
+	return nil
+}
+
+// Save updates every column of the row, or inserts it when it has no primary key.
+func (repo Repo[T]) Save(ctx context.Context, Row *T) error {
+	return repo.translate(repo.DB(ctx).Save(Row).Error)
+}
+
+// Delete deletes the row of the primary key (soft, for models with gorm.Model: it's in the trash, see Restore).
+//
+// Returns:
+//   - ErrNotFound when there's no such row.
+//   - ErrConflict when other rows still point to it.
+func (repo Repo[T]) Delete(ctx context.Context, ID uint) error {
+	var Row T
+	Result := repo.DB(ctx).Delete(&Row, ID)
+	if err := repo.translate(Result.Error); err != nil { return err }
+	if Result.RowsAffected == 0 { return ErrNotFound }
+	return nil
+}
+
+/* gorm's and the driver's errors as ErrNotFound and ErrConflict, the others as they are */
+func (repo Repo[T]) translate(err error) error {
+	if err == nil { return nil }
+	db := repo.db
+	if db == nil { db = DB }
+
+	err = translate(db, err)
+	switch {
+		case errors.Is(err, gorm.ErrRecordNotFound): return ErrNotFound
+		case errors.Is(err, gorm.ErrDuplicatedKey), errors.Is(err, gorm.ErrForeignKeyViolated): return ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// Drift is something a model declares which the database lacks: a table, a column or an index.
+type Drift struct {
+	Model		string
+	Kind		string		/* "table", "column" or "index" */
+	Name		string
+}
+
+func (drift Drift) String() string {
+	return fmt.Sprintf("%s: %s %s is missing", drift.Model, drift.Kind, drift.Name)
+}
+
+// Validate compares the models with the database's schema, the way cmd/migrate would migrate them,
+// and returns what's missing. Whatever the database has beyond the models isn't reported.
+//
+// Example usage:
+//   Drifts, err := storage.Validate(storage.DB, model.Models...)
+//   if err != nil { return err }
+//   for _, Drift := range Drifts { log.Print(Drift) }
+//
+// Notes:
+//   - Only existence is compared, a column of another type or an index on other columns passes.
+func Validate(db *gorm.DB, models ...any) ([]Drift, error) {
+	Drifts := []Drift{}
+	Migrator := db.Migrator()
+	Joins := map[string]bool{}
+
+	for _, model := range models {
+		Statement := &gorm.Statement{ DB: db }
+		if err := Statement.Parse(model); err != nil { return nil, err }
+		Schema := Statement.Schema
+		Name := reflect.Indirect(reflect.ValueOf(model)).Type().Name()
+
+		if !Migrator.HasTable(model) {
+			Drifts = append(Drifts, Drift{ Model: Name, Kind: "table", Name: Schema.Table })
+			continue
+		}
+		for _, Field := range Schema.Fields {
+			if Field.DBName == "" || Field.IgnoreMigration { continue }
+			if !Migrator.HasColumn(model, Field.DBName) { Drifts = append(Drifts, Drift{ Model: Name, Kind: "column", Name: Field.DBName }) }
+		}
+		for _, Index := range Schema.ParseIndexes() {
+			if !Migrator.HasIndex(model, Index.Name) { Drifts = append(Drifts, Drift{ Model: Name, Kind: "index", Name: Index.Name }) }
+		}
+
+		/* Both sides of a many2many declare its join table, it's checked once */
+		for _, Relation := range Schema.Relationships.Many2Many {
+			if Joins[Relation.JoinTable.Table] { continue }
+			Joins[Relation.JoinTable.Table] = true
+			if !Migrator.HasTable(Relation.JoinTable.Table) { Drifts = append(Drifts, Drift{ Model: Name, Kind: "table", Name: Relation.JoinTable.Table }) }
+		}
+	}
+	return Drifts, nil
+}
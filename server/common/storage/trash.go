@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"main/server/common/domain"
+)
+
+/*
+	Every model embeds gorm.Model, its rows are soft deleted: Delete sets deleted_at and queries leave them out.
+	They're trashed rather than gone, these bring them back or remove them for good.
+*/
+
+// ErrRestoreConflict is returned by Restore when a row's unique values were taken since it was trashed.
+var ErrRestoreConflict = domain.Conflict("storage: a row with the same unique values exists")
+
+// WithTrashed is the scope of queries which include trashed rows.
+//
+// Example usage:
+//   storage.DB.Scopes(storage.WithTrashed).First(&Product, ID)
+func WithTrashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// OnlyTrashed is the scope of queries which find trashed rows alone.
+//
+// Example usage:
+//   storage.DB.Scopes(storage.OnlyTrashed).Order("deleted_at desc").Find(&Products)
+func OnlyTrashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where(clause.Neq{ Column: clause.Column{ Table: clause.CurrentTable, Name: "deleted_at" }, Value: nil })
+}
+
+// Restore brings trashed rows of a model back, rows which aren't trashed are left as they are.
+//
+// Example usage:
+//   Restored, err := storage.Restore(storage.DB, &model.Products{}, ID)
+//
+// Returns:
+//   - How many rows were restored.
+//   - ErrRestoreConflict when one of them would repeat a unique value, nothing is restored then.
+func Restore(db *gorm.DB, Model any, IDs ...uint) (int64, error) {
+	if len(IDs) == 0 { return 0, nil }
+	Result := db.Unscoped().Model(Model).Where("id IN ? AND deleted_at IS NOT NULL", IDs).Update("deleted_at", nil)
+	if err := translate(db, Result.Error); errors.Is(err, gorm.ErrDuplicatedKey) { return 0, ErrRestoreConflict }
+	return Result.RowsAffected, Result.Error
+}
+
+// PurgeOlderThan removes the rows of a model trashed more than Age ago for good. They're removed one by one, a row
+// which can't be (another row's foreign key still points to it) stays in the trash and the others are removed.
+//
+// Example usage:
+//   Purged, err := storage.PurgeOlderThan(storage.DB, &model.News{}, 30 * 24 * time.Hour)
+//
+// Returns:
+//   - How many rows were removed, along with the errors of those which couldn't be.
+func PurgeOlderThan(db *gorm.DB, Model any, Age time.Duration) (int64, error) {
+	var IDs []uint
+	if err := db.Unscoped().Model(Model).Where("deleted_at < ?", time.Now().Add(-Age)).Pluck("id", &IDs).Error; err != nil { return 0, err }
+
+	var Purged int64
+	var Failed []error
+	for _, ID := range IDs {
+		Removed, err := Purge(db, Model, ID)
+		if err != nil {
+			Failed = append(Failed, err)
+			continue
+		}
+		Purged += Removed
+	}
+	return Purged, errors.Join(Failed...)
+}
+
+// Purge removes a trashed row for good, a row which isn't trashed is left as it is.
+//
+// Returns:
+//   - 1 when it was removed, 0 when there's no such trashed row.
+func Purge(db *gorm.DB, Model any, ID uint) (int64, error) {
+	Result := db.Unscoped().Where("deleted_at IS NOT NULL").Delete(Model, ID)
+	return Result.RowsAffected, Result.Error
+}
+
+/* The driver's error as one of gorm's (gorm.ErrDuplicatedKey, ...), the connection doesn't translate them */
+func translate(db *gorm.DB, err error) error {
+	if Translator, ok := db.Dialector.(gorm.ErrorTranslator); ok && err != nil { return Translator.Translate(err) }
+	return err
+}
@@ -0,0 +1,97 @@
+// Package stream signs the urls of streamed video files and throttles how fast they're sent.
+//
+// A stream url carries its expiry and an HMAC of the file ID and the expiry, signed with globals.Env.STREAM_SECRET.
+// The message is prefixed with "stream:", so a shared download link (see uploader.SignURL) can't be made of it:
+//
+//   /stream/12?expires=1735686000&token=4f1c...
+//
+// so a video can only be watched through a url the site handed out, and only until it expires.
+package stream
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"main/server/common/globals"
+)
+
+// TokenExpiry is how long the urls made by URL stay valid.
+const TokenExpiry = 6 * time.Hour
+
+var (
+	ErrInvalid = errors.New("stream token is invalid")
+	ErrExpired = errors.New("stream token has expired")
+)
+
+// View is the payload of the "video.viewed" event, emitted once per playback (not per range request).
+type View struct {
+	FileID		uint
+	IP			string
+	UserAgent	string
+	At			time.Time
+}
+
+// URL returns a signed stream url of the file, valid for TokenExpiry.
+func URL(FileID uint) string {
+	Expires := time.Now().Add(TokenExpiry).Unix()
+	return "/stream/" + strconv.Itoa(int(FileID)) + "?expires=" + strconv.FormatInt(Expires, 10) + "&token=" + Sign(FileID, Expires)
+}
+
+// Sign returns the token of the file ID and expiry (unix seconds).
+func Sign(FileID uint, Expires int64) string {
+	mac := hmac.New(sha256.New, []byte(globals.Env.STREAM_SECRET))
+	mac.Write([]byte("stream:" + strconv.Itoa(int(FileID)) + ":" + strconv.FormatInt(Expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a token against the file ID and the expiry as they came in the url.
+func Verify(FileID uint, Expires string, Token string) error {
+	ExpiresAt, err := strconv.ParseInt(Expires, 10, 64)
+	if err != nil || Token == "" { return ErrInvalid }
+	if !hmac.Equal([]byte(Token), []byte(Sign(FileID, ExpiresAt))) { return ErrInvalid }
+	if time.Now().Unix() > ExpiresAt { return ErrExpired }
+	return nil
+}
+
+// Throttle limits how many bytes per second are written through the response writer, rate 0 doesn't limit it.
+func Throttle(writer http.ResponseWriter, rate int) http.ResponseWriter {
+	if rate <= 0 { return writer }
+	return &throttled{ ResponseWriter: writer, rate: rate, started: time.Now() }
+}
+
+/* Writes are cut into chunks of a tenth of the rate, sleeping whenever more was sent than the elapsed time allows */
+type throttled struct {
+	http.ResponseWriter
+	rate		int
+	started		time.Time
+	sent		int64
+}
+
+func (writer *throttled) Write(data []byte) (int, error) {
+	written := 0
+	chunk := writer.rate / 10
+	if chunk < 1 { chunk = 1 }
+
+	for written < len(data) {
+		end := written + chunk
+		if end > len(data) { end = len(data) }
+
+		n, err := writer.ResponseWriter.Write(data[written:end])
+		written += n
+		writer.sent += int64(n)
+		if err != nil { return written, err }
+
+		allowed := time.Duration(float64(writer.sent) / float64(writer.rate) * float64(time.Second))
+		if wait := allowed - time.Since(writer.started); wait > 0 { time.Sleep(wait) }
+	}
+	return written, nil
+}
+
+func (writer *throttled) Flush() {
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok { flusher.Flush() }
+}
@@ -7,5 +7,5 @@ import (
 )
 
 func Register(app *echo.Echo) {
-	app.GET("/about", controller.Register(index) )
+	app.GET("/about", controller.RegisterNamed("about", index))
 }
@@ -3,15 +3,21 @@ package admin
 import (
 	"github.com/labstack/echo/v4"
 
+	"main/server/common/controller"
 	"main/server/controller/admin/category"
 	"main/server/controller/admin/dashboard"
 	"main/server/controller/admin/login"
+	"main/server/controller/admin/notifier"
+	"main/server/controller/admin/previewer"
 	"main/server/controller/admin/product"
+	"main/server/controller/admin/profile"
 	"main/server/controller/admin/setting"
+	"main/server/controller/upload"
 	"main/server/middleware"
 )
 
-func Register(app *echo.Echo) {
+// Register registers the admin routes and returns the authenticated admin group, modules add their routes to it.
+func Register(app *echo.Echo) *echo.Group {
 	admin := app.Group("admin")
 	login.Register(admin)
 	
@@ -19,6 +25,19 @@ func Register(app *echo.Echo) {
 
 	category.Register(admin)
 	dashboard.Register(admin)
+	notifier.Register(admin)
+	previewer.Register(admin)
 	product.Register(admin)
+	profile.Register(admin)
 	setting.Register(admin)
+
+	admin.DELETE("/files/:id", controller.Register(upload.Remove), middleware.Can("files.delete"))
+	admin.POST("/files/:id/share", controller.Register(upload.Share), middleware.Can("files.share"))
+	admin.POST("/files/:id/approve", controller.Register(upload.Approve), middleware.Can("files.review"))
+	admin.POST("/files/:id/replace", controller.Register(upload.Replace), middleware.Can("files.replace"))
+	admin.GET("/files/:id/versions", controller.Register(upload.History), middleware.Can("files.replace"))
+	admin.POST("/files/:id/versions/:version/restore", controller.Register(upload.Restore), middleware.Can("files.replace"))
+	admin.GET("/files/:id/events", controller.Register(upload.Events), middleware.Can("files.audit"))
+
+	return admin
 }
\ No newline at end of file
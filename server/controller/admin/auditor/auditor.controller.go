@@ -0,0 +1,46 @@
+package auditor
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"main/server/common/controller"
+	"main/server/service/audit"
+)
+
+// Audit is a change of a row as GET /admin/audits/:entity/:id answers it, User is "" for changes nobody signed in made.
+type Audit struct {
+	Action		string				`json:"action"`
+	UsersID		*uint				`json:"userId,omitempty"`
+	User		string				`json:"user,omitempty"`
+	IP			string				`json:"ip,omitempty"`
+	UserAgent	string				`json:"userAgent,omitempty"`
+	RequestID	string				`json:"requestId,omitempty"`
+	Changes		json.RawMessage		`json:"changes"`
+	At			time.Time			`json:"at"`
+}
+
+// history answers a row's audits, the latest first, a page of them (ctx.Pagination). Entity is the model's table,
+// "products", a purged row's history is still there by the ID it had.
+func history(ctx *controller.Context) error {
+	ID, err := strconv.ParseUint(ctx.Param("id"), 10, 0)
+	if err != nil { return audit.ErrUnknownEntity }
+
+	Pagination := ctx.Pagination()
+	Audits, err := audit.History(ctx.DB(), ctx.Param("entity"), uint(ID), Pagination.Offset, Pagination.PageSize)
+	if err != nil { return err }
+
+	Answer := make([]Audit, len(Audits))
+	for i, Found := range Audits {
+		Changes := json.RawMessage(Found.Changes)
+		if !json.Valid(Changes) { Changes = json.RawMessage("{}") }
+		Answer[i] = Audit{
+			Action: Found.Action, UsersID: Found.UsersID, User: Found.Users.Fullname, IP: Found.IP, UserAgent: Found.UserAgent,
+			RequestID: Found.RequestID, Changes: Changes, At: Found.CreatedAt,
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, Answer)
+}
@@ -0,0 +1,18 @@
+package auditor
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/module"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "audit" }
+
+/* Rows are audited by package audit, set up with the app's services, this only shows what it recorded */
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	Audits := controller.Group(container.Admin.Group("/audits"), controller.RequirePermission("audit.view"))
+	Audits.GET("/:entity/:id", history)
+}
@@ -0,0 +1,30 @@
+package budgeter
+
+import (
+	"log"
+
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/globals"
+	"main/server/common/module"
+	"main/server/model"
+	"main/server/service/budgeter"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "budgeter" }
+
+/* Pages are only measured on staging, the dashboard shows the last run wherever its reports are */
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	if budgeter.Allowed(globals.Env.GOENV) { container.Cron("budget", budgeter.CheckEvery, budgeter.Check) }
+	container.Widget(view.DashboardWidget{ Name: "budget", Render: widget })
+}
+
+func widget(User model.Users) templ.Component {
+	Reports, err := budgeter.Latest()
+	if err != nil { log.Print("Reading budget reports: ", err) }
+	return view.BudgetWidget(Reports)
+}
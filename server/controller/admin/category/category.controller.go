@@ -49,7 +49,7 @@ func CategoryNew(ctx *controller.Context) error {
 
 	file, err := ctx.FormFile("Icon")
 	if file != nil && err == nil {
-		Upload := uploader.File(file)
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "category"))
 		if !Upload.Success {
 			fmt.Print(Upload.Message) 
 			return ctx.String(http.StatusBadRequest, Upload.Message)
@@ -93,7 +93,7 @@ func CategoryUpdate(ctx *controller.Context) error {
 
 	file, err := ctx.FormFile("Icon")
 	if file != nil && err == nil {
-		Upload := uploader.File(file)
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "category"))
 		if !Upload.Success {
 			fmt.Print(Upload.Message) 
 			return ctx.String(http.StatusBadRequest, Upload.Message)
@@ -1,11 +1,17 @@
 package dashboard
 
 import (
+	"github.com/a-h/templ"
+
 	"main/build/view"
 	"main/server/common/controller"
 )
 
 func index(ctx *controller.Context) error {
+	User, _ := ctx.CurrentUser()
+
+	var Widgets []templ.Component
+	for _, Widget := range view.DashboardWidgets { Widgets = append(Widgets, Widget.Render(User)) }
 
-	return ctx.Html(view.Dashboard())
-}
\ No newline at end of file
+	return ctx.Html(view.Dashboard(Widgets))
+}
@@ -0,0 +1,29 @@
+package digest
+
+import (
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/i18n"
+	"main/server/common/module"
+	"main/server/model"
+	"main/server/service/digest"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "digest" }
+
+/* The job wakes up hourly and sends once a week is over, restarts don't reset the week */
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	container.Cron("digest", time.Hour, digest.Send)
+	container.Widget(view.DashboardWidget{ Name: "digest", Render: widget })
+}
+
+func widget(User model.Users) templ.Component {
+	Now := time.Now()
+	return view.DigestWidget(digest.Build(Now.Add(-digest.Period), Now).Lines(i18n.Negotiate(User.Locale)))
+}
@@ -1,17 +1,16 @@
 package login
 
 import (
-	"encoding/json"
 	"main/build/view"
 	"main/server/common/controller"
 	"main/server/common/storage"
 	"main/server/model"
+	"main/server/service/auth"
 	"net/http"
 	"time"
 
 	"github.com/a-h/templ"
 	"github.com/labstack/echo/v4"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func index(ctx *controller.Context) error {
@@ -31,43 +30,75 @@ func changePassword(ctx *controller.Context) error {
 		return ctx.String(http.StatusBadRequest, "atrakeb")
 	}
 
-	Hash, err := bcrypt.GenerateFromPassword([]byte(Parameters.Password), 12)
-	if err != nil { return ctx.String(http.StatusBadRequest, "bycrypt!!") }
+	Hash, err := auth.HashPassword(Parameters.Password)
+	if err != nil { return ctx.String(http.StatusBadRequest, "hash!!") }
 
-	Result := storage.DB.Table("users").Where(&model.Users{ Email: Parameters.Email }).Update("password", string(Hash))
+	Result := storage.DB.Table("users").Where(&model.Users{ Email: Parameters.Email }).Update("password", Hash)
 	if Result.Error != nil || Result.RowsAffected < 1 { return ctx.String(http.StatusBadRequest, "No rows affected") }
+
+	var User model.Users
+	if storage.DB.Last(&User, &model.Users{ Email: Parameters.Email }).Error == nil {
+		auth.PasswordChanged(User, ctx.RealIP())
+	}
 	return ctx.String(http.StatusOK, "success")
 }
 
+type LoginInput struct {
+	Email string `json:"email" form:"email" validate:"required,email"`
+	Password string `json:"password" form:"password" validate:"required"`
+	Remember string `json:"remember" form:"remember"`
+}
+
 func login(ctx *controller.Context) error {
 	var UserMatch model.Users
-	var Parameters CredsParams
 
-	if err := (&echo.DefaultBinder{}).BindBody(ctx, &Parameters);
-	err != nil || Parameters.Email == "" || Parameters.Password == "" {
-		return ctx.Renders(http.StatusBadRequest, view.Login())
+	Parameters, errs := controller.BindForm[LoginInput](ctx)
+	if !errs.Valid() {
+		return ctx.HtmlFormErrors("#LoginForm", view.LoginForm(Parameters.Email, errs))
 	}
 	
 	Result := storage.DB.Last(&UserMatch, &model.Users{ Email: Parameters.Email })
 	if Result.Error != nil || Result.RowsAffected < 1 {
-		return ctx.Renders(http.StatusNotFound, view.Login())
+		errs.Add(controller.FormErrorKey, ctx.T("login.invalid"))
+		return ctx.HtmlFormErrors("#LoginForm", view.LoginForm(Parameters.Email, errs))
+	}
+
+	if auth.IsLocked(UserMatch) {
+		errs.Add(controller.FormErrorKey, ctx.T("login.locked"))
+		return ctx.HtmlFormErrors("#LoginForm", view.LoginForm(Parameters.Email, errs))
+	}
+
+	/* A hash of an older scheme is upgraded here, the one moment the password is known */
+	if !auth.VerifyPassword(UserMatch, Parameters.Password) {
+		auth.LoginFailed(UserMatch, ctx.RealIP())
+		errs.Add(controller.FormErrorKey, ctx.T("login.invalid"))
+		return ctx.HtmlFormErrors("#LoginForm", view.LoginForm(Parameters.Email, errs))
 	}
 
-	err := bcrypt.CompareHashAndPassword([]byte(UserMatch.Password), []byte(Parameters.Password))
-    if err != nil { return ctx.Renders(http.StatusUnauthorized, view.Login()) }
+	auth.LoginSucceeded(UserMatch, ctx.Request().UserAgent(), ctx.RealIP())
 
-	Expires := time.Now().Add(24 * time.Hour)
-	AuthHtmxMeta, _ := json.Marshal(struct {
-		Expires   time.Time    `json:"Expires"`
-        Email     string    `json:"Email"`
-        Token     string    `json:"Token"`
-	}{
-		Expires: Expires, Email: UserMatch.Email, Token: UserMatch.Token,
-	})      
+	/* A new session on every login, the token the browser came with (maybe planted) ends */
+	Grants, err := auth.Grants(UserMatch.ID)
+	if err != nil { return err }
+	Token, err := auth.StartSession(UserMatch, Grants, ctx.Request().UserAgent(), ctx.RealIP(), ctx.ReadCookie("token").Value)
+	if err != nil { return err }
+	auth.WriteSession(ctx, UserMatch, Token)
 
-	ctx.WriteCookie(controller.Cookie{ Key: "user", Value: UserMatch.Email, Expires: Expires })
-	ctx.WriteCookie(controller.Cookie{ Key: "token", Value: UserMatch.Token, Expires: Expires })
-	ctx.Response().Header().Add("HX-AUTH-META", string(AuthHtmxMeta))
+	if Parameters.Remember != "" {
+		Remember, err := auth.Remember(UserMatch, ctx.Request().UserAgent())
+		if err == nil {
+			ctx.WriteCookie(controller.NewCookie(auth.RememberCookie, Remember, time.Now().Add(auth.RememberFor)))
+		}
+	}
 
 	return ctx.Html(view.Admin(templ.NopComponent))
 }
+
+func logout(ctx *controller.Context) error {
+	auth.EndSession(ctx.ReadCookie("token").Value)
+	if Token := ctx.Request().Header.Get("X-Token"); Token != "" { auth.EndSession(Token) }
+	if ctx.HasCookie(auth.RememberCookie) { auth.Forget(ctx.ReadCookie(auth.RememberCookie).Value) }
+	auth.ClearSession(ctx)
+
+	return ctx.Renders(http.StatusOK, view.Login())
+}
@@ -9,5 +9,6 @@ import (
 func Register(app *echo.Group) {
 	app.GET("/login", controller.Register(index))
 	app.POST("/login", controller.Register(login))
+	app.POST("/logout", controller.Register(logout))
 	// app.POST("/login/changePassword", controller.Register(changePassword))
 }
\ No newline at end of file
@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"net/http"
+
+	"github.com/a-h/templ"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/service/notifications"
+)
+
+// index answers a page of the user's notifications as json ({notifications, next, unread}), "cursor" being the
+// "next" of the previous page. htmx requests get the drawer on the first page and the following pages alone.
+func index(ctx *controller.Context) error {
+	var Query ListDto
+
+	if err := ctx.Bind(&Query); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	User, err := ctx.MustUser()
+	if err != nil { return err }
+
+	Page, err := notifications.List(User.ID, Query.Cursor, Query.Limit, Query.Unread != "")
+	if err != nil { return err }
+
+	if ctx.IsHtmx() && Query.Cursor != "" { return ctx.Html(view.NotificationsPage(Page.Notifications, Page.Next)) }
+
+	Unread, err := notifications.Unread(User.ID)
+	if err != nil { return err }
+
+	if !ctx.IsHtmx() {
+		return ctx.JSON(http.StatusOK, map[string]any{ "notifications": Page.Notifications, "next": Page.Next, "unread": Unread })
+	}
+	return ctx.Html(drawer(User.ID, Page, Unread))
+}
+
+func count(ctx *controller.Context) error {
+	User, err := ctx.MustUser()
+	if err != nil { return err }
+
+	Unread, err := notifications.Unread(User.ID)
+	if err != nil { return err }
+
+	if !ctx.IsHtmx() { return ctx.JSON(http.StatusOK, map[string]int64{ "unread": Unread }) }
+	return ctx.Html(view.NotificationsCount(Unread))
+}
+
+// read marks the notifications of "ids" read, or with "all" every one up to "until" (every one without it).
+// The response triggers "notifications-read", htmx requests marking them all get the drawer again.
+func read(ctx *controller.Context) error {
+	var Body ReadDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	User, err := ctx.MustUser()
+	if err != nil { return err }
+
+	var Marked int64
+	if Body.All != "" {
+		Marked, err = notifications.MarkAllRead(User.ID, Body.Until)
+	} else {
+		Marked, err = notifications.MarkRead(User.ID, Body.IDs)
+	}
+	if err != nil { return err }
+
+	if !ctx.IsHtmx() {
+		Unread, err := notifications.Unread(User.ID)
+		if err != nil { return err }
+		return ctx.JSON(http.StatusOK, map[string]int64{ "marked": Marked, "unread": Unread })
+	}
+	if Body.All == "" { return ctx.HxNoContent("notifications-read") }
+
+	Page, err := notifications.List(User.ID, "", 0, false)
+	if err != nil { return err }
+	Unread, err := notifications.Unread(User.ID)
+	if err != nil { return err }
+
+	if err := ctx.HxTrigger("notifications-read"); err != nil { return err }
+	return ctx.Html(drawer(User.ID, Page, Unread))
+}
+
+// mute mutes or unmutes a category of notifications for the user.
+func mute(ctx *controller.Context) error {
+	var Body MuteDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	User, err := ctx.MustUser()
+	if err != nil { return err }
+
+	if err := notifications.Mute(User.ID, Body.Category, Body.Muted != ""); err != nil { return err }
+
+	if !ctx.IsHtmx() { return ctx.JSON(http.StatusOK, notifications.Muted(User.ID)) }
+	return ctx.Html(view.NotificationMutes(categories(User.ID)))
+}
+
+func drawer(UsersID uint, Page notifications.Page, Unread int64) templ.Component {
+	Newest := uint(0)
+	if len(Page.Notifications) > 0 { Newest = Page.Notifications[0].ID }
+	return view.NotificationsDrawer(Page.Notifications, Page.Next, Unread, Newest, categories(UsersID))
+}
+
+func categories(UsersID uint) []view.NotificationCategory {
+	Muted := notifications.Muted(UsersID)
+
+	Categories := []view.NotificationCategory{}
+	for _, Category := range notifications.Categories {
+		Categories = append(Categories, view.NotificationCategory{ Name: Category.Name, Label: Category.Label, Muted: Muted[Category.Name] })
+	}
+	return Categories
+}
@@ -0,0 +1,18 @@
+package notifier
+
+type ListDto struct {
+	Cursor 			string 		`query:"cursor"`
+	Limit 			int 		`query:"limit"`
+	Unread 			string 		`query:"unread"`
+}
+
+type ReadDto struct {
+	IDs 			[]uint 		`json:"ids" form:"ids"`
+	All 			string 		`json:"all" form:"all"`
+	Until 			uint 		`json:"until" form:"until"`
+}
+
+type MuteDto struct {
+	Category 		string 		`param:"category"`
+	Muted 			string 		`json:"muted"`
+}
@@ -0,0 +1,14 @@
+package notifier
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+)
+
+func Register(app *echo.Group) {
+	app.GET("/notifications", controller.Register(index))
+	app.GET("/notifications/count", controller.Register(count))
+	app.POST("/notifications/read", controller.Register(read))
+	app.PUT("/notifications/mutes/:category", controller.Register(mute))
+}
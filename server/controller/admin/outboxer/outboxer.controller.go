@@ -0,0 +1,71 @@
+package outboxer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/a-h/templ"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+	mailer "main/server/service/mail"
+)
+
+// OutboxSize is how many of the latest mails the outbox shows.
+const OutboxSize = 100
+
+func events(ctx *controller.Context) error {
+	Body, err := io.ReadAll(io.LimitReader(ctx.Request().Body, 1 << 20))
+	if err != nil { return ctx.String(http.StatusBadRequest, err.Error()) }
+
+	Signature := ctx.Request().Header.Get("X-Twilio-Email-Event-Webhook-Signature")
+	Timestamp := ctx.Request().Header.Get("X-Twilio-Email-Event-Webhook-Timestamp")
+	if err := mailer.Verify(Signature, Timestamp, Body); err != nil {
+		return ctx.String(http.StatusForbidden, err.Error())
+	}
+
+	var Events []mailer.Event
+	if err := json.Unmarshal(Body, &Events); err != nil { return ctx.String(http.StatusBadRequest, err.Error()) }
+
+	/* A failure is answered with 500 so the provider retries the batch, applying an event twice is harmless */
+	if err := mailer.Ingest(Events); err != nil {
+		ctx.Log("Ingesting mail events: ", err)
+		return ctx.String(http.StatusInternalServerError, "")
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func index(ctx *controller.Context) error {
+	var Query OutboxDto
+
+	if err := ctx.Bind(&Query); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	return ctx.Html(outbox(Query))
+}
+
+func unsuppress(ctx *controller.Context) error {
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+
+	if err := mailer.Unsuppress(uint(ID)); err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	return ctx.Html(outbox(OutboxDto{}))
+}
+
+func outbox(Query OutboxDto) templ.Component {
+	var Mails []model.Mails
+	Filter := model.Mails{ Status: Query.Status, To: mailer.Normalize(Query.To) }
+	storage.DB.Omit("body").Where(&Filter).Order("created_at desc").Limit(OutboxSize).Find(&Mails)
+
+	var Suppressions []model.Mail_suppressions
+	storage.DB.Order("updated_at desc").Find(&Suppressions)
+
+	return view.Outbox(Mails, Suppressions, Query.Status)
+}
@@ -0,0 +1,6 @@
+package outboxer
+
+type OutboxDto struct {
+	Status			string       `query:"status"`
+	To				string       `query:"to"`
+}
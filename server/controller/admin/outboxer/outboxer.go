@@ -0,0 +1,24 @@
+package outboxer
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/module"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "outbox" }
+
+/* The webhook is public, the provider authenticates by signing its requests (mailer.Verify) */
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	app.POST("/mail/events", controller.Register(events))
+
+	Outbox := controller.Group(container.Admin.Group("/outbox"))
+	Outbox.GET("", index)
+	Outbox.DELETE("/suppressions/:id", unsuppress)
+
+	container.AdminRoute(view.AdminRoute{ Path: "/outbox", Name: "გაგზავნილი წერილები", Slug: "outbox", Icon: view.SettingsIcon() })
+}
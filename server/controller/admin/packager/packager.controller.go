@@ -0,0 +1,62 @@
+package packager
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/service/hooks"
+	"main/server/service/packager"
+)
+
+func index(ctx *controller.Context) error {
+	return ctx.Html(view.Packager(packager.Kinds))
+}
+
+func export(ctx *controller.Context) error {
+	var Body ExportDto
+
+	if err := ctx.Bind(&Body); err != nil || len(Body.Kinds) == 0 {
+		return ctx.String(http.StatusBadRequest, "Choose what to export")
+	}
+
+	Archive, err := packager.ExportBytes(Body.Kinds)
+	if err != nil {
+		ctx.Log("Exporting package: ", err)
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="content-` + time.Now().Format("20060102-150405") + `.zip"`)
+	return ctx.Blob(http.StatusOK, "application/zip", Archive)
+}
+
+func load(ctx *controller.Context) error {
+	var Body ImportDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	file, err := ctx.FormFile("package")
+	if err != nil { return ctx.Html(view.PackagerReport(nil, "Package file is missing")) }
+
+	src, err := file.Open()
+	if err != nil { return ctx.Html(view.PackagerReport(nil, err.Error())) }
+	defer src.Close()
+
+	/* The zip is read where it's spooled, not held in memory */
+	Results, err := packager.Import(src, file.Size, packager.Conflict(Body.Conflict))
+	if err != nil {
+		ctx.Log("Importing package: ", err)
+		return ctx.Html(view.PackagerReport(nil, err.Error()))
+	}
+
+	hooks.Notify(ctx.Request().Context(), "package.imported", Results)
+
+	Report := make([]view.PackagerResult, len(Results))
+	for i, Result := range Results { Report[i] = view.PackagerResult(Result) }
+	return ctx.Html(view.PackagerReport(Report, ""))
+}
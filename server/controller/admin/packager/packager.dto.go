@@ -0,0 +1,9 @@
+package packager
+
+type ExportDto struct {
+	Kinds 		[]string 	`form:"kinds"`
+}
+
+type ImportDto struct {
+	Conflict 	string 		`form:"conflict"`
+}
@@ -0,0 +1,24 @@
+package packager
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/module"
+)
+
+/* Exports and imports hold the whole archive in memory, they share their slots */
+const Concurrency = 2
+
+type Module struct{}
+
+func (Module) Name() string { return "packager" }
+
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	container.Admin.GET("/package", controller.Register(index))
+	container.Admin.POST("/package/export", controller.Register(export), controller.Concurrency("package", Concurrency))
+	container.Admin.POST("/package/import", controller.Register(load), controller.Concurrency("package", Concurrency))
+
+	container.AdminRoute(view.AdminRoute{ Path: "/package", Name: "კონტენტის გადატანა", Slug: "package", Icon: view.SettingsIcon() })
+}
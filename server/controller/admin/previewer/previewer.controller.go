@@ -0,0 +1,118 @@
+package previewer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/hooks"
+	"main/server/service/previewer"
+)
+
+func index(ctx *controller.Context) error {
+	return ctx.Html(view.Previewer(channels(), previewer.Kinds, ""))
+}
+
+func create(ctx *controller.Context) error {
+	var Body ChannelDto
+
+	if err := ctx.Bind(&Body); err != nil || Body.Name == "" {
+		return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, "Channel name is required"))
+	}
+
+	if _, err := previewer.Create(Body.Name); err != nil {
+		return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, err.Error()))
+	}
+
+	return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, ""))
+}
+
+func stage(ctx *controller.Context) error {
+	var Body ChangeDto
+	var Fields map[string]interface{}
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	if Body.Fields != "" {
+		if err := json.Unmarshal([]byte(Body.Fields), &Fields); err != nil {
+			return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, "Fields must be a json object: " + err.Error()))
+		}
+	}
+
+	if _, err := previewer.Stage(uint(ID), Body.Kind, Body.RecordID, Body.Delete != "", Fields); err != nil {
+		return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, err.Error()))
+	}
+
+	return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, ""))
+}
+
+func publish(ctx *controller.Context) error {
+	var Channel model.Preview_channels
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+	storage.DB.Preload("Changes").First(&Channel, ID)
+
+	if Verdict := hooks.Ask(ctx.Request().Context(), "preview.publish", Channel); !Verdict.Allow {
+		return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, Verdict.Message))
+	}
+
+	if err := previewer.Publish(uint(ID)); err != nil {
+		ctx.Log("Publishing preview channel ", ID, ": ", err)
+		return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, err.Error()))
+	}
+
+	return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, ""))
+}
+
+func discard(ctx *controller.Context) error {
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+
+	if err := previewer.Discard(uint(ID)); err != nil {
+		return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, err.Error()))
+	}
+
+	return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, ""))
+}
+
+// share makes a preview link of a page of the channel, valid for Hours (72 by default).
+func share(ctx *controller.Context) error {
+	var Body LinkDto
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+	Hours, err := strconv.Atoi(Body.Hours)
+	if err != nil || Hours <= 0 { Hours = 72 }
+
+	if _, err := previewer.Share(uint(ID), Body.Path, Body.Label, time.Duration(Hours) * time.Hour); err != nil {
+		return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, err.Error()))
+	}
+
+	return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, ""))
+}
+
+func revoke(ctx *controller.Context) error {
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+
+	if err := previewer.Revoke(uint(ID)); err != nil {
+		return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, err.Error()))
+	}
+
+	return ctx.Html(view.PreviewerChannels(channels(), previewer.Kinds, ""))
+}
+
+func channels() []model.Preview_channels {
+	var Channels []model.Preview_channels
+	storage.DB.Preload("Changes").Preload("Links.Visits", func(db *gorm.DB) *gorm.DB { return db.Order("id desc") }).Order("id desc").Find(&Channels)
+	return Channels
+}
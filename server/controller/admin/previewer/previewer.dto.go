@@ -0,0 +1,18 @@
+package previewer
+
+type ChannelDto struct {
+	Name 		string 		`json:"name" form:"name"`
+}
+
+type ChangeDto struct {
+	Kind 		string 		`json:"kind" form:"kind"`
+	RecordID 	uint 		`json:"recordId" form:"recordId"`
+	Delete 		string 		`json:"delete" form:"delete"`
+	Fields 		string 		`json:"fields" form:"fields"`
+}
+
+type LinkDto struct {
+	Path 		string 		`json:"path" form:"path"`
+	Label 		string 		`json:"label" form:"label"`
+	Hours 		string 		`json:"hours" form:"hours"`
+}
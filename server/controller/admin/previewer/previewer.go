@@ -0,0 +1,17 @@
+package previewer
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+)
+
+func Register(app *echo.Group) {
+	app.GET("/preview", controller.Register(index))
+	app.POST("/preview", controller.Register(create))
+	app.POST("/preview/:id/changes", controller.Register(stage))
+	app.POST("/preview/:id/publish", controller.Register(publish))
+	app.DELETE("/preview/:id", controller.Register(discard))
+	app.POST("/preview/:id/links", controller.Register(share))
+	app.DELETE("/preview/links/:id", controller.Register(revoke))
+}
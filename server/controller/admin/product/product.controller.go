@@ -5,8 +5,10 @@ import (
 	"main/build/view"
 	"main/server/common/controller"
 	uploader "main/server/common/helpers"
+	"main/server/common/route"
 	"main/server/common/storage"
 	"main/server/model"
+	"main/server/service/pinger"
 	"net/http"
 	"strconv"
 
@@ -16,8 +18,10 @@ import (
 func findProducts(ctx *controller.Context) ([]model.Products, []model.Categories) {
 	var Products []model.Products
 	
-	storage.DB.Scopes(storage.Paginate(ctx)).
-				Order("created_at desc").
+	Sort := ctx.Sort("created_at", "name", "category:category_id").Or(controller.SortField{ Column: "created_at", Desc: true })
+	Filters := ctx.Filters(controller.FilterSchema{ "category": "category_id", "public": "public", "name": "name" })
+
+	storage.DB.Scopes(storage.Paginate(ctx), storage.Sorted(Sort), storage.Filtered(Filters)).
 				Preload("Category").
 				Preload("Thumbnail").
 				Preload("Packing").
@@ -85,7 +89,7 @@ func ProductsNew(ctx *controller.Context) error {
 
 	file, err := ctx.FormFile("Thumbnail")
 	if file != nil && err == nil {
-		Upload := uploader.File(file)
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "product"))
 		if !Upload.Success {
 			fmt.Print(Upload.Message) 
 			return ctx.String(http.StatusBadRequest, Upload.Message)
@@ -102,6 +106,7 @@ func ProductsNew(ctx *controller.Context) error {
 		return ctx.String(http.StatusBadRequest, result.Error.Error())
 	}
 
+	if Parameters.Public { announce(ctx, Parameters) }
 	return ctx.Html(view.Product(findProducts(ctx)))
 }
 
@@ -134,7 +139,7 @@ func ProductsUpdate(ctx *controller.Context) error {
 
 	file, err := ctx.FormFile("Thumbnail")
 	if file != nil && err == nil {
-		Upload := uploader.File(file)
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "product"))
 		if !Upload.Success {
 			fmt.Print(Upload.Message) 
 			return ctx.String(http.StatusBadRequest, Upload.Message)
@@ -148,6 +153,7 @@ func ProductsUpdate(ctx *controller.Context) error {
 		return ctx.String(http.StatusBadRequest, result.Error.Error())
 	}
 
+	if Public { announce(ctx, Productie) }
 	return ctx.Html(view.Product(findProducts(ctx)))
 }
 
@@ -168,6 +174,8 @@ func ProductsStatus(ctx *controller.Context) error {
 		return ctx.String(http.StatusBadRequest, result.Error.Error())
 	}
 
+	/* Productie still holds the status it had */
+	if !Productie.Public { announce(ctx, Productie) }
 
 	return ctx.Html(view.Product(findProducts(ctx)))
 }
@@ -188,3 +196,10 @@ func ProductsRemove(ctx *controller.Context) error {
 
 	return ctx.Html(templ.NopComponent)
 }
+
+/* Search engines are told about a product once it's public, failing to tell them doesn't fail the change */
+func announce(ctx *controller.Context, Product model.Products) {
+	if err := pinger.Published(storage.DB, route.URL("products.detail", Product.Ref(Product.ID))); err != nil {
+		ctx.Log("Pinging search engines: ", err)
+	}
+}
@@ -0,0 +1,72 @@
+package product
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/exporter"
+)
+
+// export downloads the products matching the listing's filters as CSV, all of them rather than a page,
+// in the order they were created. Over EXPORT_ASYNC_ROWS products it's made in the background instead
+// (see package exporter) and the admin is mailed a link: json clients get 202 with the task, htmx requests
+// and links are sent to the tasks page.
+func export(ctx *controller.Context) error {
+	User, err := ctx.MustUser()
+	if err != nil { return err }
+
+	Filters := ctx.Filters(controller.FilterSchema{ "category": "category_id", "public": "public", "name": "name" })
+	filtered := func() *gorm.DB { return storage.DB.Model(&model.Products{}).Scopes(storage.Filtered(Filters)) }
+
+	var Count int64
+	if err := filtered().Count(&Count).Error; err != nil { return err }
+
+	Listing := exporter.Listing[model.Products]{
+		Name: "products",
+		Title: "პროდუქტების ექსპორტი",
+		Header: []string{ "id", "name", "slug", "category", "public", "description", "created_at", "updated_at" },
+		Query: filtered().Preload("Category"),
+		Row: func(Product model.Products) []string {
+			return []string{
+				Product.Ref(Product.ID),
+				Product.Name,
+				Product.Slug,
+				Product.Category.Name,
+				strconv.FormatBool(Product.Public),
+				Product.Description,
+				Product.CreatedAt.Format("2006-01-02 15:04:05"),
+				Product.UpdatedAt.Format("2006-01-02 15:04:05"),
+			}
+		},
+	}
+
+	if exporter.Async(Count) {
+		Task, err := exporter.Start(User, Listing, Count)
+		if err != nil { return err }
+
+		switch {
+			case strings.Contains(ctx.Request().Header.Get(echo.HeaderAccept), echo.MIMEApplicationJSON):
+				return ctx.JSON(http.StatusAccepted, Task)
+			case ctx.Htmx().Request:
+				ctx.Response().Header().Set("HX-Redirect", "/admin/tasks")
+				return ctx.NoContent(http.StatusAccepted)
+			default:
+				return ctx.Redirect(http.StatusSeeOther, "/admin/tasks")
+		}
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="` + exporter.FileName(Listing) + `"`)
+	ctx.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	ctx.Response().WriteHeader(http.StatusOK)
+
+	/* Once the header is sent a failure can't be answered anymore, the download is cut short */
+	if err := exporter.Write(ctx.Request().Context(), ctx.Response(), Listing, nil); err != nil { ctx.Log("Exporting products: ", err) }
+	return nil
+}
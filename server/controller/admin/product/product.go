@@ -9,6 +9,12 @@ import (
 func Register(app *echo.Group) {
 	Products := app.Group("/product")
 	Products.GET("", controller.Register(index))
+	Products.GET("/export", controller.Register(export))
+	Products.GET("/import", controller.Register(importPage))
+	Products.POST("/import", controller.Register(importUpload))
+	Products.POST("/import/preview", controller.Register(importPreview))
+	Products.POST("/import/commit", controller.Register(importCommit))
+	Products.DELETE("/import/:file", controller.Register(importDiscard))
 	Products.GET("/:id", controller.Register(indexByID))
 	Products.POST("", controller.Register(ProductsNew))
 	Products.PUT("", controller.Register(ProductsUpdate))
@@ -0,0 +1,142 @@
+package product
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"gorm.io/gorm"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/importer"
+)
+
+const importAction = "/admin/product/import"
+
+// importSpec reads products from a spreadsheet like the export's (see export), so an exported catalog can be
+// edited and imported again. The category is its name or ID, ids and dates aren't read: every row is a new product.
+func importSpec() importer.ImportSpec[model.Products] {
+	var Categories []model.Categories
+	storage.DB.Find(&Categories)
+	Named := map[string]int{}
+	for _, Category := range Categories {
+		Named[strings.ToLower(Category.Name)] = int(Category.ID)
+		Named[strconv.Itoa(int(Category.ID))] = int(Category.ID)
+	}
+
+	return importer.ImportSpec[model.Products]{
+		Name: "products",
+		Title: "პროდუქტების იმპორტი",
+		Columns: []importer.Column[model.Products]{
+			{ Name: "name", Label: "სახელი", Required: true, Set: func(Product *model.Products, Cell string) error {
+				Product.Name = Cell
+				return nil
+			} },
+			{ Name: "category", Label: "კატეგორია", Required: true, Set: func(Product *model.Products, Cell string) error {
+				ID, ok := Named[strings.ToLower(Cell)]
+				if !ok { return errors.New("ასეთი კატეგორია არ არსებობს") }
+				Product.CategoryID = ID
+				return nil
+			} },
+			{ Name: "slug", Label: "Slug", Set: func(Product *model.Products, Cell string) error {
+				Product.Slug = Cell
+				return nil
+			} },
+			{ Name: "public", Label: "საჯარო", Set: func(Product *model.Products, Cell string) error {
+				switch strings.ToLower(Cell) {
+					case "true", "1", "yes", "კი": Product.Public = true
+					case "false", "0", "no", "არა": Product.Public = false
+					default: return errors.New("უნდა იყოს true ან false")
+				}
+				return nil
+			} },
+			{ Name: "description", Label: "აღწერა", Set: func(Product *model.Products, Cell string) error {
+				Product.Description = Cell
+				return nil
+			} },
+			{ Name: "technical_sheet_url", Label: "ტექნიკური ფურცელი", Set: func(Product *model.Products, Cell string) error {
+				if Parsed, err := url.Parse(Cell); err != nil || Parsed.Host == "" { return errors.New("არასწორი ბმული") }
+				Product.TechnicalSheetUrl = Cell
+				return nil
+			} },
+		},
+		New: func() model.Products { return model.Products{ Public: true } },
+		/* Public defaults to true, gorm leaves a false one to the database's default: they're hidden once created */
+		Save: func(tx *gorm.DB, Batch []model.Products) error {
+			if err := tx.Create(&Batch).Error; err != nil { return err }
+
+			Hidden := []uint{}
+			for _, Product := range Batch {
+				if !Product.Public { Hidden = append(Hidden, Product.ID) }
+			}
+			if len(Hidden) == 0 { return nil }
+			return tx.Model(&model.Products{}).Where("id IN ?", Hidden).Update("public", false).Error
+		},
+	}
+}
+
+func importPage(ctx *controller.Context) error {
+	return ctx.Html(view.Import("პროდუქტების იმპორტი", importAction))
+}
+
+func importUpload(ctx *controller.Context) error {
+	User, err := ctx.MustUser()
+	if err != nil { return err }
+
+	Header, err := ctx.FormFile("sheet")
+	if err != nil { return ctx.Html(view.ImportFailed(err.Error())) }
+	Sheet, err := Header.Open()
+	if err != nil { return err }
+	defer Sheet.Close()
+
+	FileID, Read, err := importer.Stage(ctx.Request().Context(), User.ID, Header.Filename, Sheet)
+	if err != nil { return ctx.Html(view.ImportFailed(err.Error())) }
+
+	Spec := importSpec()
+	return ctx.Html(view.ImportPreview(importer.Preview(Spec, importAction, FileID, Header.Filename, Read, importer.Guess(Spec, Read.Header))))
+}
+
+func importPreview(ctx *controller.Context) error {
+	User, err := ctx.MustUser()
+	if err != nil { return err }
+
+	FileID, _ := strconv.Atoi(ctx.FormValue("file"))
+	Sheet, Original, err := importer.Open(ctx.Request().Context(), User.ID, uint(FileID))
+	if err != nil { return ctx.Html(view.ImportFailed(err.Error())) }
+
+	Spec := importSpec()
+	return ctx.Html(view.ImportPreview(importer.Preview(Spec, importAction, uint(FileID), Original, Sheet, importer.Mapped(Spec, Sheet.Header, ctx.FormValue))))
+}
+
+/* The import runs in the background, its progress and error report are on the tasks page */
+func importCommit(ctx *controller.Context) error {
+	User, err := ctx.MustUser()
+	if err != nil { return err }
+
+	FileID, _ := strconv.Atoi(ctx.FormValue("file"))
+	Sheet, _, err := importer.Open(ctx.Request().Context(), User.ID, uint(FileID))
+	if err != nil { return ctx.Html(view.ImportFailed(err.Error())) }
+
+	Spec := importSpec()
+	if _, err := importer.Start(User, Spec, uint(FileID), importer.Mapped(Spec, Sheet.Header, ctx.FormValue)); err != nil {
+		return ctx.Html(view.ImportFailed(err.Error()))
+	}
+
+	ctx.Response().Header().Set("HX-Redirect", "/admin/tasks")
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+func importDiscard(ctx *controller.Context) error {
+	User, err := ctx.MustUser()
+	if err != nil { return err }
+
+	FileID, _ := strconv.Atoi(ctx.Param("file"))
+	if err := importer.Discard(ctx.Request().Context(), User.ID, uint(FileID)); err != nil && !errors.Is(err, importer.ErrNotStaged) { return err }
+	return ctx.Html(templ.NopComponent)
+}
@@ -0,0 +1,70 @@
+package profile
+
+import (
+	"net/http"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/i18n"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+func index(ctx *controller.Context) error {
+	Current, err := ctx.MustUser()
+	if err != nil { return err }
+
+	var User model.Users
+	storage.DB.Last(&User, Current.ID)
+	return ctx.Html(view.Profile(User))
+}
+
+func alerts(ctx *controller.Context) error {
+	var Body AlertsDto
+	var User model.Users
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	Current, err := ctx.MustUser()
+	if err != nil { return err }
+
+	storage.DB.Last(&User, Current.ID)
+	result := storage.DB.Model(&User).Updates(map[string]interface{}{
+		"AlertNewDevice": Body.NewDevice != "",
+		"AlertPasswordChange": Body.PasswordChange != "",
+		"AlertLockout": Body.Lockout != "",
+		"DigestWeekly": Body.Digest != "",
+	})
+
+	if result.Error != nil {
+		return ctx.String(http.StatusBadRequest, result.Error.Error())
+	}
+
+	return ctx.Html(view.ProfileAlerts(User))
+}
+
+func locale(ctx *controller.Context) error {
+	var Body LocaleDto
+	var User model.Users
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	if Body.Locale != "" && !i18n.IsSupported(Body.Locale) {
+		return ctx.String(http.StatusBadRequest, "Unsupported locale: " + Body.Locale)
+	}
+
+	Current, err := ctx.MustUser()
+	if err != nil { return err }
+
+	storage.DB.Last(&User, Current.ID)
+	if result := storage.DB.Model(&User).Update("locale", Body.Locale); result.Error != nil {
+		return ctx.String(http.StatusBadRequest, result.Error.Error())
+	}
+
+	ctx.Response().Header().Set("HX-Refresh", "true")
+	return ctx.Html(view.ProfileLocale(User))
+}
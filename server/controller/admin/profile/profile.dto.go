@@ -0,0 +1,12 @@
+package profile
+
+type AlertsDto struct {
+	NewDevice 		string 		`json:"newDevice" form:"newDevice"`
+	PasswordChange 	string 		`json:"passwordChange" form:"passwordChange"`
+	Lockout 		string 		`json:"lockout" form:"lockout"`
+	Digest 			string 		`json:"digest" form:"digest"`
+}
+
+type LocaleDto struct {
+	Locale 			string 		`json:"locale" form:"locale"`
+}
@@ -0,0 +1,13 @@
+package profile
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+)
+
+func Register(app *echo.Group) {
+	app.GET("/profile", controller.Register(index))
+	app.POST("/profile/alerts", controller.Register(alerts))
+	app.POST("/profile/locale", controller.Register(locale))
+}
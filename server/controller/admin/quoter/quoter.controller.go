@@ -0,0 +1,58 @@
+package quoter
+
+import (
+	"net/http"
+	"strconv"
+
+	"main/server/common/controller"
+	"main/server/service/quota"
+)
+
+// index answers the default quota, the roles' and every user's with their usage.
+func index(ctx *controller.Context) error {
+	Users, err := quota.Users()
+	if err != nil { return err }
+	Roles, err := quota.Roles()
+	if err != nil { return err }
+
+	return ctx.JSON(http.StatusOK, map[string]any{ "default": quota.Default(), "roles": Roles, "users": Users })
+}
+
+func user(ctx *controller.Context) error {
+	ID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil { return quota.ErrNotFound }
+
+	Quota, err := quota.Of(uint(ID))
+	if err != nil { return err }
+	return ctx.JSON(http.StatusOK, Quota)
+}
+
+// setUser gives a user their own quota, {"bytes": null} removes it. 0 bytes is unlimited.
+func setUser(ctx *controller.Context) error {
+	var Body QuotaDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	if err := quota.SetUser(Body.ID, Body.Bytes); err != nil { return err }
+
+	Quota, err := quota.Of(Body.ID)
+	if err != nil { return err }
+	return ctx.JSON(http.StatusOK, Quota)
+}
+
+// setRole sets the quota of each user of a role, {"bytes": null} removes it. 0 bytes is unlimited.
+func setRole(ctx *controller.Context) error {
+	var Body QuotaDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	if err := quota.SetRole(Body.ID, Body.Bytes); err != nil { return err }
+
+	Roles, err := quota.Roles()
+	if err != nil { return err }
+	return ctx.JSON(http.StatusOK, Roles)
+}
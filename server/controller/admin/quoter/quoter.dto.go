@@ -0,0 +1,6 @@
+package quoter
+
+type QuotaDto struct {
+	ID 			uint 		`param:"id"`
+	Bytes 		*int64 		`json:"bytes"`
+}
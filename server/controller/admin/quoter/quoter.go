@@ -0,0 +1,21 @@
+package quoter
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/module"
+	"main/server/middleware"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "quotas" }
+
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	Quotas := controller.Group(container.Admin.Group("/quotas", middleware.Can("files.quota")))
+	Quotas.GET("", index)
+	Quotas.GET("/users/:id", user)
+	Quotas.PUT("/users/:id", setUser)
+	Quotas.PUT("/roles/:id", setRole)
+}
@@ -1,12 +1,10 @@
 package abouter
 
 import (
-	"github.com/labstack/echo/v4"
-
 	"main/server/common/controller"
 )
 
-func Register(setting *echo.Group) {
-	setting.POST("/termer", controller.Register(Termer))
-	setting.POST("/abouter", controller.Register(Abouter))
+func Register(setting *controller.RouteGroup) {
+	setting.POST("/termer", Termer)
+	setting.POST("/abouter", Abouter)
 }
\ No newline at end of file
@@ -0,0 +1,34 @@
+package bandwidther
+
+import (
+	"fmt"
+	"main/build/view"
+	"main/server/common/bandwidth"
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+	"net/http"
+)
+
+// Bandwidther sets the site's low-bandwidth mode, other instances follow on their next full page load.
+func Bandwidther(ctx *controller.Context) error {
+	var Body BandwidtherDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	var Interface model.Interface
+	if err := storage.DB.Last(&Interface).Error; err != nil {
+		fmt.Print("No Interface: ", err)
+		return ctx.String(http.StatusNotFound, "")
+	}
+
+	Low := Body.Low != ""
+	if err := storage.DB.Model(&Interface).Update("low_bandwidth", Low).Error; err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	bandwidth.SetSite(Low)
+	return ctx.Html(view.Bandwidther(Low))
+}
@@ -0,0 +1,5 @@
+package bandwidther
+
+type BandwidtherDto struct {
+	Low 		string 		`json:"low"`
+}
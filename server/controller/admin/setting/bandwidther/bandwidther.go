@@ -0,0 +1,9 @@
+package bandwidther
+
+import (
+	"main/server/common/controller"
+)
+
+func Register(setting *controller.RouteGroup) {
+	setting.POST("/bandwidth", Bandwidther)
+}
@@ -1,15 +1,13 @@
 package brancher
 
 import (
-	"github.com/labstack/echo/v4"
-
 	"main/server/common/controller"
 )
 
-func Register(setting *echo.Group) {
+func Register(setting *controller.RouteGroup) {
 	brancher := setting.Group("/brancher")
-	brancher.POST("", controller.Register(BrancherNew))
-	brancher.POST("/:id", controller.Register(Brancher))
-	brancher.DELETE("/:id", controller.Register(BrancherRemove))
-	brancher.GET("/districts/:id/:district/:default", controller.Register(Districts))
+	brancher.POST("", BrancherNew)
+	brancher.POST("/:id", Brancher)
+	brancher.DELETE("/:id", BrancherRemove)
+	brancher.GET("/districts/:id/:district/:default", Districts)
 }
\ No newline at end of file
@@ -1,12 +1,10 @@
 package contacter
 
 import (
-	"github.com/labstack/echo/v4"
-
 	"main/server/common/controller"
 )
 
-func Register(setting *echo.Group) {
-	setting.POST("/socials", controller.Register(Socials))
-	setting.POST("/contacts", controller.Register(Contact))
+func Register(setting *controller.RouteGroup) {
+	setting.POST("/socials", Socials)
+	setting.POST("/contacts", Contact)
 }
\ No newline at end of file
@@ -1,14 +1,12 @@
 package faqers
 
 import (
-	"github.com/labstack/echo/v4"
-
 	"main/server/common/controller"
 )
 
-func Register(setting *echo.Group) {
+func Register(setting *controller.RouteGroup) {
 	faqers := setting.Group("/faqers")
-	faqers.POST("", controller.Register(FaqersNew))
-	faqers.POST("/:id", controller.Register(Faqers))
-	faqers.DELETE("/:id", controller.Register(FaqersRemove))
+	faqers.POST("", FaqersNew)
+	faqers.POST("/:id", Faqers)
+	faqers.DELETE("/:id", FaqersRemove)
 }
\ No newline at end of file
@@ -0,0 +1,41 @@
+package indexer
+
+import (
+	"net/http"
+	"strconv"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/model"
+	"main/server/service/indexer"
+)
+
+func Rules(ctx *controller.Context) error {
+	Rules, err := indexer.List()
+	if err != nil { return err }
+	return ctx.Html(view.Indexer(Rules))
+}
+
+// Save sets the indexing rule of a page or a section, the one the path had is replaced.
+func Save(ctx *controller.Context) error {
+	var Body IndexerDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	_, err := indexer.Save(model.Seo_rules{
+		Path: Body.Path, Section: Body.Section != "", NoIndex: Body.NoIndex != "", NoFollow: Body.NoFollow != "",
+		Canonical: Body.Canonical, Note: Body.Note,
+	})
+	if err != nil { return err }
+	return Rules(ctx)
+}
+
+func Remove(ctx *controller.Context) error {
+	ID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil { return indexer.ErrNotFound }
+
+	if err := indexer.Remove(uint(ID)); err != nil { return err }
+	return Rules(ctx)
+}
@@ -0,0 +1,10 @@
+package indexer
+
+type IndexerDto struct {
+	Path 		string 		`json:"path"`
+	Section 	string 		`json:"section"`
+	NoIndex 	string 		`json:"noindex"`
+	NoFollow 	string 		`json:"nofollow"`
+	Canonical 	string 		`json:"canonical"`
+	Note 		string 		`json:"note"`
+}
@@ -0,0 +1,11 @@
+package indexer
+
+import (
+	"main/server/common/controller"
+)
+
+func Register(setting *controller.RouteGroup) {
+	setting.GET("/indexer", Rules)
+	setting.POST("/indexer", Save)
+	setting.DELETE("/indexer/:id", Remove)
+}
@@ -5,8 +5,10 @@ import (
 	"main/build/view"
 	"main/server/common/controller"
 	uploader "main/server/common/helpers"
+	"main/server/common/route"
 	"main/server/common/storage"
 	"main/server/model"
+	"main/server/service/pinger"
 	"net/http"
 	"strconv"
 )
@@ -41,7 +43,7 @@ func Newser(ctx *controller.Context) error {
 
 	file, err := ctx.FormFile("Thumbnail")
 	if file != nil && err == nil {
-		Upload := uploader.File(file)
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "news"))
 		if !Upload.Success {
 			fmt.Print(Upload.Message) 
 			// return ctx.String(http.StatusBadRequest, Upload.Message)
@@ -55,6 +57,7 @@ func Newser(ctx *controller.Context) error {
 	if result.Error != nil {
 		return ctx.String(http.StatusBadRequest, result.Error.Error())
 	}
+	if Public { announce(ctx, New) }
 
 	var Newz []model.News
 	storage.DB.Order("created_at desc").Preload("Thumbnail").Find(&Newz)
@@ -84,7 +87,7 @@ func NewserNew(ctx *controller.Context) error {
 
 	file, err := ctx.FormFile("Thumbnail")
 	if file != nil && err == nil {
-		Upload := uploader.File(file)
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "news"))
 		if !Upload.Success {
 			fmt.Print(Upload.Message) 
 			return ctx.String(http.StatusBadRequest, Upload.Message)
@@ -101,6 +104,7 @@ func NewserNew(ctx *controller.Context) error {
 	if result.Error != nil {
 		return ctx.String(http.StatusBadRequest, result.Error.Error())
 	}
+	if Public { announce(ctx, Parameters) }
 
 	var Newz []model.News
 	storage.DB.Order("created_at desc").Preload("Thumbnail").Find(&Newz)
@@ -125,3 +129,10 @@ func NewserRemove(ctx *controller.Context) error {
 	storage.DB.Order("created_at desc").Preload("Thumbnail").Find(&Newz)
 	return ctx.Html(view.Newser(Newz))
 }
+
+/* Search engines are told about a news once it's public, failing to tell them doesn't fail the change */
+func announce(ctx *controller.Context, New model.News) {
+	if err := pinger.Published(storage.DB, route.URL("news.detail", New.Ref(New.ID)), route.URL("news")); err != nil {
+		ctx.Log("Pinging search engines: ", err)
+	}
+}
@@ -1,14 +1,12 @@
 package newser
 
 import (
-	"github.com/labstack/echo/v4"
-
 	"main/server/common/controller"
 )
 
-func Register(setting *echo.Group) {
+func Register(setting *controller.RouteGroup) {
 	newser := setting.Group("/newser")
-	newser.POST("", controller.Register(NewserNew))
-	newser.POST("/:id", controller.Register(Newser))
-	newser.DELETE("/:id", controller.Register(NewserRemove))
+	newser.POST("", NewserNew)
+	newser.POST("/:id", Newser)
+	newser.DELETE("/:id", NewserRemove)
 }
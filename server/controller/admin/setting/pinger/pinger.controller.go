@@ -0,0 +1,29 @@
+package pinger
+
+import (
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/service/pinger"
+	"net/http"
+)
+
+// Pinger enables or disables telling a search engine about published pages.
+func Pinger(ctx *controller.Context) error {
+	var Body PingerDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	if err := pinger.Toggle(Body.Engine, Body.Enabled != ""); err != nil { return err }
+	return ctx.Html(view.Pinger(Engines()))
+}
+
+// Engines returns the search engines as the indexing tab shows them.
+func Engines() []view.PingEngine {
+	Engines := []view.PingEngine{}
+	for _, Status := range pinger.Statuses() {
+		Engines = append(Engines, view.PingEngine{ Name: Status.Name, Label: Status.Label, Enabled: Status.Enabled, Ready: Status.Ready })
+	}
+	return Engines
+}
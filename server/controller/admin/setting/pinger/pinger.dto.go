@@ -0,0 +1,6 @@
+package pinger
+
+type PingerDto struct {
+	Engine 		string 		`param:"engine"`
+	Enabled 	string 		`json:"enabled"`
+}
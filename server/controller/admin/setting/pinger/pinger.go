@@ -0,0 +1,9 @@
+package pinger
+
+import (
+	"main/server/common/controller"
+)
+
+func Register(setting *controller.RouteGroup) {
+	setting.POST("/pinger/:engine", Pinger)
+}
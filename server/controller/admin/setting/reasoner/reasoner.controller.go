@@ -37,7 +37,7 @@ func Reasoner(ctx *controller.Context) error {
 
 	file, err := ctx.FormFile("Icon")
 	if file != nil && err == nil {
-		Upload := uploader.File(file)
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "reason"))
 		if !Upload.Success {
 			fmt.Print(Upload.Message) 
 			return ctx.String(http.StatusBadRequest, Upload.Message)
@@ -74,7 +74,7 @@ func ReasonerNew(ctx *controller.Context) error {
 
 	file, err := ctx.FormFile("Icon")
 	if file != nil && err == nil {	
-		Upload := uploader.File(file)
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "reason"))
 		if !Upload.Success {
 			fmt.Print("File did not upload -> " + Upload.Message) 
 			// return ctx.String(http.StatusBadRequest, Upload.Message)
@@ -1,14 +1,12 @@
 package reasoner
 
 import (
-	"github.com/labstack/echo/v4"
-
 	"main/server/common/controller"
 )
 
-func Register(setting *echo.Group) {
+func Register(setting *controller.RouteGroup) {
 	reasoner := setting.Group("/reasoner")
-	reasoner.POST("", controller.Register(ReasonerNew))
-	reasoner.POST("/:id", controller.Register(Reasoner))
-	reasoner.DELETE("/:id", controller.Register(ReasonerRemove))
+	reasoner.POST("", ReasonerNew)
+	reasoner.POST("/:id", Reasoner)
+	reasoner.DELETE("/:id", ReasonerRemove)
 }
@@ -0,0 +1,20 @@
+package searcher
+
+import (
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/service/searcher"
+	"net/http"
+)
+
+// Searcher saves the search analyzer settings of a locale, they apply to the next search.
+func Searcher(ctx *controller.Context) error {
+	var Body SearcherDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	if err := searcher.Save(Body.Locale, Body.Stemming != "", Body.Stopwords, Body.Synonyms); err != nil { return err }
+	return ctx.Html(view.Searcher(searcher.Analyzers()))
+}
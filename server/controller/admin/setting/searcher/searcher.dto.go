@@ -0,0 +1,8 @@
+package searcher
+
+type SearcherDto struct {
+	Locale 		string 		`param:"locale"`
+	Stemming 	string 		`json:"stemming"`
+	Stopwords 	string 		`json:"stopwords"`
+	Synonyms 	string 		`json:"synonyms"`
+}
@@ -0,0 +1,9 @@
+package searcher
+
+import (
+	"main/server/common/controller"
+)
+
+func Register(setting *controller.RouteGroup) {
+	setting.POST("/searcher/:locale", Searcher)
+}
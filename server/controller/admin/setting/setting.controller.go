@@ -5,7 +5,9 @@ import (
 	"main/build/view"
 	"main/server/common/controller"
 	"main/server/common/storage"
+	"main/server/controller/admin/setting/pinger"
 	"main/server/model"
+	"main/server/service/searcher"
 	"net/http"
 
 	"gorm.io/gorm"
@@ -34,11 +36,12 @@ func index(ctx *controller.Context) error {
 		Preload("About").
 		Preload("Contact").
 		Preload("SocialMedia.Icon").
+		Preload("Mail.Logo").
 		Last(&Interface)
 	
 	if result.Error != nil {
 		fmt.Print(result.Error.Error())
-		return ctx.Html(view.ErrorPage())
+		return ctx.Html(view.ErrorPage(ctx.RequestID()))
 	}
 
 	var Faq []model.Faq
@@ -53,5 +56,5 @@ func index(ctx *controller.Context) error {
 	var Branches []model.Branches
 	storage.DB.Order("created_at desc").Preload("District.City").Find(&Branches)
 
-	return ctx.Html(view.Setting(Interface, Faq, Newz, Branches, Cities, Current.Tab))
+	return ctx.Html(view.Setting(Interface, Faq, Newz, Branches, Cities, searcher.Analyzers(), pinger.Engines(), Current.Tab))
 }
@@ -5,25 +5,37 @@ import (
 
 	"main/server/common/controller"
 	"main/server/controller/admin/setting/abouter"
+	"main/server/controller/admin/setting/bandwidther"
 	"main/server/controller/admin/setting/brancher"
 	"main/server/controller/admin/setting/contacter"
 	"main/server/controller/admin/setting/faqers"
+	"main/server/controller/admin/setting/indexer"
 	"main/server/controller/admin/setting/newser"
+	"main/server/controller/admin/setting/pinger"
 	"main/server/controller/admin/setting/reasoner"
+	"main/server/controller/admin/setting/searcher"
 	"main/server/controller/admin/setting/slideshower"
+	"main/server/controller/admin/setting/themer"
+	"main/server/controller/admin/setting/versioner"
 )
 
 func Register(admin *echo.Group) {
 	admin.GET("/settings", controller.Register(index))
 	admin.GET("/settings/:tab", controller.Register(index))
 
-	setting := admin.Group("/setting")
+	setting := controller.Group(admin.Group("/setting"), controller.WithMiddleware(versioner.Recorded()))
 
 	abouter.Register(setting)
+	bandwidther.Register(setting)
 	brancher.Register(setting)
 	contacter.Register(setting)
 	faqers.Register(setting)
+	indexer.Register(setting)
 	newser.Register(setting)
+	pinger.Register(setting)
 	reasoner.Register(setting)
+	searcher.Register(setting)
 	slideshower.Register(setting)
+	themer.Register(setting)
+	versioner.Register(setting)
 }
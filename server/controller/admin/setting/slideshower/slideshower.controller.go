@@ -37,7 +37,7 @@ func Slideshower(ctx *controller.Context) error {
 
 	file, err := ctx.FormFile("Pic")
 	if file != nil && err == nil {	
-		Upload := uploader.File(file)
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "slideshow"))
 		if !Upload.Success {
 			fmt.Print(Upload.Message) 
 			return ctx.String(http.StatusBadRequest, Upload.Message)
@@ -77,7 +77,7 @@ func SlideshowerNew(ctx *controller.Context) error {
 
 	file, err := ctx.FormFile("Pic")
 	if file != nil && err == nil {	
-		Upload := uploader.File(file)
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "slideshow"))
 		if !Upload.Success {
 			fmt.Print("File did not upload -> " + Upload.Message) 
 			// return ctx.String(http.StatusBadRequest, Upload.Message)
@@ -1,14 +1,12 @@
 package slideshower
 
 import (
-	"github.com/labstack/echo/v4"
-
 	"main/server/common/controller"
 )
 
-func Register(setting *echo.Group) {
+func Register(setting *controller.RouteGroup) {
 	slideshower := setting.Group("/slideshower")
-	slideshower.POST("", controller.Register(SlideshowerNew))
-	slideshower.POST("/:id", controller.Register(Slideshower))
-	slideshower.DELETE("/:id", controller.Register(SlideRemove))
+	slideshower.POST("", SlideshowerNew)
+	slideshower.POST("/:id", Slideshower)
+	slideshower.DELETE("/:id", SlideRemove)
 }
@@ -0,0 +1,82 @@
+package themer
+
+import (
+	"fmt"
+	"main/build/view"
+	"main/server/common/controller"
+	uploader "main/server/common/helpers"
+	"main/server/common/storage"
+	"main/server/model"
+	mailer "main/server/service/mail"
+	"net/http"
+	"strings"
+)
+
+func Themer(ctx *controller.Context) error {
+	var Body ThemerDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	/* Empty colors fall back to the default theme, anything else must be a hex color */
+	for _, Color := range []string{Body.Primary, Body.Background, Body.Text} {
+		if Color != "" && !mailer.IsColor(Color) {
+			return ctx.String(http.StatusBadRequest, "ფერი უნდა იყოს ფორმატით #RRGGBB")
+		}
+	}
+
+	var Interface model.Interface
+	if err := storage.DB.Last(&Interface).Error; err != nil {
+		fmt.Print("No Interface: ", err)
+		return ctx.String(http.StatusNotFound, "")
+	}
+
+	var Mail model.Interface_mail
+	storage.DB.Where("interface_id = ?", Interface.ID).Last(&Mail)
+
+	Mail.InterfaceID = Interface.ID
+	Mail.Primary = Body.Primary
+	Mail.Background = Body.Background
+	Mail.Text = Body.Text
+	Mail.Footer = strings.TrimSpace(Body.Footer)
+
+	file, err := ctx.FormFile("Logo")
+	if file != nil && err == nil {
+		Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.FileFor(file, "mail"))
+		if !Upload.Success {
+			fmt.Print(Upload.Message)
+			return ctx.String(http.StatusBadRequest, Upload.Message)
+		}
+		Mail.LogoID = &Upload.ID
+	}
+
+	if err := storage.DB.Omit("Logo").Save(&Mail).Error; err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	storage.DB.Preload("Logo").Last(&Mail, Mail.ID)
+	return ctx.Html(view.MailThemer(Mail))
+}
+
+/* Rendered into the settings iframe, with sample data in place of a real recipient */
+func Preview(ctx *controller.Context) error {
+	var Query PreviewDto
+
+	if err := ctx.Bind(&Query); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	Sample := view.SubscribeMail()
+	switch Query.Mail {
+	case "security":
+		Sample = view.SecurityMail("სახელი გვარი", "ახალი შესვლა", "თქვენს ანგარიშზე შესრულდა შესვლა ახალი მოწყობილობიდან.", []string{"IP: 127.0.0.1"})
+	case "digest":
+		Sample = view.DigestMail("სახელი გვარი", []string{"ახალი პროდუქტები: 3", "ახალი გამომწერები: 12"})
+	}
+
+	Body, err := mailer.Render(ctx.Request().Context(), Sample)
+	if err != nil { return err }
+
+	return ctx.HTMLBlob(http.StatusOK, []byte(Body))
+}
@@ -0,0 +1,12 @@
+package themer
+
+type ThemerDto struct {
+	Primary			string       `form:"primary"`
+	Background		string       `form:"background"`
+	Text			string       `form:"text"`
+	Footer			string       `form:"footer"`
+}
+
+type PreviewDto struct {
+	Mail			string       `query:"mail"`
+}
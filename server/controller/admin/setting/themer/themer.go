@@ -0,0 +1,10 @@
+package themer
+
+import (
+	"main/server/common/controller"
+)
+
+func Register(setting *controller.RouteGroup) {
+	setting.POST("/mail", Themer)
+	setting.GET("/mail/preview", Preview)
+}
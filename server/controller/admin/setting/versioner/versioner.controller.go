@@ -0,0 +1,65 @@
+package versioner
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/service/versioner"
+)
+
+// Shown is how many versions the settings tab lists.
+const Shown = 50
+
+func Versions(ctx *controller.Context) error {
+	Versions, err := versioner.List(Shown)
+	if err != nil { return err }
+	return ctx.Html(view.Versioner(Versions))
+}
+
+// Preview opens a preview channel with the version's settings and sends the browser into it (GET /preview/:token),
+// publishing the channel from /admin/preview applies them.
+func Preview(ctx *controller.Context) error {
+	Number, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil { return versioner.ErrNotFound }
+
+	Channel, err := versioner.Preview(Number)
+	if err != nil { return err }
+
+	if ctx.Htmx().Request {
+		ctx.Response().Header().Set("HX-Redirect", "/preview/" + Channel.Token)
+		return ctx.NoContent(http.StatusOK)
+	}
+	return ctx.Redirect(http.StatusSeeOther, "/preview/" + Channel.Token)
+}
+
+func Restore(ctx *controller.Context) error {
+	Number, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil { return versioner.ErrNotFound }
+
+	var UsersID *uint
+	if User, ok := ctx.CurrentUser(); ok { UsersID = &User.ID }
+
+	if _, err := versioner.Restore(Number, UsersID); err != nil { return err }
+	return Versions(ctx)
+}
+
+// Recorded keeps the settings as a new version after each change made through the settings routes,
+// a request which didn't change them records nothing (see versioner.Record).
+func Recorded() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			if err := next(ctx); err != nil || ctx.Request().Method == http.MethodGet { return err }
+			if ctx.Response().Status >= http.StatusBadRequest { return nil }
+
+			var UsersID *uint
+			if User, ok := ctx.CurrentUser(); ok { UsersID = &User.ID }
+			if _, err := versioner.Record(storage.DB, UsersID, ctx.Path()); err != nil { ctx.Log("Recording settings version: ", err) }
+			return nil
+		})
+	}
+}
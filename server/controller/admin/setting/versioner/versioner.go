@@ -0,0 +1,11 @@
+package versioner
+
+import (
+	"main/server/common/controller"
+)
+
+func Register(setting *controller.RouteGroup) {
+	setting.GET("/versions", Versions)
+	setting.POST("/versions/:version/preview", Preview)
+	setting.POST("/versions/:version/restore", Restore)
+}
@@ -0,0 +1,51 @@
+package tasker
+
+import (
+	"net/http"
+	"strconv"
+
+	"main/build/view"
+	"main/server/common/controller"
+	uploader "main/server/common/helpers"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/tasks"
+)
+
+// TasksSize is how many of the latest tasks the page shows.
+const TasksSize = 50
+
+func index(ctx *controller.Context) error {
+	Tasks, err := latest(ctx)
+	if err != nil { return err }
+	return ctx.Html(view.Tasks(Tasks))
+}
+
+/* What the page polls while a task runs, the list stops polling once they're all finished */
+func list(ctx *controller.Context) error {
+	Tasks, err := latest(ctx)
+	if err != nil { return err }
+	return ctx.Renders(http.StatusOK, view.TasksList(Tasks))
+}
+
+func latest(ctx *controller.Context) ([]model.Tasks, error) {
+	User, err := ctx.MustUser()
+	if err != nil { return nil, err }
+	return tasks.List(User.ID, TasksSize)
+}
+
+/* The file a finished task made, to the user who started it */
+func download(ctx *controller.Context) error {
+	User, err := ctx.MustUser()
+	if err != nil { return err }
+
+	ID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil { return tasks.ErrNotFound }
+	Task, err := tasks.Find(User.ID, uint(ID))
+	if err != nil { return err }
+	if Task.FileID == nil { return uploader.ErrNotFound }
+
+	var File model.Files
+	if err := storage.DB.First(&File, *Task.FileID).Error; err != nil { return uploader.ErrNotFound }
+	return ctx.Download(File, controller.Attachment())
+}
@@ -0,0 +1,23 @@
+package tasker
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/module"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "tasks" }
+
+/* Every admin sees their own tasks only */
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	Tasks := controller.Group(container.Admin.Group("/tasks"), controller.RequireUser())
+	Tasks.GET("", index)
+	Tasks.GET("/list", list)
+	Tasks.GET("/:id/download", download)
+
+	container.AdminRoute(view.AdminRoute{ Path: "/tasks", Name: "ფონური დავალებები", Slug: "tasks", Icon: view.SettingsIcon() })
+}
@@ -0,0 +1,31 @@
+package telemeter
+
+import (
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/globals"
+	"main/server/service/telemetry"
+)
+
+/* The modules registered, read once every module is */
+var modules = func() []string { return nil }
+
+func index(ctx *controller.Context) error {
+	Summary, err := telemetry.Summarize(modules())
+	if err != nil { return err }
+
+	return ctx.Html(view.Telemetry(view.TelemetryPage{
+		Enabled: Summary.Enabled,
+		Days: telemetry.Window,
+		Retention: globals.Env.TELEMETRY_DAYS,
+		Routes: usages(Summary.Routes),
+		Modules: usages(Summary.Modules),
+		Uploads: usages(Summary.Uploads),
+	}))
+}
+
+func usages(Usages []telemetry.Usage) []view.TelemetryUsage {
+	Result := []view.TelemetryUsage{}
+	for _, Usage := range Usages { Result = append(Result, view.TelemetryUsage{ Name: Usage.Name, Count: Usage.Count, Days: Usage.Days }) }
+	return Result
+}
@@ -0,0 +1,23 @@
+package telemeter
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/module"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "telemetry" }
+
+/* The page is there when telemetry is off too, it tells how to turn it on */
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	modules = container.Names
+
+	Telemetry := controller.Group(container.Admin.Group("/telemetry"), controller.RequirePermission("telemetry.view"))
+	Telemetry.GET("", index)
+
+	container.AdminRoute(view.AdminRoute{ Path: "/telemetry", Name: "ტელემეტრია", Slug: "telemetry", Icon: view.SettingsIcon() })
+}
@@ -0,0 +1,48 @@
+package traffic
+
+import (
+	"strconv"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/domain"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/analytics"
+)
+
+var ErrKind = domain.UnsupportedType("unknown page kind")
+
+/* The public detail route of each kind of content, whose pages are counted by their record's identifier */
+var routes = map[string]string{
+	"products": "products.detail",
+	"news": "news.detail",
+}
+
+func page(ctx *controller.Context) error {
+	Route, ok := routes[ctx.Param("kind")]
+	if !ok { return ErrKind }
+
+	ID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil { return domain.NotFound("record not found") }
+
+	/* Views are counted under the ID (ctx.Viewed), earlier ones under the identifier of the url: the public ID too */
+	var External model.External
+	switch ctx.Param("kind") {
+		case "products":
+			var Product model.Products
+			if err := storage.DB.First(&Product, ID).Error; err != nil { return domain.NotFound("product not found") }
+			External = Product.External
+		case "news":
+			var News model.News
+			if err := storage.DB.First(&News, ID).Error; err != nil { return domain.NotFound("news not found") }
+			External = News.External
+	}
+
+	Stats, err := analytics.Page(Route, External.Ref(uint(ID)), strconv.Itoa(ID))
+	if err != nil { return err }
+
+	return ctx.Html(view.PageViews(view.PageStats{
+		Views: Stats.Views, Fragments: Stats.Fragments, LastViewedAt: Stats.LastViewedAt, Daily: Stats.Daily, Days: analytics.Window,
+	}))
+}
@@ -0,0 +1,18 @@
+package traffic
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/module"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "traffic" }
+
+/* Read from the edit screens, which load it into their PageViewsLoader */
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	Traffic := controller.Group(container.Admin.Group("/traffic"), controller.RequireUser())
+	Traffic.GET("/:kind/:id", page)
+}
@@ -0,0 +1,69 @@
+package trasher
+
+import (
+	"errors"
+	"strconv"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/globals"
+	uploader "main/server/common/helpers"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/trash"
+)
+
+func index(ctx *controller.Context) error {
+	return page(ctx, ctx.QueryParam("kind"), "")
+}
+
+/* A restored file's history tells it was brought back, a purged one's that it was deleted */
+func restore(ctx *controller.Context) error {
+	Trashed, ID, ok := target(ctx)
+	if !ok { return trash.ErrNotFound }
+
+	err := Trashed.Restore(ID)
+	if errors.Is(err, storage.ErrRestoreConflict) { return page(ctx, Trashed.Name, "აღდგენა შეუძლებელია: იგივე სახელით ან მისამართით ჩანაწერი უკვე არსებობს") }
+	if err != nil { return err }
+
+	if Trashed.Name == "files" { uploader.Audit(ctx.Actor(), ID, model.FileUntrashed, "") }
+	return page(ctx, Trashed.Name, "")
+}
+
+func purge(ctx *controller.Context) error {
+	Trashed, ID, ok := target(ctx)
+	if !ok { return trash.ErrNotFound }
+
+	if err := Trashed.Purge(ctx.Request().Context(), ID); err != nil {
+		if errors.Is(err, trash.ErrNotFound) { return err }
+		ctx.Log("Purging ", Trashed.Name, " ", ID, ": ", err)
+		return page(ctx, Trashed.Name, "სამუდამოდ წაშლა შეუძლებელია: ჩანაწერს სხვა ჩანაწერები იყენებს")
+	}
+
+	if Trashed.Name == "files" { uploader.Audit(ctx.Actor(), ID, model.FileDeleted, "") }
+	return page(ctx, Trashed.Name, "")
+}
+
+func target(ctx *controller.Context) (trash.Kind, uint, bool) {
+	Trashed, ok := trash.Find(ctx.Param("kind"))
+	ID, err := strconv.ParseUint(ctx.Param("id"), 10, 0)
+	return Trashed, uint(ID), ok && err == nil
+}
+
+/* The trash of a kind, the first one's when it's unknown */
+func page(ctx *controller.Context, Name string, Problem string) error {
+	Current, ok := trash.Find(Name)
+	if !ok { Current = trash.Kinds[0] }
+
+	Page := view.TrashPage{ Current: Current.Name, Days: globals.Env.TRASH_DAYS, Problem: Problem }
+	for _, Trashed := range trash.Kinds {
+		Count, err := Trashed.Count()
+		if err != nil { return err }
+		Page.Kinds = append(Page.Kinds, view.TrashKind{ Name: Trashed.Name, Label: Trashed.Label, Count: int(Count) })
+	}
+
+	Items, err := Current.List()
+	if err != nil { return err }
+	for _, Item := range Items { Page.Items = append(Page.Items, view.TrashItem{ ID: Item.ID, Title: Item.Title, DeletedAt: Item.DeletedAt }) }
+	return ctx.Html(view.Trash(Page))
+}
@@ -0,0 +1,26 @@
+package trasher
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/globals"
+	"main/server/common/module"
+	"main/server/service/trash"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "trash" }
+
+/* Without TRASH_DAYS trashed rows stay until they're purged here */
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	Trash := controller.Group(container.Admin.Group("/trash"), controller.RequirePermission("trash.manage"))
+	Trash.GET("", index)
+	Trash.POST("/:kind/:id/restore", restore)
+	Trash.DELETE("/:kind/:id", purge)
+
+	if globals.Env.TRASH_DAYS > 0 { container.Cron("trash.purge", trash.PurgeEvery, trash.PurgeExpired) }
+	container.AdminRoute(view.AdminRoute{ Path: "/trash", Name: "სანაგვე", Slug: "trash", Icon: view.DeleteIcon() })
+}
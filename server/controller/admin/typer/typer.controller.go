@@ -0,0 +1,105 @@
+package typer
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/a-h/templ"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/filetypes"
+)
+
+func index(ctx *controller.Context) error {
+	return ctx.Html(view.FileTypes(filetypes.All(), ""))
+}
+
+func indexByID(ctx *controller.Context) error {
+	var Type model.File_types
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+
+	if result := storage.DB.First(&Type, ID); result.Error != nil {
+		return ctx.String(http.StatusNotFound, result.Error.Error())
+	}
+
+	return ctx.Html(view.UpdateFileType(Type))
+}
+
+func create(ctx *controller.Context) error {
+	var Body FileTypeDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	Type := Body.fileType()
+	if err := filetypes.Save(&Type); err != nil {
+		return ctx.Html(view.FileTypes(filetypes.All(), err.Error()))
+	}
+
+	return ctx.Html(view.FileTypes(filetypes.All(), ""))
+}
+
+func update(ctx *controller.Context) error {
+	var Body FileTypeDto
+	var Type model.File_types
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	if result := storage.DB.First(&Type, Body.ID); result.Error != nil {
+		return ctx.String(http.StatusNotFound, "")
+	}
+
+	Updated := Body.fileType()
+	Updated.Model = Type.Model
+	if err := filetypes.Save(&Updated); err != nil {
+		return ctx.Html(view.FileTypes(filetypes.All(), err.Error()))
+	}
+
+	return ctx.Html(view.FileTypes(filetypes.All(), ""))
+}
+
+func toggle(ctx *controller.Context) error {
+	var Type model.File_types
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+
+	if result := storage.DB.First(&Type, ID); result.Error != nil {
+		return ctx.String(http.StatusNotFound, "")
+	}
+
+	Type.Enabled = !Type.Enabled
+	if err := filetypes.Save(&Type); err != nil {
+		return ctx.Html(view.FileTypes(filetypes.All(), err.Error()))
+	}
+
+	return ctx.Html(view.FileTypes(filetypes.All(), ""))
+}
+
+func remove(ctx *controller.Context) error {
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+
+	if err := filetypes.Remove(uint(ID)); err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	return ctx.Html(templ.NopComponent)
+}
+
+/* The form takes the size in megabytes, File_types keeps bytes. Thumbnails and Scan are checkboxes, sent when checked */
+func (Body FileTypeDto) fileType() model.File_types {
+	return model.File_types{
+		Name: Body.Name,
+		Ext: Body.Ext,
+		Mimes: Body.Mimes,
+		Max_size: int(Body.MaxSize * 1024 * 1024),
+		Contexts: Body.Contexts,
+		Enabled: Body.Enabled != "false",
+		Thumbnails: Body.Thumbnails == "true",
+		Scan: Body.Scan == "true",
+	}
+}
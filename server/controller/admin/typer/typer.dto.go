@@ -0,0 +1,13 @@
+package typer
+
+type FileTypeDto struct {
+	ID 			uint 		`param:"id" form:"id"`
+	Name 		string 		`form:"Name"`
+	Ext 		string 		`form:"Ext"`
+	Mimes 		string 		`form:"Mimes"`
+	MaxSize 	float64 	`form:"MaxSize"`
+	Contexts 	string 		`form:"Contexts"`
+	Enabled 	string 		`form:"Enabled"`
+	Thumbnails 	string 		`form:"Thumbnails"`
+	Scan 		string 		`form:"Scan"`
+}
@@ -0,0 +1,25 @@
+package typer
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/module"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "filetypes" }
+
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	Types := controller.Group(container.Admin.Group("/filetypes"))
+	Types.GET("", index)
+	Types.GET("/:id", indexByID)
+	Types.POST("", create)
+	Types.PUT("", update)
+	Types.PATCH("/:id", toggle)
+	Types.DELETE("/:id", remove)
+
+	container.AdminRoute(view.AdminRoute{ Path: "/filetypes", Name: "ფაილის ტიპები", Slug: "filetypes", Icon: view.SettingsIcon() })
+}
@@ -7,5 +7,5 @@ import (
 )
 
 func Register(app *echo.Echo) {
-	app.GET("/branches", controller.Register(index))
+	app.GET("/branches", controller.RegisterNamed("branches", index))
 }
\ No newline at end of file
@@ -0,0 +1,29 @@
+package callbacks
+
+import (
+	"net/http"
+
+	"main/server/common/controller"
+	"main/server/common/ids"
+	"main/server/common/module"
+	"main/server/model"
+	"main/server/service/transcoder"
+)
+
+/* Runs a declared job now and waits for it, the caller learns whether it failed */
+func job(ctx *controller.Context, container *module.Container) error {
+	if err := container.Run(ctx.Request().Context(), ctx.Param("name")); err != nil { return err }
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+/* Queues the video's processing again: a transcoder's retry, or videos uploaded before ffmpeg was installed */
+func transcode(ctx *controller.Context) error {
+	var File model.Files
+	if result := ctx.DB().Scopes(ids.Match(ctx.Param("id"))).First(&File); result.Error != nil {
+		return ctx.String(http.StatusNotFound, "File not found")
+	}
+	if !transcoder.IsVideo(File) { return ctx.String(http.StatusUnprocessableEntity, "File is not a video") }
+
+	transcoder.Enqueue(&File)
+	return ctx.NoContent(http.StatusAccepted)
+}
@@ -0,0 +1,18 @@
+package callbacks
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/module"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "callbacks" }
+
+/* Called by other services (schedulers, an external transcoder), the internal group only takes signed requests */
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	container.Internal.POST("/jobs/:name", controller.Register(func(ctx *controller.Context) error { return job(ctx, container) }))
+	container.Internal.POST("/transcoder/:id", controller.Register(transcode))
+}
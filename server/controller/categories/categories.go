@@ -7,5 +7,5 @@ import (
 )
 
 func Register(app *echo.Echo) {
-	app.GET("/categories", controller.Register(index))
+	app.GET("/categories", controller.RegisterNamed("categories", index))
 }
\ No newline at end of file
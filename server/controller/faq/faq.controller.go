@@ -10,5 +10,5 @@ import (
 func index(ctx *controller.Context) error {
 	var Faq []model.Faq
 	storage.DB.Find(&Faq)
-	return ctx.Html(view.Faq(Faq))
+	return ctx.HtmlWithCache(view.Faq(Faq), controller.PublicMaxAge)
 }
\ No newline at end of file
@@ -7,5 +7,5 @@ import (
 )
 
 func Register(app *echo.Echo) {
-	app.GET("/faq", controller.Register(index))
+	app.GET("/faq", controller.RegisterNamed("faq", index))
 }
\ No newline at end of file
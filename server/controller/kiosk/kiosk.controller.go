@@ -0,0 +1,80 @@
+package kiosk
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/a-h/templ"
+	"gorm.io/gorm"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/model"
+	"main/server/service/kiosk"
+)
+
+/* The display was found by middleware.Kiosk, its page is rendered read-only and reloads itself every Refresh seconds */
+func show(ctx *controller.Context) error {
+	Display := ctx.Get("KIOSK").(model.Kiosk_displays)
+
+	var Page templ.Component
+	switch Display.Page {
+		case model.KioskBranches:
+			var Branches []model.Branches
+			ctx.DB().Preload("District.City").Preload("Shifts").Order("name").Find(&Branches)
+			Page = view.KioskBranches(Branches)
+		case model.KioskNews:
+			var News []model.News
+			ctx.DB().Where(&model.News{ Public: true }).Order("news.created_at desc").Limit(6).Preload("Thumbnail.Thumbnails").Preload("Thumbnail.Metadata").Find(&News)
+			Page = view.KioskNews(News)
+		case model.KioskMenu:
+			var Categories []model.Categories
+			var Products []model.Products
+			ctx.DB().Where(&model.Categories{ Public: true }).Order("name").Find(&Categories)
+			ctx.DB().Where(&model.Products{ Public: true }).Order("name").Find(&Products)
+			Page = view.KioskMenu(Categories, Products)
+		case model.KioskSlideshow:
+			var Interface model.Interface
+			ctx.DB().Preload("SlideShow", func(db *gorm.DB) *gorm.DB {
+				return db.Order("interface_slide_shows.index ASC").Preload("Pic.Thumbnails").Preload("Pic.Metadata").Preload("Pic.Renditions")
+			}).Last(&Interface)
+			Page = view.KioskSlideshow(Interface.SlideShow)
+		default:
+			return ctx.NoContent(http.StatusNotFound)
+	}
+
+	return ctx.Html(view.KioskScreen(Display, Page))
+}
+
+func index(ctx *controller.Context) error {
+	Displays, err := kiosk.List()
+	if err != nil { return err }
+	return ctx.Html(view.KioskDisplays(Displays, ""))
+}
+
+func create(ctx *controller.Context) error {
+	var Body KioskDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	Message := ""
+	if _, err := kiosk.Create(Body.Name, Body.Page, Body.Refresh, Body.Ancestors); err != nil { Message = err.Error() }
+
+	Displays, err := kiosk.List()
+	if err != nil { return err }
+	return ctx.Html(view.KioskDisplays(Displays, Message))
+}
+
+func remove(ctx *controller.Context) error {
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+
+	if err := kiosk.Remove(uint(ID)); err != nil {
+		if errors.Is(err, kiosk.ErrNotFound) { return ctx.String(http.StatusNotFound, err.Error()) }
+		return err
+	}
+
+	return ctx.Html(templ.NopComponent)
+}
@@ -0,0 +1,8 @@
+package kiosk
+
+type KioskDto struct {
+	Name 		string 		`form:"Name"`
+	Page 		string 		`form:"Page"`
+	Refresh 	int 		`form:"Refresh"`
+	Ancestors 	string 		`form:"Ancestors"`
+}
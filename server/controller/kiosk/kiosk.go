@@ -0,0 +1,28 @@
+package kiosk
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/module"
+	"main/server/middleware"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "kiosk" }
+
+/* Display routes are left unnamed, Analytics doesn't count the reloads of screens as visits */
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	app.Pre(middleware.Cookieless("/display/"))
+	Displays := controller.Group(app.Group("/display"), controller.WithMiddleware(middleware.Kiosk()), controller.WithLayout(view.Kiosk))
+	Displays.GET("/:token", show)
+
+	Kiosks := controller.Group(container.Admin.Group("/kiosks"), controller.RequirePermission("kiosk.manage"))
+	Kiosks.GET("", index)
+	Kiosks.POST("", create)
+	Kiosks.DELETE("/:id", remove)
+
+	container.AdminRoute(view.AdminRoute{ Path: "/kiosks", Name: "ეკრანები", Slug: "kiosks", Icon: view.SettingsIcon() })
+}
@@ -1,30 +1,29 @@
 package landing
 
 import (
-	"log"
-
 	"gorm.io/gorm"
 
 	"main/build/view"
 	"main/server/common/controller"
-	"main/server/common/storage"
 	"main/server/model"
 	mailer "main/server/service/mail"
+	"main/server/service/outbox"
 )
 
 func index(ctx *controller.Context) error {
 	var Interface model.Interface
 
-	storage.DB.
+	ctx.DB().
 	Preload("Contact").
-	Preload("News.Thumbnail").
-	Preload("Reasons.Icon").
+	Preload("News.Thumbnail.Thumbnails").
+	Preload("News.Thumbnail.Metadata").
+	Preload("Reasons.Icon.Metadata").
 	Preload("SlideShow", func(db *gorm.DB) *gorm.DB {
-		return db.Order("interface_slide_shows.index ASC").Preload("Pic")
+		return db.Order("interface_slide_shows.index ASC").Preload("Pic.Thumbnails").Preload("Pic.Metadata").Preload("Pic.Renditions")
 	}).
 	Last(&Interface)
 
-	return ctx.Html(view.Landing(Interface))
+	return ctx.HtmlWithCache(view.Landing(Interface), controller.PublicMaxAge)
 }
 
 func subscribe(ctx *controller.Context) error {
@@ -34,20 +33,15 @@ func subscribe(ctx *controller.Context) error {
 		return err
 	}
 
-	Result, _ := mailer.Send(mailer.Config{
-		To: Form.Address,
-		Subject: "მადლობა გამოწერისთვის",
-		Body: "მადლობა გამოწერისთვის, იხილეთ პროდუქცია ჩვენს ვებ გვერდზე. ექსკლუზიურ სიახლეებს მიიღებთ ელ ფოსტის საშუალებით",
-	})
-
-	var Subscriber model.Subscribes = model.Subscribes{
-		Email: Form.Address,
-	}
+	Body, err := mailer.Render(ctx.Request().Context(), view.SubscribeMail())
+	if err != nil { return err }
 
-	storage.DB.Create(&Subscriber)
+	/* The mail is sent once the subscription is saved, and only then */
+	err = ctx.Tx(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.Subscribes{ Email: Form.Address }).Error; err != nil { return err }
+		return outbox.Mail(tx, mailer.Config{ To: Form.Address, Subject: "მადლობა გამოწერისთვის", Body: Body })
+	})
+	if err != nil { return err }
 
-	if Result {
-		log.Print("Sent")
-	}
 	return ctx.Html(view.Subscribe())
 }
\ No newline at end of file
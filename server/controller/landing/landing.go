@@ -7,6 +7,6 @@ import (
 )
 
 func Register(app *echo.Echo) {
-	app.GET("/", controller.Register(index))
+	app.GET("/", controller.RegisterNamed("home", index))
 	app.POST("/subscribe", controller.Register(subscribe))
 }
\ No newline at end of file
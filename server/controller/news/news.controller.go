@@ -1,11 +1,9 @@
 package news
 
 import (
-	"strconv"
-
 	"main/build/view"
 	"main/server/common/controller"
-	"main/server/common/storage"
+	"main/server/common/ids"
 	"main/server/model"
 )
 
@@ -21,14 +19,15 @@ func index(ctx *controller.Context) error {
 		Where.TypeID = filter.Type
 	}
 
-	storage.DB.Find(&Types)
-	storage.DB.
+	ctx.DB().Find(&Types)
+	ctx.DB().
 		Order("news.created_at desc").
 		Where(Where).
-		Preload("Thumbnail").
+		Preload("Thumbnail.Thumbnails").
+		Preload("Thumbnail.Metadata").
 		Find(&News)
 
-	return ctx.Html(view.News(News, Types, ctx.QueryParam("type")))
+	return ctx.HtmlWithCache(view.News(News, Types, ctx.QueryParam("type")), controller.PublicMaxAge)
 }
 
 func detail(ctx *controller.Context) error {
@@ -36,16 +35,8 @@ func detail(ctx *controller.Context) error {
 	
 	var Where = &model.News{Public: true}
 
-	if ctx.Param("ID") != "" {
-		var filter Detail
-		ctx.Bind(&filter)
-		num, err := strconv.ParseUint(filter.ID, 10, 0)
-		if err == nil {
-			Where.ID = uint(num)
-		}
-	}
-
-	storage.DB.Where(Where).Preload("Thumbnail").Last(&News)
+	ctx.DB().Where(Where).Scopes(ids.Match(ctx.Param("ID"))).Preload("Thumbnail").Last(&News)
+	if News.ID != 0 { ctx.Viewed(News.ID) }
 
-	return ctx.Html(view.NewsDetails(News))
+	return ctx.HtmlWithCache(view.NewsDetails(News), controller.PublicMaxAge)
 }
\ No newline at end of file
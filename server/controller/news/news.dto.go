@@ -2,8 +2,4 @@ package news
 
 type Filters struct {
 	Type int `query:"type"`
-}
-
-type Detail struct {
-	ID   string `param:"id"`
 }
\ No newline at end of file
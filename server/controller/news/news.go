@@ -7,6 +7,6 @@ import (
 )
 
 func Register(app *echo.Echo) {
-	app.GET("/news", controller.Register(index))
-	app.GET("/news/:ID", controller.Register(detail))
+	app.GET("/news", controller.RegisterNamed("news", index))
+	app.GET("/news/:ID", controller.RegisterNamed("news.detail", detail))
 }
\ No newline at end of file
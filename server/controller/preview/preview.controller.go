@@ -0,0 +1,34 @@
+package preview
+
+import (
+	"net/http"
+	"time"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/preview"
+	"main/server/service/previewer"
+)
+
+func enter(ctx *controller.Context) error {
+	Channel, err := previewer.Find(ctx.Param("token"))
+	if err != nil { return ctx.HtmlWithStatus(http.StatusNotFound, view.Wildcard()) }
+
+	ctx.WriteSignedCookie(controller.NewCookie(preview.Cookie, Channel.Token, time.Now().Add(24 * time.Hour)))
+	return ctx.Redirect(http.StatusSeeOther, "/")
+}
+
+// link lets a preview link's holder see its page, the cookie lasts as long as the link.
+func link(ctx *controller.Context) error {
+	Link, _, err := previewer.FindLink(ctx.Param("token"))
+	if err != nil { return ctx.HtmlWithStatus(http.StatusNotFound, view.Wildcard()) }
+
+	ctx.WriteSignedCookie(controller.NewCookie(preview.LinkCookie, Link.Token, Link.ExpiresAt))
+	return ctx.Redirect(http.StatusSeeOther, Link.Path)
+}
+
+func exit(ctx *controller.Context) error {
+	ctx.DeleteCookie(preview.Cookie)
+	ctx.DeleteCookie(preview.LinkCookie)
+	return ctx.Redirect(http.StatusSeeOther, "/")
+}
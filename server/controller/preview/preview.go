@@ -0,0 +1,13 @@
+package preview
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+)
+
+func Register(app *echo.Echo) {
+	app.GET("/preview/exit", controller.Register(exit))
+	app.GET("/preview/link/:token", controller.Register(link))
+	app.GET("/preview/:token", controller.Register(enter))
+}
@@ -3,12 +3,20 @@ package products
 import (
 	"main/build/view"
 	"main/server/common/controller"
+	"main/server/common/ids"
+	"main/server/common/search"
 	"main/server/common/storage"
 	"main/server/model"
 	"net/http"
 	"strconv"
 )
 
+// SearchFields are the products' fields the searcher looks in, the name counts the most.
+var SearchFields = []search.Field{
+	{ Name: "Name", Label: "დასახელება", Weight: 3 },
+	{ Name: "Description", Label: "აღწერა", Weight: 1 },
+}
+
 func index(ctx *controller.Context) error {
 	var Filters FiltersQuery
 	var Category model.Categories
@@ -19,8 +27,8 @@ func index(ctx *controller.Context) error {
 	ctx.Bind(&Filters)
 	ID, _ := strconv.Atoi(Filters.Category)
 
-	storage.DB.Find(&Categories, &model.Categories{Public: true})
-	storage.DB.Preload("Filters.Options").
+	ctx.DB().Find(&Categories, &model.Categories{Public: true})
+	ctx.DB().Preload("Filters.Options").
 			   Where(&model.Categories{Public: true}).
 			   First(&Category, ID)
 
@@ -32,7 +40,7 @@ func list(ctx *controller.Context) error {
 	var Filters FiltersQuery
 	var Products []model.Products
 	var Where model.Products = model.Products{Public: true}
-	query := storage.DB.Scopes(storage.Paginate(ctx))
+	query := ctx.DB().Scopes(storage.Paginate(ctx))
 
 	if err := ctx.Bind(&Filters); err != nil {
 		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
@@ -44,22 +52,35 @@ func list(ctx *controller.Context) error {
 		query = query.Where(Where)
 	}
 
-	if Filters.Searcher != "" {
-		query.Where("name ILIKE ?", "%" + Filters.Searcher + "%")
-	}
-
-	NextPage := strconv.Itoa(ctx.Page() + 1)
-
 	query.
-			Preload("Thumbnail").
+			Scopes(storage.Search(Filters.Searcher, model.ProductsName, model.ProductsDescription)).
+			Preload("Thumbnail.Thumbnails").
+			Preload("Thumbnail.Metadata").
 			Preload("Packing").
 			Preload("Approvals").
 			Preload("Properties").
 			Preload("Specifications").
 			Find(&Products)
 
-	if len(Products) == 0 { return ctx.String(http.StatusOK, "") }
-	return ctx.Html(view.Products(NextPage, Filters.Searcher, Filters.Category, Products))
+	/* Only the first page offers a spelling, the next ones are appended below it */
+	Suggestion, Retry := "", ""
+	if ctx.Pagination().Page == 1 { Suggestion = search.Suggest(Filters.Searcher) }
+	if Suggestion != "" {
+		Query := ctx.Request().URL.Query()
+		Query.Set("searcher", Suggestion)
+		Query.Set("page", "1")
+		Retry = ctx.Request().URL.Path + "?" + Query.Encode()
+	}
+
+	if len(Products) == 0 && Suggestion == "" { return ctx.String(http.StatusOK, "") }
+
+	Hits := map[uint]search.Hit{}
+	if Terms := search.Parse(Filters.Searcher); len(Terms) > 0 {
+		for _, Product := range Products {
+			Hits[Product.ID] = search.Score(Terms, SearchFields, map[string]string{ "Name": Product.Name, "Description": Product.Description })
+		}
+	}
+	return ctx.Html(view.Products(ctx.Pagination().Next(), Products, Hits, Suggestion, Retry))
 }
 
 func detail(ctx *controller.Context) error {
@@ -67,15 +88,16 @@ func detail(ctx *controller.Context) error {
 	var Product model.Products
 
 	ctx.Bind(&Filters)
-	ID, _ := strconv.Atoi(Filters.ID)
 
-	storage.DB.Preload("Thumbnail").
+	ctx.DB().Scopes(ids.Match(Filters.ID)).
+				Preload("Thumbnail").
 				Preload("Category").
 				Preload("Packing").
 				Preload("Approvals").
 				Preload("Properties").
 				Preload("Specifications").
-				Find(&Product, ID)
+				Find(&Product)
+	if Product.ID != 0 { ctx.Viewed(Product.ID) }
 
 	return ctx.Html(view.ProductDetail(Product))
 }
\ No newline at end of file
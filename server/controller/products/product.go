@@ -1,13 +1,15 @@
 package products
 
 import (
+	"time"
+
 	"github.com/labstack/echo/v4"
 
 	"main/server/common/controller"
 )
 
 func Register(app *echo.Echo) {
-	app.GET("/products", controller.Register(index))
-	app.GET("/products/list", controller.Register(list))
-	app.GET("/products/:id", controller.Register(detail))
+	app.GET("/products", controller.RegisterNamed("products", index))
+	app.GET("/products/list", controller.RegisterWithTimeout(10 * time.Second, list))
+	app.GET("/products/:id", controller.RegisterNamed("products.detail", detail))
 }
\ No newline at end of file
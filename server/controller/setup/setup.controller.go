@@ -0,0 +1,73 @@
+package setup
+
+import (
+	"net/http"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/i18n"
+	"main/server/service/auth"
+	mailer "main/server/service/mail"
+	"main/server/service/setup"
+)
+
+func index(ctx *controller.Context) error {
+	if setup.Installed() { return ctx.Redirect(http.StatusSeeOther, "/admin") }
+	return ctx.Renders(http.StatusOK, view.Setup(values(SetupInput{ Locale: ctx.Locale(), Uploads: "/uploads/" }), nil))
+}
+
+func install(ctx *controller.Context) error {
+	Form, errs := controller.BindForm[SetupInput](ctx)
+	if Form.Locale != "" && !i18n.IsSupported(Form.Locale) { errs.Add("locale", ctx.T("form.required")) }
+
+	Uploads, err := setup.Uploads(Form.Uploads)
+	if err != nil { errs.Add("uploads", ctx.T("setup.uploads", err.Error())) }
+	Form.Uploads = Uploads
+
+	if !errs.Valid() { return ctx.HtmlFormErrors("#SetupForm", view.SetupForm(values(Form), errs)) }
+
+	User, err := setup.Complete(setup.Input{
+		Fullname: Form.Fullname,
+		Email: Form.Email,
+		Password: Form.Password,
+		SiteName: Form.SiteName,
+		Locale: Form.Locale,
+		Uploads: Form.Uploads,
+	})
+	if err != nil {
+		ctx.Log("Completing setup: ", err)
+		errs.Add(controller.FormErrorKey, ctx.T("setup.failed", err.Error()))
+		return ctx.HtmlFormErrors("#SetupForm", view.SetupForm(values(Form), errs))
+	}
+
+	/* The installer is signed in as the admin it created, with a session of its own */
+	Grants, err := auth.Grants(User.ID)
+	if err != nil { return err }
+	Token, err := auth.StartSession(User, Grants, ctx.Request().UserAgent(), ctx.RealIP(), ctx.ReadCookie("token").Value)
+	if err != nil { return err }
+	auth.WriteSession(ctx, User, Token)
+
+	ctx.Response().Header().Set("HX-Redirect", "/admin")
+	return ctx.NoContent(http.StatusOK)
+}
+
+func mail(ctx *controller.Context) error {
+	Form, errs := controller.BindForm[MailInput](ctx)
+	if !errs.Valid() { return ctx.Renders(http.StatusOK, view.SetupMailResult(false, errs["to"])) }
+
+	if _, err := mailer.Send(mailer.Config{ To: Form.To, Subject: "yacco", Body: ctx.T("setup.mail.sent", Form.To) }); err != nil {
+		return ctx.Renders(http.StatusOK, view.SetupMailResult(false, ctx.T("setup.mail.failed", err.Error())))
+	}
+	return ctx.Renders(http.StatusOK, view.SetupMailResult(true, ctx.T("setup.mail.sent", Form.To)))
+}
+
+/* The view gets the form as plain values, like it gets form errors */
+func values(Form SetupInput) map[string]string {
+	return map[string]string{
+		"fullname": Form.Fullname,
+		"email": Form.Email,
+		"siteName": Form.SiteName,
+		"locale": Form.Locale,
+		"uploads": Form.Uploads,
+	}
+}
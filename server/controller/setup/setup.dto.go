@@ -0,0 +1,14 @@
+package setup
+
+type SetupInput struct {
+	Fullname 	string 		`json:"fullname" form:"fullname" validate:"required,max=120"`
+	Email 		string 		`json:"email" form:"email" validate:"required,email"`
+	Password 	string 		`json:"password" form:"password" validate:"required,min=8"`
+	SiteName 	string 		`json:"siteName" form:"siteName" validate:"required,max=120"`
+	Locale 		string 		`json:"locale" form:"locale" validate:"required"`
+	Uploads 	string 		`json:"uploads" form:"uploads"`
+}
+
+type MailInput struct {
+	To 			string 		`json:"to" form:"to" validate:"required,email"`
+}
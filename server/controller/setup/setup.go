@@ -0,0 +1,22 @@
+package setup
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/service/setup"
+)
+
+func Register(app *echo.Echo) {
+	app.GET("/setup", controller.Register(index))
+	app.POST("/setup", controller.Register(install), uninstalled)
+	app.POST("/setup/mail", controller.Register(mail), uninstalled)
+}
+
+/* Once installed the wizard's endpoints are gone, nobody can send mail through the site with its mail test */
+func uninstalled(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		if setup.Installed() { return echo.ErrNotFound }
+		return next(ctx)
+	}
+}
@@ -0,0 +1,65 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"main/server/common/controller"
+	"main/server/common/globals"
+	"main/server/common/route"
+	"main/server/model"
+	"main/server/service/indexer"
+	"main/server/service/pinger"
+)
+
+// MaxAge is how long the sitemap is cached, engines pinged about a publish read it again anyway.
+const MaxAge = 10 * time.Minute
+
+type urlset struct {
+	XMLName		xml.Name	`xml:"urlset"`
+	Namespace	string		`xml:"xmlns,attr"`
+	URLs		[]entry		`xml:"url"`
+}
+
+type entry struct {
+	Location		string		`xml:"loc"`
+	LastModified	string		`xml:"lastmod,omitempty"`
+}
+
+// index lists the site's pages for search engines (sitemaps.org): the named pages, every public news and product,
+// but those the indexing rules keep out of the index or point elsewhere (see indexer.Listed).
+func index(ctx *controller.Context) error {
+	var Newz []model.News
+	var Products []model.Products
+	ctx.DB().Where(&model.News{ Public: true }).Order("id").Find(&Newz)
+	ctx.DB().Where(&model.Products{ Public: true }).Order("id").Find(&Products)
+
+	Sitemap := urlset{ Namespace: "http://www.sitemaps.org/schemas/sitemap/0.9" }
+	add := func(Path string, LastModified string) {
+		if !indexer.Listed(Path) { return }
+		Sitemap.URLs = append(Sitemap.URLs, entry{ Location: globals.Env.APP_URL + Path, LastModified: LastModified })
+	}
+	for _, Name := range []string{ "home", "categories", "news", "faq", "about", "branches", "terms" } {
+		add(route.URL(Name), "")
+	}
+	for _, New := range Newz {
+		add(route.URL("news.detail", New.Ref(New.ID)), New.UpdatedAt.Format(time.DateOnly))
+	}
+	for _, Product := range Products {
+		add(route.URL("products.detail", Product.Ref(Product.ID)), Product.UpdatedAt.Format(time.DateOnly))
+	}
+
+	body, err := xml.Marshal(Sitemap)
+	if err != nil { return err }
+
+	ctx.CacheControl(MaxAge)
+	return ctx.Cached("application/xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
+
+// key answers the IndexNow key, engines check it belongs to the site submitting urls (see package pinger).
+func key(ctx *controller.Context) error {
+	Key := pinger.Key()
+	if Key == "" { return ctx.NoContent(http.StatusNotFound) }
+	return ctx.String(http.StatusOK, Key)
+}
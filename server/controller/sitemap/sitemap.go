@@ -0,0 +1,13 @@
+package sitemap
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/service/pinger"
+)
+
+func Register(app *echo.Echo) {
+	app.GET("/sitemap.xml", controller.RegisterNamed("sitemap", index))
+	app.GET(pinger.KeyPath, controller.Register(key))
+}
@@ -0,0 +1,63 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/server/common/controller"
+	"main/server/common/globals"
+	"main/server/common/module"
+	"main/server/common/stream"
+	"main/server/model"
+)
+
+// video streams a video file to a signed url (see package stream), throttled to STREAM_RATE: the file itself, or
+// its rendition of the "h" height (see package transcoder). Range requests are answered with 206 (Partial Content)
+// by Download, a playback emits "video.viewed" once, on its first request: one without a Range or with a range
+// starting at byte 0.
+func video(ctx *controller.Context, container *module.Container) error {
+	var File model.Files
+	ID, _ := strconv.Atoi(ctx.Param("id"))
+
+	if err := stream.Verify(uint(ID), ctx.QueryParam("expires"), ctx.QueryParam("token")); err != nil {
+		if errors.Is(err, stream.ErrExpired) { return ctx.String(http.StatusGone, err.Error()) }
+		return ctx.String(http.StatusForbidden, err.Error())
+	}
+
+	if result := ctx.DB().Preload("Renditions").First(&File, ID); result.Error != nil {
+		return ctx.String(http.StatusNotFound, "File not found")
+	}
+
+	if !strings.HasPrefix(mime.TypeByExtension(filepath.Ext(File.Name)), "video/") {
+		return ctx.String(http.StatusUnsupportedMediaType, "Not a video")
+	}
+
+	if Range := ctx.Request().Header.Get("Range"); Range == "" || strings.HasPrefix(Range, "bytes=0-") {
+		View := stream.View{ FileID: File.ID, IP: ctx.RealIP(), UserAgent: ctx.Request().UserAgent(), At: time.Now() }
+		go container.Emit(context.Background(), "video.viewed", View)
+	}
+
+	Options := []controller.DownloadOption{ controller.Inline() }
+	if Height := ctx.QueryParam("h"); Height != "" {
+		Rendition, found := rendition(File, Height)
+		if !found { return ctx.String(http.StatusNotFound, "Rendition not found") }
+		Options = append(Options, controller.Variant(Rendition.Key))
+	}
+
+	ctx.Response().Header().Set("Accept-Ranges", "bytes")
+	ctx.Response().Writer = stream.Throttle(ctx.Response().Writer, globals.Env.STREAM_RATE)
+	return ctx.Download(File, Options...)
+}
+
+func rendition(File model.Files, Height string) (model.File_renditions, bool) {
+	for _, Rendition := range File.Renditions {
+		if strconv.Itoa(Rendition.Height) == Height { return Rendition, true }
+	}
+	return model.File_renditions{}, false
+}
@@ -0,0 +1,17 @@
+package stream
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/module"
+	"main/server/middleware"
+)
+
+type Module struct{}
+
+func (Module) Name() string { return "stream" }
+
+func (Module) Register(app *echo.Echo, container *module.Container) {
+	app.GET("/stream/:id", controller.Register(func(ctx *controller.Context) error { return video(ctx, container) }), middleware.Identify())
+}
@@ -3,17 +3,16 @@ package terms
 import (
 	"main/build/view"
 	"main/server/common/controller"
-	"main/server/common/storage"
 	"main/server/model"
 )
 
 func index(ctx *controller.Context) error {
 	var About model.Interface_about
-	result := storage.DB.Last(&About)
+	result := ctx.DB().Last(&About)
 
 	if result.Error != nil {
-		return ctx.Html(view.ErrorPage())
+		return ctx.Html(view.ErrorPage(ctx.RequestID()))
 	}
 
-	return ctx.Html(view.Terms(About.Terms))
+	return ctx.HtmlWithCache(view.Terms(About.Terms), controller.PublicMaxAge)
 }
\ No newline at end of file
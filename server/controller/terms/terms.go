@@ -7,5 +7,5 @@ import (
 )
 
 func Register(app *echo.Echo) {
-	app.GET("/terms", controller.Register(index))
+	app.GET("/terms", controller.RegisterNamed("terms", index))
 }
\ No newline at end of file
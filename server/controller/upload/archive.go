@@ -0,0 +1,74 @@
+package upload
+
+import (
+	"errors"
+	"net/http"
+
+	"main/build/view"
+	"main/server/common/controller"
+	uploader "main/server/common/helpers"
+	"main/server/service/filetypes"
+)
+
+// ArchiveManifest is what POST /upload/archive answers with: the IDs of the Files created, in the archive's order,
+// and the response of each of its entries, named after their path in the archive.
+type ArchiveManifest struct {
+	Version		int							`json:"version"`
+	Files		[]int						`json:"files"`
+	Entries		[]*uploader.UploadResponse	`json:"entries"`
+}
+
+// Archive stores the files of the zip archive sent as the "archive" form field (see uploader.Extract), each one through
+// the pipeline of a single upload, and answers with their ArchiveManifest. As FilesUpload, the request succeeds when
+// at least one file was stored, otherwise it gets the status of the first rejection. htmx requests get
+// view.UploadedFiles. It's registered behind the "files.import" permission, next to POST /upload/from-url.
+// An archive which isn't a zip one is refused (400), as is one holding more than uploader.MaxEntries files (413).
+func Archive(ctx *controller.Context) error {
+	Form, err := uploader.Receive(ctx.Request(), 1)
+	if err != nil {
+		uploader.Aborted(ctx.Actor(), err)
+		return respond(ctx, &uploader.Received{}, uploader.Refused(err))
+	}
+	defer Form.Close()
+
+	Visibility, ok := uploader.Visibility(Form.Values["visibility"])
+	if !ok { return respond(ctx, Form, uploader.Failed(uploader.CodeVisibility, "Unknown visibility " + Form.Values["visibility"])) }
+
+	Archives := Form.Named("archive")
+	if len(Archives) == 0 { return respond(ctx, Form, uploader.Failed(uploader.CodeMissingFile, "Error retrieving archive from form data")) }
+
+	Manifest := ArchiveManifest{ Version: uploader.UploadVersion, Files: []int{}, Entries: []*uploader.UploadResponse{} }
+	Status := 0
+	err = uploader.Extract(Archives[0], func(Path string, Entry uploader.ReceivedFile, err error) {
+		var Upload *uploader.UploadResponse
+		if err != nil {
+			Upload = uploader.Unextracted(Path, err).Localize(ctx.Locale())
+		} else {
+			Upload = stored(ctx, Entry, filetypes.UploadContext(Form.Values["context"]), Visibility)
+		}
+
+		if Upload.Success {
+			Status = http.StatusOK
+			Manifest.Files = append(Manifest.Files, Upload.ID)
+		} else {
+			ctx.Log("Upload of ", Path, " from ", Archives[0].Name, " failed: ", Upload.Code, " ", Upload.Detail)
+			if Status == 0 { Status = Upload.HTTPStatus() }
+		}
+		Upload.Name = Path
+		Manifest.Entries = append(Manifest.Entries, Upload)
+	})
+	if err != nil {
+		if errors.Is(err, uploader.ErrTooMany) { return respond(ctx, Form, uploader.Failed(uploader.CodeTooMany, err.Error())) }
+		return respond(ctx, Form, uploader.Failed(uploader.CodeArchive, err.Error()))
+	}
+	if Status == 0 { Status = http.StatusOK }
+
+	if !ctx.Htmx().Request { return ctx.JSON(Status, Manifest) }
+
+	Fragments := make([]view.UploadedFile, 0, len(Manifest.Entries))
+	for _, Upload := range Manifest.Entries { Fragments = append(Fragments, fragment(Upload)) }
+
+	Field := Form.Values["field"]
+	if Field == "" { Field = "file_ids" }
+	return ctx.Renders(http.StatusOK, view.UploadedFiles(Field, Fragments))
+}
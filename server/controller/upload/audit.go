@@ -0,0 +1,47 @@
+package upload
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"main/server/common/controller"
+	uploader "main/server/common/helpers"
+	"main/server/common/storage"
+)
+
+// Event is what happened to a file as GET /admin/files/:id/events answers it, User is "" for anonymous uploads.
+type Event struct {
+	Action		string		`json:"action"`
+	UsersID		*uint		`json:"userId,omitempty"`
+	User		string		`json:"user,omitempty"`
+	IP			string		`json:"ip"`
+	UserAgent	string		`json:"userAgent"`
+	RequestID	string		`json:"requestId,omitempty"`
+	Detail		string		`json:"detail"`
+	At			time.Time	`json:"at"`
+}
+
+// Events answers the history of a file (uploader.Events), oldest first, it's registered on the admin group behind
+// the "files.audit" permission. A deleted file's history is still there, by the ID it had.
+func Events(ctx *controller.Context) error {
+	File, err := lookup(ctx, storage.WithTrashed)
+	if err != nil && !errors.Is(err, uploader.ErrNotFound) { return err }
+
+	ID := File.ID
+	if Number, err := strconv.ParseUint(ctx.Param("id"), 10, 0); ID == 0 && err == nil { ID = uint(Number) }
+
+	History, err := uploader.Events(ID)
+	if err != nil { return err }
+	if ID == 0 || (File.ID == 0 && len(History) == 0) { return uploader.ErrNotFound }
+
+	Answer := make([]Event, len(History))
+	for i, Found := range History {
+		Answer[i] = Event{
+			Action: Found.Action, UsersID: Found.UsersID, User: Found.Users.Fullname, IP: Found.IP, UserAgent: Found.UserAgent,
+			RequestID: Found.RequestID, Detail: Found.Detail, At: Found.CreatedAt,
+		}
+	}
+	return ctx.JSON(http.StatusOK, Answer)
+}
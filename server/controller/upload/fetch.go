@@ -0,0 +1,50 @@
+package upload
+
+import (
+	"net/http"
+
+	"main/server/common/controller"
+	uploader "main/server/common/helpers"
+	"main/server/model"
+	"main/server/service/filetypes"
+)
+
+type FromURLDto struct {
+	URL 		string 		`json:"url" form:"url"`
+	Visibility 	string 		`json:"visibility" form:"visibility"`
+	Context 	string 		`json:"context" form:"context"`
+	Field 		string 		`json:"field" form:"field"`
+	Widget 		string 		`json:"widget" form:"widget"`
+}
+
+// FromURL stores the file at the "url" value (uploader.Fetch), through the pipeline of an uploaded one, and answers
+// as FileUpload does. It's registered behind the "files.import" permission: the server makes the request.
+// An address which isn't a public http(s) one is refused (400), as is a file the server can't download (502).
+func FromURL(ctx *controller.Context) error {
+	var Body FromURLDto
+
+	if err := ctx.Bind(&Body); err != nil {
+		return ctx.String(http.StatusBadRequest, "Parameters Binding Problem: " + err.Error())
+	}
+
+	Form, err := uploader.Fetch(ctx.Request().Context(), Body.URL)
+	if err != nil { return respond(ctx, &uploader.Received{ Values: values(Body) }, uploader.Unfetched(err)) }
+	defer Form.Close()
+	Form.Values = values(Body)
+
+	Visibility, ok := uploader.Visibility(Body.Visibility)
+	if !ok { return respond(ctx, Form, uploader.Failed(uploader.CodeVisibility, "Unknown visibility " + Body.Visibility)) }
+
+	File := Form.Files[0]
+	if Refused := charge(ctx, File.Size); Refused != nil { return respond(ctx, Form, Refused) }
+
+	Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.StoreVisible(File.Content, File.Name, File.Size, File.ContentType, filetypes.UploadContext(Body.Context), Visibility))
+	Upload = own(ctx, Upload)
+	Upload.Name = File.Name
+	return respond(ctx, Form, Upload)
+}
+
+/* The values respond reads, as a form sent to FileUpload carries them */
+func values(Body FromURLDto) map[string]string {
+	return map[string]string{ "visibility": Body.Visibility, "context": Body.Context, "field": Body.Field, "widget": Body.Widget }
+}
@@ -0,0 +1,60 @@
+package upload
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"main/server/common/controller"
+	"main/server/common/progress"
+)
+
+// ProgressEvery is how often the progress stream looks for a change.
+const ProgressEvery = 250 * time.Millisecond
+
+// progressed answers the progress of an upload (see package progress) as json, 404 while it's unknown.
+// An "Accept: text/event-stream" request gets it as server-sent "progress" events instead, one per change,
+// the stream ends with the upload. It waits for an upload which hasn't started yet, progress.Expiry at most.
+func progressed(ctx *controller.Context) error {
+	Token := ctx.Param("token")
+	if !progress.Valid(Token) { return ctx.NoContent(http.StatusNotFound) }
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+
+	if !strings.Contains(ctx.Request().Header.Get("Accept"), "text/event-stream") {
+		Progress, ok := progress.Get(Token)
+		if !ok { return ctx.NoContent(http.StatusNotFound) }
+		return ctx.JSON(http.StatusOK, Progress)
+	}
+
+	ctx.Response().Header().Set("Content-Type", "text/event-stream")
+	/* Proxies buffering the response would hold the events back until it ends */
+	ctx.Response().Header().Set("X-Accel-Buffering", "no")
+	ctx.Response().WriteHeader(http.StatusOK)
+	ctx.Response().Flush()
+
+	Ticker := time.NewTicker(ProgressEvery)
+	defer Ticker.Stop()
+	Deadline := time.Now().Add(progress.Expiry)
+
+	var Last progress.Progress
+	for {
+		Progress, ok := progress.Get(Token)
+		if ok && Progress != Last {
+			data, err := json.Marshal(Progress)
+			if err != nil { return err }
+			if _, err := fmt.Fprintf(ctx.Response(), "event: progress\ndata: %s\n\n", data); err != nil { return nil }
+			ctx.Response().Flush()
+
+			Last = Progress
+			if Progress.Finished() { return nil }
+		}
+		if !ok && time.Now().After(Deadline) { return nil }
+
+		select {
+			case <-ctx.Request().Context().Done(): return nil
+			case <-Ticker.C:
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package upload
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"main/build/view"
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/transcoder"
+)
+
+// TranscodingEvery is how often the transcoding stream looks for a change.
+const TranscodingEvery = 2 * time.Second
+
+// Transcoding is a video's processing as GET /files/:id/transcoding answers it, Renditions maps their height
+// ("720p") to their url.
+type Transcoding struct {
+	ID			string				`json:"id"`
+	State		string				`json:"state"`
+	Renditions	map[string]string	`json:"renditions,omitempty"`
+}
+
+// transcoding answers the processing of a video (see package transcoder) as json, to whoever may download it.
+// htmx requests get view.VideoTranscoding, which polls until the video is done, then the response triggers
+// "video-transcoded" with the json as its detail. An "Accept: text/event-stream" request gets server-sent
+// "transcoding" events instead, one per change, the stream ends with the processing.
+func transcoding(ctx *controller.Context) error {
+	File, err := transcoded(ctx)
+	if err != nil { return err }
+	if err := controller.Visible(ctx, File); err != nil { return err }
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+
+	switch {
+		case ctx.Htmx().Request:
+			URL := "/files/" + File.Ref(File.ID) + "/transcoding"
+			if !transcoder.Done(File) { return ctx.Html(view.VideoTranscoding(URL, File.Transcoding)) }
+			if err := ctx.HxTrigger(map[string]any{ "video-transcoded": status(File) }); err != nil { return err }
+			return ctx.HxStopPolling(view.VideoTranscoding(URL, File.Transcoding))
+
+		case strings.Contains(ctx.Request().Header.Get("Accept"), "text/event-stream"):
+			return streamTranscoding(ctx, File)
+	}
+	return ctx.JSON(http.StatusOK, status(File))
+}
+
+func streamTranscoding(ctx *controller.Context, File model.Files) error {
+	ctx.Response().Header().Set("Content-Type", "text/event-stream")
+	ctx.Response().Header().Set("X-Accel-Buffering", "no")
+	ctx.Response().WriteHeader(http.StatusOK)
+	ctx.Response().Flush()
+
+	Ticker := time.NewTicker(TranscodingEvery)
+	defer Ticker.Stop()
+
+	Last := ""
+	for {
+		if File.Transcoding != Last {
+			data, err := json.Marshal(status(File))
+			if err != nil { return err }
+			if _, err := fmt.Fprintf(ctx.Response(), "event: transcoding\ndata: %s\n\n", data); err != nil { return nil }
+			ctx.Response().Flush()
+
+			Last = File.Transcoding
+			/* A video which isn't processed ("") won't change either */
+			if transcoder.Done(File) || File.Transcoding == "" { return nil }
+		}
+
+		select {
+			case <-ctx.Request().Context().Done(): return nil
+			case <-Ticker.C:
+		}
+
+		var err error
+		if File, err = transcoded(ctx); err != nil { return nil }
+	}
+}
+
+func transcoded(ctx *controller.Context) (model.Files, error) {
+	return lookup(ctx, storage.Preload("Renditions"))
+}
+
+func status(File model.Files) Transcoding {
+	Status := Transcoding{ ID: File.Ref(File.ID), State: File.Transcoding }
+	if File.Transcoding != model.TranscodeReady { return Status }
+
+	Status.Renditions = map[string]string{}
+	for _, Rendition := range File.Renditions { Status.Renditions[fmt.Sprint(Rendition.Height, "p")] = "/" + Rendition.Key }
+	return Status
+}
@@ -0,0 +1,152 @@
+package upload
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"main/server/common/controller"
+	uploader "main/server/common/helpers"
+	"main/server/model"
+	"main/server/service/filetypes"
+	"main/server/service/quota"
+	"main/server/service/resumable"
+)
+
+// TusVersion is the version of the tus protocol the resumable upload routes speak.
+const TusVersion = "1.0.0"
+
+/* Every tus response carries Tus-Resumable, requests of another protocol version are refused with 412 */
+func tus(handler controller.Handler) controller.Handler {
+	return func(ctx *controller.Context) error {
+		ctx.Response().Header().Set("Tus-Resumable", TusVersion)
+		if ctx.Request().Method != http.MethodOptions && ctx.Request().Header.Get("Tus-Resumable") != TusVersion {
+			ctx.Response().Header().Set("Tus-Version", TusVersion)
+			return ctx.NoContent(http.StatusPreconditionFailed)
+		}
+		return handler(ctx)
+	}
+}
+
+func tusOptions(ctx *controller.Context) error {
+	ctx.Response().Header().Set("Tus-Version", TusVersion)
+	ctx.Response().Header().Set("Tus-Extension", "creation,termination")
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// tusCreate starts an upload of "Upload-Length" bytes, its "Upload-Metadata" carries the "filename",
+// "filetype" and "context" of the file. A file the filetypes don't accept is refused before any chunk is sent,
+// with the UploadResponse of the rejection, and so is one the signed in uploader's quota has no room for.
+func tusCreate(ctx *controller.Context) error {
+	Length, err := strconv.ParseInt(ctx.Request().Header.Get("Upload-Length"), 10, 64)
+	if err != nil || Length < 1 { return ctx.String(http.StatusBadRequest, "Upload-Length is required") }
+
+	Metadata := tusMetadata(ctx.Request().Header.Get("Upload-Metadata"))
+	if Metadata["filename"] == "" { return ctx.String(http.StatusBadRequest, "Upload-Metadata has no filename") }
+
+	var Owner *uint
+	if User, ok := ctx.CurrentUser(); ok {
+		if Refused := charge(ctx, Length); Refused != nil {
+			Refused.Localize(ctx.Locale())
+			return ctx.JSON(Refused.HTTPStatus(), Refused)
+		}
+		Owner = &User.ID
+	}
+
+	Upload, err := resumable.Create(Metadata["filename"], Metadata["filetype"], filetypes.UploadContext(Metadata["context"]), Length, Owner)
+	if err != nil {
+		if errors.Is(err, resumable.ErrRejected) {
+			Rejected := uploader.Rejected(err).Localize(ctx.Locale())
+			return ctx.JSON(Rejected.HTTPStatus(), Rejected)
+		}
+		/* Another upload took the room the check found */
+		if errors.Is(err, quota.ErrExceeded) {
+			Quota, _ := quota.Of(*Owner)
+			Refused := uploader.OverQuota(Quota, err).Localize(ctx.Locale())
+			return ctx.JSON(Refused.HTTPStatus(), Refused)
+		}
+		ctx.Log("Creating upload: ", err)
+		return ctx.String(http.StatusInternalServerError, "")
+	}
+
+	ctx.Response().Header().Set("Location", "/upload/tus/" + Upload.Token)
+	ctx.Response().Header().Set("Upload-Offset", "0")
+	return ctx.NoContent(http.StatusCreated)
+}
+
+func tusHead(ctx *controller.Context) error {
+	Upload, err := resumable.Find(ctx.Param("token"))
+	if err != nil { return ctx.NoContent(http.StatusNotFound) }
+
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+	ctx.Response().Header().Set("Upload-Offset", strconv.FormatInt(Upload.Offset, 10))
+	ctx.Response().Header().Set("Upload-Length", strconv.FormatInt(Upload.Length, 10))
+	if Upload.FileID != nil { ctx.Response().Header().Set("Upload-File", "/files/" + Upload.File.Ref(Upload.File.ID)) }
+	return ctx.NoContent(http.StatusOK)
+}
+
+// tusPatch appends a chunk at "Upload-Offset". The chunk completing the upload stores the file, its ID and url
+// are sent in the "Upload-File-Id", "Upload-File-Public-Id" and "Upload-File" headers, a rejected file gets its UploadResponse instead.
+func tusPatch(ctx *controller.Context) error {
+	if ctx.Request().Header.Get("Content-Type") != "application/offset+octet-stream" {
+		return ctx.NoContent(http.StatusUnsupportedMediaType)
+	}
+
+	Offset, err := strconv.ParseInt(ctx.Request().Header.Get("Upload-Offset"), 10, 64)
+	if err != nil { return ctx.String(http.StatusBadRequest, "Upload-Offset is required") }
+
+	Upload, Response, err := resumable.Append(ctx.Param("token"), Offset, uploader.Cancellable(ctx.Request().Context(), ctx.Request().Body))
+	switch {
+		case errors.Is(err, resumable.ErrNotFound):
+			return ctx.NoContent(http.StatusNotFound)
+		case errors.Is(err, uploader.ErrAborted):
+			/* What arrived of the chunk is kept, the client resumes from the new offset */
+			uploader.Aborted(ctx.Actor(), fmt.Errorf("%s at %d of %d bytes: %w", Upload.Name, Upload.Offset, Upload.Length, err))
+			ctx.Response().Header().Set("Upload-Offset", strconv.FormatInt(Upload.Offset, 10))
+			return ctx.NoContent(http.StatusBadRequest)
+		case errors.Is(err, resumable.ErrOffset), errors.Is(err, resumable.ErrFinished):
+			ctx.Response().Header().Set("Upload-Offset", strconv.FormatInt(Upload.Offset, 10))
+			return ctx.NoContent(http.StatusConflict)
+		case err != nil:
+			ctx.Log("Appending upload chunk: ", err)
+			return ctx.String(http.StatusInternalServerError, "")
+	}
+
+	ctx.Response().Header().Set("Upload-Offset", strconv.FormatInt(Upload.Offset, 10))
+	if Response == nil { return ctx.NoContent(http.StatusNoContent) }
+
+	Response.Localize(ctx.Locale())
+	if !Response.Success {
+		ctx.Log("Upload failed: ", Response.Code, " ", Response.Detail)
+		return ctx.JSON(Response.HTTPStatus(), Response)
+	}
+
+	/* The request of the last chunk is the one the upload is recorded with */
+	uploader.Audit(ctx.Actor(), uint(Response.ID), model.FileUploaded, Upload.Name)
+	ctx.Response().Header().Set("Upload-File-Id", strconv.Itoa(Response.ID))
+	ctx.Response().Header().Set("Upload-File-Public-Id", Response.PublicID)
+	ctx.Response().Header().Set("Upload-File", Response.URL)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func tusDelete(ctx *controller.Context) error {
+	if err := resumable.Remove(ctx.Param("token")); err != nil { return ctx.NoContent(http.StatusNotFound) }
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+/* "filename d29ybGQucG5n,filetype aW1hZ2UvcG5n": comma separated keys with base64 values, a value may be missing */
+func tusMetadata(Header string) map[string]string {
+	Metadata := map[string]string{}
+	for _, Pair := range strings.Split(Header, ",") {
+		Key, Value, _ := strings.Cut(strings.TrimSpace(Pair), " ")
+		if Key == "" { continue }
+
+		Decoded, err := base64.StdEncoding.DecodeString(Value)
+		if err != nil { continue }
+		Metadata[Key] = string(Decoded)
+	}
+	return Metadata
+}
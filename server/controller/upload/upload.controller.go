@@ -1,120 +1,295 @@
 package upload
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"io"
-	"log"
+	"errors"
+	"main/build/view"
 	"main/server/common/controller"
+	"main/server/common/domain"
 	"main/server/common/globals"
+	"main/server/common/ids"
 	uploader "main/server/common/helpers"
+	"main/server/common/progress"
+	"main/server/common/repository"
 	"main/server/common/storage"
 	"main/server/model"
+	"main/server/service/converter"
+	"main/server/service/filetypes"
+	"main/server/service/quota"
+	"main/server/service/thumbnailer"
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// MaxFiles is how many "files[]" fields a single upload request may send.
+const MaxFiles = 20
 
+// FileUpload stores the "file" form field, responding with uploader.UploadResponse.
+// The "visibility" form value restricts who may download it (see controller.Visible), public by default.
+// The form is read with uploader.Receive, a file over its type's size limit is refused (413) before it's stored whole.
+// Errors are translated into the request's locale. htmx requests get html instead of json:
+// view.Uploaded on success, view.UploadError otherwise, retargeted to the error slot of the
+// widget named by the "widget" form value when there is one.
+// Requests sending "files[]" fields are handled by FilesUpload, those of a view.Dropzone come through Widget.
+// A "progress" query parameter names the upload's progress, see GET /upload/progress/:token.
+// A signed in uploader is charged for the file, one their quota has no room for is refused (403) with the quota.
+// An upload the client aborts leaves nothing behind but its model.FileAborted event, see uploader.Aborted.
 func FileUpload(ctx *controller.Context) error {
-	// var About model.Interface_about
-	// result := storage.DB.Last(&About)
-
-	// if result.Error != nil {
-	// 	return ctx.Html(view.ErrorPage())
-	// }
+	Tracker := progress.Start(ctx.QueryParam("progress"), ctx.Request().ContentLength, 0)
+	ctx.Request().Body = Tracker.Body(ctx.Request().Body)
+	ctx.Set("PROGRESS", Tracker)
 
-	// return ctx.Html(view.Terms(About.Terms))
-	// Retrieve the file from form data
-	file, err := ctx.FormFile("file")
+	Form, err := uploader.Receive(ctx.Request(), MaxFiles)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest, 
-			&uploader.UploadResponse{ ID: -1, Message: "Error retrieving file from form data", Success: false },
-		)
+		uploader.Aborted(ctx.Actor(), err)
+		return respond(ctx, &uploader.Received{}, uploader.Refused(err))
 	}
+	defer Form.Close()
+	Tracker.Processing()
 
-	// Open the uploaded file
-	src, err := file.Open()
-	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest, 
-			&uploader.UploadResponse{ ID: -1, Message: "Error opening received file", Success: false },
-		)
+	Visibility, ok := uploader.Visibility(Form.Values["visibility"])
+	if !ok { return respond(ctx, Form, uploader.Failed(uploader.CodeVisibility, "Unknown visibility " + Form.Values["visibility"])) }
+
+	if Files := Form.Named("files[]"); len(Files) > 0 { return FilesUpload(ctx, Form, Files, Visibility) }
+
+	Files := Form.Named("file")
+	if len(Files) == 0 { return respond(ctx, Form, uploader.Failed(uploader.CodeMissingFile, "Error retrieving file from form data")) }
+
+	if Refused := charge(ctx, Files[0].Size); Refused != nil { return respond(ctx, Form, Refused) }
+
+	Upload := uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.StoreVisible(Files[0].Content, Files[0].Name, Files[0].Size, Files[0].ContentType, filetypes.UploadContext(Form.Values["context"]), Visibility))
+	Upload = own(ctx, Upload)
+	Upload.Name = Files[0].Name
+	return respond(ctx, Form, Upload)
+}
+
+func respond(ctx *controller.Context, Form *uploader.Received, Upload *uploader.UploadResponse) error {
+	Upload.Localize(ctx.Locale())
+	if !Upload.Success { ctx.Log("Upload failed: ", Upload.Code, " ", Upload.Detail) }
+	tracker(ctx).Finish(Upload.Code)
+
+	if !ctx.Htmx().Request { return ctx.JSON(Upload.HTTPStatus(), Upload) }
+
+	if Upload.Success {
+		Field := Form.Values["field"]
+		if Field == "" { Field = "file_id" }
+		if widget(ctx) {
+			return ctx.Renders(http.StatusOK, view.UploadCard(Field, view.UploadedFile{ Success: true, ID: Upload.ID, URL: Upload.URL, Name: Upload.Name, Thumb: preview(Upload) }))
+		}
+		return ctx.Renders(http.StatusOK, view.Uploaded(Field, Upload.ID, Upload.URL, Upload.Name))
 	}
-	defer src.Close()
-	
-	// Calculate SHA-256 hash of the file contents
-	hash := sha256.New()
-	if _, err := io.Copy(hash, src); err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest, 
-			&uploader.UploadResponse{ ID: -1, Message: "Error calculating hash", Success: false },
-		)
+
+	/* htmx only swaps 4xx responses the FormErrors script lets through, 422 is one of them */
+	if Widget := Form.Values["widget"]; Widget != "" {
+		ctx.Response().Header().Set("HX-Retarget", "#upload-error-" + Widget)
+		ctx.Response().Header().Set("HX-Reswap", "innerHTML")
 	}
+	return ctx.Renders(http.StatusUnprocessableEntity, view.UploadError(Upload.Code, Upload.Message))
+}
 
-	// Reset src to the beginning to read again
-	src.Seek(0, 0)
-	extension := uploader.GetFileExtension(file)
-	hashName := hex.EncodeToString(hash.Sum(nil))
+// FilesUpload stores every file one after the other, each one through the pipeline of a single upload,
+// responding with the array of their uploader.UploadResponse in the order they were sent.
+// The request succeeds when at least one file was stored, otherwise it gets the status of the first rejection.
+// htmx requests get view.UploadedFiles.
+func FilesUpload(ctx *controller.Context, Form *uploader.Received, files []uploader.ReceivedFile, Visibility string) error {
+	Context := filetypes.UploadContext(Form.Values["context"])
+	Uploads := make([]*uploader.UploadResponse, 0, len(files))
+	Fragments := make([]view.UploadedFile, 0, len(files))
+	Status, Code := 0, ""
 
-	// Create a new file on the server to store the uploaded file
-	dst, err := os.Create("./public" + globals.Env.Uploads + hashName + extension)
-	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest, 
-			&uploader.UploadResponse{ ID: -1, Message: "Error creating file on server: " + globals.Env.Uploads + hashName + extension, Success: false },
-		)
+	for _, file := range files {
+		Upload := stored(ctx, file, Context, Visibility)
+
+		if Upload.Success {
+			Status = http.StatusOK
+		} else {
+			ctx.Log("Upload of ", file.Name, " failed: ", Upload.Code, " ", Upload.Detail)
+			if Status == 0 { Status, Code = Upload.HTTPStatus(), Upload.Code }
+		}
+
+		Uploads = append(Uploads, Upload)
+		Fragments = append(Fragments, fragment(Upload))
 	}
-	defer dst.Close()
-
-	// Copy the file from the form data to the destination file
-	if _, err = io.Copy(dst, src); err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest, 
-			&uploader.UploadResponse{ ID: -1, Message: "Error copying file to destination", Success: false },
-		)
+
+	if Status == http.StatusOK { Code = "" }
+	tracker(ctx).Finish(Code)
+
+	if !ctx.Htmx().Request { return ctx.JSON(Status, Uploads) }
+
+	Field := Form.Values["field"]
+	if Field == "" { Field = "file_ids" }
+	if widget(ctx) { return ctx.Renders(http.StatusOK, view.UploadCards(Field, Fragments)) }
+	return ctx.Renders(http.StatusOK, view.UploadedFiles(Field, Fragments))
+}
+
+/* One file of a multiple files upload, charged, stored and owned as a single one is, its response translated */
+func stored(ctx *controller.Context, File uploader.ReceivedFile, Context string, Visibility string) *uploader.UploadResponse {
+	Upload := charge(ctx, File.Size)
+	if Upload == nil {
+		Upload = own(ctx, uploader.Audited(ctx.Actor(), model.FileUploaded, uploader.StoreVisible(File.Content, File.Name, File.Size, File.ContentType, Context, Visibility)))
 	}
+	Upload.Name = File.Name
+	return Upload.Localize(ctx.Locale())
+}
 
-	if len(extension) < 2 {
-		return ctx.JSON(
-			http.StatusBadRequest, 
-			&uploader.UploadResponse{ ID: -1, Message: "File type " + extension + " has a problem", Success: false },
-		)
+func fragment(Upload *uploader.UploadResponse) view.UploadedFile {
+	return view.UploadedFile{
+		Success: Upload.Success, ID: Upload.ID, URL: Upload.URL, Name: Upload.Name, Code: Upload.Code, Message: Upload.Message, Thumb: preview(Upload),
 	}
+}
 
-	var Type model.File_types
-	result := storage.DB.Where(&model.File_types{Ext: extension[1:]}).Last(&Type)
+/* A signed in uploader's quota must have room for the file, nil when it has. Anonymous uploads aren't counted */
+func charge(ctx *controller.Context, Size int64) *uploader.UploadResponse {
+	User, ok := ctx.CurrentUser()
+	if !ok { return nil }
 
-	if result.Error != nil {
-		log.Print(result)
-		return ctx.JSON(
-			http.StatusBadRequest, 
-			&uploader.UploadResponse{ ID: -1, Message: "Server can't accept " + extension + " type files", Success: false },
-		)
+	Quota, err := quota.Check(User.ID, Size)
+	if errors.Is(err, quota.ErrExceeded) { return uploader.OverQuota(Quota, err) }
+	if err != nil {
+		ctx.Log("Checking upload quota: ", err)
+		return uploader.Failed(uploader.CodeDatabase, "Upload quota couldn't be checked")
 	}
+	return nil
+}
+
+/* The stored file is charged to its signed in uploader. One another upload took the room for meanwhile is removed
+   and refused */
+func own(ctx *controller.Context, Upload *uploader.UploadResponse) *uploader.UploadResponse {
+	User, ok := ctx.CurrentUser()
+	if !ok || !Upload.Success { return Upload }
 
-	var File model.Files = model.Files{
-		Name: hashName + extension,
-		Original: file.Filename,
-		Size: int(file.Size),
-		Location: globals.Env.Uploads,
-		Path: globals.Env.Uploads + hashName + extension,
-		Compressed: false,
-		Base64: "",
-		TypeID: int(Type.ID),
+	Quota, err := quota.Own(uint(Upload.ID), User.ID)
+	if errors.Is(err, quota.ErrExceeded) {
+		if err := uploader.Remove(ctx.Request().Context(), uint(Upload.ID)); err != nil { ctx.Log("Removing upload ", Upload.ID, " over quota: ", err) }
+		return uploader.OverQuota(Quota, err)
 	}
+	if err != nil { ctx.Log("Charging upload ", Upload.ID, ": ", err) }
+	return Upload
+}
+
+/* The tracker FileUpload started, nil when the upload isn't tracked */
+func tracker(ctx *controller.Context) *progress.Tracker {
+	Tracker, _ := ctx.Get("PROGRESS").(*progress.Tracker)
+	return Tracker
+}
 
-	Result := storage.DB.Create(&File)
-	if Result.Error != nil || Result.RowsAffected < 1 {
-		log.Print(Result)
-		return ctx.JSON(
-			http.StatusNotAcceptable, 
-			&uploader.UploadResponse{ ID: -1, Message: "File uploaded but was not saved in database", Success: false },
-		)
+/* The file of the ":id" parameter, by its public identifier or ID (ids.Match), uploader.ErrNotFound when there's none */
+func lookup(ctx *controller.Context, Scopes ...func(*gorm.DB) *gorm.DB) (model.Files, error) {
+	File, err := repository.Files.With(ctx.DB()).First(ctx.Request().Context(), append(Scopes, ids.Match(ctx.Param("id")))...)
+	if errors.Is(err, storage.ErrNotFound) { return File, uploader.ErrNotFound }
+	return File, err
+}
+
+func download(ctx *controller.Context) error {
+	File, err := lookup(ctx, storage.Preload("Derivatives"))
+	if errors.Is(err, uploader.ErrNotFound) { return ctx.String(http.StatusNotFound, "File not found") }
+	if err != nil { return err }
+
+	if ctx.QueryParam("download") != "" { return ctx.Download(File, controller.Attachment(), controller.Guard(streamed)) }
+	return ctx.Download(File, append(compressed(ctx, File), controller.Guard(streamed))...)
+}
+
+/* Videos are played through signed stream urls (see package stream), only users with PrivatePermission download them */
+func streamed(ctx *controller.Context, File model.Files) error {
+	if !strings.HasPrefix(File.MimeType(), "video/") || ctx.Can(controller.PrivatePermission) { return nil }
+	return domain.Forbidden("files", "videos are only streamed")
+}
+
+/* Images are shown in the smallest format the browser accepts (see converter.Best), a download is the upload itself */
+func compressed(ctx *controller.Context, File model.Files) []controller.DownloadOption {
+	if !File.Compressed { return nil }
+	ctx.Response().Header().Add("Vary", "Accept")
+
+	key, ok := converter.Best(File, 0, ctx.Request().Header.Get("Accept"))
+	if !ok { return nil }
+	return []controller.DownloadOption{ controller.Variant(key) }
+}
+
+// shared sends a file through a signed url made by uploader.SignURL, whoever asks and whatever the file's visibility.
+func shared(ctx *controller.Context) error {
+	if err := uploader.VerifyURL(ctx.Param("id"), ctx.QueryParam("expires"), ctx.QueryParam("signature")); err != nil { return err }
+
+	File, err := lookup(ctx, storage.Preload("Derivatives"))
+	if errors.Is(err, uploader.ErrNotFound) { return ctx.String(http.StatusNotFound, "File not found") }
+	if err != nil { return err }
+
+	ctx.Share(File)
+	if ctx.QueryParam("download") != "" { return ctx.Download(File, controller.Attachment()) }
+	return ctx.Download(File, compressed(ctx, File)...)
+}
+
+// Share answers with a signed url of the file, valid for the "hours" query parameter (24 by default),
+// it's registered on the admin group behind the "files.share" permission.
+func Share(ctx *controller.Context) error {
+	File, err := lookup(ctx)
+	if err != nil { return err }
+
+	Hours, err := strconv.Atoi(ctx.QueryParam("hours"))
+	if err != nil || Hours <= 0 { Hours = 24 }
+
+	URL, err := uploader.SignURL(File.ID, time.Duration(Hours) * time.Hour)
+	if err != nil { return err }
+	return ctx.JSON(http.StatusOK, map[string]string{ "url": URL })
+}
+
+// Approve clears the review of a file the malware scanner flagged (uploader.Approve), it's registered on the admin
+// group behind the "files.review" permission. A flagged file is refused with the DELETE route.
+func Approve(ctx *controller.Context) error {
+	File, err := lookup(ctx)
+	if err != nil { return err }
+
+	if err := uploader.Approve(File.ID); err != nil { return err }
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// resize sends an image resized by thumbnailer.Resize, e.g. /media/01HZX3K6Q4V8M2N7P9R5T1W3YB/resize?w=640&h=480&fit=cover.
+// Either side may be left out to follow the image's ratio, fit is contain by default.
+func resize(ctx *controller.Context) error {
+	File, err := lookup(ctx)
+	if errors.Is(err, uploader.ErrNotFound) { return ctx.String(http.StatusNotFound, "File not found") }
+	if err != nil { return err }
+
+	/* Nothing is resized for whoever can't download the file */
+	if err := ctx.Authorize(File); err != nil { return err }
+
+	Width, err := dimension(ctx.QueryParam("w"))
+	if err != nil { return thumbnailer.ErrSize }
+	Height, err := dimension(ctx.QueryParam("h"))
+	if err != nil { return thumbnailer.ErrSize }
+
+	key, err := thumbnailer.Resize(ctx.Request().Context(), File, Width, Height, thumbnailer.Fit(ctx.QueryParam("fit")))
+	if err != nil { return err }
+
+	/* The url answers the same image until the file is replaced, unless it's redirected to an expiring signed url.
+	   A url naming the file's version ("&v=2") of a public file never changes, the others are revalidated: shared
+	   caches mustn't keep what only some users may see */
+	if !globals.Env.S3_REDIRECT {
+		if ctx.QueryParam("v") != strconv.Itoa(File.Version) || !File.IsPublic() || File.UnderReview() {
+			ctx.CacheControl(0)
+			if ctx.ETag(key) { return nil }
+		} else {
+			ctx.Immutable()
+		}
 	}
+	return ctx.Download(File, controller.Variant(key), controller.Inline())
+}
+
+func dimension(value string) (int, error) {
+	if value == "" { return 0, nil }
+	return strconv.Atoi(value)
+}
+
+// Remove moves a file to the trash with uploader.Trash, it's registered behind the "files.delete" permission
+// (DELETE /upload/:id and the admin group's DELETE /files/:id). It's deleted for good from the trash, see package trash.
+func Remove(ctx *controller.Context) error {
+	File, err := lookup(ctx)
+	if err != nil { return err }
 
-	return ctx.JSON(
-		http.StatusOK, 
-		&uploader.UploadResponse{ ID: int(File.ID), Message: "Successfully uploaded", Success: true },
-	)
+	/* uploader.ErrNotFound and ErrReferenced are answered with 404 and 409 by controller.ErrorHandler */
+	if err := uploader.Trash(ctx.Request().Context(), File.ID); err != nil { return err }
+	uploader.Audit(ctx.Actor(), File.ID, model.FileTrashed, File.Original)
+	return ctx.NoContent(http.StatusOK)
 }
@@ -1,11 +1,32 @@
 package upload
 
 import (
+	"runtime"
+
 	"github.com/labstack/echo/v4"
 
 	"main/server/common/controller"
+	"main/server/middleware"
 )
 
 func Register(app *echo.Echo) {
-	app.POST("/upload", controller.Register(FileUpload))
-}
\ No newline at end of file
+	app.POST("/upload", controller.Register(FileUpload), middleware.Identify())
+	app.POST("/upload/from-url", controller.Register(FromURL), middleware.Auth(), middleware.Can("files.import"))
+	app.POST("/upload/archive", controller.Register(Archive), middleware.Auth(), middleware.Can("files.import"))
+	app.POST("/upload/widget", controller.Register(Widget), middleware.Identify())
+	app.DELETE("/upload/widget/:id", controller.Register(discard), middleware.Identify())
+	app.GET("/upload/progress/:token", controller.Register(progressed))
+	app.GET("/files/:id", controller.Register(download), middleware.Identify())
+	app.GET("/files/:id/shared", controller.Register(shared))
+	app.GET("/files/:id/transcoding", controller.Register(transcoding), middleware.Identify())
+	app.GET("/media/:id/resize", controller.Register(resize), middleware.Identify(), controller.Concurrency("resize", runtime.NumCPU()))
+	app.DELETE("/upload/:id", controller.Register(Remove), middleware.Auth(), middleware.Can("files.delete"))
+
+	/* Resumable uploads, see package resumable */
+	Tus := controller.Group(app.Group("/upload/tus", middleware.Identify()))
+	Tus.OPTIONS("", tus(tusOptions))
+	Tus.POST("", tus(tusCreate))
+	Tus.HEAD("/:token", tus(tusHead))
+	Tus.PATCH("/:token", tus(tusPatch))
+	Tus.DELETE("/:token", tus(tusDelete))
+}
@@ -0,0 +1,92 @@
+package upload
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"main/server/common/controller"
+	uploader "main/server/common/helpers"
+	"main/server/model"
+)
+
+// Version is an earlier content of a file as GET /admin/files/:id/versions answers it.
+type Version struct {
+	Version		int			`json:"version"`
+	Name		string		`json:"name"`
+	Size		int			`json:"size"`
+	MimeType	string		`json:"mimeType,omitempty"`
+	Replaced	time.Time	`json:"replaced"`
+}
+
+// Versions is a file's history, its current version and the earlier ones, the newest first.
+type Versions struct {
+	ID			string		`json:"id"`
+	Current		int			`json:"current"`
+	Versions	[]Version	`json:"versions"`
+}
+
+// Replace stores the "file" form field as the file's new content (uploader.Replace), responding with its
+// uploader.UploadResponse. The file keeps its ID and urls. It's registered on the admin group behind the
+// "files.replace" permission.
+func Replace(ctx *controller.Context) error {
+	File, err := lookup(ctx)
+	if err != nil { return err }
+
+	Form, err := uploader.Receive(ctx.Request(), 1)
+	if err != nil {
+		uploader.Aborted(ctx.Actor(), err)
+		return replaced(ctx, uploader.Refused(err))
+	}
+	defer Form.Close()
+
+	Files := Form.Named("file")
+	if len(Files) == 0 { return replaced(ctx, uploader.Failed(uploader.CodeMissingFile, "Error retrieving file from form data")) }
+
+	Upload, err := uploader.Replace(File.ID, Files[0].Content, Files[0].Name, Files[0].Size, Files[0].ContentType)
+	if err != nil { return err }
+	Upload.Name = Files[0].Name
+	return replaced(ctx, uploader.Audited(ctx.Actor(), model.FileReplaced, Upload))
+}
+
+func replaced(ctx *controller.Context, Upload *uploader.UploadResponse) error {
+	Upload.Localize(ctx.Locale())
+	if !Upload.Success { ctx.Log("Replacing file failed: ", Upload.Code, " ", Upload.Detail) }
+	return ctx.JSON(Upload.HTTPStatus(), Upload)
+}
+
+// History answers the versions of a file, it's registered on the admin group behind the "files.replace" permission.
+func History(ctx *controller.Context) error {
+	File, err := lookup(ctx)
+	if err != nil { return err }
+
+	Earlier, err := uploader.Versions(File.ID)
+	if err != nil { return err }
+
+	History := Versions{ ID: File.Ref(File.ID), Current: File.Version, Versions: make([]Version, len(Earlier)) }
+	for i, Found := range Earlier {
+		History.Versions[i] = Version{
+			Version: Found.Version,
+			Name: Found.Original,
+			Size: Found.Size,
+			MimeType: Found.Mime,
+			Replaced: Found.CreatedAt,
+		}
+	}
+	return ctx.JSON(http.StatusOK, History)
+}
+
+// Restore makes an earlier version the file's content again (uploader.Restore), responding with its
+// uploader.UploadResponse. It's registered on the admin group behind the "files.replace" permission.
+func Restore(ctx *controller.Context) error {
+	File, err := lookup(ctx)
+	if err != nil { return err }
+
+	Number, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil { return uploader.ErrVersionNotFound }
+
+	Upload, err := uploader.Restore(File.ID, Number)
+	if err != nil { return err }
+	if Upload.Success { uploader.Audit(ctx.Actor(), File.ID, model.FileRestored, "v" + strconv.Itoa(Number)) }
+	return replaced(ctx, Upload)
+}
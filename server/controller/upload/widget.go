@@ -0,0 +1,46 @@
+package upload
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"main/server/common/controller"
+	uploader "main/server/common/helpers"
+	"main/server/model"
+)
+
+// Widget stores the files a view.Dropzone sends, as FileUpload does, answering with their view.UploadCard
+// (view.UploadCards for "files[]") instead of view.Uploaded.
+func Widget(ctx *controller.Context) error {
+	ctx.Set("WIDGET", true)
+	return FileUpload(ctx)
+}
+
+/* Whether the upload came from a view.Dropzone, its answer is the files' cards */
+func widget(ctx *controller.Context) bool {
+	Widget, _ := ctx.Get("WIDGET").(bool)
+	return Widget
+}
+
+/* The card's preview, images show themselves */
+func preview(Upload *uploader.UploadResponse) string {
+	if !strings.HasPrefix(Upload.MimeType, "image/") { return "" }
+	return Upload.URL
+}
+
+// discard takes an UploadCard off its form. The file goes with it when nothing uses it yet and it's the signed in
+// user's own upload, a card of a file some record still uses (an edit form's) only leaves the form.
+func discard(ctx *controller.Context) error {
+	File, err := lookup(ctx)
+	if errors.Is(err, uploader.ErrNotFound) { return ctx.NoContent(http.StatusOK) }
+	if err != nil { return err }
+
+	User, ok := ctx.CurrentUser()
+	if !ok || File.OwnerID == nil || *File.OwnerID != User.ID { return ctx.NoContent(http.StatusOK) }
+
+	err = uploader.Remove(ctx.Request().Context(), File.ID)
+	if err == nil { uploader.Audit(ctx.Actor(), File.ID, model.FileDeleted, File.Original) }
+	if err != nil && !errors.Is(err, uploader.ErrReferenced) && !errors.Is(err, uploader.ErrNotFound) { return err }
+	return ctx.NoContent(http.StatusOK)
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/preview"
+	"main/server/common/route"
+	"main/server/service/analytics"
+)
+
+// Analytics counts the views of the pages served by named routes (see analytics.Hit). Pages of a record are counted
+// under its ID once their handler found it (ctx.Viewed), never under the identifier of the url. htmx fragments (ctx.IsHtmx) are counted apart from full page loads.
+// Previews, failed requests and crawlers aren't counted.
+func Analytics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			if err := next(ctx); err != nil { return err }
+
+			Request := ctx.Request()
+			if Request.Method != http.MethodGet || ctx.Response().Status != http.StatusOK { return nil }
+			if preview.Channel(Request.Context()) != "" || crawler(Request.UserAgent()) { return nil }
+
+			Route, ok := route.NameOf(ctx.Path())
+			if !ok { return nil }
+
+			/* The url's identifier is whatever the client sent, only a record the handler found is counted */
+			Param := ""
+			if len(ctx.ParamValues()) > 0 {
+				ID, ok := ctx.Get("VIEWED").(uint)
+				if !ok { return nil }
+				Param = strconv.Itoa(int(ID))
+			}
+			analytics.Hit(Route, Param, ctx.IsHtmx())
+			return nil
+		})
+	}
+}
+
+/* Most crawlers say so in their user agent, the others are counted as visitors */
+func crawler(UserAgent string) bool {
+	UserAgent = strings.ToLower(UserAgent)
+	if UserAgent == "" { return true }
+	for _, Mark := range []string{"bot", "crawl", "spider", "slurp", "preview"} {
+		if strings.Contains(UserAgent, Mark) { return true }
+	}
+	return false
+}
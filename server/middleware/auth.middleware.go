@@ -2,13 +2,16 @@ package middleware
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"main/build/view"
 	"main/server/common/controller"
+	"main/server/common/domain"
 	"main/server/common/storage"
 	"main/server/model"
+	"main/server/service/auth"
 )
 
 type AuthHeaderCreds struct {
@@ -19,31 +22,118 @@ type AuthHeaderCreds struct {
 func Auth() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return controller.Register(func(ctx *controller.Context) error {
-			var User model.Users
-			var Parameters AuthHeaderCreds
-
 			ctx.Set("ISADMIN", false)
 
-			err := (&echo.DefaultBinder{}).BindHeaders(ctx, &Parameters);
-			if err != nil || Parameters.Email == "" || Parameters.Token == ""{
-				Parameters.Email = ctx.ReadCookie("user").Value
-				Parameters.Token = ctx.ReadCookie("token").Value
+			User, Session, Parameters, ok := authenticate(ctx)
+			if !ok {
+				if User, ok = recall(ctx); !ok {
+					if Parameters.Email == "" || Parameters.Token == "" {
+						return ctx.Renders(http.StatusBadRequest, view.Login())
+					}
+					return ctx.Renders(http.StatusUnauthorized, view.Login())
+				}
 			}
 
-			if Parameters.Email == "" || Parameters.Token == "" {
-				return ctx.Renders(http.StatusBadRequest, view.Login())
-			}
+			signIn(ctx, User)
+			if Session != nil && auth.Elevated(*Session, ctx.Grants()) { rotate(ctx, User, Parameters.Token) }
+			return next(ctx)
+		})
+	}
+}
 
-			result := storage.DB.Where(&model.Users{Email: Parameters.Email, Token: Parameters.Token}).Last(&User)
-			if result.Error != nil || result.RowsAffected < 1 { 
-				return ctx.Renders(http.StatusUnauthorized, view.Login())
-			}
+// Identify sets the user of requests carrying valid credentials, as Auth does, and lets the others through anonymous,
+// for public routes which serve signed in users more (file downloads, see controller.Visible).
+// The remember-me cookie isn't recalled, rotating it on every image of a page would race.
+func Identify() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			if User, _, _, ok := authenticate(ctx); ok { signIn(ctx, User) }
+			return next(ctx)
+		})
+	}
+}
+
+/* Credentials come from the X-User and X-Token headers, or the session cookies. The token is a session's,
+   or the user's own token, which api clients send and which never changes: it's never put in a cookie */
+func authenticate(ctx *controller.Context) (model.Users, *model.Sessions, AuthHeaderCreds, bool) {
+	var Headers AuthHeaderCreds
+	(&echo.DefaultBinder{}).BindHeaders(ctx, &Headers)
+	Cookies := AuthHeaderCreds{ Email: ctx.ReadCookie("user").Value, Token: ctx.ReadCookie("token").Value }
+
+	/* The admin's scripts may send a token the cookies already replaced */
+	for _, Parameters := range []AuthHeaderCreds{ Headers, Cookies } {
+		if Parameters.Email == "" || Parameters.Token == "" { continue }
 
-			ctx.Set("ISADMIN", true)
-			ctx.Set("USER", User)
+		if User, Session, err := auth.Session(Parameters.Email, Parameters.Token); err == nil { return User, &Session, Parameters, true }
 
+		var User model.Users
+		result := storage.DB.Where(&model.Users{Email: Parameters.Email, Token: Parameters.Token}).Last(&User)
+		if result.Error == nil && result.RowsAffected > 0 { return User, nil, Parameters, true }
+	}
+
+	if Headers.Email != "" && Headers.Token != "" { return model.Users{}, nil, Headers, false }
+	return model.Users{}, nil, Cookies, false
+}
+
+func signIn(ctx *controller.Context, User model.Users) {
+	ctx.Set("ISADMIN", true)
+	ctx.Set("USER", User)
+	ctx.SetGrants(func() (controller.Grants, error) { return auth.Grants(User.ID) })
+	if User.Locale != "" { ctx.SetLocale(User.Locale) }
+}
+
+/* A session which got a role is replaced, its old token stops working after auth.SessionGrace */
+func rotate(ctx *controller.Context, User model.Users, Previous string) {
+	Token, err := auth.StartSession(User, ctx.Grants(), ctx.Request().UserAgent(), ctx.RealIP(), Previous)
+	if err != nil {
+		ctx.Log("Rotating session: ", err)
+		return
+	}
+	auth.WriteSession(ctx, User, Token)
+}
+
+/* Falls back to the remember-me cookie, rotating it and starting a new session on success */
+func recall(ctx *controller.Context) (model.Users, bool) {
+	if !ctx.HasCookie(auth.RememberCookie) { return model.Users{}, false }
+
+	User, Remember, err := auth.Recall(ctx.ReadCookie(auth.RememberCookie).Value)
+	if err != nil {
+		ctx.DeleteCookie(auth.RememberCookie)
+		return model.Users{}, false
+	}
+
+	Grants, err := auth.Grants(User.ID)
+	if err != nil { return model.Users{}, false }
+	Token, err := auth.StartSession(User, Grants, ctx.Request().UserAgent(), ctx.RealIP(), ctx.ReadCookie("token").Value)
+	if err != nil { return model.Users{}, false }
+
+	ctx.WriteCookie(controller.NewCookie(auth.RememberCookie, Remember, time.Now().Add(auth.RememberFor)))
+	auth.WriteSession(ctx, User, Token)
+	return User, true
+}
+
+// Can only lets users having the permission through, others get 403, or 404 when the permission's resource
+// is hidden (see controller.Hides). It has to run after Auth.
+//
+// Example usage:
+//   admin.DELETE("/files/:id", controller.Register(remove), middleware.Can("files.delete"))
+func Can(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			if !ctx.Can(permission) { return domain.Forbidden(controller.PermissionResource(permission), "Missing permission: " + permission) }
 			return next(ctx)
 		})
 	}
 }
 
+// Role only lets users having the role through, others get 403, or 404 when the role is hidden.
+func Role(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			if !ctx.HasRole(role) && !ctx.HasRole(controller.SuperRole) {
+				return domain.Forbidden(role, "Missing role: " + role)
+			}
+			return next(ctx)
+		})
+	}
+}
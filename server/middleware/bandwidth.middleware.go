@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/bandwidth"
+	"main/server/common/controller"
+	"main/server/model"
+)
+
+// Bandwidth puts the request in low-bandwidth mode or not (see package bandwidth): the "lite" query parameter
+// (on or off, remembered in the bandwidth cookie), the bandwidth cookie, the Save-Data header and the site's setting.
+// It follows Interface, whose row carries the site's setting on full page loads.
+func Bandwidth() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			if Interface, ok := ctx.Get("Interface").(model.Interface); ok {
+				bandwidth.SetSite(Interface.LowBandwidth)
+			} else if !bandwidth.Known() {
+				/* htmx requests don't load the Interface, the first one reaching the instance reads the setting */
+				var Interface model.Interface
+				if ctx.DB().Select("id", "low_bandwidth").Last(&Interface).Error == nil { bandwidth.SetSite(Interface.LowBandwidth) }
+			}
+
+			Choice := ctx.QueryParam("lite")
+			if Choice == bandwidth.On || Choice == bandwidth.Off {
+				ctx.WriteCookie(controller.NewCookie(bandwidth.Cookie, Choice, time.Now().Add(365 * 24 * time.Hour)))
+			} else {
+				Choice = ctx.ReadCookie(bandwidth.Cookie).Value
+			}
+
+			Low := bandwidth.Resolve(Choice, ctx.Request().Header.Get("Save-Data"))
+			/* Caches must keep the variants of both modes apart */
+			ctx.Response().Header().Add("Vary", "Save-Data")
+			ctx.SetRequest(ctx.Request().WithContext(bandwidth.WithLow(ctx.Request().Context(), Low)))
+			return next(ctx)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/globals"
+	"main/server/service/chaos"
+)
+
+// Chaos injects the faults configured in CHAOS (see package chaos) into the requests, on development and staging only.
+// Every request a fault is injected into says so in its X-Chaos response header, dropped ones have no response at all.
+func Chaos() echo.MiddlewareFunc {
+	if globals.Env.CHAOS != "" && !chaos.Allowed(globals.Env.GOENV) {
+		log.Print("CHAOS is ignored on ", globals.Env.GOENV)
+	} else if err := chaos.Load(globals.Env.CHAOS); err != nil {
+		log.Print("Loading chaos rules: ", err)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !chaos.Enabled() { return next }
+
+		return controller.Register(func(ctx *controller.Context) error {
+			Fault := chaos.Roll(ctx.Request().Method, ctx.Request().URL.Path)
+			if Fault == (chaos.Fault{}) { return next(ctx) }
+			ctx.Response().Header().Set("X-Chaos", Fault.String())
+
+			select {
+				case <-time.After(Fault.Latency):
+				case <-ctx.Request().Context().Done():
+					return nil
+			}
+
+			switch {
+				case Fault.Drop:
+					return drop(ctx)
+				case Fault.Error != 0:
+					return echo.NewHTTPError(Fault.Error, "injected by chaos")
+			}
+			return next(ctx)
+		})
+	}
+}
+
+/* Closes the connection as a crashed server would, http.ErrAbortHandler where it can't be taken over (HTTP/2) */
+func drop(ctx *controller.Context) error {
+	Connection, _, err := ctx.Response().Hijack()
+	if err != nil { panic(http.ErrAbortHandler) }
+	return Connection.Close()
+}
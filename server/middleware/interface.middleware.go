@@ -5,18 +5,18 @@ import (
 
 	"main/build/view"
 	"main/server/common/controller"
-	"main/server/common/storage"
 	"main/server/model"
+	"main/server/service/setup"
 )
 
 func Interface() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return controller.Register(func(ctx *controller.Context) error {
-			if ctx.IsHtmx() { return next(ctx) }
+			if ctx.IsHtmx() || !setup.Installed() { return next(ctx) }
 
 			var Interface model.Interface
-			result := storage.DB.Preload("Contact").Preload("SocialMedia").Last(&Interface)
-			if result.Error != nil { return ctx.Html(view.ErrorPage()) }
+			result := ctx.DB().Preload("Contact").Preload("SocialMedia").Last(&Interface)
+			if result.Error != nil { return ctx.Html(view.ErrorPage(ctx.RequestID())) }
 
 			ctx.Set("Interface", Interface)
 			return next(ctx)
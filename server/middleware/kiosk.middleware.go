@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/service/kiosk"
+)
+
+// Cookieless drops the cookies of requests under the prefix before routing, so no middleware (preview, locale,
+// authentication, ...) acts on them, and strips every cookie set on their responses. Register it with app.Pre:
+// a screen sharing a browser with a staff session mustn't render what that session sees.
+func Cookieless(Prefix string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !strings.HasPrefix(ctx.Request().URL.Path, Prefix) { return next(ctx) }
+
+			ctx.Request().Header.Del("Cookie")
+			ctx.Response().Before(func() { ctx.Response().Header().Del("Set-Cookie") })
+			return next(ctx)
+		}
+	}
+}
+
+// Kiosk authenticates a kiosk display by the :token route parameter (see package kiosk) and sets the headers of its
+// pages: frame-ancestors of the display, never cached or indexed. The display is stored in the context as "KIOSK".
+// Unknown tokens are answered with 404.
+func Kiosk() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			Display, err := kiosk.Find(ctx.Param("token"))
+			if err != nil { return ctx.NoContent(http.StatusNotFound) }
+
+			Header := ctx.Response().Header()
+			Header.Set("Content-Security-Policy", kiosk.FrameAncestors(Display))
+			Header.Set("X-Robots-Tag", "noindex, nofollow")
+			Header.Set("Cache-Control", "no-store")
+
+			kiosk.Seen(Display)
+			ctx.Set("KIOSK", Display)
+			return next(ctx)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/globals"
+	uploader "main/server/common/helpers"
+)
+
+// BodyLimit bounds every request body to UPLOAD_MAX_SIZE. A body declared larger (Content-Length) is refused
+// with 413 before it's read, an undeclared one is cut off when it reaches the limit and its reader fails
+// with *http.MaxBytesError, which uploader.Receive reports as uploader.ErrTooLarge.
+func BodyLimit() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			Limit := globals.Env.UPLOAD_MAX_SIZE
+			if ctx.Request().ContentLength > Limit {
+				return fmt.Errorf("%w: the request is limited to %d bytes", uploader.ErrTooLarge, Limit)
+			}
+
+			ctx.Request().Body = http.MaxBytesReader(ctx.Response(), ctx.Request().Body, Limit)
+			return next(ctx)
+		})
+	}
+}
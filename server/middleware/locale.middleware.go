@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/i18n"
+)
+
+const LocaleCookie = "locale"
+
+// Locale negotiates the locale of the request, in order of precedence:
+// the "lang" query parameter (remembered in the locale cookie), the locale cookie and the Accept-Language header.
+// Auth middleware overrides it with the user's profile locale.
+func Locale() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			Query := ctx.QueryParam("lang")
+			if Query != "" && i18n.IsSupported(Query) {
+				ctx.WriteCookie(controller.NewCookie(LocaleCookie, Query, time.Now().Add(365 * 24 * time.Hour)))
+			}
+
+			Candidates := append(
+				[]string{ Query, ctx.ReadCookie(LocaleCookie).Value },
+				i18n.ParseAcceptLanguage(ctx.Request().Header.Get("Accept-Language"))...,
+			)
+
+			ctx.SetRequest(ctx.Request().WithContext(i18n.WithLocale(ctx.Request().Context(), i18n.Negotiate(Candidates...))))
+			return next(ctx)
+		})
+	}
+}
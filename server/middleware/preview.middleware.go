@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/globals"
+	"main/server/common/preview"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/previewer"
+)
+
+// Preview renders the site with the staged changes of the preview channel stored in the preview cookie.
+// The changes are applied inside a transaction which is handed to controllers through ctx.DB() and
+// always rolled back, nothing a preview request does reaches the database.
+//
+// A preview link (previewer.Share) previews its page alone, on GET requests, every one of them is recorded
+// as a visit of the link. Other requests of its holder see the live site.
+func Preview() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			if !previewing(ctx) { return next(ctx) }
+
+			Channel, ok := previewed(ctx)
+			if !ok { return next(ctx) }
+
+			tx := storage.DB.Begin()
+			if tx.Error != nil { return next(ctx) }
+			defer tx.Rollback()
+
+			if err := previewer.Apply(tx, Channel.ID); err != nil {
+				ctx.Log("Applying preview channel ", Channel.ID, ": ", err)
+				return next(ctx)
+			}
+
+			ctx.Set("DB", tx)
+			ctx.SetRequest(ctx.Request().WithContext(preview.WithChannel(ctx.Request().Context(), Channel.Name)))
+			return next(ctx)
+		})
+	}
+}
+
+/* Only pages are previewed, and only for holders of a preview cookie: other requests don't touch the database */
+func previewing(ctx *controller.Context) bool {
+	Path := ctx.Request().URL.Path
+	for _, Static := range []string{ "/assets/", globals.Env.Uploads, resizedPrefix } {
+		if strings.HasPrefix(Path, Static) { return false }
+	}
+
+	for _, Name := range []string{ preview.Cookie, preview.LinkCookie } {
+		if ctx.HasCookie(Name) { return true }
+	}
+	return false
+}
+
+/* The channel the request previews: the staff's one, or the one of a link for its page */
+func previewed(ctx *controller.Context) (model.Preview_channels, bool) {
+	if Cookie, err := ctx.ReadSignedCookie(preview.Cookie); err == nil {
+		Channel, err := previewer.Find(Cookie.Value)
+		if err == nil { return Channel, true }
+		ctx.DeleteCookie(preview.Cookie)
+	}
+
+	Cookie, err := ctx.ReadSignedCookie(preview.LinkCookie)
+	if err != nil { return model.Preview_channels{}, false }
+
+	Link, Channel, err := previewer.FindLink(Cookie.Value)
+	if err != nil {
+		ctx.DeleteCookie(preview.LinkCookie)
+		return Channel, false
+	}
+
+	Request := ctx.Request()
+	if Request.Method != http.MethodGet || Request.URL.Path != Link.Path { return Channel, false }
+
+	if err := previewer.Visit(Link, Request.URL.RequestURI(), ctx.RealIP(), Request.UserAgent()); err != nil {
+		ctx.Log("Recording preview link ", Link.ID, " visit: ", err)
+	}
+	return Channel, true
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+)
+
+/* Incoming IDs end up in logs and html, anything unusual gets replaced */
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID assigns every request an ID, reusing an incoming X-Request-ID header when it looks sane.
+// The ID is stored on the request context (ctx.RequestID()) and returned in the X-Request-ID response header,
+// along with who makes the request (see controller.WithActor).
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			RequestID := ctx.Request().Header.Get(echo.HeaderXRequestID)
+			if !validRequestID.MatchString(RequestID) {
+				random := make([]byte, 16)
+				rand.Read(random)
+				RequestID = hex.EncodeToString(random)
+			}
+
+			Context := controller.WithActor(controller.WithRequestID(ctx.Request().Context(), RequestID), ctx.Actor)
+			ctx.SetRequest(ctx.Request().WithContext(Context))
+			ctx.Response().Header().Set(echo.HeaderXRequestID, RequestID)
+
+			return next(ctx)
+		})
+	}
+}
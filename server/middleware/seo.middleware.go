@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/preview"
+	"main/server/common/seo"
+	"main/server/service/indexer"
+)
+
+// SEO puts the indexing directives of the requested page (see indexer.Resolve) on the request context, for the SEO
+// templ component, and in the X-Robots-Tag header when the page isn't indexed or followed, so files and fragments
+// carry them too. Previews are never indexed.
+func SEO() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			Request := ctx.Request()
+			Page := indexer.Resolve(Request.URL.Path)
+			if preview.Channel(Request.Context()) != "" { Page.NoIndex, Page.NoFollow = true, true }
+
+			if Page.NoIndex || Page.NoFollow { ctx.Response().Header().Set("X-Robots-Tag", Page.Robots()) }
+			ctx.SetRequest(Request.WithContext(seo.WithDirectives(Request.Context(), Page)))
+			return next(ctx)
+		})
+	}
+}
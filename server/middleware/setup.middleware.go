@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/service/setup"
+)
+
+// Setup sends every request to the first-run wizard until the site is installed.
+func Setup() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			Path := ctx.Request().URL.Path
+			if setup.Installed() || strings.HasPrefix(Path, "/setup") || strings.HasPrefix(Path, "/assets/") {
+				return next(ctx)
+			}
+
+			if ctx.Htmx().Request {
+				ctx.Response().Header().Set("HX-Redirect", "/setup")
+				return ctx.NoContent(http.StatusOK)
+			}
+			return ctx.Redirect(http.StatusSeeOther, "/setup")
+		})
+	}
+}
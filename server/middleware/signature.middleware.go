@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/signature"
+)
+
+// Signed only lets through requests signed with INTERNAL_SECRET (see package signature), for the endpoints
+// other services call. Other requests are refused as the "internal" resource, ACCESS_HIDE=internal answers them with 404.
+func Signed() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			if err := signature.Verify(ctx.Request()); err != nil { return err }
+			return next(ctx)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"main/server/service/telemetry"
+)
+
+// Telemetry counts the requests of each route and module (see telemetry.Request), when the deployment opted in.
+// Requests no route matched aren't counted, nor is anything about who made them.
+func Telemetry() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !telemetry.Enabled() { return next }
+		return func(ctx echo.Context) error {
+			err := next(ctx)
+			if Path := ctx.Path(); Path != "" && Path != "/*" { telemetry.Request(ctx.Request().Method, Path) }
+			return err
+		}
+	}
+}
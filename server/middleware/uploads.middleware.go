@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"main/server/common/controller"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+/* Resized copies are cached under this prefix followed by the file's key, see thumbnailer.ResizedKey */
+const resizedPrefix = "/cache/resize"
+
+// PrivateUploads answers 404 to the direct requests of stored files (./public is served as is) which aren't public
+// or wait for review, and of their earlier versions, thumbnails, sprites and resized copies, so they're only downloaded through /files/:id
+// and its guards. Trashed files are hidden too, and so is everything when the files can't be looked up.
+//
+// Notes:
+//   - Videos and their renditions are only streamed through signed urls (see package stream), never served directly.
+//   - Uploads are content addressed, content also stored as a public file stays reachable.
+//   - Blobs on S3 are out of its reach, the bucket mustn't be public (S3_PUBLIC_URL) if files aren't.
+func PrivateUploads() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return controller.Register(func(ctx *controller.Context) error {
+			/* The static handler cleans the path before serving it, //uploads/... and /x/../uploads/... are uploads too */
+			Path := strings.TrimPrefix(path.Clean("/" + ctx.Request().URL.Path), resizedPrefix)
+			if !strings.HasPrefix(Path, globals.Env.Uploads) { return next(ctx) }
+			if strings.HasPrefix(mime.TypeByExtension(path.Ext(Path)), "video/") { return echo.ErrNotFound }
+
+			/* Variants are named after the file: <hash>.<ext>.<anything> */
+			Parts := strings.SplitN(path.Base(Path), ".", 3)
+			if len(Parts) < 2 { return next(ctx) }
+			File := path.Join(path.Dir(Path), Parts[0] + "." + Parts[1])
+
+			/* A trashed file keeps its blobs until it's purged, it's hidden meanwhile */
+			var Files []model.Files
+			err := storage.DB.Scopes(storage.WithTrashed).Select(model.FilesVisibility, model.FilesReview, model.FilesDeletedAt).
+				Where("path = ?", File).Find(&Files).Error
+			if err != nil {
+				ctx.Log("Looking up upload ", File, ": ", err)
+				return echo.ErrNotFound
+			}
+			if len(Files) > 0 {
+				if visible(Files) { return next(ctx) }
+				return echo.ErrNotFound
+			}
+
+			/* The earlier content of a replaced file is only known by its version, it's as visible as the file */
+			err = storage.DB.Scopes(storage.WithTrashed).Select("files.visibility", "files.review", "files.deleted_at").
+				Joins("JOIN file_versions ON file_versions.file_id = files.id").Where("file_versions.path = ?", File).Find(&Files).Error
+			if err != nil {
+				ctx.Log("Looking up upload ", File, ": ", err)
+				return echo.ErrNotFound
+			}
+			if len(Files) > 0 && !visible(Files) { return echo.ErrNotFound }
+			return next(ctx)
+		})
+	}
+}
+
+/* Content is served when one of the files storing it is public, not waiting for review and not trashed */
+func visible(Files []model.Files) bool {
+	for _, Stored := range Files {
+		if Stored.IsPublic() && !Stored.UnderReview() && !Stored.DeletedAt.Valid { return true }
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Page_views are the daily rollups of the public pages' views, one row per named route, record (Param, the url's
+// identifier, "" for list pages) and day. Fragment rows count the htmx requests which rendered part of the page.
+// See package analytics.
+type Page_views struct {
+	gorm.Model
+	Route 			string 		`gorm:"size:64;uniqueIndex:idx_page_views_day"`
+	Param 			string 		`gorm:"size:64;uniqueIndex:idx_page_views_day"`
+	Fragment 		bool 		`gorm:"uniqueIndex:idx_page_views_day"`
+	Day 			time.Time 	`gorm:"type:date;uniqueIndex:idx_page_views_day"`
+	Views 			int
+	LastViewedAt 	time.Time
+}
@@ -0,0 +1,29 @@
+package model
+
+import "gorm.io/gorm"
+
+// What happened to an audited row, the Action of its Audits.
+const (
+	AuditCreated = "created"
+	AuditUpdated = "updated"
+	AuditDeleted = "deleted"		/* soft deleted, it's in the trash */
+	AuditRestored = "restored"		/* brought back from the trash */
+	AuditPurged = "purged"			/* deleted for good */
+)
+
+// Audits are the history of the rows of the models package audit is told about: who created, changed or deleted
+// them and what changed. Entity is the model's table, Changes a JSON object of the columns which changed, each with
+// its value before and after ({"name": ["Old", "New"]}, null for a value it didn't have). UsersID is nil for changes
+// made by nobody signed in or outside a request (a job, a command).
+type Audits struct {
+	gorm.Model
+	Entity			string			`gorm:"size:64;index:idx_audits_entity"`
+	EntityID		uint			`gorm:"index:idx_audits_entity"`
+	Action			string			`gorm:"size:16"`
+	UsersID			*uint			`gorm:"index"`
+	Users			Users			`gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;foreignKey:UsersID"`
+	IP				string			`gorm:"size:64"`
+	UserAgent		string			`gorm:"size:512"`
+	RequestID		string			`gorm:"size:64"`
+	Changes			string
+}
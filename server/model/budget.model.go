@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Budget_reports are what the performance budget check measured of a page (see package budgeter), one row per page
+// and run, the rows of a run share its CheckedAt. Sizes are in bytes, Exceeded lists the budgets the page went over.
+type Budget_reports struct {
+	gorm.Model
+	Path 			string 		`gorm:"size:255"`
+	Status 			string 		`gorm:"size:16"`
+	Payload 		int64
+	Blocking 		int
+	Images 			int64
+	LargestImage 	int64
+	Exceeded 		string
+	Error 			string
+	CheckedAt 		time.Time 	`gorm:"index"`
+}
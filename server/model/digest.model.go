@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Digests struct {
+	gorm.Model
+	PeriodStart		time.Time
+	PeriodEnd		time.Time
+	Recipients		int
+}
+
+// Digest_deliveries are the admins a digest was mailed to, the next run only sends it to the others.
+type Digest_deliveries struct {
+	gorm.Model
+	DigestsID		uint			`gorm:"uniqueIndex:idx_digest_delivery"`
+	UsersID			uint			`gorm:"uniqueIndex:idx_digest_delivery"`
+}
+
+type Job_failures struct {
+	gorm.Model
+	Job				string			`gorm:"index"`
+	Error			string
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"main/server/common/ids"
+)
+
+// External gives a model a public identifier (see package ids) to use in urls and apis instead of its sequential ID.
+// It's given on create, rows older than it get theirs from cmd/migrate (ids.Backfill).
+//
+// Example usage:
+//   type News struct {
+//       gorm.Model
+//       External
+//       ...
+//   }
+//
+//   route.URL("news.detail", News.Ref(News.ID))
+type External struct {
+	PublicID		*string		`gorm:"uniqueIndex;size:36"`
+}
+
+func (external *External) BeforeCreate(tx *gorm.DB) error {
+	if external.PublicID == nil {
+		ID := ids.New()
+		external.PublicID = &ID
+	}
+	return nil
+}
+
+// Ref returns the identifier to put in urls: the public one, the ID while the row has none.
+func (external External) Ref(ID uint) string {
+	if external.PublicID != nil { return *external.PublicID }
+	return strconv.Itoa(int(ID))
+}
@@ -1,23 +1,242 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"mime"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
 
 type Files struct {
 	gorm.Model
+	External
 	Name 			string
 	Original 		string
 	Location 		string
 	Path 			string
 	Size 			int
+	Mime 			string
 	Base64 			string
 	Compressed 		bool
 	TypeID 			int
 	Type 			File_types 		`gorm:"constraint: OnUpdate:CASCADE, OnDelete:SET NULL;"`
+	Thumbnails 		[]File_thumbnails 	`gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:FileID"`
+	Metadata 		File_metadata 		`gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:FileID"`
+	Transcoding 	string 			`gorm:"size:16"`
+	Sprite 			bool 								/* its timeline sprite and track are stored, see transcoder.Sprites */
+	Renditions 		[]File_renditions 	`gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:FileID"`
+	Visibility 		string 			`gorm:"size:16;default:public"`
+	Review 			string 			`gorm:"size:16"`
+	OwnerID 		*uint 			`gorm:"index"`		/* the signed in user who uploaded it, counted in their quota */
+	Version 		int 			`gorm:"default:1"`
+	Versions 		[]File_versions 	`gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:FileID"`
+	Derivatives 	[]File_derivatives 	`gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:FileID"`
+}
+
+// Who may download a file, see controller.Visible.
+const (
+	VisibilityPublic = "public"
+	VisibilityAuthenticated = "authenticated"	/* any signed in user */
+	VisibilityAdmin = "admin"					/* users with the "files.private" permission */
+)
+
+// IsPublic reports whether anyone may download the file, rows recorded before Visibility existed are public.
+func (File Files) IsPublic() bool {
+	return File.Visibility == "" || File.Visibility == VisibilityPublic
+}
+
+// ReviewPending marks a file the malware scanner flagged (SCAN_ACTION=flag), it stays for admins only until reviewed.
+const ReviewPending = "pending"
+
+// UnderReview reports whether the file waits for an admin's review, see uploader.Approve.
+func (File Files) UnderReview() bool {
+	return File.Review == ReviewPending
+}
+
+// Inline returns the image as a data uri when it's small enough to be kept as one (INLINE_MAX), "" otherwise.
+// Only public files are inlined, the others must go through their download guards.
+func (File Files) Inline() string {
+	if !File.IsPublic() || File.UnderReview() { return "" }
+	return File.Base64
+}
+
+// Src returns what an img's src should be: the inline data uri if there's one, the thumbnail of the width otherwise.
+func (File Files) Src(Width int) string {
+	if Inline := File.Inline(); Inline != "" { return Inline }
+	return File.Thumbnail(Width)
+}
+
+// MimeType returns the content type sniffed from the file when it was uploaded (filetypes.Detect),
+// files uploaded before sniffing fall back to their extension's.
+func (File Files) MimeType() string {
+	if File.Mime != "" { return File.Mime }
+	return mime.TypeByExtension(filepath.Ext(File.Name))
+}
+
+// File_thumbnails are the downscaled copies of an image upload, one per configured width (THUMBNAIL_SIZES).
+type File_thumbnails struct {
+	gorm.Model
+	FileID 		uint 		`gorm:"index"`
+	Width 		int
+	Height 		int
+	Path 		string
+}
+
+// Thumbnail returns the path of the narrowest thumbnail at least Width wide, the original when none is.
+// Thumbnails must be preloaded ("Thumbnail.Thumbnails"), and Metadata for Srcset ("Thumbnail.Metadata").
+//
+// Example usage:
+//   <img src={ News.Thumbnail.Thumbnail(480) } srcset={ News.Thumbnail.Srcset() } />
+func (File Files) Thumbnail(Width int) string {
+	Best := File.Path
+	BestWidth := 0
+	for _, Thumbnail := range File.Thumbnails {
+		if Thumbnail.Width >= Width && (BestWidth == 0 || Thumbnail.Width < BestWidth) {
+			Best, BestWidth = Thumbnail.Path, Thumbnail.Width
+		}
+	}
+	return Best
+}
+
+// Srcset returns the thumbnails then the image itself, the widest, as an img srcset
+// ("/uploads/x.jpg.160.jpg 160w, ..., /uploads/x.jpg 2400w"), "" without thumbnails. The image is left out while
+// its width isn't known (Metadata).
+func (File Files) Srcset() string {
+	if len(File.Thumbnails) == 0 { return "" }
+	Thumbnails := append([]File_thumbnails{}, File.Thumbnails...)
+	sort.Slice(Thumbnails, func(i, j int) bool { return Thumbnails[i].Width < Thumbnails[j].Width })
+
+	Sources := make([]string, len(Thumbnails))
+	for i, Thumbnail := range Thumbnails { Sources[i] = Thumbnail.Path + " " + strconv.Itoa(Thumbnail.Width) + "w" }
+	if Width := File.Metadata.Width; Width > Thumbnails[len(Thumbnails) - 1].Width {
+		Sources = append(Sources, File.Path + " " + strconv.Itoa(Width) + "w")
+	}
+	return strings.Join(Sources, ", ")
+}
+
+// Transcoding states of a video upload, see package transcoder. Other files have none, and so do videos
+// uploaded while ffmpeg wasn't installed.
+const (
+	TranscodeQueued = "queued"
+	TranscodeProcessing = "processing"
+	TranscodeReady = "ready"
+	TranscodeFailed = "failed"
+)
+
+// File_renditions are the web friendly copies of a video upload (H.264 and AAC in an mp4 which starts playing
+// before it's fully loaded), one per height of TRANSCODE_HEIGHTS. Key is the copy's blob.
+type File_renditions struct {
+	gorm.Model
+	FileID 		uint 		`gorm:"index"`
+	Width 		int
+	Height 		int
+	Key 		string
+}
+
+// File_derivatives are the copies of an image upload in a modern format (IMAGE_FORMATS: webp, avif), of the image
+// itself (Width 0) and of each of its thumbnails, see package converter. Only those smaller than their source are kept.
+type File_derivatives struct {
+	gorm.Model
+	FileID 		uint 		`gorm:"index"`
+	Format 		string 		`gorm:"size:8"`
+	Width 		int
+	Key 		string
+	Size 		int
+}
+
+// File_metadata is what's known of a file's content, read when it's uploaded (see package inspector): the dimensions
+// of images and videos, the pages of PDFs, the duration and codec of audio and video. Files uploaded before it
+// existed, and those under review, have none.
+type File_metadata struct {
+	gorm.Model
+	FileID 		uint 		`gorm:"uniqueIndex"`
+	Width 		int
+	Height 		int
+	Pages 		int
+	Duration 	float64 	/* seconds */
+	Codec 		string 		`gorm:"size:32"`
 }
 
+/* Mimes and Contexts are comma separated, empty Contexts accepts the type in every upload context. Thumbnails and
+   Scan turn thumbnails (images) and virus scanning off for the type's uploads */
 type File_types struct {
 	gorm.Model
 	Name 		string
-	Ext  		string
+	Ext  		string 		`gorm:"uniqueIndex"`
 	Max_size 	int
-}
\ No newline at end of file
+	Mimes 		string
+	Contexts 	string
+	Enabled 	bool 		`gorm:"default:true"`
+	Thumbnails 	bool 		`gorm:"default:true"`
+	Scan 		bool 		`gorm:"default:true"`
+}
+// Resumable_uploads are the tus uploads in progress, their chunks are appended to a local part file until Offset reaches Length.
+type Resumable_uploads struct {
+	gorm.Model
+	Token 		string 		`gorm:"uniqueIndex"`
+	Name 		string
+	Mime 		string
+	Context 	string
+	Length 		int64
+	Offset 		int64
+	FileID 		*int
+	File 		Files 		`gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;foreignKey:FileID"`
+	OwnerID 	*uint 		`gorm:"index"`
+}
+
+// File_versions are the earlier contents of a file, each one recorded when a new upload replaced it or an earlier
+// version was restored (see uploader.Replace). The file keeps its ID, and so its urls, while its content changes.
+// The blob of a version stays stored until the file is removed.
+type File_versions struct {
+	gorm.Model
+	FileID 		uint 		`gorm:"index"`
+	Version 	int
+	Name 		string
+	Original 	string
+	Location 	string
+	Path 		string
+	Size 		int
+	Mime 		string
+	Base64 		string
+	TypeID 		int
+	Review 		string 		`gorm:"size:16"`
+}
+
+// What happened to a file, the Action of its File_events.
+const (
+	FileUploaded = "uploaded"
+	FileReplaced = "replaced"
+	FileRestored = "restored"
+	FileDeleted = "deleted"
+	FileAborted = "aborted"
+	FileTrashed = "trashed"
+	FileUntrashed = "untrashed"
+)
+
+// File_events are the history of a file: who uploaded, replaced, restored, trashed or deleted it, from where and when
+// (see uploader.Audit). They outlive the file, a deleted one's history is still there. UsersID is nil for anonymous
+// uploads, Detail names the file (an earlier version for restores). Uploads the client aborted never became a file,
+// their events have FileID 0 and tell what was received in Detail.
+type File_events struct {
+	gorm.Model
+	FileID 		uint 		`gorm:"index"`
+	Action 		string 		`gorm:"size:16"`
+	UsersID 	*uint 		`gorm:"index"`
+	Users 		Users 		`gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;foreignKey:UsersID"`
+	IP 			string 		`gorm:"size:64"`
+	UserAgent 	string 		`gorm:"size:512"`
+	RequestID 	string 		`gorm:"size:64"`
+	Detail 		string
+}
+
+// Upload_quotas are the bytes of uploads a user (UsersID), or each user of a role (RolesID), may store, see package quota.
+// Bytes 0 is unlimited.
+type Upload_quotas struct {
+	gorm.Model
+	UsersID 	*uint 		`gorm:"uniqueIndex"`
+	RolesID 	*uint 		`gorm:"uniqueIndex"`
+	Bytes 		int64
+}
@@ -7,11 +7,13 @@ type Interface struct {
 	Ver  		int
 	Name 		string
 	Slug 		string
+	LowBandwidth	bool		/* the site's default, see package bandwidth */
 	News        []News 					`gorm:"many2many:Interface_news_joins;constraint: OnUpdate:CASCADE, OnDelete:SET NULL;"`
 	SlideShow	[]Interface_slideShow
 	Reasons		[]Interface_reasons
 	Contact		Interface_contact
 	About		Interface_about
+	Mail		Interface_mail
 	SocialMedia []Social_media
 }
 
@@ -73,3 +75,27 @@ type Interface_about struct {
 	Body   			string
 	Terms  			string
 }
+
+/* Branding of the mails sent on behalf of the site, empty fields fall back to mailer.DefaultTheme */
+type Interface_mail struct {
+	gorm.Model
+	InterfaceID 	uint
+	LogoID 			*int
+	Logo 			Files 			`gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;foreignKey:LogoID"`
+	Primary 		string
+	Background 		string
+	Text 			string
+	Footer 			string
+}
+
+// Interface_versions are snapshots of the site's settings (contact, about and terms, mail theme, social media links,
+// low-bandwidth mode) as JSON, one each time they change, see package versioner. Version follows Interface.Ver.
+type Interface_versions struct {
+	gorm.Model
+	InterfaceID 	uint 		`gorm:"index"`
+	Version 		int
+	Snapshot 		string
+	UsersID 		*uint
+	Users 			Users 		`gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;foreignKey:UsersID"`
+	Note 			string
+}
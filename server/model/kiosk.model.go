@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kiosk_displays are the screens (TVs, info screens) showing a page of the site in kiosk mode, see package kiosk.
+// Token authenticates the screen in its url (/display/:token) in place of a session, Page is what it shows and
+// Refresh how often (seconds) it's reloaded. Ancestors are the origins which may embed it in a frame, space
+// separated, the site alone when empty. SeenAt is when the screen last loaded it.
+type Kiosk_displays struct {
+	gorm.Model
+	Name 		string
+	Token 		string 		`gorm:"uniqueIndex"`
+	Page 		string 		`gorm:"size:32"`
+	Refresh 	int
+	Ancestors 	string
+	SeenAt 		*time.Time
+}
+
+// Pages a kiosk display may show.
+const (
+	KioskBranches = "branches"		/* the branches and the hours of their shifts */
+	KioskNews = "news"				/* the latest news */
+	KioskMenu = "menu"				/* the public categories and their products */
+	KioskSlideshow = "slideshow"	/* the slides of the landing page */
+)
+
+var KioskPages = []string{ KioskBranches, KioskNews, KioskMenu, KioskSlideshow }
+
+// Refresh bounds of a kiosk display, in seconds.
+const (
+	KioskMinRefresh = 15
+	KioskDefaultRefresh = 60
+)
@@ -1,15 +1,42 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/* Delivery statuses of a mail, the provider's events move it past MailSent */
+const (
+	MailSent		= "sent"
+	MailFailed		= "failed"
+	MailSuppressed	= "suppressed"
+	MailDelivered	= "delivered"
+	MailDeferred	= "deferred"
+	MailBounced		= "bounced"
+	MailComplained	= "complained"
+)
 
 type Mails struct {
 	gorm.Model
 	Subject     string
 	Body     	string
 	From   		string
-	To 			string
+	To 			string		`gorm:"index"`
 	Cc 			*string
 	Bcc 		*string
+	Status 		string		`gorm:"index"`
+	MessageID 	string		`gorm:"index"`
+	Error 		string
+	StatusAt 	*time.Time
+}
+
+// Mail_suppressions are the addresses nothing is sent to anymore, after a bounce or a complaint.
+type Mail_suppressions struct {
+	gorm.Model
+	Email 		string		`gorm:"uniqueIndex"`
+	Reason 		string
+	Detail 		string
 }
 
 type Subscribes struct {
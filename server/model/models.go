@@ -0,0 +1,115 @@
+package model
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Models are the application's own models, in the order their tables are made: cmd/migrate migrates them along with
+// the modules' (see module.Container.Migrate), cmd/generate and cmd/doctor check them against the database.
+var Models = []any{
+	&Cities{},
+	&Districts{},
+	&Branches{},
+	&Branch_shifts{},
+
+	&Category_filters_option{},
+	&Category_filters{},
+	&Categories{},
+
+	&Chat_status{},
+	&Chat_type{},
+	&Chat{},
+	&Chat_letters{},
+
+	&Faq{},
+
+	&File_types{},
+	&Files{},
+	&File_thumbnails{},
+	&File_metadata{},
+	&File_renditions{},
+	&File_derivatives{},
+	&File_versions{},
+	&File_events{},
+	&Resumable_uploads{},
+	&Upload_quotas{},
+
+	&Interface{},
+	&Interface_slideShow{},
+	&Interface_reasons{},
+	&Interface_contact{},
+	&Interface_about{},
+	&Interface_mail{},
+	&Social_media{},
+	&Interface_versions{},
+
+	&News_types{},
+	&News{},
+
+	&Products{},
+	&Product_specifications{},
+	&Product_properties{},
+	&Product_approvals{},
+	&Product_packaging{},
+
+	&Preview_channels{},
+	&Preview_changes{},
+	&Preview_links{},
+	&Preview_visits{},
+
+	&Search_analyzers{},
+	&Ping_engines{},
+	&Page_views{},
+	&Feature_uses{},
+	&Audits{},
+	&Seo_rules{},
+	&Budget_reports{},
+	&Kiosk_displays{},
+
+	&Installation{},
+	&Digests{},
+	&Digest_deliveries{},
+	&Job_failures{},
+	&Outbox_messages{},
+
+	&Permissions{},
+	&Roles{},
+	&Users{},
+	&Remember_tokens{},
+	&Sessions{},
+	&User_devices{},
+	&Notifications{},
+	&Notification_mutes{},
+	&Tasks{},
+	&Mails{},
+	&Mail_suppressions{},
+	&Subscribes{},
+}
+var (
+	mu			sync.RWMutex
+	registered	[]any
+)
+
+// Register adds models declared outside Models, by the modules, once each.
+func Register(models ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, model := range models {
+		if !known(model) { registered = append(registered, model) }
+	}
+}
+
+// All returns Models and the registered models, e.g. to find the rows pointing to one (see storage.References).
+func All() []any {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append(append([]any{}, Models...), registered...)
+}
+
+func known(model any) bool {
+	for _, Registered := range registered {
+		if reflect.TypeOf(Registered) == reflect.TypeOf(model) { return true }
+	}
+	return false
+}
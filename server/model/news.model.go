@@ -8,6 +8,7 @@ import (
 
 type News struct {
 	gorm.Model
+	External
 	Views       	int
 	Title 			string
 	Body  			string
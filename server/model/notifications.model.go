@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notifications are what the admin's notifications drawer lists, newest first, see package notifications.
+type Notifications struct {
+	gorm.Model
+	UsersID			uint			`gorm:"index"`
+	Users			Users			`gorm:"constraint: OnUpdate:CASCADE, OnDelete:CASCADE;" json:"-"`
+	Category		string			`gorm:"size:32;index"`
+	Title			string
+	Body			string
+	Link			string
+	ReadAt			*time.Time
+}
+
+// Notification_mutes are the categories a user doesn't want notifications of.
+type Notification_mutes struct {
+	gorm.Model
+	UsersID			uint			`gorm:"uniqueIndex:idx_notification_mute"`
+	Category		string			`gorm:"size:32;uniqueIndex:idx_notification_mute"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/* Statuses of an outbox message, a pending one is retried until it's sent or runs out of attempts */
+const (
+	OutboxPending	= "pending"
+	OutboxSent		= "sent"
+	OutboxFailed	= "failed"
+)
+
+// Outbox_messages are side effects (events, mails) written in the transaction of the change causing them,
+// and dispatched once it's committed, see package outbox.
+type Outbox_messages struct {
+	gorm.Model
+	Key				string			`gorm:"uniqueIndex"`
+	Kind			string
+	Topic			string
+	Payload			string
+	Status			string			`gorm:"index;default:pending"`
+	Attempts		int
+	AvailableAt		time.Time		`gorm:"index"`
+	SentAt			*time.Time
+	Error			string
+}
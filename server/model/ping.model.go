@@ -0,0 +1,12 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// Ping_engines are the search engines told about published pages (see package pinger), by Name. An engine without a row is disabled.
+type Ping_engines struct {
+	gorm.Model
+	Name 		string 		`gorm:"size:32;uniqueIndex"`
+	Enabled 	bool
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PreviewOpen			= "open"
+	PreviewPublished	= "published"
+	PreviewDiscarded	= "discarded"
+)
+
+type Preview_channels struct {
+	gorm.Model
+	Name 			string
+	Token 			string				`gorm:"uniqueIndex"`
+	Status 			string				`gorm:"default:open"`
+	PublishedAt 	*time.Time
+	Changes 		[]Preview_changes	`gorm:"foreignKey:ChannelID"`
+	Links 			[]Preview_links		`gorm:"foreignKey:ChannelID"`
+}
+
+type Preview_changes struct {
+	gorm.Model
+	ChannelID 		uint
+	Kind 			string
+	RecordID 		uint
+	Delete 			bool
+	Payload 		string
+}
+
+// Preview_links let people without an account look at one page of an open channel, read only,
+// until they expire or are revoked. Each previewed request is recorded in Preview_visits.
+type Preview_links struct {
+	gorm.Model
+	ChannelID 		uint				`gorm:"index"`
+	Token 			string				`gorm:"uniqueIndex"`
+	Path 			string
+	Label 			string
+	ExpiresAt 		time.Time
+	RevokedAt 		*time.Time
+	Visits 			[]Preview_visits	`gorm:"foreignKey:LinkID"`
+}
+
+type Preview_visits struct {
+	gorm.Model
+	LinkID 			uint 		`gorm:"index"`
+	Path 			string
+	IP 				string
+	UserAgent 		string
+}
@@ -4,6 +4,7 @@ import "gorm.io/gorm"
 
 type Products struct {
 	gorm.Model
+	External
 	Name 				string
 	Slug 				string
 	Description 		string
@@ -0,0 +1,15 @@
+package model
+
+import "gorm.io/gorm"
+
+type Roles struct {
+	gorm.Model
+	Name			string			`gorm:"uniqueIndex"`
+	Permissions		[]Permissions	`gorm:"many2many:role_permissions;"`
+}
+
+type Permissions struct {
+	gorm.Model
+	Name			string			`gorm:"uniqueIndex"`
+	Description		string
+}
@@ -0,0 +1,13 @@
+package model
+
+import "gorm.io/gorm"
+
+// Search_analyzers configure how search analyzes the words of a locale (see search.Analyzer), a locale without
+// a row uses the built-in analyzer. Stopwords are comma or line separated, Synonyms hold a group per line.
+type Search_analyzers struct {
+	gorm.Model
+	Locale 			string 		`gorm:"size:8;uniqueIndex"`
+	Stemming 		bool 		`gorm:"default:true"`
+	Stopwords 		string
+	Synonyms 		string
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// Seo_rules tell search engines how to index a page, or every page under a path when Section is set (see package indexer).
+// Canonical is the url the page's canonical link points to instead of its own, "" keeps its own.
+type Seo_rules struct {
+	gorm.Model
+	Path 		string 		`gorm:"size:255;uniqueIndex"`
+	Section 	bool
+	NoIndex 	bool
+	NoFollow 	bool
+	Canonical 	string 		`gorm:"size:512"`
+	Note 		string 		`gorm:"size:255"`
+}
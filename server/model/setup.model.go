@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Installation struct {
+	gorm.Model
+	SiteName		string
+	Locale			string
+	Uploads			string
+	CompletedAt		*time.Time
+}
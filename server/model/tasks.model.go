@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tasks are the long running jobs an admin started (listing exports, ...), /admin/tasks shows their progress,
+// see package tasks.
+type Tasks struct {
+	gorm.Model
+	UsersID			uint			`gorm:"index"`
+	Users			Users			`gorm:"constraint: OnUpdate:CASCADE, OnDelete:CASCADE;" json:"-"`
+	Kind			string			`gorm:"size:32"`
+	Title			string
+	State			string			`gorm:"size:16;index"`
+	Done			int
+	Total			int
+	FileID			*uint							/* what the task made, e.g. the exported file */
+	File			Files			`gorm:"constraint: OnUpdate:CASCADE, OnDelete:SET NULL;" json:"-"`
+	Error			string
+	FinishedAt		*time.Time
+}
+
+// States of a task.
+const (
+	TaskQueued = "queued"
+	TaskRunning = "running"
+	TaskDone = "done"
+	TaskFailed = "failed"
+)
+
+// Finished reports whether the task won't change anymore.
+func (Task Tasks) Finished() bool {
+	return Task.State == TaskDone || Task.State == TaskFailed
+}
+
+// Percent is how far the task is, 0 to 100.
+func (Task Tasks) Percent() int {
+	if Task.State == TaskDone { return 100 }
+	if Task.Total <= 0 { return 0 }
+	return min(100, Task.Done * 100 / Task.Total)
+}
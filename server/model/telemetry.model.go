@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of Feature_uses.
+const (
+	UsageRoute = "route"			/* Name is the method and route pattern, "GET /admin/product/:id" */
+	UsageModule = "module"			/* Name is the module which registered the route, "core" for the app's own */
+	UsageUpload = "upload"			/* Name is the size bucket of the file stored, "1-10 MB" */
+)
+
+// Feature_uses are the daily counts of what a deployment uses, one row per kind, name and day. Only kept when the
+// deployment opted in (TELEMETRY), see package telemetry.
+type Feature_uses struct {
+	gorm.Model
+	Kind			string			`gorm:"size:16;uniqueIndex:idx_feature_uses_day"`
+	Name			string			`gorm:"size:160;uniqueIndex:idx_feature_uses_day"`
+	Day				time.Time		`gorm:"type:date;uniqueIndex:idx_feature_uses_day"`
+	Count			int
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -10,5 +12,48 @@ type Users struct {
 	Email			string
 	Password		string
 	Token			string
+	Locale			string
+	FailedLogins		int
+	LockedUntil			*time.Time
+	AlertNewDevice		bool		`gorm:"default:true"`
+	AlertPasswordChange	bool		`gorm:"default:true"`
+	AlertLockout		bool		`gorm:"default:true"`
+	DigestWeekly		bool		`gorm:"default:true"`
+	Roles				[]Roles		`gorm:"many2many:user_roles;"`
 	// `gorm:"constraint: OnUpdate:CASCADE, OnDelete:SET NULL;"`
 }
+
+type User_devices struct {
+	gorm.Model
+	UsersID			uint
+	Users			Users			`gorm:"constraint: OnUpdate:CASCADE, OnDelete:CASCADE;"`
+	Fingerprint		string			`gorm:"index"`
+	UserAgent		string
+	IP				string
+	LastSeenAt		time.Time
+}
+
+type Remember_tokens struct {
+	gorm.Model
+	UsersID			uint
+	Users			Users			`gorm:"constraint: OnUpdate:CASCADE, OnDelete:CASCADE;"`
+	Series			string			`gorm:"uniqueIndex"`
+	TokenHash		string
+	UserAgent		string
+	ExpiresAt		time.Time
+	LastUsedAt		time.Time
+}
+
+/* Sign-in sessions, the "token" cookie holds the token whose hash is stored. Roles are the user's when it was issued */
+type Sessions struct {
+	gorm.Model
+	UsersID			uint			`gorm:"index"`
+	Users			Users			`gorm:"constraint: OnUpdate:CASCADE, OnDelete:CASCADE;"`
+	TokenHash		string			`gorm:"uniqueIndex"`
+	Roles			string
+	UserAgent		string
+	IP				string
+	ExpiresAt		time.Time
+	LastUsedAt		time.Time
+	ReplacedAt		*time.Time		/* when a new session took over, it's accepted for auth.SessionGrace after */
+}
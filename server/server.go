@@ -1,21 +1,42 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"log"
 	"os"
 
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
 
 	"main/server/common/controller"
 	"main/server/common/globals"
+	uploader "main/server/common/helpers"
+	"main/server/common/i18n"
+	"main/server/common/route"
 	"main/server/common/storage"
+	"main/server/middleware"
+	"main/server/service/analytics"
+	"main/server/service/indexer"
+	"main/server/service/hooks"
+	"main/server/service/outbox"
+	"main/server/service/notifications"
+	"main/server/service/pinger"
+	"main/server/service/tasks"
+	"main/server/service/transcoder"
+	"main/server/service/versioner"
+	"main/server/service/searcher"
+	"main/server/service/telemetry"
+	"main/server/service/audit"
+	"main/server/service/setup"
 )
 
 func Run() {
+	if err := globals.Validate(); err != nil { log.Fatal(err) }
+
 	app := echo.New()
 	app.Static("", "./public/")
-    app.Pre(middleware.RemoveTrailingSlash())
+    app.Pre(echoMiddleware.RemoveTrailingSlash())
 	
 
 	// app.Use(middleware.Secure())
@@ -26,15 +47,38 @@ func Run() {
 	// app.Use(middleware.Timeout())
 	// app.Use(middleware.BodyLimit("2M"))
 	// app.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(20)))
-	// app.Use(middleware.RequestID())
 	// app.Use(middleware.Recover())
 	// app.Use(middleware.Logger())
 	// app.Use(echoprometheus.NewMiddleware("yacco"))
 	// app.GET("/metrics", echoprometheus.NewHandler())
 	
+	route.Setup(app)
+	app.HTTPErrorHandler = controller.ErrorHandler(app.DefaultHTTPErrorHandler)
 	app.Use(controller.Initialize())
+	app.Use(middleware.RequestID())
+	app.Use(middleware.Locale())
+	app.Use(middleware.BodyLimit())
+	app.Use(middleware.Chaos())
+	app.Use(middleware.PrivateUploads())
 	storage.Connect(storage.Default())
-	ServerRouters(app)
+	setup.Apply()
+	i18n.Setup()
+	container := ServerRouters(app)
+	checkSchema(container)
+	hooks.Setup(container)
+	outbox.Setup(container)
+	uploader.Setup()
+	searcher.Setup(container)
+	pinger.Setup(container)
+	versioner.Setup(container)
+	analytics.Setup(container)
+	telemetry.Setup(container)
+	audit.Setup(container)
+	indexer.Setup(container)
+	transcoder.Setup(container)
+	notifications.Setup(container)
+	tasks.Setup()
+	container.Start(context.Background())
 
 	data, _ := json.MarshalIndent(app.Routes(), "", "    ")
 	os.WriteFile("./build/routes.json", data, 0644)
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"main/server/common/module"
+	"main/server/controller/admin/auditor"
+	"main/server/controller/admin/budgeter"
+	"main/server/controller/admin/digest"
+	"main/server/controller/admin/outboxer"
+	"main/server/controller/admin/packager"
+	"main/server/controller/admin/quoter"
+	"main/server/controller/admin/tasker"
+	"main/server/controller/admin/telemeter"
+	"main/server/controller/admin/traffic"
+	"main/server/controller/admin/trasher"
+	"main/server/controller/admin/typer"
+	"main/server/controller/callbacks"
+	"main/server/controller/kiosk"
+	"main/server/controller/stream"
+)
+
+// Modules are the features plugged in through module.Module, see package module.
+var Modules = []module.Module{
+	packager.Module{},
+	digest.Module{},
+	typer.Module{},
+	stream.Module{},
+	outboxer.Module{},
+	callbacks.Module{},
+	quoter.Module{},
+	tasker.Module{},
+	traffic.Module{},
+	budgeter.Module{},
+	kiosk.Module{},
+	telemeter.Module{},
+	trasher.Module{},
+	auditor.Module{},
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"github.com/labstack/echo/v4"
 
+	"main/server/common/module"
 	"main/server/controller/about"
 	"main/server/controller/admin"
 	"main/server/controller/branches"
@@ -11,16 +12,27 @@ import (
 	"main/server/controller/faq"
 	"main/server/controller/landing"
 	"main/server/controller/news"
+	"main/server/controller/preview"
 	"main/server/controller/products"
+	"main/server/controller/setup"
+	"main/server/controller/sitemap"
 	"main/server/controller/terms"
 	"main/server/controller/upload"
 	"main/server/middleware"
 )
 
-func ServerRouters(app *echo.Echo) {
-	admin.Register(app)
+func ServerRouters(app *echo.Echo) *module.Container {
+	Admin := admin.Register(app)
 
+	app.Use(middleware.Setup())
+	app.Use(middleware.Preview())
 	app.Use(middleware.Interface())
+	app.Use(middleware.Bandwidth())
+	app.Use(middleware.Analytics())
+	app.Use(middleware.Telemetry())
+	app.Use(middleware.SEO())
+	setup.Register(app)
+	preview.Register(app)
 	upload.Register(app)
 	landing.Register(app)
 	categories.Register(app)
@@ -30,5 +42,10 @@ func ServerRouters(app *echo.Echo) {
 	faq.Register(app)
 	about.Register(app)
 	terms.Register(app)
+	sitemap.Register(app)
 	chat.Register(app)
+
+	Internal := app.Group("internal", middleware.Signed())
+
+	return module.Boot(app, Admin, Internal, Modules...)
 }
\ No newline at end of file
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"log"
+
+	"main/server/common/globals"
+	"main/server/common/module"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+/*
+	A deployment which forgot "make migrate" fails on the first query touching a new column,
+	the models are compared with the database at startup to tell it before (SCHEMA_CHECK).
+*/
+func checkSchema(container *module.Container) {
+	if globals.Env.SCHEMA_CHECK == "off" { return }
+
+	Drifts, err := storage.Validate(storage.DB, append(model.Models, container.Models()...)...)
+	if err != nil {
+		log.Print("Schema check: ", err)
+		return
+	}
+	if len(Drifts) == 0 { return }
+
+	for _, Drift := range Drifts { log.Print("Schema check: ", Drift) }
+	if globals.Env.SCHEMA_CHECK == "strict" { log.Fatalf("Schema check: %d differences with the models, run make migrate", len(Drifts)) }
+	log.Printf("Schema check: %d differences with the models, run make migrate", len(Drifts))
+}
@@ -0,0 +1,279 @@
+// Package audit records who created, changed and deleted the rows of the models it's told about (Models) in Audits,
+// with what changed. It hooks into gorm's create, update and delete callbacks, so whatever saves them is audited:
+// handlers, services and jobs alike, without calling anything.
+//
+// The acting user is the one of the request the statement runs in (controller.WithActor), statements run outside
+// one (ctx.DB() and storage.DB.WithContext(ctx) carry it) are recorded without a user. An audit is written on the
+// statement's connection, a transaction rolled back leaves none behind.
+//
+// Rows are read before and after the statement to tell what changed, an audited statement costs two more queries.
+// Statements gorm runs without a model (Table("products").Updates(...)) or raw SQL aren't audited.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"main/server/common/controller"
+	"main/server/common/domain"
+	"main/server/common/module"
+	"main/server/model"
+)
+
+// MaxRows is how many rows of one statement are audited, a bulk update of more records the first ones alone.
+const MaxRows = 1000
+
+// Redacted stands for the values of a Model's Redact columns, their changes are recorded but not what they were.
+const Redacted = "[redacted]"
+
+var ErrUnknownEntity = domain.NotFound("audit: no such audited entity")
+
+// Model is an audited model. Redact are the columns whose values mustn't be kept (password hashes, tokens), Ignore
+// the ones changing too often to be worth a record (a failed login count), an update changing them alone isn't one.
+type Model struct {
+	Model		any
+	Label		string
+	Redact		[]string
+	Ignore		[]string
+}
+
+// Models are the audited models.
+var Models = []Model{
+	{ Model: &model.Products{}, Label: "პროდუქტები" },
+	{ Model: &model.Categories{}, Label: "კატეგორიები" },
+	{ Model: &model.News{}, Label: "სიახლეები" },
+	{ Model: &model.Faq{}, Label: "ხშირად დასმული კითხვები" },
+	{ Model: &model.Branches{}, Label: "ფილიალები" },
+	{ Model: &model.Roles{}, Label: "როლები" },
+	{ Model: &model.Users{}, Label: "მომხმარებლები", Redact: []string{ "password", "token" }, Ignore: []string{ "failed_logins", "locked_until" } },
+}
+
+/* Every row has them, the action tells about deleted_at */
+var skipped = []string{ "id", "created_at", "updated_at", "deleted_at" }
+
+/* The audited models by their table, written by Register only */
+var entities = map[string]Model{}
+
+const beforeKey = "audit:before"
+
+// snapshot is the rows a statement touches, their columns by their primary key.
+type snapshot map[string]map[string]any
+
+// Setup audits Models on the app's database.
+func Setup(container *module.Container) {
+	if err := Register(container.DB, Models...); err != nil { log.Fatal("Auditing: ", err) }
+}
+
+// Register audits the models on a database, it's called once per database.
+//
+// Example usage:
+//   err := audit.Register(storage.DB, audit.Model{ Model: &model.Products{} })
+func Register(db *gorm.DB, Audited ...Model) error {
+	for _, Kind := range Audited {
+		Statement := &gorm.Statement{ DB: db }
+		if err := Statement.Parse(Kind.Model); err != nil { return err }
+		entities[Statement.Schema.Table] = Kind
+	}
+
+	Callbacks := db.Callback()
+	return firstError(
+		Callbacks.Create().After("gorm:create").Register("audit:create", created),
+		Callbacks.Update().Before("gorm:update").Register("audit:before_update", before),
+		Callbacks.Update().After("gorm:update").Register("audit:update", changed),
+		Callbacks.Delete().Before("gorm:delete").Register("audit:before_delete", before),
+		Callbacks.Delete().After("gorm:delete").Register("audit:delete", changed),
+	)
+}
+
+// Find is the audited model of a table.
+func Find(Entity string) (Model, bool) {
+	Kind, ok := entities[Entity]
+	return Kind, ok
+}
+
+// History is a row's audits, the latest first, with the users who made them.
+//
+// Returns:
+//   - ErrUnknownEntity when the table isn't audited.
+func History(db *gorm.DB, Entity string, ID uint, Offset int, Limit int) ([]model.Audits, error) {
+	if _, ok := entities[Entity]; !ok { return nil, ErrUnknownEntity }
+
+	Audits := []model.Audits{}
+	err := db.Preload("Users").Where("entity = ? AND entity_id = ?", Entity, ID).
+		Order("id desc").Offset(Offset).Limit(Limit).Find(&Audits).Error
+	return Audits, err
+}
+
+/* The rows an update or a delete is about to touch, as they are */
+func before(db *gorm.DB) {
+	Kind, Key, ok := audited(db)
+	if !ok { return }
+
+	Query := session(db)
+	Keys := keys(db, Key)
+	Where, scoped := db.Statement.Clauses["WHERE"].Expression.(clause.Where)
+	scoped = scoped && len(Where.Exprs) > 0
+	if !scoped && len(Keys) == 0 { return }		/* gorm refuses statements without conditions */
+
+	if scoped { Query = Query.Clauses(clause.Where{ Exprs: Where.Exprs }) }
+	if len(Keys) > 0 { Query = Query.Where(clause.IN{ Column: clause.Column{ Table: clause.CurrentTable, Name: Key.DBName }, Values: Keys }) }
+	if Deleted := db.Statement.Schema.LookUpField("DeletedAt"); Deleted != nil && !db.Statement.Unscoped {
+		Query = Query.Where(clause.Eq{ Column: clause.Column{ Table: clause.CurrentTable, Name: Deleted.DBName }, Value: nil })
+	}
+
+	Before, err := load(Query, Key)
+	if err != nil {
+		log.Print("[", controller.RequestIDFrom(db.Statement.Context), "] Auditing ", Kind.Label, ": ", err)
+		return
+	}
+	db.InstanceSet(beforeKey, Before)
+}
+
+/* The rows an update or a delete touched, compared with what they were */
+func changed(db *gorm.DB) {
+	Kind, Key, ok := audited(db)
+	if !ok || db.Error != nil { return }
+
+	Stored, _ := db.InstanceGet(beforeKey)
+	Before, _ := Stored.(snapshot)
+	if len(Before) == 0 { return }
+
+	IDs := []any{}
+	for _, Row := range Before { IDs = append(IDs, Row[Key.DBName]) }
+	After, err := load(session(db).Where(clause.IN{ Column: clause.Column{ Name: Key.DBName }, Values: IDs }), Key)
+	if err != nil {
+		log.Print("[", controller.RequestIDFrom(db.Statement.Context), "] Auditing ", Kind.Label, ": ", err)
+		return
+	}
+
+	for ID, Old := range Before {
+		New, found := After[ID]
+		Action := model.AuditUpdated
+		switch {
+			case !found: Action = model.AuditPurged
+			case Old["deleted_at"] == nil && New["deleted_at"] != nil: Action = model.AuditDeleted
+			case Old["deleted_at"] != nil && New["deleted_at"] == nil: Action = model.AuditRestored
+		}
+
+		Changes := diff(Kind, Old, New)
+		if Action == model.AuditUpdated && len(Changes) == 0 { continue }
+		record(db, Kind, ID, Action, Changes)
+	}
+}
+
+/* The rows a create inserted, their primary keys are filled in by then */
+func created(db *gorm.DB) {
+	Kind, Key, ok := audited(db)
+	if !ok || db.Error != nil { return }
+
+	Keys := keys(db, Key)
+	if len(Keys) == 0 { return }
+
+	After, err := load(session(db).Where(clause.IN{ Column: clause.Column{ Name: Key.DBName }, Values: Keys }), Key)
+	if err != nil {
+		log.Print("[", controller.RequestIDFrom(db.Statement.Context), "] Auditing ", Kind.Label, ": ", err)
+		return
+	}
+	for ID, New := range After { record(db, Kind, ID, model.AuditCreated, diff(Kind, nil, New)) }
+}
+
+/* The statement's audited model and its primary key, false for the others */
+func audited(db *gorm.DB) (Model, *schema.Field, bool) {
+	if db.Statement.Schema == nil { return Model{}, nil, false }
+	Kind, ok := entities[db.Statement.Schema.Table]
+	Key := db.Statement.Schema.PrioritizedPrimaryField
+	return Kind, Key, ok && Key != nil
+}
+
+/* A query on the statement's connection (its transaction) and table, trashed rows included */
+func session(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{ NewDB: true, SkipHooks: true }).Unscoped().Table(db.Statement.Schema.Table)
+}
+
+/* The primary keys of the statement's model value, a struct or a slice of them, which have one */
+func keys(db *gorm.DB, Key *schema.Field) []any {
+	Keys := []any{}
+	Value := reflect.Indirect(db.Statement.ReflectValue)
+	switch Value.Kind() {
+		case reflect.Struct:
+			if ID, zero := Key.ValueOf(db.Statement.Context, Value); !zero { Keys = append(Keys, ID) }
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < Value.Len(); i++ {
+				if ID, zero := Key.ValueOf(db.Statement.Context, reflect.Indirect(Value.Index(i))); !zero { Keys = append(Keys, ID) }
+			}
+	}
+	return Keys
+}
+
+func load(Query *gorm.DB, Key *schema.Field) (snapshot, error) {
+	Rows := []map[string]any{}
+	if err := Query.Limit(MaxRows).Find(&Rows).Error; err != nil { return nil, err }
+
+	Loaded := snapshot{}
+	for _, Row := range Rows { Loaded[fmt.Sprint(Row[Key.DBName])] = Row }
+	return Loaded, nil
+}
+
+/* The columns whose values differ, each with its value before and after (nil for a row which wasn't there) */
+func diff(Kind Model, Old map[string]any, New map[string]any) map[string][2]any {
+	Changes := map[string][2]any{}
+	for _, Row := range []map[string]any{ Old, New } {
+		for Column := range Row {
+			if contains(skipped, Column) || contains(Kind.Ignore, Column) { continue }
+			if _, done := Changes[Column]; done { continue }
+
+			Was, Is := Old[Column], New[Column]
+			if reflect.DeepEqual(Was, Is) { continue }
+			if contains(Kind.Redact, Column) { Was, Is = redact(Was), redact(Is) }
+			Changes[Column] = [2]any{ Was, Is }
+		}
+	}
+	return Changes
+}
+
+func redact(Value any) any {
+	if Value == nil { return nil }
+	return Redacted
+}
+
+/* Written on the statement's connection: it's undone along with the change when its transaction is rolled back */
+func record(db *gorm.DB, Kind Model, ID string, Action string, Changes map[string][2]any) {
+	EntityID, err := strconv.ParseUint(ID, 10, 0)
+	if err != nil { return }
+
+	Encoded, err := json.Marshal(Changes)
+	if err != nil { Encoded = []byte("{}") }
+
+	Audit := model.Audits{
+		Entity: db.Statement.Schema.Table, EntityID: uint(EntityID), Action: Action, Changes: string(Encoded),
+		RequestID: controller.RequestIDFrom(db.Statement.Context),
+	}
+	if Actor, ok := controller.ActorFrom(db.Statement.Context); ok {
+		Audit.UsersID, Audit.IP, Audit.UserAgent = Actor.UsersID, Actor.IP, Actor.UserAgent
+	}
+
+	if err := db.Session(&gorm.Session{ NewDB: true, SkipHooks: true }).Omit("Users").Create(&Audit).Error; err != nil {
+		log.Print("[", Audit.RequestID, "] Recording ", Action, " of ", Kind.Label, " ", ID, ": ", err)
+	}
+}
+
+func contains(Columns []string, Column string) bool {
+	for _, Known := range Columns {
+		if Known == Column { return true }
+	}
+	return false
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil { return err }
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// Grants loads the roles of the user and the permissions they give.
+func Grants(UserID uint) (controller.Grants, error) {
+	var User model.Users
+	Grants := controller.Grants{ Roles: map[string]bool{}, Permissions: map[string]bool{} }
+
+	if err := storage.DB.Preload("Roles.Permissions").First(&User, UserID).Error; err != nil { return Grants, err }
+
+	for _, Role := range User.Roles {
+		Grants.Roles[Role.Name] = true
+		for _, Permission := range Role.Permissions { Grants.Permissions[Permission.Name] = true }
+	}
+	return Grants, nil
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+/*
+	Passwords are hashed with argon2id over the HMAC-SHA256 of the password keyed with PASSWORD_PEPPER, stored in the
+	PHC string format with the pepper's id (k, the start of its sha-256) among the parameters:
+
+	  $argon2id$v=19$m=65536,t=3,p=2,k=1a2b3c4d$<salt>$<hash>
+
+	so a hash made with PASSWORD_PEPPER_PREVIOUS, or before there was a pepper (no k), is still checked with the pepper
+	it was made with. Hashes of other parameters or peppers, and the bcrypt ones of earlier versions, are outdated:
+	VerifyPassword re-hashes them once their password is known, at a successful login.
+*/
+
+const (
+	saltLength = 16
+	keyLength = 32
+)
+
+/* The most a stored hash may ask of a login, a few times the defaults (64 MiB, 3 passes, 2 lanes). The configured
+   parameters are always allowed, whatever they are */
+const (
+	maxMemory = 256 << 10
+	maxTime = 10
+	maxThreads = 8
+)
+
+// Schemes a password hash may be in, as PasswordReport counts them.
+const (
+	SchemeCurrent = "current"			/* argon2id with the configured parameters and pepper */
+	SchemeParameters = "parameters"		/* argon2id with other parameters */
+	SchemePepper = "pepper"				/* argon2id with the previous pepper, or none */
+	SchemeBcrypt = "bcrypt"
+	SchemeUnknown = "unknown"			/* unreadable, or made with a pepper which isn't configured anymore */
+)
+
+type argonHash struct {
+	Memory		uint32
+	Time		uint32
+	Threads		uint8
+	Pepper		string
+	Salt		[]byte
+	Key			[]byte
+}
+
+// HashPassword hashes a password with argon2id, the configured parameters and pepper.
+func HashPassword(Password string) (string, error) {
+	Salt := make([]byte, saltLength)
+	if _, err := rand.Read(Salt); err != nil { return "", err }
+
+	Hash := argonHash{
+		Memory: uint32(globals.Env.ARGON2_MEMORY),
+		Time: uint32(globals.Env.ARGON2_TIME),
+		Threads: uint8(globals.Env.ARGON2_THREADS),
+		Pepper: pepperID(globals.Env.PASSWORD_PEPPER),
+		Salt: Salt,
+	}
+	Hash.Key = Hash.derive(peppered(globals.Env.PASSWORD_PEPPER, Password))
+	return Hash.String(), nil
+}
+
+// CheckPassword reports whether the password is the hash's, and whether the hash is outdated (see Scheme).
+func CheckPassword(Hash string, Password string) (Match bool, Outdated bool) {
+	if strings.HasPrefix(Hash, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(Hash), []byte(Password)) == nil, true
+	}
+
+	Parsed, err := parseArgon(Hash)
+	if err != nil { return false, false }
+	Pepper, ok := pepper(Parsed.Pepper)
+	if !ok { return false, false }
+
+	Match = subtle.ConstantTimeCompare(Parsed.derive(peppered(Pepper, Password)), Parsed.Key) == 1
+	return Match, Scheme(Hash) != SchemeCurrent
+}
+
+// VerifyPassword reports whether the password is the user's. An outdated hash is replaced by a current one
+// once the password matched, a failure to store it is logged: the login isn't refused for it.
+//
+// Example usage:
+//   if !auth.VerifyPassword(User, Parameters.Password) { ... }
+func VerifyPassword(User model.Users, Password string) bool {
+	Match, Outdated := CheckPassword(User.Password, Password)
+	if !Match || !Outdated { return Match }
+
+	Hash, err := HashPassword(Password)
+	if err == nil { err = storage.DB.Model(&User).UpdateColumn(model.UsersPassword, Hash).Error }
+	if err != nil { log.Print("Re-hashing the password of user ", User.ID, ": ", err) }
+	return true
+}
+
+// Scheme tells what a password hash is made with, see the Scheme constants.
+func Scheme(Hash string) string {
+	if strings.HasPrefix(Hash, "$2") {
+		if _, err := bcrypt.Cost([]byte(Hash)); err == nil { return SchemeBcrypt }
+		return SchemeUnknown
+	}
+
+	Parsed, err := parseArgon(Hash)
+	if err != nil { return SchemeUnknown }
+	if _, ok := pepper(Parsed.Pepper); !ok { return SchemeUnknown }
+
+	switch {
+		case Parsed.Pepper != pepperID(globals.Env.PASSWORD_PEPPER): return SchemePepper
+		case Parsed.Memory != uint32(globals.Env.ARGON2_MEMORY) || Parsed.Time != uint32(globals.Env.ARGON2_TIME) || Parsed.Threads != uint8(globals.Env.ARGON2_THREADS): return SchemeParameters
+	}
+	return SchemeCurrent
+}
+
+// PasswordReport counts the users' password hashes by Scheme, the ones not current are upgraded as their users sign in.
+// Users without a password aren't counted.
+func PasswordReport() (map[string]int, error) {
+	var Hashes []string
+	if err := storage.DB.Model(&model.Users{}).Where("password <> ''").Pluck(model.UsersPassword, &Hashes).Error; err != nil { return nil, err }
+
+	Report := map[string]int{}
+	for _, Hash := range Hashes { Report[Scheme(Hash)]++ }
+	return Report, nil
+}
+
+/* The pepper of an id, the current or the previous one: "" is no pepper */
+func pepper(ID string) (string, bool) {
+	for _, Pepper := range []string{ globals.Env.PASSWORD_PEPPER, globals.Env.PASSWORD_PEPPER_PREVIOUS } {
+		if pepperID(Pepper) == ID { return Pepper, true }
+	}
+	return "", ID == ""
+}
+
+func pepperID(Pepper string) string {
+	if Pepper == "" { return "" }
+	Sum := sha256.Sum256([]byte(Pepper))
+	return hex.EncodeToString(Sum[:4])
+}
+
+func peppered(Pepper string, Password string) []byte {
+	if Pepper == "" { return []byte(Password) }
+	Mac := hmac.New(sha256.New, []byte(Pepper))
+	Mac.Write([]byte(Password))
+	return Mac.Sum(nil)
+}
+
+func (Hash argonHash) derive(Password []byte) []byte {
+	return argon2.IDKey(Password, Hash.Salt, Hash.Time, Hash.Memory, Hash.Threads, keyLength)
+}
+
+func (Hash argonHash) String() string {
+	Parameters := fmt.Sprintf("m=%d,t=%d,p=%d", Hash.Memory, Hash.Time, Hash.Threads)
+	if Hash.Pepper != "" { Parameters += ",k=" + Hash.Pepper }
+	return fmt.Sprintf("$argon2id$v=%d$%s$%s$%s", argon2.Version, Parameters,
+		base64.RawStdEncoding.EncodeToString(Hash.Salt), base64.RawStdEncoding.EncodeToString(Hash.Key))
+}
+
+/* Parameters out of these bounds would make checking a (planted) hash take the server down */
+func parseArgon(Encoded string) (argonHash, error) {
+	var Hash argonHash
+	Parts := strings.Split(Encoded, "$")
+	if len(Parts) != 6 || Parts[1] != "argon2id" || Parts[2] != fmt.Sprintf("v=%d", argon2.Version) { return Hash, fmt.Errorf("not an argon2id hash") }
+
+	for _, Parameter := range strings.Split(Parts[3], ",") {
+		Name, Value, _ := strings.Cut(Parameter, "=")
+		var Number uint32
+		if Name != "k" {
+			if _, err := fmt.Sscan(Value, &Number); err != nil { return Hash, err }
+		}
+		switch Name {
+			case "m": Hash.Memory = Number
+			case "t": Hash.Time = Number
+			case "p": Hash.Threads = uint8(min(Number, 255))
+			case "k": Hash.Pepper = Value
+		}
+	}
+	Memory := max(maxMemory, uint32(globals.Env.ARGON2_MEMORY))
+	Time := max(maxTime, uint32(globals.Env.ARGON2_TIME))
+	Threads := max(maxThreads, uint8(globals.Env.ARGON2_THREADS))
+	if Hash.Memory == 0 || Hash.Memory > Memory || Hash.Time == 0 || Hash.Time > Time || Hash.Threads == 0 || Hash.Threads > Threads {
+		return Hash, fmt.Errorf("argon2id parameters out of bounds")
+	}
+
+	var err error
+	if Hash.Salt, err = base64.RawStdEncoding.DecodeString(Parts[4]); err != nil { return Hash, err }
+	if Hash.Key, err = base64.RawStdEncoding.DecodeString(Parts[5]); err != nil { return Hash, err }
+	if len(Hash.Key) != keyLength { return Hash, fmt.Errorf("argon2id key of %d bytes", len(Hash.Key)) }
+	return Hash, nil
+}
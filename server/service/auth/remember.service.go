@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+const (
+	RememberCookie = "remember"
+	RememberFor = 30 * 24 * time.Hour
+)
+
+var (
+	ErrRememberInvalid = errors.New("remember-me token is invalid or expired")
+	ErrRememberTheft = errors.New("remember-me token was reused, all sessions were invalidated")
+)
+
+// Remember creates a new remember-me series for the user.
+// The returned value goes into the remember cookie as is, only the token's hash is stored.
+func Remember(User model.Users, UserAgent string) (string, error) {
+	series, token := randomHex(16), randomHex(32)
+
+	Record := model.Remember_tokens{
+		UsersID: User.ID,
+		Series: series,
+		TokenHash: hashToken(token),
+		UserAgent: UserAgent,
+		ExpiresAt: time.Now().Add(RememberFor),
+		LastUsedAt: time.Now(),
+	}
+
+	if err := storage.DB.Create(&Record).Error; err != nil { return "", err }
+	return series + ":" + token, nil
+}
+
+// Recall logs the user in by a remember cookie value and rotates its token.
+//
+// A series with a wrong token means the cookie was copied and one of the copies was already used:
+// the series' token only changes on use. In that case every remember-me series and the user's session token
+// are invalidated and ErrRememberTheft is returned. Of concurrent requests with the same cookie one rotates
+// the token, the others get ErrRememberInvalid.
+//
+// Returns:
+//   - The user owning the series.
+//   - The new cookie value, it must replace the old one.
+//   - ErrRememberInvalid, ErrRememberTheft or a database error.
+func Recall(Value string) (model.Users, string, error) {
+	var Record model.Remember_tokens
+
+	series, token, found := strings.Cut(Value, ":")
+	if !found || series == "" || token == "" { return model.Users{}, "", ErrRememberInvalid }
+
+	result := storage.DB.Preload("Users").Where(&model.Remember_tokens{Series: series}).Last(&Record)
+	if result.Error != nil { return model.Users{}, "", ErrRememberInvalid }
+
+	if time.Now().After(Record.ExpiresAt) {
+		storage.DB.Unscoped().Delete(&Record)
+		return model.Users{}, "", ErrRememberInvalid
+	}
+
+	if subtle.ConstantTimeCompare([]byte(Record.TokenHash), []byte(hashToken(token))) != 1 {
+		log.Print("Remember-me token reuse detected for user ", Record.UsersID, ", invalidating all sessions")
+		if err := Invalidate(Record.UsersID); err != nil { return model.Users{}, "", err }
+		return model.Users{}, "", ErrRememberTheft
+	}
+
+	/* Rotated only from the token it was read with, of two requests racing with the same cookie one rotates it */
+	rotated := randomHex(32)
+	result = storage.DB.Model(&model.Remember_tokens{}).
+		Where(model.Remember_tokensID + " = ? AND " + model.Remember_tokensTokenHash + " = ?", Record.ID, Record.TokenHash).
+		Updates(map[string]interface{}{
+			model.Remember_tokensTokenHash: hashToken(rotated),
+			model.Remember_tokensLastUsedAt: time.Now(),
+		})
+	if result.Error != nil { return model.Users{}, "", result.Error }
+	if result.RowsAffected == 0 { return model.Users{}, "", ErrRememberInvalid }
+
+	return Record.Users, series + ":" + rotated, nil
+}
+
+// Forget removes the series of a remember cookie value, used on logout.
+func Forget(Value string) {
+	series, _, _ := strings.Cut(Value, ":")
+	if series == "" { return }
+	storage.DB.Unscoped().Where(&model.Remember_tokens{Series: series}).Delete(&model.Remember_tokens{})
+}
+
+// Invalidate drops every session and remember-me series of the user and replaces the user's token,
+// logging the user out everywhere.
+func Invalidate(UserID uint) error {
+	return storage.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where(&model.Remember_tokens{UsersID: UserID}).Delete(&model.Remember_tokens{})
+		if result.Error != nil { return result.Error }
+		result = tx.Unscoped().Where(&model.Sessions{UsersID: UserID}).Delete(&model.Sessions{})
+		if result.Error != nil { return result.Error }
+		return tx.Model(&model.Users{}).Where("id = ?", UserID).Update("token", randomHex(32)).Error
+	})
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) string {
+	random := make([]byte, size)
+	rand.Read(random)
+	return hex.EncodeToString(random)
+}
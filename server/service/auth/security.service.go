@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"main/build/view"
+	"main/server/common/i18n"
+	"main/server/common/storage"
+	"main/server/model"
+	mailer "main/server/service/mail"
+	"main/server/service/notifications"
+)
+
+const (
+	MaxFailedLogins = 5
+	LockoutFor = 15 * time.Minute
+)
+
+// IsLocked reports whether the account is locked out after too many failed logins.
+func IsLocked(User model.Users) bool {
+	return User.LockedUntil != nil && time.Now().Before(*User.LockedUntil)
+}
+
+// LoginFailed counts a failed login, locking the account for LockoutFor once MaxFailedLogins is reached.
+// Concurrent attempts are all counted, the counter is incremented by the database.
+func LoginFailed(User model.Users, IP string) {
+	var Counted model.Users
+	Result := storage.DB.Model(&Counted).Clauses(clause.Returning{ Columns: []clause.Column{{ Name: model.UsersFailedLogins }} }).
+		Where(model.UsersID + " = ?", User.ID).Update(model.UsersFailedLogins, gorm.Expr(model.UsersFailedLogins + " + 1"))
+	if Result.Error != nil {
+		log.Print("Counting failed login: ", Result.Error)
+		return
+	}
+	if Counted.FailedLogins < MaxFailedLogins { return }
+
+	/* Of the attempts reaching the limit together, the one resetting the counter locks the account and alerts */
+	LockedUntil := time.Now().Add(LockoutFor)
+	Locked := storage.DB.Model(&model.Users{}).Where(model.UsersID + " = ? AND " + model.UsersFailedLogins + " >= ?", User.ID, MaxFailedLogins).
+		Updates(map[string]interface{}{ model.UsersFailedLogins: 0, model.UsersLockedUntil: &LockedUntil })
+	if Locked.Error != nil {
+		log.Print("Locking account: ", Locked.Error)
+		return
+	}
+
+	if Locked.RowsAffected > 0 && User.AlertLockout {
+		alert(User, "security.lockout", []string{
+			"IP: " + IP,
+			i18n.Translate(User.Locale, "security.time", time.Now().Format("2006-01-02 15:04:05")),
+		})
+	}
+}
+
+// LoginSucceeded resets the failed login counter and alerts the user when the login comes from an unknown device.
+// A device is told apart by its user agent.
+func LoginSucceeded(User model.Users, UserAgent string, IP string) {
+	if User.FailedLogins > 0 || User.LockedUntil != nil {
+		storage.DB.Model(&User).Updates(map[string]interface{}{ "FailedLogins": 0, "LockedUntil": nil })
+	}
+
+	var Device model.User_devices
+	Fingerprint := fingerprint(UserAgent)
+
+	result := storage.DB.Where(&model.User_devices{UsersID: User.ID, Fingerprint: Fingerprint}).Last(&Device)
+	if result.Error == nil {
+		storage.DB.Model(&Device).Updates(map[string]interface{}{ "IP": IP, "LastSeenAt": time.Now() })
+		return
+	}
+
+	Device = model.User_devices{ UsersID: User.ID, Fingerprint: Fingerprint, UserAgent: UserAgent, IP: IP, LastSeenAt: time.Now() }
+	if err := storage.DB.Create(&Device).Error; err != nil {
+		log.Print("Saving new device: ", err)
+		return
+	}
+
+	if User.AlertNewDevice {
+		alert(User, "security.device", []string{
+			i18n.Translate(User.Locale, "security.device", UserAgent),
+			"IP: " + IP,
+			i18n.Translate(User.Locale, "security.time", time.Now().Format("2006-01-02 15:04:05")),
+		})
+	}
+}
+
+// PasswordChanged alerts the user about a password change.
+func PasswordChanged(User model.Users, IP string) {
+	if !User.AlertPasswordChange { return }
+
+	alert(User, "security.password", []string{
+		"IP: " + IP,
+		i18n.Translate(User.Locale, "security.time", time.Now().Format("2006-01-02 15:04:05")),
+	})
+}
+
+/* Mails are sent in the background, a slow mail provider must not hold the login up */
+func alert(User model.Users, Event string, Details []string) {
+	Title := i18n.Translate(User.Locale, Event + ".title")
+	Message := i18n.Translate(User.Locale, Event + ".message")
+
+	if err := notifications.Notify(User.ID, notifications.CategorySecurity, Title, Message, "/admin/profile"); err != nil {
+		log.Print("Notifying of ", Event, ": ", err)
+	}
+
+	ctx := i18n.WithLocale(context.Background(), i18n.Negotiate(User.Locale))
+	Body, err := mailer.Render(ctx, view.SecurityMail(User.Fullname, Title, Message, Details))
+	if err != nil {
+		log.Print("Rendering security mail: ", err)
+		return
+	}
+
+	go mailer.Send(mailer.Config{ To: User.Email, Subject: Title, Body: Body })
+}
+
+func fingerprint(UserAgent string) string {
+	sum := sha256.Sum256([]byte(UserAgent))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// SessionFor is how long a session lasts, as its cookies.
+const SessionFor = 24 * time.Hour
+
+// SessionGrace is how long a replaced session's token still works, so the requests a page sent with it before it
+// got the new one don't fail.
+const SessionGrace = 30 * time.Second
+
+var ErrSessionInvalid = errors.New("session is invalid or expired")
+
+// StartSession signs the user in with a new session and replaces Previous, the token the request came with, if any.
+// Every change of privilege gets a new token this way (login, role elevation), so a token planted in a browser
+// before the user signed in (session fixation) never becomes a signed in one. The replaced session ends after
+// SessionGrace, keeping the privileges it had.
+//
+// Returns:
+//   - The new token, it goes into the "token" cookie as is, only its hash is stored.
+func StartSession(User model.Users, Grants controller.Grants, UserAgent string, IP string, Previous string) (string, error) {
+	if Previous != "" { replace(Previous) }
+
+	token := randomHex(32)
+	Session := model.Sessions{
+		UsersID: User.ID,
+		TokenHash: hashToken(token),
+		Roles: roles(Grants),
+		UserAgent: UserAgent,
+		IP: IP,
+		ExpiresAt: time.Now().Add(SessionFor),
+		LastUsedAt: time.Now(),
+	}
+	if err := storage.DB.Create(&Session).Error; err != nil { return "", err }
+	return token, nil
+}
+
+// Session returns the user signed in with the token, the email must be the user's.
+func Session(Email string, token string) (model.Users, model.Sessions, error) {
+	var Session model.Sessions
+
+	result := storage.DB.Preload("Users").Where(&model.Sessions{ TokenHash: hashToken(token) }).Last(&Session)
+	if result.Error != nil || Session.Users.Email != Email { return model.Users{}, Session, ErrSessionInvalid }
+
+	if time.Now().After(Session.ExpiresAt) {
+		storage.DB.Unscoped().Delete(&Session)
+		return model.Users{}, Session, ErrSessionInvalid
+	}
+
+	/* Recorded at most once a minute, not on every request of a page */
+	if time.Since(Session.LastUsedAt) > time.Minute { storage.DB.Model(&Session).Update("LastUsedAt", time.Now()) }
+	return Session.Users, Session, nil
+}
+
+// Elevated reports whether the user got a role since the session was issued, it must then be replaced
+// by a new one (StartSession). A session replaced already isn't, its successor has the role.
+func Elevated(Session model.Sessions, Grants controller.Grants) bool {
+	if Session.ReplacedAt != nil { return false }
+
+	Issued := map[string]bool{}
+	for _, Role := range strings.Split(Session.Roles, ",") { Issued[Role] = true }
+
+	for Role := range Grants.Roles {
+		if !Issued[Role] { return true }
+	}
+	return false
+}
+
+// EndSession ends the session of the token, used on logout and when a session is replaced.
+func EndSession(token string) {
+	storage.DB.Unscoped().Where(&model.Sessions{ TokenHash: hashToken(token) }).Delete(&model.Sessions{})
+}
+
+/* Only the first replacement counts, a replaced token's grace isn't extended by requests racing with it */
+func replace(token string) {
+	Now := time.Now()
+	storage.DB.Model(&model.Sessions{}).
+		Where(model.SessionsTokenHash + " = ? AND " + model.SessionsReplacedAt + " IS NULL", hashToken(token)).
+		Updates(map[string]interface{}{
+			model.SessionsReplacedAt: Now,
+			model.SessionsExpiresAt: gorm.Expr("LEAST(" + model.SessionsExpiresAt + ", ?)", Now.Add(SessionGrace)),
+		})
+}
+
+func roles(Grants controller.Grants) string {
+	Roles := []string{}
+	for Role := range Grants.Roles { Roles = append(Roles, Role) }
+	sort.Strings(Roles)
+	return strings.Join(Roles, ",")
+}
+
+// WriteSession hands the session's token to the client: the "user" and "token" cookies, and the HX-AUTH-META
+// header the admin's scripts keep it from (see view.LoginListener).
+func WriteSession(ctx *controller.Context, User model.Users, token string) {
+	Expires := time.Now().Add(SessionFor)
+	AuthHtmxMeta, _ := json.Marshal(struct {
+		Expires		time.Time	`json:"Expires"`
+		Email		string		`json:"Email"`
+		Token		string		`json:"Token"`
+	}{
+		Expires: Expires, Email: User.Email, Token: token,
+	})
+
+	ctx.WriteCookie(controller.NewCookie("user", User.Email, Expires))
+	ctx.WriteCookie(controller.NewCookie("token", token, Expires))
+	ctx.Response().Header().Set("HX-AUTH-META", string(AuthHtmxMeta))
+}
+
+// ClearSession removes the session's cookies, and the token the admin's scripts kept.
+func ClearSession(ctx *controller.Context) {
+	ctx.DeleteCookie("user")
+	ctx.DeleteCookie("token")
+	ctx.DeleteCookie(RememberCookie)
+	ctx.Response().Header().Set("HX-AUTH-META", "null")
+}
@@ -0,0 +1,284 @@
+// Package budgeter checks the key pages of a staging site against performance budgets, so a page growing heavier
+// is noticed before it's released: the weight of the page (its html, render-blocking assets and images), the number
+// of render-blocking assets (stylesheets and synchronous scripts of the head), the weight of its images and of the
+// largest one.
+//
+// Pages are fetched from APP_URL as a browser would, images are weighed with what the upload pipeline recorded of
+// them (Files.Size, the WebP/AVIF derivative a browser accepting them gets, see converter.Best) and with a HEAD
+// request when it knows nothing of them. Each budget has a Warn and a Fail limit: going over Warn is reported,
+// going over Fail fails the job as well, so it's listed among the job failures.
+//
+// Budgets are read from the json file at globals.Env.BUDGETS (see budgets.example.json), Defaults without one.
+// A budget leaves out the limits it doesn't set (0).
+package budgeter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/server/common/globals"
+	"main/server/common/ids"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/converter"
+)
+
+// Statuses of a Budget_reports row.
+const (
+	Pass	= "pass"
+	Warn	= "warn"
+	Fail	= "fail"
+)
+
+// CheckEvery is how often the job checks the pages.
+const CheckEvery = 6 * time.Hour
+
+// Keep is how long reports are kept.
+const Keep = 30 * 24 * time.Hour
+
+// Timeout bounds a request to the site.
+const Timeout = 30 * time.Second
+
+// MaxPage is how much of a page is read, a larger one fails its payload budget anyway.
+const MaxPage = 10 << 20
+
+/* Browsers get the smallest derivative of an image, the check measures what a current one downloads */
+const Accept = "image/avif,image/webp,*/*"
+
+var ErrOverBudget = errors.New("pages over their performance budget")
+
+// Environments are those the job runs on, production isn't measured.
+var Environments = []string{"staging"}
+
+// Limit is a budget's limits of a measure, a page over Warn is reported, over Fail it fails the check.
+type Limit struct {
+	Warn	int64	`json:"warn"`
+	Fail	int64	`json:"fail"`
+}
+
+// Budget is what a page may weigh: Payload (bytes) in all, Blocking render-blocking assets, Images (bytes) of
+// images and Image (bytes) for the largest one.
+type Budget struct {
+	Path		string	`json:"path"`
+	Payload		Limit	`json:"payload"`
+	Blocking	Limit	`json:"blocking"`
+	Images		Limit	`json:"images"`
+	Image		Limit	`json:"image"`
+}
+
+// Defaults are the budgets of the key pages when BUDGETS isn't set.
+var Defaults = []Budget{
+	{ Path: "/", Payload: Limit{ Warn: 1500000, Fail: 3000000 }, Blocking: Limit{ Warn: 4, Fail: 8 }, Images: Limit{ Warn: 1000000, Fail: 2500000 }, Image: Limit{ Warn: 250000, Fail: 600000 } },
+	{ Path: "/categories", Payload: Limit{ Warn: 1000000, Fail: 2000000 }, Blocking: Limit{ Warn: 4, Fail: 8 }, Image: Limit{ Warn: 200000, Fail: 500000 } },
+	{ Path: "/news", Payload: Limit{ Warn: 1000000, Fail: 2000000 }, Blocking: Limit{ Warn: 4, Fail: 8 }, Image: Limit{ Warn: 200000, Fail: 500000 } },
+	{ Path: "/about", Payload: Limit{ Warn: 800000, Fail: 1500000 }, Blocking: Limit{ Warn: 4, Fail: 8 } },
+}
+
+var client = &http.Client{ Timeout: Timeout }
+
+var (
+	tags = regexp.MustCompile(`(?is)<(link|script|img)\b([^>]*)>`)
+	attributes = regexp.MustCompile(`(?s)([a-zA-Z:-]+)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+))?`)
+)
+
+// Allowed reports whether the job runs on the environment.
+func Allowed(GOENV string) bool {
+	for _, Environment := range Environments {
+		if GOENV == Environment { return true }
+	}
+	return false
+}
+
+// Load reads the budgets, an empty path means Defaults.
+func Load(file string) ([]Budget, error) {
+	if file == "" { return Defaults, nil }
+
+	data, err := os.ReadFile(file)
+	if err != nil { return nil, err }
+
+	var Budgets []Budget
+	if err := json.Unmarshal(data, &Budgets); err != nil { return nil, fmt.Errorf("%s: %w", file, err) }
+	return Budgets, nil
+}
+
+// Check measures every page with a budget and records a report of each, the reports of a run share its time.
+//
+// Returns:
+//   - ErrOverBudget, wrapped with the pages, when one went over a Fail limit or couldn't be measured.
+func Check(ctx context.Context) error {
+	if globals.Env.APP_URL == "" { return nil }
+	Budgets, err := Load(globals.Env.BUDGETS)
+	if err != nil { return err }
+
+	Now := time.Now()
+	Failed := []string{}
+	for _, Limits := range Budgets {
+		Report := Measure(ctx, Limits)
+		Report.CheckedAt = Now
+		if err := storage.DB.WithContext(ctx).Create(&Report).Error; err != nil { return err }
+		if Report.Status == Fail { Failed = append(Failed, Report.Path) }
+	}
+
+	if err := storage.DB.WithContext(ctx).Unscoped().Where("checked_at < ?", Now.Add(-Keep)).Delete(&model.Budget_reports{}).Error; err != nil { return err }
+	if len(Failed) > 0 { return fmt.Errorf("%w: %s", ErrOverBudget, strings.Join(Failed, ", ")) }
+	return nil
+}
+
+// Latest returns the reports of the last run, by path.
+func Latest() ([]model.Budget_reports, error) {
+	var Reports []model.Budget_reports
+	err := storage.DB.Where("checked_at = (?)", storage.DB.Model(&model.Budget_reports{}).Select("MAX(checked_at)")).Order("path").Find(&Reports).Error
+	return Reports, err
+}
+
+// Measure fetches the page of a budget and weighs it against the budget, a page which can't be fetched fails.
+func Measure(ctx context.Context, Limits Budget) model.Budget_reports {
+	Report := model.Budget_reports{ Path: Limits.Path, Status: Pass }
+
+	Page, err := fetch(ctx, globals.Env.APP_URL + Limits.Path)
+	if err != nil {
+		Report.Status, Report.Error = Fail, err.Error()
+		return Report
+	}
+	Report.Payload = int64(len(Page))
+
+	/* Only what's in the head holds the first render back, async, deferred and module scripts don't */
+	Head := len(Page)
+	if End := strings.Index(strings.ToLower(Page), "</head>"); End >= 0 { Head = End }
+
+	for _, Match := range tags.FindAllStringSubmatchIndex(Page, -1) {
+		Tag := strings.ToLower(Page[Match[2]:Match[3]])
+		Attributes := parse(Page[Match[4]:Match[5]])
+
+		switch {
+			case Tag == "img":
+				Size := weigh(ctx, Attributes["src"], true)
+				Report.Images += Size
+				if Size > Report.LargestImage { Report.LargestImage = Size }
+				Report.Payload += Size
+			case Match[0] < Head && blocking(Tag, Attributes):
+				Report.Blocking++
+				if Tag == "link" {
+					Report.Payload += weigh(ctx, Attributes["href"], false)
+				} else {
+					Report.Payload += weigh(ctx, Attributes["src"], false)
+				}
+		}
+	}
+
+	Exceeded := []string{}
+	for _, Measured := range []struct{ Name string; Value int64; Limit Limit; Bytes bool }{
+		{ "payload", Report.Payload, Limits.Payload, true },
+		{ "blocking", int64(Report.Blocking), Limits.Blocking, false },
+		{ "images", Report.Images, Limits.Images, true },
+		{ "image", Report.LargestImage, Limits.Image, true },
+	} {
+		Status, Over := judge(Measured.Value, Measured.Limit)
+		if Status == Pass { continue }
+		if Status == Fail || Report.Status == Pass { Report.Status = Status }
+		Exceeded = append(Exceeded, fmt.Sprintf("%s %s over %s (%s)", Measured.Name, amount(Measured.Value, Measured.Bytes), amount(Over, Measured.Bytes), Status))
+	}
+	Report.Exceeded = strings.Join(Exceeded, "\n")
+	return Report
+}
+
+/* The status of a measure and the limit it went over */
+func judge(Value int64, Bounds Limit) (string, int64) {
+	if Bounds.Fail > 0 && Value > Bounds.Fail { return Fail, Bounds.Fail }
+	if Bounds.Warn > 0 && Value > Bounds.Warn { return Warn, Bounds.Warn }
+	return Pass, 0
+}
+
+func amount(Value int64, Bytes bool) string {
+	if !Bytes { return strconv.FormatInt(Value, 10) }
+	return strconv.FormatFloat(float64(Value) / 1000, 'f', 0, 64) + " kB"
+}
+
+func blocking(Tag string, Attributes map[string]string) bool {
+	if Tag == "link" {
+		return strings.EqualFold(Attributes["rel"], "stylesheet") && Attributes["media"] != "print"
+	}
+	_, Async := Attributes["async"]
+	_, Defer := Attributes["defer"]
+	return Attributes["src"] != "" && !Async && !Defer && Attributes["type"] != "module"
+}
+
+func parse(Raw string) map[string]string {
+	Attributes := map[string]string{}
+	for _, Match := range attributes.FindAllStringSubmatch(Raw, -1) {
+		Attributes[strings.ToLower(Match[1])] = strings.Trim(Match[2], `"'`)
+	}
+	return Attributes
+}
+
+func fetch(ctx context.Context, Address string) (string, error) {
+	Request, err := http.NewRequestWithContext(ctx, http.MethodGet, Address, nil)
+	if err != nil { return "", err }
+	Request.Header.Set("Accept", "text/html")
+
+	Response, err := client.Do(Request)
+	if err != nil { return "", err }
+	defer Response.Body.Close()
+	if Response.StatusCode != http.StatusOK { return "", fmt.Errorf("%s answered %s", Address, Response.Status) }
+
+	Page, err := io.ReadAll(io.LimitReader(Response.Body, MaxPage))
+	return string(Page), err
+}
+
+// weigh returns the size of an asset or an image, 0 for those inlined in the page (already counted with it) and
+// those which can't be reached.
+func weigh(ctx context.Context, Source string, Image bool) int64 {
+	if Source == "" || strings.HasPrefix(Source, "data:") { return 0 }
+
+	Site, err := url.Parse(globals.Env.APP_URL)
+	if err != nil { return 0 }
+	Address, err := Site.Parse(Source)
+	if err != nil { return 0 }
+
+	if Image && Address.Host == Site.Host {
+		if Size, found := recorded(Address.Path); found { return Size }
+	}
+	return head(ctx, Address.String())
+}
+
+/* What the upload pipeline knows of an image, by its path (Files.Path) or its download route (/files/:id) */
+func recorded(Path string) (int64, bool) {
+	var File model.Files
+	Query := storage.DB.Preload("Derivatives")
+	if ID, found := strings.CutPrefix(Path, "/files/"); found {
+		Query = Query.Scopes(ids.Match(strings.TrimSuffix(ID, "/")))
+	} else {
+		Query = Query.Where("path = ?", Path)
+	}
+	if Query.First(&File).Error != nil { return 0, false }
+
+	if Key, ok := converter.Best(File, 0, Accept); ok && File.Compressed {
+		for _, Derivative := range File.Derivatives {
+			if Derivative.Key == Key { return int64(Derivative.Size), true }
+		}
+	}
+	return int64(File.Size), true
+}
+
+/* The Content-Length a HEAD request is answered with, 0 without one */
+func head(ctx context.Context, Address string) int64 {
+	Request, err := http.NewRequestWithContext(ctx, http.MethodHead, Address, nil)
+	if err != nil { return 0 }
+	Request.Header.Set("Accept", Accept)
+
+	Response, err := client.Do(Request)
+	if err != nil { return 0 }
+	Response.Body.Close()
+	if Response.StatusCode != http.StatusOK || Response.ContentLength < 0 { return 0 }
+	return Response.ContentLength
+}
@@ -0,0 +1,140 @@
+// Package chaos injects faults into requests, to see how the htmx front end and api clients cope with
+// a slow or failing server: their timeouts, retries and fallbacks.
+//
+// Faults are configured in the json file at globals.Env.CHAOS (see chaos.example.json), and only on development
+// and staging (GOENV), production ignores the file. Each rule affects the share Rate of the requests matching
+// its Method and Route (a path.Match pattern of the url path, "" matches all), in order:
+//
+//   { "route": "/news/*", "rate": 0.2, "latency": "300ms-2s" }    delay, uniformly within the range
+//   { "route": "/admin/*", "method": "POST", "rate": 0.1, "error": 503 }    answer with the status
+//   { "route": "/upload", "rate": 0.05, "drop": true }    close the connection without answering
+//
+// The latencies of the rules that fire add up, the first error or drop ends the request.
+package chaos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Rule struct {
+	Route		string		`json:"route"`
+	Method		string		`json:"method"`
+	Rate		float64		`json:"rate"`
+	Latency		string		`json:"latency"`
+	Error		int			`json:"error"`
+	Drop		bool		`json:"drop"`
+
+	min			time.Duration
+	max			time.Duration
+}
+
+// Fault is what the rules matching a request decided to do to it.
+type Fault struct {
+	Latency		time.Duration
+	Error		int
+	Drop		bool
+}
+
+var (
+	mu sync.RWMutex
+	configured []Rule
+)
+
+// Environments is where faults may be injected.
+var Environments = []string{"development", "staging"}
+
+// Allowed reports whether faults may be injected on the environment.
+func Allowed(GOENV string) bool {
+	for _, Environment := range Environments {
+		if GOENV == Environment { return true }
+	}
+	return false
+}
+
+// Load reads the rules, an empty path or a missing file means there are none.
+func Load(file string) error {
+	var Rules []Rule
+
+	data, err := os.ReadFile(file)
+	if file == "" || errors.Is(err, os.ErrNotExist) { data, err = []byte("[]"), nil }
+	if err != nil { return err }
+	if err := json.Unmarshal(data, &Rules); err != nil { return fmt.Errorf("%s: %w", file, err) }
+
+	for i := range Rules {
+		Rule := &Rules[i]
+		if Rule.Rate < 0 || Rule.Rate > 1 { return fmt.Errorf("%s: rate must be between 0 and 1", file) }
+		if Rule.Error != 0 && (Rule.Error < 400 || Rule.Error > 599) { return fmt.Errorf("%s: error must be a 4xx or 5xx status", file) }
+		if _, err := path.Match(Rule.Route, "/"); err != nil { return fmt.Errorf("%s: route %q: %w", file, Rule.Route, err) }
+		if Rule.min, Rule.max, err = latency(Rule.Latency); err != nil { return fmt.Errorf("%s: latency %q: %w", file, Rule.Latency, err) }
+	}
+
+	mu.Lock()
+	configured = Rules
+	mu.Unlock()
+	return nil
+}
+
+// Enabled reports whether any rule is configured.
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(configured) > 0
+}
+
+// Roll decides the fault of a request, the zero Fault when no rule fires.
+func Roll(Method string, Path string) Fault {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var Fault Fault
+	for _, Rule := range configured {
+		if !Rule.matches(Method, Path) || rand.Float64() >= Rule.Rate { continue }
+
+		Fault.Latency += Rule.min + time.Duration(rand.Int63n(int64(Rule.max - Rule.min) + 1))
+		if Rule.Error != 0 || Rule.Drop {
+			Fault.Error, Fault.Drop = Rule.Error, Rule.Drop
+			break
+		}
+	}
+	return Fault
+}
+
+// String describes the fault, for the X-Chaos response header.
+func (Fault Fault) String() string {
+	Parts := []string{}
+	if Fault.Latency > 0 { Parts = append(Parts, "latency=" + Fault.Latency.String()) }
+	if Fault.Error != 0 { Parts = append(Parts, fmt.Sprintf("error=%d", Fault.Error)) }
+	if Fault.Drop { Parts = append(Parts, "drop") }
+	return strings.Join(Parts, ",")
+}
+
+func (Rule Rule) matches(Method string, Path string) bool {
+	if Rule.Method != "" && !strings.EqualFold(Rule.Method, Method) { return false }
+	if Rule.Route == "" { return true }
+	matched, _ := path.Match(Rule.Route, Path)
+	return matched
+}
+
+/* "2s" or "300ms-2s" */
+func latency(value string) (time.Duration, time.Duration, error) {
+	if value == "" { return 0, 0, nil }
+
+	Low, High, ranged := strings.Cut(value, "-")
+	Min, err := time.ParseDuration(strings.TrimSpace(Low))
+	if err != nil { return 0, 0, err }
+	if Min < 0 { return 0, 0, errors.New("latency can't be negative") }
+	if !ranged { return Min, Min, nil }
+
+	Max, err := time.ParseDuration(strings.TrimSpace(High))
+	if err != nil { return 0, 0, err }
+	if Max < Min { return 0, 0, errors.New("the range must go from low to high") }
+	return Min, Max, nil
+}
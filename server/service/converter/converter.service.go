@@ -0,0 +1,210 @@
+// Package converter makes copies of image uploads in the modern formats of globals.Env.IMAGE_FORMATS (webp, avif)
+// with ffmpeg, in the background. The image and each of its thumbnails are converted, stored next to their source
+// under its key with the format as a suffix:
+//
+//   uploads/3f2a....jpg              the upload
+//   uploads/3f2a....jpg.webp         its webp copy
+//   uploads/3f2a....jpg.480.jpg      its 480px wide thumbnail
+//   uploads/3f2a....jpg.480.webp     the thumbnail's webp copy
+//
+// and recorded as File_derivatives, Files.Compressed is set once the image has one. Best picks the copy a request's
+// Accept header allows. A copy which isn't smaller than its source is dropped, so is every copy when ffmpeg isn't
+// installed: the image is always served as it was uploaded then.
+package converter
+
+import (
+	"context"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/blob"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/thumbnailer"
+)
+
+// Workers is how many images are converted at once, avif encoding especially is heavy on the cpu.
+const Workers = 2
+
+// Timeout bounds the conversion of a single image and its thumbnails.
+const Timeout = 5 * time.Minute
+
+// Quality of the webp copies, 0 to 100.
+const Quality = 80
+
+// Preferred lists the formats from the smallest they usually make, Best picks the first the client accepts.
+var Preferred = []string{"avif", "webp"}
+
+var workers = make(chan struct{}, Workers)
+
+// Available reports whether images are converted at all: ffmpeg is installed and IMAGE_FORMATS names a format.
+func Available() bool {
+	if len(globals.Env.IMAGE_FORMATS) == 0 { return false }
+	_, err := exec.LookPath("ffmpeg")
+	return err == nil
+}
+
+// Key is the blob key of the copy in the format of the file's thumbnail of the width, or of the image itself for 0.
+func Key(File model.Files, Width int, Format string) string {
+	Source := File.Path
+	if Width > 0 { Source = File.Path + "." + strconv.Itoa(Width) }
+	return blob.Key(Source) + "." + Format
+}
+
+// Enqueue converts the image in the background, other files are ignored.
+func Enqueue(File model.Files) {
+	if !thumbnailer.IsImage(File) || !Available() { return }
+
+	go func() {
+		workers <- struct{}{}
+		defer func() { <-workers }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+
+		if err := Convert(ctx, File); err != nil { log.Print("Converting image ", File.ID, ": ", err) }
+	}()
+}
+
+// Convert makes and records the copies of the image and its thumbnails in every format of IMAGE_FORMATS,
+// replacing those it had.
+//
+// Notes:
+//   - Only the first frame of a gif is kept, as its thumbnails do.
+//   - avif copies are made of jpegs only, transparency would be lost.
+func Convert(ctx context.Context, File model.Files) error {
+	if !thumbnailer.IsImage(File) || !Available() { return nil }
+
+	var Thumbnails []model.File_thumbnails
+	if err := storage.DB.WithContext(ctx).Where("file_id = ?", File.ID).Find(&Thumbnails).Error; err != nil { return err }
+
+	Sources := map[int]string{ 0: blob.Key(File.Path) }
+	for _, Thumbnail := range Thumbnails { Sources[Thumbnail.Width] = blob.Key(Thumbnail.Path) }
+
+	Derivatives := []model.File_derivatives{}
+	for Width, key := range Sources {
+		Made, err := convertSource(ctx, File, Width, key)
+		if err != nil { return err }
+		Derivatives = append(Derivatives, Made...)
+	}
+
+	return storage.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("file_id = ?", File.ID).Delete(&model.File_derivatives{}).Error; err != nil { return err }
+		if len(Derivatives) > 0 {
+			if err := tx.Create(&Derivatives).Error; err != nil { return err }
+		}
+		return tx.Model(&model.Files{}).Where("id = ?", File.ID).Update(model.FilesCompressed, len(Derivatives) > 0).Error
+	})
+}
+
+/* Every format's copy of one source, the image or a thumbnail */
+func convertSource(ctx context.Context, File model.Files, Width int, key string) ([]model.File_derivatives, error) {
+	local, Size, err := copyLocal(ctx, key)
+	if err != nil { return nil, err }
+	defer os.Remove(local)
+
+	Derivatives := []model.File_derivatives{}
+	for _, Format := range globals.Env.IMAGE_FORMATS {
+		if !convertible(File, Format) { continue }
+
+		output := local + "." + Format
+		if err := encode(ctx, local, output, Format); err != nil { return nil, err }
+		Derivative, err := keep(ctx, File, Width, Format, output, Size)
+		os.Remove(output)
+		if err != nil { return nil, err }
+		if Derivative != nil { Derivatives = append(Derivatives, *Derivative) }
+	}
+	return Derivatives, nil
+}
+
+func convertible(File model.Files, Format string) bool {
+	if Format != "avif" { return true }
+	Extension := strings.ToLower(filepath.Ext(File.Name))
+	return Extension == ".jpg" || Extension == ".jpeg"
+}
+
+func encode(ctx context.Context, input string, output string, Format string) error {
+	Arguments := []string{"-y", "-loglevel", "error", "-i", input, "-frames:v", "1"}
+	switch Format {
+		case "webp":
+			Arguments = append(Arguments, "-c:v", "libwebp", "-quality", strconv.Itoa(Quality), "-f", "webp")
+		case "avif":
+			Arguments = append(Arguments, "-c:v", "libaom-av1", "-still-picture", "1", "-crf", "32", "-cpu-used", "6", "-pix_fmt", "yuv420p", "-f", "avif")
+	}
+	return exec.CommandContext(ctx, "ffmpeg", append(Arguments, output)...).Run()
+}
+
+/* The copy is stored and recorded only when it saves bytes, nil otherwise */
+func keep(ctx context.Context, File model.Files, Width int, Format string, output string, Source int64) (*model.File_derivatives, error) {
+	file, err := os.Open(output)
+	if err != nil { return nil, err }
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil { return nil, err }
+	if info.Size() == 0 || info.Size() >= Source { return nil, nil }
+
+	key := Key(File, Width, Format)
+	if err := blob.Default().Put(ctx, key, file, info.Size(), mime.TypeByExtension("." + Format)); err != nil { return nil, err }
+	return &model.File_derivatives{ FileID: File.ID, Format: Format, Width: Width, Key: key, Size: int(info.Size()) }, nil
+}
+
+// Best is the key of the file's copy (of its thumbnail of the width, of the image itself for 0) in the format the
+// Accept header prefers, see Preferred. The file's Derivatives must be loaded, ok is false when none is accepted.
+//
+// Example usage:
+//   if key, ok := converter.Best(File, 0, ctx.Request().Header.Get("Accept")); ok {
+//       return ctx.Download(File, controller.Variant(key))
+//   }
+func Best(File model.Files, Width int, Accept string) (string, bool) {
+	for _, Format := range Preferred {
+		if !accepts(Accept, "image/" + Format) { continue }
+		for _, Derivative := range File.Derivatives {
+			if Derivative.Width == Width && Derivative.Format == Format { return Derivative.Key, true }
+		}
+	}
+	return "", false
+}
+
+/* Only an explicit mention counts, "*\/*" doesn't mean the browser decodes avif. q=0 refuses the type */
+func accepts(Accept string, Type string) bool {
+	for _, Part := range strings.Split(Accept, ",") {
+		Fields := strings.Split(Part, ";")
+		if strings.TrimSpace(Fields[0]) != Type { continue }
+		for _, Parameter := range Fields[1:] {
+			if Value, ok := strings.CutPrefix(strings.TrimSpace(Parameter), "q="); ok {
+				if Weight, err := strconv.ParseFloat(Value, 64); err == nil && Weight == 0 { return false }
+			}
+		}
+		return true
+	}
+	return false
+}
+
+/* ffmpeg reads local files, the blob (which may live on S3) is copied into a temporary one */
+func copyLocal(ctx context.Context, key string) (string, int64, error) {
+	reader, _, err := blob.Default().Open(ctx, key)
+	if err != nil { return "", 0, err }
+	defer reader.Close()
+
+	local, err := os.CreateTemp("", "image-*" + filepath.Ext(key))
+	if err != nil { return "", 0, err }
+	defer local.Close()
+
+	Size, err := io.Copy(local, reader)
+	if err != nil {
+		os.Remove(local.Name())
+		return "", 0, err
+	}
+	return local.Name(), Size, nil
+}
@@ -0,0 +1,124 @@
+// Package digest sums up a week of changes for the admins.
+package digest
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/build/view"
+	"main/server/common/i18n"
+	"main/server/common/storage"
+	"main/server/model"
+	mailer "main/server/service/mail"
+)
+
+const Period = 7 * 24 * time.Hour
+
+type Summary struct {
+	From			time.Time
+	To				time.Time
+	News			int64
+	Faq				int64
+	Categories		int64
+	Products		int64
+	Uploads			int64
+	Users			int64
+	FailedJobs		int64
+}
+
+// Build counts what changed between From and To.
+func Build(From time.Time, To time.Time) Summary {
+	Summary := Summary{ From: From, To: To }
+
+	changed := func(record any, count *int64) {
+		storage.DB.Model(record).Where("updated_at BETWEEN ? AND ?", From, To).Count(count)
+	}
+	created := func(record any, count *int64) {
+		storage.DB.Model(record).Where("created_at BETWEEN ? AND ?", From, To).Count(count)
+	}
+
+	changed(&model.News{}, &Summary.News)
+	changed(&model.Faq{}, &Summary.Faq)
+	changed(&model.Categories{}, &Summary.Categories)
+	changed(&model.Products{}, &Summary.Products)
+	created(&model.Files{}, &Summary.Uploads)
+	created(&model.Users{}, &Summary.Users)
+	created(&model.Job_failures{}, &Summary.FailedJobs)
+
+	return Summary
+}
+
+// Due reports whether a Period passed since the last digest, returning when the next one starts from.
+func Due(Now time.Time) (time.Time, bool) {
+	var Last model.Digests
+	if storage.DB.Order("period_end desc").First(&Last).Error != nil { return Now.Add(-Period), true }
+	return Last.PeriodEnd, Now.Sub(Last.PeriodEnd) >= Period
+}
+
+// Send mails the digest to every admin who didn't turn it off and didn't get it yet. A new digest starts once it's
+// due, until then the runs resume the last one, so an admin it failed for gets it later and no one gets it twice.
+func Send(ctx context.Context) error {
+	Digest, err := current(time.Now())
+	if err != nil { return err }
+
+	var Users []model.Users
+	err = storage.DB.Where("digest_weekly = ?", true).
+		Where("NOT EXISTS (SELECT 1 FROM digest_deliveries WHERE digests_id = ? AND users_id = users.id AND deleted_at IS NULL)", Digest.ID).
+		Find(&Users).Error
+	if err != nil { return err }
+
+	Summary := Build(Digest.PeriodStart, Digest.PeriodEnd)
+	Theme := mailer.Theme(0)
+	for _, User := range Users {
+		Locale := i18n.Negotiate(User.Locale)
+
+		Body, err := mailer.RenderWith(i18n.WithLocale(ctx, Locale), Theme, view.DigestMail(User.Fullname, Summary.Lines(Locale)))
+		if err != nil { return err }
+		if _, err := mailer.Send(mailer.Config{ To: User.Email, Subject: i18n.Translate(Locale, "digest.title"), Body: Body }); err != nil {
+			log.Print("Sending digest to ", User.Email, ": ", err)
+			continue
+		}
+		if err := delivered(Digest, User); err != nil { return err }
+	}
+	return nil
+}
+
+/* The digest being sent, the last one until a new one is due */
+func current(Now time.Time) (model.Digests, error) {
+	var Last model.Digests
+	err := storage.DB.Order("period_end desc").First(&Last).Error
+	switch {
+		case errors.Is(err, gorm.ErrRecordNotFound): Last.PeriodEnd = Now.Add(-Period)
+		case err != nil: return Last, err
+		case Now.Sub(Last.PeriodEnd) < Period: return Last, nil
+	}
+
+	Digest := model.Digests{ PeriodStart: Last.PeriodEnd, PeriodEnd: Now }
+	return Digest, storage.DB.Create(&Digest).Error
+}
+
+/* Records the admin got the digest, right after the mail so a failing run doesn't send it again */
+func delivered(Digest model.Digests, User model.Users) error {
+	return storage.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.Digest_deliveries{ DigestsID: Digest.ID, UsersID: User.ID }).Error; err != nil { return err }
+		return tx.Model(&Digest).Update("recipients", gorm.Expr("recipients + 1")).Error
+	})
+}
+
+// Lines renders the summary as translated lines, the mail and the dashboard widget list them as is.
+func (Summary Summary) Lines(Locale string) []string {
+	return []string{
+		i18n.Translate(Locale, "digest.period", Summary.From.Format("2006-01-02"), Summary.To.Format("2006-01-02")),
+		i18n.Translate(Locale, "digest.news", Summary.News),
+		i18n.Translate(Locale, "digest.faq", Summary.Faq),
+		i18n.Translate(Locale, "digest.categories", Summary.Categories),
+		i18n.Translate(Locale, "digest.products", Summary.Products),
+		i18n.Translate(Locale, "digest.uploads", Summary.Uploads),
+		i18n.Translate(Locale, "digest.users", Summary.Users),
+		i18n.Translate(Locale, "digest.jobs", Summary.FailedJobs),
+	}
+}
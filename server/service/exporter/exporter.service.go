@@ -0,0 +1,171 @@
+// Package exporter writes admin listings as CSV files. Small listings are written straight into the response,
+// those of more rows than EXPORT_ASYNC_ROWS are made by a background task (see package tasks) which stores the
+// file and mails its owner a signed download link, so a large export neither holds a request open nor loads
+// the whole listing in memory.
+package exporter
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/build/view"
+	"main/server/common/blob"
+	"main/server/common/globals"
+	uploader "main/server/common/helpers"
+	"main/server/common/i18n"
+	"main/server/common/storage"
+	"main/server/model"
+	mailer "main/server/service/mail"
+	"main/server/service/notifications"
+	"main/server/service/tasks"
+)
+
+// BatchSize is how many rows are read from the database at once, progress is reported once per batch.
+const BatchSize = 500
+
+// LinkExpiry is how long the mailed download link of an export stays valid.
+const LinkExpiry = 7 * 24 * time.Hour
+
+// Kind is the tasks' kind of exports.
+const Kind = "export"
+
+/* Excel reads CSV files without it as ANSI, Georgian text would be garbled */
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// Listing is a listing which can be exported: Query selects its rows (filters, order and preloads included),
+// Row turns one into the cells of Header.
+type Listing[T any] struct {
+	Name		string					/* of the file, without date nor extension */
+	Title		string					/* of the task */
+	Header		[]string
+	Query		*gorm.DB
+	Row			func(T) []string
+}
+
+// Async reports whether the listing of Count rows is exported in the background.
+func Async(Count int64) bool {
+	return Count > int64(globals.Env.EXPORT_ASYNC_ROWS)
+}
+
+// FileName is the name the export is downloaded as, "products-20240131-150405.csv".
+func FileName[T any](Listing Listing[T]) string {
+	return Listing.Name + "-" + time.Now().Format("20060102-150405") + ".csv"
+}
+
+// Write writes the listing as CSV, one batch of rows after the other, its cells escaped (see Escape). progress,
+// when given, is called with the rows written so far after every batch.
+func Write[T any](ctx context.Context, w io.Writer, Listing Listing[T], progress func(Done int)) error {
+	if _, err := w.Write(bom); err != nil { return err }
+
+	Writer := csv.NewWriter(w)
+	if err := Writer.Write(Listing.Header); err != nil { return err }
+
+	Done := 0
+	var Batch []T
+	Result := Listing.Query.WithContext(ctx).FindInBatches(&Batch, BatchSize, func(tx *gorm.DB, _ int) error {
+		for _, Record := range Batch {
+			if err := Writer.Write(Escape(Listing.Row(Record))); err != nil { return err }
+		}
+		Writer.Flush()
+		if err := Writer.Error(); err != nil { return err }
+
+		/* Streamed responses send each batch as it's written */
+		if Flusher, ok := w.(interface{ Flush() }); ok { Flusher.Flush() }
+
+		Done += len(Batch)
+		if progress != nil { progress(Done) }
+		return nil
+	})
+	if Result.Error != nil { return Result.Error }
+
+	Writer.Flush()
+	return Writer.Error()
+}
+
+// Escape keeps spreadsheets from running cells as formulas: those starting with = + - @, a tab or a carriage
+// return are prefixed with a quote, which Excel and LibreOffice show as text. The cells are escaped in place.
+func Escape(Cells []string) []string {
+	for i, Cell := range Cells {
+		if Cell != "" && strings.ContainsRune("=+-@\t\r", rune(Cell[0])) { Cells[i] = "'" + Cell }
+	}
+	return Cells
+}
+
+// Start exports the listing of Count rows in a background task of the user. The CSV is stored as an admin-only
+// upload, linked by the task rather than charged to their quota. Once it's done they're notified and mailed
+// a download link valid for LinkExpiry.
+//
+// Example usage:
+//   if exporter.Async(Count) {
+//       Task, err := exporter.Start(User, Listing, Count)
+//       ...
+//   }
+func Start[T any](User model.Users, Listing Listing[T], Count int64) (model.Tasks, error) {
+	Name := FileName(Listing)
+	return tasks.Run(User.ID, Kind, Listing.Title, int(Count), func(ctx context.Context, progress func(int)) (*uint, error) {
+		return export(ctx, User, Listing, Name, progress)
+	}, func(Task model.Tasks) { finished(User, Task, Name) })
+}
+
+/* Written into a temporary file first, the blob backends need the size up front */
+func export[T any](ctx context.Context, User model.Users, Listing Listing[T], Name string, progress func(int)) (*uint, error) {
+	Temporary, err := os.CreateTemp("", "export-*.csv")
+	if err != nil { return nil, err }
+	defer os.Remove(Temporary.Name())
+	defer Temporary.Close()
+
+	if err := Write(ctx, Temporary, Listing, progress); err != nil { return nil, err }
+
+	Info, err := Temporary.Stat()
+	if err != nil { return nil, err }
+	if _, err := Temporary.Seek(0, 0); err != nil { return nil, err }
+
+	Path := globals.Env.Uploads + "exports/" + strconv.Itoa(int(User.ID)) + "/" + Name
+	if err := blob.Default().Put(ctx, blob.Key(Path), Temporary, Info.Size(), "text/csv"); err != nil { return nil, err }
+
+	File := model.Files{
+		Name: Name,
+		Original: Name,
+		Location: globals.Env.Uploads + "exports/",
+		Path: Path,
+		Size: int(Info.Size()),
+		Mime: "text/csv",
+		Visibility: model.VisibilityAdmin,
+	}
+	if err := storage.DB.WithContext(ctx).Create(&File).Error; err != nil { return nil, err }
+	return &File.ID, nil
+}
+
+/* The notification links to the tasks page, the mail to the file itself: it's read where nobody is signed in */
+func finished(User model.Users, Task model.Tasks, Name string) {
+	if Task.State != model.TaskDone {
+		if err := notifications.Notify(User.ID, notifications.CategoryTasks, "ექსპორტი ვერ მოხერხდა", Task.Title, "/admin/tasks"); err != nil { log.Print("Notifying export: ", err) }
+		return
+	}
+	if err := notifications.Notify(User.ID, notifications.CategoryTasks, "ექსპორტი მზადაა", Task.Title, "/admin/tasks"); err != nil { log.Print("Notifying export: ", err) }
+
+	URL, err := uploader.SignURL(*Task.FileID, LinkExpiry)
+	if err != nil {
+		log.Print("Signing export ", Task.ID, ": ", err)
+		return
+	}
+
+	Locale := i18n.Negotiate(User.Locale)
+	Expires := time.Now().Add(LinkExpiry).Format("2006-01-02 15:04")
+	Body, err := mailer.Render(i18n.WithLocale(context.Background(), Locale), view.ExportMail(User.Fullname, Name, Task.Total, URL, Expires))
+	if err != nil {
+		log.Print("Rendering export mail: ", err)
+		return
+	}
+	if _, err := mailer.Send(mailer.Config{ To: User.Email, Subject: i18n.Translate(Locale, "export.subject", Task.Title), Body: Body }); err != nil {
+		log.Print("Mailing export ", Task.ID, " to ", User.Email, ": ", err)
+	}
+}
@@ -0,0 +1,37 @@
+package filetypes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"main/server/common/globals"
+)
+
+// Untrusted is the context of uploads which don't come with one the server signed, types restricted to other
+// contexts aren't accepted from them.
+const Untrusted = "upload"
+
+// SignContext returns the upload context as pages hand it to the client (a dropzone's "context" value), signed
+// so the client can't pick another one. An empty context stays empty, such uploads are Untrusted.
+func SignContext(Context string) string {
+	if Context == "" { return "" }
+	return Context + "." + signContext(Context)
+}
+
+// UploadContext returns the context a signed value (see SignContext) names, Untrusted when it isn't signed.
+//
+// Example usage:
+//   Upload := uploader.Store(File.Content, File.Name, File.Size, File.ContentType, filetypes.UploadContext(Form.Values["context"]))
+func UploadContext(Signed string) string {
+	Context, Signature, found := strings.Cut(Signed, ".")
+	if !found || Context == "" || !hmac.Equal([]byte(Signature), []byte(signContext(Context))) { return Untrusted }
+	return Context
+}
+
+func signContext(Context string) string {
+	mac := hmac.New(sha256.New, []byte(globals.Env.COOKIE_SECRET))
+	mac.Write([]byte("upload-context:" + Context))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
@@ -0,0 +1,187 @@
+// Package filetypes is the single source of truth for which files the site accepts.
+//
+// Accepted types live in File_types and are managed from the admin panel (/admin/filetypes), every upload is checked
+// against them by Check. The types are cached in memory, Save and Remove reset the cache so a change
+// made by an admin applies to the next upload without a restart.
+package filetypes
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"strings"
+	"sync"
+
+	"main/server/common/domain"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+var (
+	ErrUnknown = domain.UnsupportedType("file type is not accepted")
+	ErrDisabled = domain.UnsupportedType("file type is disabled")
+	ErrMime = domain.UnsupportedType("file content type is not allowed for its extension")
+	ErrSize = domain.QuotaExceeded("file is too large")
+	ErrContext = domain.UnsupportedType("file type is not accepted here")
+)
+
+var (
+	mu sync.RWMutex
+	cache map[string]model.File_types
+)
+
+// All returns every file type, disabled ones included, ordered by extension.
+func All() []model.File_types {
+	var Types []model.File_types
+	storage.DB.Order("ext").Find(&Types)
+	return Types
+}
+
+// Resolve returns the file type of an extension, with or without its leading dot, from the cache.
+func Resolve(Extension string) (model.File_types, bool) {
+	mu.RLock()
+	Types := cache
+	mu.RUnlock()
+
+	if Types == nil { Types = load() }
+	Type, found := Types[Normalize(Extension)]
+	return Type, found
+}
+
+// Thumbnailed reports whether thumbnails are made of the uploads of an extension, unknown ones are thumbnailed.
+func Thumbnailed(Extension string) bool {
+	Type, found := Resolve(Extension)
+	return !found || Type.Thumbnails
+}
+
+// Check resolves the file type of an upload and validates it.
+// Mime is the content type sniffed from the file (see Detect), never the client's: an empty Mime skips that check,
+// for checks made before the content is read. Context names the place the file is uploaded for ("category",
+// "product", ...), decided by the server (see UploadContext), an empty Context skips that check.
+//
+// Returns:
+//   - The resolved file type.
+//   - ErrUnknown, ErrDisabled, ErrMime, ErrSize or ErrContext, wrapped with details.
+func Check(Extension string, Mime string, Size int64, Context string) (model.File_types, error) {
+	Type, found := Resolve(Extension)
+	if !found { return Type, fmt.Errorf("%w: %s", ErrUnknown, Extension) }
+	if !Type.Enabled { return Type, fmt.Errorf("%w: %s", ErrDisabled, Extension) }
+
+	if Max := limit(Type); Size > Max {
+		return Type, fmt.Errorf("%w: %d bytes, %s files are limited to %d", ErrSize, Size, Type.Ext, Max)
+	}
+
+	if Mimes := List(Type.Mimes); Mime != "" && len(Mimes) > 0 {
+		Mime, _, _ = mime.ParseMediaType(Mime)
+		if !contains(Mimes, strings.ToLower(Mime)) { return Type, fmt.Errorf("%w: %s as %s", ErrMime, Mime, Type.Ext) }
+	}
+
+	if Contexts := List(Type.Contexts); Context != "" && len(Contexts) > 0 && !contains(Contexts, strings.ToLower(Context)) {
+		return Type, fmt.Errorf("%w: %s in %s", ErrContext, Type.Ext, Context)
+	}
+
+	return Type, nil
+}
+
+// Limit returns the largest size (bytes) a file of the extension may have: its type's Max_size,
+// capped by UPLOAD_MAX_SIZE. Unknown extensions get UPLOAD_MAX_SIZE, Check rejects them anyway.
+func Limit(Extension string) int64 {
+	Type, found := Resolve(Extension)
+	if !found { return globals.Env.UPLOAD_MAX_SIZE }
+	return limit(Type)
+}
+
+func limit(Type model.File_types) int64 {
+	if Type.Max_size > 0 && int64(Type.Max_size) < globals.Env.UPLOAD_MAX_SIZE { return int64(Type.Max_size) }
+	return globals.Env.UPLOAD_MAX_SIZE
+}
+
+// Save creates or updates a file type, the extension must be unique.
+func Save(Type *model.File_types) error {
+	Type.Ext = Normalize(Type.Ext)
+	Type.Mimes = strings.Join(List(Type.Mimes), ",")
+	Type.Contexts = strings.Join(List(Type.Contexts), ",")
+	if Type.Ext == "" { return domain.Invalid("extension is required") }
+
+	var Existing model.File_types
+	result := storage.DB.Where(&model.File_types{Ext: Type.Ext}).Limit(1).Find(&Existing)
+	if result.Error != nil { return result.Error }
+	if result.RowsAffected > 0 && Existing.ID != Type.ID { return fmt.Errorf("extension %s already exists", Type.Ext) }
+
+	/* Select("*") so a disabled type is saved as such, gorm skips zero values which have a default otherwise */
+	if Type.ID == 0 {
+		result = storage.DB.Select("*").Omit("ID").Create(Type)
+	} else {
+		result = storage.DB.Select("*").Omit("CreatedAt").Updates(Type)
+	}
+	if result.Error != nil { return result.Error }
+
+	Reset()
+	return nil
+}
+
+// Remove deletes a file type, files already uploaded with it are kept without a type.
+/* Unscoped, a soft deleted row would keep its extension taken */
+func Remove(ID uint) error {
+	if err := storage.DB.Unscoped().Delete(&model.File_types{}, ID).Error; err != nil { return err }
+	Reset()
+	return nil
+}
+
+// Reset drops the cache, the next Resolve reloads it.
+func Reset() {
+	mu.Lock()
+	cache = nil
+	mu.Unlock()
+}
+
+// Cache replaces the cached types with the given ones, which apply until the next Reset without the database being
+// read, e.g. in benchmarks.
+func Cache(Types ...model.File_types) {
+	cached := map[string]model.File_types{}
+	for _, Type := range Types { cached[Normalize(Type.Ext)] = Type }
+
+	mu.Lock()
+	cache = cached
+	mu.Unlock()
+}
+
+// Normalize lower-cases an extension and drops its leading dot.
+func Normalize(Extension string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(Extension), "."))
+}
+
+// List splits a comma separated column, trimmed, lower-cased and without empty entries.
+func List(Value string) []string {
+	var Values []string
+	for _, Entry := range strings.Split(Value, ",") {
+		if Entry = strings.ToLower(strings.TrimSpace(Entry)); Entry != "" { Values = append(Values, Entry) }
+	}
+	return Values
+}
+
+/* A failed load isn't cached, the next upload tries the database again */
+func load() map[string]model.File_types {
+	var Types []model.File_types
+	loaded := map[string]model.File_types{}
+
+	if err := storage.DB.Find(&Types).Error; err != nil {
+		log.Print("Loading file types: ", err)
+		return loaded
+	}
+
+	for _, Type := range Types { loaded[Normalize(Type.Ext)] = Type }
+
+	mu.Lock()
+	cache = loaded
+	mu.Unlock()
+	return loaded
+}
+
+func contains(Values []string, Value string) bool {
+	for _, Entry := range Values {
+		if Entry == Value { return true }
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package filetypes
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"main/server/common/domain"
+)
+
+// SniffLength is how many leading bytes of a file Detect needs, as http.DetectContentType.
+const SniffLength = 512
+
+var ErrContent = domain.UnsupportedType("file content doesn't match its extension")
+
+/* Content each extension may have, as sniffed. Office documents are zip (docx, xlsx) or OLE (doc, xls) containers */
+var signatures = map[string][]string{
+	"jpg": {"image/jpeg"},
+	"jpeg": {"image/jpeg"},
+	"png": {"image/png"},
+	"gif": {"image/gif"},
+	"webp": {"image/webp"},
+	"bmp": {"image/bmp"},
+	"ico": {"image/x-icon"},
+	"avif": {"image/avif"},
+	"heic": {"image/heic"},
+	"svg": {"image/svg+xml"},
+	"pdf": {"application/pdf"},
+	"zip": {"application/zip"},
+	"docx": {"application/zip"},
+	"xlsx": {"application/zip"},
+	"pptx": {"application/zip"},
+	"odt": {"application/zip"},
+	"ods": {"application/zip"},
+	"doc": {"application/x-ole-storage"},
+	"xls": {"application/x-ole-storage"},
+	"ppt": {"application/x-ole-storage"},
+	"mp4": {"video/mp4"},
+	"m4v": {"video/mp4"},
+	"mov": {"video/quicktime", "video/mp4"},
+	"webm": {"video/webm"},
+	"mkv": {"video/webm"},
+	"mp3": {"audio/mpeg"},
+	"wav": {"audio/wave"},
+	"ogg": {"application/ogg"},
+	"txt": {"text/plain"},
+	"csv": {"text/plain"},
+	"json": {"text/plain"},
+	"md": {"text/plain"},
+}
+
+/* Magic numbers http.DetectContentType doesn't know, checked first */
+var magic = []struct {
+	offset		int
+	prefix		[]byte
+	mime		string
+}{
+	{ 0, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}, "application/x-ole-storage" },
+	{ 4, []byte("ftypavif"), "image/avif" },
+	{ 4, []byte("ftypheic"), "image/heic" },
+	{ 4, []byte("ftypqt  "), "video/quicktime" },
+}
+
+// Sniff returns the content type of a file from its first SniffLength bytes, "application/octet-stream" when unknown.
+func Sniff(head []byte) string {
+	if len(head) == 0 { return "application/octet-stream" }
+	if len(head) > SniffLength { head = head[:SniffLength] }
+
+	for _, signature := range magic {
+		if len(head) >= signature.offset + len(signature.prefix) && bytes.Equal(head[signature.offset:signature.offset + len(signature.prefix)], signature.prefix) {
+			return signature.mime
+		}
+	}
+
+	Mime, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if (Mime == "text/xml" || Mime == "text/plain") && bytes.Contains(bytes.ToLower(head), []byte("<svg")) { return "image/svg+xml" }
+	return Mime
+}
+
+// Detect sniffs the content of a file and checks it matches the extension, the client's word isn't taken for it.
+//
+// Returns:
+//   - The file's content type: the sniffed one, or the extension's when the sniffed one is a container
+//     (a docx sniffs as a zip) or unknown.
+//   - ErrContent, wrapped with details, when the content is another kind of file.
+//
+// Notes:
+//   - Extensions without a known signature only have to agree with the sniffed major type ("image/", "video/", ...).
+//   - Content which can't be sniffed (application/octet-stream) passes, there's nothing to compare.
+func Detect(Extension string, head []byte) (string, error) {
+	Extension = Normalize(Extension)
+	Sniffed := Sniff(head)
+	Declared, _, _ := mime.ParseMediaType(mime.TypeByExtension("." + Extension))
+
+	if Sniffed == "application/octet-stream" {
+		if Declared != "" { return Declared, nil }
+		return Sniffed, nil
+	}
+
+	if Accepted, known := signatures[Extension]; known {
+		if !contains(Accepted, Sniffed) { return "", fmt.Errorf("%w: .%s is %s", ErrContent, Extension, Sniffed) }
+	} else if Declared != "" && major(Declared) != major(Sniffed) {
+		return "", fmt.Errorf("%w: .%s is %s", ErrContent, Extension, Sniffed)
+	}
+
+	/* A container or plain text tells less than the extension does */
+	if Declared != "" && (Sniffed == "application/zip" || Sniffed == "application/x-ole-storage" || Sniffed == "text/plain") { return Declared, nil }
+	return Sniffed, nil
+}
+
+func major(Mime string) string {
+	Major, _, _ := strings.Cut(Mime, "/")
+	return Major
+}
@@ -0,0 +1,32 @@
+package filetypes
+
+import (
+	"errors"
+	"mime"
+	"testing"
+)
+
+func FuzzDetect(f *testing.F) {
+	f.Add("jpg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF"))
+	f.Add("png", []byte("\x89PNG\r\n\x1a\n"))
+	f.Add("pdf", []byte("%PDF-1.7"))
+	f.Add("docx", []byte("PK\x03\x04"))
+	f.Add("svg", []byte("<?xml version=\"1.0\"?><svg xmlns=\"http://www.w3.org/2000/svg\"/>"))
+	f.Add("png", []byte("<html><script>alert(1)</script>"))
+	f.Add("", []byte{})
+
+	f.Fuzz(func(t *testing.T, Extension string, Head []byte) {
+		Mime, err := Detect(Extension, Head)
+		if err != nil {
+			if !errors.Is(err, ErrContent) { t.Fatalf("Detect(%q) failed with %v, not ErrContent", Extension, err) }
+			return
+		}
+
+		if _, _, err := mime.ParseMediaType(Mime); err != nil { t.Fatalf("Detect(%q) = %q: %v", Extension, Mime, err) }
+
+		/* Content of a known signature is only ever what the extension may be */
+		if Accepted, known := signatures[Normalize(Extension)]; known && Sniff(Head) != "application/octet-stream" && !contains(Accepted, Sniff(Head)) {
+			t.Fatalf("Detect(%q) accepted %s content", Extension, Sniff(Head))
+		}
+	})
+}
@@ -0,0 +1,242 @@
+// Package hooks calls external programs on application events, so the site can be extended without recompiling.
+//
+// Hooks are configured in the json file at globals.Env.HOOKS (see hooks.example.json), each one listening to an event
+// and either POSTing to a url or running a local command with the payload on its stdin.
+// The payload is signed with HMAC-SHA256 of globals.Env.HOOKS_SECRET, sent as the X-Yacco-Signature header,
+// or the YACCO_SIGNATURE environment variable of a command.
+//
+// A hook with "verdict" set is asked for a decision, it answers with json:
+//
+//   { "allow": false, "message": "Title is too long", "errors": { "Title": "max 80 characters" } }
+//
+// Any other hook is only notified, its response is ignored.
+//
+// Events about a file's content (e.g. "upload.scan") come with the file, see AskFile: a command gets its path as
+// the YACCO_FILE environment variable, a url gets a multipart/form-data POST of the signed json as "payload" and
+// the content as "file".
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"main/server/common/globals"
+	"main/server/common/module"
+)
+
+const DefaultTimeout = 10 * time.Second
+
+type Hook struct {
+	Event		string		`json:"event"`
+	URL			string		`json:"url"`
+	Command		[]string	`json:"command"`
+	Verdict		bool		`json:"verdict"`
+	Timeout		string		`json:"timeout"`
+}
+
+// Verdict is the answer of verdict hooks, a failing verdict hook denies.
+type Verdict struct {
+	Allow		bool				`json:"allow"`
+	Message		string				`json:"message"`
+	Errors		map[string]string	`json:"errors"`
+}
+
+// Keyed is a payload with a key identifying the occurrence of the event, sent beside it as "key". Events
+// dispatched through the outbox are, a receiver seeing a key again was notified of the event already.
+type Keyed interface {
+	Key() string
+}
+
+type envelope struct {
+	Event		string		`json:"event"`
+	Time		time.Time	`json:"time"`
+	Key			string		`json:"key,omitempty"`
+	Payload		any			`json:"payload"`
+}
+
+var (
+	mu sync.RWMutex
+	configured []Hook
+)
+
+// Load reads the hooks configuration, a missing file means there are no hooks.
+func Load(path string) error {
+	var Hooks []Hook
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) { data, err = []byte("[]"), nil }
+	if err != nil { return err }
+	if err := json.Unmarshal(data, &Hooks); err != nil { return fmt.Errorf("%s: %w", path, err) }
+
+	for _, Hook := range Hooks {
+		if Hook.Event == "" || (Hook.URL == "") == (len(Hook.Command) == 0) {
+			return fmt.Errorf("%s: every hook needs an event and either a url or a command", path)
+		}
+	}
+
+	mu.Lock()
+	configured = Hooks
+	mu.Unlock()
+	return nil
+}
+
+// Setup loads the hooks configured in globals.Env and subscribes notification hooks to the container's events.
+func Setup(container *module.Container) {
+	if err := Load(globals.Env.HOOKS); err != nil {
+		log.Print("Loading hooks: ", err)
+		return
+	}
+
+	subscribed := map[string]bool{}
+	for _, Hook := range hooks("") {
+		if Hook.Verdict || subscribed[Hook.Event] { continue }
+		subscribed[Hook.Event] = true
+
+		Event := Hook.Event
+		container.On(Event, func(ctx context.Context, payload any) error {
+			Notify(ctx, Event, payload)
+			return nil
+		})
+	}
+}
+
+// Notify calls the event's notification hooks in the background.
+func Notify(ctx context.Context, Event string, payload any) {
+	for _, Hook := range hooks(Event) {
+		if Hook.Verdict { continue }
+
+		go func() {
+			if _, err := call(context.WithoutCancel(ctx), Hook, payload, ""); err != nil { log.Print("Hook ", Hook.Event, ": ", err) }
+		}()
+	}
+}
+
+// Has reports whether any hook listens to the event.
+func Has(Event string) bool {
+	return len(hooks(Event)) > 0
+}
+
+// Ask calls the event's verdict hooks in order and returns the first denial.
+// With no verdict hooks configured the answer is allow.
+func Ask(ctx context.Context, Event string, payload any) Verdict {
+	return AskFile(ctx, Event, payload, "")
+}
+
+// AskFile is Ask for an event about the content of the local file at Path, which the hooks get along with the
+// payload (see the package's documentation). The payload should carry the content's sha256, so url hooks can tell
+// the file they got is the one the signed payload is about.
+//
+// Example usage:
+//   Verdict := hooks.AskFile(ctx, "upload.scan", map[string]any{ "name": Name, "sha256": Hash }, Spool.File.Name())
+func AskFile(ctx context.Context, Event string, payload any, Path string) Verdict {
+	for _, Hook := range hooks(Event) {
+		if !Hook.Verdict { continue }
+
+		body, err := call(ctx, Hook, payload, Path)
+		if err != nil {
+			log.Print("Hook ", Hook.Event, ": ", err)
+			return Verdict{ Allow: false, Message: "Validation hook failed" }
+		}
+
+		var Answer Verdict
+		if err := json.Unmarshal(body, &Answer); err != nil {
+			log.Print("Hook ", Hook.Event, " answered with invalid json: ", err)
+			return Verdict{ Allow: false, Message: "Validation hook failed" }
+		}
+		if !Answer.Allow { return Answer }
+	}
+	return Verdict{ Allow: true }
+}
+
+// Sign returns the signature of a payload, receivers compare it with the X-Yacco-Signature header.
+func Sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(globals.Env.HOOKS_SECRET))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func hooks(Event string) []Hook {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var matched []Hook
+	for _, Hook := range configured {
+		if Event == "" || Hook.Event == Event { matched = append(matched, Hook) }
+	}
+	return matched
+}
+
+/* Path is the file the event is about, "" for none */
+func call(ctx context.Context, Hook Hook, payload any, Path string) ([]byte, error) {
+	Envelope := envelope{ Event: Hook.Event, Time: time.Now(), Payload: payload }
+	if Keyed, ok := payload.(Keyed); ok { Envelope.Key = Keyed.Key() }
+
+	body, err := json.Marshal(Envelope)
+	if err != nil { return nil, err }
+
+	timeout, err := time.ParseDuration(Hook.Timeout)
+	if err != nil { timeout = DefaultTimeout }
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if len(Hook.Command) > 0 {
+		command := exec.CommandContext(ctx, Hook.Command[0], Hook.Command[1:]...)
+		command.Stdin = bytes.NewReader(body)
+		command.Env = append(os.Environ(), "YACCO_EVENT=" + Hook.Event, "YACCO_SIGNATURE=" + Sign(body))
+		if Path != "" { command.Env = append(command.Env, "YACCO_FILE=" + Path) }
+		return command.Output()
+	}
+
+	var content io.Reader = bytes.NewReader(body)
+	ContentType := "application/json"
+	if Path != "" {
+		File, err := os.Open(Path)
+		if err != nil { return nil, err }
+		defer File.Close()
+		content, ContentType = upload(body, File)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, Hook.URL, content)
+	if err != nil { return nil, err }
+	request.Header.Set("Content-Type", ContentType)
+	request.Header.Set("X-Yacco-Event", Hook.Event)
+	request.Header.Set("X-Yacco-Signature", Sign(body))
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil { return nil, err }
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 { return nil, fmt.Errorf("%s responded with %s", Hook.URL, response.Status) }
+	return io.ReadAll(io.LimitReader(response.Body, 1 << 20))
+}
+
+/* The payload and the file as a multipart form, streamed: the file is never held in memory */
+func upload(body []byte, File *os.File) (io.Reader, string) {
+	reader, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		err := form.WriteField("payload", string(body))
+		if err == nil {
+			var part io.Writer
+			if part, err = form.CreateFormFile("file", "upload"); err == nil { _, err = io.Copy(part, File) }
+		}
+		if err == nil { err = form.Close() }
+		writer.CloseWithError(err)
+	}()
+	return reader, form.FormDataContentType()
+}
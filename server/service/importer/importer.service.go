@@ -0,0 +1,307 @@
+// Package importer imports spreadsheets (CSV, XLSX) into models, the counterpart of package exporter. An ImportSpec
+// tells how the columns of a model are read from cells; an uploaded spreadsheet is staged (see Stage), its columns
+// mapped to the spec's (Guess, then as the admin corrects it), its rows previewed with their problems, and then
+// imported by a background task (see package tasks) in batches of BatchSize rows, each in a transaction. Rows which
+// can't be imported are skipped and listed, with why, in an error report the task links to.
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/build/view"
+	"main/server/common/blob"
+	"main/server/common/domain"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/exporter"
+	"main/server/service/notifications"
+	"main/server/service/tasks"
+)
+
+// Limits of an imported spreadsheet.
+const (
+	MaxSize = 20 << 20
+	MaxRows = 100000
+	MaxColumns = 64
+)
+
+// BatchSize is how many rows are saved in one transaction, progress is reported once per batch.
+const BatchSize = 500
+
+// PreviewRows is how many rows the preview shows.
+const PreviewRows = 20
+
+// Kind is the tasks' kind of imports.
+const Kind = "import"
+
+var (
+	ErrFormat = domain.Invalid("the file isn't a readable CSV or XLSX spreadsheet")
+	ErrEmpty = domain.Invalid("the spreadsheet has no header row")
+	ErrTooLarge = domain.Invalid(fmt.Sprintf("spreadsheets are imported up to %dMB, %d rows and %d columns", MaxSize >> 20, MaxRows, MaxColumns))
+	ErrNotStaged = domain.NotFound("the spreadsheet to import wasn't found, upload it again")
+	ErrUnmapped = domain.Invalid("a required column isn't mapped")
+)
+
+/* Excel reads CSV files without it as ANSI, Georgian text would be garbled */
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// Column is a column of the imported model: Set reads a cell into the record, its error is the row's problem.
+// A Required column must be mapped and its cells can't be empty, empty cells of others aren't Set.
+type Column[T any] struct {
+	Name		string				/* matched against the spreadsheet's header, like the exported one */
+	Label		string
+	Required	bool
+	Set			func(Record *T, Cell string) error
+}
+
+// ImportSpec is what a spreadsheet is imported into.
+//
+// Example usage:
+//   Spec := importer.ImportSpec[model.Faq]{
+//       Name: "faq",
+//       Title: "კითხვების იმპორტი",
+//       Columns: []importer.Column[model.Faq]{
+//           { Name: "question", Label: "კითხვა", Required: true, Set: func(Faq *model.Faq, Cell string) error { Faq.Question = Cell; return nil } },
+//       },
+//   }
+//
+// Notes:
+//   - New, when given, makes the record cells are Set on, with its defaults.
+//   - Validate, when given, checks a record once its cells are Set.
+//   - Save, when given, saves a batch of valid records instead of creating them, to update existing ones say.
+type ImportSpec[T any] struct {
+	Name		string				/* of the error report, without date nor extension */
+	Title		string				/* of the task */
+	Columns		[]Column[T]
+	New			func() T
+	Validate	func(Record *T) error
+	Save		func(tx *gorm.DB, Batch []T) error
+}
+
+// Mapping maps the spec's columns, by Name, to the index of the spreadsheet's column they're read from.
+// Unmapped ones aren't in it.
+type Mapping map[string]int
+
+// Issue is a problem of a row, Row is its number in the spreadsheet (the header is row 1).
+type Issue struct {
+	Row			int
+	Column		string
+	Message		string
+}
+
+// MappingField is the name of the form field mapping the column, its value is the spreadsheet column's index.
+func MappingField(Column string) string {
+	return "map-" + Column
+}
+
+// Guess maps the columns whose Name or Label is a header of the spreadsheet, case aside.
+func Guess[T any](Spec ImportSpec[T], Header []string) Mapping {
+	Mapped := Mapping{}
+	for _, Column := range Spec.Columns {
+		for Index, Name := range Header {
+			if strings.EqualFold(Name, Column.Name) || strings.EqualFold(Name, Column.Label) {
+				Mapped[Column.Name] = Index
+				break
+			}
+		}
+	}
+	return Mapped
+}
+
+// Mapped reads a mapping from the form fields of MappingField, value returns a field's value.
+// Indexes out of the header are left out.
+func Mapped[T any](Spec ImportSpec[T], Header []string, value func(Field string) string) Mapping {
+	Mapped := Mapping{}
+	for _, Column := range Spec.Columns {
+		Index, err := strconv.Atoi(value(MappingField(Column.Name)))
+		if err == nil && Index >= 0 && Index < len(Header) { Mapped[Column.Name] = Index }
+	}
+	return Mapped
+}
+
+// Check returns ErrUnmapped, wrapped with their labels, when required columns aren't mapped.
+func Check[T any](Spec ImportSpec[T], Mapped Mapping) error {
+	Missing := []string{}
+	for _, Column := range Spec.Columns {
+		if _, ok := Mapped[Column.Name]; Column.Required && !ok { Missing = append(Missing, Column.Label) }
+	}
+	if len(Missing) > 0 { return fmt.Errorf("%w: %s", ErrUnmapped, strings.Join(Missing, ", ")) }
+	return nil
+}
+
+// Parse reads a row (Number is its row in the spreadsheet) into a record, with its issues. Blank rows have neither.
+func Parse[T any](Spec ImportSpec[T], Mapped Mapping, Number int, Row []string) (T, []Issue) {
+	var Record T
+	if blank(Row) { return Record, nil }
+	if Spec.New != nil { Record = Spec.New() }
+
+	Issues := []Issue{}
+	for _, Column := range Spec.Columns {
+		Index, ok := Mapped[Column.Name]
+		if !ok { continue }
+
+		Cell := strings.TrimSpace(Row[Index])
+		if Cell == "" {
+			if Column.Required { Issues = append(Issues, Issue{ Row: Number, Column: Column.Label, Message: "ცარიელია" }) }
+			continue
+		}
+		if err := Column.Set(&Record, Cell); err != nil { Issues = append(Issues, Issue{ Row: Number, Column: Column.Label, Message: err.Error() }) }
+	}
+	if len(Issues) == 0 && Spec.Validate != nil {
+		if err := Spec.Validate(&Record); err != nil { Issues = append(Issues, Issue{ Row: Number, Message: err.Error() }) }
+	}
+	return Record, Issues
+}
+
+// Preview is the import page of a staged spreadsheet: the mapping, the first PreviewRows rows as they'd be read and
+// how many of all of them can't be, with the first issues. Action is the url of the import routes. Blank rows
+// aren't counted.
+func Preview[T any](Spec ImportSpec[T], Action string, FileID uint, Original string, Spreadsheet Sheet, Mapped Mapping) view.ImportPage {
+	Page := view.ImportPage{ Action: strings.TrimSuffix(Action, "/"), FileID: FileID, Original: Original, Header: Spreadsheet.Header, Mapping: map[string]int{} }
+	for _, Column := range Spec.Columns {
+		Page.Columns = append(Page.Columns, view.ImportColumn{ Name: Column.Name, Field: MappingField(Column.Name), Label: Column.Label, Required: Column.Required })
+	}
+	for Name, Index := range Mapped { Page.Mapping[Name] = Index }
+	if err := Check(Spec, Mapped); err != nil { Page.Error = err.Error() }
+
+	for Index, Row := range Spreadsheet.Rows {
+		if blank(Row) { continue }
+		Page.Total++
+
+		Number := Index + 2
+		_, Issues := Parse(Spec, Mapped, Number, Row)
+		if len(Issues) > 0 { Page.Invalid++ }
+		if len(Page.Issues) < PreviewRows { Page.Issues = append(Page.Issues, messages(Issues)...) }
+		if len(Page.Rows) >= PreviewRows { continue }
+
+		Cells := []string{}
+		for _, Column := range Spec.Columns {
+			if Index, ok := Mapped[Column.Name]; ok { Cells = append(Cells, Row[Index]) } else { Cells = append(Cells, "") }
+		}
+		Page.Rows = append(Page.Rows, view.ImportRow{ Number: Number, Cells: Cells, Invalid: len(Issues) > 0 })
+	}
+	return Page
+}
+
+// Start imports the staged spreadsheet in a background task of the user. The staged file is removed once it's
+// done, the user is notified of how many rows were imported and, with rows which weren't, the task's file is
+// the error report.
+//
+// Returns:
+//   - ErrNotStaged, ErrUnmapped before starting anything.
+func Start[T any](User model.Users, Spec ImportSpec[T], FileID uint, Mapped Mapping) (model.Tasks, error) {
+	Spreadsheet, _, err := Open(context.Background(), User.ID, FileID)
+	if err != nil { return model.Tasks{}, err }
+	if err := Check(Spec, Mapped); err != nil { return model.Tasks{}, err }
+
+	Imported := 0
+	return tasks.Run(User.ID, Kind, Spec.Title, len(Spreadsheet.Rows), func(ctx context.Context, progress func(int)) (*uint, error) {
+		defer func() {
+			if err := Discard(context.WithoutCancel(ctx), User.ID, FileID); err != nil { log.Print("Removing staged import ", FileID, ": ", err) }
+		}()
+
+		Issues := []Issue{}
+		for From := 0; From < len(Spreadsheet.Rows); From += BatchSize {
+			if err := ctx.Err(); err != nil { return nil, err }
+
+			To := min(From + BatchSize, len(Spreadsheet.Rows))
+			Batch := []T{}
+			Numbers := []int{}
+			for Index := From; Index < To; Index++ {
+				if blank(Spreadsheet.Rows[Index]) { continue }
+				Record, Problems := Parse(Spec, Mapped, Index + 2, Spreadsheet.Rows[Index])
+				if len(Problems) > 0 {
+					Issues = append(Issues, Problems...)
+					continue
+				}
+				Batch = append(Batch, Record)
+				Numbers = append(Numbers, Index + 2)
+			}
+
+			if err := save(ctx, Spec, Batch); err != nil {
+				for _, Number := range Numbers { Issues = append(Issues, Issue{ Row: Number, Message: err.Error() }) }
+			} else {
+				Imported += len(Batch)
+			}
+			progress(To)
+		}
+
+		if len(Issues) == 0 { return nil, nil }
+		return report(ctx, User, Spec.Name, Spreadsheet, Issues)
+	}, func(Task model.Tasks) { finished(User, Task, Imported) })
+}
+
+/* A batch is saved whole or not at all, a failed one is reported row by row with the database's error */
+func save[T any](ctx context.Context, Spec ImportSpec[T], Batch []T) error {
+	if len(Batch) == 0 { return nil }
+	return storage.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if Spec.Save != nil { return Spec.Save(tx, Batch) }
+		return tx.Create(&Batch).Error
+	})
+}
+
+/* The issues, then the cells of their rows, so the report can be fixed and imported again after removing the first columns */
+func report(ctx context.Context, User model.Users, Name string, Spreadsheet Sheet, Issues []Issue) (*uint, error) {
+	Temporary, err := os.CreateTemp("", "import-*.csv")
+	if err != nil { return nil, err }
+	defer os.Remove(Temporary.Name())
+	defer Temporary.Close()
+
+	if _, err := Temporary.Write(bom); err != nil { return nil, err }
+	Writer := csv.NewWriter(Temporary)
+	/* The cells are the uploader's, a spreadsheet app mustn't run them as formulas */
+	Writer.Write(exporter.Escape(append([]string{ "row", "column", "error" }, Spreadsheet.Header...)))
+	for _, Problem := range Issues {
+		Writer.Write(exporter.Escape(append([]string{ strconv.Itoa(Problem.Row), Problem.Column, Problem.Message }, Spreadsheet.Rows[Problem.Row - 2]...)))
+	}
+	Writer.Flush()
+	if err := Writer.Error(); err != nil { return nil, err }
+
+	Info, err := Temporary.Stat()
+	if err != nil { return nil, err }
+	if _, err := Temporary.Seek(0, 0); err != nil { return nil, err }
+
+	FileName := Name + "-errors-" + time.Now().Format("20060102-150405") + ".csv"
+	Location := folder(User.ID) + "reports/"
+	if err := blob.Default().Put(ctx, blob.Key(Location + FileName), Temporary, Info.Size(), "text/csv"); err != nil { return nil, err }
+
+	File := model.Files{
+		Name: FileName,
+		Original: FileName,
+		Location: Location,
+		Path: Location + FileName,
+		Size: int(Info.Size()),
+		Mime: "text/csv",
+		Visibility: model.VisibilityAdmin,
+	}
+	if err := storage.DB.WithContext(ctx).Create(&File).Error; err != nil { return nil, err }
+	return &File.ID, nil
+}
+
+func finished(User model.Users, Task model.Tasks, Imported int) {
+	Title, Body := "იმპორტი დასრულდა", Task.Title + ": " + strconv.Itoa(Imported) + " ჩანაწერი დაემატა"
+	switch {
+		case Task.State != model.TaskDone: Title = "იმპორტი ვერ მოხერხდა"
+		case Task.FileID != nil: Body += ", დანარჩენების შეცდომები ფაილშია"
+	}
+	if err := notifications.Notify(User.ID, notifications.CategoryTasks, Title, Body, "/admin/tasks"); err != nil { log.Print("Notifying import: ", err) }
+}
+
+func messages(Issues []Issue) []string {
+	Messages := []string{}
+	for _, Problem := range Issues {
+		Message := "რიგი " + strconv.Itoa(Problem.Row) + ": "
+		if Problem.Column != "" { Message += Problem.Column + " - " }
+		Messages = append(Messages, Message + Problem.Message)
+	}
+	return Messages
+}
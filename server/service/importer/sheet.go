@@ -0,0 +1,237 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Sheet is a spreadsheet as read: its first row is the Header, the others are Rows, every row is as long as Header.
+type Sheet struct {
+	Header		[]string
+	Rows		[][]string
+}
+
+/* A worksheet's xml may be far larger than its zip entry, it's read up to this much */
+const maxXML = 256 << 20
+
+// Read reads a CSV or the first worksheet of an XLSX file, told apart by the name's extension. Cells are read as
+// text: XLSX numbers as stored, dates as Excel's serial numbers.
+//
+// Returns:
+//   - ErrFormat for other files or ones which can't be read as theirs.
+//   - ErrEmpty without a header row, ErrTooLarge past MaxRows rows or MaxColumns columns.
+func Read(Name string, r io.ReaderAt, Size int64) (Sheet, error) {
+	var Records [][]string
+	var err error
+	switch strings.ToLower(path.Ext(Name)) {
+		case ".csv": Records, err = readCSV(io.NewSectionReader(r, 0, Size))
+		case ".xlsx": Records, err = readXLSX(r, Size)
+		default: return Sheet{}, fmt.Errorf("%w: %s", ErrFormat, Name)
+	}
+	if err != nil { return Sheet{}, err }
+	return sheet(Records)
+}
+
+/* Rows are padded or cut to the header, trailing empty rows (spreadsheets keep formatted ones) are dropped */
+func sheet(Records [][]string) (Sheet, error) {
+	for len(Records) > 0 && blank(Records[len(Records) - 1]) { Records = Records[:len(Records) - 1] }
+	if len(Records) == 0 { return Sheet{}, ErrEmpty }
+	if len(Records) - 1 > MaxRows || len(Records[0]) > MaxColumns { return Sheet{}, ErrTooLarge }
+
+	Header := Records[0]
+	for i := range Header { Header[i] = strings.TrimSpace(Header[i]) }
+	Rows := make([][]string, 0, len(Records) - 1)
+	for _, Record := range Records[1:] {
+		Row := make([]string, len(Header))
+		copy(Row, Record)
+		Rows = append(Rows, Row)
+	}
+	return Sheet{ Header: Header, Rows: Rows }, nil
+}
+
+func blank(Row []string) bool {
+	for _, Cell := range Row {
+		if strings.TrimSpace(Cell) != "" { return false }
+	}
+	return true
+}
+
+/* Excel writes a BOM and, in some locales, separates with semicolons: the separator is the header's */
+func readCSV(r io.Reader) ([][]string, error) {
+	Content, err := io.ReadAll(r)
+	if err != nil { return nil, err }
+	Content = bytes.TrimPrefix(Content, bom)
+
+	Reader := csv.NewReader(bytes.NewReader(Content))
+	Line, _, _ := bytes.Cut(Content, []byte("\n"))
+	if bytes.Count(Line, []byte(";")) > bytes.Count(Line, []byte(",")) { Reader.Comma = ';' }
+	Reader.FieldsPerRecord = -1
+	Reader.LazyQuotes = true
+
+	Records, err := Reader.ReadAll()
+	if err != nil { return nil, fmt.Errorf("%w: %v", ErrFormat, err) }
+	return Records, nil
+}
+
+/* The parts of an XLSX package read: the workbook's first sheet, its relations and the shared strings */
+type xlsxWorkbook struct {
+	Sheets []struct {
+		ID		string		`xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelations struct {
+	Relations []struct {
+		ID			string		`xml:"Id,attr"`
+		Target		string		`xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+/* A shared string is plain (<t>) or rich text of runs (<r><t>) */
+type xlsxString struct {
+	Text		string		`xml:"t"`
+	Runs		[]string	`xml:"r>t"`
+}
+
+type xlsxCell struct {
+	Ref			string		`xml:"r,attr"`
+	Type		string		`xml:"t,attr"`
+	Value		string		`xml:"v"`
+	Inline		xlsxString	`xml:"is"`
+}
+
+func readXLSX(r io.ReaderAt, Size int64) ([][]string, error) {
+	Archive, err := zip.NewReader(r, Size)
+	if err != nil { return nil, fmt.Errorf("%w: %v", ErrFormat, err) }
+
+	Part, err := firstSheet(Archive)
+	if err != nil { return nil, err }
+
+	var Strings []string
+	if err := decode(Archive, "xl/sharedStrings.xml", func(Decoder *xml.Decoder, Start xml.StartElement) error {
+		if Start.Name.Local != "si" { return nil }
+		var String xlsxString
+		if err := Decoder.DecodeElement(&String, &Start); err != nil { return err }
+		Strings = append(Strings, String.text())
+		return nil
+	}); err != nil && err != errMissing { return nil, err }
+
+	Records := [][]string{}
+	var Row []string
+	err = decode(Archive, Part, func(Decoder *xml.Decoder, Start xml.StartElement) error {
+		switch Start.Name.Local {
+			case "row":
+				if Row != nil { Records = append(Records, Row) }
+				if len(Records) > MaxRows { return ErrTooLarge }
+				Row = []string{}
+
+				/* Empty rows aren't written, the row numbers reported stay the spreadsheet's */
+				for _, Attr := range Start.Attr {
+					Number, err := strconv.Atoi(Attr.Value)
+					if Attr.Name.Local != "r" || err != nil || Number > MaxRows + 1 { continue }
+					for len(Records) < Number - 1 { Records = append(Records, []string{}) }
+				}
+			case "c":
+				var Cell xlsxCell
+				if err := Decoder.DecodeElement(&Cell, &Start); err != nil { return err }
+				Column := column(Cell.Ref, len(Row))
+				/* A cell reference like XFD1048576 mustn't allocate a row that long */
+				if Column >= MaxColumns { return ErrTooLarge }
+				for len(Row) <= Column { Row = append(Row, "") }
+				Row[Column] = Cell.text(Strings)
+		}
+		return nil
+	})
+	if err != nil { return nil, err }
+	if Row != nil { Records = append(Records, Row) }
+	return Records, nil
+}
+
+/* The first sheet of the workbook, Excel names it sheet1.xml but other writers needn't */
+func firstSheet(Archive *zip.Reader) (string, error) {
+	var Workbook xlsxWorkbook
+	var Relations xlsxRelations
+	if err := unmarshal(Archive, "xl/workbook.xml", &Workbook); err != nil { return "", err }
+	if err := unmarshal(Archive, "xl/_rels/workbook.xml.rels", &Relations); err != nil { return "", err }
+	if len(Workbook.Sheets) == 0 { return "", ErrEmpty }
+
+	for _, Relation := range Relations.Relations {
+		if Relation.ID != Workbook.Sheets[0].ID { continue }
+		if strings.HasPrefix(Relation.Target, "/") { return strings.TrimPrefix(Relation.Target, "/"), nil }
+		return path.Join("xl", Relation.Target), nil
+	}
+	return "", fmt.Errorf("%w: the first sheet isn't in the package", ErrFormat)
+}
+
+var errMissing = fmt.Errorf("%w: a part of the package is missing", ErrFormat)
+
+func open(Archive *zip.Reader, Name string) (io.ReadCloser, error) {
+	for _, File := range Archive.File {
+		if File.Name == Name { return File.Open() }
+	}
+	return nil, errMissing
+}
+
+func unmarshal(Archive *zip.Reader, Name string, v any) error {
+	Part, err := open(Archive, Name)
+	if err != nil { return err }
+	defer Part.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(Part, maxXML)).Decode(v); err != nil { return fmt.Errorf("%w: %s: %v", ErrFormat, Name, err) }
+	return nil
+}
+
+/* Parts are decoded as a stream of elements, a worksheet isn't unmarshaled whole */
+func decode(Archive *zip.Reader, Name string, element func(*xml.Decoder, xml.StartElement) error) error {
+	Part, err := open(Archive, Name)
+	if err != nil { return err }
+	defer Part.Close()
+
+	Decoder := xml.NewDecoder(io.LimitReader(Part, maxXML))
+	for {
+		Token, err := Decoder.Token()
+		if err == io.EOF { return nil }
+		if err != nil { return fmt.Errorf("%w: %s: %v", ErrFormat, Name, err) }
+		if Start, ok := Token.(xml.StartElement); ok {
+			if err := element(Decoder, Start); err != nil { return err }
+		}
+	}
+}
+
+/* The column of a cell reference, "C7" is 2. Cells may leave it out, they're then the next one */
+func column(Ref string, Next int) int {
+	Column := 0
+	for _, r := range Ref {
+		if r < 'A' || r > 'Z' { break }
+		Column = Column * 26 + int(r - 'A') + 1
+		if Column > MaxColumns + 1 { break }
+	}
+	if Column == 0 { return Next }
+	return Column - 1
+}
+
+func (String xlsxString) text() string {
+	if len(String.Runs) > 0 { return strings.Join(String.Runs, "") }
+	return String.Text
+}
+
+func (Cell xlsxCell) text(Strings []string) string {
+	switch Cell.Type {
+		case "s":
+			Index, err := strconv.Atoi(Cell.Value)
+			if err != nil || Index < 0 || Index >= len(Strings) { return "" }
+			return Strings[Index]
+		case "inlineStr": return Cell.Inline.text()
+		case "b":
+			if Cell.Value == "1" { return "true" }
+			return "false"
+	}
+	return Cell.Value
+}
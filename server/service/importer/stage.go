@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/server/common/blob"
+	"main/server/common/globals"
+	uploader "main/server/common/helpers"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+/*
+	An uploaded spreadsheet is staged between its preview and its import: stored as an admin-only upload under
+	imports/<user>/, so the instance committing it needn't be the one which received it and ./public doesn't serve it
+	(see middleware.PrivateUploads). It's removed once imported, the ones left behind by an abandoned preview when
+	their user stages another.
+*/
+
+// StageExpiry is how long a staged spreadsheet is kept without being imported.
+const StageExpiry = 24 * time.Hour
+
+// Stage stores an uploaded spreadsheet of the user and reads it, the Files ID returned is what Open takes.
+//
+// Returns:
+//   - ErrTooLarge past MaxSize, the errors of Read for files which aren't spreadsheets.
+func Stage(ctx context.Context, UserID uint, Name string, r io.Reader) (uint, Sheet, error) {
+	Content, err := io.ReadAll(io.LimitReader(r, MaxSize + 1))
+	if err != nil { return 0, Sheet{}, err }
+	if len(Content) > MaxSize { return 0, Sheet{}, ErrTooLarge }
+
+	Spreadsheet, err := Read(Name, bytes.NewReader(Content), int64(len(Content)))
+	if err != nil { return 0, Sheet{}, err }
+
+	expire(ctx, UserID)
+
+	random := make([]byte, 16)
+	rand.Read(random)
+	Ext := strings.ToLower(path.Ext(Name))
+	Path := folder(UserID) + hex.EncodeToString(random) + Ext
+	Mime := "text/csv"
+	if Ext == ".xlsx" { Mime = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" }
+
+	if err := blob.Default().Put(ctx, blob.Key(Path), bytes.NewReader(Content), int64(len(Content)), Mime); err != nil { return 0, Sheet{}, err }
+
+	File := model.Files{
+		Name: path.Base(Path),
+		Original: Name,
+		Location: folder(UserID),
+		Path: Path,
+		Size: len(Content),
+		Mime: Mime,
+		Visibility: model.VisibilityAdmin,
+	}
+	if err := storage.DB.WithContext(ctx).Create(&File).Error; err != nil { return 0, Sheet{}, err }
+	return File.ID, Spreadsheet, nil
+}
+
+// Open reads a spreadsheet the user staged, with the name it was uploaded as. ErrNotStaged when it isn't one (anymore).
+func Open(ctx context.Context, UserID uint, FileID uint) (Sheet, string, error) {
+	var File model.Files
+	if err := storage.DB.WithContext(ctx).Where("id = ? AND location = ?", FileID, folder(UserID)).First(&File).Error; err != nil { return Sheet{}, "", ErrNotStaged }
+
+	reader, _, err := blob.Default().Open(ctx, blob.Key(File.Path))
+	if errors.Is(err, blob.ErrNotFound) { return Sheet{}, "", ErrNotStaged }
+	if err != nil { return Sheet{}, "", err }
+	defer reader.Close()
+
+	Content, err := io.ReadAll(io.LimitReader(reader, MaxSize + 1))
+	if err != nil { return Sheet{}, "", err }
+	Spreadsheet, err := Read(File.Path, bytes.NewReader(Content), int64(len(Content)))
+	return Spreadsheet, File.Original, err
+}
+
+// Discard removes a staged spreadsheet of the user.
+func Discard(ctx context.Context, UserID uint, FileID uint) error {
+	var File model.Files
+	if err := storage.DB.WithContext(ctx).Where("id = ? AND location = ?", FileID, folder(UserID)).First(&File).Error; err != nil { return ErrNotStaged }
+	return uploader.Remove(ctx, File.ID)
+}
+
+func folder(UserID uint) string {
+	return globals.Env.Uploads + "imports/" + strconv.Itoa(int(UserID)) + "/"
+}
+
+func expire(ctx context.Context, UserID uint) {
+	var Files []model.Files
+	storage.DB.WithContext(ctx).Where("location = ? AND created_at < ?", folder(UserID), time.Now().Add(-StageExpiry)).Find(&Files)
+	for _, File := range Files {
+		if err := uploader.Remove(ctx, File.ID); err != nil { log.Print("Removing staged import ", File.ID, ": ", err) }
+	}
+}
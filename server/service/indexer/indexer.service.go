@@ -0,0 +1,154 @@
+// Package indexer decides how search engines index the site's pages, from the rules the admin sets (Seo_rules):
+// a page rule applies to its path alone, a section rule to every page under its path ("/news" covers "/news/12").
+// middleware.SEO puts the resolved directives on the request context and the X-Robots-Tag header, the SEO templ
+// component emits them, and the sitemap leaves out the pages which aren't to be indexed under their own url.
+//
+// Rules are few and read on every page, they're kept in memory: reloaded after a change and every ReloadEvery,
+// so the other instances pick the change up too.
+package indexer
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"main/server/common/domain"
+	"main/server/common/globals"
+	"main/server/common/module"
+	"main/server/common/seo"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// ReloadEvery is how long a change made on another instance takes to apply.
+const ReloadEvery = time.Minute
+
+var (
+	ErrNotFound = domain.NotFound("indexing rule not found")
+	ErrPath = domain.Invalid("path must start with /")
+	ErrCanonical = domain.Invalid("canonical must be a path or an http(s) url")
+)
+
+/* Longest path first, the first section rule covering a page is the closest one */
+var rules atomic.Pointer[[]model.Seo_rules]
+
+// Setup declares the job reloading the rules.
+func Setup(container *module.Container) {
+	container.Cron("indexer.reload", ReloadEvery, func(ctx context.Context) error {
+		return Reload(storage.DB.WithContext(ctx))
+	})
+}
+
+// Reload reads the rules again.
+func Reload(db *gorm.DB) error {
+	var Rows []model.Seo_rules
+	if err := db.Find(&Rows).Error; err != nil { return err }
+	sort.SliceStable(Rows, func(i, j int) bool { return len(Rows[i].Path) > len(Rows[j].Path) })
+	rules.Store(&Rows)
+	return nil
+}
+
+func loaded() []model.Seo_rules {
+	if Rows := rules.Load(); Rows != nil { return *Rows }
+	if err := Reload(storage.DB); err != nil { return nil }
+	return *rules.Load()
+}
+
+// Resolve returns the directives of the page at Path: those of its page rule, of the closest section covering it
+// otherwise. Its canonical url is its own (APP_URL + Path) unless the rule overrides it.
+//
+// Example usage:
+//   Page := indexer.Resolve("/news/12")
+//   ctx.Response().Header().Set("X-Robots-Tag", Page.Robots())
+func Resolve(Path string) seo.Directives {
+	Path = normalize(Path)
+	Page := seo.Directives{}
+	if globals.Env.APP_URL != "" { Page.Canonical = globals.Env.APP_URL + Path }
+
+	Rule, found := match(Path)
+	if !found { return Page }
+
+	Page.NoIndex, Page.NoFollow = Rule.NoIndex, Rule.NoFollow
+	if Rule.Canonical != "" { Page.Canonical = absolute(Rule.Canonical) }
+	return Page
+}
+
+// Listed reports whether the page at Path belongs in the sitemap: it's indexed and it's its own canonical url.
+func Listed(Path string) bool {
+	Page := Resolve(Path)
+	if Page.NoIndex { return false }
+	return Page.Canonical == "" || Page.Canonical == absolute(normalize(Path))
+}
+
+func match(Path string) (model.Seo_rules, bool) {
+	Rules := loaded()
+	for _, Rule := range Rules {
+		if !Rule.Section && Rule.Path == Path { return Rule, true }
+	}
+	for _, Rule := range Rules {
+		if Rule.Section && covers(Rule.Path, Path) { return Rule, true }
+	}
+	return model.Seo_rules{}, false
+}
+
+/* "/news" covers "/news" and "/news/12", not "/newsletter" */
+func covers(Section string, Path string) bool {
+	if Section == "/" || Section == Path { return true }
+	return strings.HasPrefix(Path, strings.TrimSuffix(Section, "/") + "/")
+}
+
+func normalize(Path string) string {
+	if Path != "/" { Path = strings.TrimSuffix(Path, "/") }
+	if Path == "" { Path = "/" }
+	return Path
+}
+
+func absolute(Canonical string) string {
+	if strings.HasPrefix(Canonical, "/") { return globals.Env.APP_URL + Canonical }
+	return Canonical
+}
+
+// List returns the rules, by path.
+func List() ([]model.Seo_rules, error) {
+	var Rows []model.Seo_rules
+	err := storage.DB.Order("path").Find(&Rows).Error
+	return Rows, err
+}
+
+// Save sets the rule of Path, replacing the one it had.
+//
+// Returns:
+//   - ErrPath for a path not starting with "/", ErrCanonical for a canonical which is neither a path nor an http(s) url.
+func Save(Rule model.Seo_rules) (model.Seo_rules, error) {
+	Rule.Path = normalize(strings.TrimSpace(Rule.Path))
+	Rule.Canonical = strings.TrimSpace(Rule.Canonical)
+	if !strings.HasPrefix(Rule.Path, "/") || strings.ContainsAny(Rule.Path, "?#") { return Rule, ErrPath }
+	if Rule.Canonical != "" && !strings.HasPrefix(Rule.Canonical, "/") {
+		Address, err := url.Parse(Rule.Canonical)
+		if err != nil || (Address.Scheme != "http" && Address.Scheme != "https") || Address.Host == "" { return Rule, ErrCanonical }
+	}
+
+	err := storage.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{ Name: model.Seo_rulesPath }},
+		DoUpdates: clause.AssignmentColumns([]string{
+			model.Seo_rulesSection, model.Seo_rulesNoIndex, model.Seo_rulesNoFollow, model.Seo_rulesCanonical, model.Seo_rulesNote, model.Seo_rulesUpdatedAt,
+		}),
+	}).Create(&Rule).Error
+	if err != nil { return Rule, err }
+	return Rule, Reload(storage.DB)
+}
+
+// Remove deletes a rule, its pages are indexed as any other again.
+func Remove(ID uint) error {
+	/* Deleted for good, the path's unique index would refuse a new rule for it otherwise */
+	Result := storage.DB.Unscoped().Delete(&model.Seo_rules{}, ID)
+	if Result.Error != nil { return Result.Error }
+	if Result.RowsAffected == 0 { return ErrNotFound }
+	return Reload(storage.DB)
+}
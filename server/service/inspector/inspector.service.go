@@ -0,0 +1,152 @@
+// Package inspector reads the metadata of uploads (model.File_metadata): the width and height of images, the page
+// count of PDFs, the duration, codec and dimensions of audio and video. Pages need the dimensions of an image
+// before it has loaded, to reserve its place instead of shifting the layout when it arrives.
+//
+// Images and PDFs are read in go, audio and video with ffprobe, they're left without metadata when it isn't installed.
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/server/model"
+	"main/server/service/thumbnailer"
+)
+
+// Timeout bounds ffprobe.
+const Timeout = 30 * time.Second
+
+// HeadLength is how much of a jpeg is read for its dimensions and EXIF orientation.
+const HeadLength = 128 << 10
+
+// Inspect reads the metadata of a file of the (sniffed) mime type, a zero File_metadata for files it knows nothing of.
+// The reader is read from its current position, and left anywhere.
+//
+// Example usage:
+//   Metadata, err := inspector.Inspect(ctx, src, Name, Mime)
+//   if err != nil { log.Print("Inspecting ", Name, ": ", err) }
+//   File.Metadata = Metadata
+func Inspect(ctx context.Context, src io.Reader, Name string, Mime string) (model.File_metadata, error) {
+	switch {
+		case thumbnailer.IsImage(model.Files{ Name: Name }): return inspectImage(src)
+		case Mime == "application/pdf": return inspectPDF(src)
+		case strings.HasPrefix(Mime, "video/"): return inspectMedia(ctx, src, filepath.Ext(Name), false)
+		case strings.HasPrefix(Mime, "audio/"): return inspectMedia(ctx, src, filepath.Ext(Name), true)
+	}
+	return model.File_metadata{}, nil
+}
+
+/* A photo turned by its EXIF orientation (5 to 8) displays with its sides swapped */
+func inspectImage(src io.Reader) (model.File_metadata, error) {
+	head, err := io.ReadAll(io.LimitReader(src, HeadLength))
+	if err != nil { return model.File_metadata{}, err }
+
+	config, _, err := image.DecodeConfig(io.MultiReader(bytes.NewReader(head), src))
+	if err != nil { return model.File_metadata{}, err }
+
+	Metadata := model.File_metadata{ Width: config.Width, Height: config.Height }
+	if thumbnailer.Orientation(head) >= 5 { Metadata.Width, Metadata.Height = config.Height, config.Width }
+	return Metadata, nil
+}
+
+var (
+	pageCount = regexp.MustCompile(`/Count\s+(\d+)`)
+	pageObject = regexp.MustCompile(`/Type\s*/Page(?:[^s]|$)`)
+)
+
+// pdfChunk is how much of a PDF is scanned at once, pdfOverlap how much of a chunk is scanned again with the next,
+// so a match across their border isn't missed.
+const (
+	pdfChunk = 1 << 20
+	pdfOverlap = 64
+)
+
+/* The page tree's root counts every page, the largest /Count is its. Page objects are counted when there's none,
+   neither is found when they're compressed into object streams, the count is left unknown then */
+func inspectPDF(src io.Reader) (model.File_metadata, error) {
+	Count, Objects := 0, 0
+	buffer := make([]byte, pdfChunk + pdfOverlap)
+	kept := 0
+	for {
+		n, err := io.ReadFull(src, buffer[kept:])
+		chunk := buffer[:kept + n]
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last { return model.File_metadata{}, err }
+
+		/* Matches starting in the overlap are left to the next chunk, which scans it whole */
+		limit := len(chunk) - pdfOverlap
+		if last { limit = len(chunk) }
+
+		for _, match := range pageCount.FindAllSubmatchIndex(chunk, -1) {
+			if match[0] >= limit { continue }
+			if value, err := strconv.Atoi(string(chunk[match[2]:match[3]])); err == nil { Count = max(Count, value) }
+		}
+		for _, match := range pageObject.FindAllIndex(chunk, -1) {
+			if match[0] < limit { Objects++ }
+		}
+
+		if last { break }
+		kept = copy(buffer, chunk[limit:])
+	}
+
+	if Count == 0 { Count = Objects }
+	return model.File_metadata{ Pages: Count }, nil
+}
+
+type probe struct {
+	Streams []struct {
+		CodecType	string		`json:"codec_type"`
+		CodecName	string		`json:"codec_name"`
+		Width		int			`json:"width"`
+		Height		int			`json:"height"`
+	}									`json:"streams"`
+	Format struct {
+		Duration	string		`json:"duration"`
+	}									`json:"format"`
+}
+
+/* ffprobe needs a seekable file, containers keep their index at the end. The codec is the video's, the audio's
+   without one. The "video" of an audio file is its cover art, it's ignored */
+func inspectMedia(ctx context.Context, src io.Reader, Extension string, Audio bool) (model.File_metadata, error) {
+	if _, err := exec.LookPath("ffprobe"); err != nil { return model.File_metadata{}, nil }
+
+	local, err := os.CreateTemp("", "inspect-*" + Extension)
+	if err != nil { return model.File_metadata{}, err }
+	defer os.Remove(local.Name())
+
+	_, err = io.Copy(local, src)
+	local.Close()
+	if err != nil { return model.File_metadata{}, err }
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-show_entries", "format=duration:stream=codec_type,codec_name,width,height", "-of", "json", local.Name()).Output()
+	if err != nil { return model.File_metadata{}, fmt.Errorf("ffprobe: %w", err) }
+
+	var Probe probe
+	if err := json.Unmarshal(output, &Probe); err != nil { return model.File_metadata{}, fmt.Errorf("ffprobe output: %w", err) }
+
+	Metadata := model.File_metadata{}
+	Metadata.Duration, _ = strconv.ParseFloat(Probe.Format.Duration, 64)
+	for _, Stream := range Probe.Streams {
+		switch {
+			case Stream.CodecType == "video" && !Audio && Metadata.Width == 0:
+				Metadata.Codec, Metadata.Width, Metadata.Height = Stream.CodecName, Stream.Width, Stream.Height
+			case Stream.CodecType == "audio" && Metadata.Codec == "":
+				Metadata.Codec = Stream.CodecName
+		}
+	}
+	return Metadata, nil
+}
@@ -0,0 +1,106 @@
+// Package kiosk serves pages of the site to screens (TVs, info screens) in kiosk mode: read-only, reloaded on their
+// own and authenticated by the token in their url rather than a session, so a screen needs no account and nobody
+// has to sign in on it. Tokens are revoked by removing their display.
+//
+// A display shows one of model.KioskPages under /display/:token, inside frames of the site or of its Ancestors only
+// (Content-Security-Policy frame-ancestors). It's served from the site itself, so embedding it needs no CORS, and
+// no cookie is read or set on it.
+package kiosk
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"main/server/common/domain"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+/* SeenAt is written at most this often, a display reloading every 15s shouldn't write as often */
+const seenEvery = time.Minute
+
+var (
+	ErrNotFound = domain.NotFound("kiosk display not found")
+	ErrPage = domain.Invalid("unknown kiosk page")
+	ErrAncestor = domain.Invalid("frame ancestors are origins like https://example.com")
+)
+
+// Create adds a display with a new token. Refresh is raised to model.KioskMinRefresh, 0 is model.KioskDefaultRefresh.
+//
+// Returns:
+//   - ErrPage for a Page which isn't one of model.KioskPages.
+//   - ErrAncestor, wrapped with it, for an ancestor which isn't an origin.
+func Create(Name string, Page string, Refresh int, Ancestors string) (model.Kiosk_displays, error) {
+	if !known(Page) { return model.Kiosk_displays{}, fmt.Errorf("%w: %s", ErrPage, Page) }
+
+	Origins, err := origins(Ancestors)
+	if err != nil { return model.Kiosk_displays{}, err }
+
+	if Refresh == 0 { Refresh = model.KioskDefaultRefresh }
+	if Refresh < model.KioskMinRefresh { Refresh = model.KioskMinRefresh }
+
+	random := make([]byte, 24)
+	rand.Read(random)
+
+	Display := model.Kiosk_displays{ Name: Name, Token: hex.EncodeToString(random), Page: Page, Refresh: Refresh, Ancestors: strings.Join(Origins, " ") }
+	return Display, storage.DB.Create(&Display).Error
+}
+
+// Find returns the display of a token.
+func Find(Token string) (model.Kiosk_displays, error) {
+	var Display model.Kiosk_displays
+	if Token == "" { return Display, ErrNotFound }
+
+	if err := storage.DB.Where("token = ?", Token).First(&Display).Error; err != nil { return Display, ErrNotFound }
+	return Display, nil
+}
+
+// List returns the displays, by name.
+func List() ([]model.Kiosk_displays, error) {
+	var Displays []model.Kiosk_displays
+	err := storage.DB.Order("name, id").Find(&Displays).Error
+	return Displays, err
+}
+
+// Remove deletes a display, its token stops working at once.
+func Remove(ID uint) error {
+	result := storage.DB.Unscoped().Delete(&model.Kiosk_displays{}, ID)
+	if result.Error != nil { return result.Error }
+	if result.RowsAffected == 0 { return ErrNotFound }
+	return nil
+}
+
+// Seen records that the display was just loaded.
+func Seen(Display model.Kiosk_displays) {
+	if Display.SeenAt != nil && time.Since(*Display.SeenAt) < seenEvery { return }
+	storage.DB.Model(&Display).UpdateColumn("seen_at", time.Now())
+}
+
+// FrameAncestors is the frame-ancestors directive of a display: the site and the display's Ancestors.
+func FrameAncestors(Display model.Kiosk_displays) string {
+	return strings.TrimSpace("frame-ancestors 'self' " + Display.Ancestors)
+}
+
+func known(Page string) bool {
+	for _, Known := range model.KioskPages {
+		if Page == Known { return true }
+	}
+	return false
+}
+
+/* Only bare origins go into the header, anything else could add directives to it */
+func origins(Ancestors string) ([]string, error) {
+	Origins := []string{}
+	for _, Ancestor := range strings.FieldsFunc(Ancestors, func(r rune) bool { return r == ' ' || r == ',' || r == '\n' }) {
+		Parsed, err := url.Parse(Ancestor)
+		if err != nil || (Parsed.Scheme != "https" && Parsed.Scheme != "http") || Parsed.Host == "" || strings.ContainsAny(Parsed.Host, ";'\"") || strings.Trim(Parsed.Path, "/") != "" || Parsed.RawQuery != "" {
+			return nil, fmt.Errorf("%w: %s", ErrAncestor, Ancestor)
+		}
+		Origins = append(Origins, Parsed.Scheme + "://" + Parsed.Host)
+	}
+	return Origins, nil
+}
@@ -0,0 +1,124 @@
+package mailer
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// ErrSignature is returned by Verify for webhook requests which weren't signed by the provider.
+var ErrSignature = errors.New("mail event signature is invalid")
+
+// Event is a delivery event as SendGrid's event webhook posts them (a JSON array of these).
+type Event struct {
+	Email		string		`json:"email"`
+	Event		string		`json:"event"`
+	MessageID	string		`json:"sg_message_id"`
+	Timestamp	int64		`json:"timestamp"`
+	Reason		string		`json:"reason"`
+	Type		string		`json:"type"`
+}
+
+// Verify checks the webhook's signature, an ECDSA signature of the timestamp followed by the body,
+// against the verification key (globals.Env.SENDGRID_WEBHOOK_KEY).
+// Unsigned requests are only let through in development, when no key is set.
+func Verify(Signature string, Timestamp string, Body []byte) error {
+	if globals.Env.SENDGRID_WEBHOOK_KEY == "" {
+		if globals.Env.GOENV == "development" { return nil }
+		return ErrSignature
+	}
+
+	der, err := base64.StdEncoding.DecodeString(globals.Env.SENDGRID_WEBHOOK_KEY)
+	if err != nil { return err }
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil { return err }
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok { return ErrSignature }
+
+	signature, err := base64.StdEncoding.DecodeString(Signature)
+	if err != nil { return ErrSignature }
+
+	hash := sha256.Sum256(append([]byte(Timestamp), Body...))
+	if !ecdsa.VerifyASN1(key, hash[:], signature) { return ErrSignature }
+	return nil
+}
+
+// Ingest applies the provider's events to the recorded mails, hard bounces and complaints suppress the address.
+// Events the outbox doesn't show (processed, open, click, ...) are ignored.
+func Ingest(Events []Event) error {
+	for _, Event := range Events {
+		Status := status(Event)
+		if Status == "" { continue }
+
+		At := time.Unix(Event.Timestamp, 0)
+		if err := update(Event, Status, At); err != nil { return err }
+
+		switch Status {
+		case model.MailBounced:
+			if err := Suppress(Event.Email, Status, Event.Reason); err != nil { return err }
+		case model.MailComplained:
+			if err := Suppress(Event.Email, Status, "spam report"); err != nil { return err }
+		}
+	}
+	return nil
+}
+
+/* "blocked" bounces are temporary (the receiving server refused for now), only "bounce" ones are hard */
+func status(Event Event) string {
+	switch Event.Event {
+	case "delivered":
+		return model.MailDelivered
+	case "deferred":
+		return model.MailDeferred
+	case "dropped":
+		return model.MailFailed
+	case "spamreport":
+		return model.MailComplained
+	case "bounce":
+		if Event.Type == "blocked" { return model.MailDeferred }
+		return model.MailBounced
+	}
+	return ""
+}
+
+/* sg_message_id is the X-Message-Id Send recorded, followed by ".filter..." */
+func update(Event Event, Status string, At time.Time) error {
+	MessageID, _, _ := strings.Cut(Event.MessageID, ".")
+	if MessageID == "" { return nil }
+
+	/* Events may come out of order, an older one doesn't overwrite a newer status */
+	return storage.DB.Model(&model.Mails{}).
+		Where("message_id = ? AND (status_at IS NULL OR status_at <= ?)", MessageID, At).
+		Updates(map[string]any{ "status": Status, "status_at": At, "error": Event.Reason }).Error
+}
+
+// Suppress stops every future mail to the address, the latest reason is kept.
+func Suppress(Email string, Reason string, Detail string) error {
+	Suppression := model.Mail_suppressions{ Email: Normalize(Email), Reason: Reason, Detail: Detail }
+	return storage.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{ Name: "email" }},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "detail", "updated_at"}),
+	}).Create(&Suppression).Error
+}
+
+// Suppressed reports whether mails to the address are suppressed.
+func Suppressed(Email string) bool {
+	var Count int64
+	storage.DB.Model(&model.Mail_suppressions{}).Where("email = ?", Normalize(Email)).Count(&Count)
+	return Count > 0
+}
+
+// Unsuppress lets mails to the address through again, e.g. after the recipient fixed their mailbox.
+func Unsuppress(ID uint) error {
+	return storage.DB.Unscoped().Delete(&model.Mail_suppressions{}, ID).Error
+}
@@ -1,41 +1,68 @@
 package mailer
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"main/server/common/globals"
 	"main/server/common/storage"
 	"main/server/model"
+	"strings"
+	"time"
 
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
 )
 
+// ErrSuppressed is returned by Send for addresses which bounced or complained before, see Suppress.
+var ErrSuppressed = errors.New("mail address is suppressed")
+
 type Config struct {
 	Subject string
 	Body string
 	To string
 }
 
+/* Every attempt is recorded with its status, the outbox shows them and provider events update them */
 func Send(config Config) (bool, error) {
+	Mail := model.Mails{
+		From: "sendgrid/ucha1bokeria@gmail.com",
+		To: Normalize(config.To),
+		Body: config.Body,
+		Subject: config.Subject,
+		Status: model.MailSent,
+	}
+
+	if Suppressed(Mail.To) {
+		Mail.Status = model.MailSuppressed
+		storage.DB.Create(&Mail)
+		return false, ErrSuppressed
+	}
+
 	to := mail.NewEmail("yacco", config.To)
 	from := mail.NewEmail("yacco", "ucha1bokeria@gmail.com")
 	message := mail.NewSingleEmail(from, config.Subject, to, "", config.Body)
 	client := sendgrid.NewSendClient(globals.Env.SENDGRID_API_KEY)
-	_, err := client.Send(message)
+	response, err := client.Send(message)
+
+	if err == nil && response.StatusCode >= 400 { err = fmt.Errorf("sendgrid: %d %s", response.StatusCode, response.Body) }
 
-	// log.Println(err, config)
+	Now := time.Now()
+	Mail.StatusAt = &Now
 	if err != nil {
 		log.Println(err, config)
-		return false, err
-	} else {
-		var Mail model.Mails = model.Mails{
-			From: "sendgrid/ucha1bokeria@gmail.com",
-			To: config.To,
-			Body: config.Body,
-			Subject: config.Subject,
-		}
-
+		Mail.Status = model.MailFailed
+		Mail.Error = err.Error()
 		storage.DB.Create(&Mail)
-		return true, nil
+		return false, err
 	}
-}
\ No newline at end of file
+
+	if ids := response.Headers["X-Message-Id"]; len(ids) > 0 { Mail.MessageID = ids[0] }
+	storage.DB.Create(&Mail)
+	return true, nil
+}
+
+// Normalize lowercases and trims an address, suppressions are matched on it.
+func Normalize(Email string) string {
+	return strings.ToLower(strings.TrimSpace(Email))
+}
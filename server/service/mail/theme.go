@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/a-h/templ"
+
+	"main/build/view"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// DefaultTheme is used for whatever a site didn't brand.
+var DefaultTheme = view.DefaultMailTheme
+
+/* Colors end up in inline styles, only plain hex colors are let through */
+var color = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// IsColor reports whether the value is a hex color a theme accepts ("#fff", "#1f2937").
+func IsColor(value string) bool {
+	return color.MatchString(value)
+}
+
+// Theme resolves the mail branding of a site (an Interface row), InterfaceID 0 takes the current site.
+// The logo is linked absolutely through APP_URL, mails are read far from the site.
+func Theme(InterfaceID uint) view.MailTheme {
+	var Interface model.Interface
+	Query := storage.DB.Preload("Mail.Logo")
+	if InterfaceID != 0 { Query = Query.Where("id = ?", InterfaceID) }
+	if Query.Last(&Interface).Error != nil { return DefaultTheme }
+
+	Theme := DefaultTheme
+	if Interface.Name != "" { Theme.Name = Interface.Name }
+
+	Mail := Interface.Mail
+	if IsColor(Mail.Primary) { Theme.Primary = Mail.Primary }
+	if IsColor(Mail.Background) { Theme.Background = Mail.Background }
+	if IsColor(Mail.Text) { Theme.Text = Mail.Text }
+	if Mail.Footer != "" { Theme.Footer = Mail.Footer }
+	if Mail.LogoID != nil && Mail.Logo.Path != "" { Theme.Logo = globals.Env.APP_URL + "/" + strings.TrimLeft(Mail.Logo.Path, "/") }
+
+	return Theme
+}
+
+// Render renders a mail template within the current site's themed layout, ctx carries the locale (i18n.WithLocale).
+//
+// Example usage:
+//   Body, err := mailer.Render(i18n.WithLocale(ctx, User.Locale), view.SecurityMail(User.Fullname, Title, Message, nil))
+//   if err != nil { return err }
+//   mailer.Send(mailer.Config{ To: User.Email, Subject: Title, Body: Body })
+func Render(ctx context.Context, component templ.Component) (string, error) {
+	return RenderWith(ctx, Theme(0), component)
+}
+
+// RenderWith renders a mail template within the layout of the given theme.
+func RenderWith(ctx context.Context, Theme view.MailTheme, component templ.Component) (string, error) {
+	var Body bytes.Buffer
+	if err := view.MailLayout(Theme, component).Render(ctx, &Body); err != nil { return "", err }
+	return Body.String(), nil
+}
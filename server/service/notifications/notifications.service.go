@@ -0,0 +1,176 @@
+// Package notifications keeps the notifications of admin users, listed newest first in the notifications drawer.
+//
+// A notification belongs to a Category, a user may mute any of them: Notify drops what a user muted instead of
+// storing it. The drawer pages through them with an opaque cursor instead of page numbers, so notifications
+// arriving while it's open don't shift the next page. Reading is batched: the drawer marks the ids it shows, or
+// everything up to the newest it has seen, in one request.
+package notifications
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+	"strconv"
+	"time"
+
+	"main/server/common/domain"
+	"main/server/common/module"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/transcoder"
+)
+
+// Categories of notifications.
+const (
+	CategorySecurity	= "security"
+	CategoryUploads		= "uploads"
+	CategoryTasks		= "tasks"
+)
+
+// Category is a kind of notifications, as the mute settings list it.
+type Category struct {
+	Name		string
+	Label		string
+}
+
+// Categories are the categories a user may mute.
+var Categories = []Category{
+	{ Name: CategorySecurity, Label: "უსაფრთხოება" },
+	{ Name: CategoryUploads, Label: "ატვირთვები" },
+	{ Name: CategoryTasks, Label: "ფონური დავალებები" },
+}
+
+// PageSize is how many notifications a page has at most.
+const PageSize = 20
+
+// MaxBatch is how many ids one MarkRead marks at most.
+const MaxBatch = 500
+
+var (
+	ErrCursor = domain.Invalid("malformed notifications cursor")
+	ErrCategory = domain.NotFound("notification category not found")
+)
+
+// Page is a page of a user's notifications, Next is the cursor of the following one, "" on the last.
+type Page struct {
+	Notifications	[]model.Notifications
+	Next			string
+}
+
+// Setup turns the events of the container into notifications.
+func Setup(container *module.Container) {
+	container.On("video.transcoded", func(ctx context.Context, payload any) error {
+		Transcoded, ok := payload.(transcoder.Transcoded)
+		if !ok { return nil }
+
+		var File model.Files
+		if err := storage.DB.First(&File, Transcoded.FileID).Error; err != nil || File.OwnerID == nil { return nil }
+
+		Title := "ვიდეო დამუშავდა"
+		if Transcoded.State == model.TranscodeFailed { Title = "ვიდეოს დამუშავება ვერ მოხერხდა" }
+		return Notify(*File.OwnerID, CategoryUploads, Title, File.Original, "/files/" + File.Ref(File.ID))
+	})
+}
+
+// Notify stores a notification for the user, unless they muted its category.
+//
+// Example usage:
+//   if err := notifications.Notify(User.ID, notifications.CategorySecurity, Title, Message, ""); err != nil {
+//       log.Print("Notifying: ", err)
+//   }
+func Notify(UsersID uint, Category string, Title string, Body string, Link string) error {
+	if Muted(UsersID)[Category] { return nil }
+	return storage.DB.Create(&model.Notifications{ UsersID: UsersID, Category: Category, Title: Title, Body: Body, Link: Link }).Error
+}
+
+// List returns the page of the user's notifications after the cursor ("" for the first one), the unread ones only
+// when Unread is set.
+func List(UsersID uint, Cursor string, Limit int, Unread bool) (Page, error) {
+	if Limit <= 0 || Limit > PageSize { Limit = PageSize }
+
+	Query := storage.DB.Where("users_id = ?", UsersID)
+	if Cursor != "" {
+		After, err := decode(Cursor)
+		if err != nil { return Page{}, err }
+		Query = Query.Where("id < ?", After)
+	}
+	if Unread { Query = Query.Where("read_at IS NULL") }
+
+	/* One more than the page tells whether there's a next one */
+	var Notifications []model.Notifications
+	if err := Query.Order("id DESC").Limit(Limit + 1).Find(&Notifications).Error; err != nil { return Page{}, err }
+
+	Page := Page{ Notifications: Notifications }
+	if len(Notifications) > Limit {
+		Page.Notifications = Notifications[:Limit]
+		Page.Next = encode(Page.Notifications[Limit - 1].ID)
+	}
+	return Page, nil
+}
+
+// Unread counts the user's unread notifications.
+func Unread(UsersID uint) (int64, error) {
+	var Count int64
+	err := storage.DB.Model(&model.Notifications{}).Where("users_id = ? AND read_at IS NULL", UsersID).Count(&Count).Error
+	return Count, err
+}
+
+// MarkRead marks the user's notifications of the ids read, MaxBatch of them at most, and returns how many were unread.
+func MarkRead(UsersID uint, IDs []uint) (int64, error) {
+	if len(IDs) == 0 { return 0, nil }
+	if len(IDs) > MaxBatch { IDs = IDs[:MaxBatch] }
+
+	Result := storage.DB.Model(&model.Notifications{}).
+		Where("users_id = ? AND id IN ? AND read_at IS NULL", UsersID, IDs).
+		Update("read_at", time.Now())
+	return Result.RowsAffected, Result.Error
+}
+
+// MarkAllRead marks every notification of the user up to the id read, the ones arriving meanwhile stay unread.
+// Until 0 marks them all.
+func MarkAllRead(UsersID uint, Until uint) (int64, error) {
+	Query := storage.DB.Model(&model.Notifications{}).Where("users_id = ? AND read_at IS NULL", UsersID)
+	if Until != 0 { Query = Query.Where("id <= ?", Until) }
+
+	Result := Query.Update("read_at", time.Now())
+	return Result.RowsAffected, Result.Error
+}
+
+// Muted returns the categories the user muted.
+func Muted(UsersID uint) map[string]bool {
+	var Mutes []model.Notification_mutes
+	if err := storage.DB.Where("users_id = ?", UsersID).Find(&Mutes).Error; err != nil { log.Print("Reading notification mutes: ", err) }
+
+	Muted := map[string]bool{}
+	for _, Mute := range Mutes { Muted[Mute.Category] = true }
+	return Muted
+}
+
+// Mute mutes or unmutes a category for the user.
+func Mute(UsersID uint, Name string, Muted bool) error {
+	if !known(Name) { return ErrCategory }
+
+	Where := model.Notification_mutes{ UsersID: UsersID, Category: Name }
+	if !Muted { return storage.DB.Unscoped().Where(&Where).Delete(&model.Notification_mutes{}).Error }
+	return storage.DB.Where(&Where).FirstOrCreate(&model.Notification_mutes{}).Error
+}
+
+func known(Name string) bool {
+	for _, Category := range Categories {
+		if Category.Name == Name { return true }
+	}
+	return false
+}
+
+/* Cursors are opaque to clients, they only hand them back */
+func encode(ID uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(ID), 10)))
+}
+
+func decode(Cursor string) (uint, error) {
+	data, err := base64.RawURLEncoding.DecodeString(Cursor)
+	if err != nil { return 0, ErrCursor }
+	ID, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil { return 0, ErrCursor }
+	return uint(ID), nil
+}
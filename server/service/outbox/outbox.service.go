@@ -0,0 +1,200 @@
+// Package outbox makes side effects (events, hooks, mails) as reliable as the database change causing them.
+//
+// Instead of being sent right away, a side effect is written as an Outbox_messages row in the same transaction
+// as the change: it's lost when the request fails and rolls back, and kept when it commits.
+//
+//   err := ctx.Tx(func(tx *gorm.DB) error {
+//      if err := tx.Create(&Subscriber).Error; err != nil { return err }
+//      return outbox.Mail(tx, mailer.Config{ To: Subscriber.Email, Subject: Subject, Body: Body })
+//   })
+//
+// The dispatcher (a job of the module container, see Setup) drains the committed messages in the background,
+// retrying failures with a growing delay. Messages are claimed for ClaimFor before they're dispatched, outside
+// of any transaction, so several app instances never send the same message. A dispatcher dying between sending
+// and recording it sends it again once the claim runs out, receivers which can't afford that dedupe on the
+// message's Key (Event.Outbox for listeners, the "key" of hook notifications).
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"main/server/common/module"
+	"main/server/common/storage"
+	"main/server/model"
+	mailer "main/server/service/mail"
+)
+
+const (
+	// DrainEvery is how often the dispatcher looks for committed messages.
+	DrainEvery = 5 * time.Second
+	// BatchSize is how many messages a drain dispatches at most.
+	BatchSize = 50
+	// MaxAttempts is how many times a message is tried before it's marked failed.
+	MaxAttempts = 8
+	// ClaimFor is how long a drain has to dispatch the messages it claimed, they're due again after it.
+	ClaimFor = 5 * time.Minute
+)
+
+/* Kinds of messages, each one has a Handler */
+const (
+	KindEvent = "event"
+	KindMail = "mail"
+)
+
+// Handler dispatches a message of a kind, an error retries it later.
+type Handler func(ctx context.Context, Message model.Outbox_messages) error
+
+// ErrNoHandler is the error of messages whose kind has no Handler.
+var ErrNoHandler = errors.New("outbox message kind has no handler")
+
+var (
+	mu sync.RWMutex
+	handlers = map[string]Handler{}
+)
+
+// Event is the payload events dispatched through the outbox are emitted with: the message's Key
+// and the payload as it was written, in json. It's marshalled as the payload alone, hooks are notified
+// with the same payload an event emitted right away has, and the Key beside it (see hooks.Keyed).
+type Event struct {
+	Outbox		string
+	Payload		json.RawMessage
+}
+
+// Key is the message's Key.
+func (Event Event) Key() string {
+	return Event.Outbox
+}
+
+func (Event Event) MarshalJSON() ([]byte, error) {
+	if len(Event.Payload) == 0 { return []byte("null"), nil }
+	return Event.Payload, nil
+}
+
+// Setup registers the event and mail handlers and declares the dispatcher job on the container.
+// Events are emitted through the container, so module listeners and hooks (see hooks.Setup) receive them.
+func Setup(container *module.Container) {
+	Handle(KindEvent, func(ctx context.Context, Message model.Outbox_messages) error {
+		container.Emit(ctx, Message.Topic, Event{ Outbox: Message.Key, Payload: json.RawMessage(Message.Payload) })
+		return nil
+	})
+
+	Handle(KindMail, func(ctx context.Context, Message model.Outbox_messages) error {
+		var Config mailer.Config
+		if err := json.Unmarshal([]byte(Message.Payload), &Config); err != nil { return err }
+
+		/* Suppressed addresses never become deliverable by retrying */
+		if _, err := mailer.Send(Config); err != nil && !errors.Is(err, mailer.ErrSuppressed) { return err }
+		return nil
+	})
+
+	container.Cron("outbox", DrainEvery, Drain)
+}
+
+// Handle sets the handler of a kind of messages.
+func Handle(Kind string, handler Handler) {
+	mu.Lock()
+	handlers[Kind] = handler
+	mu.Unlock()
+}
+
+// Add writes a message within tx, the transaction of the change it belongs to. The payload is stored as json.
+func Add(tx *gorm.DB, Kind string, Topic string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil { return err }
+
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil { return err }
+
+	return tx.Create(&model.Outbox_messages{
+		Key: hex.EncodeToString(key),
+		Kind: Kind,
+		Topic: Topic,
+		Payload: string(data),
+		Status: model.OutboxPending,
+		AvailableAt: time.Now(),
+	}).Error
+}
+
+// Emit writes an event within tx, it's emitted once tx is committed.
+//
+// Example usage:
+//   return outbox.Emit(tx, "preview.published", Channel)
+func Emit(tx *gorm.DB, Event string, payload any) error {
+	return Add(tx, KindEvent, Event, payload)
+}
+
+// Mail writes a mail within tx, it's sent once tx is committed.
+func Mail(tx *gorm.DB, config mailer.Config) error {
+	return Add(tx, KindMail, config.To, config)
+}
+
+// Drain dispatches the messages which are due, oldest first. It returns an error only when the outbox
+// itself can't be read or updated, failing messages are rescheduled.
+func Drain(ctx context.Context) error {
+	Messages, err := claim(ctx)
+	if err != nil { return err }
+
+	for _, Message := range Messages {
+		if err := storage.DB.WithContext(ctx).Model(&Message).Updates(result(Message, dispatch(ctx, Message))).Error; err != nil { return err }
+	}
+	return nil
+}
+
+/* The due messages are claimed in a short transaction, by making them due ClaimFor later: no row stays locked
+   while handlers send mails or call hooks. SKIP LOCKED lets other instances claim the rest meanwhile */
+func claim(ctx context.Context) ([]model.Outbox_messages, error) {
+	var Messages []model.Outbox_messages
+
+	err := storage.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{ Strength: "UPDATE", Options: "SKIP LOCKED" }).
+			Where("status = ? AND available_at <= ?", model.OutboxPending, time.Now()).
+			Order("id").Limit(BatchSize).Find(&Messages).Error
+		if err != nil || len(Messages) == 0 { return err }
+
+		IDs := make([]uint, len(Messages))
+		for i, Message := range Messages { IDs[i] = Message.ID }
+		return tx.Model(&model.Outbox_messages{}).Where("id IN ?", IDs).Update("available_at", time.Now().Add(ClaimFor)).Error
+	})
+	return Messages, err
+}
+
+func dispatch(ctx context.Context, Message model.Outbox_messages) (err error) {
+	mu.RLock()
+	handler, found := handlers[Message.Kind]
+	mu.RUnlock()
+	if !found { return fmt.Errorf("%w: %s", ErrNoHandler, Message.Kind) }
+
+	defer func() {
+		if recovered := recover(); recovered != nil { err = fmt.Errorf("outbox handler panicked: %v", recovered) }
+	}()
+	return handler(ctx, Message)
+}
+
+/* Failed attempts wait 2^attempts seconds before the next one: 2s, 4s, ... about 4 minutes for the last */
+func result(Message model.Outbox_messages, err error) map[string]any {
+	Now := time.Now()
+	if err == nil { return map[string]any{ "status": model.OutboxSent, "sent_at": &Now, "error": "" } }
+
+	Attempts := Message.Attempts + 1
+	log.Print("Outbox ", Message.Kind, " ", Message.Topic, " attempt ", Attempts, ": ", err)
+
+	Status := model.OutboxPending
+	if Attempts >= MaxAttempts { Status = model.OutboxFailed }
+	return map[string]any{
+		"status": Status,
+		"attempts": Attempts,
+		"available_at": Now.Add(time.Duration(1 << Attempts) * time.Second),
+		"error": err.Error(),
+	}
+}
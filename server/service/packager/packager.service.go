@@ -0,0 +1,410 @@
+// Package packager moves content between environments (staging -> production).
+//
+// An export is a zip archive holding a manifest, one json file per exported kind, the Files rows they reference
+// and those files' blobs. The manifest lists a checksum for every entry and is signed with globals.Env.PACKAGE_SECRET,
+// which has to be the same on both environments.
+//
+// On import every record gets a new ID, references between records (files, news types, the interface)
+// are remapped to the IDs of the target database, and records already present there (matched by a natural key,
+// like the slug) are resolved by the chosen Conflict strategy.
+package packager
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/blob"
+	"main/server/common/domain"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+const Version = 1
+
+type Conflict string
+
+const (
+	Skip		Conflict = "skip"
+	Overwrite	Conflict = "overwrite"
+	Duplicate	Conflict = "duplicate"
+)
+
+// Kinds lists the exportable kinds in the order they're imported, dependencies first.
+var Kinds = []string{"news_types", "news", "faq", "categories", "contact", "about", "social_media"}
+
+var (
+	ErrSignature = domain.Invalid("package signature is invalid, was it exported with the same PACKAGE_SECRET?")
+	ErrChecksum = domain.Invalid("package content doesn't match its manifest")
+	ErrVersion = domain.UnsupportedType("package version is not supported")
+	ErrKind = domain.UnsupportedType("unknown kind")
+	ErrSecret = errors.New("PACKAGE_SECRET is not set")
+	ErrTooLarge = domain.Invalid("package is too large")
+)
+
+const (
+	MaxManifestSize = 1 << 20
+	MaxSize = 1 << 30			/* what a package unpacks to at most, its blobs included */
+)
+
+type Manifest struct {
+	Version		int
+	CreatedAt	time.Time
+	Kinds		[]string
+	Checksums	map[string]string
+}
+
+// Result reports what happened to a single record on import.
+type Result struct {
+	Kind		string
+	Key			string
+	OldID		uint
+	NewID		uint
+	Action		string
+}
+
+type content struct {
+	Files		[]model.Files
+	NewsTypes	[]model.News_types
+	News		[]model.News
+	Faq			[]model.Faq
+	Categories	[]model.Categories
+	Contact		[]model.Interface_contact
+	About		[]model.Interface_about
+	SocialMedia	[]model.Social_media
+}
+
+// Export writes a signed package of the given kinds into w.
+// Kinds which others depend on are exported along with them (news brings its news types).
+func Export(w io.Writer, kinds []string) error {
+	if globals.Env.PACKAGE_SECRET == "" { return ErrSecret }
+
+	var Content content
+	selected := map[string]bool{}
+
+	for _, kind := range kinds {
+		if !isKind(kind) { return fmt.Errorf("%w: %s", ErrKind, kind) }
+		selected[kind] = true
+	}
+	if selected["news"] { selected["news_types"] = true }
+
+	if selected["news_types"] { storage.DB.Find(&Content.NewsTypes) }
+	if selected["news"] { storage.DB.Find(&Content.News) }
+	if selected["faq"] { storage.DB.Find(&Content.Faq) }
+	if selected["categories"] { storage.DB.Find(&Content.Categories) }
+	if selected["contact"] { storage.DB.Order("id desc").Limit(1).Find(&Content.Contact) }
+	if selected["about"] { storage.DB.Order("id desc").Limit(1).Find(&Content.About) }
+	if selected["social_media"] { storage.DB.Find(&Content.SocialMedia) }
+
+	var FileIDs []int
+	for _, New := range Content.News { FileIDs = append(FileIDs, New.ThumbnailID) }
+	for _, Category := range Content.Categories { FileIDs = append(FileIDs, Category.IconID) }
+	for _, Social := range Content.SocialMedia { FileIDs = append(FileIDs, Social.IconID) }
+	if len(FileIDs) > 0 { storage.DB.Find(&Content.Files, FileIDs) }
+
+	entries := map[string][]byte{}
+	for name, records := range map[string]any{
+		"data/files.json": Content.Files,
+		"data/news_types.json": Content.NewsTypes,
+		"data/news.json": Content.News,
+		"data/faq.json": Content.Faq,
+		"data/categories.json": Content.Categories,
+		"data/contact.json": Content.Contact,
+		"data/about.json": Content.About,
+		"data/social_media.json": Content.SocialMedia,
+	} {
+		data, err := json.MarshalIndent(records, "", "    ")
+		if err != nil { return err }
+		entries[name] = data
+	}
+
+	for _, File := range Content.Files {
+		reader, _, err := blob.Default().Open(context.Background(), blob.Key(File.Path))
+		if err != nil { continue }
+
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil { continue }
+		entries["blobs/" + blobName(File)] = content
+	}
+
+	Manifest := Manifest{ Version: Version, CreatedAt: time.Now(), Checksums: map[string]string{} }
+	for kind := range selected { Manifest.Kinds = append(Manifest.Kinds, kind) }
+	sort.Strings(Manifest.Kinds)
+	for name, data := range entries { Manifest.Checksums[name] = checksum(data) }
+
+	manifest, err := json.MarshalIndent(Manifest, "", "    ")
+	if err != nil { return err }
+	entries["manifest.json"] = manifest
+	entries["manifest.sig"] = []byte(sign(manifest))
+
+	names := make([]string, 0, len(entries))
+	for name := range entries { names = append(names, name) }
+	sort.Strings(names)
+
+	archive := zip.NewWriter(w)
+	for _, name := range names {
+		entry, err := archive.Create(name)
+		if err != nil { return err }
+		if _, err := entry.Write(entries[name]); err != nil { return err }
+	}
+	return archive.Close()
+}
+
+// Import verifies the package and imports it in a single transaction, nothing is imported when anything fails.
+// The entries are only read once the manifest's signature is checked, up to their declared size and MaxSize in all.
+func Import(r io.ReaderAt, size int64, strategy Conflict) ([]Result, error) {
+	if strategy != Skip && strategy != Overwrite && strategy != Duplicate {
+		return nil, fmt.Errorf("unknown conflict strategy %q", strategy)
+	}
+
+	if globals.Env.PACKAGE_SECRET == "" { return nil, ErrSecret }
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil { return nil, err }
+
+	/* The signed manifest is checked before anything else is read, an unsigned package is never unpacked */
+	entries := map[string][]byte{}
+	for _, file := range archive.File {
+		if file.Name != "manifest.json" && file.Name != "manifest.sig" { continue }
+		data, err := read(file, MaxManifestSize)
+		if err != nil { return nil, err }
+		entries[file.Name] = data
+	}
+
+	var Manifest Manifest
+	if !hmac.Equal([]byte(sign(entries["manifest.json"])), entries["manifest.sig"]) { return nil, ErrSignature }
+	if err := json.Unmarshal(entries["manifest.json"], &Manifest); err != nil { return nil, err }
+	if Manifest.Version != Version { return nil, ErrVersion }
+
+	var Total uint64
+	for _, file := range archive.File {
+		if file.Name == "manifest.json" || file.Name == "manifest.sig" { continue }
+		if _, listed := Manifest.Checksums[file.Name]; !listed { return nil, fmt.Errorf("%w: %s", ErrChecksum, file.Name) }
+
+		Total += file.UncompressedSize64
+		if Total > MaxSize { return nil, ErrTooLarge }
+		data, err := read(file, MaxSize)
+		if err != nil { return nil, err }
+		entries[file.Name] = data
+	}
+
+	for name, sum := range Manifest.Checksums {
+		data, exists := entries[name]
+		if !exists || checksum(data) != sum { return nil, fmt.Errorf("%w: %s", ErrChecksum, name) }
+	}
+
+	var Content content
+	for name, records := range map[string]any{
+		"data/files.json": &Content.Files,
+		"data/news_types.json": &Content.NewsTypes,
+		"data/news.json": &Content.News,
+		"data/faq.json": &Content.Faq,
+		"data/categories.json": &Content.Categories,
+		"data/contact.json": &Content.Contact,
+		"data/about.json": &Content.About,
+		"data/social_media.json": &Content.SocialMedia,
+	} {
+		if err := json.Unmarshal(entries[name], records); err != nil { return nil, fmt.Errorf("%s: %w", name, err) }
+	}
+
+	var Results []Result
+	var written []string
+
+	err = storage.DB.Transaction(func(tx *gorm.DB) error {
+		im := importer{ tx: tx, strategy: strategy, files: map[int]int{}, types: map[int]int{} }
+
+		for _, File := range Content.Files {
+			key, err := im.file(File, entries["blobs/" + blobName(File)])
+			if err != nil { return err }
+			if key != "" { written = append(written, key) }
+		}
+
+		var Interface model.Interface
+		tx.Last(&Interface)
+
+		/* record resets the row's ID, the news of the package point to the one it had there */
+		for _, Type := range Content.NewsTypes {
+			OldID := int(Type.ID)
+			id, err := im.record("news_types", Type.Slug, &Type, &model.News_types{Slug: Type.Slug})
+			if err != nil { return err }
+			im.types[OldID] = int(id)
+		}
+
+		for _, New := range Content.News {
+			New.ThumbnailID = im.files[New.ThumbnailID]
+			New.TypeID = im.types[New.TypeID]
+			if _, err := im.record("news", New.Title, &New, &model.News{Title: New.Title}); err != nil { return err }
+		}
+
+		for _, Faq := range Content.Faq {
+			if _, err := im.record("faq", Faq.Question, &Faq, &model.Faq{Question: Faq.Question}); err != nil { return err }
+		}
+
+		for _, Category := range Content.Categories {
+			Category.IconID = im.files[Category.IconID]
+			if _, err := im.record("categories", Category.Name, &Category, &model.Categories{Name: Category.Name}); err != nil { return err }
+		}
+
+		for _, Contact := range Content.Contact {
+			Contact.InterfaceID = Interface.ID
+			if _, err := im.record("contact", "contact", &Contact, &model.Interface_contact{InterfaceID: Interface.ID}); err != nil { return err }
+		}
+
+		for _, About := range Content.About {
+			About.InterfaceID = Interface.ID
+			if _, err := im.record("about", "about", &About, &model.Interface_about{InterfaceID: Interface.ID}); err != nil { return err }
+		}
+
+		for _, Social := range Content.SocialMedia {
+			Social.InterfaceID = Interface.ID
+			Social.IconID = im.files[Social.IconID]
+			if _, err := im.record("social_media", Social.Name, &Social, &model.Social_media{Name: Social.Name}); err != nil { return err }
+		}
+
+		Results = im.results
+		return nil
+	})
+
+	/* Blobs aren't part of the transaction, the ones written for a rolled back import are removed */
+	if err != nil {
+		for _, key := range written { blob.Default().Delete(context.Background(), key) }
+		return nil, err
+	}
+
+	return Results, nil
+}
+
+type importer struct {
+	tx			*gorm.DB
+	strategy	Conflict
+	files		map[int]int
+	types		map[int]int
+	results		[]Result
+}
+
+/* Files are content addressed by their hash name, an existing row with the same name is the same file */
+func (im *importer) file(File model.Files, content []byte) (string, error) {
+	var Existing model.Files
+	OldID := File.ID
+
+	if im.tx.Where(&model.Files{Name: File.Name}).Last(&Existing).Error == nil {
+		im.files[int(OldID)] = int(Existing.ID)
+		im.results = append(im.results, Result{ Kind: "files", Key: File.Name, OldID: OldID, NewID: Existing.ID, Action: "reused" })
+		return "", nil
+	}
+
+	written := ""
+	key := blob.Key(File.Path)
+	if reader, _, err := blob.Default().Open(context.Background(), key); err == nil {
+		reader.Close()
+	} else if content != nil {
+		if err := blob.Default().Put(context.Background(), key, bytes.NewReader(content), int64(len(content)), ""); err != nil { return "", err }
+		written = key
+	}
+
+	File.Model = gorm.Model{}
+	File.Type = model.File_types{}
+	if err := im.tx.Create(&File).Error; err != nil { return written, err }
+
+	im.files[int(OldID)] = int(File.ID)
+	im.results = append(im.results, Result{ Kind: "files", Key: File.Name, OldID: OldID, NewID: File.ID, Action: "created" })
+	return written, nil
+}
+
+/* Creates, overwrites or skips a record matched by the natural key in where, returning its ID in the target database */
+func (im *importer) record(kind string, key string, record any, where any) (uint, error) {
+	Model := reflect.ValueOf(record).Elem().FieldByName("Model").Addr().Interface().(*gorm.Model)
+	OldID := Model.ID
+	*Model = gorm.Model{}
+	clearAssociations(record)
+
+	Existing := reflect.New(reflect.TypeOf(record).Elem()).Interface()
+	found := im.tx.Where(where).Last(Existing).Error == nil
+	ExistingModel := reflect.ValueOf(Existing).Elem().FieldByName("Model").Interface().(gorm.Model)
+
+	Action := "created"
+	switch {
+		case found && im.strategy == Skip:
+			im.results = append(im.results, Result{ Kind: kind, Key: key, OldID: OldID, NewID: ExistingModel.ID, Action: "skipped" })
+			return ExistingModel.ID, nil
+		case found && im.strategy == Overwrite:
+			Model.ID = ExistingModel.ID
+			Model.CreatedAt = ExistingModel.CreatedAt
+			Action = "updated"
+			if err := im.tx.Save(record).Error; err != nil { return 0, fmt.Errorf("%s %q: %w", kind, key, err) }
+		default:
+			if err := im.tx.Create(record).Error; err != nil { return 0, fmt.Errorf("%s %q: %w", kind, key, err) }
+	}
+
+	im.results = append(im.results, Result{ Kind: kind, Key: key, OldID: OldID, NewID: Model.ID, Action: Action })
+	return Model.ID, nil
+}
+
+/* Preloaded associations would be upserted by gorm with their exported IDs, only the remapped foreign keys count */
+func clearAssociations(record any) {
+	value := reflect.ValueOf(record).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if value.Type().Field(i).Name == "Model" { continue }
+		if field.Kind() == reflect.Struct && field.Type().PkgPath() == reflect.TypeOf(model.Files{}).PkgPath() {
+			field.Set(reflect.Zero(field.Type()))
+		}
+		if field.Kind() == reflect.Slice { field.Set(reflect.Zero(field.Type())) }
+	}
+}
+
+func isKind(kind string) bool {
+	for _, known := range Kinds { if known == kind { return true } }
+	return false
+}
+
+func blobName(File model.Files) string {
+	return fmt.Sprintf("%d-%s", File.ID, filepath.Base(File.Path))
+}
+
+/* An entry is read up to the size it declares, one declaring more than the limit isn't read at all */
+func read(file *zip.File, Limit uint64) ([]byte, error) {
+	if file.UncompressedSize64 > Limit { return nil, ErrTooLarge }
+
+	reader, err := file.Open()
+	if err != nil { return nil, err }
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, int64(file.UncompressedSize64) + 1))
+	if err != nil { return nil, err }
+	if uint64(len(data)) > file.UncompressedSize64 { return nil, fmt.Errorf("%w: %s", ErrChecksum, file.Name) }
+	return data, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func sign(manifest []byte) string {
+	mac := hmac.New(sha256.New, []byte(globals.Env.PACKAGE_SECRET))
+	mac.Write(manifest)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ExportBytes is Export into memory, for handlers replying with the archive.
+func ExportBytes(kinds []string) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := Export(&buffer, kinds)
+	return buffer.Bytes(), err
+}
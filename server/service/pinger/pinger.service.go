@@ -0,0 +1,236 @@
+// Package pinger tells search engines about published pages, so they're crawled soon instead of whenever the engine
+// comes by: a sitemap ping asks an engine to read APP_URL/sitemap.xml again, IndexNow submits the pages' urls.
+//
+// Engines are enabled in the settings (Ping_engines). A publish writes a "ping" message per enabled engine through
+// the outbox, in the publish's transaction, so a rolled back publish tells nobody and an engine which is down is
+// retried. Published preview channels (the "preview.published" event) ping the pages they changed.
+//
+// Notes:
+//   - Nothing is sent without APP_URL, engines can't reach localhost.
+//   - IndexNow needs INDEXNOW_KEY, its key file is served at KeyPath.
+package pinger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/domain"
+	"main/server/common/globals"
+	"main/server/common/module"
+	"main/server/common/route"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/outbox"
+)
+
+// KindPing is the outbox kind of pings, their topic is the engine's Name.
+const KindPing = "ping"
+
+// Timeout bounds a request to an engine.
+const Timeout = 10 * time.Second
+
+// KeyPath is where the IndexNow key file is served, engines fetch it to check the key is the site's.
+const KeyPath = "/indexnow-key.txt"
+
+// MaxURLs is how many urls an IndexNow submission carries at most.
+const MaxURLs = 10000
+
+// Engine is a search engine pages are announced to, by sitemap ping (Endpoint gets the sitemap's url appended) or IndexNow.
+type Engine struct {
+	Name		string
+	Label		string
+	Endpoint	string
+	IndexNow	bool
+}
+
+// Engines are the engines the settings offer.
+var Engines = []Engine{
+	{ Name: "google", Label: "Google (sitemap)", Endpoint: "https://www.google.com/ping?sitemap=" },
+	{ Name: "bing", Label: "Bing (sitemap)", Endpoint: "https://www.bing.com/ping?sitemap=" },
+	{ Name: "indexnow", Label: "IndexNow (Bing, Yandex, Seznam, Naver)", Endpoint: "https://api.indexnow.org/indexnow", IndexNow: true },
+}
+
+// Status is an engine as the settings show it, Ready tells whether it can be used (IndexNow without a key can't).
+type Status struct {
+	Engine
+	Enabled		bool
+	Ready		bool
+}
+
+// Ping is the payload of a ping message: the absolute urls of the published pages.
+type Ping struct {
+	URLs		[]string	`json:"urls"`
+}
+
+var (
+	ErrEngine = domain.NotFound("search engine not found")
+	ErrKey = domain.Invalid("INDEXNOW_KEY is missing or malformed")
+)
+
+var key = regexp.MustCompile(`^[A-Za-z0-9-]{8,128}$`)
+
+// Setup registers the ping handler on the outbox and pings the pages of published preview channels.
+func Setup(container *module.Container) {
+	outbox.Handle(KindPing, func(ctx context.Context, Message model.Outbox_messages) error {
+		Engine, ok := find(Message.Topic)
+		if !ok { return fmt.Errorf("%w: %s", ErrEngine, Message.Topic) }
+
+		var Ping Ping
+		if err := json.Unmarshal([]byte(Message.Payload), &Ping); err != nil { return err }
+		return send(ctx, Engine, Ping.URLs)
+	})
+
+	container.On("preview.published", func(ctx context.Context, payload any) error {
+		Event, ok := payload.(outbox.Event)
+		if !ok { return nil }
+
+		var Channel model.Preview_channels
+		if err := json.Unmarshal(Event.Payload, &Channel); err != nil { return err }
+		return Published(storage.DB.WithContext(ctx), Paths(Channel.Changes)...)
+	})
+}
+
+// Published pings the enabled engines about the pages at the paths ("/news/01HZ..."), within tx.
+//
+// Example usage:
+//   return ctx.Tx(func(tx *gorm.DB) error {
+//       if err := tx.Create(&News).Error; err != nil { return err }
+//       return pinger.Published(tx, route.URL("news.detail", News.Ref(News.ID)))
+//   })
+func Published(tx *gorm.DB, Paths ...string) error {
+	if globals.Env.APP_URL == "" || len(Paths) == 0 { return nil }
+
+	URLs := make([]string, 0, len(Paths))
+	for _, Path := range unique(Paths) { URLs = append(URLs, globals.Env.APP_URL + Path) }
+
+	for _, Status := range Statuses() {
+		if !Status.Enabled || !Status.Ready { continue }
+		if err := outbox.Add(tx, KindPing, Status.Name, Ping{ URLs: URLs }); err != nil { return err }
+	}
+	return nil
+}
+
+// Paths returns the pages the changes of a preview channel touch, a change of the news' list is one of the list too.
+func Paths(Changes []model.Preview_changes) []string {
+	Paths := []string{}
+	for _, Change := range Changes {
+		switch Change.Kind {
+			case "news":
+				Paths = append(Paths, route.URL("news"))
+				/* A deleted one isn't found, the list is enough */
+				var News model.News
+				if Change.RecordID != 0 && storage.DB.First(&News, Change.RecordID).Error == nil {
+					Paths = append(Paths, route.URL("news.detail", News.Ref(News.ID)))
+				}
+			case "faq": Paths = append(Paths, route.URL("faq"))
+			case "categories": Paths = append(Paths, route.URL("categories"))
+			case "about": Paths = append(Paths, route.URL("about"))
+			case "contact": Paths = append(Paths, route.URL("home"))
+		}
+	}
+	return Paths
+}
+
+// Statuses returns every engine with its settings.
+func Statuses() []Status {
+	var Rows []model.Ping_engines
+	storage.DB.Find(&Rows)
+
+	Enabled := map[string]bool{}
+	for _, Row := range Rows { Enabled[Row.Name] = Row.Enabled }
+
+	Statuses := make([]Status, 0, len(Engines))
+	for _, Engine := range Engines {
+		Statuses = append(Statuses, Status{ Engine: Engine, Enabled: Enabled[Engine.Name], Ready: !Engine.IndexNow || Key() != "" })
+	}
+	return Statuses
+}
+
+// Toggle enables or disables an engine.
+func Toggle(Name string, Enabled bool) error {
+	Engine, ok := find(Name)
+	if !ok { return ErrEngine }
+	if Enabled && Engine.IndexNow && Key() == "" { return ErrKey }
+
+	var Row model.Ping_engines
+	storage.DB.Where(&model.Ping_engines{ Name: Name }).First(&Row)
+	Row.Name, Row.Enabled = Name, Enabled
+
+	/* Save writes false too, Updates would skip it */
+	return storage.DB.Save(&Row).Error
+}
+
+// Key returns INDEXNOW_KEY, "" when it's missing or malformed.
+func Key() string {
+	if !key.MatchString(globals.Env.INDEXNOW_KEY) { return "" }
+	return globals.Env.INDEXNOW_KEY
+}
+
+func find(Name string) (Engine, bool) {
+	for _, Engine := range Engines {
+		if Engine.Name == Name { return Engine, true }
+	}
+	return Engine{}, false
+}
+
+/* IndexNow takes the urls of one host in a json body, a sitemap ping is a GET with the sitemap's url */
+func send(ctx context.Context, Engine Engine, URLs []string) error {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	var request *http.Request
+	var err error
+	if Engine.IndexNow {
+		if Key() == "" { return ErrKey }
+		if len(URLs) > MaxURLs { URLs = URLs[:MaxURLs] }
+
+		Site, err := url.Parse(globals.Env.APP_URL)
+		if err != nil { return err }
+		body, err := json.Marshal(map[string]any{
+			"host": Site.Host,
+			"key": Key(),
+			"keyLocation": globals.Env.APP_URL + KeyPath,
+			"urlList": URLs,
+		})
+		if err != nil { return err }
+
+		request, err = http.NewRequestWithContext(ctx, http.MethodPost, Engine.Endpoint, bytes.NewReader(body))
+		if err != nil { return err }
+		request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	} else {
+		request, err = http.NewRequestWithContext(ctx, http.MethodGet, Engine.Endpoint + url.QueryEscape(globals.Env.APP_URL + route.URL("sitemap")), nil)
+		if err != nil { return err }
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil { return err }
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 1 << 16))
+
+	/* IndexNow answers 202 while it checks the key, sitemap pings answer 200 */
+	if response.StatusCode >= 300 { return fmt.Errorf("%s responded with %s", Engine.Name, response.Status) }
+	log.Print("Pinged ", Engine.Name, " about ", len(URLs), " pages")
+	return nil
+}
+
+func unique(Items []string) []string {
+	Seen := map[string]bool{}
+	Unique := []string{}
+	for _, Item := range Items {
+		if Item = strings.TrimSpace(Item); Item == "" || Seen[Item] { continue }
+		Seen[Item] = true
+		Unique = append(Unique, Item)
+	}
+	return Unique
+}
@@ -0,0 +1,83 @@
+package previewer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"main/server/common/domain"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// MaxLinkExpiry bounds how long a preview link may stay valid.
+const MaxLinkExpiry = 30 * 24 * time.Hour
+
+var (
+	ErrLinkNotFound = domain.NotFound("preview link not found, expired or revoked")
+	ErrLinkPath = domain.Invalid("a preview link must point to a page of the site, e.g. /news/12")
+)
+
+// Share makes a link showing one page of an open channel to someone without an account (Label tells who),
+// until the expiry has passed or it's revoked. The page is shown read only: only GET requests of its Path
+// are previewed, the rest of the site is the live one.
+//
+// Example usage:
+//   Link, err := previewer.Share(Channel.ID, "/news/12", "Marketing agency", 72 * time.Hour)
+//   if err != nil { return err }
+//   URL := globals.Env.APP_URL + "/preview/link/" + Link.Token
+//
+// Notes:
+//   - The expiry is capped at MaxLinkExpiry. Publishing or discarding the channel ends its links too.
+func Share(ChannelID uint, Path string, Label string, Expiry time.Duration) (model.Preview_links, error) {
+	var Channel model.Preview_channels
+	var Link model.Preview_links
+
+	Path = strings.TrimSpace(Path)
+	if !strings.HasPrefix(Path, "/") || strings.HasPrefix(Path, "//") || strings.HasPrefix(Path, "/admin") { return Link, ErrLinkPath }
+	if err := storage.DB.First(&Channel, ChannelID).Error; err != nil { return Link, ErrNotFound }
+	if Channel.Status != Open { return Link, ErrClosed }
+
+	random := make([]byte, 24)
+	rand.Read(random)
+
+	Link = model.Preview_links{
+		ChannelID: ChannelID,
+		Token: hex.EncodeToString(random),
+		Path: Path,
+		Label: Label,
+		ExpiresAt: time.Now().Add(min(Expiry, MaxLinkExpiry)),
+	}
+	return Link, storage.DB.Create(&Link).Error
+}
+
+// FindLink returns a link by its token with its channel, as long as neither the link nor the channel ended.
+func FindLink(Token string) (model.Preview_links, model.Preview_channels, error) {
+	var Link model.Preview_links
+	if Token == "" { return Link, model.Preview_channels{}, ErrLinkNotFound }
+
+	result := storage.DB.Where("token = ? AND revoked_at IS NULL AND expires_at > ?", Token, time.Now()).Last(&Link)
+	if result.Error != nil { return Link, model.Preview_channels{}, ErrLinkNotFound }
+
+	var Channel model.Preview_channels
+	result = storage.DB.Where(&model.Preview_channels{ Status: Open }).First(&Channel, Link.ChannelID)
+	if result.Error != nil { return Link, Channel, ErrLinkNotFound }
+	return Link, Channel, nil
+}
+
+// Revoke ends a link before its expiry.
+func Revoke(LinkID uint) error {
+	result := storage.DB.Model(&model.Preview_links{}).
+		Where("id = ? AND revoked_at IS NULL", LinkID).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil { return result.Error }
+	if result.RowsAffected == 0 { return ErrLinkNotFound }
+	return nil
+}
+
+// Visit records a request previewed through the link, who made it is kept for the channel's owners to see.
+func Visit(Link model.Preview_links, Path string, IP string, UserAgent string) error {
+	return storage.DB.Create(&model.Preview_visits{ LinkID: Link.ID, Path: Path, IP: IP, UserAgent: UserAgent }).Error
+}
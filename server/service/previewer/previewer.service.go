@@ -0,0 +1,145 @@
+// Package previewer stages draft changes into preview channels.
+//
+// A channel is a batch of changes (create, update or delete of a content record) which isn't applied to the site
+// until the channel is published. Visiting /preview/:token marks the browser with the channel, from then on
+// middleware.Preview applies the channel's changes inside a transaction which is rolled back after the request,
+// so the site renders as it will look once published. Publishing applies the same changes in a committed
+// transaction, all of them or none.
+package previewer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/domain"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/outbox"
+)
+
+const (
+	Open		= model.PreviewOpen
+	Published	= model.PreviewPublished
+	Discarded	= model.PreviewDiscarded
+)
+
+// Kinds lists the kinds of content a channel can change.
+var Kinds = []string{"news", "faq", "categories", "contact", "about", "interface", "mail", "social"}
+
+var (
+	ErrKind = domain.UnsupportedType("unknown kind")
+	ErrClosed = domain.Conflict("preview channel is already published or discarded")
+	ErrNotFound = domain.NotFound("preview channel not found")
+)
+
+// Create opens a new channel.
+func Create(Name string) (model.Preview_channels, error) {
+	random := make([]byte, 24)
+	rand.Read(random)
+
+	Channel := model.Preview_channels{ Name: Name, Token: hex.EncodeToString(random), Status: Open }
+	return Channel, storage.DB.Create(&Channel).Error
+}
+
+// Find returns an open channel by its token.
+func Find(Token string) (model.Preview_channels, error) {
+	var Channel model.Preview_channels
+	if Token == "" { return Channel, ErrNotFound }
+
+	result := storage.DB.Where(&model.Preview_channels{Token: Token, Status: Open}).Last(&Channel)
+	if result.Error != nil { return Channel, ErrNotFound }
+	return Channel, nil
+}
+
+// Stage adds a change to an open channel.
+// RecordID 0 creates a new record from Fields, otherwise the record is updated with Fields, or deleted when Delete is set.
+// Fields are keyed by the model's field names, e.g. {"Title": "...", "Public": true}.
+func Stage(ChannelID uint, Kind string, RecordID uint, Delete bool, Fields map[string]interface{}) (model.Preview_changes, error) {
+	var Channel model.Preview_channels
+	var Change model.Preview_changes
+
+	if _, err := record(Kind); err != nil { return Change, err }
+	if err := storage.DB.First(&Channel, ChannelID).Error; err != nil { return Change, ErrNotFound }
+	if Channel.Status != Open { return Change, ErrClosed }
+
+	Payload, err := json.Marshal(Fields)
+	if err != nil { return Change, err }
+
+	Change = model.Preview_changes{ ChannelID: ChannelID, Kind: Kind, RecordID: RecordID, Delete: Delete, Payload: string(Payload) }
+	return Change, storage.DB.Create(&Change).Error
+}
+
+// Apply applies the channel's changes, in the order they were staged, on the given transaction.
+// Committing or rolling it back is up to the caller.
+func Apply(tx *gorm.DB, ChannelID uint) error {
+	var Changes []model.Preview_changes
+	if err := tx.Where(&model.Preview_changes{ChannelID: ChannelID}).Order("id asc").Find(&Changes).Error; err != nil { return err }
+
+	for _, Change := range Changes {
+		if err := apply(tx, Change); err != nil { return fmt.Errorf("change #%d (%s): %w", Change.ID, Change.Kind, err) }
+	}
+	return nil
+}
+
+// Publish applies the channel to the site atomically and closes it, "preview.published" is emitted through the outbox.
+func Publish(ChannelID uint) error {
+	return storage.DB.Transaction(func(tx *gorm.DB) error {
+		var Channel model.Preview_channels
+		if err := tx.Preload("Changes").First(&Channel, ChannelID).Error; err != nil { return ErrNotFound }
+		if Channel.Status != Open { return ErrClosed }
+
+		if err := Apply(tx, ChannelID); err != nil { return err }
+
+		Now := time.Now()
+		if err := tx.Model(&Channel).Updates(map[string]interface{}{ "Status": Published, "PublishedAt": &Now }).Error; err != nil { return err }
+		return outbox.Emit(tx, "preview.published", Channel)
+	})
+}
+
+// Discard closes the channel without applying it.
+func Discard(ChannelID uint) error {
+	result := storage.DB.Model(&model.Preview_channels{}).
+		Where("id = ? AND status = ?", ChannelID, Open).
+		Update("status", Discarded)
+
+	if result.Error != nil { return result.Error }
+	if result.RowsAffected == 0 { return ErrClosed }
+	return nil
+}
+
+func apply(tx *gorm.DB, Change model.Preview_changes) error {
+	Record, err := record(Change.Kind)
+	if err != nil { return err }
+
+	if Change.Delete { return tx.Delete(Record, Change.RecordID).Error }
+
+	var Fields map[string]interface{}
+	if err := json.Unmarshal([]byte(Change.Payload), &Fields); err != nil { return err }
+	if len(Fields) == 0 { return nil }
+
+	if Change.RecordID == 0 { return tx.Model(Record).Create(Fields).Error }
+
+	result := tx.Model(Record).Where("id = ?", Change.RecordID).Updates(Fields)
+	if result.Error != nil { return result.Error }
+	if result.RowsAffected == 0 { return gorm.ErrRecordNotFound }
+	return nil
+}
+
+func record(Kind string) (any, error) {
+	switch Kind {
+		case "news": return &model.News{}, nil
+		case "faq": return &model.Faq{}, nil
+		case "categories": return &model.Categories{}, nil
+		case "contact": return &model.Interface_contact{}, nil
+		case "about": return &model.Interface_about{}, nil
+		case "interface": return &model.Interface{}, nil
+		case "mail": return &model.Interface_mail{}, nil
+		case "social": return &model.Social_media{}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKind, Kind)
+}
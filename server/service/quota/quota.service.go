@@ -0,0 +1,265 @@
+// Package quota bounds the bytes of uploads each signed in user stores. A user's quota is their own (Upload_quotas
+// with UsersID), else the largest of their roles' (RolesID), else UPLOAD_QUOTA. 0 bytes is unlimited, and so is
+// a role's quota when one of the user's roles has no limit.
+//
+// Usage is the size of the files the user uploaded (Files.OwnerID) and the length of their resumable uploads in
+// progress, counted from the database whenever it's checked.
+//
+// Notes:
+//   - Anonymous uploads and the files admins attach through the settings forms aren't owned, nobody is charged for them.
+//   - The charges of a user (Own, Reserve) queue on their row and each one counts the ones before it, uploads
+//     stored at the same time can't exceed the quota together. Check only refuses early, before the file is stored.
+package quota
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"main/server/common/domain"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// Where a quota comes from.
+const (
+	SourceUser		= "user"
+	SourceRole		= "role"
+	SourceDefault	= "default"
+)
+
+var (
+	ErrExceeded = domain.Forbidden("quota", "upload quota exceeded")
+	ErrNotFound = domain.NotFound("user or role not found")
+	ErrBytes = domain.Invalid("quota bytes can't be negative")
+)
+
+// Quota is a user's quota and usage, Remaining is meaningless when it's Unlimited.
+type Quota struct {
+	Limit		int64		`json:"limit"`
+	Used		int64		`json:"used"`
+	Remaining	int64		`json:"remaining"`
+	Unlimited	bool		`json:"unlimited"`
+	Source		string		`json:"source"`
+}
+
+// Usage is a user's quota, as the admin lists it.
+type Usage struct {
+	UsersID		uint		`json:"userId"`
+	Email		string		`json:"email"`
+	Quota		Quota		`json:"quota"`
+}
+
+// RoleQuota is the quota of each user of a role, Bytes is nil when the role has none.
+type RoleQuota struct {
+	RolesID		uint		`json:"roleId"`
+	Name		string		`json:"name"`
+	Bytes		*int64		`json:"bytes"`
+}
+
+// Of returns the quota of the user.
+func Of(UsersID uint) (Quota, error) {
+	return of(storage.DB, UsersID)
+}
+
+func of(db *gorm.DB, UsersID uint) (Quota, error) {
+	Quota, err := limit(db, UsersID)
+	if err != nil { return Quota, err }
+
+	var Stored, Pending int64
+	if err := db.Model(&model.Files{}).Where("owner_id = ?", UsersID).Select("COALESCE(SUM(size), 0)").Scan(&Stored).Error; err != nil { return Quota, err }
+	if err := db.Model(&model.Resumable_uploads{}).Where("owner_id = ? AND file_id IS NULL", UsersID).Select("COALESCE(SUM(length), 0)").Scan(&Pending).Error; err != nil { return Quota, err }
+
+	return used(Quota, Stored + Pending), nil
+}
+
+// Check returns the user's quota, and ErrExceeded when Bytes more don't fit in it.
+//
+// Example usage:
+//   if Quota, err := quota.Check(User.ID, Size); err != nil {
+//       return ctx.JSON(http.StatusForbidden, Quota)
+//   }
+func Check(UsersID uint, Bytes int64) (Quota, error) {
+	Quota, err := Of(UsersID)
+	if err != nil { return Quota, err }
+	if !Quota.Unlimited && Bytes > Quota.Remaining { return Quota, exceeded(Quota, Bytes) }
+	return Quota, nil
+}
+
+// Own charges the file to the user, when its size fits in their quota.
+//
+// Returns:
+//   - ErrExceeded with the quota when it doesn't, the file stays unowned.
+func Own(FileID uint, UsersID uint) (Quota, error) {
+	return charge(UsersID, func(tx *gorm.DB, Quota Quota) (bool, error) {
+		Query := tx.Model(&model.Files{}).Where("id = ?", FileID)
+		if !Quota.Unlimited { Query = Query.Where("size <= ?", Quota.Remaining) }
+		Result := Query.Update(model.FilesOwnerID, UsersID)
+		return Result.RowsAffected > 0, Result.Error
+	})
+}
+
+// Reserve creates the resumable upload of its owner, when its length fits in their quota. It's counted from then on,
+// the file it completes into is already paid for.
+//
+// Returns:
+//   - ErrExceeded with the quota when it doesn't, the upload isn't created.
+func Reserve(Upload *model.Resumable_uploads) (Quota, error) {
+	return charge(*Upload.OwnerID, func(tx *gorm.DB, Quota Quota) (bool, error) {
+		if !Quota.Unlimited && Upload.Length > Quota.Remaining { return false, nil }
+		return true, tx.Create(Upload).Error
+	})
+}
+
+/* Runs the charge with the user's row locked, so the usage it's given can't change until it's written. The charge
+   reports whether it fit */
+func charge(UsersID uint, fn func(tx *gorm.DB, Quota Quota) (bool, error)) (Quota, error) {
+	var Quota Quota
+	var Fits bool
+	err := storage.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{ Strength: "UPDATE" }).Select("id").First(&model.Users{}, UsersID).Error; err != nil { return err }
+
+		var err error
+		if Quota, err = of(tx, UsersID); err != nil { return err }
+		Fits, err = fn(tx, Quota)
+		return err
+	})
+	if err != nil { return Quota, err }
+	if !Fits { return Quota, exceeded(Quota, 0) }
+	return Quota, nil
+}
+
+func exceeded(Quota Quota, Bytes int64) error {
+	if Bytes == 0 { return fmt.Errorf("%w: %d bytes left", ErrExceeded, Quota.Remaining) }
+	return fmt.Errorf("%w: %d bytes left, %d sent", ErrExceeded, Quota.Remaining, Bytes)
+}
+
+func used(Quota Quota, Used int64) Quota {
+	Quota.Used = Used
+	if !Quota.Unlimited { Quota.Remaining = max(0, Quota.Limit - Quota.Used) }
+	return Quota
+}
+
+// SetUser gives the user their own quota, nil removes it and the user's roles decide again.
+func SetUser(UsersID uint, Bytes *int64) error {
+	if err := storage.DB.Select("id").First(&model.Users{}, UsersID).Error; err != nil { return ErrNotFound }
+	return set(&model.Upload_quotas{ UsersID: &UsersID }, Bytes)
+}
+
+// SetRole sets the quota of each user of the role, nil removes it.
+func SetRole(RolesID uint, Bytes *int64) error {
+	if err := storage.DB.Select("id").First(&model.Roles{}, RolesID).Error; err != nil { return ErrNotFound }
+	return set(&model.Upload_quotas{ RolesID: &RolesID }, Bytes)
+}
+
+// Users returns the quota of every user, counted with a few queries whatever their number.
+func Users() ([]Usage, error) {
+	var Users []model.Users
+	if err := storage.DB.Select("id", "email").Order("id").Find(&Users).Error; err != nil { return nil, err }
+
+	var Stored, Pending []struct {
+		OwnerID		uint
+		Bytes		int64
+	}
+	err := storage.DB.Model(&model.Files{}).Select("owner_id, SUM(size) AS bytes").Where("owner_id IS NOT NULL").Group("owner_id").Scan(&Stored).Error
+	if err != nil { return nil, err }
+	err = storage.DB.Model(&model.Resumable_uploads{}).Select("owner_id, SUM(length) AS bytes").Where("owner_id IS NOT NULL AND file_id IS NULL").Group("owner_id").Scan(&Pending).Error
+	if err != nil { return nil, err }
+	Used := map[uint]int64{}
+	for _, Row := range append(Stored, Pending...) { Used[Row.OwnerID] += Row.Bytes }
+
+	var Quotas []model.Upload_quotas
+	if err := storage.DB.Find(&Quotas).Error; err != nil { return nil, err }
+	var Memberships []struct {
+		UsersID		uint
+		RolesID		uint
+	}
+	if err := storage.DB.Table("user_roles").Select("users_id", "roles_id").Scan(&Memberships).Error; err != nil { return nil, err }
+
+	Own := map[uint]model.Upload_quotas{}
+	ByRole := map[uint]model.Upload_quotas{}
+	for _, Row := range Quotas {
+		if Row.UsersID != nil { Own[*Row.UsersID] = Row }
+		if Row.RolesID != nil { ByRole[*Row.RolesID] = Row }
+	}
+	Roles := map[uint][]model.Upload_quotas{}
+	for _, Membership := range Memberships {
+		if Row, ok := ByRole[Membership.RolesID]; ok { Roles[Membership.UsersID] = append(Roles[Membership.UsersID], Row) }
+	}
+
+	Usages := make([]Usage, 0, len(Users))
+	for _, User := range Users {
+		Quota := resolve(nil, Roles[User.ID])
+		if Row, ok := Own[User.ID]; ok { Quota = resolve(&Row, nil) }
+		Usages = append(Usages, Usage{ UsersID: User.ID, Email: User.Email, Quota: used(Quota, Used[User.ID]) })
+	}
+	return Usages, nil
+}
+
+// Roles returns every role with its quota.
+func Roles() ([]RoleQuota, error) {
+	var Roles []model.Roles
+	if err := storage.DB.Order("id").Find(&Roles).Error; err != nil { return nil, err }
+
+	var Rows []model.Upload_quotas
+	if err := storage.DB.Where("roles_id IS NOT NULL").Find(&Rows).Error; err != nil { return nil, err }
+	Limits := map[uint]int64{}
+	for _, Row := range Rows { Limits[*Row.RolesID] = Row.Bytes }
+
+	Quotas := make([]RoleQuota, 0, len(Roles))
+	for _, Role := range Roles {
+		Quota := RoleQuota{ RolesID: Role.ID, Name: Role.Name }
+		if Bytes, ok := Limits[Role.ID]; ok { Quota.Bytes = &Bytes }
+		Quotas = append(Quotas, Quota)
+	}
+	return Quotas, nil
+}
+
+// Default returns UPLOAD_QUOTA, the quota of users without another one.
+func Default() Quota {
+	return Quota{ Limit: globals.Env.UPLOAD_QUOTA, Unlimited: globals.Env.UPLOAD_QUOTA == 0, Source: SourceDefault }
+}
+
+/* The user's own quota, else the most generous of their roles', else the default */
+func limit(db *gorm.DB, UsersID uint) (Quota, error) {
+	var Own model.Upload_quotas
+	err := db.Where("users_id = ?", UsersID).First(&Own).Error
+	if err == nil { return resolve(&Own, nil), nil }
+	if !errors.Is(err, gorm.ErrRecordNotFound) { return Quota{}, err }
+
+	var Roles []model.Upload_quotas
+	Query := db.Where("roles_id IN (?)", db.Table("user_roles").Select("roles_id").Where("users_id = ?", UsersID))
+	if err := Query.Find(&Roles).Error; err != nil { return Quota{}, err }
+	return resolve(nil, Roles), nil
+}
+
+/* The quota of a user with their own row, or with the rows of their roles */
+func resolve(Own *model.Upload_quotas, Roles []model.Upload_quotas) Quota {
+	if Own != nil { return Quota{ Limit: Own.Bytes, Unlimited: Own.Bytes == 0, Source: SourceUser } }
+	if len(Roles) == 0 { return Default() }
+
+	Quota := Quota{ Source: SourceRole }
+	for _, Role := range Roles {
+		if Role.Bytes == 0 {
+			Quota.Unlimited = true
+			return Quota
+		}
+		Quota.Limit = max(Quota.Limit, Role.Bytes)
+	}
+	return Quota
+}
+
+func set(Where *model.Upload_quotas, Bytes *int64) error {
+	if Bytes == nil { return storage.DB.Unscoped().Where(Where).Delete(&model.Upload_quotas{}).Error }
+	if *Bytes < 0 { return ErrBytes }
+
+	var Row model.Upload_quotas
+	storage.DB.Where(Where).First(&Row)
+	Row.UsersID, Row.RolesID, Row.Bytes = Where.UsersID, Where.RolesID, *Bytes
+
+	/* Save writes 0 too, Updates would skip it */
+	return storage.DB.Save(&Row).Error
+}
@@ -0,0 +1,296 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"main/server/common/controller"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+/*
+	A file is imported over the current setup: its permissions and roles are created or updated, and the users it
+	assigns get exactly its roles. What it leaves out stays as it is, unless it's imported with Prune: then it's the
+	whole setup and the permissions and roles it leaves out are removed, as are the roles of users it leaves out.
+
+	Users are matched by email, the ones this database doesn't have are skipped: environments don't share users.
+*/
+
+// Change is one difference between the current setup and the imported one.
+type Change struct {
+	Action		string		/* "+" added, "~" changed, "-" removed */
+	Kind		string		/* permission, role or user */
+	Name		string
+	Detail		string		/* what changed, "+files.audit -files.share" */
+}
+
+func (Item Change) String() string {
+	if Item.Detail == "" { return fmt.Sprintf("%s %s %s", Item.Action, Item.Kind, Item.Name) }
+	return fmt.Sprintf("%s %s %s: %s", Item.Action, Item.Kind, Item.Name, Item.Detail)
+}
+
+// Diff is what importing a setup would change (Plan) or changed (Apply).
+type Diff struct {
+	Changes		[]Change
+	Skipped		[]string	/* users of the file this database doesn't have */
+	Problems	[]string	/* why the file can't be imported, ErrInvalid is returned with them */
+}
+
+/* A setup by name: permissions' descriptions, roles' permissions and users' roles (sorted) */
+type state struct {
+	Permissions		map[string]string
+	Roles			map[string][]string
+	Users			map[string][]string
+}
+
+// Plan validates a setup against the database and tells what importing it would change, without changing anything.
+//
+// Returns:
+//   - ErrInvalid with the Diff's Problems when it can't be imported as it is.
+func Plan(Setup Config, Prune bool) (Diff, error) {
+	Planned, _, _, err := plan(storage.DB, Setup, Prune)
+	return Planned, err
+}
+
+// Apply imports a setup, planned again in the transaction which applies it so nothing changes in between.
+//
+// Example usage:
+//   Diff, err := rbac.Apply(Setup, false)
+func Apply(Setup Config, Prune bool) (Diff, error) {
+	var Planned Diff
+	err := storage.DB.Transaction(func(tx *gorm.DB) error {
+		Result, Current, Target, err := plan(tx, Setup, Prune)
+		Planned = Result
+		if err != nil { return err }
+		return apply(tx, Current, Target)
+	})
+	return Planned, err
+}
+
+func plan(tx *gorm.DB, Setup Config, Prune bool) (Diff, state, state, error) {
+	var Result Diff
+	Current, err := current(tx)
+	if err != nil { return Result, state{}, state{}, err }
+
+	var Emails []string
+	if err := tx.Model(&model.Users{}).Pluck(model.UsersEmail, &Emails).Error; err != nil { return Result, state{}, state{}, err }
+	Known := map[string]bool{}
+	for _, Email := range Emails { Known[Email] = true }
+
+	Target := state{ Permissions: map[string]string{}, Roles: map[string][]string{}, Users: map[string][]string{} }
+	if !Prune { Target = state{ Permissions: maps.Clone(Current.Permissions), Roles: maps.Clone(Current.Roles), Users: maps.Clone(Current.Users) } }
+
+	Result.Problems = check(Setup)
+	for _, Permission := range Setup.Permissions { Target.Permissions[Permission.Name] = Permission.Description }
+	for _, Role := range Setup.Roles { Target.Roles[Role.Name] = sorted(Role.Permissions) }
+	for _, Assignment := range Setup.Assignments {
+		if !Known[Assignment.User] {
+			Result.Skipped = append(Result.Skipped, Assignment.User)
+			continue
+		}
+		Target.Users[Assignment.User] = sorted(Assignment.Roles)
+		if len(Assignment.Roles) == 0 { delete(Target.Users, Assignment.User) }
+	}
+
+	Result.Problems = append(Result.Problems, Target.check(Current)...)
+	Result.Changes = changes(Current, Target)
+	if len(Result.Problems) > 0 { return Result, Current, Target, ErrInvalid }
+	return Result, Current, Target, nil
+}
+
+func current(tx *gorm.DB) (state, error) {
+	Current := state{ Permissions: map[string]string{}, Roles: map[string][]string{}, Users: map[string][]string{} }
+	var Permissions []model.Permissions
+	var Roles []model.Roles
+	var Users []model.Users
+	if err := tx.Find(&Permissions).Error; err != nil { return Current, err }
+	if err := tx.Preload("Permissions").Find(&Roles).Error; err != nil { return Current, err }
+	if err := tx.Preload("Roles").Find(&Users).Error; err != nil { return Current, err }
+
+	for _, Row := range Permissions { Current.Permissions[Row.Name] = Row.Description }
+	for _, Row := range Roles {
+		Names := []string{}
+		for _, Granted := range Row.Permissions { Names = append(Names, Granted.Name) }
+		Current.Roles[Row.Name] = sorted(Names)
+	}
+	for _, Row := range Users {
+		if len(Row.Roles) == 0 { continue }
+		Names := []string{}
+		for _, Granted := range Row.Roles { Names = append(Names, Granted.Name) }
+		Current.Users[Row.Email] = sorted(Names)
+	}
+	return Current, nil
+}
+
+/* The file on its own: names are given and given once */
+func check(Setup Config) []string {
+	Problems := []string{}
+	duplicate := func(Kind string) func(string) {
+		Seen := map[string]bool{}
+		return func(Name string) {
+			if strings.TrimSpace(Name) == "" { Problems = append(Problems, Kind + " without a name") }
+			if Seen[Name] { Problems = append(Problems, fmt.Sprintf("%s %s is given twice", Kind, Name)) }
+			Seen[Name] = true
+		}
+	}
+
+	Permission := duplicate("permission")
+	for _, Item := range Setup.Permissions { Permission(Item.Name) }
+	Role := duplicate("role")
+	for _, Item := range Setup.Roles { Role(Item.Name) }
+	User := duplicate("user")
+	for _, Item := range Setup.Assignments { User(Item.User) }
+	return Problems
+}
+
+/* The setup once imported: what roles grant and users have exists, and whoever administered the site still can */
+func (Target state) check(Current state) []string {
+	Problems := []string{}
+	for _, Role := range keys(Target.Roles) {
+		for _, Permission := range Target.Roles[Role] {
+			if _, ok := Target.Permissions[Permission]; !ok { Problems = append(Problems, fmt.Sprintf("role %s: permission %s isn't defined", Role, Permission)) }
+		}
+	}
+	for _, User := range keys(Target.Users) {
+		for _, Role := range Target.Users[User] {
+			if _, ok := Target.Roles[Role]; !ok { Problems = append(Problems, fmt.Sprintf("user %s: role %s isn't defined", User, Role)) }
+		}
+	}
+
+	if administered(Current) && !administered(Target) {
+		Problems = append(Problems, fmt.Sprintf("no user would have the %s role, nobody could administer the site", controller.SuperRole))
+	}
+	return Problems
+}
+
+func administered(Setup state) bool {
+	for _, Roles := range Setup.Users {
+		if slices.Contains(Roles, controller.SuperRole) { return true }
+	}
+	return false
+}
+
+func changes(Current state, Target state) []Change {
+	Changes := []Change{}
+	for _, Name := range union(Current.Permissions, Target.Permissions) {
+		Before, Was := Current.Permissions[Name]
+		After, Is := Target.Permissions[Name]
+		switch {
+			case !Was: Changes = append(Changes, Change{ "+", "permission", Name, After })
+			case !Is: Changes = append(Changes, Change{ "-", "permission", Name, "" })
+			case Before != After: Changes = append(Changes, Change{ "~", "permission", Name, fmt.Sprintf("%q → %q", Before, After) })
+		}
+	}
+	Changes = append(Changes, listed("role", Current.Roles, Target.Roles)...)
+	return append(Changes, listed("user", Current.Users, Target.Users)...)
+}
+
+/* Changes of a name's list, a role's permissions or a user's roles */
+func listed(Kind string, Current map[string][]string, Target map[string][]string) []Change {
+	Changes := []Change{}
+	for _, Name := range union(Current, Target) {
+		Before, Was := Current[Name]
+		After, Is := Target[Name]
+		Detail := []string{}
+		for _, Item := range After {
+			if !slices.Contains(Before, Item) { Detail = append(Detail, "+" + Item) }
+		}
+		for _, Item := range Before {
+			if !slices.Contains(After, Item) { Detail = append(Detail, "-" + Item) }
+		}
+
+		switch {
+			case !Was: Changes = append(Changes, Change{ "+", Kind, Name, strings.Join(Detail, " ") })
+			case !Is: Changes = append(Changes, Change{ "-", Kind, Name, strings.Join(Detail, " ") })
+			case len(Detail) > 0: Changes = append(Changes, Change{ "~", Kind, Name, strings.Join(Detail, " ") })
+		}
+	}
+	return Changes
+}
+
+/*
+	Permissions and roles are created before they're granted and removed once nothing grants them anymore. They're
+	removed for good: their names are unique, a soft-deleted one would keep its name from being used again.
+*/
+func apply(tx *gorm.DB, Current state, Target state) error {
+	Permissions := map[string]model.Permissions{}
+	for Name, Description := range Target.Permissions {
+		Row := model.Permissions{ Name: Name }
+		if err := restore(tx, &Row, Name); err != nil { return err }
+		Row.Description = Description
+		if err := tx.Save(&Row).Error; err != nil { return err }
+		Permissions[Name] = Row
+	}
+
+	Roles := map[string]model.Roles{}
+	for Name, Granted := range Target.Roles {
+		Row := model.Roles{ Name: Name }
+		if err := restore(tx, &Row, Name); err != nil { return err }
+		if err := tx.Save(&Row).Error; err != nil { return err }
+		Roles[Name] = Row
+
+		if Before, ok := Current.Roles[Name]; ok && slices.Equal(Before, Granted) { continue }
+		Rows := []model.Permissions{}
+		for _, Permission := range Granted { Rows = append(Rows, Permissions[Permission]) }
+		if err := tx.Model(&Row).Association("Permissions").Replace(Rows); err != nil { return err }
+	}
+
+	for _, Email := range union(Current.Users, Target.Users) {
+		if slices.Equal(Current.Users[Email], Target.Users[Email]) { continue }
+		var User model.Users
+		if err := tx.Where(&model.Users{ Email: Email }).First(&User).Error; err != nil { return err }
+		Rows := []model.Roles{}
+		for _, Role := range Target.Users[Email] { Rows = append(Rows, Roles[Role]) }
+		if err := tx.Model(&User).Association("Roles").Replace(Rows); err != nil { return err }
+	}
+
+	for Name := range Current.Roles {
+		if _, ok := Target.Roles[Name]; ok { continue }
+		var Row model.Roles
+		if err := tx.Where("name = ?", Name).First(&Row).Error; err != nil { return err }
+		if err := tx.Model(&Row).Association("Permissions").Clear(); err != nil { return err }
+		if err := tx.Unscoped().Delete(&Row).Error; err != nil { return err }
+	}
+	for Name := range Current.Permissions {
+		if _, ok := Target.Permissions[Name]; ok { continue }
+		if err := tx.Unscoped().Where("name = ?", Name).Delete(&model.Permissions{}).Error; err != nil { return err }
+	}
+	return nil
+}
+
+/* The row of a name into Row, a soft-deleted one is restored. Row is left as it was (named) when there's none */
+func restore(tx *gorm.DB, Row any, Name string) error {
+	err := tx.Unscoped().Where("name = ?", Name).First(Row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) { return nil }
+	if err != nil { return err }
+	return tx.Unscoped().Model(Row).Where("deleted_at IS NOT NULL").Update("deleted_at", nil).Error
+}
+
+func union[V any](Current map[string]V, Target map[string]V) []string {
+	Names := keys(Current)
+	for Name := range Target {
+		if _, ok := Current[Name]; !ok { Names = append(Names, Name) }
+	}
+	slices.Sort(Names)
+	return Names
+}
+
+func keys[V any](Map map[string]V) []string {
+	Names := make([]string, 0, len(Map))
+	for Name := range Map { Names = append(Names, Name) }
+	slices.Sort(Names)
+	return Names
+}
+
+func sorted(Names []string) []string {
+	Sorted := slices.Clone(Names)
+	if Sorted == nil { Sorted = []string{} }
+	slices.Sort(Sorted)
+	return slices.Compact(Sorted)
+}
@@ -0,0 +1,122 @@
+// Package rbac exports the access-control setup (permissions, roles and which users have them) to JSON or YAML and
+// imports it again, so a setup made in one environment can be promoted to another and kept in the repository.
+//
+// An import is planned before it's applied: Plan validates the file against the database and tells what would
+// change, Apply makes those changes in one transaction. See cmd/rbac.
+package rbac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"main/server/common/domain"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+var (
+	ErrFormat = domain.Invalid("rbac: unknown format, json or yaml")
+	ErrInvalid = domain.Invalid("rbac: the configuration is invalid")
+)
+
+// Config is the access-control setup as exported and imported, everything in it is sorted by name so exports of
+// the same setup are the same file.
+type Config struct {
+	Permissions		[]Permission	`json:"permissions" yaml:"permissions"`
+	Roles			[]Role			`json:"roles" yaml:"roles"`
+	Assignments		[]Assignment	`json:"assignments" yaml:"assignments"`
+}
+
+type Permission struct {
+	Name			string			`json:"name" yaml:"name"`
+	Description		string			`json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type Role struct {
+	Name			string			`json:"name" yaml:"name"`
+	Permissions		[]string		`json:"permissions" yaml:"permissions"`
+}
+
+/* Users are named by email, their IDs differ between environments */
+type Assignment struct {
+	User			string			`json:"user" yaml:"user"`
+	Roles			[]string		`json:"roles" yaml:"roles"`
+}
+
+type Format string
+
+const (
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+// FormatOf is the format of a file by its extension, YAML unless it's .json.
+func FormatOf(Name string) Format {
+	if strings.ToLower(path.Ext(Name)) == ".json" { return JSON }
+	return YAML
+}
+
+// Export reads the current setup. Users without roles aren't assigned anything, so they're left out.
+func Export() (Config, error) {
+	var Permissions []model.Permissions
+	var Roles []model.Roles
+	var Users []model.Users
+	if err := storage.DB.Order("name").Find(&Permissions).Error; err != nil { return Config{}, err }
+	if err := storage.DB.Preload("Permissions").Order("name").Find(&Roles).Error; err != nil { return Config{}, err }
+	if err := storage.DB.Preload("Roles").Order("email").Find(&Users).Error; err != nil { return Config{}, err }
+
+	Exported := Config{ Permissions: []Permission{}, Roles: []Role{}, Assignments: []Assignment{} }
+	for _, Row := range Permissions {
+		Exported.Permissions = append(Exported.Permissions, Permission{ Name: Row.Name, Description: Row.Description })
+	}
+	for _, Row := range Roles {
+		Names := []string{}
+		for _, Granted := range Row.Permissions { Names = append(Names, Granted.Name) }
+		slices.Sort(Names)
+		Exported.Roles = append(Exported.Roles, Role{ Name: Row.Name, Permissions: Names })
+	}
+	for _, User := range Users {
+		if len(User.Roles) == 0 { continue }
+		Names := []string{}
+		for _, Granted := range User.Roles { Names = append(Names, Granted.Name) }
+		slices.Sort(Names)
+		Exported.Assignments = append(Exported.Assignments, Assignment{ User: User.Email, Roles: Names })
+	}
+	return Exported, nil
+}
+
+// Marshal writes a setup in the format.
+func Marshal(Setup Config, As Format) ([]byte, error) {
+	switch As {
+		case JSON:
+			Content, err := json.MarshalIndent(Setup, "", "  ")
+			return append(Content, '\n'), err
+		case YAML: return yaml.Marshal(Setup)
+	}
+	return nil, ErrFormat
+}
+
+// Unmarshal reads a setup in the format, fields it doesn't know are refused: a typo mustn't pass for an empty list.
+func Unmarshal(Content []byte, As Format) (Config, error) {
+	var Setup Config
+	var err error
+	switch As {
+		case JSON:
+			Decoder := json.NewDecoder(bytes.NewReader(Content))
+			Decoder.DisallowUnknownFields()
+			err = Decoder.Decode(&Setup)
+		case YAML:
+			Decoder := yaml.NewDecoder(bytes.NewReader(Content))
+			Decoder.KnownFields(true)
+			err = Decoder.Decode(&Setup)
+		default: return Setup, ErrFormat
+	}
+	if err != nil { return Setup, fmt.Errorf("%w: %v", ErrInvalid, err) }
+	return Setup, nil
+}
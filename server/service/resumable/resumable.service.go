@@ -0,0 +1,193 @@
+// Package resumable keeps the state of resumable uploads (the tus protocol, https://tus.io/protocols/resumable-upload).
+//
+// An upload is created with its final length, then its chunks are appended in order, each one at the offset the
+// previous one ended. A client whose connection dropped asks for the offset and resumes from there instead of
+// starting over. Chunks are appended to a local part file, the completed file goes through the same pipeline
+// as a form upload (uploader.Store): hashing, type check, scan, storage and its Files record.
+package resumable
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/domain"
+	uploader "main/server/common/helpers"
+	"main/server/common/progress"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/filetypes"
+	"main/server/service/quota"
+)
+
+// Expiry is how long an unfinished upload is kept after its creation.
+const Expiry = 24 * time.Hour
+
+var (
+	ErrNotFound = domain.NotFound("upload not found")
+	ErrOffset = domain.Conflict("upload offset doesn't match")
+	ErrFinished = domain.Conflict("upload is already finished")
+	ErrRejected = errors.New("upload is rejected")
+)
+
+// Dir holds the part files of the uploads in progress.
+var Dir = filepath.Join(os.TempDir(), "yacco-uploads")
+
+/* Appends to the same upload are serialized, a retried chunk may race the one it replaces */
+var locks sync.Map
+
+// Create starts an upload of Length bytes, it's rejected right away when its type, size or context isn't accepted:
+// the error is ErrRejected wrapping the one of filetypes.Check. The file is charged to the Owner, when there's one
+// (see package quota), its length is counted in their quota while it's uploaded: quota.ErrExceeded when it doesn't fit.
+func Create(Name string, Mime string, Context string, Length int64, Owner *uint) (model.Resumable_uploads, error) {
+	Expire()
+
+	/* tus clients don't always send the file's type, it's guessed from the extension like browsers do */
+	if Mime == "" { Mime = mime.TypeByExtension(uploader.Extension(Name)) }
+
+	/* Mime is the client's, the content type is checked once the content is in */
+	if _, err := filetypes.Check(uploader.Extension(Name), "", Length, Context); err != nil {
+		return model.Resumable_uploads{}, fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil { return model.Resumable_uploads{}, err }
+
+	Upload := model.Resumable_uploads{ Token: hex.EncodeToString(token), Name: Name, Mime: Mime, Context: Context, Length: Length, OwnerID: Owner }
+	if err := os.MkdirAll(Dir, 0700); err != nil { return Upload, err }
+
+	part, err := os.Create(path(Upload))
+	if err != nil { return Upload, err }
+	part.Close()
+
+	if Owner == nil {
+		err = storage.DB.Create(&Upload).Error
+	} else {
+		_, err = quota.Reserve(&Upload)
+	}
+	if err != nil {
+		os.Remove(path(Upload))
+		return Upload, err
+	}
+	return Upload, nil
+}
+
+// Find returns the upload of the token.
+func Find(Token string) (model.Resumable_uploads, error) {
+	var Upload model.Resumable_uploads
+	if err := storage.DB.Preload("File").Where("token = ?", Token).First(&Upload).Error; err != nil { return Upload, ErrNotFound }
+	return Upload, nil
+}
+
+// Append writes a chunk at the offset, which must be where the upload currently ends.
+// Whatever part of the chunk arrived is kept when the body breaks off, the client resumes from the new offset.
+// The chunk completing the upload stores the file, its UploadResponse is returned (nil until then).
+// Its progress is tracked under the upload's token, counting the whole upload.
+func Append(Token string, Offset int64, Body io.Reader) (model.Resumable_uploads, *uploader.UploadResponse, error) {
+	/* Unknown tokens don't get a lock, it would never be removed */
+	if _, err := Find(Token); err != nil { return model.Resumable_uploads{}, nil, err }
+
+	lock, _ := locks.LoadOrStore(Token, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	Upload, err := Find(Token)
+	if err != nil { return Upload, nil, err }
+	if Upload.FileID != nil || Upload.Offset == Upload.Length {
+		locks.Delete(Token)
+		return Upload, nil, ErrFinished
+	}
+	if Offset != Upload.Offset { return Upload, nil, ErrOffset }
+
+	part, err := os.OpenFile(path(Upload), os.O_WRONLY | os.O_APPEND, 0600)
+	if err != nil { return Upload, nil, err }
+
+	Tracker := progress.Start(Token, Upload.Length, Upload.Offset)
+	written, copyErr := io.Copy(part, io.LimitReader(Tracker.Body(io.NopCloser(Body)), Upload.Length - Upload.Offset))
+	part.Close()
+
+	Upload.Offset += written
+	if err := storage.DB.Model(&Upload).Update("offset", Upload.Offset).Error; err != nil { return Upload, nil, err }
+	if copyErr != nil {
+		if errors.Is(copyErr, uploader.ErrAborted) { Tracker.Finish(uploader.CodeAborted) }
+		return Upload, nil, copyErr
+	}
+
+	if Upload.Offset < Upload.Length { return Upload, nil, nil }
+
+	/* A whole upload is never appended to again, waiting requests find it finished */
+	defer locks.Delete(Token)
+
+	Tracker.Processing()
+	Upload, Response, err := complete(Upload)
+	switch {
+		case err != nil: Tracker.Finish(uploader.CodeStorage)
+		case !Response.Success: Tracker.Finish(Response.Code)
+		default: Tracker.Finish("")
+	}
+	return Upload, Response, err
+}
+
+/* A rejected file is removed with its upload, resuming it would be rejected again */
+func complete(Upload model.Resumable_uploads) (model.Resumable_uploads, *uploader.UploadResponse, error) {
+	part, err := os.Open(path(Upload))
+	if err != nil { return Upload, nil, err }
+
+	Response := uploader.Store(part, Upload.Name, Upload.Length, Upload.Mime, Upload.Context)
+	part.Close()
+	os.Remove(path(Upload))
+
+	if !Response.Success {
+		storage.DB.Unscoped().Delete(&Upload)
+		return Upload, Response, nil
+	}
+
+	/* The length was reserved in the owner's quota (quota.Reserve), the file takes its place at once */
+	FileID := Response.ID
+	Upload.FileID = &FileID
+	return Upload, Response, storage.DB.Transaction(func(tx *gorm.DB) error {
+		if Upload.OwnerID != nil {
+			if err := tx.Model(&model.Files{}).Where("id = ?", FileID).Update(model.FilesOwnerID, *Upload.OwnerID).Error; err != nil { return err }
+		}
+		return tx.Model(&Upload).Update("file_id", FileID).Error
+	})
+}
+
+// Remove cancels an upload, deleting its part file.
+func Remove(Token string) error {
+	Upload, err := Find(Token)
+	if err != nil { return err }
+
+	os.Remove(path(Upload))
+	locks.Delete(Token)
+	return storage.DB.Unscoped().Delete(&Upload).Error
+}
+
+// Expire removes the uploads older than Expiry, finished ones only lose their row.
+func Expire() {
+	var Uploads []model.Resumable_uploads
+	if err := storage.DB.Where("created_at < ?", time.Now().Add(-Expiry)).Find(&Uploads).Error; err != nil {
+		log.Print("Expiring uploads: ", err)
+		return
+	}
+
+	for _, Upload := range Uploads {
+		os.Remove(path(Upload))
+		locks.Delete(Upload.Token)
+		storage.DB.Unscoped().Delete(&Upload)
+	}
+}
+
+func path(Upload model.Resumable_uploads) string {
+	return filepath.Join(Dir, Upload.Token + ".part")
+}
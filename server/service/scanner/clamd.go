@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+/* clamd takes the stream in chunks, each one prefixed with its length. Its StreamMaxLength (25M by default)
+   must be at least UPLOAD_MAX_SIZE, a longer stream is refused */
+const chunkSize = 64 * 1024
+
+// Clamd scans with ClamAV's daemon through its INSTREAM command.
+type Clamd struct {
+	Address		string		/* "tcp://host:port" or "unix:///path/to/socket" */
+}
+
+func (*Clamd) Name() string { return "clamd" }
+
+func (clamd *Clamd) Scan(ctx context.Context, content io.Reader) (Result, error) {
+	Network, Address, found := strings.Cut(clamd.Address, "://")
+	if !found { Network, Address = "tcp", clamd.Address }
+
+	var dialer net.Dialer
+	connection, err := dialer.DialContext(ctx, Network, Address)
+	if err != nil { return Result{}, err }
+	defer connection.Close()
+	if Deadline, ok := ctx.Deadline(); ok { connection.SetDeadline(Deadline) }
+
+	if _, err := connection.Write([]byte("zINSTREAM\x00")); err != nil { return Result{}, err }
+
+	chunk := make([]byte, chunkSize)
+	var size [4]byte
+	for {
+		n, err := content.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			if _, err := connection.Write(append(size[:], chunk[:n]...)); err != nil { return Result{}, err }
+		}
+		if err == io.EOF { break }
+		if err != nil { return Result{}, err }
+	}
+	if _, err := connection.Write([]byte{0, 0, 0, 0}); err != nil { return Result{}, err }
+
+	Reply, err := bufio.NewReader(connection).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) { return Result{}, err }
+	return parse(strings.TrimRight(Reply, "\x00\n"))
+}
+
+/* "stream: OK", "stream: Eicar-Signature FOUND" or "INSTREAM size limit exceeded. ERROR" */
+func parse(Reply string) (Result, error) {
+	Reply = strings.TrimPrefix(Reply, "stream: ")
+	switch {
+		case Reply == "OK":
+			return Result{}, nil
+		case strings.HasSuffix(Reply, " FOUND"):
+			return Result{ Infected: true, Signature: strings.TrimSuffix(Reply, " FOUND") }, nil
+	}
+	return Result{}, fmt.Errorf("clamd: %s", Reply)
+}
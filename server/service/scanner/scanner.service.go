@@ -0,0 +1,72 @@
+// Package scanner checks uploads for malware before they're stored, with the Scanner chosen by globals.Env.SCANNER:
+//
+//   clamd   ClamAV's daemon, at CLAMD_ADDRESS ("tcp://127.0.0.1:3310" or "unix:///var/run/clamav/clamd.ctl")
+//
+// none when empty. What happens to an infected file is up to SCAN_ACTION, see Action.
+package scanner
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"main/server/common/globals"
+)
+
+// Timeout bounds the scan of a single file.
+const Timeout = 2 * time.Minute
+
+// Action is what the upload pipeline does with an infected file, or one the scanner failed on.
+type Action string
+
+const (
+	ActionReject		Action = "reject"		/* the upload fails and the blob is deleted */
+	ActionQuarantine	Action = "quarantine"	/* the upload fails and its content is kept in the SCAN_QUARANTINE directory */
+	ActionFlag			Action = "flag"			/* the file is stored, pending review: only admins may download it */
+)
+
+// Result is the verdict of a scan.
+type Result struct {
+	Infected	bool
+	Signature	string		/* the malware found, "" when clean */
+}
+
+type Scanner interface {
+	Name() string
+	// Scan reads the content to its end and tells whether it's infected, an error when it couldn't decide.
+	Scan(ctx context.Context, content io.Reader) (Result, error)
+}
+
+var (
+	once sync.Once
+	scanner Scanner
+)
+
+// Default returns the scanner chosen by globals.Env.SCANNER, nil when there is none.
+func Default() Scanner {
+	once.Do(func() {
+		switch globals.Env.SCANNER {
+			case "clamd":
+				scanner = &Clamd{ Address: globals.Env.CLAMD_ADDRESS }
+		}
+	})
+	return scanner
+}
+
+// Configured returns SCAN_ACTION, ActionReject unless it's one of the others.
+func Configured() Action {
+	switch Action(strings.ToLower(globals.Env.SCAN_ACTION)) {
+		case ActionQuarantine: return ActionQuarantine
+		case ActionFlag: return ActionFlag
+	}
+	return ActionReject
+}
+
+// Scan runs the default scanner on the content, bounded by Timeout.
+func Scan(ctx context.Context, content io.Reader) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	return Default().Scan(ctx, content)
+}
@@ -0,0 +1,94 @@
+// Package searcher keeps the search analyzers (see search.Analyzer) in Search_analyzers, where the admin's settings
+// edit them, and hands them to package search at startup and on every change. It also builds the vocabulary
+// misspelled queries are corrected with (see search.Index) from the public products, every IndexEvery.
+//
+// Notes:
+//   - Other instances of the site pick a change up on their next start.
+//   - A product is corrected to only once the vocabulary is rebuilt after it's published.
+package searcher
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"main/server/common/domain"
+	"main/server/common/i18n"
+	"main/server/common/module"
+	"main/server/common/search"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// IndexEvery is how often the vocabulary is rebuilt.
+const IndexEvery = 10 * time.Minute
+
+var ErrLocale = domain.Invalid("locale is not supported")
+
+// Setup configures the analyzers of the locales which have a row, the others keep the built-in ones, builds
+// the vocabulary and declares the job rebuilding it on the container.
+func Setup(container *module.Container) {
+	var Rows []model.Search_analyzers
+	if err := storage.DB.Find(&Rows).Error; err != nil { log.Print("Loading search analyzers: ", err) }
+	for _, Row := range Rows { search.Configure(analyzer(Row)) }
+
+	if err := Index(context.Background()); err != nil { log.Print("Indexing search vocabulary: ", err) }
+	container.Cron("search.vocabulary", IndexEvery, Index)
+}
+
+// Index rebuilds the vocabulary from the names and descriptions of the public products.
+func Index(ctx context.Context) error {
+	var Products []model.Products
+	err := storage.DB.WithContext(ctx).
+		Select(model.ProductsName, model.ProductsDescription).
+		Where(&model.Products{ Public: true }).
+		Find(&Products).Error
+	if err != nil { return err }
+
+	Texts := make([]string, 0, 2 * len(Products))
+	for _, Product := range Products { Texts = append(Texts, Product.Name, Product.Description) }
+	search.UseIndex(search.NewIndex(Texts...))
+	return nil
+}
+
+// Analyzers returns the settings of every supported locale, the built-in ones for a locale without a row.
+func Analyzers() []model.Search_analyzers {
+	var Rows []model.Search_analyzers
+	storage.DB.Find(&Rows)
+
+	Stored := map[string]model.Search_analyzers{}
+	for _, Row := range Rows { Stored[Row.Locale] = Row }
+
+	Analyzers := []model.Search_analyzers{}
+	for _, Locale := range i18n.Supported() {
+		Row, ok := Stored[Locale]
+		if !ok { Row = model.Search_analyzers{ Locale: Locale, Stemming: true, Stopwords: strings.Join(search.Stopwords[Locale], ", ") } }
+		Analyzers = append(Analyzers, Row)
+	}
+	return Analyzers
+}
+
+// Save stores the settings of a locale and starts searching with them.
+func Save(Locale string, Stemming bool, Stopwords string, Synonyms string) error {
+	if !i18n.IsSupported(Locale) { return ErrLocale }
+
+	var Row model.Search_analyzers
+	storage.DB.Where(&model.Search_analyzers{ Locale: Locale }).First(&Row)
+	Row.Locale, Row.Stemming, Row.Stopwords, Row.Synonyms = Locale, Stemming, Stopwords, Synonyms
+
+	/* Save writes false too, Updates would skip it */
+	if err := storage.DB.Save(&Row).Error; err != nil { return err }
+	search.Configure(analyzer(Row))
+	return nil
+}
+
+func analyzer(Row model.Search_analyzers) *search.Analyzer {
+	Stopwords := strings.FieldsFunc(Row.Stopwords, func(Rune rune) bool { return Rune == ',' || Rune == '\n' })
+
+	Synonyms := [][]string{}
+	for _, Line := range strings.Split(Row.Synonyms, "\n") {
+		if Group := strings.Split(Line, ","); len(Group) > 1 { Synonyms = append(Synonyms, Group) }
+	}
+	return search.NewAnalyzer(Row.Locale, Row.Stemming, Stopwords, Synonyms)
+}
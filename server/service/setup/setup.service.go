@@ -0,0 +1,134 @@
+// Package setup runs the first-run installation: creating the first admin and the site's base settings.
+//
+// A site counts as installed once the wizard was completed, or when it already has users (seeded by hand
+// before the wizard existed). Once installed it stays installed, the wizard can't be run again.
+package setup
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/controller"
+	"main/server/common/domain"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/auth"
+)
+
+var (
+	ErrInstalled = domain.Conflict("site is already installed")
+	ErrUploadsPath = domain.Invalid("the uploads directory must be a path under public, starting with /")
+)
+
+type Input struct {
+	Fullname	string
+	Email		string
+	Password	string
+	SiteName	string
+	Locale		string
+	Uploads		string
+}
+
+// RecheckEvery is how long a site found not installed is taken as such before the database is asked again,
+// another instance may complete the setup meanwhile.
+const RecheckEvery = 5 * time.Second
+
+/* The positive answer is kept for good, it never changes back */
+var (
+	installed	atomic.Bool
+	checked		atomic.Int64			/* when the negative answer was found, in unix nanoseconds */
+)
+
+// Installed reports whether the site was set up. It's asked on every request, the database is only asked
+// while the site isn't, every RecheckEvery at most.
+func Installed() bool {
+	if installed.Load() { return true }
+	if time.Since(time.Unix(0, checked.Load())) < RecheckEvery { return false }
+	checked.Store(time.Now().UnixNano())
+
+	var Installations, Users int64
+	storage.DB.Model(&model.Installation{}).Where("completed_at IS NOT NULL").Count(&Installations)
+	storage.DB.Model(&model.Users{}).Count(&Users)
+
+	if Installations > 0 || Users > 0 { installed.Store(true) }
+	return installed.Load()
+}
+
+// Apply overrides globals.Env with the settings chosen in the wizard, it runs on boot.
+func Apply() {
+	var Installation model.Installation
+	if storage.DB.Where("completed_at IS NOT NULL").Last(&Installation).Error != nil { return }
+
+	if Installation.Locale != "" { globals.Env.DefaultLocale = Installation.Locale }
+	if Installation.Uploads != "" { globals.Env.Uploads = Installation.Uploads }
+}
+
+// Uploads normalizes the upload path to the "/uploads/" form and makes sure its directory exists under ./public.
+//
+// Returns:
+//   - ErrUploadsPath when it doesn't start with "/", has ".." segments or is ./public itself.
+func Uploads(Dir string) (string, error) {
+	Dir = strings.TrimSpace(Dir)
+	if Dir == "" { return "/uploads/", os.MkdirAll("./public/uploads/", 0755) }
+	if !strings.HasPrefix(Dir, "/") || slices.Contains(strings.Split(Dir, "/"), "..") { return Dir, ErrUploadsPath }
+
+	Dir = path.Clean(Dir)
+	if Dir == "/" { return Dir, ErrUploadsPath }
+	Dir += "/"
+
+	if err := os.MkdirAll("./public" + Dir, 0755); err != nil { return Dir, err }
+	return Dir, nil
+}
+
+// Complete creates the admin user and the installation in one transaction.
+func Complete(Input Input) (model.Users, error) {
+	var User model.Users
+	if Installed() { return User, ErrInstalled }
+
+	Hash, err := auth.HashPassword(Input.Password)
+	if err != nil { return User, err }
+
+	random := make([]byte, 32)
+	rand.Read(random)
+
+	Now := time.Now()
+	err = storage.DB.Transaction(func(tx *gorm.DB) error {
+		var Admin model.Roles
+		if err := tx.Where(&model.Roles{Name: controller.SuperRole}).FirstOrCreate(&Admin).Error; err != nil { return err }
+
+		User = model.Users{
+			Fullname: Input.Fullname,
+			Email: Input.Email,
+			Password: Hash,
+			Token: hex.EncodeToString(random),
+			Locale: Input.Locale,
+			Roles: []model.Roles{ Admin },
+		}
+		if err := tx.Create(&User).Error; err != nil { return err }
+
+		/* Public pages render inside the interface, an empty one keeps them up until it's filled in */
+		var Interface model.Interface
+		if err := tx.FirstOrCreate(&Interface).Error; err != nil { return err }
+
+		return tx.Create(&model.Installation{
+			SiteName: Input.SiteName,
+			Locale: Input.Locale,
+			Uploads: Input.Uploads,
+			CompletedAt: &Now,
+		}).Error
+	})
+	if err != nil { return User, err }
+
+	installed.Store(true)
+	Apply()
+	return User, nil
+}
@@ -0,0 +1,182 @@
+package snapshot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"main/server/model"
+)
+
+// Password is what every user's password becomes, developers sign in with their anonymized email and it.
+const Password = "password"
+
+// Fake replaces a value of a column. The same value always gets the same fake within a snapshot (same Seed),
+// whatever table it's in, so an email is still the same address in users, mails and subscribes.
+type Fake func(Anonymizer *Anonymizer, Value string) string
+
+// Rules are the columns holding personal data (or secrets) and how they're faked, by table.
+// Phone numbers are only the site's own (branches, contact), they're public and kept.
+var Rules = map[string]map[string]Fake{
+	model.UsersTable: {
+		model.UsersFullname: Name,
+		model.UsersEmail: Email,
+		model.UsersPassword: Hash,
+		model.UsersToken: Blank,
+	},
+	model.ChatTable: {
+		model.ChatFullname: Name,
+		model.ChatEmail: Email,
+	},
+	model.Chat_lettersTable: {
+		model.Chat_lettersBody: Text,
+		model.Chat_lettersFrom: Email,
+		model.Chat_lettersTo: Email,
+	},
+	model.MailsTable: {
+		model.MailsTo: Email,
+		model.MailsBody: Text,
+	},
+	model.Mail_suppressionsTable: {
+		model.Mail_suppressionsEmail: Email,
+		model.Mail_suppressionsDetail: Text,
+	},
+	model.NotificationsTable: {
+		model.NotificationsBody: Text,		/* security alerts name devices and addresses */
+	},
+	model.SubscribesTable: {
+		model.SubscribesEmail: Email,
+	},
+	model.Preview_channelsTable: {
+		model.Preview_channelsToken: Token,
+	},
+	model.Preview_linksTable: {
+		model.Preview_linksToken: Token,
+	},
+	model.Kiosk_displaysTable: {
+		model.Kiosk_displaysToken: Token,
+	},
+	model.Preview_visitsTable: {
+		model.Preview_visitsIP: IP,
+		model.Preview_visitsUserAgent: Text,
+	},
+	model.FilesTable: {
+		model.FilesOriginal: FileName,
+		model.FilesBase64: Blank,
+		model.FilesTranscoding: Blank,
+	},
+	model.File_eventsTable: {
+		model.File_eventsIP: IP,
+		model.File_eventsUserAgent: Text,
+		model.File_eventsDetail: Text,		/* original names of the files */
+	},
+	model.AuditsTable: {
+		model.AuditsIP: IP,
+		model.AuditsUserAgent: Text,
+		model.AuditsChanges: Changes,		/* the users' diffs hold names and emails */
+	},
+}
+
+// Skipped are the tables whose rows aren't in a snapshot at all: sign-in sessions and devices, work in
+// progress, and what refers to blobs the placeholders don't replace (video renditions, earlier versions).
+var Skipped = map[string]bool{
+	model.SessionsTable: true,
+	model.Remember_tokensTable: true,
+	model.User_devicesTable: true,
+	model.Resumable_uploadsTable: true,
+	model.Outbox_messagesTable: true,
+	model.File_renditionsTable: true,
+	model.File_versionsTable: true,
+}
+
+var firstNames = []string{ "Giorgi", "Nino", "Levan", "Mariam", "Davit", "Ana", "Irakli", "Tamar", "Luka", "Salome", "Nika", "Elene" }
+var lastNames = []string{ "Beridze", "Kapanadze", "Gelashvili", "Maisuradze", "Giorgadze", "Lomidze", "Tsiklauri", "Bolkvadze", "Nozadze", "Abashidze" }
+
+// Anonymizer fakes values deterministically from its seed.
+type Anonymizer struct {
+	Seed		[]byte
+	hash		string		/* bcrypt of Password, computed once */
+}
+
+// NewAnonymizer returns an Anonymizer of the seed, the same seed fakes the same values the same way.
+func NewAnonymizer(Seed []byte) (*Anonymizer, error) {
+	Hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.DefaultCost)
+	if err != nil { return nil, err }
+	return &Anonymizer{ Seed: Seed, hash: string(Hash) }, nil
+}
+
+// Row fakes the columns of the table's row its Rules name, empty values stay empty.
+func (Anonymizer *Anonymizer) Row(Table string, Row map[string]any) {
+	for Column, fake := range Rules[Table] {
+		Value, ok := Row[Column]
+		if !ok || Value == nil { continue }
+
+		Text := fmt.Sprint(Value)
+		if Bytes, ok := Value.([]byte); ok { Text = string(Bytes) }
+		if Text == "" { continue }
+		Row[Column] = fake(Anonymizer, Text)
+	}
+}
+
+/* The kind keeps an email and a name of the same text apart */
+func (Anonymizer *Anonymizer) sum(Kind string, Value string) []byte {
+	mac := hmac.New(sha256.New, Anonymizer.Seed)
+	mac.Write([]byte(Kind + ":" + Value))
+	return mac.Sum(nil)
+}
+
+func Name(Anonymizer *Anonymizer, Value string) string {
+	Sum := Anonymizer.sum("name", Value)
+	return firstNames[int(Sum[0]) % len(firstNames)] + " " + lastNames[int(Sum[1]) % len(lastNames)]
+}
+
+/* example.test can never receive mail */
+func Email(Anonymizer *Anonymizer, Value string) string {
+	return "user-" + hex.EncodeToString(Anonymizer.sum("email", Value)[:5]) + "@example.test"
+}
+
+func IP(Anonymizer *Anonymizer, Value string) string {
+	Sum := Anonymizer.sum("ip", Value)
+	return fmt.Sprintf("10.%d.%d.%d", Sum[0], Sum[1], Sum[2])
+}
+
+/* Unique like the tokens they replace, useless against production */
+func Token(Anonymizer *Anonymizer, Value string) string {
+	return hex.EncodeToString(Anonymizer.sum("token", Value)[:16])
+}
+
+func FileName(Anonymizer *Anonymizer, Value string) string {
+	return "file-" + hex.EncodeToString(Anonymizer.sum("file", Value)[:4]) + filepath.Ext(Value)
+}
+
+func Text(Anonymizer *Anonymizer, Value string) string {
+	return "[anonymized]"
+}
+
+/* An audit's diff keeps the columns which changed, not their values */
+func Changes(Anonymizer *Anonymizer, Value string) string {
+	var Diff map[string][2]any
+	if err := json.Unmarshal([]byte(Value), &Diff); err != nil { return "{}" }
+
+	for Column, Values := range Diff {
+		for i := range Values {
+			if Values[i] != nil { Values[i] = Text(Anonymizer, "") }
+		}
+		Diff[Column] = Values
+	}
+	Faked, _ := json.Marshal(Diff)
+	return string(Faked)
+}
+
+func Hash(Anonymizer *Anonymizer, Value string) string {
+	return Anonymizer.hash
+}
+
+func Blank(Anonymizer *Anonymizer, Value string) string {
+	return ""
+}
@@ -0,0 +1,52 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"strings"
+
+	"main/server/model"
+	"main/server/service/thumbnailer"
+)
+
+// MaxPlaceholder bounds the sides of placeholder images, larger uploads get a scaled down one.
+const MaxPlaceholder = 1600
+
+var placeholderColor = color.RGBA{ 0xd1, 0xd5, 0xdb, 0xff }
+
+// Placeholder returns what replaces an upload in a snapshot: a gray image of its dimensions (800x600 when they
+// aren't known) for images, a line of text naming it for other files, which is enough for pages to render.
+func Placeholder(Path string, Width int, Height int) ([]byte, error) {
+	Name := filepath.Base(Path)
+	Extension := strings.ToLower(filepath.Ext(Name))
+
+	if Extension == ".svg" {
+		return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d"><rect width="100%%" height="100%%" fill="#d1d5db"/></svg>`, max(Width, 1), max(Height, 1))), nil
+	}
+	if !thumbnailer.IsImage(model.Files{ Name: Name }) { return []byte("placeholder of " + Name + "\n"), nil }
+
+	if Width <= 0 || Height <= 0 { Width, Height = 800, 600 }
+	if Scale := max(Width, Height); Scale > MaxPlaceholder {
+		Width, Height = max(Width * MaxPlaceholder / Scale, 1), max(Height * MaxPlaceholder / Scale, 1)
+	}
+
+	Image := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(Image, Image.Bounds(), &image.Uniform{ placeholderColor }, image.Point{}, draw.Src)
+
+	/* Other formats (webp, ...) can't be encoded here, image decoders sniff a png behind their extension */
+	var Buffer bytes.Buffer
+	var err error
+	switch Extension {
+		case ".jpg", ".jpeg": err = jpeg.Encode(&Buffer, Image, &jpeg.Options{ Quality: 60 })
+		case ".gif": err = gif.Encode(&Buffer, Image, nil)
+		default: err = png.Encode(&Buffer, Image)
+	}
+	return Buffer.Bytes(), err
+}
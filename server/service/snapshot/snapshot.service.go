@@ -0,0 +1,170 @@
+// Package snapshot makes anonymized copies of the database for developers to reproduce production issues with.
+//
+// A snapshot is a zip archive of:
+//
+//   snapshot.sql        the rows of every table as INSERTs, personal data faked (see Rules)
+//   public/uploads/...  a placeholder (see Placeholder) at the key of every upload and thumbnail
+//
+// It's loaded into an empty database whose schema cmd/migrate made, the placeholders are unzipped at the
+// project's root where the local storage backend serves them:
+//
+//   go run ./cmd/migrate
+//   psql -d yacco -f snapshot.sql
+//
+// Faking is deterministic: a value always gets the same fake within a snapshot, in every table, so records
+// still match each other (a user's mails are still theirs). Different seeds fake differently, a snapshot
+// can't be matched back to the addresses it was made from without its seed.
+package snapshot
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"main/server/common/blob"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// Write writes the snapshot of the database as a zip archive, faking with the seed.
+//
+// Example usage:
+//   file, _ := os.Create("snapshot.zip")
+//   if err := snapshot.Write(ctx, file, Seed); err != nil { log.Fatal(err) }
+//
+// Notes:
+//   - The dump holds data only, tables which don't exist in the target database fail their INSERTs.
+//   - Foreign keys are checked once everything is loaded: the dump disables the triggers while it runs,
+//     which takes a superuser (the postgres user of a development database is one).
+func Write(ctx context.Context, w io.Writer, Seed []byte) error {
+	Anonymizer, err := NewAnonymizer(Seed)
+	if err != nil { return err }
+
+	Archive := zip.NewWriter(w)
+
+	Dump, err := Archive.Create("snapshot.sql")
+	if err != nil { return err }
+	if err := dump(ctx, Dump, Anonymizer); err != nil { return err }
+	if err := placeholders(ctx, Archive); err != nil { return err }
+
+	return Archive.Close()
+}
+
+func dump(ctx context.Context, w io.Writer, Anonymizer *Anonymizer) error {
+	Tables, err := storage.DB.WithContext(ctx).Migrator().GetTables()
+	if err != nil { return err }
+	sort.Strings(Tables)
+
+	Writer := bufio.NewWriter(w)
+	fmt.Fprintf(Writer, "-- Anonymized snapshot made %s, see package snapshot\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintln(Writer, "SET session_replication_role = replica;")
+
+	for _, Table := range Tables {
+		if Skipped[Table] { continue }
+		if err := table(ctx, Writer, Anonymizer, Table); err != nil { return fmt.Errorf("%s: %w", Table, err) }
+	}
+
+	fmt.Fprintln(Writer, "SET session_replication_role = DEFAULT;")
+	return Writer.Flush()
+}
+
+/* Rows are read and written one at a time, soft deleted ones too, a table of any size takes no memory */
+func table(ctx context.Context, w *bufio.Writer, Anonymizer *Anonymizer, Table string) error {
+	Rows, err := storage.DB.WithContext(ctx).Table(Table).Rows()
+	if err != nil { return err }
+	defer Rows.Close()
+
+	Columns, err := Rows.Columns()
+	if err != nil { return err }
+
+	Quoted := make([]string, len(Columns))
+	for i, Column := range Columns { Quoted[i] = identifier(Column) }
+	Insert := "INSERT INTO " + identifier(Table) + " (" + strings.Join(Quoted, ", ") + ") VALUES ("
+
+	fmt.Fprintf(w, "\n-- %s\n", Table)
+	Values := make([]any, len(Columns))
+	Pointers := make([]any, len(Columns))
+	for i := range Values { Pointers[i] = &Values[i] }
+
+	for Rows.Next() {
+		if err := Rows.Scan(Pointers...); err != nil { return err }
+
+		Row := make(map[string]any, len(Columns))
+		for i, Column := range Columns { Row[Column] = Values[i] }
+		Anonymizer.Row(Table, Row)
+
+		Literals := make([]string, len(Columns))
+		for i, Column := range Columns { Literals[i] = literal(Row[Column]) }
+		if _, err := w.WriteString(Insert + strings.Join(Literals, ", ") + ");\n"); err != nil { return err }
+	}
+	if err := Rows.Err(); err != nil { return err }
+
+	/* The ids were inserted as they were, new rows continue after them */
+	for _, Column := range Columns {
+		if Column != "id" { continue }
+		fmt.Fprintf(w, "SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE(MAX(id), 1)) FROM %s;\n", identifier(Table), identifier(Table))
+	}
+	return nil
+}
+
+/* Every upload and thumbnail gets its placeholder, the sizes come from their metadata */
+func placeholders(ctx context.Context, Archive *zip.Writer) error {
+	var Files []model.Files
+	if err := storage.DB.WithContext(ctx).Unscoped().Preload("Metadata").Preload("Thumbnails").Find(&Files).Error; err != nil { return err }
+
+	Written := map[string]bool{}
+	write := func(Path string, Width int, Height int) error {
+		Key := blob.Key(Path)
+		if Key == "" || Written[Key] { return nil }
+		Written[Key] = true
+
+		Content, err := Placeholder(Path, Width, Height)
+		if err != nil { return fmt.Errorf("placeholder of %s: %w", Path, err) }
+		Entry, err := Archive.Create("public/" + Key)
+		if err != nil { return err }
+		_, err = Entry.Write(Content)
+		return err
+	}
+
+	for _, File := range Files {
+		if err := write(File.Path, File.Metadata.Width, File.Metadata.Height); err != nil { return err }
+		for _, Thumbnail := range File.Thumbnails {
+			if err := write(Thumbnail.Path, Thumbnail.Width, Thumbnail.Height); err != nil { return err }
+		}
+	}
+	return nil
+}
+
+func identifier(Name string) string {
+	return `"` + strings.ReplaceAll(Name, `"`, `""`) + `"`
+}
+
+/* Postgres literals of what database/sql scans, standard_conforming_strings keeps backslashes as they are */
+func literal(Value any) string {
+	switch Value := Value.(type) {
+		case nil:
+			return "NULL"
+		case bool:
+			if Value { return "TRUE" }
+			return "FALSE"
+		case int64, int32, int16, int, float64, float32:
+			return fmt.Sprint(Value)
+		case time.Time:
+			return quote(Value.Format("2006-01-02 15:04:05.999999Z07:00"))
+		case []byte:
+			return quote(string(Value))
+		case string:
+			return quote(Value)
+		default:
+			return quote(fmt.Sprint(Value))
+	}
+}
+
+func quote(Value string) string {
+	return "'" + strings.ReplaceAll(Value, "'", "''") + "'"
+}
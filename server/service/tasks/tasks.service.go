@@ -0,0 +1,102 @@
+// Package tasks runs the long jobs admins start (listing exports, ...) in the background and records how far
+// they are in Tasks, so /admin/tasks can show their progress and what they made once they're done.
+package tasks
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/server/common/domain"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// Workers is how many tasks run at once per instance, the others wait queued.
+const Workers = 2
+
+// Timeout bounds how long a task may run.
+const Timeout = time.Hour
+
+// ErrNotFound is returned by Find for tasks which don't exist or belong to another user.
+var ErrNotFound = domain.NotFound("task not found")
+
+var workers = make(chan struct{}, Workers)
+
+// Work is what a task does. It reports how many of the task's Total it has done with progress, and returns
+// the file it made, if any.
+type Work func(ctx context.Context, progress func(Done int)) (*uint, error)
+
+// Setup fails the tasks which were queued or running when the app stopped, their work is lost.
+func Setup() {
+	Now := time.Now()
+	if err := storage.DB.Model(&model.Tasks{}).Where("state IN ?", []string{ model.TaskQueued, model.TaskRunning }).
+		Updates(map[string]any{ model.TasksState: model.TaskFailed, model.TasksError: "interrupted by a restart", model.TasksFinishedAt: Now }).Error; err != nil {
+		log.Print("Failing interrupted tasks: ", err)
+	}
+}
+
+// Run records a queued task of the user and runs the work in the background, once one of the Workers is free.
+// done is called with the finished task, failed or not.
+//
+// Example usage:
+//   Task, err := tasks.Run(User.ID, "export", "პროდუქტები", Count, export, nil)
+//   if err != nil { return err }
+//
+// Notes:
+//   - Progress is written as it's reported, the work should report it every batch rather than every row.
+func Run(UsersID uint, Kind string, Title string, Total int, work Work, done func(model.Tasks)) (model.Tasks, error) {
+	Task := model.Tasks{ UsersID: UsersID, Kind: Kind, Title: Title, State: model.TaskQueued, Total: Total }
+	if err := storage.DB.Create(&Task).Error; err != nil { return Task, err }
+
+	Running := Task
+	go func() {
+		workers <- struct{}{}
+		defer func() { <-workers }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+
+		update(Running.ID, map[string]any{ model.TasksState: model.TaskRunning })
+		FileID, err := work(ctx, func(Done int) { update(Running.ID, map[string]any{ model.TasksDone: Done }) })
+
+		Now := time.Now()
+		Running.FinishedAt = &Now
+		if err != nil {
+			log.Print("Task ", Running.ID, " (", Running.Kind, "): ", err)
+			Running.State, Running.Error = model.TaskFailed, err.Error()
+			update(Running.ID, map[string]any{ model.TasksState: model.TaskFailed, model.TasksError: err.Error(), model.TasksFinishedAt: Now })
+		} else {
+			Running.State, Running.FileID, Running.Done = model.TaskDone, FileID, Running.Total
+			update(Running.ID, map[string]any{ model.TasksState: model.TaskDone, model.TasksFileID: FileID, model.TasksDone: Running.Total, model.TasksFinishedAt: Now })
+		}
+
+		if done != nil { done(Running) }
+	}()
+	return Task, nil
+}
+
+/* A task which can't record its progress still runs, its page is only behind */
+func update(ID uint, Columns map[string]any) {
+	if err := storage.DB.Model(&model.Tasks{}).Where("id = ?", ID).Updates(Columns).Error; err != nil { log.Print("Updating task ", ID, ": ", err) }
+}
+
+// List returns the user's latest tasks, newest first.
+func List(UsersID uint, Limit int) ([]model.Tasks, error) {
+	var Tasks []model.Tasks
+	err := storage.DB.Where("users_id = ?", UsersID).Order("id DESC").Limit(Limit).Find(&Tasks).Error
+	return Tasks, err
+}
+
+// Find returns a task of the user.
+func Find(UsersID uint, ID uint) (model.Tasks, error) {
+	var Task model.Tasks
+	if err := storage.DB.Where("users_id = ?", UsersID).First(&Task, ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) { return Task, ErrNotFound }
+		return Task, err
+	}
+	return Task, nil
+}
@@ -0,0 +1,203 @@
+// Package telemetry counts what a deployment uses, for its owner to decide what to disable or scale: requests per
+// route and per module, and uploads by size bucket. It's opt-in (TELEMETRY=true), nothing is counted otherwise.
+//
+// Counts are tallied in memory and rolled up into Feature_uses by Flush, one row per kind, name and day, like
+// package analytics does page views. They stay in the database, nothing is sent anywhere, and nothing in them
+// identifies a user or visitor: routes are counted by their pattern, not their url, and files by their size's bucket.
+package telemetry
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"main/server/common/globals"
+	"main/server/common/module"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// FlushEvery is how often the tallies are written, a crash loses at most this much.
+const FlushEvery = time.Minute
+
+// Window is how many days Summarize sums up.
+const Window = 30
+
+type key struct {
+	Kind		string
+	Name		string
+	Day			time.Time
+}
+
+var (
+	mu			sync.Mutex
+	pending		= map[key]int{}
+	owner		= func(Method string, Path string) string { return "core" }
+)
+
+/* Upper bounds of the upload size buckets, the last one is open */
+var buckets = []struct{ Below int64; Name string }{
+	{ 100 << 10, "< 100 KB" },
+	{ 1 << 20, "100 KB - 1 MB" },
+	{ 10 << 20, "1 - 10 MB" },
+	{ 100 << 20, "10 - 100 MB" },
+	{ 1 << 30, "100 MB - 1 GB" },
+	{ math.MaxInt64, "≥ 1 GB" },
+}
+
+// Usage is a name's count over the last Window days.
+type Usage struct {
+	Name		string
+	Count		int
+	Days		int			/* days it was used on */
+}
+
+// Summary is what was used over the last Window days, by kind, the most used first.
+type Summary struct {
+	Enabled		bool
+	Routes		[]Usage
+	Modules		[]Usage		/* every registered module, the unused ones too */
+	Uploads		[]Usage		/* every bucket, smallest first */
+}
+
+// Enabled reports whether the deployment opted in.
+func Enabled() bool {
+	return globals.Env.TELEMETRY
+}
+
+// Setup declares the jobs writing the tallies and forgetting the days past TELEMETRY_DAYS, routes are attributed to
+// the container's modules.
+func Setup(container *module.Container) {
+	if !Enabled() { return }
+	owner = container.Owner
+	container.Cron("telemetry.flush", FlushEvery, Flush)
+	container.Cron("telemetry.prune", 24 * time.Hour, Prune)
+}
+
+// Request counts a request of a route, Path is its pattern (ctx.Path()), and of the module which registered it.
+func Request(Method string, Path string) {
+	if !Enabled() || Path == "" { return }
+	count(model.UsageRoute, Method + " " + Path)
+	count(model.UsageModule, owner(Method, Path))
+}
+
+// Upload counts a stored file in its size's bucket.
+func Upload(Size int64) {
+	if !Enabled() { return }
+	count(model.UsageUpload, bucket(Size))
+}
+
+func bucket(Size int64) string {
+	for _, Bucket := range buckets {
+		if Size < Bucket.Below { return Bucket.Name }
+	}
+	return buckets[len(buckets) - 1].Name
+}
+
+func count(Kind string, Name string) {
+	Key := key{ Kind: Kind, Name: Name, Day: day(time.Now()) }
+	mu.Lock()
+	pending[Key]++
+	mu.Unlock()
+}
+
+// Flush rolls the tallies up into Feature_uses, those it couldn't write are kept for the next one.
+func Flush(ctx context.Context) error {
+	mu.Lock()
+	Tallies := pending
+	pending = map[key]int{}
+	mu.Unlock()
+
+	for Key, Count := range Tallies {
+		Row := model.Feature_uses{ Kind: Key.Kind, Name: Key.Name, Day: Key.Day, Count: Count }
+		err := storage.DB.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{ Name: model.Feature_usesKind }, { Name: model.Feature_usesName }, { Name: model.Feature_usesDay }},
+			DoUpdates: clause.Assignments(map[string]any{
+				model.Feature_usesCount: gorm.Expr("feature_uses.count + ?", Count),
+				model.Feature_usesUpdatedAt: time.Now(),
+			}),
+		}).Create(&Row).Error
+		if err != nil {
+			restore(Tallies)
+			return err
+		}
+		delete(Tallies, Key)
+	}
+	return nil
+}
+
+/* Tallies which weren't written go back, along with those counted meanwhile */
+func restore(Tallies map[key]int) {
+	mu.Lock()
+	defer mu.Unlock()
+	for Key, Count := range Tallies { pending[Key] += Count }
+}
+
+// Prune forgets the days older than TELEMETRY_DAYS.
+func Prune(ctx context.Context) error {
+	Before := day(time.Now()).AddDate(0, 0, -globals.Env.TELEMETRY_DAYS)
+	return storage.DB.WithContext(ctx).Unscoped().Where("day < ?", Before).Delete(&model.Feature_uses{}).Error
+}
+
+// Summarize sums up the last Window days, tallies not written yet included. Modules are the ones registered, so
+// those nobody used are listed with 0.
+func Summarize(Modules []string) (Summary, error) {
+	Result := Summary{ Enabled: Enabled() }
+	Since := day(time.Now()).AddDate(0, 0, -(Window - 1))
+
+	var Rows []model.Feature_uses
+	if err := storage.DB.Where("day >= ?", Since).Find(&Rows).Error; err != nil { return Result, err }
+
+	mu.Lock()
+	for Key, Count := range pending { Rows = append(Rows, model.Feature_uses{ Kind: Key.Kind, Name: Key.Name, Day: Key.Day, Count: Count }) }
+	mu.Unlock()
+
+	Sums := map[string]map[string]*Usage{ model.UsageRoute: {}, model.UsageModule: {}, model.UsageUpload: {} }
+	Seen := map[string]bool{}
+	for _, Name := range Modules { Sums[model.UsageModule][Name] = &Usage{ Name: Name } }
+	for _, Bucket := range buckets { Sums[model.UsageUpload][Bucket.Name] = &Usage{ Name: Bucket.Name } }
+
+	for _, Row := range Rows {
+		Names, ok := Sums[Row.Kind]
+		if !ok { continue }
+		Sum, ok := Names[Row.Name]
+		if !ok {
+			Sum = &Usage{ Name: Row.Name }
+			Names[Row.Name] = Sum
+		}
+		Sum.Count += Row.Count
+
+		/* A day's row and its pending tally are the same day. The date column comes back at midnight UTC, the
+		   tallies' days at local midnight: they're compared by date */
+		if Key := Row.Kind + " " + Row.Name + " " + Row.Day.Format(time.DateOnly); !Seen[Key] {
+			Seen[Key] = true
+			Sum.Days++
+		}
+	}
+
+	Result.Routes = ranked(Sums[model.UsageRoute])
+	Result.Modules = ranked(Sums[model.UsageModule])
+	for _, Bucket := range buckets { Result.Uploads = append(Result.Uploads, *Sums[model.UsageUpload][Bucket.Name]) }
+	return Result, nil
+}
+
+func ranked(Sums map[string]*Usage) []Usage {
+	Result := []Usage{}
+	for _, Sum := range Sums { Result = append(Result, *Sum) }
+	sort.Slice(Result, func(i, j int) bool {
+		if Result[i].Count != Result[j].Count { return Result[i].Count > Result[j].Count }
+		return Result[i].Name < Result[j].Name
+	})
+	return Result
+}
+
+/* Days are counted in the server's time zone */
+func day(t time.Time) time.Time {
+	Year, Month, Day := t.Date()
+	return time.Date(Year, Month, Day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,186 @@
+package thumbnailer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"strings"
+)
+
+/* Returned by the strippers for files they can't walk, Normalize re-encodes those */
+var errMalformed = errors.New("malformed image")
+
+// NormalizedQuality of the jpegs Normalize re-encodes, higher than the thumbnails' as they replace the original.
+const NormalizedQuality = 92
+
+// Normalize strips the metadata of a jpeg or png upload (EXIF with its GPS position, XMP, IPTC, comments, text
+// chunks) without touching its pixels. A jpeg which isn't upright (see Orientation) is re-encoded instead, turned
+// as its EXIF orientation tells, so it displays upright everywhere. Other formats come back as they are, with false,
+// and so do images without metadata.
+//
+// Example usage:
+//   Normalized, changed, err := thumbnailer.Normalize(data, ".jpg")
+//   if err != nil { return err }
+//   if changed { data = Normalized }
+//
+// Returns:
+//   - ErrTooLarge for images with more than MaxPixels pixels.
+func Normalize(data []byte, Extension string) ([]byte, bool, error) {
+	Extension = strings.ToLower(Extension)
+	if Extension != ".jpg" && Extension != ".jpeg" && Extension != ".png" { return data, false, nil }
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil { return nil, false, err }
+	if config.Width * config.Height > MaxPixels { return nil, false, ErrTooLarge }
+
+	/* Only turning the image needs its pixels, the metadata is cut out of the file as it is */
+	var Stripped []byte
+	if Extension == ".png" {
+		Stripped, err = stripPNG(data)
+	} else if Orientation(data) == 1 {
+		Stripped, err = stripJPEG(data)
+	} else {
+		return reencode(data)
+	}
+	if err != nil { return reencode(data) }
+	return Stripped, len(Stripped) != len(data), nil
+}
+
+/* The fallback for images whose structure can't be walked, decoding them fails too when they're broken */
+func reencode(data []byte) ([]byte, bool, error) {
+	decoded, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil { return nil, false, err }
+
+	var encoded bytes.Buffer
+	if format == "png" {
+		err = png.Encode(&encoded, decoded)
+	} else {
+		err = jpeg.Encode(&encoded, orient(toRGBA(decoded), Orientation(data)), &jpeg.Options{ Quality: NormalizedQuality })
+	}
+	if err != nil { return nil, false, err }
+	return encoded.Bytes(), true, nil
+}
+
+/* The segments before the scan without APP1 (EXIF, XMP), APP13 (IPTC) and comments, the rest as it is. ICC profiles
+   (APP2) and Adobe's color transform (APP14) stay, the colors depend on them */
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 { return nil, errMalformed }
+	stripped := append(make([]byte, 0, len(data)), data[:2]...)
+	for offset := 2; offset + 4 <= len(data); {
+		if data[offset] != 0xFF { return nil, errMalformed }
+		marker := data[offset + 1]
+		if marker == 0xFF {
+			offset++
+			continue
+		}
+		if marker == 0xDA { return append(stripped, data[offset:]...), nil }
+
+		length := int(binary.BigEndian.Uint16(data[offset + 2:]))
+		if length < 2 || offset + 2 + length > len(data) { return nil, errMalformed }
+		if marker != 0xE1 && marker != 0xED && marker != 0xFE { stripped = append(stripped, data[offset:offset + 2 + length]...) }
+		offset += 2 + length
+	}
+	return nil, errMalformed
+}
+
+/* The chunks without text (tEXt, zTXt, iTXt), EXIF (eXIf) and the modification time, the rest as it is: the chunks
+   are length, type, data and crc, the crc covers the type and data alone */
+func stripPNG(data []byte) ([]byte, error) {
+	if len(data) < 8 || string(data[:8]) != "\x89PNG\r\n\x1a\n" { return nil, errMalformed }
+	stripped := append(make([]byte, 0, len(data)), data[:8]...)
+	for offset := 8; offset + 12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		end := offset + 12 + length
+		if end > len(data) { return nil, errMalformed }
+
+		switch string(data[offset + 4:offset + 8]) {
+			case "tEXt", "zTXt", "iTXt", "eXIf", "tIME":
+			case "IEND": return append(stripped, data[offset:end]...), nil
+			default: stripped = append(stripped, data[offset:end]...)
+		}
+		offset = end
+	}
+	return nil, errMalformed
+}
+
+// Orientation returns the EXIF orientation tag of a jpeg (1 to 8), 1 (upright) when it has none.
+func Orientation(data []byte) int {
+	/* Segments follow the SOI marker: 0xFF, the marker, a big endian length counting itself, the payload */
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 { return 1 }
+	for offset := 2; offset + 4 <= len(data); {
+		if data[offset] != 0xFF { return 1 }
+		marker := data[offset + 1]
+		length := int(binary.BigEndian.Uint16(data[offset + 2:]))
+		if marker == 0xDA || length < 2 || offset + 2 + length > len(data) { return 1 }
+
+		payload := data[offset + 4:offset + 2 + length]
+		if marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) { return exifOrientation(payload[6:]) }
+		offset += 2 + length
+	}
+	return 1
+}
+
+/* A TIFF header (byte order, 42, the first IFD's offset) then IFD0: a count of 12 byte entries, tag 0x0112 is the orientation */
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 { return 1 }
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+		case "II": order = binary.LittleEndian
+		case "MM": order = binary.BigEndian
+		default: return 1
+	}
+
+	IFD := int(order.Uint32(tiff[4:]))
+	if IFD < 8 || IFD + 2 > len(tiff) { return 1 }
+	count := int(order.Uint16(tiff[IFD:]))
+	for i := 0; i < count; i++ {
+		entry := IFD + 2 + i * 12
+		if entry + 12 > len(tiff) { return 1 }
+		if order.Uint16(tiff[entry:]) != 0x0112 { continue }
+
+		value := int(order.Uint16(tiff[entry + 8:]))
+		if value < 1 || value > 8 { return 1 }
+		return value
+	}
+	return 1
+}
+
+func toRGBA(decoded image.Image) *image.RGBA {
+	rgba := image.NewRGBA(decoded.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+	return rgba
+}
+
+// orient turns the image upright after its EXIF orientation: 2 to 4 mirror or turn it half way round,
+// 5 to 8 swap its sides.
+func orient(source *image.RGBA, Orientation int) *image.RGBA {
+	if Orientation < 2 || Orientation > 8 { return source }
+
+	bounds := source.Bounds()
+	W, H := bounds.Dx(), bounds.Dy()
+	target := image.NewRGBA(image.Rect(0, 0, W, H))
+	if Orientation >= 5 { target = image.NewRGBA(image.Rect(0, 0, H, W)) }
+
+	for y := 0; y < H; y++ {
+		for x := 0; x < W; x++ {
+			var tx, ty int
+			switch Orientation {
+				case 2: tx, ty = W - 1 - x, y
+				case 3: tx, ty = W - 1 - x, H - 1 - y
+				case 4: tx, ty = x, H - 1 - y
+				case 5: tx, ty = y, x
+				case 6: tx, ty = H - 1 - y, x
+				case 7: tx, ty = H - 1 - y, W - 1 - x
+				case 8: tx, ty = y, W - 1 - x
+			}
+			from := source.PixOffset(bounds.Min.X + x, bounds.Min.Y + y)
+			to := target.PixOffset(tx, ty)
+			copy(target.Pix[to:to + 4], source.Pix[from:from + 4])
+		}
+	}
+	return target
+}
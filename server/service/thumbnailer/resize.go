@@ -0,0 +1,160 @@
+package thumbnailer
+
+import (
+	"context"
+	"errors"
+	"image"
+	"strconv"
+	"sync"
+
+	"main/server/common/blob"
+	"main/server/common/domain"
+	"main/server/common/globals"
+	"main/server/model"
+)
+
+// Fit is how Resize fits an image into the requested box.
+type Fit string
+
+const (
+	FitContain	Fit = "contain"	/* the whole image within the box, keeping its ratio */
+	FitCover	Fit = "cover"	/* the box filled, keeping the ratio and cropping the overflow around the center */
+	FitFill		Fit = "fill"	/* the box filled, stretching the image */
+)
+
+var (
+	ErrNotImage = domain.UnsupportedType("only images can be resized")
+	ErrFit = domain.Invalid("fit must be contain, cover or fill")
+	ErrSize = domain.Invalid("width and height must be 0 or one of RESIZE_SIZES, at least one of them set")
+)
+
+/* Concurrent requests for the same size make it once, the others wait and find it cached. A lock is kept while
+   requests use it, not for every size ever asked for */
+var (
+	mu sync.Mutex
+	locks = map[string]*sizeLock{}
+)
+
+type sizeLock struct {
+	sync.Mutex
+	users		int
+}
+
+// ResizedKey is the blob key the file resized to the parameters is cached under.
+func ResizedKey(File model.Files, Width int, Height int, Fit Fit) string {
+	return resizedPrefix(File.Path) + strconv.Itoa(Width) + "x" + strconv.Itoa(Height) + "." + string(Fit) + extension(File)
+}
+
+func resizedPrefix(Path string) string {
+	return "cache/resize/" + blob.Key(Path) + "."
+}
+
+// Purge deletes the resized copies of the content at Path, Resize makes them again when they're asked for.
+// Backends which can't list their blobs (see blob.Lister) keep them.
+func Purge(ctx context.Context, Path string) error {
+	Lister, ok := blob.Default().(blob.Lister)
+	if !ok { return nil }
+
+	Keys, err := Lister.List(ctx, resizedPrefix(Path))
+	if err != nil { return err }
+	for _, key := range Keys {
+		if err := blob.Default().Delete(ctx, key); err != nil && !errors.Is(err, blob.ErrNotFound) { return err }
+	}
+	return nil
+}
+
+// Resize returns the blob key of the image file resized to fit Width x Height, made on the first request and cached after.
+// A zero Width or Height follows the image's ratio, an empty Fit is FitContain.
+//
+// Example usage:
+//   key, err := thumbnailer.Resize(ctx, File, 640, 0, thumbnailer.FitContain)
+//   if err != nil { return err }
+//   reader, _, err := blob.Default().Open(ctx, key)
+//
+// Notes:
+//   - Images are never upscaled: a box larger than the image is shrunk (keeping its ratio) until it fits the image.
+//   - Files are content addressed, a cached size never goes stale. They're cached per parameters, hence RESIZE_SIZES.
+//   - Removing or replacing a file purges its cached sizes (see Purge), everything under cache/ may be deleted at any time.
+func Resize(ctx context.Context, File model.Files, Width int, Height int, Fit Fit) (string, error) {
+	if Fit == "" { Fit = FitContain }
+	if Fit != FitContain && Fit != FitCover && Fit != FitFill { return "", ErrFit }
+	if Width == 0 && Height == 0 || !allowed(Width) || !allowed(Height) { return "", ErrSize }
+	if !IsImage(File) { return "", ErrNotImage }
+
+	key := ResizedKey(File, Width, Height, Fit)
+	defer lock(key)()
+
+	reader, _, err := blob.Default().Open(ctx, key)
+	if err == nil {
+		reader.Close()
+		return key, nil
+	}
+	if !errors.Is(err, blob.ErrNotFound) { return "", err }
+
+	source, err := decode(ctx, File)
+	if err != nil { return "", err }
+
+	return key, store(ctx, key, resize(source, Width, Height, Fit), File)
+}
+
+/* 0 follows the image's ratio */
+func allowed(Size int) bool {
+	if Size == 0 { return true }
+	for _, Allowed := range globals.Env.RESIZE_SIZES {
+		if Size == Allowed { return true }
+	}
+	return false
+}
+
+/* Locks the size's key, the returned func unlocks it */
+func lock(key string) func() {
+	mu.Lock()
+	Lock := locks[key]
+	if Lock == nil {
+		Lock = &sizeLock{}
+		locks[key] = Lock
+	}
+	Lock.users++
+	mu.Unlock()
+
+	Lock.Lock()
+	return func() {
+		Lock.Unlock()
+		mu.Lock()
+		if Lock.users--; Lock.users == 0 { delete(locks, key) }
+		mu.Unlock()
+	}
+}
+
+func resize(source *image.RGBA, Width int, Height int, Fit Fit) *image.RGBA {
+	Bounds := source.Bounds()
+	W, H := Bounds.Dx(), Bounds.Dy()
+
+	/* A missing side follows the image's ratio, the box then has the image's ratio and every fit is the same */
+	if Width == 0 { Width = max(1, W * Height / H) }
+	if Height == 0 { Height = max(1, H * Width / W) }
+
+	/* Never upscaled: the box shrinks to the image, keeping its own ratio */
+	if Width > W { Width, Height = W, max(1, Height * W / Width) }
+	if Height > H { Width, Height = max(1, Width * H / Height), H }
+
+	switch Fit {
+		case FitContain:
+			if W * Height > H * Width {
+				Height = max(1, H * Width / W)
+			} else {
+				Width = max(1, W * Height / H)
+			}
+		case FitCover:
+			/* Crop the image to the box's ratio around its center, then it's a fill */
+			Crop := image.Rect(0, 0, W, H)
+			if W * Height > H * Width {
+				Crop.Max.X = max(1, H * Width / Height)
+			} else {
+				Crop.Max.Y = max(1, W * Height / Width)
+			}
+			Crop = Crop.Add(image.Pt((W - Crop.Dx()) / 2, (H - Crop.Dy()) / 2)).Add(Bounds.Min)
+			source = source.SubImage(Crop).(*image.RGBA)
+	}
+	return Downscale(source, Width, Height)
+}
@@ -0,0 +1,171 @@
+// Package thumbnailer makes downscaled copies of image uploads, one per width of globals.Env.THUMBNAIL_SIZES,
+// stored next to the image under its key with the width as a suffix:
+//
+//   uploads/3f2a....png            the upload
+//   uploads/3f2a....png.160.png    its 160px wide thumbnail
+//   uploads/3f2a....png.480.png    its 480px wide thumbnail
+//
+// and recorded as File_thumbnails, templates pick a size with Files.Thumbnail or list them with Files.Srcset.
+// Widths the image doesn't exceed are skipped, images are never upscaled.
+// Only the standard library's decoders are used: jpeg, png and gif.
+package thumbnailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	_ "image/gif" /* registers the gif decoder, thumbnails of gifs are pngs of their first frame */
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"main/server/common/blob"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// MaxPixels refuses images whose decoding alone would exhaust the memory (decompression bombs).
+const MaxPixels = 50_000_000
+
+// Quality of the jpeg thumbnails.
+const Quality = 85
+
+var ErrTooLarge = errors.New("image has too many pixels to be thumbnailed")
+
+// IsImage reports whether the file is an image thumbnails are made of, by its extension.
+func IsImage(File model.Files) bool {
+	switch strings.ToLower(filepath.Ext(File.Name)) {
+		case ".jpg", ".jpeg", ".png", ".gif":
+			return true
+	}
+	return false
+}
+
+// Key is the blob key of the file's thumbnail of the width.
+func Key(File model.Files, Width int) string {
+	return blob.Key(Path(File, Width))
+}
+
+// Path is the path of the file's thumbnail of the width, the file's path with the width as a suffix.
+func Path(File model.Files, Width int) string {
+	return File.Path + "." + strconv.Itoa(Width) + extension(File)
+}
+
+// Generate makes and records the thumbnails of an image file, other files are ignored. Widths it has a thumbnail
+// of already ("Thumbnails" preloaded) are skipped, a retry only makes the missing ones.
+func Generate(ctx context.Context, File *model.Files) error {
+	if !IsImage(*File) || len(globals.Env.THUMBNAIL_SIZES) == 0 { return nil }
+
+	source, err := decode(ctx, *File)
+	if err != nil { return err }
+	Bounds := source.Bounds()
+
+	for _, Width := range globals.Env.THUMBNAIL_SIZES {
+		if Width >= Bounds.Dx() || thumbnailed(*File, Width) { continue }
+
+		Height := Bounds.Dy() * Width / Bounds.Dx()
+		if Height < 1 { Height = 1 }
+
+		if err := store(ctx, Key(*File, Width), Downscale(source, Width, Height), *File); err != nil { return err }
+
+		Thumbnail := model.File_thumbnails{ FileID: File.ID, Width: Width, Height: Height, Path: Path(*File, Width) }
+		if err := storage.DB.WithContext(ctx).Create(&Thumbnail).Error; err != nil { return err }
+		File.Thumbnails = append(File.Thumbnails, Thumbnail)
+	}
+	return nil
+}
+
+func thumbnailed(File model.Files, Width int) bool {
+	for _, Thumbnail := range File.Thumbnails {
+		if Thumbnail.Width == Width { return true }
+	}
+	return false
+}
+
+/* Working on RGBA pixels directly is much faster than At() on whatever the decoder made */
+func decode(ctx context.Context, File model.Files) (*image.RGBA, error) {
+	reader, _, err := blob.Default().Open(ctx, blob.Key(File.Path))
+	if err != nil { return nil, err }
+	defer reader.Close()
+
+	var data bytes.Buffer
+	if _, err := data.ReadFrom(reader); err != nil { return nil, err }
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data.Bytes()))
+	if err != nil { return nil, err }
+	if config.Width * config.Height > MaxPixels { return nil, ErrTooLarge }
+
+	decoded, _, err := image.Decode(bytes.NewReader(data.Bytes()))
+	if err != nil { return nil, err }
+
+	/* Uploads stored before Normalize, or with EXIF_STRIP=false, may still be turned by their EXIF orientation */
+	return orient(toRGBA(decoded), Orientation(data.Bytes())), nil
+}
+
+func store(ctx context.Context, key string, resized image.Image, File model.Files) error {
+	var encoded bytes.Buffer
+	if err := encode(&encoded, resized, File); err != nil { return err }
+	return blob.Default().Put(ctx, key, &encoded, int64(encoded.Len()), ContentType(File))
+}
+
+// Downscale resizes the image to width x height by averaging the source pixels every target pixel covers.
+func Downscale(source *image.RGBA, width int, height int) *image.RGBA {
+	bounds := source.Bounds()
+	target := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		top := bounds.Min.Y + y * bounds.Dy() / height
+		bottom := bounds.Min.Y + (y + 1) * bounds.Dy() / height
+		if bottom <= top { bottom = top + 1 }
+
+		for x := 0; x < width; x++ {
+			left := bounds.Min.X + x * bounds.Dx() / width
+			right := bounds.Min.X + (x + 1) * bounds.Dx() / width
+			if right <= left { right = left + 1 }
+
+			var r, g, b, a, count int
+			for sy := top; sy < bottom; sy++ {
+				offset := source.PixOffset(left, sy)
+				for sx := left; sx < right; sx++ {
+					r += int(source.Pix[offset])
+					g += int(source.Pix[offset + 1])
+					b += int(source.Pix[offset + 2])
+					a += int(source.Pix[offset + 3])
+					offset += 4
+					count++
+				}
+			}
+
+			offset := target.PixOffset(x, y)
+			target.Pix[offset] = uint8(r / count)
+			target.Pix[offset + 1] = uint8(g / count)
+			target.Pix[offset + 2] = uint8(b / count)
+			target.Pix[offset + 3] = uint8(a / count)
+		}
+	}
+	return target
+}
+
+/* jpegs stay jpegs, the others become pngs to keep their transparency */
+func extension(File model.Files) string {
+	switch strings.ToLower(filepath.Ext(File.Name)) {
+		case ".jpg", ".jpeg":
+			return ".jpg"
+	}
+	return ".png"
+}
+
+// ContentType is the type of the file's thumbnails and resized copies.
+func ContentType(File model.Files) string {
+	if extension(File) == ".jpg" { return "image/jpeg" }
+	return "image/png"
+}
+
+func encode(buffer *bytes.Buffer, thumbnail image.Image, File model.Files) error {
+	if extension(File) == ".jpg" { return jpeg.Encode(buffer, thumbnail, &jpeg.Options{ Quality: Quality }) }
+	return png.Encode(buffer, thumbnail)
+}
@@ -0,0 +1,69 @@
+package transcoder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"main/server/common/blob"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// CRF is the quality of the renditions (x264's constant rate factor), lower is better and larger.
+const CRF = 23
+
+// RenditionKey is the blob key of the video's rendition of the height.
+func RenditionKey(File model.Files, Height int) string {
+	return blob.Key(File.Path) + "." + strconv.Itoa(Height) + "p.mp4"
+}
+
+// Renditions makes the mp4 copies of the video at the local path, one per height of TRANSCODE_HEIGHTS the video
+// is at least as tall as, and records them in File_renditions. A video smaller than every height gets one at its own.
+func Renditions(ctx context.Context, File model.Files, local string) error {
+	width, height, err := probeSize(ctx, local)
+	if err != nil { return err }
+
+	for _, Height := range heights(height) {
+		Width := evenly(width * Height / height)
+		if err := render(ctx, local, RenditionKey(File, Height), Height); err != nil { return fmt.Errorf("rendition %dp: %w", Height, err) }
+
+		Rendition := model.File_renditions{ FileID: File.ID, Width: Width, Height: Height, Key: RenditionKey(File, Height) }
+		/* A retried video replaces its renditions */
+		storage.DB.WithContext(ctx).Unscoped().Where("file_id = ? AND height = ?", File.ID, Height).Delete(&model.File_renditions{})
+		if err := storage.DB.WithContext(ctx).Create(&Rendition).Error; err != nil { return err }
+	}
+	return nil
+}
+
+/* yuv420p and the main profile play everywhere, faststart moves the index before the data so playback starts right away */
+func render(ctx context.Context, local string, key string, Height int) error {
+	output := local + "." + strconv.Itoa(Height) + "p.mp4"
+	defer os.Remove(output)
+
+	command := exec.CommandContext(ctx, "ffmpeg", "-v", "error", "-y", "-i", local,
+		"-vf", "scale=-2:" + strconv.Itoa(Height),
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", strconv.Itoa(CRF), "-profile:v", "main", "-pix_fmt", "yuv420p",
+		"-c:a", "aac", "-b:a", "128k",
+		"-movflags", "+faststart", output)
+	if out, err := command.CombinedOutput(); err != nil { return fmt.Errorf("ffmpeg: %w: %s", err, out) }
+
+	return store(ctx, key, output, "video/mp4")
+}
+
+/* The configured heights the video reaches, H.264 needs even sizes */
+func heights(source int) []int {
+	Heights := []int{}
+	for _, Height := range globals.Env.TRANSCODE_HEIGHTS {
+		if Height <= source { Heights = append(Heights, evenly(Height)) }
+	}
+	if len(Heights) == 0 && len(globals.Env.TRANSCODE_HEIGHTS) > 0 { Heights = append(Heights, evenly(source)) }
+	return Heights
+}
+
+func evenly(pixels int) int {
+	return pixels - pixels % 2
+}
@@ -0,0 +1,106 @@
+package transcoder
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+const (
+	// SpriteColumns is how many thumbnails a sprite row holds.
+	SpriteColumns = 10
+	// SpriteWidth is the width of a thumbnail, the height keeps the video's aspect ratio.
+	SpriteWidth = 160
+	// MaxThumbnails caps the thumbnails of long videos, the interval between them grows instead.
+	MaxThumbnails = 100
+	// MinInterval is the shortest time between two thumbnails.
+	MinInterval = 2 * time.Second
+)
+
+// Sprites makes the timeline thumbnail sprite and its WebVTT track of the video at the local path.
+func Sprites(ctx context.Context, File model.Files, local string) error {
+	duration, err := probeDuration(ctx, local)
+	if err != nil { return err }
+
+	interval := time.Duration(float64(duration) / MaxThumbnails)
+	if interval < MinInterval { interval = MinInterval }
+
+	count := int(math.Ceil(duration.Seconds() / interval.Seconds()))
+	if count < 1 { count = 1 }
+	rows := (count + SpriteColumns - 1) / SpriteColumns
+
+	sprite := local + ".sprite.jpg"
+	defer os.Remove(sprite)
+
+	filter := fmt.Sprintf("fps=1/%s,scale=%d:-2,tile=%dx%d", seconds(interval), SpriteWidth, SpriteColumns, rows)
+	command := exec.CommandContext(ctx, "ffmpeg", "-v", "error", "-y", "-i", local, "-vf", filter, "-frames:v", "1", "-q:v", "5", sprite)
+	if output, err := command.CombinedOutput(); err != nil { return fmt.Errorf("ffmpeg: %w: %s", err, output) }
+
+	width, height, err := probeSize(ctx, sprite)
+	if err != nil { return err }
+
+	track := local + ".vtt"
+	defer os.Remove(track)
+
+	/* The sprite is referenced relatively, it sits next to the track wherever the backend serves it from */
+	vtt := Track(path.Base(SpriteKey(File)), duration, interval, count, width / SpriteColumns, height / rows)
+	if err := os.WriteFile(track, []byte(vtt), 0644); err != nil { return err }
+
+	if err := store(ctx, SpriteKey(File), sprite, "image/jpeg"); err != nil { return err }
+	if err := store(ctx, TrackKey(File), track, "text/vtt"); err != nil { return err }
+
+	/* Only listed (see uploader.Uploaded) once both are there */
+	return storage.DB.WithContext(ctx).Model(&model.Files{}).Where("id = ?", File.ID).Update(model.FilesSprite, true).Error
+}
+
+// Track returns the WebVTT track of a sprite: one cue per thumbnail, pointing at it with a #xywh fragment.
+func Track(sprite string, duration time.Duration, interval time.Duration, count int, width int, height int) string {
+	var vtt strings.Builder
+	vtt.WriteString("WEBVTT\n\n")
+
+	for i := 0; i < count; i++ {
+		start := time.Duration(i) * interval
+		end := start + interval
+		if end > duration { end = duration }
+
+		x, y := (i % SpriteColumns) * width, (i / SpriteColumns) * height
+		fmt.Fprintf(&vtt, "%s --> %s\n%s#xywh=%d,%d,%d,%d\n\n", timestamp(start), timestamp(end), sprite, x, y, width, height)
+	}
+	return vtt.String()
+}
+
+func probeDuration(ctx context.Context, local string) (time.Duration, error) {
+	output, err := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "csv=p=0", local).Output()
+	if err != nil { return 0, fmt.Errorf("ffprobe: %w", err) }
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil { return 0, fmt.Errorf("ffprobe duration %q: %w", output, err) }
+	return time.Duration(value * float64(time.Second)), nil
+}
+
+func probeSize(ctx context.Context, image string) (int, int, error) {
+	output, err := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-select_streams", "v:0", "-show_entries", "stream=width,height", "-of", "csv=p=0:s=x", image).Output()
+	if err != nil { return 0, 0, fmt.Errorf("ffprobe: %w", err) }
+
+	var width, height int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(output)), "%dx%d", &width, &height); err != nil { return 0, 0, err }
+	return width, height, nil
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+func timestamp(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", int(d.Hours()), int(d.Minutes()) % 60, int(d.Seconds()) % 60, d.Milliseconds() % 1000)
+}
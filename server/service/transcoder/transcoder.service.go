@@ -0,0 +1,163 @@
+// Package transcoder post-processes uploaded videos with ffmpeg, in the background.
+//
+// Every step works on a temporary local copy of the video (the blob may live on S3) and stores what it makes
+// next to the video, under the video's key with a suffix:
+//
+//   uploads/3f2a....mp4             the upload
+//   uploads/3f2a....mp4.sprite.jpg  timeline thumbnails, tiled into one image
+//   uploads/3f2a....mp4.vtt         WebVTT track pointing every time range at its thumbnail in the sprite
+//   uploads/3f2a....mp4.720p.mp4    H.264 copy of each height of TRANSCODE_HEIGHTS (see Renditions)
+//
+// The player loads the VTT as a "metadata" track to show seek previews. Nothing is made when ffmpeg isn't installed.
+//
+// The video's progress is kept in Files.Transcoding: queued, processing, then ready or failed, and "video.transcoded"
+// is emitted on the container (see Setup) when it's done, with a Transcoded payload. Videos a restart interrupted
+// are queued again by Setup.
+package transcoder
+
+import (
+	"context"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"main/server/common/blob"
+	"main/server/common/module"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// Workers is how many videos are processed at once, ffmpeg is heavy on the cpu.
+const Workers = 2
+
+// Timeout bounds the processing of a single video.
+const Timeout = 10 * time.Minute
+
+var workers = make(chan struct{}, Workers)
+
+/* Set by Setup, nothing is emitted before */
+var container *module.Container
+
+// Transcoded is the payload of the "video.transcoded" event.
+type Transcoded struct {
+	FileID		uint
+	State		string
+}
+
+// Setup makes the transcoder emit its events on the container, and queues again the videos which were queued
+// or processing when the app stopped.
+func Setup(Container *module.Container) {
+	container = Container
+
+	var Files []model.Files
+	if err := storage.DB.Where("transcoding IN ?", []string{ model.TranscodeQueued, model.TranscodeProcessing }).Find(&Files).Error; err != nil {
+		log.Print("Resuming video processing: ", err)
+		return
+	}
+	for i := range Files { Enqueue(&Files[i]) }
+}
+
+// IsVideo reports whether the file is a video, by its extension.
+func IsVideo(File model.Files) bool {
+	return strings.HasPrefix(mime.TypeByExtension(filepath.Ext(File.Name)), "video/")
+}
+
+// Enqueue processes the video in the background and marks it queued, other files are ignored. So are videos
+// while ffmpeg isn't installed, they can be queued again once it is (POST /internal/files/:id/transcode).
+func Enqueue(File *model.Files) {
+	if !IsVideo(*File) || !Available() { return }
+	state(File, model.TranscodeQueued)
+
+	Video := *File
+	go func() {
+		workers <- struct{}{}
+		defer func() { <-workers }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+
+		state(&Video, model.TranscodeProcessing)
+		if err := Process(ctx, Video); err != nil {
+			log.Print("Processing video ", Video.ID, ": ", err)
+			state(&Video, model.TranscodeFailed)
+		} else {
+			state(&Video, model.TranscodeReady)
+		}
+
+		if container != nil { container.Emit(context.Background(), "video.transcoded", Transcoded{ FileID: Video.ID, State: Video.Transcoding }) }
+	}()
+}
+
+// Available reports whether ffmpeg and ffprobe are installed.
+func Available() bool {
+	for _, binary := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(binary); err != nil { return false }
+	}
+	return true
+}
+
+// Process runs every step of the pipeline on the video.
+func Process(ctx context.Context, File model.Files) error {
+	if !Available() { return exec.ErrNotFound }
+
+	local, err := copyLocal(ctx, File)
+	if err != nil { return err }
+	defer os.Remove(local)
+
+	if err := Sprites(ctx, File, local); err != nil { return err }
+	return Renditions(ctx, File, local)
+}
+
+// Done reports whether the video's processing is over, ready or failed.
+func Done(File model.Files) bool {
+	return File.Transcoding == model.TranscodeReady || File.Transcoding == model.TranscodeFailed
+}
+
+func state(File *model.Files, State string) {
+	File.Transcoding = State
+	if err := storage.DB.Model(&model.Files{}).Where("id = ?", File.ID).Update(model.FilesTranscoding, State).Error; err != nil {
+		log.Print("Recording video ", File.ID, " ", State, ": ", err)
+	}
+}
+
+// SpriteKey is the blob key of the video's thumbnail sprite.
+func SpriteKey(File model.Files) string {
+	return blob.Key(File.Path) + ".sprite.jpg"
+}
+
+// TrackKey is the blob key of the video's thumbnails WebVTT track.
+func TrackKey(File model.Files) string {
+	return blob.Key(File.Path) + ".vtt"
+}
+
+/* ffmpeg needs a seekable local file, the blob is copied into a temporary one */
+func copyLocal(ctx context.Context, File model.Files) (string, error) {
+	reader, _, err := blob.Default().Open(ctx, blob.Key(File.Path))
+	if err != nil { return "", err }
+	defer reader.Close()
+
+	local, err := os.CreateTemp("", "video-*" + filepath.Ext(File.Name))
+	if err != nil { return "", err }
+	defer local.Close()
+
+	if _, err := io.Copy(local, reader); err != nil {
+		os.Remove(local.Name())
+		return "", err
+	}
+	return local.Name(), nil
+}
+
+func store(ctx context.Context, key string, path string, contentType string) error {
+	file, err := os.Open(path)
+	if err != nil { return err }
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil { return err }
+	return blob.Default().Put(ctx, key, file, info.Size(), contentType)
+}
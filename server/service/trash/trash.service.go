@@ -0,0 +1,126 @@
+// Package trash is where deleted content and files wait to be restored or purged. Rows are soft deleted (see
+// storage.OnlyTrashed), the trash lists them by kind, restores them, and purges those older than TRASH_DAYS.
+//
+// A trashed file keeps its blobs until it's purged, purging it deletes them with uploader.Remove.
+package trash
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"main/server/common/domain"
+	uploader "main/server/common/helpers"
+	"main/server/common/globals"
+	"main/server/common/storage"
+	"main/server/model"
+)
+
+// ListSize is how many of a kind's latest trashed rows are listed.
+const ListSize = 200
+
+// PurgeEvery is how often the rows past TRASH_DAYS are purged.
+const PurgeEvery = 24 * time.Hour
+
+var ErrNotFound = domain.NotFound("trash: no such trashed row")
+
+// Kind is a model whose rows are trashed, Title is the column naming a row.
+type Kind struct {
+	Name		string
+	Label		string
+	Title		string
+	model		func() any
+	purge		func(ctx context.Context, ID uint) error		/* nil purges the row alone */
+}
+
+// Item is a trashed row.
+type Item struct {
+	ID			uint
+	Title		string
+	DeletedAt	time.Time
+}
+
+// Kinds are the models the trash shows, in its order.
+var Kinds = []Kind{
+	{ Name: "products", Label: "პროდუქტები", Title: model.ProductsName, model: func() any { return &model.Products{} } },
+	{ Name: "categories", Label: "კატეგორიები", Title: model.CategoriesName, model: func() any { return &model.Categories{} } },
+	{ Name: "news", Label: "სიახლეები", Title: model.NewsTitle, model: func() any { return &model.News{} } },
+	{ Name: "faq", Label: "ხშირად დასმული კითხვები", Title: model.FaqName, model: func() any { return &model.Faq{} } },
+	{ Name: "files", Label: "ფაილები", Title: model.FilesOriginal, model: func() any { return &model.Files{} }, purge: uploader.Remove },
+}
+
+// Find is the kind of a name.
+func Find(Name string) (Kind, bool) {
+	for _, Known := range Kinds {
+		if Known.Name == Name { return Known, true }
+	}
+	return Kind{}, false
+}
+
+// List is a kind's trashed rows, the last trashed first.
+func (Trashed Kind) List() ([]Item, error) {
+	Items := []Item{}
+	err := storage.DB.Model(Trashed.model()).Scopes(storage.OnlyTrashed).
+		Select("id", Trashed.Title + " AS title", "deleted_at").
+		Order("deleted_at desc").Limit(ListSize).Scan(&Items).Error
+	return Items, err
+}
+
+// Count is how many rows of a kind are trashed.
+func (Trashed Kind) Count() (int64, error) {
+	var Count int64
+	err := storage.DB.Model(Trashed.model()).Scopes(storage.OnlyTrashed).Count(&Count).Error
+	return Count, err
+}
+
+// Restore brings a trashed row back.
+//
+// Returns:
+//   - ErrNotFound when there's no such trashed row.
+//   - storage.ErrRestoreConflict when its unique values were taken since it was trashed.
+func (Trashed Kind) Restore(ID uint) error {
+	Restored, err := storage.Restore(storage.DB, Trashed.model(), ID)
+	if err != nil { return err }
+	if Restored == 0 { return ErrNotFound }
+	return nil
+}
+
+// Purge removes a trashed row for good, a file with its blobs.
+//
+// Returns:
+//   - ErrNotFound when there's no such trashed row.
+//   - The database's error when other rows still point to it, it stays in the trash then.
+func (Trashed Kind) Purge(ctx context.Context, ID uint) error {
+	var Count int64
+	if err := storage.DB.WithContext(ctx).Model(Trashed.model()).Scopes(storage.OnlyTrashed).Where("id = ?", ID).Count(&Count).Error; err != nil { return err }
+	if Count == 0 { return ErrNotFound }
+
+	if Trashed.purge != nil { return Trashed.purge(ctx, ID) }
+	_, err := storage.Purge(storage.DB.WithContext(ctx), Trashed.model(), ID)
+	return err
+}
+
+// PurgeExpired purges the rows trashed more than TRASH_DAYS ago, every kind's. Those which can't be purged are logged
+// and stay in the trash, the next run tries them again.
+func PurgeExpired(ctx context.Context) error {
+	if globals.Env.TRASH_DAYS == 0 { return nil }
+	Age := time.Duration(globals.Env.TRASH_DAYS) * 24 * time.Hour
+
+	var Failed []error
+	for _, Trashed := range Kinds {
+		if Trashed.purge == nil {
+			Purged, err := storage.PurgeOlderThan(storage.DB.WithContext(ctx), Trashed.model(), Age)
+			if Purged > 0 { log.Print("Purged ", Purged, " trashed ", Trashed.Name) }
+			if err != nil { Failed = append(Failed, err) }
+			continue
+		}
+
+		var IDs []uint
+		if err := storage.DB.WithContext(ctx).Model(Trashed.model()).Scopes(storage.OnlyTrashed).Where("deleted_at < ?", time.Now().Add(-Age)).Pluck("id", &IDs).Error; err != nil { return err }
+		for _, ID := range IDs {
+			if err := Trashed.purge(ctx, ID); err != nil { Failed = append(Failed, err) }
+		}
+	}
+	return errors.Join(Failed...)
+}
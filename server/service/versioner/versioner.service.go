@@ -0,0 +1,272 @@
+// Package versioner keeps the history of the site's settings (model.Interface and its contact, about, mail theme and
+// social media records) as Interface_versions, each a JSON Snapshot of them.
+//
+// Record takes a snapshot after a change, one identical to the latest version isn't recorded again. Any version can
+// be looked at through a preview channel (Preview, the site renders with its settings while the channel is open)
+// and rolled back to (Restore), which records the restored settings as a new version: the history only grows.
+package versioner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"main/server/common/bandwidth"
+	"main/server/common/domain"
+	"main/server/common/module"
+	"main/server/common/storage"
+	"main/server/model"
+	"main/server/service/outbox"
+	"main/server/service/previewer"
+)
+
+var ErrNotFound = domain.NotFound("settings version not found")
+
+// Kinds are the preview channel kinds (see previewer.Kinds) which change the settings, publishing a channel with
+// one of them records a version.
+var Kinds = []string{"interface", "contact", "about", "mail", "social"}
+
+// Snapshot is what a version keeps of the settings, records are keyed by their ID so they are restored in place.
+type Snapshot struct {
+	LowBandwidth	bool				`json:"lowBandwidth"`
+	Contact			Contact				`json:"contact"`
+	About			About				`json:"about"`
+	Mail			Mail				`json:"mail"`
+	Socials			[]Social			`json:"socials"`
+}
+
+type Contact struct {
+	ID				uint		`json:"id"`
+	Phone			string		`json:"phone"`
+	Email			string		`json:"email"`
+	Location		string		`json:"location"`
+	ShortDesc		string		`json:"shortDesc"`
+	LocationLink	string		`json:"locationLink"`
+	LocationIframe	string		`json:"locationIframe"`
+}
+
+type About struct {
+	ID				uint		`json:"id"`
+	Body			string		`json:"body"`
+	Terms			string		`json:"terms"`
+}
+
+type Mail struct {
+	ID				uint		`json:"id"`
+	LogoID			*int		`json:"logoId"`
+	Primary			string		`json:"primary"`
+	Background		string		`json:"background"`
+	Text			string		`json:"text"`
+	Footer			string		`json:"footer"`
+}
+
+type Social struct {
+	ID				uint		`json:"id"`
+	Name			string		`json:"name"`
+	Url				string		`json:"url"`
+}
+
+/* One record's settings, as a preview change: Kind of previewer.Kinds, Fields keyed by the model's field names */
+type change struct {
+	Kind		string
+	RecordID	uint
+	Fields		map[string]any
+}
+
+// Setup records a version when a preview channel changing the settings is published.
+func Setup(container *module.Container) {
+	container.On("preview.published", func(ctx context.Context, payload any) error {
+		Event, ok := payload.(outbox.Event)
+		if !ok { return nil }
+
+		var Channel model.Preview_channels
+		if err := json.Unmarshal(Event.Payload, &Channel); err != nil { return err }
+		for _, Change := range Channel.Changes {
+			if !settings(Change.Kind) { continue }
+			_, err := Record(storage.DB.WithContext(ctx), nil, "preview: " + Channel.Name)
+			return err
+		}
+		return nil
+	})
+}
+
+func settings(Kind string) bool {
+	for _, Setting := range Kinds {
+		if Setting == Kind { return true }
+	}
+	return false
+}
+
+// Capture reads the current settings.
+func Capture(db *gorm.DB) (model.Interface, Snapshot, error) {
+	var Interface model.Interface
+	if err := db.Preload("Contact").Preload("About").Preload("Mail").Preload("SocialMedia").Last(&Interface).Error; err != nil {
+		return Interface, Snapshot{}, err
+	}
+
+	Settings := Snapshot{
+		LowBandwidth: Interface.LowBandwidth,
+		Contact: Contact{
+			ID: Interface.Contact.ID, Phone: Interface.Contact.Phone, Email: Interface.Contact.Email, Location: Interface.Contact.Location,
+			ShortDesc: Interface.Contact.ShortDesc, LocationLink: Interface.Contact.LocationLink, LocationIframe: Interface.Contact.LocationIframe,
+		},
+		About: About{ ID: Interface.About.ID, Body: Interface.About.Body, Terms: Interface.About.Terms },
+		Mail: Mail{
+			ID: Interface.Mail.ID, LogoID: Interface.Mail.LogoID, Primary: Interface.Mail.Primary,
+			Background: Interface.Mail.Background, Text: Interface.Mail.Text, Footer: Interface.Mail.Footer,
+		},
+		Socials: []Social{},
+	}
+	for _, Media := range Interface.SocialMedia {
+		Settings.Socials = append(Settings.Socials, Social{ ID: Media.ID, Name: Media.Name, Url: Media.Url })
+	}
+	return Interface, Settings, nil
+}
+
+// Record keeps the current settings as the next version, made by UsersID (nil for the system), unless they are
+// those of the latest version already. Interface.Ver follows the version.
+//
+// Returns:
+//   - The version recorded, or the latest one when nothing changed.
+func Record(db *gorm.DB, UsersID *uint, Note string) (model.Interface_versions, error) {
+	var Version model.Interface_versions
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		Interface, Settings, err := Capture(tx)
+		if err != nil { return err }
+
+		Encoded, err := json.Marshal(Settings)
+		if err != nil { return err }
+
+		Latest := tx.Where("interface_id = ?", Interface.ID).Order("version desc").Limit(1).Find(&Version)
+		if Latest.Error != nil { return Latest.Error }
+		if Latest.RowsAffected > 0 && Version.Snapshot == string(Encoded) { return nil }
+
+		Version = model.Interface_versions{
+			InterfaceID: Interface.ID, Version: Version.Version + 1, Snapshot: string(Encoded), UsersID: UsersID, Note: Note,
+		}
+		if err := tx.Create(&Version).Error; err != nil { return err }
+		return tx.Model(&model.Interface{}).Where("id = ?", Interface.ID).Update(model.InterfaceVer, Version.Version).Error
+	})
+	return Version, err
+}
+
+// List returns the latest versions, newest first, with the users who made them.
+func List(Limit int) ([]model.Interface_versions, error) {
+	var Versions []model.Interface_versions
+	err := storage.DB.Preload("Users").Order("version desc").Limit(Limit).Find(&Versions).Error
+	return Versions, err
+}
+
+// Find returns a version by its number.
+func Find(Number int) (model.Interface_versions, Snapshot, error) {
+	var Version model.Interface_versions
+	var Settings Snapshot
+
+	if err := storage.DB.Where("version = ?", Number).Order("id desc").First(&Version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) { return Version, Settings, ErrNotFound }
+		return Version, Settings, err
+	}
+	if err := json.Unmarshal([]byte(Version.Snapshot), &Settings); err != nil { return Version, Settings, err }
+	return Version, Settings, nil
+}
+
+// Preview opens a preview channel staging the version's settings, visiting /preview/:token shows the site with them.
+// Publishing the channel applies them, as Restore does.
+func Preview(Number int) (model.Preview_channels, error) {
+	_, Settings, err := Find(Number)
+	if err != nil { return model.Preview_channels{}, err }
+
+	Interface, _, err := Capture(storage.DB)
+	if err != nil { return model.Preview_channels{}, err }
+
+	Channel, err := previewer.Create(fmt.Sprintf("Settings v%d", Number))
+	if err != nil { return Channel, err }
+
+	for _, Change := range changes(Interface.ID, Settings) {
+		/* A channel change of a missing record fails the whole preview, records removed since are left out */
+		Target, _ := record(Change.Kind)
+		var Found int64
+		if err := storage.DB.Model(Target).Where("id = ?", Change.RecordID).Count(&Found).Error; err != nil { return Channel, err }
+		if Found == 0 { continue }
+
+		if _, err := previewer.Stage(Channel.ID, Change.Kind, Change.RecordID, false, Change.Fields); err != nil { return Channel, err }
+	}
+	return Channel, nil
+}
+
+// Restore rolls the settings back to the version's, recorded as a new version by UsersID.
+//
+// Notes:
+//   - Records removed since the version (a social media link) aren't brought back, those added stay as they are.
+//   - A mail logo which was deleted since is left out.
+func Restore(Number int, UsersID *uint) (model.Interface_versions, error) {
+	_, Settings, err := Find(Number)
+	if err != nil { return model.Interface_versions{}, err }
+
+	var Version model.Interface_versions
+	err = storage.DB.Transaction(func(tx *gorm.DB) error {
+		Interface, _, err := Capture(tx)
+		if err != nil { return err }
+
+		if Settings.Mail.LogoID != nil {
+			var Logos int64
+			if err := tx.Model(&model.Files{}).Where("id = ?", *Settings.Mail.LogoID).Count(&Logos).Error; err != nil { return err }
+			if Logos == 0 { Settings.Mail.LogoID = nil }
+		}
+
+		for _, Change := range changes(Interface.ID, Settings) {
+			Target, _ := record(Change.Kind)
+			if err := tx.Model(Target).Where("id = ?", Change.RecordID).Updates(Change.Fields).Error; err != nil { return err }
+		}
+
+		Version, err = Record(tx, UsersID, fmt.Sprintf("restored v%d", Number))
+		return err
+	})
+	if err != nil { return Version, err }
+
+	bandwidth.SetSite(Settings.LowBandwidth)
+	return Version, nil
+}
+
+/* The snapshot as changes of the records which still exist, those it has no ID of (never created) are skipped */
+func changes(InterfaceID uint, Settings Snapshot) []change {
+	Changes := []change{
+		{ Kind: "interface", RecordID: InterfaceID, Fields: map[string]any{ "LowBandwidth": Settings.LowBandwidth } },
+	}
+	if Settings.Contact.ID != 0 {
+		Changes = append(Changes, change{ Kind: "contact", RecordID: Settings.Contact.ID, Fields: map[string]any{
+			"Phone": Settings.Contact.Phone, "Email": Settings.Contact.Email, "Location": Settings.Contact.Location,
+			"ShortDesc": Settings.Contact.ShortDesc, "LocationLink": Settings.Contact.LocationLink, "LocationIframe": Settings.Contact.LocationIframe,
+		}})
+	}
+	if Settings.About.ID != 0 {
+		Changes = append(Changes, change{ Kind: "about", RecordID: Settings.About.ID, Fields: map[string]any{
+			"Body": Settings.About.Body, "Terms": Settings.About.Terms,
+		}})
+	}
+	if Settings.Mail.ID != 0 {
+		Changes = append(Changes, change{ Kind: "mail", RecordID: Settings.Mail.ID, Fields: map[string]any{
+			"LogoID": Settings.Mail.LogoID, "Primary": Settings.Mail.Primary, "Background": Settings.Mail.Background,
+			"Text": Settings.Mail.Text, "Footer": Settings.Mail.Footer,
+		}})
+	}
+	for _, Media := range Settings.Socials {
+		Changes = append(Changes, change{ Kind: "social", RecordID: Media.ID, Fields: map[string]any{ "Url": Media.Url } })
+	}
+	return Changes
+}
+
+func record(Kind string) (any, bool) {
+	switch Kind {
+		case "interface": return &model.Interface{}, true
+		case "contact": return &model.Interface_contact{}, true
+		case "about": return &model.Interface_about{}, true
+		case "mail": return &model.Interface_mail{}, true
+		case "social": return &model.Social_media{}, true
+	}
+	return nil, false
+}